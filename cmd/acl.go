@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/code-sleuth/ike-go/internal/manager/repository"
+	"github.com/code-sleuth/ike-go/pkg/util"
+
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+)
+
+var aclCmd = &cobra.Command{
+	Use:   "acl",
+	Short: "Manage the access-control labels of sources",
+	Long: `Manage the access-control labels searches enforce. Imports label what they import: GitHub
+files with their repository's visibility (public, internal, or private), WordPress posts with
+public, private, or restricted (password protected), and plugin items with the labels the plugin
+reports, unless "sources settings set --label" gives the content under a URL other labels. The
+search API only returns documents whose source has no labels, is labeled public, or has a label
+granted to the caller's bearer token with serve --search-token; search --label shows what such a
+caller would see.`,
+}
+
+var aclListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List labels with the sources and documents they cover",
+	Run: func(cmd *cobra.Command, _ []string) {
+		logger := util.NewLogger(zerolog.ErrorLevel)
+		database, err := openDatabase()
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to connect to database")
+		}
+		defer database.Close()
+
+		labels, err := repository.NewACLRepository(database).List()
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to list labels")
+		}
+
+		err = printResult(cmd, nonNil(labels), func(w io.Writer) {
+			for _, label := range labels {
+				fmt.Fprintf(w, "%s  sources=%d documents=%d\n", label.Label, label.Sources, label.Documents)
+			}
+		})
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to print labels")
+		}
+	},
+}
+
+var aclSetCmd = &cobra.Command{
+	Use:   "set [source-id] [label...]",
+	Short: "Replace the labels of a source until its next import",
+	Long: `Replace the access-control labels of a source, such as to restrict content at once. Giving no
+labels makes the source searchable by everyone. Its next import replaces the labels again with
+those it finds, so lasting labels are given with "sources settings set --label".`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		logger := util.NewLogger(zerolog.ErrorLevel)
+		database, err := openDatabase()
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to connect to database")
+		}
+		defer database.Close()
+
+		if _, err := repository.NewSourceRepository(database).GetByID(args[0]); err != nil {
+			logger.Fatal().Err(err).Msg("Failed to get source")
+		}
+		if err := repository.NewACLRepository(database).Set(args[0], args[1:]); err != nil {
+			logger.Fatal().Err(err).Msg("Failed to set labels")
+		}
+
+		message := fmt.Sprintf("Source %s is searchable by everyone", args[0])
+		if len(args) > 1 {
+			message = fmt.Sprintf("Source %s labeled %s", args[0], strings.Join(args[1:], ", "))
+		}
+		printAction(cmd, logger, args[0], "labeled", message)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(aclCmd)
+	aclCmd.AddCommand(aclListCmd)
+	aclCmd.AddCommand(aclSetCmd)
+}
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/code-sleuth/ike-go/internal/manager/models"
+	"github.com/code-sleuth/ike-go/internal/manager/repository"
+	"github.com/code-sleuth/ike-go/pkg/util"
+
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+)
+
+var collectionsCmd = &cobra.Command{
+	Use:   "collections",
+	Short: "Manage collections",
+	Long: `Manage the named collections sources are grouped into, so one database can hold several
+corpora. Sources are placed in a collection with import --collection, and documents and chunks
+belong to the collection of their source; search --filter collection=<name>, export --collection,
+status --collection, and sources list --collection work on a single collection.`,
+}
+
+var collectionsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List collections with their source, document, chunk, and embedding counts",
+	Run: func(cmd *cobra.Command, _ []string) {
+		logger := util.NewLogger(zerolog.ErrorLevel)
+		database, err := openDatabase()
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to connect to database")
+		}
+		defer database.Close()
+
+		collections, err := repository.NewCollectionRepository(database).List()
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to list collections")
+		}
+
+		err = printResult(cmd, nonNil(collections), func(w io.Writer) {
+			for _, collection := range collections {
+				fmt.Fprintf(w, "%s  sources=%d documents=%d chunks=%d embeddings=%d  %s\n", collection.Name,
+					collection.Sources, collection.Documents, collection.Chunks, collection.Embeddings,
+					stringOr(collection.Description, ""))
+			}
+		})
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to print collections")
+		}
+	},
+}
+
+var collectionsCreateCmd = &cobra.Command{
+	Use:   "create [name]",
+	Short: "Create a collection",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		logger := util.NewLogger(zerolog.ErrorLevel)
+		database, err := openDatabase()
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to connect to database")
+		}
+		defer database.Close()
+
+		collection := &models.Collection{Name: args[0]}
+		if description, _ := cmd.Flags().GetString("description"); description != "" {
+			collection.Description = &description
+		}
+		if err := repository.NewCollectionRepository(database).Create(collection); err != nil {
+			logger.Fatal().Err(err).Msg("Failed to create collection")
+		}
+
+		printAction(cmd, logger, args[0], "created", "Collection created: "+args[0])
+	},
+}
+
+var collectionsDeleteCmd = &cobra.Command{
+	Use:   "delete [name]",
+	Short: "Delete a collection, soft-deleting its sources (or, with --cascade, deleting them permanently)",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		logger := util.NewLogger(zerolog.ErrorLevel)
+		database, err := openDatabase()
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to connect to database")
+		}
+		defer database.Close()
+
+		cascade, _ := cmd.Flags().GetBool("cascade")
+		deleted, err := repository.NewCollectionRepository(database).Delete(args[0], cascade)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to delete collection")
+		}
+
+		action, message := "deleted", fmt.Sprintf("Collection %s deleted with %d sources", args[0], deleted)
+		if cascade {
+			action = "purged"
+			message = fmt.Sprintf("Collection %s and its %d sources, with their downloads, documents, chunks, "+
+				"and embeddings, deleted", args[0], deleted)
+		}
+		printAction(cmd, logger, args[0], action, message)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(collectionsCmd)
+	collectionsCmd.AddCommand(collectionsListCmd)
+	collectionsCmd.AddCommand(collectionsCreateCmd)
+	collectionsCmd.AddCommand(collectionsDeleteCmd)
+
+	collectionsCreateCmd.Flags().String("description", "", "What the collection holds")
+	collectionsDeleteCmd.Flags().Bool("cascade", false,
+		"Permanently delete the collection's sources with their content instead of soft-deleting them")
+}
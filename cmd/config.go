@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+
+	"strings"
+
+	"github.com/code-sleuth/ike-go/pkg/config"
+	"github.com/code-sleuth/ike-go/pkg/db"
+	"github.com/spf13/cobra"
+)
+
+// configFileEnv names the settings file used when --config-file is not given.
+const configFileEnv = "IKE_CONFIG_FILE"
+
+var (
+	ErrUnknownConfigKey = errors.New("unknown config key")
+	ErrConfigValue      = errors.New("config values must be strings, numbers, or booleans")
+	ErrInvalidSetting   = errors.New("settings must be given as KEY=VALUE")
+)
+
+// appConfig holds the settings commands hand to the components they create. It is loaded before
+// any command runs, from the environment, the --config-file settings file, and --set.
+var appConfig *config.Config
+
+// loadAppConfig loads appConfig with the settings file and --set overrides given to the root
+// command.
+func loadAppConfig() error {
+	path, _ := rootCmd.PersistentFlags().GetString("config-file")
+	if path == "" {
+		path = os.Getenv(configFileEnv)
+	}
+
+	entries, _ := rootCmd.PersistentFlags().GetStringArray("set")
+	overrides := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		key, value, ok := strings.Cut(entry, "=")
+		if !ok || key == "" {
+			return fmt.Errorf("%w: %q", ErrInvalidSetting, entry)
+		}
+		overrides[key] = value
+	}
+
+	loaded, err := config.Load(path, overrides)
+	if err != nil {
+		return err
+	}
+	appConfig = loaded
+	return nil
+}
+
+// openDatabase connects to the database appConfig configures.
+func openDatabase() (*db.DB, error) {
+	return db.NewConnectionWithConfig(appConfig.Database)
+}
+
+// loadConfigFile sets cmd's flags from the JSON object in the file named by its --config flag, if
+// any. Keys are flag names and values are what would follow the flag on the command line, such as
+// {"addr": ":8080", "poll": "10s"}. Flags given on the command line win over the file.
+func loadConfigFile(cmd *cobra.Command) error {
+	path, _ := cmd.Flags().GetString("config")
+	if path == "" {
+		return nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open config file: %w", err)
+	}
+	defer file.Close()
+
+	var values map[string]interface{}
+	decoder := json.NewDecoder(file)
+	decoder.UseNumber()
+	if err := decoder.Decode(&values); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(values)) {
+		flag := cmd.Flags().Lookup(name)
+		if flag == nil || name == "config" {
+			return fmt.Errorf("%w %q in %s", ErrUnknownConfigKey, name, path)
+		}
+		if flag.Changed {
+			continue
+		}
+
+		var value string
+		switch v := values[name].(type) {
+		case string:
+			value = v
+		case json.Number, bool:
+			value = fmt.Sprint(v)
+		default:
+			return fmt.Errorf("%w: %q in %s", ErrConfigValue, name, path)
+		}
+		if err := cmd.Flags().Set(name, value); err != nil {
+			return fmt.Errorf("invalid %q in %s: %w", name, path, err)
+		}
+	}
+	return nil
+}
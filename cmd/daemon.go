@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/code-sleuth/ike-go/internal/manager/notifier"
+	"github.com/code-sleuth/ike-go/internal/manager/repository"
+	"github.com/code-sleuth/ike-go/internal/manager/scheduler"
+	"github.com/code-sleuth/ike-go/pkg/util"
+
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+)
+
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Run scheduled imports, the job worker, and optionally the HTTP API",
+	Long: `Run the scheduler and a job worker in one long-lived process. Every schedule added with
+"ike-go schedules add" is enqueued as an import job whenever its cron expression comes due, and
+the worker runs it, so sources stay fresh without external cron plumbing.
+
+With --addr the daemon also serves the HTTP API of "ike-go serve": search, health probes, metrics,
+and the webhook receivers enabled by their secrets. Imports the webhooks enqueue are run by the
+daemon's own worker, making it a self-contained ingestion service.
+
+Settings can be kept in a JSON file passed with --config, whose keys are the flag names:
+
+  {"addr": ":8080", "model": "text-embedding-3-small", "poll": "10s",
+   "github-webhook-secret": "...", "shutdown-timeout": "25s"}
+
+Flags given on the command line override the file.
+
+The daemon running the scheduler also calls the outbound webhooks added with "ike-go webhooks
+add". Several daemons can share one database, but only one should run the scheduler; start the
+others with --no-scheduler, or use "ike-go worker".
+
+On SIGINT or SIGTERM the daemon stops serving, scheduling, and claiming jobs, and drains the
+running job as "ike-go worker" does.`,
+	Example: `  ike-go daemon
+  ike-go daemon --addr :8080
+  ike-go daemon --config /etc/ike-go/daemon.json
+  ike-go daemon --no-scheduler --poll 10s`,
+	PreRunE: func(cmd *cobra.Command, _ []string) error {
+		return loadConfigFile(cmd)
+	},
+	Run: func(cmd *cobra.Command, _ []string) {
+		logger := util.NewLogger(zerolog.ErrorLevel)
+
+		addr, _ := cmd.Flags().GetString("addr")
+		model, _ := cmd.Flags().GetString("model")
+
+		poll, _ := cmd.Flags().GetDuration("poll")
+		lease, _ := cmd.Flags().GetDuration("lease")
+		interval, _ := cmd.Flags().GetDuration("interval")
+		notifyInterval, _ := cmd.Flags().GetDuration("notify-interval")
+		noScheduler, _ := cmd.Flags().GetBool("no-scheduler")
+		shutdownTimeout, _ := cmd.Flags().GetDuration("shutdown-timeout")
+		metricsAddr, _ := cmd.Flags().GetString("metrics-addr")
+		workerID, _ := cmd.Flags().GetString("id")
+		if workerID == "" {
+			workerID = defaultWorkerID()
+		}
+
+		database, err := openDatabase()
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to connect to database")
+		}
+		defer database.Close()
+
+		stopMetrics := serveMetrics(metricsAddr, logger)
+		defer stopMetrics()
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		// Any loop failing stops the others
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		apiDone := make(chan error, 1)
+		if addr == "" {
+			apiDone <- nil
+		} else {
+			srv, err := newAPIServer(cmd, database, model)
+			if err != nil {
+				logger.Fatal().Err(err).Msg("Failed to create API server")
+			}
+			go func() {
+				defer cancel()
+				apiDone <- srv.ListenAndServe(ctx, addr)
+			}()
+		}
+
+		queue := repository.NewJobRepository(database)
+		schedulerDone := make(chan error, 1)
+		if noScheduler {
+			schedulerDone <- nil
+		} else {
+			sched := scheduler.New(repository.NewScheduleRepository(database), queue)
+			notify := notifier.New(repository.NewWebhookSubscriptionRepository(database))
+			go func() {
+				defer cancel()
+				// The scheduler and the notifier run together so only one daemon calls webhooks
+				notifyDone := make(chan error, 1)
+				go func() { notifyDone <- notify.Run(ctx, notifyInterval) }()
+				err := sched.Run(ctx, interval)
+				cancel()
+				schedulerDone <- errors.Join(err, <-notifyDone)
+			}()
+		}
+
+		// The worker is stopped by draining the engine, so the running job can finish
+		engine := newProcessingEngine(logger)
+		engine.SetDialect(database.Dialect())
+		go shutdownOnDone(ctx, engine, shutdownTimeout, logger)
+		workErr := engine.Work(cmd.Context(), queue, database.DB, workerID, poll, lease)
+		cancel()
+
+		if err := <-apiDone; err != nil {
+			logger.Fatal().Err(err).Msg("HTTP server failed")
+		}
+		if err := <-schedulerDone; err != nil {
+			logger.Fatal().Err(err).Msg("Scheduler failed")
+		}
+		if workErr != nil {
+			logger.Fatal().Err(workErr).Msg("Worker failed")
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(daemonCmd)
+
+	addAPIFlags(daemonCmd, "")
+	daemonCmd.Flag("addr").Usage = "Serve the HTTP API on this address, e.g. :8080 (default no API)"
+	daemonCmd.Flags().String("config", "", "JSON file of flag values; flags given on the command line override it")
+	daemonCmd.Flags().Duration("poll", 5*time.Second, "How often to check the queue for new jobs")
+	daemonCmd.Flags().Duration("lease", time.Hour,
+		"Requeue running jobs whose worker has not renewed their lock for this long")
+	daemonCmd.Flags().Duration("interval", scheduler.DefaultInterval, "How often to check for due schedules")
+	daemonCmd.Flags().Duration("notify-interval", notifier.DefaultInterval,
+		"How often to deliver new events to outbound webhooks")
+	daemonCmd.Flags().Bool("no-scheduler", false, "Only run the worker, not the scheduler or outbound webhooks")
+	daemonCmd.Flags().String("id", "", "Worker ID recorded on claimed jobs (default host:pid)")
+	daemonCmd.Flags().String("metrics-addr", "", "Serve Prometheus metrics at GET /metrics on this address, e.g. :9090")
+	daemonCmd.Flags().Duration("shutdown-timeout", defaultShutdownTimeout,
+		"How long the running job may continue after SIGINT or SIGTERM before it is cancelled")
+}
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDaemonConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("Failed to write config file: %v", err)
+		}
+		return path
+	}
+	config := writeConfig("daemon.json", `{"addr": ":8080", "poll": "10s", "no-scheduler": true}`)
+
+	tests := []struct {
+		name                string
+		args                []string
+		expectedErr         error
+		expectError         bool
+		expectedAddr        string
+		expectedPoll        time.Duration
+		expectedNoScheduler bool
+		description         string
+	}{
+		{
+			name:         "no config file",
+			args:         nil,
+			expectedPoll: 5 * time.Second,
+			description:  "should keep the defaults without --config",
+		},
+		{
+			name:                "config file",
+			args:                []string{"--config", config},
+			expectedAddr:        ":8080",
+			expectedPoll:        10 * time.Second,
+			expectedNoScheduler: true,
+			description:         "should apply the values in the file",
+		},
+		{
+			name:                "flags override file",
+			args:                []string{"--config", config, "--poll", "30s", "--addr", ":9000"},
+			expectedAddr:        ":9000",
+			expectedPoll:        30 * time.Second,
+			expectedNoScheduler: true,
+			description:         "should prefer flags given on the command line",
+		},
+		{
+			name:        "missing file",
+			args:        []string{"--config", filepath.Join(dir, "missing.json")},
+			expectedErr: fs.ErrNotExist,
+			description: "should fail when the file does not exist",
+		},
+		{
+			name:        "malformed file",
+			args:        []string{"--config", writeConfig("malformed.json", `{"poll": "10s"`)},
+			expectError: true,
+			description: "should fail when the file is not valid JSON",
+		},
+		{
+			name:        "unknown key",
+			args:        []string{"--config", writeConfig("unknown.json", `{"pol": "10s"}`)},
+			expectedErr: ErrUnknownConfigKey,
+			description: "should fail on keys that are not flags",
+		},
+		{
+			name:        "invalid value",
+			args:        []string{"--config", writeConfig("invalid.json", `{"poll": "soon"}`)},
+			expectError: true,
+			description: "should fail on values the flag does not accept",
+		},
+		{
+			name:        "nested value",
+			args:        []string{"--config", writeConfig("nested.json", `{"poll": {"every": "10s"}}`)},
+			expectedErr: ErrConfigValue,
+			description: "should fail on values that are not strings, numbers, or booleans",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parseFlags(t, daemonCmd, tt.args...)
+
+			err := loadConfigFile(daemonCmd)
+			if tt.expectError || tt.expectedErr != nil {
+				if err == nil {
+					t.Fatalf("Expected error but got none for test: %s", tt.description)
+				}
+				if tt.expectedErr != nil && !errors.Is(err, tt.expectedErr) {
+					t.Errorf("Expected error %v, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error for test %s: %v", tt.description, err)
+			}
+
+			addr, _ := daemonCmd.Flags().GetString("addr")
+			if addr != tt.expectedAddr {
+				t.Errorf("Expected addr %q, got %q", tt.expectedAddr, addr)
+			}
+			poll, _ := daemonCmd.Flags().GetDuration("poll")
+			if poll != tt.expectedPoll {
+				t.Errorf("Expected poll %v, got %v", tt.expectedPoll, poll)
+			}
+			noScheduler, _ := daemonCmd.Flags().GetBool("no-scheduler")
+			if noScheduler != tt.expectedNoScheduler {
+				t.Errorf("Expected no-scheduler %v, got %v", tt.expectedNoScheduler, noScheduler)
+			}
+		})
+	}
+}
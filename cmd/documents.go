@@ -2,11 +2,14 @@ package cmd
 
 import (
 	"database/sql"
-	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"os"
+	"time"
 
 	"github.com/code-sleuth/ike-go/internal/manager/models"
+	"github.com/code-sleuth/ike-go/internal/manager/repository"
 	"github.com/code-sleuth/ike-go/pkg/db"
 	"github.com/code-sleuth/ike-go/pkg/util"
 	"github.com/rs/zerolog"
@@ -16,16 +19,16 @@ import (
 var documentsCmd = &cobra.Command{
 	Use:   "documents",
 	Short: "Manage documents",
-	Long:  `Manage documents in the database - list and get.`,
+	Long:  `Manage documents in the database - list, get, and expire.`,
 }
 
 var documentsListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List all documents",
-	Run: func(_ *cobra.Command, _ []string) {
+	Run: func(cmd *cobra.Command, _ []string) {
 		logger := util.NewLogger(zerolog.ErrorLevel)
 
-		database, err := db.NewConnection()
+		database, err := openDatabase()
 		if err != nil {
 			logger.Fatal().Err(err).Msg("Failed to connect to database")
 		}
@@ -34,9 +37,9 @@ var documentsListCmd = &cobra.Command{
 		query := `
 			SELECT id, source_id, download_id, format, indexed_at, min_chunk_size, max_chunk_size, 
 			published_at, modified_at, wp_version
-			FROM documents ORDER BY indexed_at DESC
+			FROM documents WHERE deleted_at IS NULL ORDER BY indexed_at DESC
 		`
-		rows, err := database.Query(query)
+		rows, err := database.Reader().Query(query)
 		if err != nil {
 			logger.Fatal().Err(err).Msg("Failed to query documents")
 		}
@@ -55,16 +58,18 @@ var documentsListCmd = &cobra.Command{
 			documents = append(documents, doc)
 		}
 
-		if len(documents) == 0 {
-			logger.Info().Msg("No documents found")
-			return
-		}
-
-		jsonOutput, err := json.MarshalIndent(documents, "", "  ")
+		err = printResult(cmd, nonNil(documents), func(w io.Writer) {
+			if len(documents) == 0 {
+				fmt.Fprintln(w, "No documents found")
+			}
+			for _, doc := range documents {
+				fmt.Fprintf(w, "%s  source=%s  format=%s  indexed=%s\n", doc.ID, doc.SourceID,
+					stringOr(doc.Format, "-"), timeOr(doc.IndexedAt, "-"))
+			}
+		})
 		if err != nil {
-			logger.Fatal().Err(err).Msg("Failed to marshal JSON")
+			logger.Fatal().Err(err).Msg("Failed to print documents")
 		}
-		logger.Info().RawJSON("documents", jsonOutput).Msg("Documents retrieved successfully")
 	},
 }
 
@@ -72,10 +77,10 @@ var documentsGetCmd = &cobra.Command{
 	Use:   "get [id]",
 	Short: "Get a document by ID",
 	Args:  cobra.ExactArgs(1),
-	Run: func(_ *cobra.Command, args []string) {
-		logger := util.NewLogger(zerolog.InfoLevel)
+	Run: func(cmd *cobra.Command, args []string) {
+		logger := util.NewLogger(zerolog.ErrorLevel)
 
-		database, err := db.NewConnection()
+		database, err := openDatabase()
 		if err != nil {
 			logger.Fatal().Err(err).Msg("Failed to connect to database")
 		}
@@ -88,9 +93,9 @@ var documentsGetCmd = &cobra.Command{
 		query := `
 			SELECT id, source_id, download_id, format, indexed_at, min_chunk_size, max_chunk_size, 
 			published_at, modified_at, wp_version
-			FROM documents WHERE id = ?
+			FROM documents WHERE id = ? AND deleted_at IS NULL
 		`
-		row := database.QueryRow(query, args[0])
+		row := database.Reader().QueryRow(query, args[0])
 
 		var doc models.Document
 		err = row.Scan(&doc.ID, &doc.SourceID, &doc.DownloadID, &doc.Format, &doc.IndexedAt,
@@ -105,11 +110,43 @@ var documentsGetCmd = &cobra.Command{
 			logger.Fatal().Err(err).Msg("Failed to get document")
 		}
 
-		jsonOutput, err := json.MarshalIndent(doc, "", "  ")
+		// A document has no short form, so both formats print it whole
+		if err := printJSONResult(cmd.OutOrStdout(), doc); err != nil {
+			logger.Fatal().Err(err).Msg("Failed to print document")
+		}
+	},
+}
+
+var documentsExpireCmd = &cobra.Command{
+	Use:   "expire",
+	Short: "Mark stale or purge documents that outlived their source's TTL",
+	Long: `Apply the TTLs set with "sources settings set --ttl". Documents older than their source's TTL,
+counted from when they were published, else indexed, are marked stale, which leaves them out of
+search unless --include-stale is given, or purged when the settings say --expire purge. Stale
+documents whose TTL was since raised or cleared are made searchable again. Run it periodically,
+such as daily from cron, to keep promotions and release announcements from outliving their
+relevance.`,
+	Run: func(cmd *cobra.Command, _ []string) {
+		logger := util.NewLogger(zerolog.ErrorLevel)
+
+		database, err := openDatabase()
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to connect to database")
+		}
+		defer database.Close()
+
+		result, err := repository.NewDocumentRepository(database).Expire(time.Now())
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to expire documents")
+		}
+
+		err = printResult(cmd, result, func(w io.Writer) {
+			fmt.Fprintf(w, "%d marked stale, %d purged, %d searchable again\n", result.Stale, result.Purged,
+				result.Fresh)
+		})
 		if err != nil {
-			logger.Fatal().Err(err).Msg("Failed to marshal JSON")
+			logger.Fatal().Err(err).Msg("Failed to print expiry result")
 		}
-		logger.Info().RawJSON("document", jsonOutput).Str("document_id", args[0]).Msg("Document retrieved successfully")
 	},
 }
 
@@ -117,4 +154,5 @@ func init() {
 	rootCmd.AddCommand(documentsCmd)
 	documentsCmd.AddCommand(documentsListCmd)
 	documentsCmd.AddCommand(documentsGetCmd)
+	documentsCmd.AddCommand(documentsExpireCmd)
 }
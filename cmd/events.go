@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/code-sleuth/ike-go/internal/manager/models"
+	"github.com/code-sleuth/ike-go/internal/manager/repository"
+	"github.com/code-sleuth/ike-go/pkg/util"
+
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+)
+
+// defaultEventLimit is how many events are listed unless --limit is given.
+const defaultEventLimit = 50
+
+var eventsCmd = &cobra.Command{
+	Use:   "events",
+	Short: "Show the log of changes to sources and documents",
+	Long: `Show when sources were imported, documents created, chunks embedded, updates detected by
+webhooks, and sources or documents deleted, most recent first. Each event names its actor (cli, a
+worker ID, or webhook:github / webhook:wordpress) and the job it ran in, if any.
+
+Event types: source_imported, document_created, chunks_embedded, update_detected,
+document_deleted, source_deleted, job_failed.`,
+	Example: `  ike-go events --document 3f6d...
+  ike-go events --source 9b1c... --since 24h
+  ike-go events --type update_detected --limit 10`,
+	Run: func(cmd *cobra.Command, _ []string) {
+		logger := util.NewLogger(zerolog.ErrorLevel)
+
+		sourceID, _ := cmd.Flags().GetString("source")
+		documentID, _ := cmd.Flags().GetString("document")
+		jobID, _ := cmd.Flags().GetString("job")
+		eventType, _ := cmd.Flags().GetString("type")
+		since, _ := cmd.Flags().GetDuration("since")
+		limit, _ := cmd.Flags().GetInt("limit")
+
+		opts := repository.EventListOptions{
+			ListOptions: repository.ListOptions{Limit: limit},
+			Type:        models.EventType(eventType),
+			SourceID:    sourceID,
+			DocumentID:  documentID,
+			JobID:       jobID,
+		}
+		if since > 0 {
+			opts.After = time.Now().Add(-since)
+		}
+
+		database, err := openDatabase()
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to connect to database")
+		}
+		defer database.Close()
+
+		events, err := repository.NewEventRepository(database).List(opts)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to list events")
+		}
+
+		err = printResult(cmd, nonNil(events), func(w io.Writer) {
+			for _, event := range events {
+				fmt.Fprintln(w, formatEvent(event))
+			}
+		})
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to print events")
+		}
+	},
+}
+
+// formatEvent renders event as one line; unset fields are left out.
+func formatEvent(event models.Event) string {
+	fields := []string{event.CreatedAt.Local().Format(time.RFC3339), fmt.Sprintf("%-16s", event.Type)}
+	for _, field := range []struct {
+		name  string
+		value *string
+	}{
+		{"actor", event.Actor},
+		{"job", event.JobID},
+		{"source", event.SourceID},
+		{"document", event.DocumentID},
+	} {
+		if field.value != nil {
+			fields = append(fields, field.name+"="+*field.value)
+		}
+	}
+	if event.Detail != nil {
+		fields = append(fields, " "+*event.Detail)
+	}
+	return strings.Join(fields, "  ")
+}
+
+func init() {
+	rootCmd.AddCommand(eventsCmd)
+
+	eventsCmd.Flags().String("source", "", "Only show events of this source ID")
+	eventsCmd.Flags().String("document", "", "Only show events of this document ID")
+	eventsCmd.Flags().String("job", "", "Only show events of this job ID")
+	eventsCmd.Flags().String("type", "", "Only show events of this type")
+	eventsCmd.Flags().Duration("since", 0, "Only show events from this long ago, e.g. 24h")
+	eventsCmd.Flags().Int("limit", defaultEventLimit, "Maximum number of events to show (0 for all)")
+}
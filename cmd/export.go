@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/code-sleuth/ike-go/internal/manager/export"
+	"github.com/code-sleuth/ike-go/pkg/util"
+
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export documents, chunks, and embeddings",
+	Long: `Export the corpus to JSONL or Parquet files for analytics, backups, or loading into other tools.
+
+Documents, chunks, and embeddings are written to separate files in the --dir directory.
+Use --source, --host, or --collection to export only part of the corpus.`,
+	Example: `  ike-go export --dir ./export
+  ike-go export --dir ./export --format parquet --host github.com`,
+	Run: func(cmd *cobra.Command, _ []string) {
+		logger := util.NewLogger(zerolog.ErrorLevel)
+
+		dir, _ := cmd.Flags().GetString("dir")
+		formatName, _ := cmd.Flags().GetString("format")
+		sourceIDs, _ := cmd.Flags().GetStringSlice("source")
+		host, _ := cmd.Flags().GetString("host")
+		collection, _ := cmd.Flags().GetString("collection")
+
+		format, err := export.ParseFormat(formatName)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Invalid export format")
+		}
+
+		database, err := openDatabase()
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to connect to database")
+		}
+		defer database.Close()
+
+		exporter := export.NewExporter(database)
+		summary, err := exporter.Export(cmd.Context(), dir, export.Options{
+			Format:     format,
+			SourceIDs:  sourceIDs,
+			Host:       host,
+			Collection: collection,
+		})
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to export corpus")
+		}
+
+		err = printResult(cmd, summary, func(w io.Writer) {
+			fmt.Fprintf(w, "Exported %d documents, %d chunks, and %d embeddings to:\n",
+				summary.Documents, summary.Chunks, summary.Embeddings)
+			for _, file := range summary.Files {
+				fmt.Fprintf(w, "  %s\n", file)
+			}
+		})
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to print summary")
+		}
+	},
+}
+
+var exportFineTuneCmd = &cobra.Command{
+	Use:   "finetune",
+	Short: "Export chunks as a fine-tuning dataset",
+	Long: `Export chunks as JSONL fine-tuning examples, so the corpus feeding search can also seed
+fine-tuning datasets. Each chunk becomes the answer to a prompt rendered from --prompt, a Go
+template over the chunk's metadata: {{.SourceURL}}, {{.DocumentID}}, {{.ChunkID}}, {{.Format}},
+and {{.Meta.<key>}} for the document's imported metadata, such as {{.Meta.title}}.
+
+Styles:
+  openai  {"messages": [{"role": "system", ...}, {"role": "user", ...}, {"role": "assistant", ...}]}
+          for OpenAI's fine-tuning API; --system sets the system message.
+  hf      {"prompt": ..., "completion": ...}, or {"text": ...} without --prompt, for Hugging Face
+          datasets and TRL.`,
+	Example: `  ike-go export finetune --file train.jsonl --prompt "Explain {{.Meta.title}} from {{.SourceURL}}"
+  ike-go export finetune --style hf --host github.com --min-tokens 50`,
+	Run: func(cmd *cobra.Command, _ []string) {
+		logger := util.NewLogger(zerolog.ErrorLevel)
+
+		file, _ := cmd.Flags().GetString("file")
+		styleName, _ := cmd.Flags().GetString("style")
+		prompt, _ := cmd.Flags().GetString("prompt")
+		system, _ := cmd.Flags().GetString("system")
+		minTokens, _ := cmd.Flags().GetInt("min-tokens")
+		sourceIDs, _ := cmd.Flags().GetStringSlice("source")
+		host, _ := cmd.Flags().GetString("host")
+		collection, _ := cmd.Flags().GetString("collection")
+
+		style, err := export.ParseFineTuneStyle(styleName)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Invalid fine-tuning style")
+		}
+
+		database, err := openDatabase()
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to connect to database")
+		}
+		defer database.Close()
+
+		summary, err := export.NewExporter(database).ExportFineTune(cmd.Context(), file, export.FineTuneOptions{
+			Style:      style,
+			SourceIDs:  sourceIDs,
+			Host:       host,
+			Collection: collection,
+			Prompt:     prompt,
+			System:     system,
+			MinTokens:  minTokens,
+		})
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to export fine-tuning dataset")
+		}
+
+		err = printResult(cmd, summary, func(w io.Writer) {
+			fmt.Fprintf(w, "Exported %d examples to %s\n", summary.Examples, summary.File)
+		})
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to print summary")
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	exportCmd.AddCommand(exportFineTuneCmd)
+
+	exportCmd.Flags().StringP("dir", "d", "export", "Directory to write the export files to")
+	exportCmd.Flags().String("format", string(export.FormatJSONL), "Export format (jsonl or parquet)")
+	exportCmd.Flags().StringSlice("source", nil, "Only export documents from these source IDs")
+	exportCmd.Flags().String("host", "", "Only export documents from sources on this host")
+	exportCmd.Flags().String("collection", "", "Only export documents from sources in this collection")
+
+	exportFineTuneCmd.Flags().StringP("file", "f", "finetune.jsonl", "File to write the examples to")
+	exportFineTuneCmd.Flags().String("style", string(export.FineTuneOpenAI), "Record layout (openai or hf)")
+	exportFineTuneCmd.Flags().String("prompt", "", "Go template of the prompt each chunk answers (default none)")
+	exportFineTuneCmd.Flags().String("system", "", "System message of openai examples")
+	exportFineTuneCmd.Flags().Int("min-tokens", 0, "Skip chunks with fewer tokens than this")
+	exportFineTuneCmd.Flags().StringSlice("source", nil, "Only export chunks of documents from these source IDs")
+	exportFineTuneCmd.Flags().String("host", "", "Only export chunks of documents from sources on this host")
+	exportFineTuneCmd.Flags().String("collection", "",
+		"Only export chunks of documents from sources in this collection")
+}
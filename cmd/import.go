@@ -4,44 +4,106 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/code-sleuth/ike-go/internal/manager/chunkers"
-	"github.com/code-sleuth/ike-go/internal/manager/embedders"
 	"github.com/code-sleuth/ike-go/internal/manager/importers"
 	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
+	"github.com/code-sleuth/ike-go/internal/manager/repository"
 	"github.com/code-sleuth/ike-go/internal/manager/services"
 	"github.com/code-sleuth/ike-go/internal/manager/transformers"
 	"github.com/code-sleuth/ike-go/pkg/db"
+	"github.com/code-sleuth/ike-go/pkg/ikego"
 	"github.com/code-sleuth/ike-go/pkg/util"
 	"github.com/rs/zerolog"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
-var ErrUnsupportedEmbeddingModel = errors.New("unsupported embedding model")
+var ErrUnsupportedEmbeddingModel = ikego.ErrUnsupportedEmbeddingModel
+
+// Defaults of the --tokens, --concurrency, and --timeout flags of import and transform.
+const (
+	defaultMaxTokens   = 8191
+	defaultConcurrency = 5
+	defaultTimeout     = 5 * time.Minute
+)
 
 var (
-	sourceURL      string
-	embeddingModel string
-	chunkStrategy  string
-	maxTokens      int
-	concurrency    int
-	timeout        time.Duration
+	sourceURLs       []string
+	parallel         int
+	jobPriority      string
+	shutdownTimeout  time.Duration
+	embeddingModel   string
+	chunkStrategy    string
+	maxTokens        int
+	concurrency      int
+	timeout          time.Duration
+	importTimeout    time.Duration
+	transformTimeout time.Duration
+	embedTimeout     time.Duration
+	embedBatchSize   int
+	embedBatchTokens int
+	collection       string
+	owners           []string
+	commitAuthors    bool
+	restart          bool
+	force            bool
+	queueImport      bool
+	maxRetries       int
+	showProgress     bool
+	rateLimit        float64
+	rateBurst        int
 )
 
 // importCmd represents the import command.
 var importCmd = &cobra.Command{
 	Use:   "import",
 	Short: "Import content from external sources",
-	Long: `Import content from external sources like WordPress JSON API endpoints or GitHub repositories.
+	Long: `Import content from external sources like WordPress JSON API endpoints or GitHub repositories,
+or from JSONL and CSV dumps of content other tools already extracted.
 	
 Examples:
   # Import from WordPress JSON API
   ike-go import --url "https://wsform.com/wp-json/wp/v2/knowledgebase"
   
+  # Import drafts too from a WordPress site, with WORDPRESS_USERNAME and WORDPRESS_APP_PASSWORD set
+  ike-go import --url "https://staging.example.com/wp-json/wp/v2/posts?status=draft,publish"
+
+  # Import posts with their author, featured image, and term names embedded
+  ike-go import --url "https://example.com/wp-json/wp/v2/posts?_embed"
+
   # Import from GitHub repository
   ike-go import --url "https://github.com/owner/repo" --model "text-embedding-3-small"
   
+  # Import a JSONL or CSV dump with url, title, body, and published_at fields
+  ike-go import --url "file:///data/export.jsonl"
+
+  # Import a CSV dump whose columns are named differently
+  ike-go import --url "file:///data/posts.csv?url=link&title=name&body=content&published_at=date"
+
+  # Import several sources, two at a time
+  ike-go import --url "https://github.com/owner/docs" --url "https://example.com/wp-json/wp/v2/posts"
+
+  # Import into a named collection
+  ike-go import --url "https://github.com/owner/docs" --collection product-docs
+
+  # Record the team owning the imported content, and each file's last commit author
+  ike-go import --url "https://github.com/owner/docs" --owner docs-team --github-commit-authors
+
+  # Re-import from scratch instead of resuming an interrupted run
+  ike-go import --url "https://github.com/owner/repo" --restart
+
+  # Queue the import for "ike-go worker" processes
+  ike-go import --url "https://github.com/owner/repo" --queue
+
+  # Queue an import ahead of scheduled re-imports
+  ike-go import --url "https://github.com/owner/repo" --queue --priority high
+
   # Import with custom settings
   ike-go import --url "https://example.com/wp-json/wp/v2/posts" --tokens 4096 --concurrency 10`,
 	Run: runImport,
@@ -49,20 +111,55 @@ Examples:
 
 func init() {
 	rootCmd.AddCommand(importCmd)
-	var (
-		maxTokens   = 8191
-		concurrency = 5
-		timeout     = 5 * time.Minute
-	)
 
 	// Add flags
-	importCmd.Flags().StringVarP(&sourceURL, "url", "u", "", "Source URL to import from (required)")
+	importCmd.Flags().
+		StringArrayVarP(&sourceURLs, "url", "u", nil, "Source URL to import from; repeat to import several (required)")
+	importCmd.Flags().IntVar(&parallel, "parallel", 2, "Number of sources imported at once when several are given")
 	importCmd.Flags().StringVarP(&embeddingModel, "model", "m", "text-embedding-3-small", "Embedding model to use")
 	importCmd.Flags().
 		StringVarP(&chunkStrategy, "strategy", "s", "token", "Chunking strategy (token, heading, recursive)")
-	importCmd.Flags().IntVarP(&maxTokens, "tokens", "t", maxTokens, "Maximum tokens per chunk")
-	importCmd.Flags().IntVarP(&concurrency, "concurrency", "c", concurrency, "Number of concurrent operations")
-	importCmd.Flags().DurationVar(&timeout, "timeout", timeout, "Timeout for the entire operation")
+	importCmd.Flags().IntVarP(&maxTokens, "tokens", "t", defaultMaxTokens, "Maximum tokens per chunk")
+	importCmd.Flags().IntVarP(&concurrency, "concurrency", "c", defaultConcurrency, "Number of concurrent operations")
+	importCmd.Flags().DurationVar(&timeout, "timeout", defaultTimeout, "Timeout for the entire operation")
+	// --max-tokens is accepted for --tokens, the name ProcessingOptions gives the setting
+	importCmd.Flags().SetNormalizeFunc(func(_ *pflag.FlagSet, name string) pflag.NormalizedName {
+		if name == "max-tokens" {
+			name = "tokens"
+		}
+		return pflag.NormalizedName(name)
+	})
+	importCmd.Flags().DurationVar(&importTimeout, "import-timeout", 0,
+		"Timeout for importing each source (0 for none beyond --timeout)")
+	importCmd.Flags().DurationVar(&transformTimeout, "transform-timeout", 0,
+		"Timeout for transforming each download (0 for none beyond --timeout)")
+	importCmd.Flags().DurationVar(&embedTimeout, "embed-timeout", 0,
+		"Timeout for embedding each chunk or batch of chunks (0 for none beyond --timeout)")
+	importCmd.Flags().IntVar(&embedBatchSize, "embed-batch-size", services.DefaultEmbeddingBatchSize,
+		"Most chunks embedded with one request, for models whose API takes batches (1 disables batching)")
+	importCmd.Flags().IntVar(&embedBatchTokens, "embed-batch-tokens", services.DefaultEmbeddingBatchTokens,
+		"Most tokens embedded with one request (0 for no limit beyond --embed-batch-size)")
+	importCmd.Flags().
+		StringVar(&collection, "collection", interfaces.DefaultCollection, "Collection to place imported sources in")
+	importCmd.Flags().StringArrayVar(&owners, "owner", nil,
+		"Team or person to record as an owner of every imported source (repeatable)")
+	importCmd.Flags().BoolVar(&commitAuthors, "github-commit-authors", false,
+		"Record the author of each GitHub file's last commit as its author email (one request per changed file)")
+	importCmd.Flags().
+		BoolVar(&restart, "restart", false, "Start from the first item instead of resuming an interrupted import")
+	importCmd.Flags().BoolVar(&force, "force", false, "Re-embed documents even when their content is unchanged")
+	importCmd.Flags().IntVar(&maxRetries, "max-retries", importers.DefaultRetryPolicy().MaxRetries,
+		"Retries with exponential backoff for transient HTTP failures (429, 5xx)")
+	importCmd.Flags().Float64Var(&rateLimit, "rate-limit", importers.DefaultRateLimit,
+		"Maximum requests per second to each host (0 disables rate limiting)")
+	importCmd.Flags().IntVar(&rateBurst, "burst", importers.DefaultRateBurst, "Requests allowed back to back per host")
+	importCmd.Flags().BoolVar(&showProgress, "progress", false, "Print import progress to stderr")
+	importCmd.Flags().
+		BoolVar(&queueImport, "queue", false, "Enqueue the import for a worker instead of running it now")
+	importCmd.Flags().DurationVar(&shutdownTimeout, "shutdown-timeout", defaultShutdownTimeout,
+		"How long to keep embedding the current document after SIGINT or SIGTERM before cancelling")
+	importCmd.Flags().StringVar(&jobPriority, "priority", "normal",
+		"Priority of the queued import: high, normal, low, or an integer; higher runs first")
 
 	// Mark required flags
 	err := importCmd.MarkFlagRequired("url")
@@ -71,12 +168,42 @@ func init() {
 	}
 }
 
-func runImport(_ *cobra.Command, _ []string) {
+// importOptions returns the processing options the import flags give.
+func importOptions() *interfaces.ProcessingOptions {
+	return &interfaces.ProcessingOptions{
+		MaxTokens:         maxTokens,
+		ChunkStrategy:     chunkStrategy,
+		EmbeddingModel:    embeddingModel,
+		Concurrency:       concurrency,
+		Timeout:           timeout,
+		ImportTimeout:     importTimeout,
+		TransformTimeout:  transformTimeout,
+		EmbedTimeout:      embedTimeout,
+		Collection:        collection,
+		Owners:            owners,
+		Restart:           restart,
+		Force:             force,
+		SourceConcurrency: parallel,
+	}
+}
+
+func runImport(cmd *cobra.Command, _ []string) {
 	logger := util.NewLogger(zerolog.ErrorLevel)
-	logger.Info().Str("source_url", sourceURL).Msg("Starting import")
+	logger.Info().Strs("source_urls", sourceURLs).Msg("Starting import")
+
+	options := importOptions()
+
+	if queueImport {
+		priority, err := parsePriority(jobPriority)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Invalid priority")
+		}
+		enqueueImport(cmd, logger, options, priority)
+		return
+	}
 
 	// Create context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
 	defer cancel()
 
 	// Connect to database
@@ -87,7 +214,104 @@ func runImport(_ *cobra.Command, _ []string) {
 	defer database.Close()
 
 	// Create processing engine
+	engine := newProcessingEngine(logger)
+
+	// On SIGINT or SIGTERM finish the current document and leave the rest of the run to resume
+	signals, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go shutdownOnDone(signals, engine, shutdownTimeout, logger)
+
+	if showProgress {
+		var stopProgress func()
+		options.Progress, stopProgress = startProgress(os.Stderr)
+		defer stopProgress()
+	}
+
+	// Run the import
+	if len(sourceURLs) == 1 {
+		start := time.Now()
+		err := engine.ProcessSource(ctx, sourceURLs[0], options, database)
+		if jsonOutput(cmd) {
+			// A single import prints nothing for people, but scripts get the same report as a batch
+			report := &interfaces.BatchReport{Results: []interfaces.SourceResult{
+				{SourceURL: sourceURLs[0], Err: err, Duration: time.Since(start)},
+			}}
+			if err != nil {
+				report.Failed = 1
+			} else {
+				report.Succeeded = 1
+			}
+			printBatchReport(cmd, logger, report)
+		}
+		if err != nil {
+			importFailed(logger, err)
+		}
+	} else {
+		report, err := engine.ProcessSources(ctx, sourceURLs, options, database)
+		printBatchReport(cmd, logger, report)
+		if err != nil {
+			importFailed(logger, err)
+		}
+	}
+
+	logger.Info().Msg("Import completed successfully!")
+}
+
+// importFailed exits with err, pointing out that an import stopped by a signal can be resumed.
+func importFailed(logger zerolog.Logger, err error) {
+	if errors.Is(err, services.ErrShuttingDown) {
+		logger.Fatal().Err(err).Msg("Import interrupted; run the same command again to resume it")
+	}
+	logger.Fatal().Err(err).Msg("Import failed")
+}
+
+// importResult is one source of an import as printed with --output json.
+type importResult struct {
+	SourceURL  string `json:"source_url"`
+	Error      string `json:"error,omitempty"`
+	DurationMS int64  `json:"duration_ms"`
+}
+
+// importReport is an import as printed with --output json.
+type importReport struct {
+	Results   []importResult `json:"results"`
+	Succeeded int            `json:"succeeded"`
+	Failed    int            `json:"failed"`
+}
+
+// printBatchReport prints the outcome of each source of an import, one line per source for people.
+func printBatchReport(cmd *cobra.Command, logger zerolog.Logger, report *interfaces.BatchReport) {
+	output := importReport{Results: []importResult{}, Succeeded: report.Succeeded, Failed: report.Failed}
+	for _, result := range report.Results {
+		entry := importResult{SourceURL: result.SourceURL, DurationMS: result.Duration.Milliseconds()}
+		if result.Err != nil {
+			entry.Error = result.Err.Error()
+		}
+		output.Results = append(output.Results, entry)
+	}
+
+	err := printResult(cmd, output, func(w io.Writer) {
+		for _, result := range report.Results {
+			status := "ok"
+			if result.Err != nil {
+				status = "failed: " + result.Err.Error()
+			}
+			fmt.Fprintf(w, "%s  %s  (%s)\n", result.SourceURL, status, result.Duration.Round(time.Millisecond))
+		}
+		fmt.Fprintf(w, "%d succeeded, %d failed\n", report.Succeeded, report.Failed)
+	})
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to print import report")
+	}
+}
+
+// newProcessingEngine returns an engine with every importer, transformer, chunker, and embedder
+// registered.
+func newProcessingEngine(logger zerolog.Logger) *services.ProcessingEngine {
 	engine := services.NewProcessingEngine()
+	if embedBatchSize > 0 {
+		engine.SetEmbeddingBatch(embedBatchSize, embedBatchTokens)
+	}
 
 	// Register importers
 	if err := registerImporters(engine); err != nil {
@@ -99,47 +323,92 @@ func runImport(_ *cobra.Command, _ []string) {
 		logger.Fatal().Err(err).Msg("Failed to register transformers")
 	}
 
+	// Register importers and transformers provided by plugins
+	if err := registerPlugins(engine); err != nil {
+		logger.Fatal().Err(err).Msg("Failed to register plugins")
+	}
+
 	// Register chunkers
 	if err := registerChunkers(engine); err != nil {
 		logger.Fatal().Err(err).Msg("Failed to register chunkers")
 	}
 
-	// Register embedders
+	// Register embedders; models named by source settings or queued jobs are created on first use
 	if err := registerEmbedders(engine); err != nil {
 		logger.Fatal().Err(err).Msg("Failed to register embedders")
 	}
+	engine.SetEmbedderFactory(newEmbedder)
+
+	return engine
+}
 
-	// Configure processing options
-	options := &interfaces.ProcessingOptions{
-		MaxTokens:      maxTokens,
-		ChunkStrategy:  chunkStrategy,
-		EmbeddingModel: embeddingModel,
-		Concurrency:    concurrency,
-		Timeout:        timeout,
+// enqueueImport adds a job per source URL to the queue for workers to run and prints the job IDs.
+func enqueueImport(cmd *cobra.Command, logger zerolog.Logger, options *interfaces.ProcessingOptions, priority int) {
+	database, err := openDatabase()
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Failed to connect to database")
 	}
+	defer database.Close()
 
-	// Run the import
-	if err := engine.ProcessSource(ctx, sourceURL, options, database); err != nil {
-		logger.Fatal().Err(err).Msg("Import failed")
+	queue := repository.NewJobRepository(database)
+	jobIDs := []string{}
+	for _, url := range sourceURLs {
+		job, err := services.NewImportJob(url, options)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to create import job")
+		}
+		job.Priority = priority
+		if err := queue.Enqueue(job); err != nil {
+			logger.Fatal().Err(err).Msg("Failed to enqueue import")
+		}
+		jobIDs = append(jobIDs, job.ID)
 	}
 
-	logger.Info().Msg("Import completed successfully!")
+	err = printResult(cmd, map[string][]string{"jobs": jobIDs}, func(w io.Writer) {
+		for _, id := range jobIDs {
+			fmt.Fprintln(w, id)
+		}
+	})
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Failed to print job IDs")
+	}
 }
 
 func registerImporters(engine *services.ProcessingEngine) error {
+	retryPolicy := importers.DefaultRetryPolicy()
+	retryPolicy.MaxRetries = maxRetries
+	// One limiter is shared by all importers so requests to a host draw on a single budget
+	options := []importers.Option{
+		importers.WithConcurrency(concurrency),
+		importers.WithRetryPolicy(retryPolicy),
+		importers.WithRateLimiter(importers.NewHostLimiter(rateLimit, rateBurst)),
+	}
+
 	// Register WP-JSON importer
-	wpImporter := importers.NewWPJSONImporter()
-	wpImporter.SetConcurrency(concurrency)
+	wordpress := appConfig.WordPress
+	wpImporter := importers.NewWPJSONImporter(append(options, importers.WithAuth(importers.WPAuth{
+		Username:    wordpress.Username,
+		AppPassword: wordpress.AppPassword,
+		Token:       wordpress.Token,
+		Headers:     wordpress.Headers,
+		Hosts:       wordpress.Hosts,
+	}))...)
 	if err := engine.RegisterImporter(wpImporter); err != nil {
 		return fmt.Errorf("failed to register WP-JSON importer: %w", err)
 	}
 
 	// Register GitHub importer
-	githubImporter := importers.NewGitHubImporter()
+	githubImporter := importers.NewGitHubImporter(append(options,
+		importers.WithToken(appConfig.GitHubToken), importers.WithCommitAuthors(commitAuthors))...)
 	if err := engine.RegisterImporter(githubImporter); err != nil {
 		return fmt.Errorf("failed to register GitHub importer: %w", err)
 	}
 
+	// Register JSONL/CSV dump importer
+	if err := engine.RegisterImporter(importers.NewDumpImporter()); err != nil {
+		return fmt.Errorf("failed to register dump importer: %w", err)
+	}
+
 	return nil
 }
 
@@ -156,12 +425,17 @@ func registerTransformers(engine *services.ProcessingEngine) error {
 		return fmt.Errorf("failed to register GitHub transformer: %w", err)
 	}
 
+	// Register JSONL/CSV dump transformer
+	if err := engine.RegisterTransformer(transformers.NewDumpTransformer()); err != nil {
+		return fmt.Errorf("failed to register dump transformer: %w", err)
+	}
+
 	return nil
 }
 
 func registerChunkers(engine *services.ProcessingEngine) error {
 	// Register token chunker
-	tokenChunker, err := chunkers.NewTokenChunker()
+	tokenChunker, err := chunkers.NewTokenChunker(chunkers.WithTokenizerName(appConfig.ChunkerTokenizer))
 	if err != nil {
 		return fmt.Errorf("failed to create token chunker: %w", err)
 	}
@@ -173,29 +447,18 @@ func registerChunkers(engine *services.ProcessingEngine) error {
 }
 
 func registerEmbedders(engine *services.ProcessingEngine) error {
-	// Determine which embedder to use based on model
-	switch embeddingModel {
-	case "text-embedding-3-small", "text-embedding-3-large", "text-embedding-ada-002":
-		// Register OpenAI embedder
-		openaiEmbedder, err := embedders.NewOpenAIEmbedder(embeddingModel)
-		if err != nil {
-			return fmt.Errorf("failed to create OpenAI embedder: %w", err)
-		}
-		if err := engine.RegisterEmbedder(openaiEmbedder); err != nil {
-			return fmt.Errorf("failed to register OpenAI embedder: %w", err)
-		}
-	case "togethercomputer/m2-bert-80M-8k-retrieval", "togethercomputer/m2-bert-80M-32k-retrieval":
-		// Register Together AI embedder
-		togetherEmbedder, err := embedders.NewTogetherAIEmbedder(embeddingModel)
-		if err != nil {
-			return fmt.Errorf("failed to create Together AI embedder: %w", err)
-		}
-		if err := engine.RegisterEmbedder(togetherEmbedder); err != nil {
-			return fmt.Errorf("failed to register Together AI embedder: %w", err)
-		}
-	default:
-		return fmt.Errorf("%w: %s", ErrUnsupportedEmbeddingModel, embeddingModel)
+	embedder, err := newEmbedder(embeddingModel)
+	if err != nil {
+		return err
+	}
+	if err := engine.RegisterEmbedder(embedder); err != nil {
+		return fmt.Errorf("failed to register embedder: %w", err)
 	}
 
 	return nil
 }
+
+// newEmbedder creates the embedder for the given model name.
+func newEmbedder(model string) (interfaces.Embedder, error) {
+	return ikego.NewEmbedder(model, appConfig)
+}
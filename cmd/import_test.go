@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// parseFlags parses args as cmd's flags, restoring every flag to its default when the test ends.
+func parseFlags(t *testing.T, cmd *cobra.Command, args ...string) {
+	t.Helper()
+	t.Cleanup(func() {
+		cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+			if slice, ok := flag.Value.(pflag.SliceValue); ok {
+				_ = slice.Replace(nil)
+			} else {
+				_ = flag.Value.Set(flag.DefValue)
+			}
+			flag.Changed = false
+		})
+	})
+	if err := cmd.Flags().Parse(args); err != nil {
+		t.Fatalf("Failed to parse flags %v: %v", args, err)
+	}
+}
+
+func TestImportOptions(t *testing.T) {
+	tests := []struct {
+		name                string
+		args                []string
+		expectedMaxTokens   int
+		expectedConcurrency int
+		expectedTimeout     time.Duration
+	}{
+		{
+			name:                "defaults",
+			args:                []string{"--url", "https://github.com/owner/repo"},
+			expectedMaxTokens:   defaultMaxTokens,
+			expectedConcurrency: defaultConcurrency,
+			expectedTimeout:     defaultTimeout,
+		},
+		{
+			name: "flags",
+			args: []string{"--url", "https://github.com/owner/repo", "--timeout", "30m", "--max-tokens", "512",
+				"--concurrency", "8"},
+			expectedMaxTokens:   512,
+			expectedConcurrency: 8,
+			expectedTimeout:     30 * time.Minute,
+		},
+		{
+			name:                "short flags",
+			args:                []string{"-u", "https://github.com/owner/repo", "-t", "256", "-c", "2"},
+			expectedMaxTokens:   256,
+			expectedConcurrency: 2,
+			expectedTimeout:     defaultTimeout,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parseFlags(t, importCmd, tt.args...)
+
+			options := importOptions()
+			if options.MaxTokens != tt.expectedMaxTokens {
+				t.Errorf("Expected MaxTokens %d, got %d", tt.expectedMaxTokens, options.MaxTokens)
+			}
+			if options.Concurrency != tt.expectedConcurrency {
+				t.Errorf("Expected Concurrency %d, got %d", tt.expectedConcurrency, options.Concurrency)
+			}
+			if options.Timeout != tt.expectedTimeout {
+				t.Errorf("Expected Timeout %v, got %v", tt.expectedTimeout, options.Timeout)
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/code-sleuth/ike-go/pkg/metrics"
+
+	"github.com/rs/zerolog"
+)
+
+// serveMetrics serves GET /metrics on addr for commands that run the engine outside "ike-go
+// serve", and returns a function that stops the server. An empty addr serves nothing.
+func serveMetrics(addr string, logger zerolog.Logger) func() {
+	if addr == "" {
+		return func() {}
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", metrics.Handler())
+	srv := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			logger.Error().Err(err).Str("addr", addr).Msg("Metrics server failed")
+		}
+	}()
+	return func() { _ = srv.Close() }
+}
@@ -1,24 +1,38 @@
 package cmd
 
 import (
+	"context"
+	"fmt"
 	"io"
-	"os"
+	"strings"
 
+	"github.com/code-sleuth/ike-go/internal/manager/repository"
+	"github.com/code-sleuth/ike-go/pkg/compression"
 	"github.com/code-sleuth/ike-go/pkg/db"
+	"github.com/code-sleuth/ike-go/pkg/migrations"
 	"github.com/code-sleuth/ike-go/pkg/util"
 
 	"github.com/rs/zerolog"
 	"github.com/spf13/cobra"
 )
 
+var (
+	compressBodies    bool
+	convertEmbeddings bool
+)
+
 var migrateCmd = &cobra.Command{
-	Use:   "migrate",
-	Short: "Run database migrations",
-	Long:  `Run database migrations to set up the schema in your Turso database.`,
-	Run: func(_ *cobra.Command, _ []string) {
+	Use:         "migrate",
+	Short:       "Run database migrations",
+	Annotations: map[string]string{skipSchemaCheck: "true"},
+	Long: `Run database migrations to set up the schema in your Turso database.
+
+Use --compress-bodies after upgrading to gzip the bodies of downloads stored before compression was enabled,
+and --convert-embeddings to re-encode text-formatted embedding vectors as binary BLOBs.`,
+	Run: func(cmd *cobra.Command, _ []string) {
 		logger := util.NewLogger(zerolog.ErrorLevel)
 
-		database, err := db.NewConnection()
+		database, err := openDatabase()
 		if err != nil {
 			logger.Fatal().Err(err).Msg("Failed to connect to database")
 		}
@@ -28,31 +42,70 @@ var migrateCmd = &cobra.Command{
 			}
 		}(database)
 
-		migrationFile := "pkg/migrations/init_schema.sql"
-		content, err := os.Open(migrationFile)
+		applied, err := migrations.Apply(context.Background(), database.DB)
 		if err != nil {
-			logger.Fatal().Err(err).Str("migration_file", migrationFile).Msg("Failed to open migration file")
+			logger.Fatal().Err(err).Strs("applied", applied).Msg("Failed to execute migration")
 		}
-		defer func(content *os.File) {
-			if err := content.Close(); err != nil {
-				logger.Error().Err(err).Msg("Failed to close migration file")
-			}
-		}(content)
+		result := migrateResult{Applied: nonNil(applied)}
 
-		sqlBytes, err := io.ReadAll(content)
+		// Sources recorded before the current URL normalization may now share a URL
+		result.Merged, err = repository.NewSourceRepository(database).MergeDuplicates()
 		if err != nil {
-			logger.Fatal().Err(err).Msg("Failed to read migration file")
+			logger.Fatal().Err(err).Msg("Failed to merge duplicate sources")
 		}
 
-		_, err = database.Exec(string(sqlBytes))
-		if err != nil {
-			logger.Fatal().Err(err).Msg("Failed to execute migration")
+		if compressBodies {
+			repo := repository.NewDownloadRepository(database)
+			count, err := repo.CompressBodies(compression.DefaultMinSize)
+			if err != nil {
+				logger.Fatal().Err(err).Int("compressed", count).Msg("Failed to compress download bodies")
+			}
+			result.Compressed = &count
+		}
+
+		if convertEmbeddings {
+			repo := repository.NewEmbeddingRepository(database)
+			count, err := repo.ConvertLegacyVectors()
+			if err != nil {
+				logger.Fatal().Err(err).Int("converted", count).Msg("Failed to convert embeddings")
+			}
+			result.Converted = &count
 		}
 
-		logger.Info().Msg("Database migration completed successfully!")
+		err = printResult(cmd, result, func(w io.Writer) {
+			if len(applied) == 0 {
+				fmt.Fprintln(w, "Database schema is up to date")
+			} else {
+				fmt.Fprintf(w, "Applied migrations: %s\n", strings.Join(applied, ", "))
+			}
+			if result.Merged > 0 {
+				fmt.Fprintf(w, "Merged %d duplicate sources\n", result.Merged)
+			}
+			if result.Compressed != nil {
+				fmt.Fprintf(w, "Compressed %d download bodies\n", *result.Compressed)
+			}
+			if result.Converted != nil {
+				fmt.Fprintf(w, "Converted %d embeddings\n", *result.Converted)
+			}
+		})
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to print result")
+		}
 	},
 }
 
+// migrateResult is what migrate did. Compressed and Converted are only set when requested.
+type migrateResult struct {
+	Applied    []string `json:"applied"`
+	Merged     int      `json:"merged"`
+	Compressed *int     `json:"compressed,omitempty"`
+	Converted  *int     `json:"converted,omitempty"`
+}
+
 func init() {
 	rootCmd.AddCommand(migrateCmd)
+
+	migrateCmd.Flags().BoolVar(&compressBodies, "compress-bodies", false, "Compress existing download bodies")
+	migrateCmd.Flags().
+		BoolVar(&convertEmbeddings, "convert-embeddings", false, "Re-encode text embeddings as binary BLOBs")
 }
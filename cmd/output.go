@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+)
+
+// Formats accepted by the global --output flag.
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+var ErrInvalidOutput = errors.New("invalid output format")
+
+// checkOutput rejects --output values other than table and json.
+func checkOutput(cmd *cobra.Command) error {
+	output, _ := cmd.Flags().GetString("output")
+	if output != outputTable && output != outputJSON {
+		return fmt.Errorf("%w: %q, expected %s or %s", ErrInvalidOutput, output, outputTable, outputJSON)
+	}
+	return nil
+}
+
+// jsonOutput reports whether cmd prints JSON, either because of --output json or the --json flag
+// some commands had before --output existed.
+func jsonOutput(cmd *cobra.Command) bool {
+	if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+		return true
+	}
+	output, _ := cmd.Flags().GetString("output")
+	return output == outputJSON
+}
+
+// printResult writes v to cmd's standard output as indented JSON when cmd prints JSON, and
+// otherwise leaves the human-readable rendering to table. Logs and errors never go to standard
+// output in JSON mode, so the result can be piped straight into jq.
+func printResult(cmd *cobra.Command, v interface{}, table func(w io.Writer)) error {
+	w := cmd.OutOrStdout()
+	if !jsonOutput(cmd) {
+		table(w)
+		return nil
+	}
+	return printJSONResult(w, v)
+}
+
+// actionResult is what commands that change something without producing a record print with
+// --output json.
+type actionResult struct {
+	ID     string `json:"id"`
+	Action string `json:"action"`
+}
+
+// printAction reports that action was done to the object with id: as an actionResult in JSON,
+// and as message, if any, for people.
+func printAction(cmd *cobra.Command, logger zerolog.Logger, id, action, message string) {
+	err := printResult(cmd, actionResult{ID: id, Action: action}, func(w io.Writer) {
+		if message != "" {
+			fmt.Fprintln(w, message)
+		}
+	})
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Failed to print result")
+	}
+}
+
+// nonNil returns s, or an empty slice when s is nil, so empty lists print as [] rather than null.
+func nonNil[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
+
+// printJSONResult writes v to w as indented JSON.
+func printJSONResult(w io.Writer, v interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/code-sleuth/ike-go/internal/manager/repository"
+
+	"github.com/spf13/cobra"
+)
+
+// outputCommand returns a command with the global --output flag and the legacy --json flag,
+// writing its standard output to out.
+func outputCommand(out io.Writer) *cobra.Command {
+	cmd := &cobra.Command{Use: "test"}
+	cmd.Flags().StringP("output", "o", outputTable, "")
+	cmd.Flags().Bool("json", false, "")
+	cmd.SetOut(out)
+	return cmd
+}
+
+func TestPrintResult(t *testing.T) {
+	owners := []repository.OwnerSummary{
+		{Owner: "docs-team", Sources: 2, Documents: 5, Chunks: 40},
+		{Owner: "@octocat", Sources: 1, Documents: 1, Chunks: 3},
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		owners   []repository.OwnerSummary
+		expected string
+	}{
+		{
+			name:   "table",
+			args:   nil,
+			owners: owners,
+			expected: "docs-team  sources=2 documents=5 chunks=40\n" +
+				"@octocat  sources=1 documents=1 chunks=3\n",
+		},
+		{
+			name:     "empty table",
+			args:     []string{"--output", "table"},
+			owners:   nil,
+			expected: "",
+		},
+		{
+			name:   "json",
+			args:   []string{"--output", "json"},
+			owners: owners[:1],
+			expected: "[\n" +
+				"  {\n" +
+				"    \"owner\": \"docs-team\",\n" +
+				"    \"sources\": 2,\n" +
+				"    \"documents\": 5,\n" +
+				"    \"chunks\": 40\n" +
+				"  }\n" +
+				"]\n",
+		},
+		{
+			name:     "empty json",
+			args:     []string{"-o", "json"},
+			owners:   nil,
+			expected: "[]\n",
+		},
+		{
+			name:     "legacy json flag",
+			args:     []string{"--json"},
+			owners:   nil,
+			expected: "[]\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			cmd := outputCommand(&out)
+			if err := cmd.Flags().Parse(tt.args); err != nil {
+				t.Fatalf("Failed to parse flags %v: %v", tt.args, err)
+			}
+
+			err := printResult(cmd, nonNil(tt.owners), func(w io.Writer) {
+				for _, owner := range tt.owners {
+					fmt.Fprintf(w, "%s  sources=%d documents=%d chunks=%d\n", owner.Owner, owner.Sources,
+						owner.Documents, owner.Chunks)
+				}
+			})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if out.String() != tt.expected {
+				t.Errorf("Expected output %q, got %q", tt.expected, out.String())
+			}
+		})
+	}
+}
+
+func TestCheckOutput(t *testing.T) {
+	tests := []struct {
+		name        string
+		output      string
+		expectedErr error
+	}{
+		{name: "table", output: outputTable},
+		{name: "json", output: outputJSON},
+		{name: "unknown", output: "yaml", expectedErr: ErrInvalidOutput},
+		{name: "empty", output: "", expectedErr: ErrInvalidOutput},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := outputCommand(io.Discard)
+			if err := cmd.Flags().Set("output", tt.output); err != nil {
+				t.Fatalf("Failed to set output: %v", err)
+			}
+
+			err := checkOutput(cmd)
+			if !errors.Is(err, tt.expectedErr) {
+				t.Errorf("Expected error %v, got %v", tt.expectedErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/code-sleuth/ike-go/internal/manager/repository"
+	"github.com/code-sleuth/ike-go/pkg/util"
+
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+)
+
+var ownersCmd = &cobra.Command{
+	Use:   "owners",
+	Short: "Manage who owns sources",
+	Long: `Manage the teams and people recorded as owning sources. Imports record the owners a
+repository's CODEOWNERS names for each file, the authors of WordPress posts fetched with _embed,
+and any owner given with import --owner. sources list --owner <owner> lists what an owner owns,
+and search --filter owner=<owner> searches only their content.`,
+}
+
+var ownersListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List owners with the sources, documents, and chunks they own",
+	Run: func(cmd *cobra.Command, _ []string) {
+		logger := util.NewLogger(zerolog.ErrorLevel)
+		database, err := openDatabase()
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to connect to database")
+		}
+		defer database.Close()
+
+		owners, err := repository.NewOwnerRepository(database).List()
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to list owners")
+		}
+
+		err = printResult(cmd, nonNil(owners), func(w io.Writer) {
+			for _, owner := range owners {
+				fmt.Fprintf(w, "%s  sources=%d documents=%d chunks=%d\n", owner.Owner, owner.Sources,
+					owner.Documents, owner.Chunks)
+			}
+		})
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to print owners")
+		}
+	},
+}
+
+var ownersAddCmd = &cobra.Command{
+	Use:   "add [source-id] [owner]",
+	Short: "Record a team or person as an owner of a source",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		logger := util.NewLogger(zerolog.ErrorLevel)
+		database, err := openDatabase()
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to connect to database")
+		}
+		defer database.Close()
+
+		if _, err := repository.NewSourceRepository(database).GetByID(args[0]); err != nil {
+			logger.Fatal().Err(err).Msg("Failed to get source")
+		}
+		if err := repository.NewOwnerRepository(database).Add(args[0], args[1]); err != nil {
+			logger.Fatal().Err(err).Msg("Failed to add owner")
+		}
+
+		printAction(cmd, logger, args[0], "added", fmt.Sprintf("Owner %s added to source %s", args[1], args[0]))
+	},
+}
+
+var ownersRemoveCmd = &cobra.Command{
+	Use:   "remove [source-id] [owner]",
+	Short: "Stop a team or person owning a source",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		logger := util.NewLogger(zerolog.ErrorLevel)
+		database, err := openDatabase()
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to connect to database")
+		}
+		defer database.Close()
+
+		if err := repository.NewOwnerRepository(database).Remove(args[0], args[1]); err != nil {
+			logger.Fatal().Err(err).Msg("Failed to remove owner")
+		}
+
+		printAction(cmd, logger, args[0], "removed",
+			fmt.Sprintf("Owner %s removed from source %s", args[1], args[0]))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(ownersCmd)
+	ownersCmd.AddCommand(ownersListCmd)
+	ownersCmd.AddCommand(ownersAddCmd)
+	ownersCmd.AddCommand(ownersRemoveCmd)
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/code-sleuth/ike-go/internal/manager/importers"
+	"github.com/code-sleuth/ike-go/internal/manager/plugins"
+	"github.com/code-sleuth/ike-go/internal/manager/services"
+	"github.com/code-sleuth/ike-go/internal/manager/transformers"
+	"github.com/code-sleuth/ike-go/pkg/util"
+
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+)
+
+// pluginDirEnv names the directory importer and transformer plugins are loaded from.
+const pluginDirEnv = "IKE_PLUGIN_DIR"
+
+var pluginsCmd = &cobra.Command{
+	Use:   "plugins",
+	Short: "Inspect importer and transformer plugins",
+	Long: `Plugins add importers and transformers for sources ike-go does not support itself. Every
+executable in the directory named by ` + pluginDirEnv + ` is run once at startup to describe itself,
+then again for each import or transform it handles. See the plugins package documentation for the
+JSON-RPC contract.`,
+}
+
+var pluginsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the plugins found in " + pluginDirEnv,
+	Run: func(cmd *cobra.Command, _ []string) {
+		logger := util.NewLogger(zerolog.ErrorLevel)
+
+		found, err := plugins.Discover(cmd.Context(), appConfig.PluginDir)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to load plugins")
+		}
+
+		err = printResult(cmd, nonNil(found), func(w io.Writer) {
+			for _, plugin := range found {
+				var provides []string
+				if plugin.Manifest.Importer {
+					provides = append(provides, "importer")
+				}
+				if plugin.Manifest.Transformer {
+					provides = append(provides, "transformer")
+				}
+				fmt.Fprintf(w, "%s  type=%s  provides=%s  urls=%s  %s\n", plugin.Manifest.Name,
+					plugin.Manifest.SourceType, strings.Join(provides, ","), plugin.Manifest.URLPattern, plugin.Path)
+			}
+		})
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to print plugins")
+		}
+	},
+}
+
+// registerPlugins registers the importers and transformers of the plugins in IKE_PLUGIN_DIR. A
+// plugin's transformer is registered even when it only provides an importer, so its downloads are
+// embedded as they are.
+func registerPlugins(engine *services.ProcessingEngine) error {
+	dir := appConfig.PluginDir
+	if dir == "" {
+		return nil
+	}
+
+	found, err := plugins.Discover(context.Background(), dir)
+	if err != nil {
+		return err
+	}
+
+	for _, plugin := range found {
+		if plugin.Manifest.Importer {
+			if err := engine.RegisterImporter(importers.NewPluginImporter(plugin)); err != nil {
+				return fmt.Errorf("failed to register plugin %s: %w", plugin.Manifest.Name, err)
+			}
+		}
+		if err := engine.RegisterTransformer(transformers.NewPluginTransformer(plugin)); err != nil {
+			return fmt.Errorf("failed to register plugin %s: %w", plugin.Manifest.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(pluginsCmd)
+	pluginsCmd.AddCommand(pluginsListCmd)
+}
@@ -0,0 +1,197 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
+)
+
+const (
+	// progressInterval is how often the progress bars are redrawn.
+	progressInterval = 500 * time.Millisecond
+	// progressBarWidth is the number of cells in each progress bar.
+	progressBarWidth = 20
+	// progressSourceWidth is how much of each source URL is shown beside its bar.
+	progressSourceWidth = 40
+	// maxProgressRows caps the sources drawn at once; earlier ones only count towards the totals.
+	maxProgressRows = 10
+)
+
+// startProgress reports import progress to w until the returned function is called. On a
+// terminal it draws a bar per source and a line of totals, redrawn in place; otherwise, such as in
+// CI logs, it writes each event as a line of JSON.
+func startProgress(w io.Writer) (interfaces.ProgressFunc, func()) {
+	if !isTerminal(w) {
+		return newProgressEvents(w).Report, func() {}
+	}
+
+	bars := newProgressBars()
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(progressInterval)
+		defer ticker.Stop()
+
+		drawn := 0
+		for {
+			select {
+			case <-done:
+				bars.draw(w, drawn)
+				return
+			case <-ticker.C:
+				if bars.changed() {
+					drawn = bars.draw(w, drawn)
+				}
+			}
+		}
+	}()
+
+	return bars.Report, func() {
+		close(done)
+		<-stopped
+	}
+}
+
+// progressEvent is the line of JSON written per progress event when stderr isn't a terminal.
+type progressEvent struct {
+	Time   time.Time `json:"time"`
+	Source string    `json:"source,omitempty"`
+	Stage  string    `json:"stage"`
+	Item   string    `json:"item,omitempty"`
+	Count  int       `json:"count"`
+	Error  string    `json:"error,omitempty"`
+}
+
+// progressEvents writes progress events to a stream as line-delimited JSON.
+type progressEvents struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+}
+
+func newProgressEvents(w io.Writer) *progressEvents {
+	return &progressEvents{encoder: json.NewEncoder(w)}
+}
+
+// Report writes event as a line of JSON; pass it as a ProgressFunc.
+func (p *progressEvents) Report(event interfaces.ProgressEvent) {
+	line := progressEvent{
+		Time:   time.Now().UTC(),
+		Source: event.Source,
+		Stage:  string(event.Stage),
+		Item:   event.Item,
+		Count:  event.Count,
+	}
+	if event.Err != nil {
+		line.Error = event.Err.Error()
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	// Progress is best effort; a closed stderr mustn't fail the import
+	_ = p.encoder.Encode(line)
+}
+
+// progressBars totals progress events per source, in the order the sources started.
+type progressBars struct {
+	mu       sync.Mutex
+	sources  []string
+	trackers map[string]*interfaces.ProgressTracker
+	// waiting holds why a source is paused, until its next event
+	waiting map[string]string
+	total   interfaces.ProgressTracker
+	dirty   bool
+}
+
+func newProgressBars() *progressBars {
+	return &progressBars{
+		trackers: make(map[string]*interfaces.ProgressTracker),
+		waiting:  make(map[string]string),
+	}
+}
+
+// Report adds event to its source's totals; pass it as a ProgressFunc.
+func (p *progressBars) Report(event interfaces.ProgressEvent) {
+	p.total.Report(event)
+
+	p.mu.Lock()
+	tracker, ok := p.trackers[event.Source]
+	if !ok {
+		tracker = &interfaces.ProgressTracker{}
+		p.trackers[event.Source] = tracker
+		p.sources = append(p.sources, event.Source)
+	}
+	if event.Stage == interfaces.StageWaiting {
+		p.waiting[event.Source] = event.Item
+	} else {
+		delete(p.waiting, event.Source)
+	}
+	p.dirty = true
+	p.mu.Unlock()
+
+	tracker.Report(event)
+}
+
+// changed reports whether events arrived since it was last called.
+func (p *progressBars) changed() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	dirty := p.dirty
+	p.dirty = false
+	return dirty
+}
+
+// draw writes the bars to w over the previous drawn lines and returns how many lines it wrote.
+func (p *progressBars) draw(w io.Writer, drawn int) int {
+	p.mu.Lock()
+	sources := p.sources[max(0, len(p.sources)-maxProgressRows):]
+	lines := make([]string, 0, len(sources)+1)
+	for _, source := range sources {
+		line := progressLine(source, p.trackers[source].Counts())
+		if waiting := p.waiting[source]; waiting != "" {
+			line += "  waiting for " + waiting
+		}
+		lines = append(lines, line)
+	}
+	if len(p.sources) != 1 {
+		lines = append(lines, progressLine("total", p.total.Counts()))
+	}
+	p.mu.Unlock()
+
+	var b strings.Builder
+	if drawn > 0 {
+		fmt.Fprintf(&b, "\033[%dA", drawn)
+	}
+	for _, line := range lines {
+		fmt.Fprintf(&b, "\r\033[K%s\n", line)
+	}
+	fmt.Fprint(w, b.String())
+	return len(lines)
+}
+
+// progressLine renders one source's counts beside a bar of the discovered items it has finished.
+func progressLine(label string, c interfaces.ProgressCounts) string {
+	if label == "" {
+		label = "-"
+	}
+	if runes := []rune(label); len(runes) > progressSourceWidth {
+		label = "…" + string(runes[len(runes)-progressSourceWidth+1:])
+	}
+	return fmt.Sprintf("%-*s %s %d/%d  transformed %d  unchanged %d  embedded %d/%d chunks  failed %d",
+		progressSourceWidth, label, progressBar(c.Imported+c.Failed, c.Discovered),
+		c.Imported, c.Discovered, c.Transformed, c.Skipped, c.Embedded, c.Chunked, c.Failed)
+}
+
+// progressBar renders done out of total as a fixed-width bar, empty while the total is unknown.
+func progressBar(done, total int) string {
+	filled := 0
+	if total > 0 {
+		filled = min(progressBarWidth, done*progressBarWidth/total)
+	}
+	return "[" + strings.Repeat("#", filled) + strings.Repeat("-", progressBarWidth-filled) + "]"
+}
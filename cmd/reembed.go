@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/code-sleuth/ike-go/internal/manager/services"
+	"github.com/code-sleuth/ike-go/pkg/util"
+
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+)
+
+var reembedCmd = &cobra.Command{
+	Use:   "reembed",
+	Short: "Embed existing chunks with another model",
+	Long: `Generate embeddings with the --to model for every chunk embedded with the --from model,
+storing them in the column for the new model's dimension. Chunks are read from the database, so
+nothing is fetched or chunked again. Chunks that already have a --to embedding are skipped, so an
+interrupted or partly failed run can simply be started again. With --delete-old, the --from
+embeddings are removed once every chunk has been re-embedded without errors.`,
+	Example: `  ike-go reembed --from text-embedding-ada-002 --to text-embedding-3-large
+  ike-go reembed --from text-embedding-ada-002 --to text-embedding-3-large --delete-old`,
+	Run: func(cmd *cobra.Command, _ []string) {
+		logger := util.NewLogger(zerolog.ErrorLevel)
+
+		var options services.ReembedOptions
+		options.From, _ = cmd.Flags().GetString("from")
+		options.To, _ = cmd.Flags().GetString("to")
+		options.DeleteOld, _ = cmd.Flags().GetBool("delete-old")
+		options.Concurrency, _ = cmd.Flags().GetInt("concurrency")
+		options.BatchSize, _ = cmd.Flags().GetInt("batch-size")
+
+		database, err := openDatabase()
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to connect to database")
+		}
+		defer database.Close()
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		engine := newProcessingEngine(logger)
+		engine.SetDialect(database.Dialect())
+
+		report, err := engine.Reembed(ctx, options, database.DB)
+		if report != nil {
+			printErr := printResult(cmd, report, func(w io.Writer) {
+				fmt.Fprintf(w, "Re-embedded %d chunks: %d succeeded, %d failed, %d old embeddings deleted\n",
+					report.Chunks, report.Embedded, report.Failed, report.Deleted)
+			})
+			if printErr != nil {
+				logger.Error().Err(printErr).Msg("Failed to print report")
+			}
+		}
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Re-embed failed")
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(reembedCmd)
+
+	reembedCmd.Flags().String("from", "", "Embedding model the chunks are currently embedded with (required)")
+	reembedCmd.Flags().String("to", "", "Embedding model to embed the chunks with (required)")
+	reembedCmd.Flags().Bool("delete-old", false, "Delete the --from embeddings once every chunk is re-embedded")
+	reembedCmd.Flags().IntP("concurrency", "c", 5, "Number of chunks embedded at once")
+	reembedCmd.Flags().Int("batch-size", 500, "Number of chunks loaded from the database at a time")
+
+	_ = reembedCmd.MarkFlagRequired("from")
+	_ = reembedCmd.MarkFlagRequired("to")
+}
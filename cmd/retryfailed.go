@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/code-sleuth/ike-go/pkg/util"
+
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+)
+
+var retryFailedCmd = &cobra.Command{
+	Use:   "retry-failed",
+	Short: "Re-process chunks that failed embedding or saving",
+	Long: `Chunks that fail to embed or save during an import are recorded in the failed_chunks table
+with the error instead of being dropped. retry-failed re-processes just those chunks with the
+embedding model they were imported with, removing each one that succeeds.`,
+	Example: `  ike-go retry-failed
+  ike-go retry-failed --list
+  ike-go retry-failed --limit 100`,
+	Run: func(cmd *cobra.Command, _ []string) {
+		logger := util.NewLogger(zerolog.ErrorLevel)
+
+		limit, _ := cmd.Flags().GetInt("limit")
+		list, _ := cmd.Flags().GetBool("list")
+
+		database, err := openDatabase()
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to connect to database")
+		}
+		defer database.Close()
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		engine := newProcessingEngine(logger)
+		engine.SetDialect(database.Dialect())
+
+		if list {
+			failed, err := engine.FailedChunks(ctx, database.DB, limit)
+			if err != nil {
+				logger.Fatal().Err(err).Msg("Failed to list failed chunks")
+			}
+			err = printResult(cmd, nonNil(failed), func(w io.Writer) {
+				for _, fc := range failed {
+					fmt.Fprintf(w, "%s  document=%s  model=%s  attempts=%d  error=%s\n",
+						fc.Chunk.ID, fc.Chunk.DocumentID, fc.Model, fc.Attempts, fc.Error)
+				}
+			})
+			if err != nil {
+				logger.Fatal().Err(err).Msg("Failed to print failed chunks")
+			}
+			return
+		}
+
+		report, err := engine.RetryFailedChunks(ctx, database.DB, limit)
+		if report != nil {
+			printErr := printResult(cmd, report, func(w io.Writer) {
+				fmt.Fprintf(w, "Retried %d chunks: %d succeeded, %d failed\n",
+					report.Retried, report.Succeeded, report.Failed)
+			})
+			if printErr != nil {
+				logger.Error().Err(printErr).Msg("Failed to print report")
+			}
+		}
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Retry failed")
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(retryFailedCmd)
+
+	retryFailedCmd.Flags().Int("limit", 0, "Maximum number of failed chunks to process (0 for all)")
+	retryFailedCmd.Flags().Bool("list", false, "List failed chunks without retrying them")
+}
@@ -1,27 +1,66 @@
 package cmd
 
 import (
+	"context"
+	"os"
+
+	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
+	"github.com/code-sleuth/ike-go/pkg/blobstore"
+	"github.com/code-sleuth/ike-go/pkg/httpclient"
+	"github.com/code-sleuth/ike-go/pkg/migrations"
 	"github.com/code-sleuth/ike-go/pkg/util"
 	"github.com/joho/godotenv"
 	"github.com/rs/zerolog"
 	"github.com/spf13/cobra"
 )
 
+// skipSchemaCheck marks commands that must run against databases whose schema does not match
+// this binary, such as migrate itself.
+const skipSchemaCheck = "skip-schema-check"
+
 var rootCmd = &cobra.Command{
 	Use:   "ike-go",
 	Short: "A CLI tool for managing document indexing and embeddings",
 	Long:  `ike-go is a CLI application for managing sources: documents, chunks, and embeddings.`,
+	PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+		if err := checkOutput(cmd); err != nil {
+			return err
+		}
+		if jsonOutput(cmd) {
+			// Keep standard output for the JSON result alone
+			util.SetLogOutput(os.Stderr)
+		}
+
+		skip, _ := cmd.Flags().GetBool(skipSchemaCheck)
+		if skip || cmd.Annotations[skipSchemaCheck] == "true" || isBuiltin(cmd) {
+			return nil
+		}
+		// A schema mismatch is not a usage error, so don't print the usage text with it
+		cmd.SilenceUsage = true
+		return checkSchema(cmd.Context())
+	},
 }
 
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
-		logger := util.NewLogger(zerolog.ErrorLevel)
+	logger := util.NewLogger(zerolog.ErrorLevel)
+	// Events recorded by commands name the CLI as their actor; workers and webhooks set their own
+	err := rootCmd.ExecuteContext(interfaces.WithActor(context.Background(), "cli"))
+	shutdownTracing(logger)
+	if err != nil {
 		logger.Fatal().Err(err)
 	}
 }
 
 func init() {
 	cobra.OnInitialize(initConfig)
+
+	rootCmd.PersistentFlags().Bool(skipSchemaCheck, false, "Run even if the database schema version does not match")
+	rootCmd.PersistentFlags().StringP("output", "o", outputTable,
+		"Output format: table for people, json for scripts and tools such as jq")
+	rootCmd.PersistentFlags().String("config-file", "",
+		"JSON file of settings such as GITHUB_TOKEN, overriding the environment (default $"+configFileEnv+")")
+	rootCmd.PersistentFlags().StringArray("set", nil,
+		"Setting as KEY=VALUE, such as DB_MAX_OPEN_CONNS=8, overriding the environment and settings file (repeatable)")
 }
 
 func initConfig() {
@@ -30,4 +69,30 @@ func initConfig() {
 	if err != nil {
 		logger.Fatal().Err(err).Msg("No .env file found")
 	}
+	if err := loadAppConfig(); err != nil {
+		logger.Fatal().Err(err).Msg("Invalid configuration")
+	}
+	util.SetLogLevels(appConfig.LogLevels)
+	httpclient.SetDefault(appConfig.HTTP)
+	blobstore.SetDefault(appConfig.Blob)
+	setupTracing(logger)
+}
+
+// isBuiltin reports whether cmd is one of cobra's generated help or completion commands.
+func isBuiltin(cmd *cobra.Command) bool {
+	if cmd.Name() == "help" || cmd.Name() == "completion" {
+		return true
+	}
+	return cmd.HasParent() && cmd.Parent().Name() == "completion"
+}
+
+// checkSchema refuses to run commands against a database migrated to a different schema version.
+func checkSchema(ctx context.Context) error {
+	database, err := openDatabase()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	return migrations.Check(ctx, database.DB)
 }
@@ -0,0 +1,217 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
+	"github.com/code-sleuth/ike-go/internal/manager/repository"
+	"github.com/code-sleuth/ike-go/internal/manager/scheduler"
+	"github.com/code-sleuth/ike-go/pkg/util"
+
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+)
+
+var schedulesCmd = &cobra.Command{
+	Use:   "schedules",
+	Short: "Manage scheduled imports",
+	Long: `Manage the cron schedules "ike-go daemon" re-imports sources on. Expressions have five fields
+(minute, hour, day of month, month, day of week) or are one of @hourly, @daily, @weekly, @monthly,
+and @yearly. Times are in the daemon's local time zone.`,
+}
+
+var schedulesAddCmd = &cobra.Command{
+	Use:   "add",
+	Short: "Schedule a source to be re-imported",
+	Example: `  ike-go schedules add --url "https://github.com/owner/repo" --cron "0 3 * * *"
+  ike-go schedules add --url "https://example.com/wp-json/wp/v2/posts" --cron @hourly --collection blog`,
+	Run: func(cmd *cobra.Command, _ []string) {
+		logger := util.NewLogger(zerolog.ErrorLevel)
+
+		url, _ := cmd.Flags().GetString("url")
+		expr, _ := cmd.Flags().GetString("cron")
+		model, _ := cmd.Flags().GetString("model")
+		strategy, _ := cmd.Flags().GetString("strategy")
+		tokens, _ := cmd.Flags().GetInt("tokens")
+		workers, _ := cmd.Flags().GetInt("concurrency")
+		jobTimeout, _ := cmd.Flags().GetDuration("timeout")
+		collectionName, _ := cmd.Flags().GetString("collection")
+		priorityName, _ := cmd.Flags().GetString("priority")
+
+		priority, err := parsePriority(priorityName)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Invalid priority")
+		}
+
+		schedule, err := scheduler.NewSchedule(url, expr, &interfaces.ProcessingOptions{
+			MaxTokens:      tokens,
+			ChunkStrategy:  strategy,
+			EmbeddingModel: model,
+			Concurrency:    workers,
+			Timeout:        jobTimeout,
+			Collection:     collectionName,
+		}, time.Now())
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Invalid schedule")
+		}
+		schedule.Priority = priority
+
+		database, err := openDatabase()
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to connect to database")
+		}
+		defer database.Close()
+
+		if err := repository.NewScheduleRepository(database).Create(schedule); err != nil {
+			logger.Fatal().Err(err).Msg("Failed to create schedule")
+		}
+
+		err = printResult(cmd, schedule, func(w io.Writer) {
+			fmt.Fprintln(w, schedule.ID)
+		})
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to print schedule")
+		}
+	},
+}
+
+var schedulesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List scheduled imports",
+	Run: func(cmd *cobra.Command, _ []string) {
+		logger := util.NewLogger(zerolog.ErrorLevel)
+
+		database, err := openDatabase()
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to connect to database")
+		}
+		defer database.Close()
+
+		schedules, err := repository.NewScheduleRepository(database).List()
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to list schedules")
+		}
+
+		err = printResult(cmd, nonNil(schedules), func(w io.Writer) {
+			for _, schedule := range schedules {
+				next := "never"
+				if !schedule.Enabled {
+					next = "paused"
+				} else if schedule.NextRunAt != nil {
+					next = schedule.NextRunAt.Local().Format(time.RFC3339)
+				}
+				line := fmt.Sprintf("%s  %-15s priority=%d next=%s  %s", schedule.ID, schedule.Cron,
+					schedule.Priority, next, schedule.SourceURL)
+				if schedule.LastError != nil {
+					line += "  error=" + *schedule.LastError
+				}
+				fmt.Fprintln(w, line)
+			}
+		})
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to print schedules")
+		}
+	},
+}
+
+var schedulesRemoveCmd = &cobra.Command{
+	Use:   "remove [id]",
+	Short: "Remove a scheduled import",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		logger := util.NewLogger(zerolog.ErrorLevel)
+
+		database, err := openDatabase()
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to connect to database")
+		}
+		defer database.Close()
+
+		if err := repository.NewScheduleRepository(database).Delete(args[0]); err != nil {
+			logger.Fatal().Err(err).Msg("Failed to remove schedule")
+		}
+		printAction(cmd, logger, args[0], "removed", "")
+	},
+}
+
+var schedulesPauseCmd = &cobra.Command{
+	Use:   "pause [id]",
+	Short: "Stop a schedule from running until it is resumed",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		setScheduleEnabled(cmd, args[0], false)
+	},
+}
+
+var schedulesResumeCmd = &cobra.Command{
+	Use:   "resume [id]",
+	Short: "Resume a paused schedule",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		setScheduleEnabled(cmd, args[0], true)
+	},
+}
+
+// setScheduleEnabled pauses or resumes a schedule. A resumed schedule next runs at the first
+// match of its expression from now, not at the runs it missed while paused.
+func setScheduleEnabled(cmd *cobra.Command, id string, enabled bool) {
+	logger := util.NewLogger(zerolog.ErrorLevel)
+
+	database, err := openDatabase()
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Failed to connect to database")
+	}
+	defer database.Close()
+
+	repo := repository.NewScheduleRepository(database)
+	schedule, err := repo.GetByID(id)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Failed to get schedule")
+	}
+
+	var next *time.Time
+	if enabled {
+		cron, err := scheduler.ParseCron(schedule.Cron)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Invalid schedule")
+		}
+		if at := cron.Next(time.Now()); !at.IsZero() {
+			next = &at
+		}
+	}
+
+	if err := repo.SetEnabled(id, enabled, next); err != nil {
+		logger.Fatal().Err(err).Msg("Failed to update schedule")
+	}
+
+	action := "paused"
+	if enabled {
+		action = "resumed"
+	}
+	printAction(cmd, logger, id, action, "")
+}
+
+func init() {
+	rootCmd.AddCommand(schedulesCmd)
+	schedulesCmd.AddCommand(schedulesAddCmd)
+	schedulesCmd.AddCommand(schedulesListCmd)
+	schedulesCmd.AddCommand(schedulesRemoveCmd)
+	schedulesCmd.AddCommand(schedulesPauseCmd)
+	schedulesCmd.AddCommand(schedulesResumeCmd)
+
+	schedulesAddCmd.Flags().StringP("url", "u", "", "Source URL to import from (required)")
+	schedulesAddCmd.Flags().String("cron", "", "Cron expression for when to import, such as \"0 3 * * *\" (required)")
+	schedulesAddCmd.Flags().StringP("model", "m", "text-embedding-3-small", "Embedding model to use")
+	schedulesAddCmd.Flags().StringP("strategy", "s", "token", "Chunking strategy (token, heading, recursive)")
+	schedulesAddCmd.Flags().IntP("tokens", "t", 8191, "Maximum tokens per chunk")
+	schedulesAddCmd.Flags().IntP("concurrency", "c", 5, "Number of concurrent operations")
+	schedulesAddCmd.Flags().Duration("timeout", 5*time.Minute, "Timeout for each scheduled import")
+	schedulesAddCmd.Flags().
+		String("collection", interfaces.DefaultCollection, "Collection to place imported sources in")
+	schedulesAddCmd.Flags().
+		String("priority", "low", "Priority of the scheduled imports: high, normal, low, or an integer")
+	_ = schedulesAddCmd.MarkFlagRequired("url")
+	_ = schedulesAddCmd.MarkFlagRequired("cron")
+}
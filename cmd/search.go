@@ -0,0 +1,255 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/code-sleuth/ike-go/internal/manager/expanders"
+	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
+	"github.com/code-sleuth/ike-go/internal/manager/rerankers"
+	"github.com/code-sleuth/ike-go/internal/manager/search"
+	"github.com/code-sleuth/ike-go/pkg/util"
+
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+)
+
+var ErrUnsupportedReranker = errors.New("unsupported reranker")
+
+var searchCmd = &cobra.Command{
+	Use:   "search [query]",
+	Short: "Search chunks by keyword, vector similarity, or both",
+	Long: `Search the chunks of live documents.
+
+Hybrid mode (the default) combines keyword (BM25) and vector results with reciprocal rank fusion,
+so exact error messages and code identifiers are found even when embeddings miss them.
+--weight sets the share given to vector results, from 0 (keyword only) to 1 (vector only).
+--diversity re-ranks results so near-duplicate chunks do not crowd out the rest.
+--reranker re-scores the top results with Cohere, Jina, or a local cross-encoder (RERANKER_URL).
+--filter restricts results by collection, host, source ID, or document format and may be repeated.
+--recency-half-life favours recently published or modified documents.
+--include-stale also searches documents that outlived their source's TTL (see documents expire).
+--label searches only what a caller granted those access-control labels would see: unlabeled and
+public content, and content carrying one of the labels. Without it every document is searched.
+--expand generates paraphrases of the query with an LLM and searches them all, improving recall
+on vague questions at the cost of extra API calls.
+--context assembles the results into prompt-ready context within a token budget, stitching
+neighbouring chunks and numbering passages for citation.
+
+Results are printed as a ranked list with scores, source URLs, and snippets; use --output json
+(or --json) for machine-readable output.`,
+	Example: `  ike-go search "how do I rotate keys" --top-k 5 --filter host=github.com
+  ike-go search "os.ReadFile" --weight 0.2
+  ike-go search "how do I configure retries" --mode vector --json
+  ike-go search "getting started" --diversity 0.3
+  ike-go search "rotate api keys" --reranker cohere --rerank-top-n 20
+  ike-go search "how do webhooks work" --context 2000
+  ike-go search "release notes" --recency-half-life 720h
+  ike-go search "why is it slow" --expand 3
+  ike-go search "on-call escalation" --label support`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		logger := util.NewLogger(zerolog.ErrorLevel)
+
+		topK, _ := cmd.Flags().GetInt("top-k")
+		weight, _ := cmd.Flags().GetFloat64("weight")
+		model, _ := cmd.Flags().GetString("model")
+		mode, _ := cmd.Flags().GetString("mode")
+		diversity, _ := cmd.Flags().GetFloat64("diversity")
+		rerankerName, _ := cmd.Flags().GetString("reranker")
+		rerankModel, _ := cmd.Flags().GetString("rerank-model")
+		rerankTopN, _ := cmd.Flags().GetInt("rerank-top-n")
+		filterExpressions, _ := cmd.Flags().GetStringArray("filter")
+		contextTokens, _ := cmd.Flags().GetInt("context")
+		recencyHalfLife, _ := cmd.Flags().GetDuration("recency-half-life")
+		recencyWeight, _ := cmd.Flags().GetFloat64("recency-weight")
+		expansions, _ := cmd.Flags().GetInt("expand")
+		expandModel, _ := cmd.Flags().GetString("expand-model")
+		includeStale, _ := cmd.Flags().GetBool("include-stale")
+		labels, _ := cmd.Flags().GetStringArray("label")
+
+		filters, err := search.ParseFilters(filterExpressions)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Invalid filter")
+		}
+		filters.IncludeStale = includeStale
+		// Whoever can run the CLI can read the database, so only --label restricts the search
+		filters.Access = search.Access{Labels: labels, Unrestricted: !cmd.Flags().Changed("label")}
+
+		var reranker interfaces.Reranker
+		if rerankerName != "" {
+			reranker, err = newReranker(rerankerName, rerankModel)
+			if err != nil {
+				logger.Fatal().Err(err).Msg("Failed to create reranker")
+			}
+		}
+
+		var expander interfaces.QueryExpander
+		if expansions > 0 {
+			expander, err = expanders.NewOpenAIQueryExpander(expandModel, expanders.WithAPIKey(appConfig.OpenAIAPIKey))
+			if err != nil {
+				logger.Fatal().Err(err).Msg("Failed to create query expander")
+			}
+		}
+
+		database, err := openDatabase()
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to connect to database")
+		}
+		defer database.Close()
+
+		var embedder interfaces.Embedder
+		if search.Mode(mode) != search.ModeKeyword {
+			embedder, err = newEmbedder(model)
+			if err != nil {
+				logger.Fatal().Err(err).Msg("Failed to create embedder")
+			}
+		}
+
+		searcher := search.NewSearcher(database, embedder)
+		query := strings.Join(args, " ")
+		results, err := searcher.Search(cmd.Context(), query, search.Options{
+			Mode:            search.Mode(mode),
+			Limit:           topK,
+			Filters:         filters,
+			Weight:          weight,
+			Diversity:       diversity,
+			Reranker:        reranker,
+			RerankTopN:      rerankTopN,
+			RecencyHalfLife: recencyHalfLife,
+			RecencyWeight:   recencyWeight,
+			Expander:        expander,
+			Expansions:      expansions,
+		})
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Search failed")
+		}
+
+		if contextTokens > 0 {
+			builder := search.NewContextBuilder(database)
+			builder.SetTokenBudget(contextTokens)
+			assembled, err := builder.Build(cmd.Context(), results)
+			if err != nil {
+				logger.Fatal().Err(err).Msg("Failed to assemble context")
+			}
+			err = printResult(cmd, assembled, func(w io.Writer) {
+				_, _ = fmt.Fprintln(w, assembled.Text)
+			})
+			if err != nil {
+				logger.Fatal().Err(err).Msg("Failed to print context")
+			}
+			return
+		}
+
+		err = printResult(cmd, nonNil(results), func(w io.Writer) {
+			printSearchResults(w, query, results)
+		})
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to print results")
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(searchCmd)
+
+	searchCmd.Flags().IntP("top-k", "k", search.DefaultLimit, "Number of results to return")
+	searchCmd.Flags().
+		StringArray("filter", nil, "Filter results by collection=, host=, source=, format=, or owner= (repeatable)")
+	searchCmd.Flags().Bool("json", false, "Print results as JSON (same as --output json)")
+	searchCmd.Flags().Bool("include-stale", false, "Also search documents that outlived their source's TTL")
+	searchCmd.Flags().StringArray("label", nil, "Search as a caller granted this access-control label (repeatable)")
+	searchCmd.Flags().Duration("recency-half-life", 0, "Favour recent documents; one this old keeps half its boost")
+	searchCmd.Flags().
+		Float64("recency-weight", search.DefaultRecencyWeight, "Share of the score subject to time decay (0-1)")
+	searchCmd.Flags().Int("expand", 0, "Also search this many LLM-generated paraphrases of the query (costs tokens)")
+	searchCmd.Flags().String("expand-model", expanders.DefaultModel, "Chat model used to paraphrase the query")
+	searchCmd.Flags().Int("context", 0, "Assemble results into prompt context within this many tokens")
+	searchCmd.Flags().Float64("weight", search.DefaultWeight, "Share of the score given to vector results (0-1)")
+	searchCmd.Flags().StringP("model", "m", "text-embedding-3-small", "Embedding model used to embed the query")
+	searchCmd.Flags().String("mode", string(search.ModeHybrid), "Search mode (hybrid, keyword, or vector)")
+	searchCmd.Flags().String("reranker", "", "Rerank results with cohere, jina, or cross-encoder")
+	searchCmd.Flags().String("rerank-model", "", "Reranking model (defaults to the provider's recommended model)")
+	searchCmd.Flags().Int("rerank-top-n", 0, "Number of top results to rerank (default: all candidates)")
+	searchCmd.Flags().Float64("diversity", 0, "Diversify results with maximal marginal relevance (0 disables, up to 1)")
+}
+
+// newReranker creates the reranker for the given provider, using its default model when model
+// is empty.
+func newReranker(provider, model string) (interfaces.Reranker, error) {
+	switch provider {
+	case "cohere":
+		if model == "" {
+			model = "rerank-v3.5"
+		}
+		return rerankers.NewCohereReranker(model, rerankers.WithAPIKey(appConfig.CohereAPIKey))
+	case "jina":
+		if model == "" {
+			model = "jina-reranker-v2-base-multilingual"
+		}
+		return rerankers.NewJinaReranker(model, rerankers.WithAPIKey(appConfig.JinaAPIKey))
+	case "cross-encoder":
+		if model == "" {
+			model = "cross-encoder"
+		}
+		return rerankers.NewCrossEncoderReranker(model,
+			rerankers.WithBaseURL(appConfig.Reranker.URL), rerankers.WithAPIKey(appConfig.Reranker.APIKey))
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedReranker, provider)
+	}
+}
+
+// printSearchResults writes results as a ranked list with scores, sources, and snippets.
+// Highlighted matches are shown in bold when writing to a terminal.
+func printSearchResults(w io.Writer, query string, results []search.Result) {
+	if len(results) == 0 {
+		_, _ = fmt.Fprintln(w, "No results.")
+		return
+	}
+
+	emphasize := isTerminal(w)
+	for i, result := range results {
+		snippet := result.Snippet
+		if snippet == nil {
+			highlighted := search.Highlight(result.Body, query, search.DefaultSnippetWidth)
+			snippet = &highlighted
+		}
+
+		_, _ = fmt.Fprintf(w, "%d. [%.4f] %s\n", i+1, result.Score, result.SourceURL)
+		_, _ = fmt.Fprintf(w, "   chunk %s (document %s, %s match)\n", result.ChunkID, result.DocumentID, snippet.Match)
+		_, _ = fmt.Fprintf(w, "   %s\n\n", renderHighlighted(snippet, emphasize))
+	}
+}
+
+// renderHighlighted returns the snippet text, wrapping highlighted spans in ANSI bold when
+// emphasize is set.
+func renderHighlighted(snippet *search.Highlighted, emphasize bool) string {
+	if !emphasize || len(snippet.Highlights) == 0 {
+		return snippet.Text
+	}
+
+	var builder strings.Builder
+	last := 0
+	for _, span := range snippet.Highlights {
+		builder.WriteString(snippet.Text[last:span.Start])
+		builder.WriteString("\033[1m")
+		builder.WriteString(snippet.Text[span.Start:span.End])
+		builder.WriteString("\033[0m")
+		last = span.End
+	}
+	builder.WriteString(snippet.Text[last:])
+	return builder.String()
+}
+
+// isTerminal reports whether w is an interactive terminal.
+func isTerminal(w io.Writer) bool {
+	file, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,223 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
+	"github.com/code-sleuth/ike-go/internal/manager/repository"
+	"github.com/code-sleuth/ike-go/internal/manager/search"
+	"github.com/code-sleuth/ike-go/internal/manager/server"
+	"github.com/code-sleuth/ike-go/internal/manager/services"
+	"github.com/code-sleuth/ike-go/pkg/db"
+	"github.com/code-sleuth/ike-go/pkg/migrations"
+	"github.com/code-sleuth/ike-go/pkg/util"
+
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+)
+
+var (
+	ErrProfilingNeedsToken = errors.New("--pprof needs --admin-token or IKE_ADMIN_TOKEN, which requests must send")
+	ErrInvalidSearchToken  = errors.New("search tokens must be given as token=label[,label...]")
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve the search API over HTTP",
+	Long: `Run ike-go in server mode, exposing the corpus to applications over HTTP.
+
+Endpoints:
+  POST /v1/search         {"query": "...", "top_k": 5, "filters": {"host": "github.com"}}
+                          Without a bearer token only unlabeled and public content is searched;
+                          tokens given with --search-token token=label,label or IKE_SEARCH_TOKENS
+                          (space-separated) also search content carrying one of their labels,
+                          and the admin token searches everything.
+  GET  /metrics           Prometheus metrics in the text exposition format.
+  GET  /healthz           Liveness: 200 while the database is reachable, 503 otherwise.
+  GET  /readyz            Readiness: 200 once the database is reachable, its schema matches
+                          this binary, and the embedder answers; 503 with the failing checks
+                          otherwise.
+  POST /webhooks/github   GitHub push events; enabled with --github-webhook-secret or
+                          GITHUB_WEBHOOK_SECRET. Pushes to imported repositories enqueue
+                          re-imports of the changed files for "ike-go worker".
+  POST /webhooks/wordpress
+                          {"endpoint": "https://example.com/wp-json/wp/v2/posts", "post_id": 42}
+                          signed in X-Webhook-Signature: sha256=<hex HMAC-SHA256 of the body>;
+                          enabled with --wordpress-webhook-secret or WORDPRESS_WEBHOOK_SECRET.
+                          Enqueues a re-import of the published or updated post.
+  GET  /v1/webhooks       List, add ({"url": "...", "event_types": ["job_failed"]}), and remove
+  POST /v1/webhooks       the outbound webhooks "ike-go daemon" calls; enabled with --admin-token
+  DELETE /v1/webhooks/{id}
+                          or IKE_ADMIN_TOKEN, which requests must send as a bearer token.
+  GET  /debug/pprof/      Runtime profiles for "go tool pprof"; enabled with --pprof, and
+                          authorized with the admin token like /v1/webhooks.
+
+The server shuts down gracefully on SIGINT or SIGTERM.`,
+	Example: `  ike-go serve --addr :8080
+  ike-go serve --search-token "$SUPPORT_TOKEN=support,internal"`,
+	Run: func(cmd *cobra.Command, _ []string) {
+		logger := util.NewLogger(zerolog.ErrorLevel)
+
+		addr, _ := cmd.Flags().GetString("addr")
+		model, _ := cmd.Flags().GetString("model")
+
+		database, err := openDatabase()
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to connect to database")
+		}
+		defer database.Close()
+
+		srv, err := newAPIServer(cmd, database, model)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to create API server")
+		}
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		if err := srv.ListenAndServe(ctx, addr); err != nil {
+			logger.Fatal().Err(err).Msg("HTTP server failed")
+		}
+	},
+}
+
+// newAPIServer returns the search API over database, embedding queries with model, with health
+// probes, the webhook receivers whose secrets are set, and outbound webhook management, and
+// runtime profiles with --pprof, when an admin token is set.
+func newAPIServer(cmd *cobra.Command, database *db.DB, model string) (*server.Server, error) {
+	token := flagOr(cmd, "admin-token", appConfig.Server.AdminToken)
+	profiling, _ := cmd.Flags().GetBool("pprof")
+	if profiling && token == "" {
+		return nil, ErrProfilingNeedsToken
+	}
+
+	embedder, err := newEmbedder(model)
+	if err != nil {
+		return nil, err
+	}
+
+	srv := server.NewServer(search.NewSearcher(database, embedder))
+	grants, err := searchGrants(cmd)
+	if err != nil {
+		return nil, err
+	}
+	for searchToken, labels := range grants {
+		srv.GrantSearchAccess(searchToken, search.Access{Labels: labels})
+	}
+	if token != "" {
+		srv.GrantSearchAccess(token, search.Access{Unrestricted: true})
+	}
+	srv.HandleHealth(healthChecks(database, embedder)...)
+	githubSecret := flagOr(cmd, "github-webhook-secret", appConfig.Server.GitHubWebhookSecret)
+	wordpressSecret := flagOr(cmd, "wordpress-webhook-secret", appConfig.Server.WordPressWebhookSecret)
+	if githubSecret != "" || wordpressSecret != "" {
+		receiver := services.NewWebhookReceiver(database.DB, database.Dialect(),
+			repository.NewJobRepository(database), webhookImportOptions(model))
+		if githubSecret != "" {
+			srv.HandleGitHubWebhook(githubSecret, receiver)
+		}
+		if wordpressSecret != "" {
+			srv.HandleWordPressWebhook(wordpressSecret, receiver)
+		}
+	}
+	if token != "" {
+		srv.HandleWebhookSubscriptions(token, repository.NewWebhookSubscriptionRepository(database))
+	}
+	if profiling {
+		srv.HandleProfiling(token)
+	}
+	return srv, nil
+}
+
+// addAPIFlags registers the flags newAPIServer reads, listening on addr by default.
+func addAPIFlags(cmd *cobra.Command, addr string) {
+	cmd.Flags().String("addr", addr, "Address to listen on")
+	cmd.Flags().StringP("model", "m", "text-embedding-3-small", "Embedding model used to embed queries")
+	cmd.Flags().String("github-webhook-secret", "",
+		"Secret GitHub signs webhook deliveries with; enables POST /webhooks/github (default $GITHUB_WEBHOOK_SECRET)")
+	cmd.Flags().String("wordpress-webhook-secret", "",
+		"Secret WordPress hooks sign post updates with; enables POST /webhooks/wordpress "+
+			"(default $WORDPRESS_WEBHOOK_SECRET)")
+	cmd.Flags().String("admin-token", "",
+		"Bearer token for managing outbound webhooks at /v1/webhooks; enables them (default $IKE_ADMIN_TOKEN)")
+	cmd.Flags().Bool("pprof", false, "Serve runtime profiles at /debug/pprof/ to holders of the admin token")
+	cmd.Flags().StringArray("search-token", nil,
+		"Bearer token=label,label whose searches also see content with those labels "+
+			"(repeatable; default $IKE_SEARCH_TOKENS)")
+}
+
+// searchGrants returns the labels each search token is granted, from --search-token or, when it
+// isn't given, the space-separated IKE_SEARCH_TOKENS setting.
+func searchGrants(cmd *cobra.Command) (map[string][]string, error) {
+	entries, _ := cmd.Flags().GetStringArray("search-token")
+	if len(entries) == 0 {
+		entries = appConfig.Server.SearchTokens
+	}
+
+	grants := make(map[string][]string, len(entries))
+	for _, entry := range entries {
+		token, labels, ok := strings.Cut(entry, "=")
+		if !ok || token == "" {
+			return nil, ErrInvalidSearchToken
+		}
+		for _, label := range strings.Split(labels, ",") {
+			if label = strings.TrimSpace(label); label != "" {
+				grants[token] = append(grants[token], label)
+			}
+		}
+		if len(grants[token]) == 0 {
+			return nil, ErrInvalidSearchToken
+		}
+	}
+	return grants, nil
+}
+
+// flagOr returns the string flag name, falling back to setting, its value in appConfig. Secrets
+// are read this way so their values never appear as flag defaults in --help.
+func flagOr(cmd *cobra.Command, name, setting string) string {
+	if value, _ := cmd.Flags().GetString(name); value != "" {
+		return value
+	}
+	return setting
+}
+
+// healthChecks are the dependencies the server reports on. A lost database connection fails
+// liveness as well as readiness; a schema this binary doesn't match or an unreachable embedder
+// only fail readiness, since restarting the server can't fix either. The embedder is probed with
+// a one-word embedding at most once a minute to keep probes cheap.
+func healthChecks(database *db.DB, embedder interfaces.Embedder) []server.HealthCheck {
+	return []server.HealthCheck{
+		{Name: "database", Check: database.PingContext, Liveness: true},
+		{Name: "schema", Check: func(ctx context.Context) error {
+			return migrations.Check(ctx, database.DB)
+		}},
+		{Name: "embedder", Check: func(ctx context.Context) error {
+			_, err := embedder.GenerateEmbedding(ctx, "ping")
+			return err
+		}, CacheFor: time.Minute},
+	}
+}
+
+// webhookImportOptions are the options imports enqueued by webhooks run with. They embed with
+// the model the server searches with, so updated chunks stay comparable with queries.
+func webhookImportOptions(model string) interfaces.ProcessingOptions {
+	return interfaces.ProcessingOptions{
+		MaxTokens:      8191,
+		ChunkStrategy:  "token",
+		EmbeddingModel: model,
+		Concurrency:    5,
+		Timeout:        5 * time.Minute,
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+
+	addAPIFlags(serveCmd, server.DefaultAddr)
+}
@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/code-sleuth/ike-go/internal/manager/models"
+	"github.com/code-sleuth/ike-go/internal/manager/repository"
+	"github.com/code-sleuth/ike-go/pkg/util"
+
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+)
+
+var ErrNoCredentials = errors.New(
+	"no credentials given; set --github-token, --openai-api-key, or --together-api-key")
+
+var sourceCredentialsCmd = &cobra.Command{
+	Use:   "credentials",
+	Short: "Manage per-source credentials",
+	Long: `Manage credentials stored for a source URL. Imports of content under that URL, including
+scheduled imports and webhook re-imports, fetch it and embed it with these credentials in place
+of the configured GITHUB_TOKEN, OPENAI_API_KEY, and TOGETHER_API_KEY, so each customer's sources
+can use their own. When several URLs match, the longest wins; credentials left unset fall back to
+the configured ones.`,
+}
+
+var sourceCredentialsSetCmd = &cobra.Command{
+	Use:   "set",
+	Short: "Store credentials for a source URL",
+	Long: `Store credentials for a source URL. Running set again for the same URL replaces its
+credentials.`,
+	Example: `  ike-go sources credentials set --url "https://github.com/customer" --github-token "$CUSTOMER_TOKEN"
+  ike-go sources credentials set --url "https://github.com/customer/repo" --openai-api-key "$CUSTOMER_KEY"`,
+	Run: func(cmd *cobra.Command, _ []string) {
+		logger := util.NewLogger(zerolog.ErrorLevel)
+
+		url, _ := cmd.Flags().GetString("url")
+		// Sources are stored under their normalized URL, so credentials must be too to match them
+		url, err := util.NormalizeURL(url)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Invalid source URL")
+		}
+		credentials := &models.SourceCredentials{SourceURL: url}
+		credentials.GitHubToken, _ = cmd.Flags().GetString("github-token")
+		credentials.OpenAIAPIKey, _ = cmd.Flags().GetString("openai-api-key")
+		credentials.TogetherAPIKey, _ = cmd.Flags().GetString("together-api-key")
+		if credentials.GitHubToken == "" && credentials.OpenAIAPIKey == "" && credentials.TogetherAPIKey == "" {
+			logger.Fatal().Err(ErrNoCredentials).Msg("Invalid credentials")
+		}
+
+		database, err := openDatabase()
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to connect to database")
+		}
+		defer database.Close()
+
+		if err := repository.NewSourceCredentialsRepository(database).Set(credentials); err != nil {
+			logger.Fatal().Err(err).Msg("Failed to save source credentials")
+		}
+		if err := printResult(cmd, redactCredentials(*credentials), func(io.Writer) {}); err != nil {
+			logger.Fatal().Err(err).Msg("Failed to print source credentials")
+		}
+	},
+}
+
+var sourceCredentialsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the source URLs with stored credentials",
+	Long:  `List the source URLs with stored credentials, and which credentials each has. Values are redacted.`,
+	Run: func(cmd *cobra.Command, _ []string) {
+		logger := util.NewLogger(zerolog.ErrorLevel)
+
+		database, err := openDatabase()
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to connect to database")
+		}
+		defer database.Close()
+
+		all, err := repository.NewSourceCredentialsRepository(database).List()
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to list source credentials")
+		}
+		for i := range all {
+			all[i] = redactCredentials(all[i])
+		}
+
+		err = printResult(cmd, nonNil(all), func(w io.Writer) {
+			for _, credentials := range all {
+				line := credentials.SourceURL
+				if credentials.GitHubToken != "" {
+					line += "  github-token"
+				}
+				if credentials.OpenAIAPIKey != "" {
+					line += "  openai-api-key"
+				}
+				if credentials.TogetherAPIKey != "" {
+					line += "  together-api-key"
+				}
+				if credentials.URLSecrets != "" {
+					line += "  url-secrets"
+				}
+				fmt.Fprintln(w, line)
+			}
+		})
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to print source credentials")
+		}
+	},
+}
+
+var sourceCredentialsClearCmd = &cobra.Command{
+	Use:   "clear [url]",
+	Short: "Remove the credentials of a source URL",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		logger := util.NewLogger(zerolog.ErrorLevel)
+
+		database, err := openDatabase()
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to connect to database")
+		}
+		defer database.Close()
+
+		url, err := util.NormalizeURL(args[0])
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Invalid source URL")
+		}
+		if err := repository.NewSourceCredentialsRepository(database).Delete(url); err != nil {
+			logger.Fatal().Err(err).Msg("Failed to clear source credentials")
+		}
+		printAction(cmd, logger, url, "cleared", "")
+	},
+}
+
+// redactCredentials returns credentials with each value that is set replaced by util.Redacted, so
+// output shows which are stored without revealing them.
+func redactCredentials(credentials models.SourceCredentials) models.SourceCredentials {
+	for _, value := range []*string{
+		&credentials.GitHubToken, &credentials.OpenAIAPIKey, &credentials.TogetherAPIKey, &credentials.URLSecrets,
+	} {
+		if *value != "" {
+			*value = util.Redacted
+		}
+	}
+	return credentials
+}
+
+func init() {
+	sourcesCmd.AddCommand(sourceCredentialsCmd)
+	sourceCredentialsCmd.AddCommand(sourceCredentialsSetCmd)
+	sourceCredentialsCmd.AddCommand(sourceCredentialsListCmd)
+	sourceCredentialsCmd.AddCommand(sourceCredentialsClearCmd)
+
+	sourceCredentialsSetCmd.Flags().StringP("url", "u", "", "Source URL the credentials apply to (required)")
+	sourceCredentialsSetCmd.Flags().String("github-token", "", "GitHub token to import with")
+	sourceCredentialsSetCmd.Flags().String("openai-api-key", "", "OpenAI API key to embed with")
+	sourceCredentialsSetCmd.Flags().String("together-api-key", "", "Together AI API key to embed with")
+	_ = sourceCredentialsSetCmd.MarkFlagRequired("url")
+}
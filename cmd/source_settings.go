@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/code-sleuth/ike-go/internal/manager/models"
+	"github.com/code-sleuth/ike-go/internal/manager/repository"
+	"github.com/code-sleuth/ike-go/pkg/util"
+
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+)
+
+var (
+	ErrNoSettings = errors.New(
+		"no settings given; set --model, --strategy, --tokens, --concurrency, --ttl, or --label")
+	ErrInvalidSettings = errors.New("--tokens, --concurrency, and --ttl must be positive")
+	ErrInvalidExpiry   = errors.New("--expire must be stale or purge, and requires --ttl")
+)
+
+var sourceSettingsCmd = &cobra.Command{
+	Use:   "settings",
+	Short: "Manage per-source processing settings",
+	Long: `Manage processing settings stored for a source URL. They override the options an import,
+scheduled import, or webhook re-import runs with for content under that URL, so a code repository
+and a blog can be chunked and embedded differently. Settings for a repository or WordPress
+endpoint apply to each of its files or posts; when several URLs match, the longest wins.
+
+--ttl gives the documents under a URL a time to live, counted from when they were published, else
+indexed. "documents expire" marks documents past it stale, leaving them out of search unless
+--include-stale is given, or with --expire purge deletes them.
+
+--label gives the content under a URL access-control labels in place of those importers derive,
+such as a GitHub repository's visibility. They take effect at its next import; searches only
+return labeled content to callers granted one of its labels.`,
+}
+
+var sourceSettingsSetCmd = &cobra.Command{
+	Use:   "set",
+	Short: "Store processing settings for a source URL",
+	Long: `Store processing settings for a source URL. Only the flags given are overridden; running set
+again for the same URL replaces its settings.`,
+	Example: `  ike-go sources settings set --url "https://github.com/owner/repo" --tokens 512
+  ike-go sources settings set --url "https://example.com/wp-json/wp/v2/posts" --model text-embedding-3-large
+  ike-go sources settings set --url "https://example.com/wp-json/wp/v2/promotions" --ttl 720h --expire purge
+  ike-go sources settings set --url "https://github.com/owner/runbooks" --label support --label sre`,
+	Run: func(cmd *cobra.Command, _ []string) {
+		logger := util.NewLogger(zerolog.ErrorLevel)
+
+		url, _ := cmd.Flags().GetString("url")
+		// Sources are stored under their normalized URL, so settings must be too to match them
+		url, err := util.NormalizeURL(url)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Invalid source URL")
+		}
+		settings := &models.SourceSettings{SourceURL: url}
+		if cmd.Flags().Changed("model") {
+			model, _ := cmd.Flags().GetString("model")
+			if _, err := newEmbedder(model); errors.Is(err, ErrUnsupportedEmbeddingModel) {
+				logger.Fatal().Err(err).Msg("Invalid settings")
+			}
+			settings.EmbeddingModel = &model
+		}
+		if cmd.Flags().Changed("strategy") {
+			strategy, _ := cmd.Flags().GetString("strategy")
+			settings.ChunkStrategy = &strategy
+		}
+		if cmd.Flags().Changed("tokens") {
+			tokens, _ := cmd.Flags().GetInt("tokens")
+			if tokens <= 0 {
+				logger.Fatal().Err(ErrInvalidSettings).Msg("Invalid settings")
+			}
+			settings.MaxTokens = &tokens
+		}
+		if cmd.Flags().Changed("concurrency") {
+			workers, _ := cmd.Flags().GetInt("concurrency")
+			if workers <= 0 {
+				logger.Fatal().Err(ErrInvalidSettings).Msg("Invalid settings")
+			}
+			settings.Concurrency = &workers
+		}
+		if cmd.Flags().Changed("ttl") {
+			ttl, _ := cmd.Flags().GetDuration("ttl")
+			if ttl < time.Second {
+				logger.Fatal().Err(ErrInvalidSettings).Msg("Invalid settings")
+			}
+			seconds := int64(ttl / time.Second)
+			settings.TTLSeconds = &seconds
+		}
+		if cmd.Flags().Changed("expire") {
+			expire, _ := cmd.Flags().GetString("expire")
+			action := models.ExpiryAction(expire)
+			if settings.TTLSeconds == nil || (action != models.ExpireStale && action != models.ExpirePurge) {
+				logger.Fatal().Err(ErrInvalidExpiry).Msg("Invalid settings")
+			}
+			settings.ExpiryAction = &action
+		}
+		settings.ACLLabels, _ = cmd.Flags().GetStringArray("label")
+		if settings.EmbeddingModel == nil && settings.ChunkStrategy == nil && settings.MaxTokens == nil &&
+			settings.Concurrency == nil && settings.TTLSeconds == nil && len(settings.ACLLabels) == 0 {
+			logger.Fatal().Err(ErrNoSettings).Msg("Invalid settings")
+		}
+
+		database, err := openDatabase()
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to connect to database")
+		}
+		defer database.Close()
+
+		if err := repository.NewSourceSettingsRepository(database).Set(settings); err != nil {
+			logger.Fatal().Err(err).Msg("Failed to save source settings")
+		}
+		if err := printResult(cmd, settings, func(io.Writer) {}); err != nil {
+			logger.Fatal().Err(err).Msg("Failed to print source settings")
+		}
+	},
+}
+
+var sourceSettingsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List per-source processing settings",
+	Run: func(cmd *cobra.Command, _ []string) {
+		logger := util.NewLogger(zerolog.ErrorLevel)
+
+		database, err := openDatabase()
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to connect to database")
+		}
+		defer database.Close()
+
+		all, err := repository.NewSourceSettingsRepository(database).List()
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to list source settings")
+		}
+
+		err = printResult(cmd, nonNil(all), func(w io.Writer) {
+			for _, settings := range all {
+				line := settings.SourceURL
+				if settings.EmbeddingModel != nil {
+					line += "  model=" + *settings.EmbeddingModel
+				}
+				if settings.ChunkStrategy != nil {
+					line += "  strategy=" + *settings.ChunkStrategy
+				}
+				if settings.MaxTokens != nil {
+					line += fmt.Sprintf("  tokens=%d", *settings.MaxTokens)
+				}
+				if settings.Concurrency != nil {
+					line += fmt.Sprintf("  concurrency=%d", *settings.Concurrency)
+				}
+				if settings.TTLSeconds != nil {
+					line += fmt.Sprintf("  ttl=%s", time.Duration(*settings.TTLSeconds)*time.Second)
+					if settings.ExpiryAction != nil {
+						line += "  expire=" + string(*settings.ExpiryAction)
+					}
+				}
+				if len(settings.ACLLabels) > 0 {
+					line += "  labels=" + strings.Join(settings.ACLLabels, ",")
+				}
+				fmt.Fprintln(w, line)
+			}
+		})
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to print source settings")
+		}
+	},
+}
+
+var sourceSettingsClearCmd = &cobra.Command{
+	Use:   "clear [url]",
+	Short: "Remove the processing settings of a source URL",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		logger := util.NewLogger(zerolog.ErrorLevel)
+
+		database, err := openDatabase()
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to connect to database")
+		}
+		defer database.Close()
+
+		url, err := util.NormalizeURL(args[0])
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Invalid source URL")
+		}
+		if err := repository.NewSourceSettingsRepository(database).Delete(url); err != nil {
+			logger.Fatal().Err(err).Msg("Failed to clear source settings")
+		}
+		printAction(cmd, logger, url, "cleared", "")
+	},
+}
+
+func init() {
+	sourcesCmd.AddCommand(sourceSettingsCmd)
+	sourceSettingsCmd.AddCommand(sourceSettingsSetCmd)
+	sourceSettingsCmd.AddCommand(sourceSettingsListCmd)
+	sourceSettingsCmd.AddCommand(sourceSettingsClearCmd)
+
+	sourceSettingsSetCmd.Flags().StringP("url", "u", "", "Source URL the settings apply to (required)")
+	sourceSettingsSetCmd.Flags().StringP("model", "m", "", "Embedding model to use")
+	sourceSettingsSetCmd.Flags().StringP("strategy", "s", "", "Chunking strategy to use")
+	sourceSettingsSetCmd.Flags().IntP("tokens", "t", 0, "Maximum tokens per chunk")
+	sourceSettingsSetCmd.Flags().IntP("concurrency", "c", 0, "Number of concurrent embedding requests")
+	sourceSettingsSetCmd.Flags().Duration("ttl", 0, "How long documents live before they expire, such as 720h")
+	sourceSettingsSetCmd.Flags().String("expire", string(models.ExpireStale),
+		"What happens to expired documents: stale (left out of search) or purge")
+	sourceSettingsSetCmd.Flags().StringArray("label", nil,
+		"Access-control label of the content, replacing those importers derive (repeatable)")
+	_ = sourceSettingsSetCmd.MarkFlagRequired("url")
+}
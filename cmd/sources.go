@@ -1,10 +1,12 @@
 package cmd
 
 import (
-	"encoding/json"
+	"fmt"
+	"io"
 	"strings"
 	"time"
 
+	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
 	"github.com/code-sleuth/ike-go/internal/manager/models"
 	"github.com/code-sleuth/ike-go/internal/manager/repository"
 	"github.com/code-sleuth/ike-go/pkg/db"
@@ -22,11 +24,11 @@ var sourcesCmd = &cobra.Command{
 
 var sourcesListCmd = &cobra.Command{
 	Use:   "list",
-	Short: "List all sources",
-	Run: func(_ *cobra.Command, _ []string) {
+	Short: "List sources",
+	Run: func(cmd *cobra.Command, _ []string) {
 		logger := util.NewLogger(zerolog.ErrorLevel)
 
-		database, err := db.NewConnection()
+		database, err := openDatabase()
 		if err != nil {
 			logger.Fatal().Err(err).Msg("Failed to connect to database")
 		}
@@ -37,32 +39,134 @@ var sourcesListCmd = &cobra.Command{
 			}
 		}(database)
 
+		limit, _ := cmd.Flags().GetInt("limit")
+		offset, _ := cmd.Flags().GetInt("offset")
+		sortBy, _ := cmd.Flags().GetString("sort")
+		order, _ := cmd.Flags().GetString("order")
+		host, _ := cmd.Flags().GetString("host")
+		format, _ := cmd.Flags().GetString("format")
+		collection, _ := cmd.Flags().GetString("collection")
+		owner, _ := cmd.Flags().GetString("owner")
+		label, _ := cmd.Flags().GetString("label")
+
 		repo := repository.NewSourceRepository(database)
-		sources, err := repo.List()
+		sources, err := repo.ListWithOptions(repository.SourceListOptions{
+			ListOptions: repository.ListOptions{
+				Limit:  limit,
+				Offset: offset,
+				SortBy: sortBy,
+				Order:  repository.SortOrder(strings.ToLower(order)),
+			},
+			Host:       host,
+			Format:     format,
+			Collection: collection,
+			Owner:      owner,
+			Label:      label,
+		})
 		if err != nil {
 			logger.Fatal().Err(err).Msgf("Failed to list sources: %v\n", err)
 		}
 
-		if len(sources) == 0 {
+		if len(sources) == 0 && !jsonOutput(cmd) {
 			logger.Error().Msg("No sources found")
 			return
 		}
 
-		jsonOutput, err := json.MarshalIndent(sources, "", "  ")
+		summaries, err := repo.Summarize(sources)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to summarize sources")
+		}
+
+		err = printResult(cmd, nonNil(summaries), func(w io.Writer) {
+			for _, summary := range summaries {
+				line := fmt.Sprintf("%s  %s  %s  imported=%s  documents=%d chunks=%d embeddings=%d", summary.ID,
+					summary.Collection, stringOr(summary.RawURL, "-"), timeOr(summary.LastImportedAt, "never"),
+					summary.Documents, summary.Chunks, summary.Embeddings)
+				if summary.LastError != nil {
+					line += "  last_error=" + truncate(*summary.LastError, maxErrorWidth)
+				}
+				fmt.Fprintln(w, line)
+			}
+		})
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to print sources")
+		}
+	},
+}
+
+var sourcesShowCmd = &cobra.Command{
+	Use:   "show [id]",
+	Short: "Show a source with its document, chunk, and embedding counts and last error",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		logger := util.NewLogger(zerolog.ErrorLevel)
+		database, err := openDatabase()
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to connect to database")
+		}
+		defer database.Close()
+
+		summary, err := repository.NewSourceRepository(database).GetSummary(args[0])
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to get source")
+		}
+
+		err = printResult(cmd, summary, func(w io.Writer) {
+			fmt.Fprintf(w, "ID:             %s\n", summary.ID)
+			fmt.Fprintf(w, "URL:            %s\n", stringOr(summary.RawURL, "-"))
+			fmt.Fprintf(w, "Collection:     %s\n", summary.Collection)
+			if len(summary.Owners) > 0 {
+				fmt.Fprintf(w, "Owners:         %s\n", strings.Join(summary.Owners, ", "))
+			}
+			if len(summary.Labels) > 0 {
+				fmt.Fprintf(w, "Labels:         %s\n", strings.Join(summary.Labels, ", "))
+			}
+			if summary.AuthorEmail != nil {
+				fmt.Fprintf(w, "Author:         %s\n", *summary.AuthorEmail)
+			}
+			fmt.Fprintf(w, "Created:        %s\n", summary.CreatedAt.Local().Format(time.RFC3339))
+			fmt.Fprintf(w, "Last imported:  %s\n", timeOr(summary.LastImportedAt, "never"))
+			fmt.Fprintf(w, "Documents:      %d\n", summary.Documents)
+			fmt.Fprintf(w, "Chunks:         %d\n", summary.Chunks)
+			fmt.Fprintf(w, "Embeddings:     %d\n", summary.Embeddings)
+			fmt.Fprintf(w, "Last success:   %s\n", timeOr(summary.LastSuccessAt, "never"))
+			if summary.ConsecutiveFailures > 0 {
+				fmt.Fprintf(w, "Failing:        %d imports in a row, last with %s\n", summary.ConsecutiveFailures,
+					stringOr(summary.LastImportError, "an unknown error"))
+			}
+			if summary.LastError != nil {
+				fmt.Fprintf(w, "Last error:     %s (%s)\n", *summary.LastError, timeOr(summary.LastErrorAt, ""))
+			}
+		})
 		if err != nil {
-			logger.Fatal().Err(err).Msgf("Failed to marshal JSON: %v\n", err)
+			logger.Fatal().Err(err).Msg("Failed to print source")
 		}
-		logger.Info().Msg(string(jsonOutput))
 	},
 }
 
+// stringOr returns *s, or fallback when s is nil.
+func stringOr(s *string, fallback string) string {
+	if s == nil {
+		return fallback
+	}
+	return *s
+}
+
+// timeOr formats *t in local time, or returns fallback when t is nil.
+func timeOr(t *time.Time, fallback string) string {
+	if t == nil {
+		return fallback
+	}
+	return t.Local().Format(time.RFC3339)
+}
+
 var sourcesGetCmd = &cobra.Command{
 	Use:   "get [id]",
 	Short: "Get a source by ID",
 	Args:  cobra.ExactArgs(1),
-	Run: func(_ *cobra.Command, args []string) {
+	Run: func(cmd *cobra.Command, args []string) {
 		logger := util.NewLogger(zerolog.ErrorLevel)
-		database, err := db.NewConnection()
+		database, err := openDatabase()
 		if err != nil {
 			logger.Fatal().Err(err).Msgf("Failed to connect to database: %v\n", err)
 		}
@@ -74,11 +178,10 @@ var sourcesGetCmd = &cobra.Command{
 			logger.Fatal().Err(err).Msgf("Failed to get source: %v\n", err)
 		}
 
-		jsonOutput, err := json.MarshalIndent(source, "", "  ")
-		if err != nil {
-			logger.Fatal().Err(err).Msgf("Failed to marshal JSON: %v\n", err)
+		// A source has no short form, so both formats print it whole
+		if err := printJSONResult(cmd.OutOrStdout(), source); err != nil {
+			logger.Fatal().Err(err).Msg("Failed to print source")
 		}
-		logger.Info().Msg(string(jsonOutput))
 	},
 }
 
@@ -87,7 +190,7 @@ var sourcesCreateCmd = &cobra.Command{
 	Short: "Create a new source",
 	Run: func(cmd *cobra.Command, _ []string) {
 		logger := util.NewLogger(zerolog.ErrorLevel)
-		database, err := db.NewConnection()
+		database, err := openDatabase()
 		if err != nil {
 			logger.Fatal().Err(err).Msgf("Failed to connect to database: %v\n", err)
 		}
@@ -98,6 +201,7 @@ var sourcesCreateCmd = &cobra.Command{
 		authorEmail, _ := cmd.Flags().GetString("author-email")
 		activeDomain, _ := cmd.Flags().GetInt("active-domain")
 		format, _ := cmd.Flags().GetString("format")
+		collection, _ := cmd.Flags().GetString("collection")
 
 		if strings.EqualFold(id, "") || strings.EqualFold(rawURL, "") {
 			logger.Fatal().Err(err).Msgf("ID and URL are required")
@@ -107,6 +211,7 @@ var sourcesCreateCmd = &cobra.Command{
 			ID:           id,
 			RawURL:       &rawURL,
 			ActiveDomain: activeDomain,
+			Collection:   collection,
 			CreatedAt:    time.Now(),
 			UpdatedAt:    time.Now(),
 		}
@@ -124,29 +229,148 @@ var sourcesCreateCmd = &cobra.Command{
 			logger.Fatal().Err(err).Msgf("Failed to create source: %v\n", err)
 		}
 
-		logger.Info().Msgf("Source created successfully with ID: %s\n", id)
+		err = printResult(cmd, source, func(w io.Writer) {
+			fmt.Fprintf(w, "Source created successfully with ID: %s\n", id)
+		})
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to print source")
+		}
 	},
 }
 
 var sourcesDeleteCmd = &cobra.Command{
 	Use:   "delete [id]",
-	Short: "Delete a source by ID",
+	Short: "Soft-delete a source by ID (use restore to undo, or --cascade to delete it permanently)",
 	Args:  cobra.ExactArgs(1),
-	Run: func(_ *cobra.Command, args []string) {
+	Run: func(cmd *cobra.Command, args []string) {
 		logger := util.NewLogger(zerolog.ErrorLevel)
-		database, err := db.NewConnection()
+		database, err := openDatabase()
 		if err != nil {
 			logger.Fatal().Err(err).Msgf("Failed to connect to database: %v\n", err)
 		}
 		defer database.Close()
 
 		repo := repository.NewSourceRepository(database)
+		if cascade, _ := cmd.Flags().GetBool("cascade"); cascade {
+			if err := repo.Purge(args[0]); err != nil {
+				logger.Fatal().Err(err).Msgf("Failed to delete source: %v\n", err)
+			}
+			printAction(cmd, logger, args[0], "purged",
+				"Source and its downloads, documents, chunks, and embeddings deleted: "+args[0])
+			return
+		}
+
 		err = repo.Delete(args[0])
 		if err != nil {
 			logger.Fatal().Err(err).Msgf("Failed to delete source: %v\n", err)
 		}
 
-		logger.Info().Msgf("Source deleted successfully: %s\n", args[0])
+		printAction(cmd, logger, args[0], "deleted", "Source deleted successfully: "+args[0])
+	},
+}
+
+var sourcesRestoreCmd = &cobra.Command{
+	Use:   "restore [id]",
+	Short: "Restore a soft-deleted source by ID",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		logger := util.NewLogger(zerolog.ErrorLevel)
+		database, err := openDatabase()
+		if err != nil {
+			logger.Fatal().Err(err).Msgf("Failed to connect to database: %v\n", err)
+		}
+		defer database.Close()
+
+		repo := repository.NewSourceRepository(database)
+		err = repo.Restore(args[0])
+		if err != nil {
+			logger.Fatal().Err(err).Msgf("Failed to restore source: %v\n", err)
+		}
+
+		printAction(cmd, logger, args[0], "restored", "Source restored successfully: "+args[0])
+	},
+}
+
+var sourcesDoctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "List sources whose imports keep failing or that have not been imported successfully for a while",
+	Long: `List the sources whose last imports failed, with how many failed in a row and the last error,
+and those not imported successfully within --stale, so dead feeds and revoked tokens show up
+instead of silently going stale. Sources with the most failures in a row come first.`,
+	Run: func(cmd *cobra.Command, _ []string) {
+		logger := util.NewLogger(zerolog.ErrorLevel)
+		database, err := openDatabase()
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to connect to database")
+		}
+		defer database.Close()
+
+		stale, _ := cmd.Flags().GetDuration("stale")
+		minFailures, _ := cmd.Flags().GetInt("min-failures")
+		limit, _ := cmd.Flags().GetInt("limit")
+		collection, _ := cmd.Flags().GetString("collection")
+
+		unhealthy, err := repository.NewSourceRepository(database).Doctor(repository.DoctorOptions{
+			StaleAfter:  stale,
+			MinFailures: minFailures,
+			Limit:       limit,
+			Collection:  collection,
+		})
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to check source health")
+		}
+
+		err = printResult(cmd, nonNil(unhealthy), func(w io.Writer) {
+			if len(unhealthy) == 0 {
+				fmt.Fprintln(w, "All sources are healthy")
+				return
+			}
+			for _, health := range unhealthy {
+				fmt.Fprintf(w, "%s  %s  failures=%d last_success=%s\n", health.SourceID,
+					stringOr(health.RawURL, "-"), health.ConsecutiveFailures, timeOr(health.LastSuccessAt, "never"))
+				if health.LastError != nil {
+					fmt.Fprintf(w, "    %s\n", *health.LastError)
+				}
+			}
+		})
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to print source health")
+		}
+	},
+}
+
+var sourcesPurgeCmd = &cobra.Command{
+	Use:   "purge [id]",
+	Short: "Permanently delete a source and all its downloads, documents, chunks, and embeddings",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		logger := util.NewLogger(zerolog.ErrorLevel)
+		database, err := openDatabase()
+		if err != nil {
+			logger.Fatal().Err(err).Msgf("Failed to connect to database: %v\n", err)
+		}
+		defer database.Close()
+
+		repo := repository.NewSourceRepository(database)
+		if len(args) == 1 {
+			if err := repo.Purge(args[0]); err != nil {
+				logger.Fatal().Err(err).Msgf("Failed to purge source: %v\n", err)
+			}
+			printAction(cmd, logger, args[0], "purged", "Source purged successfully: "+args[0])
+			return
+		}
+
+		olderThan, _ := cmd.Flags().GetDuration("deleted-before")
+		count, err := repo.PurgeDeleted(time.Now().Add(-olderThan))
+		if err != nil {
+			logger.Fatal().Err(err).Msgf("Failed to purge deleted sources: %v\n", err)
+		}
+		err = printResult(cmd, map[string]int{"purged": count}, func(w io.Writer) {
+			fmt.Fprintf(w, "Purged %d deleted sources\n", count)
+		})
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to print result")
+		}
 	},
 }
 
@@ -154,12 +378,40 @@ func init() {
 	rootCmd.AddCommand(sourcesCmd)
 	sourcesCmd.AddCommand(sourcesListCmd)
 	sourcesCmd.AddCommand(sourcesGetCmd)
+	sourcesCmd.AddCommand(sourcesShowCmd)
 	sourcesCmd.AddCommand(sourcesCreateCmd)
 	sourcesCmd.AddCommand(sourcesDeleteCmd)
+	sourcesCmd.AddCommand(sourcesRestoreCmd)
+	sourcesCmd.AddCommand(sourcesPurgeCmd)
+	sourcesCmd.AddCommand(sourcesDoctorCmd)
+
+	sourcesListCmd.Flags().Int("limit", 0, "Maximum number of sources to return (0 for all)")
+	sourcesListCmd.Flags().Int("offset", 0, "Number of sources to skip")
+	sourcesListCmd.Flags().String("sort", "", "Sort by created_at, updated_at, host, format, raw_url, or collection")
+	sourcesListCmd.Flags().String("order", "", "Sort order (asc or desc)")
+	sourcesListCmd.Flags().String("host", "", "Only list sources from this host")
+	sourcesListCmd.Flags().String("format", "", "Only list sources with this format")
+	sourcesListCmd.Flags().String("collection", "", "Only list sources in this collection")
+	sourcesListCmd.Flags().String("owner", "", "Only list sources owned by this team or person")
+	sourcesListCmd.Flags().String("label", "", "Only list sources with this access-control label")
+	sourcesListCmd.Flags().Bool("json", false, "Print the sources and their counts as JSON (same as --output json)")
+	sourcesShowCmd.Flags().Bool("json", false, "Print the source and its counts as JSON (same as --output json)")
 
 	sourcesCreateCmd.Flags().String("id", "", "Source ID (required)")
 	sourcesCreateCmd.Flags().String("url", "", "Raw URL (required)")
 	sourcesCreateCmd.Flags().String("author-email", "", "Author email")
 	sourcesCreateCmd.Flags().Int("active-domain", 1, "Active domain (0 or 1)")
 	sourcesCreateCmd.Flags().String("format", "", "Format (json, yml, yaml)")
+	sourcesCreateCmd.Flags().String("collection", interfaces.DefaultCollection, "Collection the source belongs to")
+
+	sourcesDeleteCmd.Flags().
+		Bool("cascade", false, "Permanently delete the source with its downloads, documents, chunks, and embeddings")
+	sourcesPurgeCmd.Flags().
+		Duration("deleted-before", 0, "Without an ID, purge sources soft-deleted at least this long ago")
+
+	sourcesDoctorCmd.Flags().Duration("stale", repository.DefaultStaleAfter,
+		"Also list sources not imported successfully for this long, or ever (0 lists only failing sources)")
+	sourcesDoctorCmd.Flags().Int("min-failures", 1, "List sources whose last imports failed at least this many times")
+	sourcesDoctorCmd.Flags().Int("limit", 50, "Maximum number of sources to list (0 for all)")
+	sourcesDoctorCmd.Flags().String("collection", "", "Only check sources in this collection")
 }
@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/code-sleuth/ike-go/internal/manager/repository"
+	"github.com/code-sleuth/ike-go/pkg/migrations"
+	"github.com/code-sleuth/ike-go/pkg/util"
+
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+)
+
+var statusCmd = &cobra.Command{
+	Use:         "status",
+	Short:       "Show the schema version and what the database holds",
+	Annotations: map[string]string{skipSchemaCheck: "true"},
+	Long: `Show the database's schema version, how many sources, documents, chunks, and embeddings it
+holds, and the work still outstanding: dead-lettered chunks, queued jobs, and pipeline runs that
+the next import will resume.
+
+With --collection, sources, documents, chunks, embeddings, and dead-lettered chunks are counted for
+that collection only; jobs and resumable runs are always counted for the whole database.
+
+Status runs against databases at any schema version; the counts are only shown once the schema is
+up to date.`,
+	Run: func(cmd *cobra.Command, _ []string) {
+		logger := util.NewLogger(zerolog.ErrorLevel)
+
+		database, err := openDatabase()
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to connect to database")
+		}
+		defer database.Close()
+
+		current, err := migrations.Current(cmd.Context(), database.DB)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to read schema version")
+		}
+		latest, err := migrations.Latest()
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to read migrations")
+		}
+		if current == "" {
+			current = "unversioned"
+		}
+		result := statusResult{Schema: current, ExpectedSchema: latest}
+		if err := migrations.Check(cmd.Context(), database.DB); err != nil {
+			result.SchemaError = err.Error()
+		} else {
+			collection, _ := cmd.Flags().GetString("collection")
+			result.Status, err = repository.NewStatusRepository(database).GetCollection(collection)
+			if err != nil {
+				logger.Fatal().Err(err).Msg("Failed to get status")
+			}
+		}
+
+		err = printResult(cmd, result, func(w io.Writer) {
+			fmt.Fprintf(w, "Schema:          %s (this binary expects %s)\n", current, latest)
+			if result.SchemaError != "" {
+				fmt.Fprintln(w, result.SchemaError)
+				return
+			}
+			fmt.Fprint(w, formatStatus(result.Status))
+		})
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to print status")
+		}
+	},
+}
+
+// statusResult is what status shows. The counts are left out while the schema doesn't match.
+type statusResult struct {
+	Schema         string `json:"schema"`
+	ExpectedSchema string `json:"expected_schema"`
+	SchemaError    string `json:"schema_error,omitempty"`
+	*repository.Status
+}
+
+// formatStatus renders status as aligned lines.
+func formatStatus(status *repository.Status) string {
+	var b strings.Builder
+	if status.Collection != "" {
+		fmt.Fprintf(&b, "Collection:      %s\n", status.Collection)
+	}
+	fmt.Fprintf(&b, "Sources:         %d\n", status.Sources)
+	fmt.Fprintf(&b, "Documents:       %d\n", status.Documents)
+	fmt.Fprintf(&b, "Chunks:          %d\n", status.Chunks)
+	fmt.Fprintf(&b, "Embeddings:      %s\n", formatCounts(status.Embeddings))
+	fmt.Fprintf(&b, "Failed chunks:   %d\n", status.FailedChunks)
+	jobs := make(map[string]int, len(status.Jobs))
+	for jobStatus, n := range status.Jobs {
+		jobs[string(jobStatus)] = n
+	}
+	fmt.Fprintf(&b, "Jobs:            %s\n", formatCounts(jobs))
+	fmt.Fprintf(&b, "Resumable runs:  %d\n", status.ResumableRuns)
+	return b.String()
+}
+
+// formatCounts renders counts as "key n" pairs sorted by key, or "none".
+func formatCounts(counts map[string]int) string {
+	if len(counts) == 0 {
+		return "none"
+	}
+	var pairs []string
+	for _, key := range slices.Sorted(maps.Keys(counts)) {
+		pairs = append(pairs, fmt.Sprintf("%s %d", key, counts[key]))
+	}
+	return strings.Join(pairs, ", ")
+}
+
+func init() {
+	rootCmd.AddCommand(statusCmd)
+
+	statusCmd.Flags().String("collection", "", "Only count the content of this collection")
+}
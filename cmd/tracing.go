@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/code-sleuth/ike-go/pkg/tracing"
+
+	"github.com/rs/zerolog"
+)
+
+// tracingFlushTimeout bounds how long exiting waits for the last spans to reach the collector.
+const tracingFlushTimeout = 5 * time.Second
+
+// setupTracing installs a tracer when an OTLP endpoint is configured in the environment; see
+// tracing.FromEnv.
+func setupTracing(logger zerolog.Logger) {
+	tracer, err := tracing.FromEnv()
+	switch {
+	case errors.Is(err, tracing.ErrNotConfigured):
+		return
+	case err != nil:
+		logger.Fatal().Err(err).Msg("Invalid tracing configuration")
+	}
+	tracing.SetTracer(tracer)
+}
+
+// shutdownTracing exports the spans still queued before the process exits.
+func shutdownTracing(logger zerolog.Logger) {
+	ctx, cancel := context.WithTimeout(context.Background(), tracingFlushTimeout)
+	defer cancel()
+
+	if err := tracing.Shutdown(ctx); err != nil {
+		logger.Error().Err(err).Msg("Failed to export traces")
+	}
+}
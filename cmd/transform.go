@@ -3,7 +3,6 @@ package cmd
 import (
 	"context"
 	"database/sql"
-	"time"
 
 	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
 	"github.com/code-sleuth/ike-go/internal/manager/services"
@@ -18,8 +17,10 @@ var downloadID string
 
 // transformCmd represents the transform command.
 var transformCmd = &cobra.Command{
-	Use:   "transform",
-	Short: "Transform existing downloads into documents and chunks",
+	Use: "transform",
+	// process matches the pipeline's name for this step, ProcessingEngine.ProcessDocument
+	Aliases: []string{"process"},
+	Short:   "Transform existing downloads into documents and chunks",
 	Long: `Transform existing download records into structured documents, chunk them, and generate embeddings.
 	
 Examples:
@@ -34,20 +35,15 @@ Examples:
 func init() {
 	rootCmd.AddCommand(transformCmd)
 
-	var (
-		maxTokens   = 8191
-		concurrency = 5
-		timeout     = 5 * time.Minute
-	)
-
 	// Add flags
 	transformCmd.Flags().StringVarP(&downloadID, "download-id", "d", "", "Download ID to transform (required)")
 	transformCmd.Flags().StringVarP(&embeddingModel, "model", "m", "text-embedding-3-small", "Embedding model to use")
 	transformCmd.Flags().
 		StringVarP(&chunkStrategy, "strategy", "s", "token", "Chunking strategy (token, heading, recursive)")
-	transformCmd.Flags().IntVarP(&maxTokens, "tokens", "t", maxTokens, "Maximum tokens per chunk")
-	transformCmd.Flags().IntVarP(&concurrency, "concurrency", "c", concurrency, "Number of concurrent operations")
-	transformCmd.Flags().DurationVar(&timeout, "timeout", timeout, "Timeout for the entire operation")
+	transformCmd.Flags().IntVarP(&maxTokens, "tokens", "t", defaultMaxTokens, "Maximum tokens per chunk")
+	transformCmd.Flags().
+		IntVarP(&concurrency, "concurrency", "c", defaultConcurrency, "Number of concurrent operations")
+	transformCmd.Flags().DurationVar(&timeout, "timeout", defaultTimeout, "Timeout for the entire operation")
 
 	// Mark required flags
 	err := transformCmd.MarkFlagRequired("download-id")
@@ -56,12 +52,12 @@ func init() {
 	}
 }
 
-func runTransform(_ *cobra.Command, _ []string) {
+func runTransform(cmd *cobra.Command, _ []string) {
 	logger := util.NewLogger(zerolog.InfoLevel)
 	logger.Info().Str("download_id", downloadID).Msg("Starting transformation")
 
 	// Create context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
 	defer cancel()
 
 	// Connect to database
@@ -99,6 +95,8 @@ func runTransform(_ *cobra.Command, _ []string) {
 		EmbeddingModel: embeddingModel,
 		Concurrency:    concurrency,
 		Timeout:        timeout,
+		// Re-processing a download is an explicit request, so never skip it as unchanged
+		Force: true,
 	}
 
 	// Run the transformation
@@ -107,4 +105,5 @@ func runTransform(_ *cobra.Command, _ []string) {
 	}
 
 	logger.Info().Msg("Transformation completed successfully!")
+	printAction(cmd, logger, downloadID, "transformed", "")
 }
@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/code-sleuth/ike-go/internal/manager/models"
+	"github.com/code-sleuth/ike-go/internal/manager/repository"
+	"github.com/code-sleuth/ike-go/pkg/db"
+	"github.com/code-sleuth/ike-go/pkg/util"
+
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// clearScreen moves the cursor home and clears the terminal before each redraw.
+	clearScreen = "\033[H\033[2J"
+	// maxErrorWidth is how much of an error message the dashboard shows.
+	maxErrorWidth = 100
+)
+
+var tuiCmd = &cobra.Command{
+	Use:   "tui",
+	Short: "Show a live dashboard of jobs, imports in progress, errors, and corpus stats",
+	Long: `Show a dashboard that redraws every --interval with the jobs workers are running, the progress of
+each source being imported, the most recent errors, and how much the database holds. It reads the
+database only, so it can watch imports run by "ike-go import", workers, or the daemon on other
+hosts. Press Ctrl-C to quit. With --output json, one snapshot is printed and the command exits.`,
+	Run: func(cmd *cobra.Command, _ []string) {
+		logger := util.NewLogger(zerolog.ErrorLevel)
+
+		interval, _ := cmd.Flags().GetDuration("interval")
+		errorLimit, _ := cmd.Flags().GetInt("errors")
+		if interval <= 0 {
+			logger.Fatal().Dur("interval", interval).Msg("--interval must be positive")
+		}
+
+		database, err := openDatabase()
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to connect to database")
+		}
+		defer database.Close()
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			snapshot, err := loadDashboard(database, errorLimit)
+			if err != nil {
+				logger.Fatal().Err(err).Msg("Failed to load dashboard")
+			}
+			if jsonOutput(cmd) {
+				// Scripts get a single snapshot rather than a stream of redraws
+				if err := printJSONResult(cmd.OutOrStdout(), snapshot); err != nil {
+					logger.Fatal().Err(err).Msg("Failed to print dashboard")
+				}
+				return
+			}
+			fmt.Print(clearScreen + renderDashboard(snapshot, interval, time.Now()))
+
+			select {
+			case <-ctx.Done():
+				fmt.Println()
+				return
+			case <-ticker.C:
+			}
+		}
+	},
+}
+
+// dashboard is what one redraw of the tui command shows.
+type dashboard struct {
+	Status   *repository.Status       `json:"status"`
+	Jobs     []models.Job             `json:"jobs"`
+	Runs     []repository.RunProgress `json:"runs"`
+	Failures []repository.Failure     `json:"failures"`
+}
+
+// loadDashboard reads everything the dashboard shows from database.
+func loadDashboard(database *db.DB, errorLimit int) (*dashboard, error) {
+	statusRepo := repository.NewStatusRepository(database)
+	status, err := statusRepo.Get()
+	if err != nil {
+		return nil, err
+	}
+	jobs, err := repository.NewJobRepository(database).ListWithOptions(repository.JobListOptions{
+		ListOptions: repository.ListOptions{SortBy: "created_at", Order: repository.SortAsc},
+		Status:      models.JobRunning,
+	})
+	if err != nil {
+		return nil, err
+	}
+	runs, err := statusRepo.ActiveRuns()
+	if err != nil {
+		return nil, err
+	}
+	failures, err := statusRepo.RecentFailures(errorLimit)
+	if err != nil {
+		return nil, err
+	}
+	return &dashboard{Status: status, Jobs: nonNil(jobs), Runs: nonNil(runs), Failures: nonNil(failures)}, nil
+}
+
+// renderDashboard lays out d as it stood at now.
+func renderDashboard(d *dashboard, interval time.Duration, now time.Time) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "ike-go  %s  (every %s, Ctrl-C to quit)\n\n", now.Format(time.DateTime), interval)
+
+	embeddings := 0
+	for _, n := range d.Status.Embeddings {
+		embeddings += n
+	}
+	fmt.Fprintf(&b, "Corpus       %d sources  %d documents  %d chunks  %d embeddings\n",
+		d.Status.Sources, d.Status.Documents, d.Status.Chunks, embeddings)
+	fmt.Fprintf(&b, "Outstanding  %d queued jobs  %d failed chunks  %d resumable runs\n\n",
+		d.Status.Jobs[models.JobQueued], d.Status.FailedChunks, d.Status.ResumableRuns)
+
+	fmt.Fprintf(&b, "Active jobs (%d)\n", len(d.Jobs))
+	for _, job := range d.Jobs {
+		worker := ""
+		if job.WorkerID != nil {
+			worker = *job.WorkerID
+		}
+		running := job.UpdatedAt
+		if job.LockedAt != nil {
+			running = *job.LockedAt
+		}
+		fmt.Fprintf(&b, "  %s  %-7s %-20s attempt %d/%d  running %s\n", job.ID, job.Kind, worker,
+			job.Attempts, job.MaxAttempts, since(running, now))
+	}
+
+	fmt.Fprintf(&b, "\nSources in progress (%d)\n", len(d.Runs))
+	for _, run := range d.Runs {
+		fmt.Fprintf(&b, "  %s  %d/%d items done, %d failed  running %s, last progress %s ago\n", run.SourceURL,
+			run.Done, run.Items, run.Failed, since(run.StartedAt, now), since(run.UpdatedAt, now))
+	}
+
+	fmt.Fprintf(&b, "\nRecent errors (%d)\n", len(d.Failures))
+	for _, failure := range d.Failures {
+		fmt.Fprintf(&b, "  %s  %-5s %s  %s\n", failure.At.Local().Format(time.DateTime), failure.Kind, failure.ID,
+			truncate(strings.Join(strings.Fields(failure.Error), " "), maxErrorWidth))
+	}
+	return b.String()
+}
+
+// since returns the time from t to now rounded to seconds.
+func since(t, now time.Time) time.Duration {
+	return now.Sub(t).Round(time.Second)
+}
+
+// truncate shortens s to at most n runes, marking the cut with "...".
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-3]) + "..."
+}
+
+func init() {
+	rootCmd.AddCommand(tuiCmd)
+
+	tuiCmd.Flags().Duration("interval", 2*time.Second, "How often to redraw the dashboard")
+	tuiCmd.Flags().Int("errors", 10, "Number of recent errors to show")
+}
@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/code-sleuth/ike-go/internal/manager/models"
+	"github.com/code-sleuth/ike-go/internal/manager/notifier"
+	"github.com/code-sleuth/ike-go/internal/manager/repository"
+	"github.com/code-sleuth/ike-go/pkg/util"
+
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+)
+
+// defaultDeliveryLimit is how many deliveries are listed unless --limit is given.
+const defaultDeliveryLimit = 20
+
+var webhooksCmd = &cobra.Command{
+	Use:   "webhooks",
+	Short: "Manage outbound webhooks",
+	Long: `Manage the webhooks "ike-go daemon" calls when imports complete, documents change, or jobs
+fail, so downstream systems can react without polling.
+
+Each event from the event log (see "ike-go events") is POSTed to the subscribed URL as JSON, with
+its type in X-Ike-Event, its ID in X-Ike-Delivery, and X-Webhook-Signature set to "sha256=" and
+the hex HMAC-SHA256 of the body keyed with the subscription's secret. Any 2xx response counts as
+delivered; other responses are retried with backoff, up to 10 attempts.
+
+Event types: source_imported, document_created, chunks_embedded, update_detected,
+document_deleted, source_deleted, job_failed.`,
+}
+
+var webhooksAddCmd = &cobra.Command{
+	Use:   "add",
+	Short: "Subscribe a URL to events",
+	Example: `  ike-go webhooks add --url https://example.com/hooks/ike
+  ike-go webhooks add --url https://example.com/hooks/ike --events source_imported,job_failed`,
+	Run: func(cmd *cobra.Command, _ []string) {
+		logger := util.NewLogger(zerolog.ErrorLevel)
+
+		url, _ := cmd.Flags().GetString("url")
+		secret, _ := cmd.Flags().GetString("secret")
+		eventNames, _ := cmd.Flags().GetStringSlice("events")
+
+		eventTypes := make([]models.EventType, len(eventNames))
+		for i, name := range eventNames {
+			eventTypes[i] = models.EventType(strings.TrimSpace(name))
+		}
+		subscription, err := notifier.NewSubscription(url, secret, eventTypes)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Invalid webhook")
+		}
+
+		database, err := openDatabase()
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to connect to database")
+		}
+		defer database.Close()
+
+		if err := repository.NewWebhookSubscriptionRepository(database).Create(subscription); err != nil {
+			logger.Fatal().Err(err).Msg("Failed to create webhook")
+		}
+
+		// The secret is only shown here, for configuring the receiving end
+		result := struct {
+			*models.WebhookSubscription
+			Secret string `json:"secret"`
+		}{subscription, subscription.Secret}
+		err = printResult(cmd, result, func(w io.Writer) {
+			fmt.Fprintln(w, subscription.ID)
+			if secret == "" {
+				fmt.Fprintf(w, "secret: %s\n", subscription.Secret)
+			}
+		})
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to print webhook")
+		}
+	},
+}
+
+var webhooksListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List outbound webhooks",
+	Run: func(cmd *cobra.Command, _ []string) {
+		logger := util.NewLogger(zerolog.ErrorLevel)
+
+		database, err := openDatabase()
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to connect to database")
+		}
+		defer database.Close()
+
+		subscriptions, err := repository.NewWebhookSubscriptionRepository(database).List()
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to list webhooks")
+		}
+
+		err = printResult(cmd, nonNil(subscriptions), func(w io.Writer) {
+			for _, subscription := range subscriptions {
+				events := "all events"
+				if len(subscription.EventTypes) > 0 {
+					events = joinEventTypes(subscription.EventTypes)
+				}
+				fmt.Fprintf(w, "%s  %s  %s\n", subscription.ID, subscription.URL, events)
+			}
+		})
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to print webhooks")
+		}
+	},
+}
+
+var webhooksRemoveCmd = &cobra.Command{
+	Use:   "remove [id]",
+	Short: "Remove an outbound webhook and its pending deliveries",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		logger := util.NewLogger(zerolog.ErrorLevel)
+
+		database, err := openDatabase()
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to connect to database")
+		}
+		defer database.Close()
+
+		if err := repository.NewWebhookSubscriptionRepository(database).Delete(args[0]); err != nil {
+			logger.Fatal().Err(err).Msg("Failed to remove webhook")
+		}
+		printAction(cmd, logger, args[0], "removed", "")
+	},
+}
+
+var webhooksDeliveriesCmd = &cobra.Command{
+	Use:   "deliveries [id]",
+	Short: "Show recent deliveries to an outbound webhook",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		logger := util.NewLogger(zerolog.ErrorLevel)
+
+		limit, _ := cmd.Flags().GetInt("limit")
+
+		database, err := openDatabase()
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to connect to database")
+		}
+		defer database.Close()
+
+		deliveries, err := repository.NewWebhookSubscriptionRepository(database).Deliveries(args[0], limit)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to list deliveries")
+		}
+
+		err = printResult(cmd, nonNil(deliveries), func(w io.Writer) {
+			for _, delivery := range deliveries {
+				line := fmt.Sprintf("%s  %-9s attempts=%d  %s", formatEvent(delivery.Event), delivery.Status,
+					delivery.Attempts, delivery.Event.ID)
+				if delivery.LastError != nil {
+					line += "  error=" + *delivery.LastError
+				}
+				fmt.Fprintln(w, line)
+			}
+		})
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to print deliveries")
+		}
+	},
+}
+
+// joinEventTypes renders eventTypes as a comma-separated list.
+func joinEventTypes(eventTypes []models.EventType) string {
+	names := make([]string, len(eventTypes))
+	for i, eventType := range eventTypes {
+		names[i] = string(eventType)
+	}
+	return strings.Join(names, ",")
+}
+
+func init() {
+	rootCmd.AddCommand(webhooksCmd)
+	webhooksCmd.AddCommand(webhooksAddCmd)
+	webhooksCmd.AddCommand(webhooksListCmd)
+	webhooksCmd.AddCommand(webhooksRemoveCmd)
+	webhooksCmd.AddCommand(webhooksDeliveriesCmd)
+
+	webhooksAddCmd.Flags().StringP("url", "u", "", "URL to POST events to (required)")
+	webhooksAddCmd.Flags().
+		String("secret", "", "Secret to sign deliveries with (default a generated one, printed once)")
+	webhooksAddCmd.Flags().StringSlice("events", nil, "Event types to deliver, comma-separated (default all)")
+	_ = webhooksAddCmd.MarkFlagRequired("url")
+
+	webhooksDeliveriesCmd.Flags().IntP("limit", "l", defaultDeliveryLimit, "Maximum number of deliveries to show")
+}
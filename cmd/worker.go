@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/code-sleuth/ike-go/internal/manager/models"
+	"github.com/code-sleuth/ike-go/internal/manager/repository"
+	"github.com/code-sleuth/ike-go/internal/manager/services"
+	"github.com/code-sleuth/ike-go/pkg/util"
+
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+)
+
+var ErrInvalidPriority = errors.New("priority must be high, normal, low, or an integer")
+
+// defaultShutdownTimeout is how long in-flight work may run after SIGINT or SIGTERM before it is
+// cancelled. Container runtimes typically wait 30 seconds before killing the process.
+const defaultShutdownTimeout = 25 * time.Second
+
+var workerCmd = &cobra.Command{
+	Use:   "worker",
+	Short: "Run queued processing jobs",
+	Long: `Claim and run jobs from the database-backed queue, such as imports enqueued with
+"ike-go import --queue". Any number of workers can share one database; each job is run by a
+single worker. Workers renew the lock on the job they are running every third of --lease; jobs
+held by a worker that died are requeued once their lock has not been renewed for --lease, or left
+failed when they have used all their attempts.
+
+On SIGINT or SIGTERM the worker stops claiming jobs and lets the running job finish its current
+document. If that takes longer than --shutdown-timeout the job is cancelled; either way an
+unfinished job is returned to the queue and resumes where it stopped.`,
+	Example: `  ike-go worker
+  ike-go worker --once`,
+	Run: func(cmd *cobra.Command, _ []string) {
+		logger := util.NewLogger(zerolog.ErrorLevel)
+
+		poll, _ := cmd.Flags().GetDuration("poll")
+		lease, _ := cmd.Flags().GetDuration("lease")
+		once, _ := cmd.Flags().GetBool("once")
+		shutdownTimeout, _ := cmd.Flags().GetDuration("shutdown-timeout")
+		metricsAddr, _ := cmd.Flags().GetString("metrics-addr")
+		workerID, _ := cmd.Flags().GetString("id")
+		if workerID == "" {
+			workerID = defaultWorkerID()
+		}
+
+		database, err := openDatabase()
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to connect to database")
+		}
+		defer database.Close()
+
+		stopMetrics := serveMetrics(metricsAddr, logger)
+		defer stopMetrics()
+
+		engine := newProcessingEngine(logger)
+		engine.SetDialect(database.Dialect())
+		queue := repository.NewJobRepository(database)
+
+		// Signals drain the engine rather than cancelling the job outright
+		signals, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		go shutdownOnDone(signals, engine, shutdownTimeout, logger)
+		ctx := cmd.Context()
+
+		if once {
+			ran, err := engine.Drain(ctx, queue, database.DB, workerID, lease)
+			if err != nil {
+				logger.Fatal().Err(err).Msg("Worker failed")
+			}
+			err = printResult(cmd, map[string]int{"ran": ran}, func(w io.Writer) {
+				fmt.Fprintf(w, "Ran %d jobs\n", ran)
+			})
+			if err != nil {
+				logger.Fatal().Err(err).Msg("Failed to print result")
+			}
+			return
+		}
+
+		if err := engine.Work(ctx, queue, database.DB, workerID, poll, lease); err != nil {
+			logger.Fatal().Err(err).Msg("Worker failed")
+		}
+	},
+}
+
+// defaultWorkerID identifies this process on the jobs it claims.
+func defaultWorkerID() string {
+	hostname, _ := os.Hostname()
+	return fmt.Sprintf("%s:%d", hostname, os.Getpid())
+}
+
+// shutdownOnDone drains engine once ctx is done, giving in-flight work up to timeout to finish.
+func shutdownOnDone(ctx context.Context, engine *services.ProcessingEngine, timeout time.Duration,
+	logger zerolog.Logger,
+) {
+	<-ctx.Done()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := engine.Shutdown(shutdownCtx); err != nil {
+		logger.Warn().Err(err).Msg("In-flight work was cancelled before it finished")
+	}
+}
+
+// parsePriority accepts a job priority as high, normal, low, or any integer.
+func parsePriority(value string) (int, error) {
+	switch strings.ToLower(value) {
+	case "high":
+		return models.JobPriorityHigh, nil
+	case "normal", "":
+		return models.JobPriorityNormal, nil
+	case "low":
+		return models.JobPriorityLow, nil
+	}
+	priority, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidPriority, value)
+	}
+	return priority, nil
+}
+
+var jobsCmd = &cobra.Command{
+	Use:   "jobs",
+	Short: "Inspect the processing job queue",
+}
+
+var jobsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List jobs",
+	Run: func(cmd *cobra.Command, _ []string) {
+		logger := util.NewLogger(zerolog.ErrorLevel)
+
+		database, err := openDatabase()
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to connect to database")
+		}
+		defer database.Close()
+
+		limit, _ := cmd.Flags().GetInt("limit")
+		status, _ := cmd.Flags().GetString("status")
+		kind, _ := cmd.Flags().GetString("kind")
+		sortBy, _ := cmd.Flags().GetString("sort")
+
+		jobs, err := repository.NewJobRepository(database).ListWithOptions(repository.JobListOptions{
+			ListOptions: repository.ListOptions{Limit: limit, SortBy: sortBy},
+			Status:      models.JobStatus(status),
+			Kind:        kind,
+		})
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to list jobs")
+		}
+
+		err = printResult(cmd, nonNil(jobs), func(w io.Writer) {
+			for _, job := range jobs {
+				line := fmt.Sprintf("%s  %-8s %-7s priority=%d attempts=%d/%d  %s", job.ID, job.Status, job.Kind,
+					job.Priority, job.Attempts, job.MaxAttempts, job.CreatedAt.Format(time.RFC3339))
+				if job.Error != nil {
+					line += "  error=" + *job.Error
+				}
+				fmt.Fprintln(w, line)
+			}
+		})
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to print jobs")
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(workerCmd)
+	rootCmd.AddCommand(jobsCmd)
+	jobsCmd.AddCommand(jobsListCmd)
+
+	workerCmd.Flags().Duration("poll", 5*time.Second, "How often to check the queue for new jobs")
+	workerCmd.Flags().Duration("lease", time.Hour,
+		"Requeue running jobs whose worker has not renewed their lock for this long")
+	workerCmd.Flags().Bool("once", false, "Run the queued jobs and exit instead of polling")
+	workerCmd.Flags().String("id", "", "Worker ID recorded on claimed jobs (default host:pid)")
+	workerCmd.Flags().String("metrics-addr", "", "Serve Prometheus metrics at GET /metrics on this address, e.g. :9090")
+	workerCmd.Flags().Duration("shutdown-timeout", defaultShutdownTimeout,
+		"How long the running job may continue after SIGINT or SIGTERM before it is cancelled")
+
+	jobsListCmd.Flags().Int("limit", 50, "Maximum number of jobs to list")
+	jobsListCmd.Flags().String("status", "", "Only list jobs with this status (queued, running, failed, done)")
+	jobsListCmd.Flags().String("sort", "created_at", "Sort by created_at, updated_at, status, kind, or priority")
+	jobsListCmd.Flags().String("kind", "", "Only list jobs of this kind (import, process)")
+}
@@ -2,16 +2,16 @@ package chunkers
 
 import (
 	"errors"
-	"os"
-	"strconv"
 	"strings"
+	"unicode/utf8"
 
+	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
 	"github.com/code-sleuth/ike-go/internal/manager/models"
+	"github.com/code-sleuth/ike-go/internal/manager/tokenizers"
 	"github.com/code-sleuth/ike-go/pkg/util"
 	"github.com/rs/zerolog"
 
 	"github.com/google/uuid"
-	"github.com/tiktoken-go/tokenizer"
 )
 
 var (
@@ -25,32 +25,59 @@ const (
 	overlapTokensDefault = 20
 )
 
-// TokenChunker implements token-based chunking using tiktoken.
+// TokenChunker implements token-based chunking, counting tokens with tiktoken unless given an
+// embedder's tokenizer.
 type TokenChunker struct {
-	encoding tokenizer.Codec
-	logger   zerolog.Logger
+	tokenizer interfaces.Tokenizer
+	logger    zerolog.Logger
 }
 
-// NewTokenChunker creates a new token-based chunker.
-func NewTokenChunker() (*TokenChunker, error) {
-	// Get log level from environment or default to error
-	logLevel := getLogLevelFromEnv()
-	logger := util.NewLogger(logLevel)
+// Option configures a TokenChunker when it is created.
+type Option func(*options)
 
-	// Get tokenizer from environment or default to cl100k_base
-	tokenizerName := getTokenizerFromEnv()
-	encoding, err := getTokenizerEncoding(tokenizerName)
+// options are the settings Options give; unset ones leave the chunker's default.
+type options struct {
+	tokenizerName string
+}
+
+// WithTokenizerName sizes chunks with the named tiktoken encoding, in place of cl100k_base.
+func WithTokenizerName(name string) Option {
+	return func(o *options) {
+		o.tokenizerName = name
+	}
+}
+
+// NewTokenChunker creates a new token-based chunker, configured by opts.
+func NewTokenChunker(opts ...Option) (*TokenChunker, error) {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	logger := util.ComponentLogger("chunkers.token", zerolog.ErrorLevel)
+
+	tokenizerName := o.tokenizerName
+	if tokenizerName == "" {
+		tokenizerName = tokenizers.Cl100kBase
+	}
+	tokenizer, err := getTokenizer(tokenizerName)
 	if err != nil {
 		logger.Error().Err(err).Str("tokenizer", tokenizerName).Msg("failed to get tokenizer")
 		return nil, err
 	}
 
 	return &TokenChunker{
-		encoding: encoding,
-		logger:   logger,
+		tokenizer: tokenizer,
+		logger:    logger,
 	}, nil
 }
 
+// WithTokenizer returns a chunker that sizes chunks and counts their tokens with tokenizer, such
+// as the tokenizer of the model that embeds them.
+func (t *TokenChunker) WithTokenizer(tokenizer interfaces.Tokenizer) interfaces.Chunker {
+	return &TokenChunker{tokenizer: tokenizer, logger: t.logger}
+}
+
 // GetChunkingStrategy returns the strategy name used by this chunker.
 func (t *TokenChunker) GetChunkingStrategy() string {
 	return "token"
@@ -58,78 +85,96 @@ func (t *TokenChunker) GetChunkingStrategy() string {
 
 // ChunkDocument splits a document into manageable chunks based on token count.
 func (t *TokenChunker) ChunkDocument(content string, maxTokens int) ([]*models.Chunk, error) {
+	var chunks []*models.Chunk
+	err := t.StreamChunks(content, maxTokens, func(chunk *models.Chunk) error {
+		// Link each chunk to its neighbours
+		if len(chunks) > 0 {
+			previous := chunks[len(chunks)-1]
+			previous.RightChunkID = &chunk.ID
+			chunk.LeftChunkID = &previous.ID
+		}
+		chunks = append(chunks, chunk)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return chunks, nil
+}
+
+// StreamChunks splits content into chunks of maxTokens tokens like ChunkDocument, handing each to
+// yield as soon as it is cut rather than returning them all, and stops with the first error yield
+// returns. Content is tokenized streamWindow bytes at a time, cut before a space so words stay
+// whole, so neither a large document's chunks nor its tokens are all in memory at once. The
+// chunks are not linked to their neighbours.
+func (t *TokenChunker) StreamChunks(content string, maxTokens int, yield func(*models.Chunk) error) error {
 	if content == "" {
 		t.logger.Warn().Msg("content is empty")
-		return nil, ErrContentEmpty
+		return ErrContentEmpty
 	}
 
 	if maxTokens <= 0 {
 		t.logger.Warn().Msg("maxTokens must be positive")
-		return nil, ErrInvalidMaxTokens
-	}
-
-	// Tokenize the entire content
-	tokens, _, err := t.encoding.Encode(content)
-	if err != nil {
-		t.logger.Err(err).Msg("failed to tokenize content")
-		return nil, err
-	}
-
-	totalTokens := len(tokens)
-
-	// If content fits in one chunk, return it as is
-	if totalTokens <= maxTokens {
-		chunk := &models.Chunk{
-			ID:         uuid.New().String(),
-			Body:       &content,
-			ByteSize:   intPtr(len([]byte(content))),
-			Tokenizer:  stringPtr(getTokenizerFromEnv()),
-			TokenCount: &totalTokens,
-		}
-		return []*models.Chunk{chunk}, nil
+		return ErrInvalidMaxTokens
 	}
 
-	// Split into multiple chunks
-	var chunks []*models.Chunk
-	var previousChunkID *string
-
-	for i := 0; i < totalTokens; i += maxTokens {
-		end := i + maxTokens
-		if end > totalTokens {
-			end = totalTokens
-		}
+	// carry holds the text of the tokens at the end of a window too few to fill a chunk; they
+	// are tokenized again with the next window
+	carry := ""
+	for rest := content; rest != ""; {
+		window := rest[:windowEnd(rest)]
+		rest = rest[len(window):]
 
-		// Get the token slice
-		chunkTokens := tokens[i:end]
-
-		// Decode back to text
-		chunkText, err := t.encoding.Decode(chunkTokens)
+		tokens, err := t.tokenizer.Tokenize(carry + window)
 		if err != nil {
-			t.logger.Err(err).Msg("failed to decode chunk tokens")
-			return nil, err
+			t.logger.Err(err).Msg("failed to tokenize content")
+			return err
 		}
 
-		// Create chunk
-		chunkID := uuid.New().String()
-		chunk := &models.Chunk{
-			ID:          chunkID,
-			Body:        &chunkText,
-			ByteSize:    intPtr(len([]byte(chunkText))),
-			Tokenizer:   stringPtr("cl100k_base"),
-			TokenCount:  intPtr(len(chunkTokens)),
-			LeftChunkID: previousChunkID,
+		// The last window's remaining tokens make the last chunk
+		for len(tokens) >= maxTokens || (rest == "" && len(tokens) > 0) {
+			n := min(maxTokens, len(tokens))
+			if err := yield(t.newChunk(tokens[:n])); err != nil {
+				return err
+			}
+			tokens = tokens[n:]
 		}
+		carry = strings.Join(tokens, "")
+	}
 
-		// Update previous chunk's right pointer
-		if len(chunks) > 0 {
-			chunks[len(chunks)-1].RightChunkID = &chunkID
-		}
+	return nil
+}
 
-		chunks = append(chunks, chunk)
-		previousChunkID = &chunkID
+// streamWindow is how many bytes of a document StreamChunks tokenizes at a time.
+const streamWindow = 1 << 20
+
+// windowEnd returns where the next window of text ends: at most streamWindow bytes in, before the
+// last space, or else at the last rune boundary.
+func windowEnd(text string) int {
+	if len(text) <= streamWindow {
+		return len(text)
 	}
+	if end := strings.LastIndexByte(text[:streamWindow], ' '); end > 0 {
+		return end
+	}
+	end := streamWindow
+	for end > 0 && !utf8.RuneStart(text[end]) {
+		end--
+	}
+	return end
+}
 
-	return chunks, nil
+// newChunk returns the chunk of tokens.
+func (t *TokenChunker) newChunk(tokens []string) *models.Chunk {
+	// Tokens concatenate back to text
+	text := strings.Join(tokens, "")
+	return &models.Chunk{
+		ID:         uuid.New().String(),
+		Body:       &text,
+		ByteSize:   intPtr(len(text)),
+		Tokenizer:  stringPtr(t.tokenizer.GetName()),
+		TokenCount: intPtr(len(tokens)),
+	}
 }
 
 // ChunkDocumentWithOverlap splits a document with overlapping chunks for better context.
@@ -154,7 +199,7 @@ func (t *TokenChunker) ChunkDocumentWithOverlap(
 	}
 
 	// Tokenize the entire content
-	tokens, _, err := t.encoding.Encode(content)
+	tokens, err := t.tokenizer.Tokenize(content)
 	if err != nil {
 		t.logger.Err(err).Msg("failed to tokenize content")
 		return nil, err
@@ -168,7 +213,7 @@ func (t *TokenChunker) ChunkDocumentWithOverlap(
 			ID:         uuid.New().String(),
 			Body:       &content,
 			ByteSize:   intPtr(len([]byte(content))),
-			Tokenizer:  stringPtr(getTokenizerFromEnv()),
+			Tokenizer:  stringPtr(t.tokenizer.GetName()),
 			TokenCount: &totalTokens,
 		}
 		return []*models.Chunk{chunk}, nil
@@ -188,12 +233,8 @@ func (t *TokenChunker) ChunkDocumentWithOverlap(
 		// Get the token slice
 		chunkTokens := tokens[i:end]
 
-		// Decode back to text
-		chunkText, err := t.encoding.Decode(chunkTokens)
-		if err != nil {
-			t.logger.Err(err).Msg("failed to decode chunk tokens")
-			return nil, err
-		}
+		// Tokens concatenate back to text
+		chunkText := strings.Join(chunkTokens, "")
 
 		// Create chunk
 		chunkID := uuid.New().String()
@@ -201,7 +242,7 @@ func (t *TokenChunker) ChunkDocumentWithOverlap(
 			ID:          chunkID,
 			Body:        &chunkText,
 			ByteSize:    intPtr(len([]byte(chunkText))),
-			Tokenizer:   stringPtr("cl100k_base"),
+			Tokenizer:   stringPtr(t.tokenizer.GetName()),
 			TokenCount:  intPtr(len(chunkTokens)),
 			LeftChunkID: previousChunkID,
 		}
@@ -225,7 +266,7 @@ func (t *TokenChunker) ChunkDocumentWithOverlap(
 
 // CountTokens returns the number of tokens in the given text.
 func (t *TokenChunker) CountTokens(text string) (int, error) {
-	tokens, _, err := t.encoding.Encode(text)
+	tokens, err := t.tokenizer.Tokenize(text)
 	if err != nil {
 		t.logger.Err(err).Msg("failed to tokenize text")
 		return 0, err
@@ -242,65 +283,25 @@ func stringPtr(s string) *string {
 	return &s
 }
 
-// getTokenizerFromEnv returns the tokenizer name from environment or default.
-func getTokenizerFromEnv() string {
-	tokenizerName := os.Getenv("CHUNKER_TOKENIZER")
-	if tokenizerName == "" {
-		return "cl100k_base"
-	}
-	return tokenizerName
-}
-
-// getTokenizerEncoding returns the tokenizer encoding for the given name.
-func getTokenizerEncoding(name string) (tokenizer.Codec, error) {
-	switch strings.ToLower(name) {
-	case "cl100k_base":
-		return tokenizer.Get(tokenizer.Cl100kBase)
-	case "p50k_base":
-		return tokenizer.Get(tokenizer.P50kBase)
-	case "r50k_base":
-		return tokenizer.Get(tokenizer.R50kBase)
-	default:
-		// Default to cl100k_base for unknown tokenizers
-		return tokenizer.Get(tokenizer.Cl100kBase)
-	}
-}
-
-// getLogLevelFromEnv returns the log level from environment or default.
-func getLogLevelFromEnv() zerolog.Level {
-	logLevel := os.Getenv("CHUNKER_LOG_LEVEL")
-	switch strings.ToLower(logLevel) {
-	case "debug":
-		return zerolog.DebugLevel
-	case "info":
-		return zerolog.InfoLevel
-	case "warn", "warning":
-		return zerolog.WarnLevel
-	case "error":
-		return zerolog.ErrorLevel
-	default:
-		return zerolog.ErrorLevel
+// getTokenizer returns the tiktoken encoding with the given name, or cl100k_base for names it
+// doesn't know.
+func getTokenizer(name string) (interfaces.Tokenizer, error) {
+	tokenizer, err := tokenizers.NewTiktoken(name)
+	if errors.Is(err, tokenizers.ErrUnknownTokenizer) {
+		tokenizer, err = tokenizers.NewTiktoken(tokenizers.Cl100kBase)
 	}
-}
-
-// getIntFromEnv returns an integer from environment variable or default value.
-func getIntFromEnv(key string, defaultValue int) int {
-	value := os.Getenv(key)
-	if value == "" {
-		return defaultValue
-	}
-	if intValue, err := strconv.Atoi(value); err == nil {
-		return intValue
+	if err != nil {
+		return nil, err
 	}
-	return defaultValue
+	return tokenizer, nil
 }
 
-// GetDefaultMaxTokens returns the default max tokens from environment or default.
+// GetDefaultMaxTokens returns the max tokens of a chunk when the options don't set them.
 func GetDefaultMaxTokens() int {
-	return getIntFromEnv("CHUNKER_DEFAULT_MAX_TOKENS", maxTokensDefault)
+	return maxTokensDefault
 }
 
-// GetDefaultOverlapTokens returns the default overlap tokens from environment or default.
+// GetDefaultOverlapTokens returns the tokens chunks overlap by when the options don't set them.
 func GetDefaultOverlapTokens() int {
-	return getIntFromEnv("CHUNKER_DEFAULT_OVERLAP_TOKENS", overlapTokensDefault)
+	return overlapTokensDefault
 }
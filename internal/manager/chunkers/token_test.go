@@ -1,10 +1,13 @@
 package chunkers
 
 import (
+	"errors"
+	"strings"
 	"testing"
 
 	"github.com/code-sleuth/ike-go/internal/manager/models"
 	"github.com/code-sleuth/ike-go/internal/manager/testutil"
+	"github.com/code-sleuth/ike-go/internal/manager/tokenizers"
 )
 
 func TestNewTokenChunker(t *testing.T) {
@@ -28,6 +31,26 @@ func TestNewTokenChunker(t *testing.T) {
 	}
 }
 
+func TestNewTokenChunker_WithTokenizerName(t *testing.T) {
+	t.Setenv("CHUNKER_TOKENIZER", tokenizers.R50kBase)
+
+	chunker, err := NewTokenChunker(WithTokenizerName(tokenizers.P50kBase))
+	if err != nil {
+		t.Fatalf("Failed to create token chunker: %v", err)
+	}
+	if name := chunker.tokenizer.GetName(); name != tokenizers.P50kBase {
+		t.Errorf("Expected the option's tokenizer %s over the environment's, got %s", tokenizers.P50kBase, name)
+	}
+
+	chunker, err = NewTokenChunker()
+	if err != nil {
+		t.Fatalf("Failed to create token chunker: %v", err)
+	}
+	if name := chunker.tokenizer.GetName(); name != tokenizers.Cl100kBase {
+		t.Errorf("Expected the default tokenizer %s, not the environment's, got %s", tokenizers.Cl100kBase, name)
+	}
+}
+
 func TestTokenChunker_ChunkDocument(t *testing.T) {
 	// Load environment variables from .env file
 	err := testutil.LoadEnvFromFile("../../../.env")
@@ -227,7 +250,7 @@ func example() {
 					t.Errorf("Chunk %d has invalid byte size %v for test: %s", i, chunk.ByteSize, tt.description)
 				}
 
-				expectedTokenizer := getTokenizerFromEnv()
+				expectedTokenizer := tokenizers.Cl100kBase
 				if chunk.Tokenizer == nil || *chunk.Tokenizer != expectedTokenizer {
 					t.Errorf("Chunk %d has wrong tokenizer %v, expected %s for test: %s", i, chunk.Tokenizer, expectedTokenizer, tt.description)
 				}
@@ -418,13 +441,45 @@ func max(a, b int) int {
 	return b
 }
 
+func TestTokenChunker_WithTokenizer(t *testing.T) {
+	chunker, err := NewTokenChunker()
+	if err != nil {
+		t.Fatalf("Failed to create token chunker: %v", err)
+	}
+
+	content := "Chunks are sized with the embedding model's tokenizer, not the chunker's default."
+	chunks, err := chunker.WithTokenizer(tokenizers.WordPiece{}).ChunkDocument(content, 5)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(chunks) < 2 {
+		t.Fatalf("Expected several chunks, got %d", len(chunks))
+	}
+	var joined strings.Builder
+	for i, chunk := range chunks {
+		if *chunk.Tokenizer != "wordpiece" || *chunk.TokenCount > 5 {
+			t.Errorf("Chunk %d has %d %s tokens", i, *chunk.TokenCount, *chunk.Tokenizer)
+		}
+		joined.WriteString(*chunk.Body)
+	}
+	if joined.String() != content {
+		t.Errorf("Expected chunks to cover the content, got %q", joined.String())
+	}
+
+	// The original chunker keeps its own tokenizer
+	chunks, err = chunker.ChunkDocument(content, 100)
+	if err != nil || *chunks[0].Tokenizer != tokenizers.Cl100kBase {
+		t.Errorf("Expected the %s tokenizer, got %v, %v", tokenizers.Cl100kBase, chunks, err)
+	}
+}
+
 // Helper function to create a test chunk
 func createTestChunk(id, documentID, body string, tokenCount int) *models.Chunk {
 	bodyPtr := &body
 	tokenCountPtr := &tokenCount
 	byteSize := len(body)
 	byteSizePtr := &byteSize
-	tokenizer := getTokenizerFromEnv()
+	tokenizer := tokenizers.Cl100kBase
 	tokenizerPtr := &tokenizer
 
 	return &models.Chunk{
@@ -491,3 +546,83 @@ func BenchmarkTokenChunker_LongContent(b *testing.B) {
 		}
 	}
 }
+
+func TestTokenChunker_StreamChunks(t *testing.T) {
+	chunker, err := NewTokenChunker()
+	if err != nil {
+		t.Fatalf("Failed to create chunker: %v", err)
+	}
+
+	// Longer than one window, so chunks are cut across window boundaries
+	content := strings.Repeat("The quick brown fox jumps over the lazy dog. ", streamWindow/45+1000)
+	const maxTokens = 500
+
+	var streamed []*models.Chunk
+	err = chunker.StreamChunks(content, maxTokens, func(chunk *models.Chunk) error {
+		streamed = append(streamed, chunk)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("StreamChunks failed: %v", err)
+	}
+
+	var body strings.Builder
+	for i, chunk := range streamed {
+		body.WriteString(*chunk.Body)
+		if i < len(streamed)-1 && *chunk.TokenCount != maxTokens {
+			t.Errorf("Expected chunk %d to hold %d tokens, got %d", i, maxTokens, *chunk.TokenCount)
+		}
+		if chunk.LeftChunkID != nil || chunk.RightChunkID != nil {
+			t.Errorf("Expected streamed chunk %d not to be linked", i)
+		}
+	}
+	if body.String() != content {
+		t.Error("Expected the streamed chunks to concatenate back to the content")
+	}
+
+	chunks, err := chunker.ChunkDocument(content, maxTokens)
+	if err != nil {
+		t.Fatalf("ChunkDocument failed: %v", err)
+	}
+	if len(chunks) != len(streamed) {
+		t.Fatalf("Expected ChunkDocument to cut the same %d chunks, got %d", len(streamed), len(chunks))
+	}
+	for i := 1; i < len(chunks); i++ {
+		if *chunks[i].LeftChunkID != chunks[i-1].ID || *chunks[i-1].RightChunkID != chunks[i].ID {
+			t.Errorf("Expected chunk %d to be linked to its neighbours", i)
+		}
+	}
+
+	// The first error yield returns stops the stream
+	stop := errors.New("stop")
+	calls := 0
+	err = chunker.StreamChunks(content, maxTokens, func(*models.Chunk) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Errorf("Expected the stream to stop at the first error, got %v after %d chunks", err, calls)
+	}
+}
+
+func TestWindowEnd(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected int
+	}{
+		{name: "fits", text: "short text", expected: len("short text")},
+		{name: "before the last space", text: strings.Repeat("a", streamWindow-10) + " " + strings.Repeat("b", 20),
+			expected: streamWindow - 10},
+		{name: "rune boundary without spaces", text: "a" + strings.Repeat("é", streamWindow),
+			expected: streamWindow - 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := windowEnd(tt.text); got != tt.expected {
+				t.Errorf("Expected window to end at %d, got %d", tt.expected, got)
+			}
+		})
+	}
+}
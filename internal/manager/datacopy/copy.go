@@ -0,0 +1,282 @@
+package datacopy
+
+import (
+	"context"
+	"database/sql"
+	_ "embed" // postgres schema
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/code-sleuth/ike-go/pkg/dialect"
+	"github.com/code-sleuth/ike-go/pkg/util"
+
+	_ "github.com/jackc/pgx/v5/stdlib" // registers the "pgx" database/sql driver
+	"github.com/rs/zerolog"
+)
+
+//go:embed postgres_schema.sql
+var postgresSchema string
+
+var (
+	ErrTargetNotEmpty = errors.New("target database already contains data")
+	ErrCountMismatch  = errors.New("row counts differ after copy")
+)
+
+const progressInterval = 1000
+
+// table describes a table to copy. Tables are listed parents first so foreign keys resolve, and
+// binary lists the columns holding BLOB data.
+type table struct {
+	name    string
+	columns []string
+	binary  map[string]bool
+}
+
+var tables = []table{
+	{name: "collections", columns: []string{"name", "description", "created_at"}},
+	{name: "sources", columns: []string{"id", "author_email", "raw_url", "scheme", "host", "path", "query",
+		"active_domain", "format", "created_at", "updated_at", "deleted_at", "collection", "last_success_at",
+		"last_error", "consecutive_failure_count"}},
+	{name: "source_owners", columns: []string{"source_id", "owner", "created_at"}},
+	{name: "source_acl", columns: []string{"source_id", "label", "created_at"}},
+	{name: "download_bodies", columns: []string{"content_hash", "body", "body_encoding", "size", "created_at",
+		"blob_key"}, binary: map[string]bool{"body": true}},
+	{name: "github_blobs", columns: []string{"sha", "content_hash", "encoding", "created_at"}},
+	{name: "downloads", columns: []string{"id", "source_id", "attempted_at", "downloaded_at", "status_code",
+		"headers", "body", "body_encoding", "content_hash"}, binary: map[string]bool{"body": true}},
+	{name: "documents", columns: []string{"id", "source_id", "download_id", "format", "indexed_at",
+		"min_chunk_size", "max_chunk_size", "published_at", "modified_at", "wp_version", "deleted_at",
+		"content_hash", "stale_at"}},
+	{name: "chunks", columns: []string{"id", "document_id", "parent_chunk_id", "left_chunk_id", "right_chunk_id",
+		"body", "byte_size", "tokenizer", "token_count", "natural_lang", "code_lang"}},
+	{name: "tags", columns: []string{"id", "name", "created_at"}},
+	{name: "document_tags", columns: []string{"id", "document_id", "tag_id", "created_at"}},
+	{name: "document_meta", columns: []string{"id", "document_id", `"key"`, "meta", "created_at"}},
+	{name: "chunk_meta", columns: []string{"id", "chunk_id", `"key"`, "meta", "created_at"}},
+	{name: "embeddings", columns: []string{"id", "embedding_1536", "embedding_3072", "embedding_768", "model",
+		"embedded_at", "object_id", "object_type"},
+		binary: map[string]bool{"embedding_1536": true, "embedding_3072": true, "embedding_768": true}},
+	{name: "requests", columns: []string{"id", "message", "meta", "requested_at", "result_chunks"}},
+	{name: "pipeline_runs", columns: []string{"id", "source_url", "status", "error", "started_at", "updated_at",
+		"finished_at", "revision", "embedding_model"}},
+	{name: "pipeline_run_items", columns: []string{"run_id", "item_key", "position", "source_id", "download_id",
+		"status", "error", "updated_at"}},
+	{name: "jobs", columns: []string{"id", "kind", "payload", "status", "priority", "attempts", "max_attempts",
+		"error", "worker_id", "locked_at", "created_at", "updated_at", "finished_at"}},
+	{name: "failed_chunks", columns: []string{"chunk_id", "document_id", "chunk", "model", "error", "attempts",
+		"created_at", "updated_at"}},
+	{name: "schedules", columns: []string{"id", "source_url", "cron", "options", "enabled", "priority",
+		"next_run_at", "last_run_at", "last_error", "created_at", "updated_at"}},
+	{name: "source_settings", columns: []string{"source_url", "chunk_strategy", "max_tokens", "embedding_model",
+		"concurrency", "created_at", "updated_at", "ttl_seconds", "expiry_action",
+		"acl_labels"}},
+	{name: "source_credentials", columns: []string{"source_url", "github_token", "openai_api_key", "together_api_key",
+		"created_at", "updated_at", "url_secrets"}},
+	{name: "events", columns: []string{"id", "type", "source_id", "document_id", "job_id", "actor", "detail",
+		"created_at"}},
+	{name: "webhook_subscriptions", columns: []string{"id", "url", "secret", "event_types", "created_at",
+		"updated_at"}},
+	{name: "webhook_deliveries", columns: []string{"subscription_id", "event_id", "status", "attempts",
+		"next_attempt_at", "last_error", "created_at", "updated_at"}},
+	{name: "schema_migrations", columns: []string{"version"}},
+	{name: "schema_version", columns: []string{"id", "version", "updated_at"}},
+}
+
+// Progress reports how far the copy of a table has got.
+type Progress struct {
+	Table  string
+	Copied int64
+	Total  int64
+}
+
+// TableResult holds the verified row counts of one copied table.
+type TableResult struct {
+	Table  string `json:"table"`
+	Source int64  `json:"source"`
+	Target int64  `json:"target"`
+}
+
+// Copier streams every table of a SQLite corpus into a Postgres database.
+type Copier struct {
+	source     *sql.DB
+	target     *sql.DB
+	onProgress func(Progress)
+	logger     zerolog.Logger
+}
+
+func NewCopier(source, target *sql.DB) *Copier {
+	logger := util.NewLogger(zerolog.ErrorLevel)
+	return &Copier{
+		source: source,
+		target: target,
+		logger: logger,
+	}
+}
+
+// SetProgressFunc sets a callback invoked periodically while each table is copied.
+func (c *Copier) SetProgressFunc(fn func(Progress)) {
+	c.onProgress = fn
+}
+
+// OpenPostgres opens a connection to the Postgres database at url.
+func OpenPostgres(url string) (*sql.DB, error) {
+	return sql.Open("pgx", url)
+}
+
+// Copy creates the schema on the target, copies every table inside a single transaction so a
+// failed copy leaves the target untouched, and then verifies the row counts of both sides.
+func (c *Copier) Copy(ctx context.Context) ([]TableResult, error) {
+	if _, err := c.target.ExecContext(ctx, postgresSchema); err != nil {
+		c.logger.Error().Err(err).Msg("Failed to create target schema")
+		return nil, err
+	}
+
+	if err := c.ensureEmpty(ctx); err != nil {
+		return nil, err
+	}
+
+	tx, err := c.target.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	for _, t := range tables {
+		if err := c.copyTable(ctx, tx, t); err != nil {
+			c.logger.Error().Err(err).Str("table", t.name).Msg("Failed to copy table")
+			return nil, fmt.Errorf("copy %s: %w", t.name, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return c.verify(ctx)
+}
+
+func (c *Copier) ensureEmpty(ctx context.Context) error {
+	for _, t := range tables {
+		count, err := countRows(ctx, c.target, t.name)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return fmt.Errorf("%w: %s has %d rows", ErrTargetNotEmpty, t.name, count)
+		}
+	}
+	return nil
+}
+
+func (c *Copier) copyTable(ctx context.Context, tx *sql.Tx, t table) error {
+	total, err := countRows(ctx, c.source, t.name)
+	if err != nil {
+		return err
+	}
+
+	columns := strings.Join(t.columns, ", ")
+	// #nosec G202 -- table and column names come from the tables list
+	rows, err := c.source.QueryContext(ctx, `SELECT `+columns+` FROM `+t.name)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	insert, err := tx.PrepareContext(ctx, dialect.Postgres.Rebind(insertQuery(t)))
+	if err != nil {
+		return err
+	}
+	defer insert.Close()
+
+	values := make([]interface{}, len(t.columns))
+	pointers := make([]interface{}, len(t.columns))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+
+	var copied int64
+	for rows.Next() {
+		if err := rows.Scan(pointers...); err != nil {
+			return err
+		}
+		if _, err := insert.ExecContext(ctx, convertRow(t, values)...); err != nil {
+			return err
+		}
+
+		copied++
+		if c.onProgress != nil && (copied%progressInterval == 0 || copied == total) {
+			c.onProgress(Progress{Table: t.name, Copied: copied, Total: total})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if c.onProgress != nil && copied == 0 {
+		c.onProgress(Progress{Table: t.name, Total: total})
+	}
+	return nil
+}
+
+func (c *Copier) verify(ctx context.Context) ([]TableResult, error) {
+	results := make([]TableResult, 0, len(tables))
+	var mismatched []string
+	for _, t := range tables {
+		source, err := countRows(ctx, c.source, t.name)
+		if err != nil {
+			return results, err
+		}
+		target, err := countRows(ctx, c.target, t.name)
+		if err != nil {
+			return results, err
+		}
+
+		results = append(results, TableResult{Table: t.name, Source: source, Target: target})
+		if source != target {
+			mismatched = append(mismatched, fmt.Sprintf("%s (%d != %d)", t.name, source, target))
+		}
+	}
+
+	if len(mismatched) > 0 {
+		return results, fmt.Errorf("%w: %s", ErrCountMismatch, strings.Join(mismatched, ", "))
+	}
+	return results, nil
+}
+
+func insertQuery(t table) string {
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(t.columns)), ", ")
+	return `INSERT INTO ` + t.name + ` (` + strings.Join(t.columns, ", ") + `) VALUES (` + placeholders + `)`
+}
+
+// convertRow adapts SQLite's loosely typed values to the target column types: BLOB columns may
+// hold text (uncompressed bodies, legacy vectors) and must be sent as bytes, while text columns
+// must not be sent as bytes.
+func convertRow(t table, values []interface{}) []interface{} {
+	args := make([]interface{}, len(values))
+	for i, value := range values {
+		switch v := value.(type) {
+		case string:
+			if t.binary[t.columns[i]] {
+				args[i] = []byte(v)
+				continue
+			}
+		case []byte:
+			if !t.binary[t.columns[i]] {
+				args[i] = string(v)
+				continue
+			}
+		}
+		args[i] = value
+	}
+	return args
+}
+
+func countRows(ctx context.Context, db *sql.DB, name string) (int64, error) {
+	var count int64
+	// #nosec G202 -- table names come from the tables list
+	err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM `+name).Scan(&count)
+	return count, err
+}
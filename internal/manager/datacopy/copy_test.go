@@ -0,0 +1,72 @@
+package datacopy
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/code-sleuth/ike-go/pkg/dialect"
+)
+
+func TestInsertQuery(t *testing.T) {
+	tbl := table{name: "tags", columns: []string{"id", "name", "created_at"}}
+
+	expected := "INSERT INTO tags (id, name, created_at) VALUES ($1, $2, $3)"
+	if got := dialect.Postgres.Rebind(insertQuery(tbl)); got != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+}
+
+func TestConvertRow(t *testing.T) {
+	tbl := table{
+		name:    "downloads",
+		columns: []string{"id", "body", "status_code"},
+		binary:  map[string]bool{"body": true},
+	}
+
+	tests := []struct {
+		name        string
+		values      []interface{}
+		description string
+	}{
+		{
+			name:        "text body",
+			values:      []interface{}{[]byte("d1"), "plain body", int64(200)},
+			description: "should send text stored in a BLOB column as bytes and byte IDs as text",
+		},
+		{
+			name:        "binary body",
+			values:      []interface{}{"d2", []byte{0x1f, 0x8b}, nil},
+			description: "should pass bytes and NULLs through unchanged",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := convertRow(tbl, tt.values)
+
+			if _, ok := args[0].(string); !ok {
+				t.Errorf("Expected id as string, got %T", args[0])
+			}
+			if _, ok := args[1].([]byte); !ok {
+				t.Errorf("Expected body as []byte, got %T", args[1])
+			}
+			if args[2] != tt.values[2] {
+				t.Errorf("Expected status code %v, got %v", tt.values[2], args[2])
+			}
+		})
+	}
+
+	args := convertRow(tbl, []interface{}{"d3", "body", nil})
+	if !bytes.Equal(args[1].([]byte), []byte("body")) {
+		t.Errorf("Expected body bytes to match, got %v", args[1])
+	}
+}
+
+func TestPostgresSchemaCoversTables(t *testing.T) {
+	for _, tbl := range tables {
+		if !strings.Contains(postgresSchema, "CREATE TABLE IF NOT EXISTS "+tbl.name+" (") {
+			t.Errorf("Expected Postgres schema to create table %s", tbl.name)
+		}
+	}
+}
@@ -0,0 +1,310 @@
+-- Postgres schema matching the SQLite schema after all migrations in pkg/migrations.
+-- Timestamps stay TEXT so copied values are preserved exactly. Foreign keys are deferred
+-- because the copy runs in a single transaction, and cascade deletes like SQLite's.
+
+CREATE TABLE IF NOT EXISTS sources (
+    id TEXT NOT NULL PRIMARY KEY,
+    author_email TEXT,
+    raw_url TEXT,
+    scheme TEXT,
+    host TEXT,
+    path TEXT,
+    query TEXT,
+    active_domain INTEGER NOT NULL CHECK (active_domain IN (0, 1)),
+    format TEXT CHECK (format IN ('json', 'yml', 'yaml')),
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL,
+    deleted_at TEXT,
+    collection TEXT NOT NULL DEFAULT 'default',
+    last_success_at TEXT,
+    last_error TEXT,
+    consecutive_failure_count INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_sources_collection ON sources(collection);
+
+CREATE TABLE IF NOT EXISTS source_owners (
+    source_id TEXT NOT NULL REFERENCES sources(id) ON DELETE CASCADE DEFERRABLE INITIALLY DEFERRED,
+    owner TEXT NOT NULL,
+    created_at TEXT NOT NULL,
+    PRIMARY KEY (source_id, owner)
+);
+
+CREATE INDEX IF NOT EXISTS idx_source_owners_owner ON source_owners(owner);
+
+CREATE TABLE IF NOT EXISTS source_acl (
+    source_id TEXT NOT NULL REFERENCES sources(id) ON DELETE CASCADE DEFERRABLE INITIALLY DEFERRED,
+    label TEXT NOT NULL,
+    created_at TEXT NOT NULL,
+    PRIMARY KEY (source_id, label)
+);
+
+CREATE INDEX IF NOT EXISTS idx_source_acl_label ON source_acl(label);
+
+CREATE TABLE IF NOT EXISTS collections (
+    name TEXT NOT NULL PRIMARY KEY,
+    description TEXT,
+    created_at TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS download_bodies (
+    content_hash TEXT PRIMARY KEY,
+    body BYTEA NOT NULL,
+    body_encoding TEXT NOT NULL DEFAULT 'identity' CHECK (body_encoding IN ('identity', 'gzip')),
+    size INTEGER NOT NULL,
+    created_at TEXT NOT NULL,
+    blob_key TEXT
+);
+
+CREATE TABLE IF NOT EXISTS github_blobs (
+    sha TEXT PRIMARY KEY,
+    content_hash TEXT NOT NULL REFERENCES download_bodies(content_hash) ON DELETE CASCADE DEFERRABLE INITIALLY DEFERRED,
+    encoding TEXT NOT NULL,
+    created_at TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS downloads (
+    id TEXT NOT NULL PRIMARY KEY,
+    source_id TEXT NOT NULL REFERENCES sources(id) ON DELETE CASCADE DEFERRABLE INITIALLY DEFERRED,
+    attempted_at TEXT,
+    downloaded_at TEXT,
+    status_code INTEGER,
+    headers TEXT NOT NULL,
+    body BYTEA,
+    body_encoding TEXT NOT NULL DEFAULT 'identity' CHECK (body_encoding IN ('identity', 'gzip')),
+    content_hash TEXT REFERENCES download_bodies(content_hash) DEFERRABLE INITIALLY DEFERRED
+);
+
+CREATE TABLE IF NOT EXISTS documents (
+    id TEXT NOT NULL PRIMARY KEY,
+    source_id TEXT NOT NULL REFERENCES sources(id) ON DELETE CASCADE DEFERRABLE INITIALLY DEFERRED,
+    download_id TEXT NOT NULL,
+    format TEXT CHECK (format IN ('json', 'yml', 'yaml')),
+    indexed_at TEXT,
+    min_chunk_size INTEGER NOT NULL,
+    max_chunk_size INTEGER NOT NULL,
+    published_at TEXT,
+    modified_at TEXT,
+    wp_version TEXT,
+    deleted_at TEXT,
+    content_hash TEXT,
+    stale_at TEXT
+);
+
+CREATE TABLE IF NOT EXISTS chunks (
+    id TEXT NOT NULL PRIMARY KEY,
+    document_id TEXT NOT NULL REFERENCES documents(id) ON DELETE CASCADE DEFERRABLE INITIALLY DEFERRED,
+    parent_chunk_id TEXT,
+    left_chunk_id TEXT,
+    right_chunk_id TEXT,
+    body TEXT,
+    byte_size INTEGER,
+    tokenizer TEXT,
+    token_count INTEGER,
+    natural_lang TEXT CHECK (natural_lang IN ('en', 'fr')),
+    code_lang TEXT CHECK (code_lang IN ('python', 'sql', 'javascript'))
+);
+
+CREATE TABLE IF NOT EXISTS tags (
+    id TEXT NOT NULL PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    created_at TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS document_tags (
+    id TEXT NOT NULL PRIMARY KEY,
+    document_id TEXT NOT NULL REFERENCES documents(id) ON DELETE CASCADE DEFERRABLE INITIALLY DEFERRED,
+    tag_id TEXT NOT NULL REFERENCES tags(id) ON DELETE CASCADE DEFERRABLE INITIALLY DEFERRED,
+    created_at TEXT NOT NULL,
+    UNIQUE (document_id, tag_id)
+);
+
+CREATE TABLE IF NOT EXISTS document_meta (
+    id TEXT NOT NULL PRIMARY KEY,
+    document_id TEXT NOT NULL REFERENCES documents(id) ON DELETE CASCADE DEFERRABLE INITIALLY DEFERRED,
+    "key" TEXT NOT NULL,
+    meta TEXT,
+    created_at TEXT NOT NULL,
+    UNIQUE (document_id, "key")
+);
+
+CREATE TABLE IF NOT EXISTS chunk_meta (
+    id TEXT NOT NULL PRIMARY KEY,
+    chunk_id TEXT NOT NULL REFERENCES chunks(id) ON DELETE CASCADE DEFERRABLE INITIALLY DEFERRED,
+    "key" TEXT NOT NULL,
+    meta TEXT,
+    created_at TEXT NOT NULL,
+    UNIQUE (chunk_id, "key")
+);
+
+CREATE TABLE IF NOT EXISTS embeddings (
+    id TEXT NOT NULL PRIMARY KEY,
+    embedding_1536 BYTEA,
+    embedding_3072 BYTEA,
+    embedding_768 BYTEA,
+    model TEXT,
+    embedded_at TEXT NOT NULL,
+    object_id TEXT NOT NULL REFERENCES chunks(id) ON DELETE CASCADE DEFERRABLE INITIALLY DEFERRED,
+    object_type TEXT NOT NULL DEFAULT 'chunk'
+);
+
+CREATE TABLE IF NOT EXISTS requests (
+    id TEXT NOT NULL PRIMARY KEY,
+    message TEXT NOT NULL,
+    meta TEXT,
+    requested_at TEXT NOT NULL,
+    result_chunks TEXT
+);
+
+CREATE TABLE IF NOT EXISTS pipeline_runs (
+    id TEXT PRIMARY KEY,
+    source_url TEXT NOT NULL,
+    status TEXT NOT NULL,
+    error TEXT,
+    started_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL,
+    finished_at TEXT,
+    revision TEXT,
+    embedding_model TEXT
+);
+
+CREATE TABLE IF NOT EXISTS pipeline_run_items (
+    run_id TEXT NOT NULL REFERENCES pipeline_runs(id) ON DELETE CASCADE DEFERRABLE INITIALLY DEFERRED,
+    item_key TEXT NOT NULL,
+    position INTEGER NOT NULL,
+    source_id TEXT,
+    download_id TEXT NOT NULL,
+    status TEXT NOT NULL,
+    error TEXT,
+    updated_at TEXT NOT NULL,
+    PRIMARY KEY (run_id, item_key)
+);
+
+CREATE TABLE IF NOT EXISTS jobs (
+    id TEXT PRIMARY KEY,
+    kind TEXT NOT NULL,
+    payload TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'queued',
+    priority INTEGER NOT NULL DEFAULT 0,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL DEFAULT 3,
+    error TEXT,
+    worker_id TEXT,
+    locked_at TEXT,
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL,
+    finished_at TEXT
+);
+
+CREATE TABLE IF NOT EXISTS failed_chunks (
+    chunk_id TEXT PRIMARY KEY,
+    document_id TEXT NOT NULL REFERENCES documents(id) ON DELETE CASCADE DEFERRABLE INITIALLY DEFERRED,
+    chunk TEXT NOT NULL,
+    model TEXT NOT NULL,
+    error TEXT NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 1,
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS schedules (
+    id TEXT PRIMARY KEY,
+    source_url TEXT NOT NULL UNIQUE,
+    cron TEXT NOT NULL,
+    options TEXT NOT NULL,
+    enabled INTEGER NOT NULL DEFAULT 1,
+    priority INTEGER NOT NULL DEFAULT -10,
+    next_run_at TEXT,
+    last_run_at TEXT,
+    last_error TEXT,
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS source_settings (
+    source_url TEXT PRIMARY KEY,
+    chunk_strategy TEXT,
+    max_tokens INTEGER,
+    embedding_model TEXT,
+    concurrency INTEGER,
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL,
+    ttl_seconds INTEGER,
+    expiry_action TEXT CHECK (expiry_action IN ('stale', 'purge')),
+    acl_labels TEXT
+);
+
+CREATE TABLE IF NOT EXISTS source_credentials (
+    source_url TEXT PRIMARY KEY,
+    github_token TEXT,
+    openai_api_key TEXT,
+    together_api_key TEXT,
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL,
+    url_secrets TEXT
+);
+
+CREATE TABLE IF NOT EXISTS events (
+    id TEXT PRIMARY KEY,
+    type TEXT NOT NULL,
+    source_id TEXT,
+    document_id TEXT,
+    job_id TEXT,
+    actor TEXT,
+    detail TEXT,
+    created_at TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    id TEXT PRIMARY KEY,
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    event_types TEXT NOT NULL DEFAULT '',
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    subscription_id TEXT NOT NULL REFERENCES webhook_subscriptions(id) ON DELETE CASCADE DEFERRABLE INITIALLY DEFERRED,
+    event_id TEXT NOT NULL,
+    status TEXT NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TEXT NOT NULL,
+    last_error TEXT,
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL,
+    PRIMARY KEY (subscription_id, event_id)
+);
+
+CREATE TABLE IF NOT EXISTS schema_migrations (
+    version TEXT
+);
+
+CREATE TABLE IF NOT EXISTS schema_version (
+    id INTEGER PRIMARY KEY CHECK (id = 1),
+    version TEXT NOT NULL,
+    updated_at TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_documents_source_id ON documents(source_id);
+CREATE INDEX IF NOT EXISTS idx_documents_deleted_at ON documents(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_documents_stale_at ON documents(stale_at);
+CREATE INDEX IF NOT EXISTS idx_documents_source_id_content_hash ON documents(source_id, content_hash);
+CREATE INDEX IF NOT EXISTS idx_sources_deleted_at ON sources(deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_sources_live_raw_url ON sources(raw_url) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_downloads_source_id ON downloads(source_id);
+CREATE INDEX IF NOT EXISTS idx_downloads_content_hash ON downloads(content_hash);
+CREATE INDEX IF NOT EXISTS idx_chunks_document_id ON chunks(document_id);
+CREATE INDEX IF NOT EXISTS idx_document_tags_document_id ON document_tags(document_id);
+CREATE INDEX IF NOT EXISTS idx_document_tags_tag_id ON document_tags(tag_id);
+CREATE INDEX IF NOT EXISTS idx_document_meta_document_id ON document_meta(document_id);
+CREATE INDEX IF NOT EXISTS idx_embeddings_object_id ON embeddings(object_id);
+CREATE INDEX IF NOT EXISTS idx_pipeline_runs_source_url ON pipeline_runs(source_url, status);
+CREATE INDEX IF NOT EXISTS idx_jobs_status_created_at ON jobs(status, created_at);
+CREATE INDEX IF NOT EXISTS idx_jobs_status_priority ON jobs(status, priority DESC, created_at);
+CREATE INDEX IF NOT EXISTS idx_failed_chunks_document_id ON failed_chunks(document_id);
+CREATE INDEX IF NOT EXISTS idx_schedules_next_run_at ON schedules(enabled, next_run_at);
+CREATE INDEX IF NOT EXISTS idx_events_source_id ON events(source_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_events_document_id ON events(document_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_events_created_at ON events(created_at);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_pending ON webhook_deliveries(status, next_attempt_at);
@@ -1,6 +1,10 @@
 package embedders
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
 
 var (
 	ErrAPIKeyNotSet     = errors.New("API key not set")
@@ -8,4 +12,26 @@ var (
 	ErrContentEmpty     = errors.New("content is empty")
 	ErrAPIRequestFailed = errors.New("API request failed")
 	ErrNoEmbeddingData  = errors.New("no embedding data in response")
+	// ErrMissingEmbedding is returned when a batch response has no embedding for some input.
+	ErrMissingEmbedding = errors.New("no embedding in response for input")
 )
+
+// StatusError is an unsuccessful response from an embedding API. It matches ErrAPIRequestFailed
+// with errors.Is, and its status code tells a rate limit or an invalid key from a provider outage.
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s: %d %s", ErrAPIRequestFailed, e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// Unwrap returns ErrAPIRequestFailed.
+func (e *StatusError) Unwrap() error {
+	return ErrAPIRequestFailed
+}
+
+// HTTPStatus returns the status code, so callers outside this package can classify the error.
+func (e *StatusError) HTTPStatus() int {
+	return e.StatusCode
+}
@@ -0,0 +1,22 @@
+package embedders
+
+import (
+	"fmt"
+	"strings"
+)
+
+// cleanInput replaces newlines with spaces and trims content before it is embedded.
+func cleanInput(content string) string {
+	return strings.TrimSpace(strings.ReplaceAll(content, "\n", " "))
+}
+
+// checkBatch returns ErrMissingEmbedding when the response to a batch request left an input
+// without an embedding.
+func checkBatch(embeddings [][]float32) error {
+	for i, embedding := range embeddings {
+		if embedding == nil {
+			return fmt.Errorf("%w %d of %d", ErrMissingEmbedding, i, len(embeddings))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package embedders
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/code-sleuth/ike-go/pkg/metrics"
+)
+
+var (
+	requestsTotal = metrics.NewCounterVec("ike_embedder_requests_total",
+		"Embedding API requests by provider, model, and status code (\"error\" when no response arrived).",
+		"provider", "model", "code")
+	requestDuration = metrics.NewHistogramVec("ike_embedder_request_duration_seconds",
+		"Latency of embedding API requests by provider and model.", nil, "provider", "model")
+)
+
+// observeRequest records an embedding API request started at start that returned resp or err.
+func observeRequest(provider, model string, start time.Time, resp *http.Response, err error) {
+	code := "error"
+	if err == nil {
+		code = strconv.Itoa(resp.StatusCode)
+	}
+	requestsTotal.With(provider, model, code).Inc()
+	requestDuration.With(provider, model).Observe(time.Since(start).Seconds())
+}
@@ -6,9 +6,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"os"
 	"strings"
+	"time"
 
+	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
+	"github.com/code-sleuth/ike-go/internal/manager/tokenizers"
+	"github.com/code-sleuth/ike-go/pkg/httpclient"
 	"github.com/code-sleuth/ike-go/pkg/util"
 
 	"github.com/rs/zerolog"
@@ -20,6 +23,7 @@ type OpenAIEmbedder struct {
 	model      string
 	dimension  int
 	maxTokens  int
+	tokenizer  interfaces.Tokenizer
 	httpClient *http.Client
 	apiURL     string
 	logger     zerolog.Logger
@@ -32,6 +36,13 @@ type OpenAIEmbeddingRequest struct {
 	EncodingFormat string `json:"encoding_format"`
 }
 
+// OpenAIBatchEmbeddingRequest is an embeddings API request for several inputs at once.
+type OpenAIBatchEmbeddingRequest struct {
+	Input          []string `json:"input"`
+	Model          string   `json:"model"`
+	EncodingFormat string   `json:"encoding_format"`
+}
+
 // OpenAIEmbeddingResponse represents the response structure from OpenAI embeddings API.
 type OpenAIEmbeddingResponse struct {
 	Data []struct {
@@ -47,17 +58,16 @@ type OpenAIEmbeddingResponse struct {
 	} `json:"usage"`
 }
 
-// NewOpenAIEmbedder creates a new OpenAI embedder.
-func NewOpenAIEmbedder(model string) (*OpenAIEmbedder, error) {
-	return NewOpenAIEmbedderWithClient(model, nil, "")
-}
-
-// NewOpenAIEmbedderWithClient creates a new OpenAI embedder with custom HTTP client and API URL.
-func NewOpenAIEmbedderWithClient(model string, httpClient *http.Client, apiURL string) (*OpenAIEmbedder, error) {
-	logger := util.NewLogger(zerolog.ErrorLevel)
-	apiKey := os.Getenv("OPENAI_API_KEY")
-	if strings.EqualFold(apiKey, "") {
-		logger.Error().Msg("OPENAI_API_KEY env variable not set")
+// NewOpenAIEmbedder creates a new OpenAI embedder for model, configured by opts.
+func NewOpenAIEmbedder(model string, opts ...Option) (*OpenAIEmbedder, error) {
+	o := newOptions(opts)
+	logger := util.ComponentLogger("embedders.openai", zerolog.ErrorLevel)
+	if o.logger != nil {
+		logger = *o.logger
+	}
+	apiKey := o.apiKey
+	if apiKey == "" {
+		logger.Error().Msg("OpenAI API key not set")
 		return nil, ErrAPIKeyNotSet
 	}
 
@@ -78,14 +88,20 @@ func NewOpenAIEmbedderWithClient(model string, httpClient *http.Client, apiURL s
 		return nil, ErrUnsupportedModel
 	}
 
+	// All supported models count tokens with cl100k_base
+	tokenizer, err := tokenizers.NewTiktoken(tokenizers.Cl100kBase)
+	if err != nil {
+		return nil, err
+	}
+
 	// Use provided HTTP client or create default one
+	httpClient := o.httpClient
 	if httpClient == nil {
-		httpClient = &http.Client{
-			Timeout: timeout,
-		}
+		httpClient = httpclient.New(timeout)
 	}
 
 	// Use provided API URL or default one
+	apiURL := o.apiURL
 	if apiURL == "" {
 		apiURL = "https://api.openai.com/v1/embeddings"
 	}
@@ -95,6 +111,7 @@ func NewOpenAIEmbedderWithClient(model string, httpClient *http.Client, apiURL s
 		model:      model,
 		dimension:  dimension,
 		maxTokens:  maxTokens,
+		tokenizer:  tokenizer,
 		httpClient: httpClient,
 		apiURL:     apiURL,
 		logger:     logger,
@@ -108,17 +125,63 @@ func (o *OpenAIEmbedder) GenerateEmbedding(ctx context.Context, content string)
 		return nil, ErrContentEmpty
 	}
 
-	// Clean the content (remove newlines and extra spaces)
-	cleanContent := strings.ReplaceAll(content, "\n", " ")
-	cleanContent = strings.TrimSpace(cleanContent)
-
 	// Prepare the request
 	request := OpenAIEmbeddingRequest{
-		Input:          cleanContent,
+		Input:          cleanInput(content),
+		Model:          o.model,
+		EncodingFormat: "float",
+	}
+
+	response, err := o.post(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	if len(response.Data) == 0 {
+		return nil, ErrNoEmbeddingData
+	}
+
+	o.logger.Debug().Str("model", o.model).Int("tokens_used", response.Usage.TotalTokens).Msg("Generated embedding")
+	return response.Data[0].Embedding, nil
+}
+
+// GenerateEmbeddings creates a vector embedding for each of contents with one request, returning
+// them in the order of contents.
+func (o *OpenAIEmbedder) GenerateEmbeddings(ctx context.Context, contents []string) ([][]float32, error) {
+	inputs := make([]string, len(contents))
+	for i, content := range contents {
+		if strings.EqualFold(content, "") {
+			o.logger.Warn().Int("input", i).Msg("content is empty")
+			return nil, ErrContentEmpty
+		}
+		inputs[i] = cleanInput(content)
+	}
+
+	response, err := o.post(ctx, OpenAIBatchEmbeddingRequest{
+		Input:          inputs,
 		Model:          o.model,
 		EncodingFormat: "float",
+	})
+	if err != nil {
+		return nil, err
 	}
 
+	embeddings := make([][]float32, len(contents))
+	for _, data := range response.Data {
+		if data.Index >= 0 && data.Index < len(embeddings) {
+			embeddings[data.Index] = data.Embedding
+		}
+	}
+	if err := checkBatch(embeddings); err != nil {
+		return nil, err
+	}
+
+	o.logger.Debug().Str("model", o.model).Int("inputs", len(contents)).
+		Int("tokens_used", response.Usage.TotalTokens).Msg("Generated embeddings")
+	return embeddings, nil
+}
+
+// post sends an embeddings request and decodes the response.
+func (o *OpenAIEmbedder) post(ctx context.Context, request any) (*OpenAIEmbeddingResponse, error) {
 	requestBody, err := json.Marshal(request)
 	if err != nil {
 		o.logger.Err(err).Msg("failed to marshal request")
@@ -139,10 +202,12 @@ func (o *OpenAIEmbedder) GenerateEmbedding(ctx context.Context, content string)
 
 	// Set headers
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", o.apiKey))
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", o.apiKeyFor(ctx)))
 
 	// Make the request
+	start := time.Now()
 	resp, err := o.httpClient.Do(req)
+	observeRequest("openai", o.model, start, resp, err)
 	if err != nil {
 		o.logger.Err(err).Msg("failed to make request")
 		return nil, err
@@ -153,9 +218,10 @@ func (o *OpenAIEmbedder) GenerateEmbedding(ctx context.Context, content string)
 		}
 	}()
 
+	reportRateLimit(ctx, resp)
 	if resp.StatusCode != http.StatusOK {
 		o.logger.Error().Int("status_code", resp.StatusCode).Msg("API request failed")
-		return nil, ErrAPIRequestFailed
+		return nil, &StatusError{StatusCode: resp.StatusCode}
 	}
 
 	// Parse the response
@@ -164,13 +230,7 @@ func (o *OpenAIEmbedder) GenerateEmbedding(ctx context.Context, content string)
 		o.logger.Err(err).Msg("failed to decode response")
 		return nil, err
 	}
-
-	if len(response.Data) == 0 {
-		return nil, ErrNoEmbeddingData
-	}
-
-	o.logger.Debug().Str("model", o.model).Int("tokens_used", response.Usage.TotalTokens).Msg("Generated embedding")
-	return response.Data[0].Embedding, nil
+	return &response, nil
 }
 
 // GetModelName returns the name of the embedding model.
@@ -187,3 +247,17 @@ func (o *OpenAIEmbedder) GetDimension() int {
 func (o *OpenAIEmbedder) GetMaxTokens() int {
 	return o.maxTokens
 }
+
+// GetTokenizer returns the cl100k_base encoding the embedding models count tokens with.
+func (o *OpenAIEmbedder) GetTokenizer() interfaces.Tokenizer {
+	return o.tokenizer
+}
+
+// apiKeyFor returns the API key requests made with ctx authenticate with: the OpenAI key of the
+// credentials ctx carries, else the embedder's own.
+func (o *OpenAIEmbedder) apiKeyFor(ctx context.Context) string {
+	if apiKey := interfaces.CredentialsFromContext(ctx).OpenAIAPIKey; apiKey != "" {
+		return apiKey
+	}
+	return o.apiKey
+}
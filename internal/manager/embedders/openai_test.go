@@ -2,20 +2,21 @@ package embedders
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
 	"github.com/code-sleuth/ike-go/internal/manager/testutil"
 )
 
 func TestNewOpenAIEmbedder(t *testing.T) {
-	// Save original env var
-	originalAPIKey := os.Getenv("OPENAI_API_KEY")
-	defer os.Setenv("OPENAI_API_KEY", originalAPIKey)
-
 	tests := []struct {
 		name        string
 		model       string
@@ -83,10 +84,7 @@ func TestNewOpenAIEmbedder(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Set up environment
-			os.Setenv("OPENAI_API_KEY", tt.apiKey)
-
-			embedder, err := NewOpenAIEmbedder(tt.model)
+			embedder, err := NewOpenAIEmbedder(tt.model, WithAPIKey(tt.apiKey))
 
 			// Check error expectation
 			if tt.expectError && err == nil {
@@ -147,7 +145,7 @@ func TestOpenAIEmbedder_GenerateEmbedding(t *testing.T) {
 		t.Skip("OPENAI_API_KEY not set, skipping real API tests")
 	}
 
-	embedder, err := NewOpenAIEmbedder("text-embedding-3-small")
+	embedder, err := NewOpenAIEmbedder("text-embedding-3-small", WithAPIKey(apiKey))
 	if err != nil {
 		t.Fatalf("Failed to create embedder: %v", err)
 	}
@@ -262,12 +260,7 @@ func TestOpenAIEmbedder_GenerateEmbedding_ErrorHandling(t *testing.T) {
 
 	// Test error handling with invalid API key
 	t.Run("invalid API key", func(t *testing.T) {
-		// Temporarily set invalid API key
-		originalAPIKey := os.Getenv("OPENAI_API_KEY")
-		os.Setenv("OPENAI_API_KEY", "invalid-key-12345")
-		defer os.Setenv("OPENAI_API_KEY", originalAPIKey)
-
-		embedder, err := NewOpenAIEmbedder("text-embedding-3-small")
+		embedder, err := NewOpenAIEmbedder("text-embedding-3-small", WithAPIKey("invalid-key-12345"))
 		if err != nil {
 			t.Fatalf("Failed to create embedder: %v", err)
 		}
@@ -283,7 +276,7 @@ func TestOpenAIEmbedder_GenerateEmbedding_ErrorHandling(t *testing.T) {
 
 	// Test timeout handling with very short timeout
 	t.Run("request timeout", func(t *testing.T) {
-		embedder, err := NewOpenAIEmbedder("text-embedding-3-small")
+		embedder, err := NewOpenAIEmbedder("text-embedding-3-small", WithAPIKey(apiKey))
 		if err != nil {
 			t.Fatalf("Failed to create embedder: %v", err)
 		}
@@ -312,7 +305,7 @@ func TestOpenAIEmbedder_ContextCancellation(t *testing.T) {
 		t.Skip("OPENAI_API_KEY not set, skipping real API tests")
 	}
 
-	embedder, err := NewOpenAIEmbedder("text-embedding-3-small")
+	embedder, err := NewOpenAIEmbedder("text-embedding-3-small", WithAPIKey(apiKey))
 	if err != nil {
 		t.Fatalf("Failed to create embedder: %v", err)
 	}
@@ -340,7 +333,7 @@ func TestOpenAIEmbedder_ConcurrentRequests(t *testing.T) {
 		t.Skip("OPENAI_API_KEY not set, skipping real API tests")
 	}
 
-	embedder, err := NewOpenAIEmbedder("text-embedding-3-small")
+	embedder, err := NewOpenAIEmbedder("text-embedding-3-small", WithAPIKey(apiKey))
 	if err != nil {
 		t.Fatalf("Failed to create embedder: %v", err)
 	}
@@ -393,7 +386,7 @@ func TestOpenAIEmbedder_ModelProperties(t *testing.T) {
 
 	for model, expected := range models {
 		t.Run(model, func(t *testing.T) {
-			embedder, err := NewOpenAIEmbedder(model)
+			embedder, err := NewOpenAIEmbedder(model, WithAPIKey(apiKey))
 			if err != nil {
 				t.Fatalf("Failed to create embedder for %s: %v", model, err)
 			}
@@ -409,6 +402,10 @@ func TestOpenAIEmbedder_ModelProperties(t *testing.T) {
 			if embedder.GetMaxTokens() != expected.maxTokens {
 				t.Errorf("Expected max tokens %d for %s, got %d", expected.maxTokens, model, embedder.GetMaxTokens())
 			}
+
+			if embedder.GetTokenizer().GetName() != "cl100k_base" {
+				t.Errorf("Expected the cl100k_base tokenizer for %s, got %s", model, embedder.GetTokenizer().GetName())
+			}
 		})
 	}
 }
@@ -423,9 +420,105 @@ func BenchmarkNewOpenAIEmbedder(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_, err := NewOpenAIEmbedder("text-embedding-3-small")
+		_, err := NewOpenAIEmbedder("text-embedding-3-small", WithAPIKey(apiKey))
 		if err != nil {
 			b.Fatalf("Error creating embedder: %v", err)
 		}
 	}
 }
+
+func TestOpenAIEmbedder_GenerateEmbeddings(t *testing.T) {
+	// The server answers in reverse order, and leaves out the last input when asked for three
+	var requested OpenAIBatchEmbeddingRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&requested); err != nil {
+			t.Errorf("Failed to decode request: %v", err)
+		}
+		count := len(requested.Input)
+		if count == 3 {
+			count--
+		}
+		var response OpenAIEmbeddingResponse
+		for i := count - 1; i >= 0; i-- {
+			response.Data = append(response.Data, struct {
+				Embedding []float32 `json:"embedding"`
+				Index     int       `json:"index"`
+				Object    string    `json:"object"`
+			}{Embedding: []float32{float32(i)}, Index: i, Object: "embedding"})
+		}
+		_ = json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	embedder, err := NewOpenAIEmbedder("text-embedding-3-small", WithHTTPClient(server.Client()), WithAPIURL(server.URL),
+		WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("Failed to create embedder: %v", err)
+	}
+
+	embeddings, err := embedder.GenerateEmbeddings(context.Background(), []string{"first\nline", "second"})
+	if err != nil {
+		t.Fatalf("GenerateEmbeddings failed: %v", err)
+	}
+	if len(requested.Input) != 2 || requested.Input[0] != "first line" {
+		t.Errorf("Expected both cleaned inputs in one request, got %q", requested.Input)
+	}
+	for i, embedding := range embeddings {
+		if len(embedding) != 1 || embedding[0] != float32(i) {
+			t.Errorf("Expected embedding %d in input order, got %v", i, embedding)
+		}
+	}
+
+	if _, err := embedder.GenerateEmbeddings(context.Background(), []string{"a", "b", "c"}); !errors.Is(err,
+		ErrMissingEmbedding) {
+		t.Errorf("Expected ErrMissingEmbedding, got %v", err)
+	}
+	if _, err := embedder.GenerateEmbeddings(context.Background(), []string{"a", ""}); !errors.Is(err,
+		ErrContentEmpty) {
+		t.Errorf("Expected ErrContentEmpty, got %v", err)
+	}
+}
+
+func TestNewOpenAIEmbedder_WithAPIKey(t *testing.T) {
+	embedder, err := NewOpenAIEmbedder("text-embedding-3-small", WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if embedder.apiKey != "test-key" {
+		t.Errorf("Expected API key test-key, got %q", embedder.apiKey)
+	}
+}
+
+func TestNewOpenAIEmbedder_IgnoresEnvironment(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "sk-environment")
+
+	if _, err := NewOpenAIEmbedder("text-embedding-3-small", WithAPIKey("")); !errors.Is(err, ErrAPIKeyNotSet) {
+		t.Errorf("Expected an empty key to stay empty rather than come from the environment, got %v", err)
+	}
+}
+
+func TestOpenAIEmbedder_CredentialsFromContext(t *testing.T) {
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		_ = json.NewEncoder(w).Encode(OpenAIEmbeddingResponse{})
+	}))
+	defer server.Close()
+
+	embedder, err := NewOpenAIEmbedder("text-embedding-3-small", WithHTTPClient(server.Client()),
+		WithAPIURL(server.URL), WithAPIKey("sk-configured"))
+	if err != nil {
+		t.Fatalf("Failed to create embedder: %v", err)
+	}
+
+	ctx := interfaces.WithCredentials(context.Background(), interfaces.Credentials{OpenAIAPIKey: "sk-caller"})
+	_, _ = embedder.GenerateEmbedding(ctx, "content")
+	if authorization != "Bearer sk-caller" {
+		t.Errorf("Expected the context's key, got %q", authorization)
+	}
+
+	_, _ = embedder.GenerateEmbedding(context.Background(), "content")
+	if authorization != "Bearer sk-configured" {
+		t.Errorf("Expected the embedder's own key, got %q", authorization)
+	}
+}
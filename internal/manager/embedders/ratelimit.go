@@ -0,0 +1,30 @@
+package embedders
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
+)
+
+// reportRateLimit tells the caller's rate-limit observer about resp. Request limits are read from
+// OpenAI's x-ratelimit-limit-requests and x-ratelimit-remaining-requests headers, or from the
+// x-ratelimit-limit and x-ratelimit-remaining headers Together AI sends.
+func reportRateLimit(ctx context.Context, resp *http.Response) {
+	interfaces.ReportRateLimit(ctx, interfaces.RateLimitEvent{
+		Throttled: resp.StatusCode == http.StatusTooManyRequests,
+		Limit:     headerInt(resp.Header, "X-Ratelimit-Limit-Requests", "X-Ratelimit-Limit"),
+		Remaining: headerInt(resp.Header, "X-Ratelimit-Remaining-Requests", "X-Ratelimit-Remaining"),
+	})
+}
+
+// headerInt returns the first of names that holds a non-negative integer, or 0.
+func headerInt(header http.Header, names ...string) int {
+	for _, name := range names {
+		if value, err := strconv.Atoi(header.Get(name)); err == nil && value >= 0 {
+			return value
+		}
+	}
+	return 0
+}
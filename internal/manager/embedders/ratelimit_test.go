@@ -0,0 +1,88 @@
+package embedders
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
+)
+
+func TestReportRateLimit(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		header      http.Header
+		expected    interfaces.RateLimitEvent
+		description string
+	}{
+		{
+			name:   "openai headers",
+			status: http.StatusOK,
+			header: http.Header{
+				"X-Ratelimit-Limit-Requests":     {"3000"},
+				"X-Ratelimit-Remaining-Requests": {"2999"},
+				"X-Ratelimit-Limit-Tokens":       {"1000000"},
+			},
+			expected:    interfaces.RateLimitEvent{Limit: 3000, Remaining: 2999},
+			description: "should read the request limits rather than the token limits",
+		},
+		{
+			name:        "together ai headers",
+			status:      http.StatusOK,
+			header:      http.Header{"X-Ratelimit-Limit": {"60"}, "X-Ratelimit-Remaining": {"5"}},
+			expected:    interfaces.RateLimitEvent{Limit: 60, Remaining: 5},
+			description: "should fall back to the generic rate-limit headers",
+		},
+		{
+			name:        "throttled",
+			status:      http.StatusTooManyRequests,
+			header:      http.Header{},
+			expected:    interfaces.RateLimitEvent{Throttled: true},
+			description: "should report 429 responses as throttled",
+		},
+		{
+			name:        "malformed headers",
+			status:      http.StatusOK,
+			header:      http.Header{"X-Ratelimit-Limit": {"lots"}, "X-Ratelimit-Remaining": {"-1"}},
+			expected:    interfaces.RateLimitEvent{},
+			description: "should ignore values that are not non-negative integers",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []interfaces.RateLimitEvent
+			ctx := interfaces.WithRateLimitObserver(context.Background(), func(event interfaces.RateLimitEvent) {
+				got = append(got, event)
+			})
+
+			reportRateLimit(ctx, &http.Response{StatusCode: tt.status, Header: tt.header})
+
+			if len(got) != 1 || got[0] != tt.expected {
+				t.Errorf("Expected event %+v, got %+v for test: %s", tt.expected, got, tt.description)
+			}
+		})
+	}
+}
+
+func TestReportRateLimit_NoObserver(_ *testing.T) {
+	// Must not panic when the caller does not observe rate limits
+	reportRateLimit(context.Background(), &http.Response{StatusCode: http.StatusOK, Header: http.Header{}})
+}
+
+func TestStatusError(t *testing.T) {
+	var err error = &StatusError{StatusCode: http.StatusTooManyRequests}
+
+	if !errors.Is(err, ErrAPIRequestFailed) {
+		t.Error("Expected StatusError to match ErrAPIRequestFailed")
+	}
+	var status interface{ HTTPStatus() int }
+	if !errors.As(err, &status) || status.HTTPStatus() != http.StatusTooManyRequests {
+		t.Errorf("Expected HTTPStatus 429, got %v", err)
+	}
+	if expected := "API request failed: 429 Too Many Requests"; err.Error() != expected {
+		t.Errorf("Expected %q, got %q", expected, err.Error())
+	}
+}
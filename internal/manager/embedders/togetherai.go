@@ -6,10 +6,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"os"
 	"strings"
 	"time"
 
+	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
+	"github.com/code-sleuth/ike-go/internal/manager/tokenizers"
+	"github.com/code-sleuth/ike-go/pkg/httpclient"
 	"github.com/code-sleuth/ike-go/pkg/util"
 
 	"github.com/rs/zerolog"
@@ -34,6 +36,12 @@ type TogetherAIEmbeddingRequest struct {
 	Model string `json:"model"`
 }
 
+// TogetherAIBatchEmbeddingRequest is an embeddings API request for several inputs at once.
+type TogetherAIBatchEmbeddingRequest struct {
+	Input []string `json:"input"`
+	Model string   `json:"model"`
+}
+
 // TogetherAIEmbeddingResponse represents the response structure from Together AI embeddings API.
 type TogetherAIEmbeddingResponse struct {
 	Data []struct {
@@ -45,21 +53,16 @@ type TogetherAIEmbeddingResponse struct {
 	Object string `json:"object"`
 }
 
-// NewTogetherAIEmbedder creates a new Together AI embedder.
-func NewTogetherAIEmbedder(model string) (*TogetherAIEmbedder, error) {
-	return NewTogetherAIEmbedderWithClient(model, nil, "")
-}
-
-// NewTogetherAIEmbedderWithClient creates a new Together AI embedder with custom HTTP client and API URL.
-func NewTogetherAIEmbedderWithClient(
-	model string,
-	httpClient *http.Client,
-	apiURL string,
-) (*TogetherAIEmbedder, error) {
-	logger := util.NewLogger(zerolog.ErrorLevel)
-	apiKey := os.Getenv("TOGETHER_API_KEY")
-	if strings.EqualFold(apiKey, "") {
-		logger.Error().Msg("TOGETHER_API_KEY env variable not set")
+// NewTogetherAIEmbedder creates a new Together AI embedder for model, configured by opts.
+func NewTogetherAIEmbedder(model string, opts ...Option) (*TogetherAIEmbedder, error) {
+	o := newOptions(opts)
+	logger := util.ComponentLogger("embedders.togetherai", zerolog.ErrorLevel)
+	if o.logger != nil {
+		logger = *o.logger
+	}
+	apiKey := o.apiKey
+	if apiKey == "" {
+		logger.Error().Msg("Together AI API key not set")
 		return nil, ErrAPIKeyNotSet
 	}
 
@@ -78,13 +81,13 @@ func NewTogetherAIEmbedderWithClient(
 	}
 
 	// Use provided HTTP client or create default one
+	httpClient := o.httpClient
 	if httpClient == nil {
-		httpClient = &http.Client{
-			Timeout: timeout,
-		}
+		httpClient = httpclient.New(timeout)
 	}
 
 	// Use provided API URL or default one
+	apiURL := o.apiURL
 	if apiURL == "" {
 		apiURL = "https://api.together.xyz/v1/embeddings"
 	}
@@ -106,16 +109,57 @@ func (t *TogetherAIEmbedder) GenerateEmbedding(ctx context.Context, content stri
 		return nil, ErrContentEmpty
 	}
 
-	// Clean the content (remove newlines and extra spaces)
-	cleanContent := strings.ReplaceAll(content, "\n", " ")
-	cleanContent = strings.TrimSpace(cleanContent)
-
 	// Prepare the request
 	request := TogetherAIEmbeddingRequest{
-		Input: cleanContent,
+		Input: cleanInput(content),
 		Model: t.model,
 	}
 
+	response, err := t.post(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	if len(response.Data) == 0 {
+		t.logger.Warn().Msg("no embedding data in response")
+		return nil, ErrNoEmbeddingData
+	}
+
+	t.logger.Debug().Str("model", t.model).Msg("Generated embedding")
+	return response.Data[0].Embedding, nil
+}
+
+// GenerateEmbeddings creates a vector embedding for each of contents with one request, returning
+// them in the order of contents.
+func (t *TogetherAIEmbedder) GenerateEmbeddings(ctx context.Context, contents []string) ([][]float32, error) {
+	inputs := make([]string, len(contents))
+	for i, content := range contents {
+		if strings.EqualFold(content, "") {
+			return nil, ErrContentEmpty
+		}
+		inputs[i] = cleanInput(content)
+	}
+
+	response, err := t.post(ctx, TogetherAIBatchEmbeddingRequest{Input: inputs, Model: t.model})
+	if err != nil {
+		return nil, err
+	}
+
+	embeddings := make([][]float32, len(contents))
+	for _, data := range response.Data {
+		if data.Index >= 0 && data.Index < len(embeddings) {
+			embeddings[data.Index] = data.Embedding
+		}
+	}
+	if err := checkBatch(embeddings); err != nil {
+		return nil, err
+	}
+
+	t.logger.Debug().Str("model", t.model).Int("inputs", len(contents)).Msg("Generated embeddings")
+	return embeddings, nil
+}
+
+// post sends an embeddings request and decodes the response.
+func (t *TogetherAIEmbedder) post(ctx context.Context, request any) (*TogetherAIEmbeddingResponse, error) {
 	requestBody, err := json.Marshal(request)
 	if err != nil {
 		t.logger.Err(err).Msg("failed to marshal request")
@@ -136,10 +180,12 @@ func (t *TogetherAIEmbedder) GenerateEmbedding(ctx context.Context, content stri
 
 	// Set headers
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", t.apiKey))
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", t.apiKeyFor(ctx)))
 
 	// Make the request
+	start := time.Now()
 	resp, err := t.httpClient.Do(req)
+	observeRequest("togetherai", t.model, start, resp, err)
 	if err != nil {
 		t.logger.Err(err).Msg("failed to make request")
 		return nil, err
@@ -152,9 +198,10 @@ func (t *TogetherAIEmbedder) GenerateEmbedding(ctx context.Context, content stri
 		}
 	}()
 
+	reportRateLimit(ctx, resp)
 	if resp.StatusCode != http.StatusOK {
 		t.logger.Error().Int("status_code", resp.StatusCode).Msg("API request failed")
-		return nil, ErrAPIRequestFailed
+		return nil, &StatusError{StatusCode: resp.StatusCode}
 	}
 
 	// Parse the response
@@ -163,14 +210,7 @@ func (t *TogetherAIEmbedder) GenerateEmbedding(ctx context.Context, content stri
 		t.logger.Err(err).Msg("failed to decode response")
 		return nil, err
 	}
-
-	if len(response.Data) == 0 {
-		t.logger.Warn().Msg("no embedding data in response")
-		return nil, ErrNoEmbeddingData
-	}
-
-	t.logger.Debug().Str("model", t.model).Msg("Generated embedding")
-	return response.Data[0].Embedding, nil
+	return &response, nil
 }
 
 // GetModelName returns the name of the embedding model.
@@ -187,3 +227,17 @@ func (t *TogetherAIEmbedder) GetDimension() int {
 func (t *TogetherAIEmbedder) GetMaxTokens() int {
 	return t.maxTokens
 }
+
+// GetTokenizer returns an estimate of the WordPiece tokenizer of the M2-BERT models.
+func (t *TogetherAIEmbedder) GetTokenizer() interfaces.Tokenizer {
+	return tokenizers.WordPiece{}
+}
+
+// apiKeyFor returns the API key requests made with ctx authenticate with: the Together AI key of the
+// credentials ctx carries, else the embedder's own.
+func (t *TogetherAIEmbedder) apiKeyFor(ctx context.Context) string {
+	if apiKey := interfaces.CredentialsFromContext(ctx).TogetherAPIKey; apiKey != "" {
+		return apiKey
+	}
+	return t.apiKey
+}
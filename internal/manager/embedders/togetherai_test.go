@@ -11,10 +11,6 @@ import (
 )
 
 func TestNewTogetherAIEmbedder(t *testing.T) {
-	// Save original env var
-	originalAPIKey := os.Getenv("TOGETHER_API_KEY")
-	defer os.Setenv("TOGETHER_API_KEY", originalAPIKey)
-
 	tests := []struct {
 		name        string
 		model       string
@@ -82,10 +78,7 @@ func TestNewTogetherAIEmbedder(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Set up environment
-			os.Setenv("TOGETHER_API_KEY", tt.apiKey)
-
-			embedder, err := NewTogetherAIEmbedder(tt.model)
+			embedder, err := NewTogetherAIEmbedder(tt.model, WithAPIKey(tt.apiKey))
 
 			// Check error expectation
 			if tt.expectError && err == nil {
@@ -146,7 +139,7 @@ func TestTogetherAIEmbedder_GenerateEmbedding(t *testing.T) {
 		t.Skip("TOGETHER_API_KEY not set, skipping real API tests")
 	}
 
-	embedder, err := NewTogetherAIEmbedder("togethercomputer/m2-bert-80M-8k-retrieval")
+	embedder, err := NewTogetherAIEmbedder("togethercomputer/m2-bert-80M-8k-retrieval", WithAPIKey(apiKey))
 	if err != nil {
 		t.Fatalf("Failed to create embedder: %v", err)
 	}
@@ -267,12 +260,7 @@ func TestTogetherAIEmbedder_GenerateEmbedding_ErrorHandling(t *testing.T) {
 
 	// Test error handling with invalid API key
 	t.Run("invalid API key", func(t *testing.T) {
-		// Temporarily set invalid API key
-		originalAPIKey := os.Getenv("TOGETHER_API_KEY")
-		os.Setenv("TOGETHER_API_KEY", "invalid-key-12345")
-		defer os.Setenv("TOGETHER_API_KEY", originalAPIKey)
-
-		embedder, err := NewTogetherAIEmbedder("togethercomputer/m2-bert-80M-8k-retrieval")
+		embedder, err := NewTogetherAIEmbedder("togethercomputer/m2-bert-80M-8k-retrieval", WithAPIKey("invalid-key-12345"))
 		if err != nil {
 			t.Fatalf("Failed to create embedder: %v", err)
 		}
@@ -288,7 +276,7 @@ func TestTogetherAIEmbedder_GenerateEmbedding_ErrorHandling(t *testing.T) {
 
 	// Test timeout handling with very short timeout
 	t.Run("request timeout", func(t *testing.T) {
-		embedder, err := NewTogetherAIEmbedder("togethercomputer/m2-bert-80M-8k-retrieval")
+		embedder, err := NewTogetherAIEmbedder("togethercomputer/m2-bert-80M-8k-retrieval", WithAPIKey(apiKey))
 		if err != nil {
 			t.Fatalf("Failed to create embedder: %v", err)
 		}
@@ -345,7 +333,7 @@ func TestTogetherAIEmbedder_ModelCompatibility(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			embedder, err := NewTogetherAIEmbedder(tt.model)
+			embedder, err := NewTogetherAIEmbedder(tt.model, WithAPIKey(apiKey))
 
 			if tt.expectError && err == nil {
 				t.Errorf("Expected error but got none for test: %s", tt.description)
@@ -370,6 +358,11 @@ func TestTogetherAIEmbedder_ModelCompatibility(t *testing.T) {
 					tt.expectedMaxTokens, embedder.GetMaxTokens(), tt.description)
 			}
 
+			if embedder.GetTokenizer().GetName() != "wordpiece" {
+				t.Errorf("Expected the wordpiece tokenizer, got %s for test: %s",
+					embedder.GetTokenizer().GetName(), tt.description)
+			}
+
 			if embedder.GetModelName() != tt.model {
 				t.Errorf("Expected model name %s, got %s for test: %s",
 					tt.model, embedder.GetModelName(), tt.description)
@@ -391,7 +384,7 @@ func TestTogetherAIEmbedder_ContentCleaning(t *testing.T) {
 		t.Skip("TOGETHER_API_KEY not set, skipping real API tests")
 	}
 
-	embedder, err := NewTogetherAIEmbedder("togethercomputer/m2-bert-80M-8k-retrieval")
+	embedder, err := NewTogetherAIEmbedder("togethercomputer/m2-bert-80M-8k-retrieval", WithAPIKey(apiKey))
 	if err != nil {
 		t.Fatalf("Failed to create embedder: %v", err)
 	}
@@ -477,7 +470,7 @@ func TestTogetherAIEmbedder_ModelProperties(t *testing.T) {
 
 	for model, expected := range models {
 		t.Run(model, func(t *testing.T) {
-			embedder, err := NewTogetherAIEmbedder(model)
+			embedder, err := NewTogetherAIEmbedder(model, WithAPIKey(apiKey))
 			if err != nil {
 				t.Fatalf("Failed to create embedder for %s: %v", model, err)
 			}
@@ -507,7 +500,7 @@ func BenchmarkNewTogetherAIEmbedder(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_, err := NewTogetherAIEmbedder("togethercomputer/m2-bert-80M-8k-retrieval")
+		_, err := NewTogetherAIEmbedder("togethercomputer/m2-bert-80M-8k-retrieval", WithAPIKey(apiKey))
 		if err != nil {
 			b.Fatalf("Error creating embedder: %v", err)
 		}
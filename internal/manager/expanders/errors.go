@@ -0,0 +1,10 @@
+package expanders
+
+import "errors"
+
+var (
+	ErrAPIKeyNotSet     = errors.New("API key not set")
+	ErrModelNotSet      = errors.New("model not set")
+	ErrAPIRequestFailed = errors.New("API request failed")
+	ErrNoCompletion     = errors.New("no completion in response")
+)
@@ -0,0 +1,177 @@
+package expanders
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/code-sleuth/ike-go/pkg/httpclient"
+	"github.com/code-sleuth/ike-go/pkg/util"
+
+	"github.com/rs/zerolog"
+)
+
+var timeout = 30 * time.Second
+
+// DefaultModel is the chat model used for query expansion when none is given.
+const DefaultModel = "gpt-4o-mini"
+
+const expansionPrompt = `You rewrite search queries for a documentation search engine.
+Write %d alternative phrasings of the user's query that could match relevant passages.
+Vary vocabulary and specificity, keep identifiers and error messages verbatim.
+Reply with one query per line and nothing else.`
+
+// OpenAIQueryExpander generates query paraphrases with OpenAI's chat completions API.
+type OpenAIQueryExpander struct {
+	apiKey     string
+	model      string
+	httpClient *http.Client
+	apiURL     string
+	logger     zerolog.Logger
+}
+
+// OpenAIChatMessage is a single message in a chat completions request or response.
+type OpenAIChatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// OpenAIChatRequest represents the request structure for the OpenAI chat completions API.
+type OpenAIChatRequest struct {
+	Model       string              `json:"model"`
+	Messages    []OpenAIChatMessage `json:"messages"`
+	Temperature float64             `json:"temperature"`
+}
+
+// OpenAIChatResponse represents the response structure from the OpenAI chat completions API.
+type OpenAIChatResponse struct {
+	Choices []struct {
+		Message OpenAIChatMessage `json:"message"`
+	} `json:"choices"`
+}
+
+// NewOpenAIQueryExpander creates a new OpenAI query expander for model, configured by opts.
+func NewOpenAIQueryExpander(model string, opts ...Option) (*OpenAIQueryExpander, error) {
+	o := newOptions(opts)
+	logger := util.ComponentLogger("expanders.openai", zerolog.ErrorLevel)
+	if o.logger != nil {
+		logger = *o.logger
+	}
+	apiKey := o.apiKey
+	if apiKey == "" {
+		logger.Error().Msg("OpenAI API key not set")
+		return nil, ErrAPIKeyNotSet
+	}
+	if model == "" {
+		return nil, ErrModelNotSet
+	}
+
+	httpClient := o.httpClient
+	if httpClient == nil {
+		httpClient = httpclient.New(timeout)
+	}
+
+	apiURL := o.apiURL
+	if apiURL == "" {
+		apiURL = "https://api.openai.com/v1/chat/completions"
+	}
+
+	return &OpenAIQueryExpander{
+		apiKey:     apiKey,
+		model:      model,
+		httpClient: httpClient,
+		apiURL:     apiURL,
+		logger:     logger,
+	}, nil
+}
+
+// Expand returns up to n paraphrases of query, excluding the query itself.
+func (o *OpenAIQueryExpander) Expand(ctx context.Context, query string, n int) ([]string, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+
+	request := OpenAIChatRequest{
+		Model: o.model,
+		Messages: []OpenAIChatMessage{
+			{Role: "system", Content: fmt.Sprintf(expansionPrompt, n)},
+			{Role: "user", Content: query},
+		},
+		Temperature: 0.7,
+	}
+
+	requestBody, err := json.Marshal(request)
+	if err != nil {
+		o.logger.Err(err).Msg("failed to marshal request")
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.apiURL, bytes.NewBuffer(requestBody))
+	if err != nil {
+		o.logger.Err(err).Msg("failed to create request")
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", o.apiKey))
+
+	resp, err := o.httpClient.Do(req)
+	if err != nil {
+		o.logger.Err(err).Msg("failed to make request")
+		return nil, err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			o.logger.Error().Err(err).Msg("Failed to close response body")
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		o.logger.Error().Int("status_code", resp.StatusCode).Msg("API request failed")
+		return nil, ErrAPIRequestFailed
+	}
+
+	var response OpenAIChatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		o.logger.Err(err).Msg("failed to decode response")
+		return nil, err
+	}
+	if len(response.Choices) == 0 {
+		return nil, ErrNoCompletion
+	}
+
+	paraphrases := parseParaphrases(response.Choices[0].Message.Content, query, n)
+	o.logger.Debug().Str("model", o.model).Int("paraphrases", len(paraphrases)).Msg("Expanded query")
+	return paraphrases, nil
+}
+
+// GetModelName returns the name of the chat model.
+func (o *OpenAIQueryExpander) GetModelName() string {
+	return o.model
+}
+
+// parseParaphrases extracts one query per line, stripping list markers and quotes, and drops
+// blanks, duplicates, and repeats of the original query.
+func parseParaphrases(content, query string, n int) []string {
+	seen := map[string]bool{strings.ToLower(strings.TrimSpace(query)): true}
+	var paraphrases []string
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		line = strings.TrimLeft(line, "-*•0123456789.) ")
+		line = strings.Trim(line, `"'`)
+		key := strings.ToLower(line)
+		if line == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		paraphrases = append(paraphrases, line)
+		if len(paraphrases) == n {
+			break
+		}
+	}
+	return paraphrases
+}
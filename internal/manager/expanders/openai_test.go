@@ -0,0 +1,95 @@
+package expanders
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestParseParaphrases(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		n        int
+		expected []string
+	}{
+		{
+			name:     "plain lines",
+			content:  "rotate api keys\nchange credentials",
+			n:        3,
+			expected: []string{"rotate api keys", "change credentials"},
+		},
+		{
+			name:     "numbered and quoted",
+			content:  "1. \"rotate api keys\"\n2) change credentials\n- renew tokens",
+			n:        3,
+			expected: []string{"rotate api keys", "change credentials", "renew tokens"},
+		},
+		{
+			name:     "drops original and duplicates",
+			content:  "How do I rotate keys\nrotate api keys\nRotate API keys\n\n",
+			n:        3,
+			expected: []string{"rotate api keys"},
+		},
+		{
+			name:     "limit",
+			content:  "a query\nb query\nc query",
+			n:        2,
+			expected: []string{"a query", "b query"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseParaphrases(tt.content, "how do I rotate keys", tt.n); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestOpenAIQueryExpander_Expand(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request OpenAIChatRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("Failed to decode request: %v", err)
+		}
+		if len(request.Messages) != 2 || request.Messages[1].Content != "rotate keys" {
+			t.Errorf("Expected system and user messages, got %+v", request.Messages)
+		}
+
+		response := OpenAIChatResponse{}
+		response.Choices = append(response.Choices, struct {
+			Message OpenAIChatMessage `json:"message"`
+		}{Message: OpenAIChatMessage{Role: "assistant", Content: "renew api keys\nreplace credentials"}})
+		_ = json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	expander, err := NewOpenAIQueryExpander(DefaultModel, WithHTTPClient(server.Client()), WithAPIURL(server.URL),
+		WithAPIKey("test-api-key"))
+	if err != nil {
+		t.Fatalf("Failed to create expander: %v", err)
+	}
+
+	paraphrases, err := expander.Expand(context.Background(), "rotate keys", 2)
+	if err != nil {
+		t.Fatalf("Failed to expand: %v", err)
+	}
+	if !reflect.DeepEqual(paraphrases, []string{"renew api keys", "replace credentials"}) {
+		t.Errorf("Unexpected paraphrases: %v", paraphrases)
+	}
+}
+
+func TestNewOpenAIQueryExpander_MissingKey(t *testing.T) {
+	// An empty key stays empty rather than coming from the environment
+	t.Setenv("OPENAI_API_KEY", "sk-environment")
+
+	if _, err := NewOpenAIQueryExpander(DefaultModel); !errors.Is(err, ErrAPIKeyNotSet) {
+		t.Errorf("Expected %v, got %v", ErrAPIKeyNotSet, err)
+	}
+}
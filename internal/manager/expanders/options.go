@@ -0,0 +1,57 @@
+package expanders
+
+import (
+	"net/http"
+
+	"github.com/rs/zerolog"
+)
+
+// Option configures a expander when it is created, as in
+// NewOpenAIQueryExpander(model, WithAPIKey(key)).
+type Option func(*options)
+
+// options are the settings Options give; unset ones leave the expander's default.
+type options struct {
+	httpClient *http.Client
+	apiURL     string
+	apiKey     string
+	logger     *zerolog.Logger
+}
+
+// newOptions returns the settings given by opts.
+func newOptions(opts []Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithHTTPClient sends the expander's requests through client, such as one for a test server.
+func WithHTTPClient(client *http.Client) Option {
+	return func(o *options) {
+		o.httpClient = client
+	}
+}
+
+// WithAPIURL sets the endpoint the expander calls, such as a proxy's.
+func WithAPIURL(apiURL string) Option {
+	return func(o *options) {
+		o.apiURL = apiURL
+	}
+}
+
+// WithAPIKey sets the API key; without it, creating the component fails with ErrAPIKeyNotSet.
+func WithAPIKey(apiKey string) Option {
+	return func(o *options) {
+		o.apiKey = apiKey
+	}
+}
+
+// WithLogger sends the query expander's logs to logger, such as one from util.NewSlogLogger, in
+// place of the component logger it would create.
+func WithLogger(logger zerolog.Logger) Option {
+	return func(o *options) {
+		o.logger = &logger
+	}
+}
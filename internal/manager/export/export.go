@@ -0,0 +1,241 @@
+package export
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/code-sleuth/ike-go/pkg/db"
+	"github.com/code-sleuth/ike-go/pkg/util"
+	"github.com/code-sleuth/ike-go/pkg/vector"
+
+	"github.com/rs/zerolog"
+)
+
+var ErrUnsupportedFormat = errors.New("unsupported export format")
+
+// Options selects what an export contains and how it is written.
+type Options struct {
+	Format Format
+	// SourceIDs limits the export to documents from these sources; empty exports every source.
+	SourceIDs []string
+	// Host limits the export to sources served from this host.
+	Host string
+	// Collection limits the export to sources in this collection.
+	Collection string
+}
+
+// Summary reports how many records of each kind were exported and where they were written.
+type Summary struct {
+	Documents  int      `json:"documents"`
+	Chunks     int      `json:"chunks"`
+	Embeddings int      `json:"embeddings"`
+	Files      []string `json:"files"`
+}
+
+// Exporter dumps live documents with their chunks and embeddings to files.
+type Exporter struct {
+	db     *db.DB
+	logger zerolog.Logger
+}
+
+func NewExporter(database *db.DB) *Exporter {
+	logger := util.NewLogger(zerolog.ErrorLevel)
+	return &Exporter{
+		db:     database,
+		logger: logger,
+	}
+}
+
+// Export writes documents, chunks, and embeddings to separate files in dir, named after the
+// record kind with the format as extension (e.g. chunks.jsonl). Rows are streamed, so the corpus
+// is never held in memory.
+func (e *Exporter) Export(ctx context.Context, dir string, opts Options) (*Summary, error) {
+	if _, err := ParseFormat(string(opts.Format)); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+
+	documentIDs, documentArgs := documentSelection(opts)
+	chunkIDs := `SELECT id FROM chunks WHERE document_id IN (` + documentIDs + `)`
+
+	summary := &Summary{}
+	steps := []struct {
+		name  string
+		count *int
+		run   func(path string) (int, error)
+	}{
+		{"documents", &summary.Documents, func(path string) (int, error) {
+			return exportFile(ctx, e, path, opts.Format, documentsQuery(documentIDs), documentArgs, scanDocument)
+		}},
+		{"chunks", &summary.Chunks, func(path string) (int, error) {
+			return exportFile(ctx, e, path, opts.Format, chunksQuery(documentIDs), documentArgs, scanChunk)
+		}},
+		{"embeddings", &summary.Embeddings, func(path string) (int, error) {
+			return exportFile(ctx, e, path, opts.Format, embeddingsQuery(chunkIDs), documentArgs, scanEmbedding)
+		}},
+	}
+
+	for _, step := range steps {
+		path := filepath.Join(dir, step.name+"."+string(opts.Format))
+		count, err := step.run(path)
+		if err != nil {
+			e.logger.Error().Err(err).Str("file", path).Msg("Failed to export " + step.name)
+			return summary, err
+		}
+		*step.count = count
+		summary.Files = append(summary.Files, path)
+	}
+
+	return summary, nil
+}
+
+// documentSelection returns the subquery selecting the IDs of exported documents and its arguments.
+func documentSelection(opts Options) (string, []interface{}) {
+	clauses := []string{"d.deleted_at IS NULL", "s.deleted_at IS NULL"}
+	var args []interface{}
+
+	if len(opts.SourceIDs) > 0 {
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(opts.SourceIDs)), ", ")
+		clauses = append(clauses, "d.source_id IN ("+placeholders+")")
+		for _, id := range opts.SourceIDs {
+			args = append(args, id)
+		}
+	}
+	if opts.Host != "" {
+		clauses = append(clauses, "s.host = ?")
+		args = append(args, opts.Host)
+	}
+	if opts.Collection != "" {
+		clauses = append(clauses, "s.collection = ?")
+		args = append(args, opts.Collection)
+	}
+
+	return `SELECT d.id FROM documents d JOIN sources s ON s.id = d.source_id WHERE ` +
+		strings.Join(clauses, " AND "), args
+}
+
+func documentsQuery(documentIDs string) string {
+	// #nosec G202 -- subquery is built from constants, values are bound through args
+	return `SELECT d.id, d.source_id, d.download_id, s.raw_url, d.format, d.indexed_at, d.published_at,
+		d.modified_at, d.wp_version, d.min_chunk_size, d.max_chunk_size
+		FROM documents d JOIN sources s ON s.id = d.source_id
+		WHERE d.id IN (` + documentIDs + `) ORDER BY d.id`
+}
+
+func chunksQuery(documentIDs string) string {
+	// #nosec G202 -- subquery is built from constants, values are bound through args
+	return `SELECT id, document_id, parent_chunk_id, left_chunk_id, right_chunk_id, body, byte_size,
+		tokenizer, token_count, natural_lang, code_lang
+		FROM chunks WHERE document_id IN (` + documentIDs + `) ORDER BY document_id, id`
+}
+
+func embeddingsQuery(chunkIDs string) string {
+	// Each row stores its vector in exactly one of the per-dimension columns
+	// #nosec G202 -- subquery is built from constants, values are bound through args
+	return `SELECT id, object_id, object_type, model, embedded_at,
+		COALESCE(embedding_768, embedding_1536, embedding_3072),
+		typeof(COALESCE(embedding_768, embedding_1536, embedding_3072))
+		FROM embeddings WHERE object_type = 'chunk' AND object_id IN (` + chunkIDs + `) ORDER BY object_id, id`
+}
+
+// exportFile runs query and streams every scanned row into a new file at path.
+func exportFile[T any](
+	ctx context.Context,
+	e *Exporter,
+	path string,
+	format Format,
+	query string,
+	args []interface{},
+	scan func(rows *sql.Rows) (T, error),
+) (count int, err error) {
+	file, err := os.Create(filepath.Clean(path))
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	writer, err := newRecordWriter[T](format, file)
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := e.db.Reader().QueryContext(ctx, e.db.Rebind(query), args...)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		record, err := scan(rows)
+		if err != nil {
+			return count, err
+		}
+		if err := writer.Write(record); err != nil {
+			return count, err
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return count, err
+	}
+
+	return count, writer.Close()
+}
+
+func scanDocument(rows *sql.Rows) (DocumentRecord, error) {
+	var record DocumentRecord
+	err := rows.Scan(&record.ID, &record.SourceID, &record.DownloadID, &record.SourceURL, &record.Format,
+		&record.IndexedAt, &record.PublishedAt, &record.ModifiedAt, &record.WPVersion,
+		&record.MinChunkSize, &record.MaxChunkSize)
+	return record, err
+}
+
+func scanChunk(rows *sql.Rows) (ChunkRecord, error) {
+	var record ChunkRecord
+	err := rows.Scan(&record.ID, &record.DocumentID, &record.ParentChunkID, &record.LeftChunkID,
+		&record.RightChunkID, &record.Body, &record.ByteSize, &record.Tokenizer, &record.TokenCount,
+		&record.NaturalLang, &record.CodeLang)
+	return record, err
+}
+
+func scanEmbedding(rows *sql.Rows) (EmbeddingRecord, error) {
+	var record EmbeddingRecord
+	var raw []byte
+	var storage sql.NullString
+	err := rows.Scan(&record.ID, &record.ObjectID, &record.ObjectType, &record.Model, &record.EmbeddedAt,
+		&raw, &storage)
+	if err != nil {
+		return record, err
+	}
+
+	record.Vector, err = decodeVector(raw, storage.String)
+	if err != nil {
+		return record, fmt.Errorf("embedding %s: %w", record.ID, err)
+	}
+	record.Dimensions = int32(len(record.Vector)) // #nosec G115 -- vectors have at most 3072 dimensions
+	return record, nil
+}
+
+// decodeVector decodes a stored vector, accepting text vectors not yet converted by
+// `ike-go migrate --convert-embeddings`.
+func decodeVector(raw []byte, storage string) ([]float32, error) {
+	switch storage {
+	case "null", "":
+		return nil, nil
+	case "text":
+		return vector.ParseLegacy(string(raw))
+	default:
+		return vector.Decode(raw)
+	}
+}
@@ -0,0 +1,176 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/code-sleuth/ike-go/pkg/vector"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expected    Format
+		expectedErr error
+		description string
+	}{
+		{
+			name:        "jsonl",
+			input:       "jsonl",
+			expected:    FormatJSONL,
+			description: "should accept JSONL",
+		},
+		{
+			name:        "parquet",
+			input:       "parquet",
+			expected:    FormatParquet,
+			description: "should accept Parquet",
+		},
+		{
+			name:        "csv",
+			input:       "csv",
+			expectedErr: ErrUnsupportedFormat,
+			description: "should reject unsupported formats",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			format, err := ParseFormat(tt.input)
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("Expected error %v, got %v", tt.expectedErr, err)
+			}
+			if format != tt.expected {
+				t.Errorf("Expected format %s, got %s", tt.expected, format)
+			}
+		})
+	}
+}
+
+func testEmbeddings() []EmbeddingRecord {
+	model := "text-embedding-3-small"
+	return []EmbeddingRecord{
+		{ID: "e1", ObjectID: "c1", ObjectType: "chunk", Model: &model, EmbeddedAt: "2024-01-01T00:00:00Z",
+			Dimensions: 3, Vector: []float32{0.1, 0.2, 0.3}},
+		{ID: "e2", ObjectID: "c2", ObjectType: "chunk", EmbeddedAt: "2024-01-02T00:00:00Z",
+			Dimensions: 2, Vector: []float32{-1, 1}},
+	}
+}
+
+func TestJSONLWriter(t *testing.T) {
+	var buf bytes.Buffer
+	writer, err := newRecordWriter[EmbeddingRecord](FormatJSONL, &buf)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	for _, record := range testEmbeddings() {
+		if err := writer.Write(record); err != nil {
+			t.Fatalf("Expected no error writing record, got %v", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Expected no error closing writer, got %v", err)
+	}
+
+	scanner := bufio.NewScanner(&buf)
+	lines := 0
+	for scanner.Scan() {
+		var record EmbeddingRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("Expected valid JSON on line %d, got %v", lines+1, err)
+		}
+		if record.Dimensions != int32(len(record.Vector)) {
+			t.Errorf("Expected %d dimensions, got %d", len(record.Vector), record.Dimensions)
+		}
+		lines++
+	}
+	if lines != 2 {
+		t.Errorf("Expected 2 lines, got %d", lines)
+	}
+}
+
+func TestParquetWriter(t *testing.T) {
+	var buf bytes.Buffer
+	writer, err := newRecordWriter[EmbeddingRecord](FormatParquet, &buf)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := testEmbeddings()
+	for _, record := range expected {
+		if err := writer.Write(record); err != nil {
+			t.Fatalf("Expected no error writing record, got %v", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Expected no error closing writer, got %v", err)
+	}
+
+	records, err := parquet.Read[EmbeddingRecord](bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("Expected readable Parquet file, got %v", err)
+	}
+	if len(records) != len(expected) {
+		t.Fatalf("Expected %d records, got %d", len(expected), len(records))
+	}
+	for i, record := range records {
+		if record.ID != expected[i].ID || len(record.Vector) != len(expected[i].Vector) {
+			t.Errorf("Expected record %+v, got %+v", expected[i], record)
+		}
+	}
+	if records[1].Model != nil {
+		t.Errorf("Expected nil model, got %s", *records[1].Model)
+	}
+}
+
+func TestDocumentSelection(t *testing.T) {
+	query, args := documentSelection(Options{SourceIDs: []string{"a", "b"}, Host: "github.com"})
+
+	if !strings.Contains(query, "d.source_id IN (?, ?)") {
+		t.Errorf("Expected source filter in query, got %s", query)
+	}
+	if !strings.Contains(query, "s.host = ?") {
+		t.Errorf("Expected host filter in query, got %s", query)
+	}
+	if len(args) != 3 || args[0] != "a" || args[1] != "b" || args[2] != "github.com" {
+		t.Errorf("Expected args [a b github.com], got %v", args)
+	}
+}
+
+func TestDocumentSelection_Collection(t *testing.T) {
+	query, args := documentSelection(Options{Collection: "internal-wiki"})
+
+	if !strings.Contains(query, "s.collection = ?") {
+		t.Errorf("Expected collection filter in query, got %s", query)
+	}
+	if len(args) != 1 || args[0] != "internal-wiki" {
+		t.Errorf("Expected args [internal-wiki], got %v", args)
+	}
+}
+
+func TestDecodeVector(t *testing.T) {
+	values := []float32{0.5, -0.25}
+
+	decoded, err := decodeVector(vector.Encode(values), "blob")
+	if err != nil || len(decoded) != 2 || decoded[0] != 0.5 {
+		t.Errorf("Expected %v, got %v (err %v)", values, decoded, err)
+	}
+
+	decoded, err = decodeVector([]byte("[[0.5 -0.25]]"), "text")
+	if err != nil || len(decoded) != 2 || decoded[1] != -0.25 {
+		t.Errorf("Expected %v from legacy text, got %v (err %v)", values, decoded, err)
+	}
+
+	decoded, err = decodeVector(nil, "null")
+	if err != nil || decoded != nil {
+		t.Errorf("Expected nil vector, got %v (err %v)", decoded, err)
+	}
+}
@@ -0,0 +1,196 @@
+package export
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+var ErrUnsupportedFineTuneStyle = errors.New("unsupported fine-tuning style")
+
+// FineTuneStyle is the record layout of a fine-tuning export.
+type FineTuneStyle string
+
+const (
+	// FineTuneOpenAI writes chat examples as OpenAI's fine-tuning API expects:
+	// {"messages": [{"role": "system", ...}, {"role": "user", ...}, {"role": "assistant", ...}]}.
+	FineTuneOpenAI FineTuneStyle = "openai"
+	// FineTuneHF writes {"prompt": ..., "completion": ...}, or {"text": ...} without a prompt, as
+	// Hugging Face datasets and TRL trainers load them.
+	FineTuneHF FineTuneStyle = "hf"
+)
+
+// ParseFineTuneStyle validates a fine-tuning style name.
+func ParseFineTuneStyle(name string) (FineTuneStyle, error) {
+	switch FineTuneStyle(name) {
+	case FineTuneOpenAI, FineTuneHF:
+		return FineTuneStyle(name), nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedFineTuneStyle, name)
+	}
+}
+
+// FineTuneOptions selects the chunks of a fine-tuning export and how examples are built from them.
+type FineTuneOptions struct {
+	Style FineTuneStyle
+	// SourceIDs limits the export to documents from these sources; empty exports every source.
+	SourceIDs []string
+	// Host limits the export to sources served from this host.
+	Host string
+	// Collection limits the export to sources in this collection.
+	Collection string
+	// Prompt is a text/template rendered with each chunk's PromptData into the prompt the chunk
+	// answers, such as "Summarize {{.Meta.title}}". Empty writes chunks without prompts.
+	Prompt string
+	// System is the system message of OpenAI examples; empty leaves it out.
+	System string
+	// MinTokens skips chunks with fewer tokens, such as headings split off on their own.
+	MinTokens int
+}
+
+// PromptData is what prompt templates are rendered with. Meta holds the document's metadata,
+// such as title or path, as imported.
+type PromptData struct {
+	SourceURL  string
+	DocumentID string
+	ChunkID    string
+	Format     string
+	Meta       map[string]string
+}
+
+// FineTuneSummary reports how many examples were exported and where they were written.
+type FineTuneSummary struct {
+	Examples int    `json:"examples"`
+	File     string `json:"file"`
+}
+
+// FineTuneMessage is one turn of an OpenAI chat example.
+type FineTuneMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// FineTuneRecord is one fine-tuning example; only the fields of its style are set.
+type FineTuneRecord struct {
+	Messages   []FineTuneMessage `json:"messages,omitempty"`
+	Prompt     string            `json:"prompt,omitempty"`
+	Completion string            `json:"completion,omitempty"`
+	Text       string            `json:"text,omitempty"`
+}
+
+// ExportFineTune writes a JSONL fine-tuning example to path for every chunk of the selected live
+// documents, with the chunk as the answer to the rendered prompt. Rows are streamed, so the
+// corpus is never held in memory.
+func (e *Exporter) ExportFineTune(ctx context.Context, path string, opts FineTuneOptions) (*FineTuneSummary, error) {
+	if _, err := ParseFineTuneStyle(string(opts.Style)); err != nil {
+		return nil, err
+	}
+	var prompt *template.Template
+	if opts.Prompt != "" {
+		var err error
+		if prompt, err = template.New("prompt").Option("missingkey=zero").Parse(opts.Prompt); err != nil {
+			return nil, fmt.Errorf("invalid prompt template: %w", err)
+		}
+	}
+
+	documentIDs, args := documentSelection(Options{
+		SourceIDs: opts.SourceIDs, Host: opts.Host, Collection: opts.Collection,
+	})
+	// #nosec G202 -- subquery is built from constants, values are bound through args
+	query := `SELECT c.id, c.document_id, c.body, s.raw_url, d.format
+		FROM chunks c JOIN documents d ON d.id = c.document_id JOIN sources s ON s.id = d.source_id
+		WHERE c.document_id IN (` + documentIDs + `) AND c.body IS NOT NULL AND c.body != ''`
+	if opts.MinTokens > 0 {
+		query += ` AND c.token_count >= ?`
+		args = append(args, opts.MinTokens)
+	}
+	query += ` ORDER BY c.document_id, c.id`
+
+	// Chunks arrive grouped by document, so each document's metadata is loaded once
+	var metaDocument string
+	var meta map[string]string
+	scan := func(rows *sql.Rows) (FineTuneRecord, error) {
+		var data PromptData
+		var body string
+		var sourceURL, format sql.NullString
+		if err := rows.Scan(&data.ChunkID, &data.DocumentID, &body, &sourceURL, &format); err != nil {
+			return FineTuneRecord{}, err
+		}
+		data.SourceURL, data.Format = sourceURL.String, format.String
+
+		var rendered string
+		if prompt != nil {
+			if data.DocumentID != metaDocument {
+				var err error
+				if meta, err = e.documentMeta(ctx, data.DocumentID); err != nil {
+					return FineTuneRecord{}, err
+				}
+				metaDocument = data.DocumentID
+			}
+			data.Meta = meta
+
+			var b strings.Builder
+			if err := prompt.Execute(&b, data); err != nil {
+				return FineTuneRecord{}, fmt.Errorf("chunk %s: %w", data.ChunkID, err)
+			}
+			rendered = b.String()
+		}
+		return newFineTuneRecord(opts, rendered, body), nil
+	}
+
+	count, err := exportFile(ctx, e, path, FormatJSONL, query, args, scan)
+	if err != nil {
+		e.logger.Error().Err(err).Str("file", path).Msg("Failed to export fine-tuning examples")
+		return nil, err
+	}
+	return &FineTuneSummary{Examples: count, File: path}, nil
+}
+
+// newFineTuneRecord builds the example of opts' style answering prompt with body.
+func newFineTuneRecord(opts FineTuneOptions, prompt, body string) FineTuneRecord {
+	if opts.Style == FineTuneHF {
+		if prompt == "" {
+			return FineTuneRecord{Text: body}
+		}
+		return FineTuneRecord{Prompt: prompt, Completion: body}
+	}
+
+	var messages []FineTuneMessage
+	if opts.System != "" {
+		messages = append(messages, FineTuneMessage{Role: "system", Content: opts.System})
+	}
+	if prompt != "" {
+		messages = append(messages, FineTuneMessage{Role: "user", Content: prompt})
+	}
+	return FineTuneRecord{Messages: append(messages, FineTuneMessage{Role: "assistant", Content: body})}
+}
+
+// documentMeta returns a document's metadata. Values stored JSON-encoded are decoded when they
+// are strings, so templates see "Title" rather than "\"Title\"".
+func (e *Exporter) documentMeta(ctx context.Context, documentID string) (map[string]string, error) {
+	rows, err := e.db.Reader().QueryContext(ctx,
+		e.db.Rebind(`SELECT "key", meta FROM document_meta WHERE document_id = ?`), documentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	meta := make(map[string]string)
+	for rows.Next() {
+		var key string
+		var value sql.NullString
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, err
+		}
+		meta[key] = value.String
+		var decoded string
+		if json.Unmarshal([]byte(value.String), &decoded) == nil {
+			meta[key] = decoded
+		}
+	}
+	return meta, rows.Err()
+}
@@ -0,0 +1,62 @@
+package export
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestParseFineTuneStyle(t *testing.T) {
+	for _, name := range []string{"openai", "hf"} {
+		if style, err := ParseFineTuneStyle(name); err != nil || string(style) != name {
+			t.Errorf("Expected %s to be accepted, got %s, %v", name, style, err)
+		}
+	}
+	if _, err := ParseFineTuneStyle("alpaca"); !errors.Is(err, ErrUnsupportedFineTuneStyle) {
+		t.Errorf("Expected ErrUnsupportedFineTuneStyle, got %v", err)
+	}
+}
+
+func TestNewFineTuneRecord(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     FineTuneOptions
+		prompt   string
+		expected string
+	}{
+		{
+			name:     "openai with system and prompt",
+			opts:     FineTuneOptions{Style: FineTuneOpenAI, System: "You answer from the docs."},
+			prompt:   "Explain Install",
+			expected: `{"messages":[{"role":"system","content":"You answer from the docs."},{"role":"user","content":"Explain Install"},{"role":"assistant","content":"Run make."}]}`,
+		},
+		{
+			name:     "openai without prompt",
+			opts:     FineTuneOptions{Style: FineTuneOpenAI},
+			expected: `{"messages":[{"role":"assistant","content":"Run make."}]}`,
+		},
+		{
+			name:     "hf with prompt",
+			opts:     FineTuneOptions{Style: FineTuneHF},
+			prompt:   "Explain Install",
+			expected: `{"prompt":"Explain Install","completion":"Run make."}`,
+		},
+		{
+			name:     "hf without prompt",
+			opts:     FineTuneOptions{Style: FineTuneHF},
+			expected: `{"text":"Run make."}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(newFineTuneRecord(tt.opts, tt.prompt, "Run make."))
+			if err != nil {
+				t.Fatalf("Failed to marshal record: %v", err)
+			}
+			if string(data) != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, data)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package export
+
+// DocumentRecord is one exported document, together with the URL of its source.
+type DocumentRecord struct {
+	ID           string  `json:"id"                     parquet:"id"`
+	SourceID     string  `json:"source_id"              parquet:"source_id"`
+	DownloadID   string  `json:"download_id"            parquet:"download_id"`
+	SourceURL    *string `json:"source_url,omitempty"   parquet:"source_url,optional"`
+	Format       *string `json:"format,omitempty"       parquet:"format,optional"`
+	IndexedAt    *string `json:"indexed_at,omitempty"   parquet:"indexed_at,optional"`
+	PublishedAt  *string `json:"published_at,omitempty" parquet:"published_at,optional"`
+	ModifiedAt   *string `json:"modified_at,omitempty"  parquet:"modified_at,optional"`
+	WPVersion    *string `json:"wp_version,omitempty"   parquet:"wp_version,optional"`
+	MinChunkSize int64   `json:"min_chunk_size"         parquet:"min_chunk_size"`
+	MaxChunkSize int64   `json:"max_chunk_size"         parquet:"max_chunk_size"`
+}
+
+// ChunkRecord is one exported chunk of a document.
+type ChunkRecord struct {
+	ID            string  `json:"id"                        parquet:"id"`
+	DocumentID    string  `json:"document_id"               parquet:"document_id"`
+	ParentChunkID *string `json:"parent_chunk_id,omitempty" parquet:"parent_chunk_id,optional"`
+	LeftChunkID   *string `json:"left_chunk_id,omitempty"   parquet:"left_chunk_id,optional"`
+	RightChunkID  *string `json:"right_chunk_id,omitempty"  parquet:"right_chunk_id,optional"`
+	Body          *string `json:"body,omitempty"            parquet:"body,optional"`
+	ByteSize      *int64  `json:"byte_size,omitempty"       parquet:"byte_size,optional"`
+	Tokenizer     *string `json:"tokenizer,omitempty"       parquet:"tokenizer,optional"`
+	TokenCount    *int64  `json:"token_count,omitempty"     parquet:"token_count,optional"`
+	NaturalLang   *string `json:"natural_lang,omitempty"    parquet:"natural_lang,optional"`
+	CodeLang      *string `json:"code_lang,omitempty"       parquet:"code_lang,optional"`
+}
+
+// EmbeddingRecord is one exported embedding vector. Dimensions is the vector's length, so
+// consumers can tell models apart without inspecting the vector.
+type EmbeddingRecord struct {
+	ID         string    `json:"id"              parquet:"id"`
+	ObjectID   string    `json:"object_id"       parquet:"object_id"`
+	ObjectType string    `json:"object_type"     parquet:"object_type"`
+	Model      *string   `json:"model,omitempty" parquet:"model,optional"`
+	EmbeddedAt string    `json:"embedded_at"     parquet:"embedded_at"`
+	Dimensions int32     `json:"dimensions"      parquet:"dimensions"`
+	Vector     []float32 `json:"vector"          parquet:"vector,list"`
+}
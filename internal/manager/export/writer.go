@@ -0,0 +1,71 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// Format is an export file format.
+type Format string
+
+const (
+	FormatJSONL   Format = "jsonl"
+	FormatParquet Format = "parquet"
+)
+
+// ParseFormat validates a format name.
+func ParseFormat(name string) (Format, error) {
+	switch Format(name) {
+	case FormatJSONL, FormatParquet:
+		return Format(name), nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedFormat, name)
+	}
+}
+
+// recordWriter streams records of a single type to an output file.
+type recordWriter[T any] interface {
+	Write(record T) error
+	Close() error
+}
+
+func newRecordWriter[T any](format Format, w io.Writer) (recordWriter[T], error) {
+	switch format {
+	case FormatJSONL:
+		return &jsonlWriter[T]{encoder: json.NewEncoder(w)}, nil
+	case FormatParquet:
+		return &parquetWriter[T]{writer: parquet.NewGenericWriter[T](w)}, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, format)
+	}
+}
+
+// jsonlWriter writes one JSON object per line.
+type jsonlWriter[T any] struct {
+	encoder *json.Encoder
+}
+
+func (w *jsonlWriter[T]) Write(record T) error {
+	return w.encoder.Encode(record)
+}
+
+func (w *jsonlWriter[T]) Close() error {
+	return nil
+}
+
+// parquetWriter buffers records into row groups and writes the footer on Close.
+type parquetWriter[T any] struct {
+	writer *parquet.GenericWriter[T]
+}
+
+func (w *parquetWriter[T]) Write(record T) error {
+	_, err := w.writer.Write([]T{record})
+	return err
+}
+
+func (w *parquetWriter[T]) Close() error {
+	return w.writer.Close()
+}
@@ -0,0 +1,95 @@
+package importers
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/code-sleuth/ike-go/pkg/blobstore"
+	"github.com/code-sleuth/ike-go/pkg/compression"
+	"github.com/code-sleuth/ike-go/pkg/dialect"
+)
+
+// contentHash returns the hex-encoded SHA-256 of a download body.
+func contentHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// storeBody records content in download_bodies unless an identical body is already stored,
+// and returns the hash downloads use to reference it. Bodies large enough for the configured blob
+// store are written there instead, and their row records the blob's key.
+func storeBody(ctx context.Context, db dbExecutor, content string) (string, error) {
+	hash := contentHash(content)
+
+	var exists int
+	err := db.QueryRowContext(ctx, `SELECT 1 FROM download_bodies WHERE content_hash = ?`, hash).Scan(&exists)
+	if err == nil {
+		return hash, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return "", err
+	}
+
+	var body interface{}
+	var bodyEncoding string
+	var blobKey sql.NullString
+	if blobs := blobstore.Default(); blobs.Stores(len(content)) {
+		blobKey = sql.NullString{String: blobstore.BodyKey(hash), Valid: true}
+		body, bodyEncoding = []byte{}, compression.EncodingGzip
+		if err := putBlob(ctx, blobs.Store, blobKey.String, content); err != nil {
+			return "", err
+		}
+	} else {
+		body, bodyEncoding, err = compression.EncodeBody(content, compression.DefaultMinSize)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	query := `INSERT INTO download_bodies (content_hash, body, body_encoding, size, created_at, blob_key)
+			  VALUES (?, ?, ?, ?, ?, ?)
+			  ON CONFLICT (content_hash) DO NOTHING`
+
+	_, err = db.ExecContext(ctx, query, hash, body, bodyEncoding, len(content),
+		dialect.SQLite.FormatTime(time.Now()), blobKey)
+	if err != nil {
+		return "", err
+	}
+
+	return hash, nil
+}
+
+// putBlob gzips content into a temporary file, so the compressed copy isn't held in memory too,
+// and streams it to the blob store under key.
+func putBlob(ctx context.Context, store blobstore.Store, key, content string) error {
+	file, err := os.CreateTemp("", "ike-body-*.gz")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	writer := gzip.NewWriter(file)
+	if _, err := io.Copy(writer, strings.NewReader(content)); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	size, err := file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	return store.Put(ctx, key, file, size)
+}
@@ -0,0 +1,31 @@
+package importers
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/code-sleuth/ike-go/pkg/blobstore"
+	"github.com/code-sleuth/ike-go/pkg/compression"
+)
+
+func TestPutBlob(t *testing.T) {
+	store, err := blobstore.NewDisk(t.TempDir())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	content := strings.Repeat("a body worth compressing ", 1000)
+	key := blobstore.BodyKey(contentHash(content))
+
+	if err := putBlob(context.Background(), store, key, content); err != nil {
+		t.Fatalf("Failed to put blob: %v", err)
+	}
+
+	body, err := blobstore.Config{Store: store}.ReadBody(context.Background(), key, compression.EncodingGzip)
+	if err != nil {
+		t.Fatalf("Failed to read blob: %v", err)
+	}
+	if body != content {
+		t.Errorf("Expected the stored body back, got %d bytes", len(body))
+	}
+}
@@ -0,0 +1,453 @@
+package importers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
+	"github.com/code-sleuth/ike-go/internal/manager/models"
+	"github.com/code-sleuth/ike-go/pkg/dialect"
+	"github.com/code-sleuth/ike-go/pkg/util"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+var (
+	ErrNotDumpFile        = errors.New("not a file:// URL of a .jsonl, .ndjson, or .csv dump")
+	ErrInvalidDumpSource  = errors.New("invalid dump source")
+	ErrEmptyDump          = errors.New("dump has no records")
+	ErrMissingDumpField   = errors.New("record is missing a field")
+	ErrInvalidPublishedAt = errors.New("unrecognized published_at date")
+)
+
+// DumpFormat is the file format of a dump.
+type DumpFormat string
+
+const (
+	// DumpJSONL is one JSON object per line.
+	DumpJSONL DumpFormat = "jsonl"
+	// DumpCSV is comma-separated values with a header row naming the columns.
+	DumpCSV DumpFormat = "csv"
+)
+
+// publishedAtLayouts are the date formats published_at values are parsed with, in order.
+var publishedAtLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// DumpFields names the JSON fields or CSV columns a dump's records are read from. Title and
+// PublishedAt may be empty when the dump has no such field.
+type DumpFields struct {
+	URL         string
+	Title       string
+	Body        string
+	PublishedAt string
+}
+
+// DefaultDumpFields returns the field names used unless the source URL renames them.
+func DefaultDumpFields() DumpFields {
+	return DumpFields{URL: "url", Title: "title", Body: "body", PublishedAt: "published_at"}
+}
+
+// DumpSource is a dump file and how its records are read.
+type DumpSource struct {
+	Path   string
+	Format DumpFormat
+	Fields DumpFields
+}
+
+// ParseDumpSource parses a dump's source URL, such as
+// file:///data/posts.csv?body=content&published_at=date. The format follows the file extension
+// unless a format parameter gives it; url, title, body, and published_at parameters rename the
+// fields records are read from, and an empty title or published_at leaves that field out.
+func ParseDumpSource(sourceURL string) (*DumpSource, error) {
+	parsed, err := url.Parse(sourceURL)
+	if err != nil {
+		return nil, err
+	}
+	if parsed.Scheme != "file" || parsed.Path == "" || (parsed.Host != "" && parsed.Host != "localhost") {
+		return nil, fmt.Errorf("%w: %s", ErrNotDumpFile, sourceURL)
+	}
+
+	source := &DumpSource{Path: parsed.Path, Fields: DefaultDumpFields()}
+	query := parsed.Query()
+	for key := range query {
+		value := query.Get(key)
+		switch key {
+		case "format":
+			source.Format = DumpFormat(strings.ToLower(value))
+		case "url":
+			source.Fields.URL = value
+		case "title":
+			source.Fields.Title = value
+		case "body":
+			source.Fields.Body = value
+		case "published_at":
+			source.Fields.PublishedAt = value
+		default:
+			return nil, fmt.Errorf("%w: unknown parameter %q", ErrInvalidDumpSource, key)
+		}
+	}
+	if source.Fields.URL == "" || source.Fields.Body == "" {
+		return nil, fmt.Errorf("%w: the url and body fields cannot be empty", ErrInvalidDumpSource)
+	}
+
+	if source.Format == "" {
+		switch strings.ToLower(filepath.Ext(source.Path)) {
+		case ".jsonl", ".ndjson":
+			source.Format = DumpJSONL
+		case ".csv":
+			source.Format = DumpCSV
+		default:
+			return nil, fmt.Errorf("%w: %s", ErrNotDumpFile, sourceURL)
+		}
+	}
+	if source.Format != DumpJSONL && source.Format != DumpCSV {
+		return nil, fmt.Errorf("%w: unsupported format %q", ErrInvalidDumpSource, source.Format)
+	}
+
+	return source, nil
+}
+
+// dumpItem is a record read from a dump, or the reason it could not be read.
+type dumpItem struct {
+	line   int
+	url    string
+	record models.DumpRecord
+	err    error
+}
+
+// key identifies the item in reports and checkpoints: its URL, or its line without one.
+func (i dumpItem) key() string {
+	if i.url != "" {
+		return i.url
+	}
+	return fmt.Sprintf("line %d", i.line)
+}
+
+// DumpImporter imports content other tools already extracted, from JSONL or CSV dumps. Each
+// record becomes a source for its URL with the record as its download, which DumpTransformer
+// turns into a document, so a corpus can be migrated without fetching its pages again.
+type DumpImporter struct {
+	logger zerolog.Logger
+}
+
+// NewDumpImporter creates a new dump importer.
+func NewDumpImporter() *DumpImporter {
+	return &DumpImporter{
+		logger: util.ComponentLogger("importers.dump", zerolog.ErrorLevel),
+	}
+}
+
+// GetSourceType returns the source type this importer handles.
+func (d *DumpImporter) GetSourceType() string {
+	return models.DumpSourceFormat
+}
+
+// ValidateSource checks that sourceURL names a dump file.
+func (d *DumpImporter) ValidateSource(sourceURL string) error {
+	_, err := ParseDumpSource(sourceURL)
+	return err
+}
+
+// Import reads the dump's records and stores the ones not already imported. Records that cannot
+// be read, such as ones without a URL, are reported as failed without stopping the import.
+func (d *DumpImporter) Import(ctx context.Context, sourceURL string, db *sql.DB) (*interfaces.ImportResult, error) {
+	source, err := ParseDumpSource(sourceURL)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(source.Path)
+	if err != nil {
+		d.logger.Error().Err(err).Str("path", source.Path).Msg("Failed to open dump")
+		return nil, err
+	}
+	defer file.Close()
+
+	var lastResult *interfaces.ImportResult
+	report := &interfaces.ImportReport{}
+	checkpoint := interfaces.CheckpointFromContext(ctx)
+	paths := interfaces.PathsFromContext(ctx)
+
+	err = readDump(file, source, func(item dumpItem) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if len(paths) > 0 && !slices.Contains(paths, item.url) {
+			return nil
+		}
+		key := item.key()
+		interfaces.ReportProgress(ctx, interfaces.ProgressEvent{Stage: interfaces.StageDiscovered, Item: key, Count: 1})
+
+		// Skip records an interrupted run already imported
+		if result, ok := checkpoint.Imported(key); ok {
+			lastResult = result
+			report.Add(interfaces.ItemReport{Key: key, Status: interfaces.ItemImported})
+			interfaces.ReportProgress(ctx, interfaces.ProgressEvent{
+				Stage: interfaces.StageImported, Item: key, Count: 1,
+			})
+			return nil
+		}
+
+		var result *interfaces.ImportResult
+		err := item.err
+		if err == nil {
+			result, err = d.importItem(ctx, item, db)
+		}
+		if err != nil {
+			report.Add(interfaces.ItemReport{
+				Key: key, Status: interfaces.ItemFailed, Err: fmt.Errorf("%s: %w", key, err),
+			})
+			d.logger.Error().Err(err).Str("item", key).Msg("Failed to import dump record")
+			interfaces.ReportProgress(ctx, interfaces.ProgressEvent{
+				Stage: interfaces.StageFailed, Item: key, Count: 1, Err: err,
+			})
+			return nil
+		}
+
+		lastResult = result
+		report.Add(interfaces.ItemReport{Key: key, Status: interfaces.ItemImported})
+		interfaces.ReportProgress(ctx, interfaces.ProgressEvent{
+			Stage: interfaces.StageImported, Item: key, Count: 1,
+		})
+		if err := checkpoint.RecordImport(ctx, key, result); err != nil {
+			d.logger.Warn().Err(err).Str("item", key).Msg("Failed to record import checkpoint")
+		}
+		return nil
+	})
+	if err != nil {
+		d.logger.Error().Err(err).Str("path", source.Path).Msg("Failed to read dump")
+		return nil, err
+	}
+
+	if lastResult == nil {
+		if report.Failed == 0 && len(paths) > 0 {
+			return nil, interfaces.ErrNoMatchingPaths
+		}
+		if report.Failed == 0 {
+			return nil, fmt.Errorf("%w: %s", ErrEmptyDump, source.Path)
+		}
+		return nil, fmt.Errorf("all imports failed: %w", report.Err())
+	}
+	lastResult.Report = report
+	if report.Failed > 0 {
+		d.logger.Warn().Err(report.Err()).Int("error_count", report.Failed).Msg("Dump import completed with errors")
+		lastResult.Error = fmt.Errorf("%w: %w", ErrImportCompleted, report.Err())
+	}
+
+	return lastResult, nil
+}
+
+// importItem stores a record's source and download together.
+func (d *DumpImporter) importItem(ctx context.Context, item dumpItem, db *sql.DB) (*interfaces.ImportResult, error) {
+	body, err := json.Marshal(item.record)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &interfaces.ImportResult{}
+	err = withTx(ctx, db, func(tx *sql.Tx) error {
+		var err error
+		result.SourceID, err = upsertSource(ctx, tx, item.url, models.DumpSourceFormat)
+		if err != nil {
+			return err
+		}
+		// Dumps carry no access control of their own, so records are labeled only at import
+		if err := recordACL(ctx, tx, result.SourceID, aclLabels(ctx, nil)); err != nil {
+			return err
+		}
+		result.DownloadID, err = d.createDownload(ctx, result.SourceID, string(body), tx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// createDownload creates a download record holding a record's JSON.
+func (d *DumpImporter) createDownload(ctx context.Context, sourceID, body string, db dbExecutor) (string, error) {
+	downloadID := uuid.New().String()
+	now := dialect.SQLite.FormatTime(time.Now())
+
+	headersJSON, err := json.Marshal(http.Header{"Content-Type": {"application/json"}})
+	if err != nil {
+		return "", err
+	}
+
+	hash, err := storeBody(ctx, db, body)
+	if err != nil {
+		return "", err
+	}
+	existingID, found, err := findDownloadID(ctx, db, sourceID, hash)
+	if err != nil {
+		return "", err
+	}
+	if found {
+		return existingID, nil
+	}
+
+	query := `INSERT INTO downloads (id, source_id, attempted_at, downloaded_at, status_code, headers, content_hash)
+			  VALUES (?, ?, ?, ?, ?, ?, ?)`
+
+	_, err = db.ExecContext(ctx, query, downloadID, sourceID, now, now, http.StatusOK, string(headersJSON), hash)
+	if err != nil {
+		return "", err
+	}
+
+	return downloadID, nil
+}
+
+// readDump calls fn with each record of the dump in r, in order. A record that cannot be read is
+// passed with its error set; an error from fn or a file that cannot be parsed at all stops reading.
+func readDump(r io.Reader, source *DumpSource, fn func(item dumpItem) error) error {
+	if source.Format == DumpCSV {
+		return readCSVDump(r, source.Fields, fn)
+	}
+	return readJSONLDump(r, source.Fields, fn)
+}
+
+// readJSONLDump reads one JSON object per line, skipping blank lines. Values that are not
+// strings, such as numeric timestamps, are used as they are written.
+func readJSONLDump(r io.Reader, fields DumpFields, fn func(item dumpItem) error) error {
+	reader := bufio.NewReader(r)
+	for line := 1; ; line++ {
+		data, err := reader.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+		if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 {
+			var object map[string]json.RawMessage
+			item := dumpItem{line: line}
+			// Lines of exports from legacy databases may be in another charset than UTF-8
+			text, _ := util.ToUTF8(trimmed, "")
+			if item.err = json.Unmarshal([]byte(text), &object); item.err == nil {
+				item = newDumpItem(line, fields, func(name string) string {
+					return jsonFieldString(object[name])
+				})
+			}
+			if err := fn(item); err != nil {
+				return err
+			}
+		}
+		if err != nil {
+			return nil
+		}
+	}
+}
+
+// jsonFieldString returns a JSON string's value, or the raw text of any other value.
+func jsonFieldString(raw json.RawMessage) string {
+	var value string
+	if json.Unmarshal(raw, &value) == nil {
+		return value
+	}
+	if string(raw) == "null" {
+		return ""
+	}
+	return string(raw)
+}
+
+// readCSVDump reads a header row naming the columns, then a record per row.
+func readCSVDump(r io.Reader, fields DumpFields, fn func(item dumpItem) error) error {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))] = i
+	}
+	for _, name := range []string{fields.URL, fields.Body} {
+		if _, ok := columns[name]; !ok {
+			return fmt.Errorf("%w: no %q column", ErrInvalidDumpSource, name)
+		}
+	}
+
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		line, _ := reader.FieldPos(0)
+		item := newDumpItem(line, fields, func(name string) string {
+			if i, ok := columns[name]; ok && i < len(row) {
+				text, _ := util.ToUTF8([]byte(row[i]), "")
+				return text
+			}
+			return ""
+		})
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+}
+
+// newDumpItem builds the item for the record on line, reading its fields with value.
+func newDumpItem(line int, fields DumpFields, value func(name string) string) dumpItem {
+	item := dumpItem{line: line, url: strings.TrimSpace(value(fields.URL))}
+	item.record.Body = value(fields.Body)
+	if fields.Title != "" {
+		item.record.Title = strings.TrimSpace(value(fields.Title))
+	}
+
+	switch {
+	case item.url == "":
+		item.err = fmt.Errorf("%w: %s", ErrMissingDumpField, fields.URL)
+	case strings.TrimSpace(item.record.Body) == "":
+		item.err = fmt.Errorf("%w: %s", ErrMissingDumpField, fields.Body)
+	}
+	if fields.PublishedAt == "" || item.err != nil {
+		return item
+	}
+	if publishedAt := strings.TrimSpace(value(fields.PublishedAt)); publishedAt != "" {
+		var t time.Time
+		if t, item.err = parsePublishedAt(publishedAt); item.err == nil {
+			item.record.PublishedAt = &t
+		}
+	}
+	return item
+}
+
+// parsePublishedAt parses a date in one of publishedAtLayouts or as Unix seconds.
+func parsePublishedAt(value string) (time.Time, error) {
+	for _, layout := range publishedAtLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0).UTC(), nil
+	}
+	return time.Time{}, fmt.Errorf("%w: %q", ErrInvalidPublishedAt, value)
+}
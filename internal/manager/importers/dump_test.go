@@ -0,0 +1,146 @@
+package importers
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDumpImporter_ValidateSource(t *testing.T) {
+	importer := NewDumpImporter()
+	if importer.GetSourceType() != "dump" {
+		t.Errorf("Expected source type 'dump', got %s", importer.GetSourceType())
+	}
+
+	tests := []struct {
+		name        string
+		sourceURL   string
+		expectedErr error
+	}{
+		{name: "jsonl", sourceURL: "file:///data/export.jsonl"},
+		{name: "ndjson", sourceURL: "file:///data/export.ndjson"},
+		{name: "csv with renamed columns", sourceURL: "file:///data/posts.csv?body=content&title="},
+		{name: "format overrides extension", sourceURL: "file:///data/export.txt?format=csv"},
+		{name: "localhost", sourceURL: "file://localhost/data/export.jsonl"},
+		{name: "http URL", sourceURL: "https://example.com/export.jsonl", expectedErr: ErrNotDumpFile},
+		{name: "remote host", sourceURL: "file://server/data/export.jsonl", expectedErr: ErrNotDumpFile},
+		{name: "unknown extension", sourceURL: "file:///data/export.txt", expectedErr: ErrNotDumpFile},
+		{name: "unknown format", sourceURL: "file:///data/export.jsonl?format=xml", expectedErr: ErrInvalidDumpSource},
+		{name: "unknown parameter", sourceURL: "file:///data/export.jsonl?boddy=x", expectedErr: ErrInvalidDumpSource},
+		{name: "empty body field", sourceURL: "file:///data/export.jsonl?body=", expectedErr: ErrInvalidDumpSource},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := importer.ValidateSource(tt.sourceURL)
+			if tt.expectedErr == nil && err != nil {
+				t.Errorf("Expected %s to be valid, got %v", tt.sourceURL, err)
+			}
+			if tt.expectedErr != nil && !errors.Is(err, tt.expectedErr) {
+				t.Errorf("Expected %v for %s, got %v", tt.expectedErr, tt.sourceURL, err)
+			}
+		})
+	}
+}
+
+func TestParseDumpSource(t *testing.T) {
+	source, err := ParseDumpSource("file:///data/posts.csv?url=link&body=content&title=&published_at=date")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := DumpFields{URL: "link", Body: "content", PublishedAt: "date"}
+	if source.Path != "/data/posts.csv" || source.Format != DumpCSV || source.Fields != expected {
+		t.Errorf("Expected /data/posts.csv as CSV with fields %+v, got %+v", expected, source)
+	}
+}
+
+func readAll(t *testing.T, source *DumpSource, data string) []dumpItem {
+	t.Helper()
+	var items []dumpItem
+	err := readDump(strings.NewReader(data), source, func(item dumpItem) error {
+		items = append(items, item)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return items
+}
+
+func TestReadDump_JSONL(t *testing.T) {
+	source := &DumpSource{Format: DumpJSONL, Fields: DefaultDumpFields()}
+	data := `{"url": "https://example.com/a", "title": " A ", "body": "First", "published_at": "2024-03-01"}
+
+{"url": "https://example.com/b", "body": "Second", "published_at": 1700000000}
+not json
+{"title": "No URL", "body": "Third"}
+{"url": "https://example.com/d", "body": "Fourth", "published_at": "last week"}
+{"url": "https://example.com/e", "body": "Fifth", "published_at": null}`
+
+	items := readAll(t, source, data)
+	if len(items) != 6 {
+		t.Fatalf("Expected 6 records, got %d", len(items))
+	}
+
+	first := items[0]
+	if first.err != nil || first.url != "https://example.com/a" || first.record.Title != "A" ||
+		first.record.Body != "First" || !first.record.PublishedAt.Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected first record: %+v", first)
+	}
+	if items[1].record.PublishedAt == nil || items[1].record.PublishedAt.Unix() != 1700000000 {
+		t.Errorf("Expected Unix seconds to be parsed, got %v", items[1].record.PublishedAt)
+	}
+	if items[2].err == nil || items[2].line != 4 || items[2].key() != "line 4" {
+		t.Errorf("Expected line 4 to fail to parse, got %+v", items[2])
+	}
+	if !errors.Is(items[3].err, ErrMissingDumpField) {
+		t.Errorf("Expected a record without a URL to fail, got %v", items[3].err)
+	}
+	if !errors.Is(items[4].err, ErrInvalidPublishedAt) {
+		t.Errorf("Expected an unrecognized date to fail, got %v", items[4].err)
+	}
+	if items[5].err != nil || items[5].record.PublishedAt != nil {
+		t.Errorf("Expected a null date to be left unset, got %+v", items[5])
+	}
+}
+
+func TestReadDump_CSV(t *testing.T) {
+	source := &DumpSource{
+		Format: DumpCSV,
+		Fields: DumpFields{URL: "link", Title: "name", Body: "content", PublishedAt: "date"},
+	}
+	data := "\ufefflink,name,content,date\n" +
+		"https://example.com/a,A,\"First, with a comma\",2024-03-01 10:30:00\n" +
+		"https://example.com/b,B,,\n"
+
+	items := readAll(t, source, data)
+	if len(items) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(items))
+	}
+	if items[0].err != nil || items[0].record.Body != "First, with a comma" || items[0].line != 2 ||
+		!items[0].record.PublishedAt.Equal(time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected first record: %+v", items[0])
+	}
+	if !errors.Is(items[1].err, ErrMissingDumpField) {
+		t.Errorf("Expected a record without a body to fail, got %v", items[1].err)
+	}
+
+	err := readDump(strings.NewReader("url,text\n"), &DumpSource{Format: DumpCSV, Fields: DefaultDumpFields()},
+		func(dumpItem) error { return nil })
+	if !errors.Is(err, ErrInvalidDumpSource) {
+		t.Errorf("Expected a missing body column to fail, got %v", err)
+	}
+}
+
+func TestReadDump_Windows1252(t *testing.T) {
+	jsonl := readAll(t, &DumpSource{Format: DumpJSONL, Fields: DefaultDumpFields()},
+		"{\"url\": \"https://example.com/a\", \"body\": \"Caf\xe9 \x93menu\x94\"}\n")
+	csv := readAll(t, &DumpSource{Format: DumpCSV, Fields: DefaultDumpFields()},
+		"url,body\nhttps://example.com/a,Caf\xe9 \x93menu\x94\n")
+
+	for _, items := range [][]dumpItem{jsonl, csv} {
+		if len(items) != 1 || items[0].err != nil || items[0].record.Body != "Café “menu”" {
+			t.Errorf("Expected the record transcoded to UTF-8, got %+v", items)
+		}
+	}
+}
@@ -3,21 +3,24 @@ package importers
 import (
 	"context"
 	"database/sql"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
-	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
+	"github.com/code-sleuth/ike-go/pkg/dialect"
+	"github.com/code-sleuth/ike-go/pkg/httpclient"
 	"github.com/code-sleuth/ike-go/pkg/util"
 
 	"github.com/google/uuid"
 	"github.com/rs/zerolog"
+	"golang.org/x/sync/errgroup"
 )
 
 const (
@@ -35,6 +38,32 @@ const (
 	sourceTypeGitHub = "github"
 )
 
+// Reasons files of a repository are skipped, reported in ItemReport.SkipReason.
+const (
+	SkipTooLarge    = "larger than the maximum file size"
+	SkipExcluded    = "excluded path"
+	SkipUnsupported = "unsupported file type"
+	SkipSymlink     = "symbolic link"
+	SkipSubmodule   = "submodule"
+	SkipLFSPointer  = "Git LFS pointer"
+)
+
+// Git tree entry modes of entries that are not regular files.
+const (
+	modeSymlink   = "120000"
+	modeSubmodule = "160000"
+)
+
+// lfsPointerPrefix starts every Git LFS pointer file, which the LFS spec keeps under
+// maxLFSPointerSize bytes.
+const (
+	lfsPointerPrefix  = "version https://git-lfs.github.com/spec/v1\n"
+	maxLFSPointerSize = 1024
+)
+
+// errLFSPointer is returned by importFile for a file whose content is a Git LFS pointer.
+var errLFSPointer = errors.New("file is a Git LFS pointer")
+
 var (
 	ErrInvalidGitHubURL       = errors.New("invalid GitHub URL: missing owner or repository")
 	ErrImportCompleted        = errors.New("import completed with errors")
@@ -42,6 +71,9 @@ var (
 	ErrNotGitHubURL           = errors.New("not a GitHub URL")
 	ErrInvalidGitHubURLFormat = errors.New("invalid GitHub URL format")
 	ErrGitHubAPIRequestFailed = errors.New("GitHub API request failed")
+	// ErrUnsupportedContentEncoding is returned for file content GitHub sends in an encoding other
+	// than base64, such as "none" for files too large for the contents API.
+	ErrUnsupportedContentEncoding = errors.New("unsupported GitHub content encoding")
 )
 
 // GitHubImporter handles importing content from GitHub repositories.
@@ -52,6 +84,13 @@ type GitHubImporter struct {
 	exclusions    []string
 	maxFileSize   int64
 	supportedExts []string
+	rateLimit     *gitHubRateLimit
+	// maxRateLimitWait caps how long a request waits for the rate limit to reset
+	maxRateLimitWait time.Duration
+	// concurrency is how many files are fetched and stored at once
+	concurrency int
+	// commitAuthors records the author of each file's last commit as its source's author email
+	commitAuthors bool
 	logger        zerolog.Logger
 }
 
@@ -60,11 +99,20 @@ type GitHubRepoInfo struct {
 	Owner string
 	Repo  string
 	Ref   string // branch, tag, or commit SHA
+	// codeowners are the rules of the repository's CODEOWNERS file, read when it is imported
+	codeowners []codeownersRule
+	// labels are the access-control labels of the repository's content, by default its visibility
+	labels []string
+	// license is the SPDX identifier of the repository's license, recorded with each file
+	license string
 }
 
 // GitHubTreeResponse represents the response from GitHub's tree API.
 type GitHubTreeResponse struct {
+	SHA  string           `json:"sha"`
 	Tree []GitHubTreeItem `json:"tree"`
+	// Truncated is set when the tree has more entries than GitHub returns in one response
+	Truncated bool `json:"truncated"`
 }
 
 // GitHubTreeItem represents a single item in the repository tree.
@@ -92,29 +140,28 @@ type GitHubFileResponse struct {
 	Encoding    string `json:"encoding"`
 }
 
-// NewGitHubImporter creates a new GitHub repository importer.
-func NewGitHubImporter() *GitHubImporter {
-	return NewGitHubImporterWithClient(nil, "")
-}
-
-// NewGitHubImporterWithClient creates a new GitHub repository importer with custom HTTP client and API base URL.
-func NewGitHubImporterWithClient(client *http.Client, apiBaseURL string) *GitHubImporter {
-	logger := util.NewLogger(zerolog.ErrorLevel)
-	githubToken := os.Getenv("GITHUB_TOKEN")
+// NewGitHubImporter creates a new GitHub repository importer configured by opts. Without
+// WithToken, requests are unauthenticated.
+func NewGitHubImporter(opts ...Option) *GitHubImporter {
+	o := newOptions(opts)
+	logger := util.ComponentLogger("importers.github", zerolog.ErrorLevel)
+	if o.logger != nil {
+		logger = *o.logger
+	}
 
+	client := o.client
 	if client == nil {
-		client = &http.Client{
-			Timeout: defaultHTTPTimeout * time.Second,
-		}
+		client = httpclient.New(defaultHTTPTimeout * time.Second)
 	}
 
+	apiBaseURL := o.baseURL
 	if apiBaseURL == "" {
 		apiBaseURL = "https://api.github.com"
 	}
 
-	return &GitHubImporter{
-		client:      client,
-		token:       githubToken,
+	g := &GitHubImporter{
+		client:      o.configureClient(withRetries(client, DefaultRetryPolicy())),
+		token:       o.token,
 		apiBaseURL:  apiBaseURL,
 		maxFileSize: defaultMaxFileSize,
 		supportedExts: []string{
@@ -154,8 +201,28 @@ func NewGitHubImporterWithClient(client *http.Client, apiBaseURL string) *GitHub
 			".coverage",
 			".DS_Store",
 		},
-		logger: logger,
+		rateLimit:        newGitHubRateLimit(),
+		maxRateLimitWait: DefaultMaxRateLimitWait,
+		concurrency:      defaultConcurrency,
+		commitAuthors:    o.commitAuthors,
+		logger:           logger,
+	}
+	if o.exclusions != nil {
+		g.exclusions = o.exclusions
+	}
+	if o.supportedExts != nil {
+		g.supportedExts = o.supportedExts
+	}
+	if o.maxFileSize > 0 {
+		g.maxFileSize = o.maxFileSize
+	}
+	if o.maxRateLimitWait != nil {
+		g.maxRateLimitWait = *o.maxRateLimitWait
 	}
+	if o.concurrency > 0 {
+		g.concurrency = o.concurrency
+	}
+	return g
 }
 
 // GetSourceType returns the source type this importer handles.
@@ -202,47 +269,125 @@ func (g *GitHubImporter) Import(ctx context.Context, sourceURL string, db *sql.D
 	}
 
 	// Filter files based on exclusions and supported extensions
-	filteredFiles := g.filterFiles(tree.Tree)
+	files := tree.Tree
+	paths := interfaces.PathsFromContext(ctx)
+	if len(paths) > 0 {
+		files = onlyPaths(files, paths)
+	}
+	filteredFiles := g.filterFiles(files)
+	if len(filteredFiles) == 0 && len(paths) > 0 {
+		return nil, interfaces.ErrNoMatchingPaths
+	}
+
+	// Content is labeled with the repository's visibility, and the files imported before follow it
+	// even when they are unchanged
+	repo := g.readRepo(ctx, repoInfo)
+	repoInfo.license = repo.license()
+	repoInfo.labels = interfaces.ACLLabelsFromContext(ctx)
+	if len(repoInfo.labels) == 0 {
+		repoInfo.labels = []string{repo.visibility()}
+	}
+	if err := relabelRepository(ctx, db, repoInfo, repoInfo.labels); err != nil {
+		g.logger.Warn().Err(err).Msg("Failed to label repository content")
+		return nil, fmt.Errorf("failed to label repository content: %w", err)
+	}
+
+	// Only an import of the whole repository has a revision, and the next import compares with it
+	var revision string
+	if len(paths) == 0 {
+		revision = g.treeRevision(tree)
+	}
+	var unchanged []GitHubTreeItem
+	if last := interfaces.LastRevisionFromContext(ctx); revision != "" && last != "" {
+		if last == revision {
+			g.logger.Info().Str("revision", revision).Msg("Repository unchanged since the last import")
+			return &interfaces.ImportResult{Revision: revision}, interfaces.ErrNoChanges
+		}
+		// Import only the files added or modified since the last import, or all of them when the
+		// stored versions can't be read
+		changed, skipped, err := g.changedFiles(ctx, repoInfo, filteredFiles, db)
+		if err != nil {
+			g.logger.Warn().Err(err).Msg("Failed to read stored file versions, importing every file")
+		} else {
+			filteredFiles, unchanged = changed, skipped
+		}
+		if len(filteredFiles) == 0 {
+			return &interfaces.ImportResult{Revision: revision}, interfaces.ErrNoChanges
+		}
+	}
+
+	// Files are owned by whoever the repository's CODEOWNERS names for them
+	repoInfo.codeowners = g.readCodeowners(ctx, repoInfo, tree.Tree)
+
+	report := &interfaces.ImportReport{}
+	for _, file := range files {
+		// Directories are walked rather than imported, so they are not items of the import
+		if reason := g.skipReason(file); reason != "" && file.Type != "tree" {
+			report.Add(interfaces.ItemReport{Key: file.Path, Status: interfaces.ItemSkipped, SkipReason: reason})
+		}
+	}
+	for _, file := range unchanged {
+		report.Add(interfaces.ItemReport{Key: file.Path, Status: interfaces.ItemSkipped, SkipReason: SkipUnchanged})
+	}
 
 	g.logger.Info().Int("file_count", len(filteredFiles)).Msg("Found files to import after filtering")
+	interfaces.ReportProgress(ctx, interfaces.ProgressEvent{
+		Stage: interfaces.StageDiscovered,
+		Item:  sourceURL,
+		Count: len(filteredFiles),
+	})
+
+	// Process files concurrently. Outcomes are kept in tree order, so the report and the returned
+	// result don't depend on which file finished first.
+	outcomes := make([]fileOutcome, len(filteredFiles))
+	checkpoint := interfaces.CheckpointFromContext(ctx)
+
+	var group errgroup.Group
+	group.SetLimit(max(g.concurrency, 1))
+	for i, file := range filteredFiles {
+		group.Go(func() error {
+			outcomes[i] = g.importTreeFile(ctx, repoInfo, file, db, checkpoint)
+			return nil
+		})
+	}
+	_ = group.Wait()
 
-	// Process files
 	var lastResult *interfaces.ImportResult
-	var errorsList []error
+	for _, outcome := range outcomes {
+		report.Add(outcome.item)
+		if outcome.result != nil {
+			lastResult = outcome.result
+		}
+	}
 
-	for _, file := range filteredFiles {
-		result, err := g.importFile(ctx, repoInfo, file, db)
-		if err != nil {
-			errorsList = append(errorsList, err)
-			g.logger.Error().Err(err).Str("file_path", file.Path).Msg("Failed to import file")
-		} else {
-			lastResult = result
+	if lastResult == nil {
+		if report.Failed == 0 {
+			return nil, ErrNoFilesImported
 		}
+		// Every file failed or was skipped; the joined errors say why
+		return nil, fmt.Errorf("%w: %w", ErrNoFilesImported, report.Err())
 	}
+	lastResult.Report = report
 
-	if len(errorsList) > 0 {
+	// An import with failures has no revision, so the next one walks the whole repository again
+	if report.Failed > 0 {
 		g.logger.Warn().
-			Int("error_count", len(errorsList)).
+			Err(report.Err()).
+			Int("error_count", report.Failed).
 			Int("total_files", len(filteredFiles)).
-			Msg("GitHub import completed with errorsList")
-		if lastResult != nil {
-			g.logger.Warn().Err(errorsList[0]).Msg("Last error")
-			lastResult.Error = ErrImportCompleted
-		} else {
-			g.logger.Warn().Err(errorsList[0]).Msg("Last error")
-			return nil, err
-		}
+			Msg("GitHub import completed with errors")
+		lastResult.Error = fmt.Errorf("%w: %w", ErrImportCompleted, report.Err())
+		return lastResult, nil
 	}
 
 	g.logger.Info().
-		Int("successful_files", len(filteredFiles)-len(errorsList)).
+		Int("successful_files", report.Imported).
+		Int("skipped_files", report.Skipped).
+		Interface("skip_reasons", report.SkipCounts()).
 		Msg("GitHub import completed successfully")
 
-	if lastResult != nil {
-		return lastResult, nil
-	}
-
-	return nil, ErrNoFilesImported
+	lastResult.Revision = revision
+	return lastResult, nil
 }
 
 // parseGitHubURL parses a GitHub URL and extracts repository information.
@@ -280,6 +425,22 @@ func (g *GitHubImporter) parseGitHubURL(sourceURL string) (*GitHubRepoInfo, erro
 	return repoInfo, nil
 }
 
+// tokenFor returns the token requests made with ctx authenticate with: the GitHub token of the
+// credentials ctx carries, else the importer's own.
+func (g *GitHubImporter) tokenFor(ctx context.Context) string {
+	if token := interfaces.CredentialsFromContext(ctx).GitHubToken; token != "" {
+		return token
+	}
+	return g.token
+}
+
+// authorize adds the token for req's context to req, when there is one.
+func (g *GitHubImporter) authorize(req *http.Request) {
+	if token := g.tokenFor(req.Context()); token != "" {
+		req.Header.Set("Authorization", "token "+token)
+	}
+}
+
 // getRepoTree fetches the repository tree from GitHub API.
 func (g *GitHubImporter) getRepoTree(ctx context.Context, repoInfo *GitHubRepoInfo) (*GitHubTreeResponse, error) {
 	url := fmt.Sprintf("%s/repos/%s/%s/git/trees/%s?recursive=1",
@@ -292,13 +453,10 @@ func (g *GitHubImporter) getRepoTree(ctx context.Context, repoInfo *GitHubRepoIn
 	}
 
 	// Add authentication if token is available
-	if g.token != "" {
-		// g.logger.Info().Str("token", g.token).Msg("Adding authentication")
-		req.Header.Set("Authorization", fmt.Sprintf("token %s", g.token))
-	}
+	g.authorize(req)
 	req.Header.Set("Accept", "application/vnd.github.v3+json")
 
-	resp, err := g.client.Do(req)
+	resp, err := g.do(req)
 	if err != nil {
 		g.logger.Error().Err(err).Msg("Request failed")
 		return nil, fmt.Errorf("request failed: %w", err)
@@ -307,7 +465,7 @@ func (g *GitHubImporter) getRepoTree(ctx context.Context, repoInfo *GitHubRepoIn
 
 	if resp.StatusCode != http.StatusOK {
 		g.logger.Error().Int("status_code", resp.StatusCode).Msg("GitHub API request failed")
-		return nil, ErrGitHubAPIRequestFailed
+		return nil, fmt.Errorf("%w: %w", ErrGitHubAPIRequestFailed, &StatusError{StatusCode: resp.StatusCode, URL: url})
 	}
 
 	var tree GitHubTreeResponse
@@ -324,23 +482,12 @@ func (g *GitHubImporter) filterFiles(items []GitHubTreeItem) []GitHubTreeItem {
 	filtered := make([]GitHubTreeItem, 0, len(items))
 
 	for _, item := range items {
-		// Skip if not a file (blob)
+		// Skip if not a file (blob); submodules are commit entries
 		if item.Type != "blob" {
 			continue
 		}
 
-		// Skip if file is too large
-		if item.Size > g.maxFileSize {
-			continue
-		}
-
-		// Check exclusions
-		if g.isExcluded(item.Path) {
-			continue
-		}
-
-		// Check supported extensions
-		if !g.isSupportedFile(item.Path) {
+		if g.skipReason(item) != "" {
 			continue
 		}
 
@@ -350,6 +497,40 @@ func (g *GitHubImporter) filterFiles(items []GitHubTreeItem) []GitHubTreeItem {
 	return filtered
 }
 
+// skipReason returns why a file is not imported, or "" if it is. Symbolic links are skipped
+// since their target, when it is in the repository, is imported on its own.
+func (g *GitHubImporter) skipReason(item GitHubTreeItem) string {
+	switch {
+	case item.Mode == modeSymlink:
+		return SkipSymlink
+	case item.Mode == modeSubmodule || item.Type == "commit":
+		return SkipSubmodule
+	case item.Size > g.maxFileSize:
+		return SkipTooLarge
+	case g.isExcluded(item.Path):
+		return SkipExcluded
+	case !g.isSupportedFile(item.Path):
+		return SkipUnsupported
+	}
+	return ""
+}
+
+// onlyPaths keeps the items whose path is in paths.
+func onlyPaths(items []GitHubTreeItem, paths []string) []GitHubTreeItem {
+	wanted := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		wanted[path] = true
+	}
+
+	kept := items[:0]
+	for _, item := range items {
+		if wanted[item.Path] {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
+
 // isExcluded checks if a file path should be excluded.
 func (g *GitHubImporter) isExcluded(path string) bool {
 	for _, exclusion := range g.exclusions {
@@ -371,7 +552,60 @@ func (g *GitHubImporter) isSupportedFile(path string) bool {
 	return false
 }
 
-// importFile imports a single file from the repository.
+// fileOutcome is what importing one file of the tree came to: its report item, and its result
+// if it was imported.
+type fileOutcome struct {
+	result *interfaces.ImportResult
+	item   interfaces.ItemReport
+}
+
+// importTreeFile imports file unless the checkpoint says an interrupted run already did, reporting
+// progress and recording the import in the checkpoint. It is safe to call concurrently.
+func (g *GitHubImporter) importTreeFile(
+	ctx context.Context,
+	repoInfo *GitHubRepoInfo,
+	file GitHubTreeItem,
+	db *sql.DB,
+	checkpoint interfaces.Checkpoint,
+) fileOutcome {
+	// Skip files an interrupted run already imported
+	if result, ok := checkpoint.Imported(file.Path); ok {
+		interfaces.ReportProgress(ctx, interfaces.ProgressEvent{
+			Stage: interfaces.StageImported, Item: file.Path, Count: 1,
+		})
+		return fileOutcome{result: result, item: interfaces.ItemReport{Key: file.Path, Status: interfaces.ItemImported}}
+	}
+
+	result, err := g.importFile(ctx, repoInfo, file, db)
+	if errors.Is(err, errLFSPointer) {
+		// The file's content is in LFS storage, and the pointer to it is not worth embedding
+		return fileOutcome{item: interfaces.ItemReport{
+			Key: file.Path, Status: interfaces.ItemSkipped, SkipReason: SkipLFSPointer,
+		}}
+	}
+	if err != nil {
+		g.logger.Error().Err(err).Str("file_path", file.Path).Msg("Failed to import file")
+		interfaces.ReportProgress(ctx, interfaces.ProgressEvent{
+			Stage: interfaces.StageFailed, Item: file.Path, Count: 1, Err: err,
+		})
+		return fileOutcome{item: interfaces.ItemReport{
+			Key: file.Path, Status: interfaces.ItemFailed, Err: fmt.Errorf("%s: %w", file.Path, err),
+		}}
+	}
+
+	interfaces.ReportProgress(ctx, interfaces.ProgressEvent{
+		Stage: interfaces.StageImported, Item: file.Path, Count: 1,
+	})
+	if err := checkpoint.RecordImport(ctx, file.Path, result); err != nil {
+		g.logger.Warn().Err(err).Str("file_path", file.Path).Msg("Failed to record import checkpoint")
+	}
+	return fileOutcome{result: result, item: interfaces.ItemReport{Key: file.Path, Status: interfaces.ItemImported}}
+}
+
+// importFile imports a single file from the repository. A file whose blob SHA matches the last
+// import's, or that of any file already stored, is not fetched again, and otherwise the request
+// carries the last import's ETag, so unchanged files cost neither bandwidth nor rate-limit budget.
+// Their download reuses the stored body.
 func (g *GitHubImporter) importFile(
 	ctx context.Context,
 	repoInfo *GitHubRepoInfo,
@@ -379,27 +613,71 @@ func (g *GitHubImporter) importFile(
 	db *sql.DB,
 ) (*interfaces.ImportResult, error) {
 	// Build URL for the file
-	fileURL := fmt.Sprintf("https://github.com/%s/%s/blob/%s/%s",
-		repoInfo.Owner, repoInfo.Repo, repoInfo.Ref, file.Path)
-
-	// Get file content
-	content, err := g.getFileContent(ctx, repoInfo, file.Path)
-	if err != nil {
-		g.logger.Error().Err(err).Str("file_path", file.Path).Msg("Failed to get file content")
-		return nil, err
+	fileURL := gitHubFileURL(repoInfo, file.Path)
+
+	previous, found := g.previousVersion(ctx, fileURL, db)
+	var version fileVersion
+	if found && file.SHA != "" && previous.sha == file.SHA {
+		version = previous
+	} else if cached, ok := g.cachedBlob(ctx, file.SHA, db); ok {
+		version = cached
+	} else {
+		// Get file content
+		fetched, err := g.getFileContent(ctx, repoInfo, file.Path, previous.etag)
+		switch {
+		case errors.Is(err, errNotModified):
+			version = previous
+		case err != nil:
+			g.logger.Error().Err(err).Str("file_path", file.Path).Msg("Failed to get file content")
+			return nil, err
+		default:
+			if isLFSPointer(fetched.content) {
+				return nil, errLFSPointer
+			}
+			version = fetched
+		}
 	}
+	version.sha = file.SHA
+	version.license = repoInfo.license
 
-	// Create source record
-	sourceID, err := g.createSource(ctx, fileURL, repoInfo, file, db)
-	if err != nil {
-		g.logger.Error().Err(err).Str("file_path", file.Path).Msg("Failed to create source")
-		return nil, err
+	// The author of a file changes only with its content
+	var authorEmail string
+	if g.commitAuthors && (!found || file.SHA == "" || previous.sha != file.SHA) {
+		email, err := g.commitAuthor(ctx, repoInfo, file.Path)
+		if err != nil {
+			g.logger.Warn().Err(err).Str("file_path", file.Path).Msg("Failed to get commit author")
+		}
+		authorEmail = email
 	}
 
-	// Create download record
-	downloadID, err := g.createDownload(ctx, sourceID, content, file, db)
+	// Create source and download records atomically
+	var sourceID, downloadID string
+	err := withTx(ctx, db, func(tx *sql.Tx) error {
+		var err error
+		sourceID, err = g.createSource(ctx, fileURL, repoInfo, file, tx)
+		if err != nil {
+			g.logger.Error().Err(err).Str("file_path", file.Path).Msg("Failed to create source")
+			return err
+		}
+
+		err = recordOwnership(ctx, tx, sourceID, authorEmail, codeownersOf(repoInfo.codeowners, file.Path))
+		if err != nil {
+			g.logger.Error().Err(err).Str("file_path", file.Path).Msg("Failed to record owners")
+			return err
+		}
+		if err := recordACL(ctx, tx, sourceID, repoInfo.labels); err != nil {
+			g.logger.Error().Err(err).Str("file_path", file.Path).Msg("Failed to record access labels")
+			return err
+		}
+
+		downloadID, err = g.createFileDownload(ctx, sourceID, version, file, tx)
+		if err != nil {
+			g.logger.Error().Err(err).Str("file_path", file.Path).Msg("Failed to create download")
+			return err
+		}
+		return nil
+	})
 	if err != nil {
-		g.logger.Error().Err(err).Str("file_path", file.Path).Msg("Failed to create download")
 		return nil, err
 	}
 
@@ -409,72 +687,96 @@ func (g *GitHubImporter) importFile(
 	}, nil
 }
 
-// getFileContent fetches the content of a file from GitHub.
-func (g *GitHubImporter) getFileContent(ctx context.Context, repoInfo *GitHubRepoInfo, path string) (string, error) {
+// getFileContent fetches a file from GitHub and returns its content, decoded to UTF-8 text, with
+// its ETag and the encoding GitHub sent it in. With etag set the request is conditional, and an
+// unchanged file returns errNotModified.
+func (g *GitHubImporter) getFileContent(
+	ctx context.Context,
+	repoInfo *GitHubRepoInfo,
+	path string,
+	etag string,
+) (fileVersion, error) {
 	url := fmt.Sprintf("%s/repos/%s/%s/contents/%s?ref=%s",
 		g.apiBaseURL, repoInfo.Owner, repoInfo.Repo, path, repoInfo.Ref)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		g.logger.Error().Err(err).Str("file_path", path).Msg("Failed to create request")
-		return "", err
+		return fileVersion{}, err
 	}
 
 	// Add authentication if token is available
-	if g.token != "" {
-		// g.logger.Info().Str("token", g.token).Msg("Adding authentication")
-		req.Header.Set("Authorization", fmt.Sprintf("token %s", g.token))
-	}
+	g.authorize(req)
 	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
 
-	resp, err := g.client.Do(req)
+	resp, err := g.do(req)
 	if err != nil {
 		g.logger.Error().Err(err).Str("file_path", path).Msg("Request failed")
-		return "", err
+		return fileVersion{}, err
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotModified && etag != "" {
+		return fileVersion{}, errNotModified
+	}
 	if resp.StatusCode != http.StatusOK {
 		g.logger.Error().Int("status_code", resp.StatusCode).Str("file_path", path).Msg("GitHub API request failed")
-		return "", err
+		return fileVersion{}, fmt.Errorf("%w: %w", ErrGitHubAPIRequestFailed,
+			&StatusError{StatusCode: resp.StatusCode, URL: url})
 	}
 
 	var file GitHubFileResponse
 	if err := json.NewDecoder(resp.Body).Decode(&file); err != nil {
 		g.logger.Error().Err(err).Str("file_path", path).Msg("Failed to decode response")
-		return "", err
+		return fileVersion{}, err
 	}
 
-	// GitHub returns base64-encoded content
-	if file.Encoding == "base64" {
-		// For simplicity, I'm storing the raw content as is.
-		// might want to decode it for production use
-		return file.Content, nil
+	content, err := decodeContent(file.Content, file.Encoding)
+	if err != nil {
+		g.logger.Error().Err(err).Str("file_path", path).Msg("Failed to decode file content")
+		return fileVersion{}, err
 	}
 
-	return file.Content, nil
+	return fileVersion{content: content, etag: resp.Header.Get("ETag"), encoding: file.Encoding}, nil
 }
 
-// createSource creates a source record in the database.
+// isLFSPointer reports whether content is a Git LFS pointer rather than the file it stands for.
+func isLFSPointer(content string) bool {
+	return len(content) < maxLFSPointerSize && strings.HasPrefix(content, lfsPointerPrefix)
+}
+
+// decodeContent decodes content from the contents API's encoding. GitHub sends base64 wrapped
+// in lines, which are joined first. Files in other charsets, such as Windows-1252 pages of older
+// sites, are transcoded, so stored bodies are always UTF-8.
+func decodeContent(content, encoding string) (string, error) {
+	switch encoding {
+	case "base64":
+		decoded, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(content), ""))
+		if err != nil {
+			return "", fmt.Errorf("invalid base64 content: %w", err)
+		}
+		content = string(decoded)
+	case "", "utf-8":
+	default:
+		// Files over 1MB come back with encoding "none" and no content
+		return "", fmt.Errorf("%w: %q", ErrUnsupportedContentEncoding, encoding)
+	}
+	text, _ := util.ToUTF8([]byte(content), "")
+	return text, nil
+}
+
+// createSource creates a source record in the database, reusing an existing source for the same URL.
+// New sources are placed in the collection carried by ctx.
 func (g *GitHubImporter) createSource(
 	ctx context.Context,
 	fileURL string,
 	_ *GitHubRepoInfo,
 	file GitHubTreeItem,
-	db *sql.DB,
+	db dbExecutor,
 ) (string, error) {
-	parsedURL, err := url.Parse(fileURL)
-	if err != nil {
-		g.logger.Error().Err(err).Str("file_path", file.Path).Msg("Failed to parse URL")
-		return "", err
-	}
-
-	sourceID := uuid.New().String()
-	now := time.Now().Format(time.RFC3339)
-
-	query := `INSERT INTO sources (id, raw_url, scheme, host, path, query, active_domain, format, created_at, updated_at)
-			  VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
-
 	// Determine format based on file extension
 	ext := filepath.Ext(file.Path)
 	format := formatJSON // default to json for unsupported types
@@ -483,31 +785,52 @@ func (g *GitHubImporter) createSource(
 		format = "yaml"
 	}
 
-	_, err = db.ExecContext(ctx, query, sourceID, fileURL, parsedURL.Scheme, parsedURL.Host,
-		parsedURL.Path, parsedURL.RawQuery, 1, format, now, now)
+	sourceID, err := upsertSource(ctx, db, fileURL, format)
 	if err != nil {
-		g.logger.Error().Err(err).Str("file_path", file.Path).Msg("Failed to insert source")
+		g.logger.Error().Err(err).Str("file_path", file.Path).Msg("Failed to upsert source")
 		return "", err
 	}
 
 	return sourceID, nil
 }
 
-// createDownload creates a download record in the database.
+// createDownload creates a download record in the database for file's content. Its body is stored
+// once per distinct content.
 func (g *GitHubImporter) createDownload(
 	ctx context.Context,
 	sourceID string,
 	content string,
 	file GitHubTreeItem,
-	db *sql.DB,
+	db dbExecutor,
+) (string, error) {
+	return g.createFileDownload(ctx, sourceID, fileVersion{sha: file.SHA, content: content}, file, db)
+}
+
+// createFileDownload creates a download record in the database for a version of file. A version
+// the last import already stored reuses its body and is recorded as 304 Not Modified.
+func (g *GitHubImporter) createFileDownload(
+	ctx context.Context,
+	sourceID string,
+	version fileVersion,
+	file GitHubTreeItem,
+	db dbExecutor,
 ) (string, error) {
 	downloadID := uuid.New().String()
-	now := time.Now().Format(time.RFC3339)
+	now := dialect.SQLite.FormatTime(time.Now())
 
 	// Create a simple headers structure
 	headers := map[string][]string{
-		"Content-Type": {"text/plain"},
-		"X-GitHub-SHA": {file.SHA},
+		"Content-Type":  {"text/plain"},
+		gitHubSHAHeader: {version.sha},
+	}
+	if version.etag != "" {
+		headers[gitHubETagHeader] = []string{version.etag}
+	}
+	if version.encoding != "" {
+		headers[gitHubEncodingHeader] = []string{version.encoding}
+	}
+	if version.license != "" {
+		headers[gitHubLicenseHeader] = []string{version.license}
 	}
 
 	headersJSON, err := json.Marshal(headers)
@@ -516,35 +839,38 @@ func (g *GitHubImporter) createDownload(
 		return "", err
 	}
 
-	query := `INSERT INTO downloads (id, source_id, attempted_at, downloaded_at, status_code, headers, body)
+	statusCode, hash := http.StatusNotModified, version.contentHash
+	if hash == "" {
+		statusCode = httpOKStatus
+		hash, err = storeBody(ctx, db, version.content)
+		if err != nil {
+			g.logger.Error().Err(err).Str("file_path", file.Path).Msg("Failed to store body")
+			return "", err
+		}
+	}
+	existingID, found, err := findDownloadID(ctx, db, sourceID, hash)
+	if err != nil {
+		g.logger.Error().Err(err).Str("file_path", file.Path).Msg("Failed to look up download")
+		return "", err
+	}
+	if found {
+		return existingID, nil
+	}
+
+	query := `INSERT INTO downloads (id, source_id, attempted_at, downloaded_at, status_code, headers, content_hash)
 			  VALUES (?, ?, ?, ?, ?, ?, ?)`
 
-	_, err = db.ExecContext(ctx, query, downloadID, sourceID, now, now, httpOKStatus,
-		string(headersJSON), content)
+	_, err = db.ExecContext(ctx, query, downloadID, sourceID, now, now, statusCode,
+		string(headersJSON), hash)
 	if err != nil {
 		g.logger.Error().Err(err).Str("file_path", file.Path).Msg("Failed to insert download")
 		return "", err
 	}
 
-	return downloadID, nil
-}
-
-// SetExclusions sets the list of paths/patterns to exclude.
-func (g *GitHubImporter) SetExclusions(exclusions []string) {
-	g.exclusions = exclusions
-}
-
-// SetSupportedExtensions sets the list of supported file extensions.
-func (g *GitHubImporter) SetSupportedExtensions(extensions []string) {
-	g.supportedExts = extensions
-}
-
-// SetMaxFileSize sets the maximum file size to import.
-func (g *GitHubImporter) SetMaxFileSize(size int64) {
-	g.maxFileSize = size
-}
+	if err := cacheBlob(ctx, db, version.sha, hash, version.encoding); err != nil {
+		g.logger.Error().Err(err).Str("file_path", file.Path).Msg("Failed to cache blob")
+		return "", err
+	}
 
-// SetToken sets the GitHub API token.
-func (g *GitHubImporter) SetToken(token string) {
-	g.token = token
+	return downloadID, nil
 }
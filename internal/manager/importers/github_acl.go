@@ -0,0 +1,44 @@
+package importers
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/code-sleuth/ike-go/pkg/dialect"
+	"github.com/code-sleuth/ike-go/pkg/util"
+)
+
+// relabelRepository gives every source already imported from the repository, at any ref, the
+// access-control labels of this import, so a repository made private stops being searchable by
+// everyone even when none of its files changed.
+func relabelRepository(ctx context.Context, db *sql.DB, repoInfo *GitHubRepoInfo, labels []string) error {
+	// Without a database there are no sources imported before to relabel
+	if db == nil {
+		return nil
+	}
+	root, err := util.NormalizeURL(fmt.Sprintf("https://github.com/%s/%s", repoInfo.Owner, repoInfo.Repo))
+	if err != nil {
+		return err
+	}
+	prefix := root + "/"
+	const underPrefix = `deleted_at IS NULL AND SUBSTR(raw_url, 1, LENGTH(?)) = ?`
+
+	return withTx(ctx, db, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, `DELETE FROM source_acl
+			WHERE source_id IN (SELECT id FROM sources WHERE `+underPrefix+`)`, prefix, prefix)
+		if err != nil {
+			return err
+		}
+		now := dialect.SQLite.FormatTime(time.Now())
+		for _, label := range cleanLabels(labels) {
+			_, err := tx.ExecContext(ctx, `INSERT INTO source_acl (source_id, label, created_at)
+				SELECT id, ?, ? FROM sources WHERE `+underPrefix, label, now, prefix, prefix)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,28 @@
+package importers
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
+)
+
+func TestACLLabels(t *testing.T) {
+	derived := []string{"public"}
+	if got := aclLabels(context.Background(), derived); !slices.Equal(got, derived) {
+		t.Errorf("Expected the derived labels without labels in the context, got %v", got)
+	}
+
+	ctx := interfaces.WithACLLabels(context.Background(), []string{"support"})
+	if got := aclLabels(ctx, derived); !slices.Equal(got, []string{"support"}) {
+		t.Errorf("Expected the context's labels to replace the derived ones, got %v", got)
+	}
+}
+
+func TestCleanLabels(t *testing.T) {
+	got := cleanLabels([]string{" support ", "", "sre", "support", "  "})
+	if !slices.Equal(got, []string{"support", "sre"}) {
+		t.Errorf("Expected trimmed labels without empty or repeated ones, got %v", got)
+	}
+}
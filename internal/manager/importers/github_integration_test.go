@@ -5,10 +5,12 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
 	"github.com/code-sleuth/ike-go/internal/manager/testutil"
 )
 
@@ -35,7 +37,7 @@ func TestGitHubImporter_ImportFile_Integration(t *testing.T) {
 				GitURL:      "https://api.github.com/repos/code-sleuth/outh/git/blobs/0e02509a5729c071ca1f6f919ea397fd2653b62b",
 				DownloadURL: "https://raw.githubusercontent.com/code-sleuth/outh/main/README.md",
 				Type:        "file",
-				Content:     "IyBPVVRIIFNlcnZpY2UKCiMjIEVudmlyb25tZW50Ckl0cyBhIHByZXJlcXVpc2l0ZSB0aGF0IHRoZXNlIGVudmlyb25tZW50IHZhcmlhYmxlcyBhcmUgc2V0LiBTZXQgdGhlbSBpbiB5b3VyIHRlcm1pbmFsLgoKYGBgYmFzaAokIGV4cG9ydCBKV1RfU0VDUkVUPTx5b3VyLWp3dC1zZWNyZXQ+CiQgZXhwb3J0IERBVEFC\nQVNFX1VSTD08ZXhhbXBsZS1wb3N0Z3JlczovL3Bvc3RncmVzOm5vdFNvU2VjcmV0QHBvc3RncmVzOjU0MzI+CiQgZXhwb3J0IFBPU1RNQVJLX0FVVEhfVE9LRU49PHlvdXItcG9zdG1hcmstYXV0aC10b2tlbj4KYGBgCgoKIyMgU2V0dXAg\nJiBCdWlsZApgYGBzaGVsbAptYWtlIGJ1aWxkCmBgYAoKIyMgUnVuIHNlcnZpY2VzIGxvY2FsbHkKIyMjIyBBcHAgc2VydmljZQpgYGBzaGVsbAptYWtlIHJ1bi1hcHAtc2VydmljZQpgY", // Real base64 content from code-sleuth/outh README.md
+				Content:     "IyBPVVRIIFNlcnZpY2UKCiMjIEVudmlyb25tZW50Ckl0cyBhIHByZXJlcXVpc2l0ZSB0aGF0IHRoZXNlIGVudmlyb25tZW50IHZhcmlhYmxlcyBhcmUgc2V0LiBTZXQgdGhlbSBpbiB5b3VyIHRlcm1pbmFsLgoKYGBgYmFzaAokIGV4cG9ydCBKV1RfU0VDUkVUPTx5b3VyLWp3dC1zZWNyZXQ+CiQgZXhwb3J0IERBVEFC\nQVNFX1VSTD08ZXhhbXBsZS1wb3N0Z3JlczovL3Bvc3RncmVzOm5vdFNvU2VjcmV0QHBvc3RncmVzOjU0MzI+CiQgZXhwb3J0IFBPU1RNQVJLX0FVVEhfVE9LRU49PHlvdXItcG9zdG1hcmstYXV0aC10b2tlbj4KYGBgCgoKIyMgU2V0dXAg\nJiBCdWlsZApgYGBzaGVsbAptYWtlIGJ1aWxkCmBgYAoKIyMgUnVuIHNlcnZpY2VzIGxvY2FsbHkKIyMjIyBBcHAgc2VydmljZQpgYGBzaGVsbAptYWtlIHJ1bi1hcHAtc2VydmljZQpgYGA=", // Real base64 content from code-sleuth/outh README.md
 				Encoding:    "base64",
 			}
 			w.Header().Set("Content-Type", "application/json")
@@ -48,7 +50,7 @@ func TestGitHubImporter_ImportFile_Integration(t *testing.T) {
 	defer testServer.Close()
 
 	// Create importer with custom HTTP client and API base URL pointing to test server
-	importer := NewGitHubImporterWithClient(&http.Client{Timeout: 30 * time.Second}, testServer.URL)
+	importer := NewGitHubImporter(WithHTTPClient(&http.Client{Timeout: 30 * time.Second}), WithBaseURL(testServer.URL))
 
 	tests := []struct {
 		name        string
@@ -357,7 +359,7 @@ func TestGitHubImporter_CreateDownload_Integration(t *testing.T) {
 		{
 			name:     "create download with base64 content",
 			sourceID: sourceID,
-			content:  "IyBPVVRIIFNlcnZpY2UKCiMjIEVudmlyb25tZW50Ckl0cyBhIHByZXJlcXVpc2l0ZSB0aGF0IHRoZXNlIGVudmlyb25tZW50IHZhcmlhYmxlcyBhcmUgc2V0LiBTZXQgdGhlbSBpbiB5b3VyIHRlcm1pbmFsLgoKYGBgYmFzaAokIGV4cG9ydCBKV1RfU0VDUkVUPTx5b3VyLWp3dC1zZWNyZXQ+CiQgZXhwb3J0IERBVEFC\nQVNFX1VSTD08ZXhhbXBsZS1wb3N0Z3JlczovL3Bvc3RncmVzOm5vdFNvU2VjcmV0QHBvc3RncmVzOjU0MzI+CiQgZXhwb3J0IFBPU1RNQVJLX0FVVEhfVE9LRU49PHlvdXItcG9zdG1hcmstYXV0aC10b2tlbj4KYGBgCgoKIyMgU2V0dXAg\nJiBCdWlsZApgYGBzaGVsbAptYWtlIGJ1aWxkCmBgYAoKIyMgUnVuIHNlcnZpY2VzIGxvY2FsbHkKIyMjIyBBcHAgc2VydmljZQpgYGBzaGVsbAptYWtlIHJ1bi1hcHAtc2VydmljZQpgY",
+			content:  "IyBPVVRIIFNlcnZpY2UKCiMjIEVudmlyb25tZW50Ckl0cyBhIHByZXJlcXVpc2l0ZSB0aGF0IHRoZXNlIGVudmlyb25tZW50IHZhcmlhYmxlcyBhcmUgc2V0LiBTZXQgdGhlbSBpbiB5b3VyIHRlcm1pbmFsLgoKYGBgYmFzaAokIGV4cG9ydCBKV1RfU0VDUkVUPTx5b3VyLWp3dC1zZWNyZXQ+CiQgZXhwb3J0IERBVEFC\nQVNFX1VSTD08ZXhhbXBsZS1wb3N0Z3JlczovL3Bvc3RncmVzOm5vdFNvU2VjcmV0QHBvc3RncmVzOjU0MzI+CiQgZXhwb3J0IFBPU1RNQVJLX0FVVEhfVE9LRU49PHlvdXItcG9zdG1hcmstYXV0aC10b2tlbj4KYGBgCgoKIyMgU2V0dXAg\nJiBCdWlsZApgYGBzaGVsbAptYWtlIGJ1aWxkCmBgYAoKIyMgUnVuIHNlcnZpY2VzIGxvY2FsbHkKIyMjIyBBcHAgc2VydmljZQpgYGBzaGVsbAptYWtlIHJ1bi1hcHAtc2VydmljZQpgYGA=",
 			file: GitHubTreeItem{
 				Path: "README.md",
 				Mode: "100644",
@@ -411,7 +413,8 @@ func TestGitHubImporter_CreateDownload_Integration(t *testing.T) {
 				var sourceIDFromDB string
 				var statusCode int
 				var headers, body string
-				query := "SELECT source_id, status_code, headers, body FROM downloads WHERE id = ?"
+				query := `SELECT d.source_id, d.status_code, d.headers, b.body FROM downloads d
+					JOIN download_bodies b ON b.content_hash = d.content_hash WHERE d.id = ?`
 				err := db.QueryRow(query, downloadID).Scan(&sourceIDFromDB, &statusCode, &headers, &body)
 				if err != nil {
 					t.Errorf("Failed to query download data: %v", err)
@@ -521,7 +524,7 @@ func TestGitHubImporter_DatabaseErrorHandling(t *testing.T) {
 				Path:        "README.md",
 				SHA:         "0e02509a5729c071ca1f6f919ea397fd2653b62b",
 				Size:        787,
-				Content:     "IyBPVVRIIFNlcnZpY2UKCiMjIEVudmlyb25tZW50Ckl0cyBhIHByZXJlcXVpc2l0ZSB0aGF0IHRoZXNlIGVudmlyb25tZW50IHZhcmlhYmxlcyBhcmUgc2V0LiBTZXQgdGhlbSBpbiB5b3VyIHRlcm1pbmFsLgoKYGBgYmFzaAokIGV4cG9ydCBKV1RfU0VDUkVUPTx5b3VyLWp3dC1zZWNyZXQ+CiQgZXhwb3J0IERBVEFC\\nQVNFX1VSTD08ZXhhbXBsZS1wb3N0Z3JlczovL3Bvc3RncmVzOm5vdFNvU2VjcmV0QHBvc3RncmVzOjU0MzI+CiQgZXhwb3J0IFBPU1RNQVJLX0FVVEhfVE9LRU49PHlvdXItcG9zdG1hcmstYXV0aC10b2tlbj4KYGBgCgoKIyMgU2V0dXAg\\nJiBCdWlsZApgYGBzaGVsbAptYWtlIGJ1aWxkCmBgYAoKIyMgUnVuIHNlcnZpY2VzIGxvY2FsbHkKIyMjIyBBcHAgc2VydmljZQpgYGBzaGVsbAptYWtlIHJ1bi1hcHAtc2VydmljZQpgY",
+				Content:     "IyBPVVRIIFNlcnZpY2UKCiMjIEVudmlyb25tZW50Ckl0cyBhIHByZXJlcXVpc2l0ZSB0aGF0IHRoZXNlIGVudmlyb25tZW50IHZhcmlhYmxlcyBhcmUgc2V0LiBTZXQgdGhlbSBpbiB5b3VyIHRlcm1pbmFsLgoKYGBgYmFzaAokIGV4cG9ydCBKV1RfU0VDUkVUPTx5b3VyLWp3dC1zZWNyZXQ+CiQgZXhwb3J0IERBVEFC\nQVNFX1VSTD08ZXhhbXBsZS1wb3N0Z3JlczovL3Bvc3RncmVzOm5vdFNvU2VjcmV0QHBvc3RncmVzOjU0MzI+CiQgZXhwb3J0IFBPU1RNQVJLX0FVVEhfVE9LRU49PHlvdXItcG9zdG1hcmstYXV0aC10b2tlbj4KYGBgCgoKIyMgU2V0dXAg\nJiBCdWlsZApgYGBzaGVsbAptYWtlIGJ1aWxkCmBgYAoKIyMgUnVuIHNlcnZpY2VzIGxvY2FsbHkKIyMjIyBBcHAgc2VydmljZQpgYGBzaGVsbAptYWtlIHJ1bi1hcHAtc2VydmljZQpgYGA=",
 				Encoding:    "base64",
 			}
 			w.Header().Set("Content-Type", "application/json")
@@ -530,7 +533,10 @@ func TestGitHubImporter_DatabaseErrorHandling(t *testing.T) {
 		defer testServer.Close()
 
 		// Create importer with test server
-		importerWithClient := NewGitHubImporterWithClient(&http.Client{Timeout: 5 * time.Second}, testServer.URL)
+		importerWithClient := NewGitHubImporter(
+			WithHTTPClient(&http.Client{Timeout: 5 * time.Second}),
+			WithBaseURL(testServer.URL),
+		)
 
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
@@ -657,3 +663,116 @@ func TestGitHubImporter_DatabaseErrorHandling(t *testing.T) {
 		}
 	})
 }
+
+func TestGitHubImporter_CreateSource_Upsert_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db := testutil.SetupTestDB(t)
+	defer testutil.CleanupTestDB(t, db)
+
+	importer := NewGitHubImporter()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	repoInfo := &GitHubRepoInfo{Owner: "code-sleuth", Repo: "outh", Ref: "main"}
+	file := GitHubTreeItem{Path: "README.md", Type: "blob", Size: 787}
+
+	fileURL := "https://github.com/code-sleuth/outh/blob/main/README.md"
+	first, err := importer.createSource(ctx, fileURL, repoInfo, file, db)
+	if err != nil {
+		t.Fatalf("Failed to create source: %v", err)
+	}
+	second, err := importer.createSource(ctx, "HTTPS://GitHub.com:443/code-sleuth/outh/blob/main/README.md#top",
+		repoInfo, file, db)
+	if err != nil {
+		t.Fatalf("Failed to upsert source: %v", err)
+	}
+
+	if first != second {
+		t.Errorf("Expected the same URL to reuse source %s, got %s", first, second)
+	}
+	if count := testutil.GetRecordCount(t, db, "sources"); count != 1 {
+		t.Errorf("Expected 1 source after re-importing the same URL, got %d", count)
+	}
+}
+
+// Test that a file stored from one repository is found by its blob SHA when a fork imports it.
+func TestGitHubImporter_CachedBlob_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db := testutil.SetupTestDB(t)
+	defer testutil.CleanupTestDB(t, db)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	importer := NewGitHubImporter()
+	repoInfo := &GitHubRepoInfo{Owner: "code-sleuth", Repo: "outh", Ref: "main"}
+	file := GitHubTreeItem{Path: "README.md", Type: "blob", SHA: "cached-blob-sha", Size: 11}
+
+	sourceID, err := importer.createSource(ctx, gitHubFileURL(repoInfo, file.Path), repoInfo, file, db)
+	if err != nil {
+		t.Fatalf("Failed to create test source: %v", err)
+	}
+	version := fileVersion{sha: file.SHA, encoding: "base64", content: "# OUTH test"}
+	if _, err := importer.createFileDownload(ctx, sourceID, version, file, db); err != nil {
+		t.Fatalf("Failed to create download: %v", err)
+	}
+
+	cached, ok := importer.cachedBlob(ctx, file.SHA, db)
+	if !ok || cached.contentHash != contentHash(version.content) || cached.encoding != "base64" {
+		t.Errorf("Expected the stored body to be cached by blob SHA, got %+v, %v", cached, ok)
+	}
+	if _, ok := importer.cachedBlob(ctx, "unknown-sha", db); ok {
+		t.Error("Expected no cached blob for an unknown SHA")
+	}
+}
+
+func TestRecordOwnership_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db := testutil.SetupTestDB(t)
+	defer testutil.CleanupTestDB(t, db)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ctx = interfaces.WithOwners(ctx, []string{"platform-team"})
+
+	sourceID, err := upsertSource(ctx, db, "https://github.com/code-sleuth/outh/blob/main/README.md", "json")
+	if err != nil {
+		t.Fatalf("Failed to create test source: %v", err)
+	}
+	err = recordOwnership(ctx, db, sourceID, "ada@example.com", []string{"@org/docs", " ", "platform-team"})
+	if err != nil {
+		t.Fatalf("Failed to record ownership: %v", err)
+	}
+
+	rows, err := db.QueryContext(ctx, `SELECT owner FROM source_owners WHERE source_id = ? ORDER BY owner`, sourceID)
+	if err != nil {
+		t.Fatalf("Failed to query owners: %v", err)
+	}
+	defer rows.Close()
+	var owners []string
+	for rows.Next() {
+		var owner string
+		if err := rows.Scan(&owner); err != nil {
+			t.Fatalf("Failed to scan owner: %v", err)
+		}
+		owners = append(owners, owner)
+	}
+	if !reflect.DeepEqual(owners, []string{"@org/docs", "platform-team"}) {
+		t.Errorf("Expected the context's and the importer's owners once each, got %v", owners)
+	}
+
+	var authorEmail string
+	if err := db.QueryRowContext(ctx, `SELECT author_email FROM sources WHERE id = ?`, sourceID).
+		Scan(&authorEmail); err != nil || authorEmail != "ada@example.com" {
+		t.Errorf("Expected the author email recorded, got %q, %v", authorEmail, err)
+	}
+}
@@ -0,0 +1,171 @@
+package importers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// codeownersPaths are where GitHub looks for a repository's CODEOWNERS file, in the order it
+// looks; the first one found applies.
+var codeownersPaths = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+// codeownersRule is a line of a CODEOWNERS file: the owners of the paths its pattern matches.
+type codeownersRule struct {
+	pattern *regexp.Regexp
+	owners  []string
+}
+
+// GitHubCommitResponse represents a commit in the response from GitHub's commits API.
+type GitHubCommitResponse struct {
+	SHA    string `json:"sha"`
+	Commit struct {
+		Author struct {
+			Name  string `json:"name"`
+			Email string `json:"email"`
+		} `json:"author"`
+	} `json:"commit"`
+}
+
+// parseCodeowners parses the rules of a CODEOWNERS file. Lines whose pattern can't be compiled
+// are left out, as GitHub ignores them too.
+func parseCodeowners(content string) []codeownersRule {
+	var rules []codeownersRule
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if comment := strings.Index(line, " #"); comment >= 0 {
+			line = line[:comment]
+		}
+		fields := strings.Fields(line)
+		pattern, err := regexp.Compile(codeownersRegexp(fields[0]))
+		if err != nil {
+			continue
+		}
+		rules = append(rules, codeownersRule{pattern: pattern, owners: fields[1:]})
+	}
+	return rules
+}
+
+// codeownersRegexp translates a CODEOWNERS pattern, which follows .gitignore syntax, into a
+// regular expression matching the paths it covers. A pattern matching a directory covers
+// everything under it, except one ending in /*, which covers only the directory's own files.
+func codeownersRegexp(pattern string) string {
+	// Patterns with a slash other than a trailing one are relative to the repository's root
+	anchored := strings.Contains(strings.TrimSuffix(pattern, "/"), "/")
+	directory := strings.HasSuffix(pattern, "/")
+	pattern = strings.Trim(pattern, "/")
+
+	var expression strings.Builder
+	if anchored {
+		expression.WriteString("^")
+	} else {
+		expression.WriteString("^(?:.*/)?")
+	}
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			expression.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			expression.WriteString(".*")
+			i++
+		case pattern[i] == '*':
+			expression.WriteString("[^/]*")
+		case pattern[i] == '?':
+			expression.WriteString("[^/]")
+		default:
+			expression.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+	switch {
+	case directory:
+		expression.WriteString("/.*$")
+	case strings.HasSuffix(pattern, "/*"):
+		// docs/* covers the files in docs but not those in its subdirectories
+		expression.WriteString("$")
+	default:
+		expression.WriteString("(?:/.*)?$")
+	}
+	return expression.String()
+}
+
+// codeownersOf returns the owners of path under rules: those of the last rule matching it.
+func codeownersOf(rules []codeownersRule, path string) []string {
+	for i := len(rules) - 1; i >= 0; i-- {
+		if rules[i].pattern.MatchString(path) {
+			return rules[i].owners
+		}
+	}
+	return nil
+}
+
+// readCodeowners fetches and parses the repository's CODEOWNERS file, when the tree has one.
+// A repository without one, or one that can't be read, has no rules.
+func (g *GitHubImporter) readCodeowners(
+	ctx context.Context,
+	repoInfo *GitHubRepoInfo,
+	tree []GitHubTreeItem,
+) []codeownersRule {
+	inTree := make(map[string]bool)
+	for _, item := range tree {
+		if item.Type == "blob" {
+			inTree[item.Path] = true
+		}
+	}
+
+	for _, path := range codeownersPaths {
+		if !inTree[path] {
+			continue
+		}
+		file, err := g.getFileContent(ctx, repoInfo, path, "")
+		if err != nil {
+			g.logger.Warn().Err(err).Str("file_path", path).Msg("Failed to read CODEOWNERS, importing without owners")
+			return nil
+		}
+		return parseCodeowners(file.content)
+	}
+	return nil
+}
+
+// commitAuthor returns the email of the author of the last commit to path, or "" when there is
+// none. It costs one request per file, so it is only made with SetCommitAuthors.
+func (g *GitHubImporter) commitAuthor(ctx context.Context, repoInfo *GitHubRepoInfo, path string) (string, error) {
+	query := url.Values{"path": {path}, "sha": {repoInfo.Ref}, "per_page": {"1"}}
+	commitsURL := fmt.Sprintf("%s/repos/%s/%s/commits?%s", g.apiBaseURL, repoInfo.Owner, repoInfo.Repo, query.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, commitsURL, nil)
+	if err != nil {
+		return "", err
+	}
+	g.authorize(req)
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+
+	resp, err := g.do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%w: %w", ErrGitHubAPIRequestFailed,
+			&StatusError{StatusCode: resp.StatusCode, URL: commitsURL})
+	}
+
+	var commits []GitHubCommitResponse
+	if err := json.NewDecoder(resp.Body).Decode(&commits); err != nil {
+		return "", err
+	}
+	if len(commits) == 0 {
+		return "", nil
+	}
+	return commits[0].Commit.Author.Email, nil
+}
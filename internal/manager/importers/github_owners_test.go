@@ -0,0 +1,111 @@
+package importers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestCodeownersOf(t *testing.T) {
+	rules := parseCodeowners(`# Default owners
+*       @org/everyone
+
+*.go    @org/backend gopher@example.com
+docs/   @org/docs-team  # inline comment
+/build/logs/ @org/ops
+apps/*  @org/apps
+**/testdata @org/qa
+/scripts/unowned.sh
+`)
+
+	tests := []struct {
+		path     string
+		expected []string
+	}{
+		{"README.md", []string{"@org/everyone"}},
+		{"cmd/main.go", []string{"@org/backend", "gopher@example.com"}},
+		{"docs/guide.md", []string{"@org/docs-team"}},
+		{"pkg/docs/api.md", []string{"@org/docs-team"}},
+		{"build/logs/today.txt", []string{"@org/ops"}},
+		{"src/build/logs/today.txt", []string{"@org/everyone"}},
+		{"apps/web.md", []string{"@org/apps"}},
+		{"apps/web/index.md", []string{"@org/everyone"}},
+		{"pkg/testdata/input.json", []string{"@org/qa"}},
+		{"scripts/unowned.sh", nil},
+	}
+	for _, tt := range tests {
+		if got := codeownersOf(rules, tt.path); !slices.Equal(got, tt.expected) {
+			t.Errorf("codeownersOf(%q) = %v, expected %v", tt.path, got, tt.expected)
+		}
+	}
+
+	if got := codeownersOf(nil, "README.md"); got != nil {
+		t.Errorf("Expected no owners without rules, got %v", got)
+	}
+}
+
+func TestGitHubImporter_readCodeowners(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/owner/repo/contents/.github/CODEOWNERS" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"content": "* @org/docs\n", "encoding": "utf-8"}`))
+	}))
+	defer testServer.Close()
+
+	importer := NewGitHubImporter(
+		WithHTTPClient(testServer.Client()),
+		WithBaseURL(testServer.URL),
+		WithRateLimiter(nil),
+	)
+	repoInfo := &GitHubRepoInfo{Owner: "owner", Repo: "repo", Ref: "main"}
+
+	tree := []GitHubTreeItem{{Path: "CODEOWNERS", Type: "blob"}, {Path: ".github/CODEOWNERS", Type: "blob"}}
+	rules := importer.readCodeowners(context.Background(), repoInfo, tree)
+	if got := codeownersOf(rules, "README.md"); !reflect.DeepEqual(got, []string{"@org/docs"}) {
+		t.Errorf("Expected .github/CODEOWNERS to apply, got %v", got)
+	}
+
+	if rules := importer.readCodeowners(context.Background(), repoInfo, tree[:1]); rules != nil {
+		t.Errorf("Expected an unreadable CODEOWNERS to give no rules, got %v", rules)
+	}
+	if rules := importer.readCodeowners(context.Background(), repoInfo, nil); rules != nil {
+		t.Errorf("Expected a tree without CODEOWNERS to give no rules, got %v", rules)
+	}
+}
+
+func TestGitHubImporter_commitAuthor(t *testing.T) {
+	var query string
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		if r.URL.Query().Get("path") == "new.md" {
+			_, _ = w.Write([]byte(`[]`))
+			return
+		}
+		_, _ = w.Write([]byte(`[{"sha": "abc", "commit": {"author": {"name": "Ada", "email": "ada@example.com"}}}]`))
+	}))
+	defer testServer.Close()
+
+	importer := NewGitHubImporter(
+		WithHTTPClient(testServer.Client()),
+		WithBaseURL(testServer.URL),
+		WithRateLimiter(nil),
+	)
+	repoInfo := &GitHubRepoInfo{Owner: "owner", Repo: "repo", Ref: "main"}
+
+	email, err := importer.commitAuthor(context.Background(), repoInfo, "docs/guide.md")
+	if err != nil || email != "ada@example.com" {
+		t.Fatalf("Expected ada@example.com, got %q, %v", email, err)
+	}
+	if query != "path=docs%2Fguide.md&per_page=1&sha=main" {
+		t.Errorf("Unexpected query %q", query)
+	}
+
+	if email, err := importer.commitAuthor(context.Background(), repoInfo, "new.md"); err != nil || email != "" {
+		t.Errorf("Expected no author for a path without commits, got %q, %v", email, err)
+	}
+}
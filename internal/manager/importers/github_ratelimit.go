@@ -0,0 +1,173 @@
+package importers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
+)
+
+const (
+	// DefaultMaxRateLimitWait caps how long the GitHub importer waits for a rate limit to reset.
+	// GitHub's primary rate limit resets every hour.
+	DefaultMaxRateLimitWait = time.Hour
+	// secondaryRateLimitWait is how long to wait after a secondary rate limit that gives no
+	// Retry-After, as GitHub's documentation advises.
+	secondaryRateLimitWait = time.Minute
+	// maxRateLimitWaits is how many rate-limit rejections of one request are waited out.
+	maxRateLimitWaits = 3
+	// rateLimitSlack is added to reset times, so requests don't arrive just before the reset.
+	rateLimitSlack = time.Second
+	// maxRateLimitBody caps how much of a 403 body is read to tell a rate limit from a denial.
+	maxRateLimitBody = 64 * 1024
+)
+
+var ErrGitHubRateLimited = errors.New("GitHub rate limit exceeded")
+
+// gitHubRateLimit tracks GitHub's primary rate limit from the X-RateLimit headers of responses,
+// so requests wait for it to reset once it is used up instead of being rejected. It is shared by
+// every import the importer runs, since they draw on the same token's limit.
+type gitHubRateLimit struct {
+	mu        sync.Mutex
+	remaining int
+	// reset is when the limit next resets; zero until a response reports it
+	reset time.Time
+	now   func() time.Time
+}
+
+func newGitHubRateLimit() *gitHubRateLimit {
+	return &gitHubRateLimit{now: time.Now}
+}
+
+// observe records the rate limit resp reports, if any.
+func (l *gitHubRateLimit) observe(resp *http.Response) {
+	remaining, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+	reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.remaining = remaining
+	l.reset = time.Unix(reset, 0)
+}
+
+// exhausted returns how long until the rate limit resets when no requests are left before then.
+func (l *gitHubRateLimit) exhausted() (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.reset.IsZero() || l.remaining > 0 {
+		return 0, false
+	}
+	wait := l.reset.Sub(l.now())
+	if wait <= 0 {
+		return 0, false
+	}
+	return wait + rateLimitSlack, true
+}
+
+// rateLimitWait returns how long to wait before retrying a request GitHub rejected for
+// exceeding its primary or secondary rate limit. Other responses, including 403s for missing
+// permissions, return false. A 403 body that is read is put back for the caller.
+func rateLimitWait(resp *http.Response) (time.Duration, bool) {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return 0, false
+	}
+	if wait, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+		return wait, true
+	}
+	if wait, ok := rateLimitReset(resp); ok {
+		return wait + rateLimitSlack, true
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return secondaryRateLimitWait, true
+	}
+
+	// Secondary rate limits may come as a bare 403 whose message says so
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxRateLimitBody))
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+	if err == nil && strings.Contains(strings.ToLower(string(body)), "rate limit") {
+		return secondaryRateLimitWait, true
+	}
+	return 0, false
+}
+
+// do sends req, waiting out GitHub's rate limit rather than failing: before the request when
+// earlier responses used the limit up, and after a primary or secondary rate-limit rejection.
+// Waits longer than the importer's maximum or past ctx's deadline fail with ErrGitHubRateLimited.
+func (g *GitHubImporter) do(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	for waits := 0; ; waits++ {
+		if wait, ok := g.rateLimit.exhausted(); ok {
+			if err := g.waitForRateLimit(ctx, req, wait); err != nil {
+				return nil, err
+			}
+		}
+
+		resp, err := g.client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		g.rateLimit.observe(resp)
+
+		wait, limited := rateLimitWait(resp)
+		if !limited {
+			return resp, nil
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+		if waits >= maxRateLimitWaits {
+			return nil, fmt.Errorf("%w: still limited after waiting %d times%s", ErrGitHubRateLimited, waits,
+				g.tokenHint(req.Context()))
+		}
+		if err := g.waitForRateLimit(ctx, req, wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// waitForRateLimit sleeps for wait before req is sent again, reporting the pause as progress.
+func (g *GitHubImporter) waitForRateLimit(ctx context.Context, req *http.Request, wait time.Duration) error {
+	until := time.Now().Add(wait)
+	if wait > g.maxRateLimitWait {
+		return fmt.Errorf("%w: resets at %s, later than the importer waits for%s", ErrGitHubRateLimited,
+			until.Format(time.RFC3339), g.tokenHint(req.Context()))
+	}
+	if deadline, ok := ctx.Deadline(); ok && deadline.Before(until) {
+		return fmt.Errorf("%w: resets at %s, after the import's deadline; use a longer --timeout%s",
+			ErrGitHubRateLimited, until.Format(time.RFC3339), g.tokenHint(req.Context()))
+	}
+
+	g.logger.Warn().Str("url", req.URL.String()).Time("until", until).
+		Msg("GitHub rate limit exceeded, waiting for it to reset")
+	interfaces.ReportProgress(ctx, interfaces.ProgressEvent{
+		Stage: interfaces.StageWaiting,
+		Item:  "GitHub rate limit until " + until.Format(time.RFC3339),
+	})
+	return sleep(ctx, wait)
+}
+
+// tokenHint suggests setting GITHUB_TOKEN when requests made with ctx are unauthenticated, since
+// GitHub allows authenticated requests a much higher rate.
+func (g *GitHubImporter) tokenHint(ctx context.Context) string {
+	if g.tokenFor(ctx) != "" {
+		return ""
+	}
+	return "; set GITHUB_TOKEN for a higher limit"
+}
@@ -0,0 +1,151 @@
+package importers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRateLimitWait(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        int
+		header        http.Header
+		body          string
+		expectLimited bool
+		expectedWait  time.Duration
+	}{
+		{name: "ok", status: http.StatusOK},
+		{name: "retry-after", status: http.StatusForbidden, header: http.Header{"Retry-After": {"30"}},
+			expectLimited: true, expectedWait: 30 * time.Second},
+		{name: "bare 429", status: http.StatusTooManyRequests, expectLimited: true,
+			expectedWait: secondaryRateLimitWait},
+		{name: "secondary 403", status: http.StatusForbidden,
+			body:          `{"message": "You have exceeded a secondary rate limit."}`,
+			expectLimited: true, expectedWait: secondaryRateLimitWait},
+		{name: "permission 403", status: http.StatusForbidden, body: `{"message": "Resource not accessible"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := tt.header
+			if header == nil {
+				header = http.Header{}
+			}
+			resp := &http.Response{
+				StatusCode: tt.status, Header: header, Body: io.NopCloser(strings.NewReader(tt.body)),
+			}
+			wait, limited := rateLimitWait(resp)
+			if limited != tt.expectLimited || wait != tt.expectedWait {
+				t.Errorf("Expected (%v, %v), got (%v, %v)", tt.expectedWait, tt.expectLimited, wait, limited)
+			}
+			// The body stays readable for error reporting
+			if body, _ := io.ReadAll(resp.Body); string(body) != tt.body {
+				t.Errorf("Expected body %q to be kept, got %q", tt.body, body)
+			}
+		})
+	}
+
+	reset := strconv.FormatInt(time.Now().Add(time.Minute).Unix(), 10)
+	resp := &http.Response{StatusCode: http.StatusForbidden, Body: http.NoBody,
+		Header: http.Header{"X-Ratelimit-Remaining": {"0"}, "X-Ratelimit-Reset": {reset}}}
+	if wait, limited := rateLimitWait(resp); !limited || wait < 50*time.Second || wait > time.Minute+rateLimitSlack {
+		t.Errorf("Expected to wait about a minute for the reset, got (%v, %v)", wait, limited)
+	}
+}
+
+func TestGitHubRateLimit_exhausted(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	limit := newGitHubRateLimit()
+	limit.now = func() time.Time { return now }
+
+	if _, ok := limit.exhausted(); ok {
+		t.Error("Expected an unknown limit not to be exhausted")
+	}
+
+	observe := func(remaining int, reset time.Time) {
+		limit.observe(&http.Response{Header: http.Header{
+			"X-Ratelimit-Remaining": {strconv.Itoa(remaining)},
+			"X-Ratelimit-Reset":     {strconv.FormatInt(reset.Unix(), 10)},
+		}})
+	}
+	observe(5, now.Add(time.Minute))
+	if _, ok := limit.exhausted(); ok {
+		t.Error("Expected a limit with requests left not to be exhausted")
+	}
+	observe(0, now.Add(time.Minute))
+	if wait, ok := limit.exhausted(); !ok || wait != time.Minute+rateLimitSlack {
+		t.Errorf("Expected to wait a minute for the reset, got (%v, %v)", wait, ok)
+	}
+	observe(0, now.Add(-time.Second))
+	if _, ok := limit.exhausted(); ok {
+		t.Error("Expected a limit that has reset not to be exhausted")
+	}
+}
+
+func TestGitHubImporter_WaitsOutRateLimit(t *testing.T) {
+	var requests atomic.Int32
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if requests.Add(1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"message": "You have exceeded a secondary rate limit."}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"tree": []}`))
+	}))
+	defer testServer.Close()
+
+	importer := NewGitHubImporter(
+		WithHTTPClient(testServer.Client()),
+		WithBaseURL(testServer.URL),
+		WithRateLimiter(nil),
+		WithRetryPolicy(RetryPolicy{}),
+	)
+
+	_, err := importer.getRepoTree(context.Background(), &GitHubRepoInfo{Owner: "owner", Repo: "repo", Ref: "main"})
+	if err != nil {
+		t.Fatalf("Expected the request to succeed after the rate limit, got %v", err)
+	}
+	if requests.Load() != 2 {
+		t.Errorf("Expected the request to be sent again, got %d requests", requests.Load())
+	}
+}
+
+func TestGitHubImporter_RateLimitLongerThanMaxWait(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer testServer.Close()
+
+	importer := NewGitHubImporter(
+		WithHTTPClient(testServer.Client()),
+		WithBaseURL(testServer.URL),
+		WithRateLimiter(nil),
+		WithRetryPolicy(RetryPolicy{}),
+		WithMaxRateLimitWait(time.Minute),
+	)
+
+	_, err := importer.getRepoTree(context.Background(), &GitHubRepoInfo{Owner: "owner", Repo: "repo", Ref: "main"})
+	if !errors.Is(err, ErrGitHubRateLimited) {
+		t.Errorf("Expected ErrGitHubRateLimited, got %v", err)
+	}
+
+	// With the limit known to be used up, later requests fail without being sent
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	importer.maxRateLimitWait = 2 * time.Hour
+	repoInfo := &GitHubRepoInfo{Owner: "owner", Repo: "repo", Ref: "main"}
+	_, err = importer.getFileContent(ctx, repoInfo, "README.md", "")
+	if !errors.Is(err, ErrGitHubRateLimited) || !strings.Contains(err.Error(), "deadline") {
+		t.Errorf("Expected the wait past the deadline to fail, got %v", err)
+	}
+}
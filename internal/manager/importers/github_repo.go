@@ -0,0 +1,92 @@
+package importers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+const (
+	// visibilityPrivate labels the content of a repository whose visibility can't be read, so
+	// content that may be private is never served to every search.
+	visibilityPrivate = "private"
+	// spdxNoAssertion is the SPDX ID GitHub reports for a license file it can't identify.
+	spdxNoAssertion = "NOASSERTION"
+)
+
+// GitHubRepoResponse represents the response from GitHub's repository API.
+type GitHubRepoResponse struct {
+	Private bool `json:"private"`
+	// Visibility is public, private, or internal; older GitHub Enterprise servers leave it out.
+	Visibility string `json:"visibility"`
+	// License is the license GitHub detected in the repository, if any.
+	License *GitHubLicense `json:"license"`
+}
+
+// GitHubLicense is a repository license as reported by GitHub's repository API.
+type GitHubLicense struct {
+	SPDXID string `json:"spdx_id"`
+}
+
+// readRepo returns the repository's details, or nil when they can't be read.
+func (g *GitHubImporter) readRepo(ctx context.Context, repoInfo *GitHubRepoInfo) *GitHubRepoResponse {
+	repoURL := fmt.Sprintf("%s/repos/%s/%s", g.apiBaseURL, repoInfo.Owner, repoInfo.Repo)
+	repo, err := g.getRepo(ctx, repoURL)
+	if err != nil {
+		g.logger.Warn().Err(err).Str("repo", repoInfo.Repo).
+			Msg("Failed to read repository, labeling it private")
+		return nil
+	}
+	return repo
+}
+
+// visibility returns the repository's visibility, which labels the content imported from it. A
+// repository whose details couldn't be read is taken to be private.
+func (r *GitHubRepoResponse) visibility() string {
+	switch {
+	case r == nil:
+		return visibilityPrivate
+	case r.Visibility != "":
+		return r.Visibility
+	case r.Private:
+		return visibilityPrivate
+	default:
+		return "public"
+	}
+}
+
+// license returns the SPDX identifier of the repository's license, or "" when it has none GitHub
+// could identify.
+func (r *GitHubRepoResponse) license() string {
+	if r == nil || r.License == nil || r.License.SPDXID == spdxNoAssertion {
+		return ""
+	}
+	return r.License.SPDXID
+}
+
+func (g *GitHubImporter) getRepo(ctx context.Context, repoURL string) (*GitHubRepoResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, repoURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	g.authorize(req)
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+
+	resp, err := g.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: %w", ErrGitHubAPIRequestFailed,
+			&StatusError{StatusCode: resp.StatusCode, URL: repoURL})
+	}
+
+	var repo GitHubRepoResponse
+	if err := json.NewDecoder(resp.Body).Decode(&repo); err != nil {
+		return nil, err
+	}
+	return &repo, nil
+}
@@ -0,0 +1,51 @@
+package importers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGitHubImporter_readRepo(t *testing.T) {
+	responses := map[string]string{
+		"/repos/owner/public":   `{"private": false, "visibility": "public", "license": {"spdx_id": "MIT"}}`,
+		"/repos/owner/internal": `{"private": true, "visibility": "internal", "license": null}`,
+		"/repos/owner/legacy":   `{"private": true, "license": {"spdx_id": "NOASSERTION"}}`,
+	}
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := responses[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	defer testServer.Close()
+
+	importer := NewGitHubImporter(
+		WithHTTPClient(testServer.Client()),
+		WithBaseURL(testServer.URL),
+		WithRateLimiter(nil),
+	)
+
+	tests := []struct {
+		repo               string
+		expectedVisibility string
+		expectedLicense    string
+	}{
+		{"public", "public", "MIT"},
+		{"internal", "internal", ""},
+		{"legacy", "private", ""},
+		{"missing", "private", ""},
+	}
+	for _, tt := range tests {
+		repo := importer.readRepo(context.Background(), &GitHubRepoInfo{Owner: "owner", Repo: tt.repo})
+		if got := repo.visibility(); got != tt.expectedVisibility {
+			t.Errorf("visibility of %s = %q, expected %q", tt.repo, got, tt.expectedVisibility)
+		}
+		if got := repo.license(); got != tt.expectedLicense {
+			t.Errorf("license of %s = %q, expected %q", tt.repo, got, tt.expectedLicense)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package importers
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/code-sleuth/ike-go/pkg/util"
+)
+
+// SkipUnchanged is the ItemReport.SkipReason of files whose blob SHA is the one the last import
+// stored, when only files changed since the last completed run are imported.
+const SkipUnchanged = "unchanged since the last import"
+
+// treeRevision returns the revision of a repository import: the SHA of the tree imported and a
+// fingerprint of the filters it was imported with, so changing the exclusions, extensions, or
+// maximum file size counts as a change. A truncated tree lists only part of the repository, so it
+// has no revision.
+func (g *GitHubImporter) treeRevision(tree *GitHubTreeResponse) string {
+	if tree.SHA == "" || tree.Truncated {
+		return ""
+	}
+	filters := fmt.Sprintf("%q %q %d", g.exclusions, g.supportedExts, g.maxFileSize)
+	sum := sha256.Sum256([]byte(filters))
+	return tree.SHA + ":" + hex.EncodeToString(sum[:8])
+}
+
+// changedFiles returns the files of files whose blob SHA differs from the one the last import of
+// the repository stored, and those it skipped as unchanged. Files whose sources were deleted, or
+// whose documents were, count as changed so they are imported again.
+func (g *GitHubImporter) changedFiles(
+	ctx context.Context,
+	repoInfo *GitHubRepoInfo,
+	files []GitHubTreeItem,
+	db *sql.DB,
+) ([]GitHubTreeItem, []GitHubTreeItem, error) {
+	stored, err := g.storedSHAs(ctx, repoInfo, db)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var changed, unchanged []GitHubTreeItem
+	for _, file := range files {
+		normalized, err := util.NormalizeURL(gitHubFileURL(repoInfo, file.Path))
+		if err == nil && file.SHA != "" && stored[normalized] == file.SHA {
+			unchanged = append(unchanged, file)
+			continue
+		}
+		changed = append(changed, file)
+	}
+	return changed, unchanged, nil
+}
+
+// storedSHAs returns the blob SHA of the newest download of every file of the repository that
+// has a live source and document, by the file's normalized URL.
+func (g *GitHubImporter) storedSHAs(
+	ctx context.Context,
+	repoInfo *GitHubRepoInfo,
+	db *sql.DB,
+) (map[string]string, error) {
+	stored := make(map[string]string)
+	// Without a database there is no earlier import to compare with
+	if db == nil {
+		return stored, nil
+	}
+	root, err := util.NormalizeURL(gitHubFileURL(repoInfo, ""))
+	if err != nil {
+		return nil, err
+	}
+	prefix := strings.TrimSuffix(root, "/") + "/"
+
+	rows, err := db.QueryContext(ctx, `SELECT s.raw_url, d.headers FROM sources s
+		JOIN downloads d ON d.source_id = s.id
+		WHERE substr(s.raw_url, 1, length(?)) = ? AND s.deleted_at IS NULL
+		  AND d.downloaded_at = (SELECT MAX(downloaded_at) FROM downloads WHERE source_id = s.id)
+		  AND EXISTS (SELECT 1 FROM documents doc WHERE doc.source_id = s.id AND doc.deleted_at IS NULL)`,
+		prefix, prefix)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var rawURL, headersJSON string
+		if err := rows.Scan(&rawURL, &headersJSON); err != nil {
+			return nil, err
+		}
+		var headers map[string][]string
+		if err := json.Unmarshal([]byte(headersJSON), &headers); err != nil {
+			continue
+		}
+		if values := headers[gitHubSHAHeader]; len(values) > 0 {
+			stored[rawURL] = values[0]
+		}
+	}
+	return stored, rows.Err()
+}
+
+// gitHubFileURL returns the URL sources of a repository's files are stored under.
+func gitHubFileURL(repoInfo *GitHubRepoInfo, path string) string {
+	return fmt.Sprintf("https://github.com/%s/%s/blob/%s/%s", repoInfo.Owner, repoInfo.Repo, repoInfo.Ref, path)
+}
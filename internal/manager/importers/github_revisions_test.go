@@ -0,0 +1,84 @@
+package importers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
+)
+
+func TestGitHubImporter_treeRevision(t *testing.T) {
+	importer := NewGitHubImporter()
+	revision := importer.treeRevision(&GitHubTreeResponse{SHA: "abc"})
+	if revision == "" || revision != importer.treeRevision(&GitHubTreeResponse{SHA: "abc"}) {
+		t.Fatalf("Expected a stable revision, got %q", revision)
+	}
+	if importer.treeRevision(&GitHubTreeResponse{SHA: "def"}) == revision {
+		t.Error("Expected another tree to have another revision")
+	}
+	if got := importer.treeRevision(&GitHubTreeResponse{SHA: "abc", Truncated: true}); got != "" {
+		t.Errorf("Expected a truncated tree to have no revision, got %q", got)
+	}
+
+	filtered := NewGitHubImporter(WithExclusions([]string{"docs/"}))
+	if filtered.treeRevision(&GitHubTreeResponse{SHA: "abc"}) == revision {
+		t.Error("Expected changing the filters to change the revision")
+	}
+}
+
+// Test that an unchanged tree is not walked, and that a changed one is walked file by file while
+// explicit paths are imported whatever the last revision.
+func TestGitHubImporter_Import_LastRevision(t *testing.T) {
+	var fetched []string
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/repos/owner/repo/git/trees/main" {
+			_ = json.NewEncoder(w).Encode(GitHubTreeResponse{SHA: "tree1", Tree: []GitHubTreeItem{
+				{Path: "README.md", Type: "blob", SHA: "blob1", Size: 10},
+			}})
+			return
+		}
+		if r.URL.Path == "/repos/owner/repo" {
+			_, _ = w.Write([]byte(`{"visibility": "public"}`))
+			return
+		}
+		fetched = append(fetched, r.URL.Path)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer testServer.Close()
+
+	importer := NewGitHubImporter(
+		WithHTTPClient(testServer.Client()),
+		WithBaseURL(testServer.URL),
+		WithRateLimiter(nil),
+	)
+	revision := importer.treeRevision(&GitHubTreeResponse{SHA: "tree1"})
+
+	ctx := interfaces.WithLastRevision(context.Background(), revision)
+	result, err := importer.Import(ctx, "https://github.com/owner/repo", nil)
+	if !errors.Is(err, interfaces.ErrNoChanges) || result == nil || result.Revision != revision {
+		t.Fatalf("Expected ErrNoChanges with revision %q, got %+v, %v", revision, result, err)
+	}
+	if len(fetched) != 0 {
+		t.Errorf("Expected no file fetched for an unchanged tree, got %v", fetched)
+	}
+
+	ctx = interfaces.WithLastRevision(context.Background(), "tree0:filters")
+	if _, err := importer.Import(ctx, "https://github.com/owner/repo", nil); !errors.Is(err, ErrNoFilesImported) {
+		t.Fatalf("Expected the changed file to be fetched, got %v", err)
+	}
+	if len(fetched) != 1 {
+		t.Errorf("Expected README.md fetched once, got %v", fetched)
+	}
+
+	ctx = interfaces.WithPaths(interfaces.WithLastRevision(context.Background(), revision), []string{"README.md"})
+	if _, err := importer.Import(ctx, "https://github.com/owner/repo", nil); !errors.Is(err, ErrNoFilesImported) {
+		t.Fatalf("Expected an explicit path to be fetched, got %v", err)
+	}
+	if len(fetched) != 2 {
+		t.Errorf("Expected README.md fetched again, got %v", fetched)
+	}
+}
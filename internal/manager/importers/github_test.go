@@ -2,14 +2,18 @@ package importers
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
-	"os"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
-	"github.com/code-sleuth/ike-go/internal/manager/testutil"
+	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
 )
 
 func TestNewGitHubImporter(t *testing.T) {
@@ -278,8 +282,11 @@ func TestGitHubImporter_GetRepoTree(t *testing.T) {
 	defer testServer.Close()
 
 	// Create importer with custom HTTP client and API base URL pointing to test server
-	importer := NewGitHubImporterWithClient(&http.Client{Timeout: 5 * time.Second}, testServer.URL)
-	importer.SetToken("test-token")
+	importer := NewGitHubImporter(
+		WithHTTPClient(&http.Client{Timeout: 5 * time.Second}),
+		WithBaseURL(testServer.URL),
+		WithToken("test-token"),
+	)
 
 	tests := []struct {
 		name          string
@@ -423,6 +430,33 @@ func TestGitHubImporter_FilterFiles(t *testing.T) {
 	}
 }
 
+func TestGitHubImporter_Import_NoMatchingPaths(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/owner/repo/git/trees/main" {
+			t.Errorf("Expected only the tree to be fetched, got %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(GitHubTreeResponse{Tree: []GitHubTreeItem{
+			{Path: "README.md", Type: "blob", Size: 1024},
+			{Path: "image.png", Type: "blob", Size: 1024},
+		}})
+	}))
+	defer testServer.Close()
+
+	importer := NewGitHubImporter(
+		WithHTTPClient(testServer.Client()),
+		WithBaseURL(testServer.URL),
+		WithRateLimiter(nil),
+	)
+
+	ctx := interfaces.WithPaths(context.Background(), []string{"image.png", "deleted.md"})
+	_, err := importer.Import(ctx, "https://github.com/owner/repo", nil)
+	if !errors.Is(err, interfaces.ErrNoMatchingPaths) {
+		t.Errorf("Expected ErrNoMatchingPaths, got %v", err)
+	}
+}
+
 func TestGitHubImporter_FileFiltering(t *testing.T) {
 	importer := NewGitHubImporter()
 	
@@ -615,12 +649,19 @@ func TestGitHubImporter_Configuration(t *testing.T) {
 		}
 	})
 	
-	t.Run("setter methods", func(t *testing.T) {
-		importer := NewGitHubImporter()
-		
-		// Test SetExclusions
+	t.Run("options", func(t *testing.T) {
 		newExclusions := []string{"custom_exclude", "another_exclude"}
-		importer.SetExclusions(newExclusions)
+		newExts := []string{".custom", ".another"}
+		newMaxSize := int64(2048)
+		newToken := "test-token-123"
+		importer := NewGitHubImporter(
+			WithExclusions(newExclusions),
+			WithSupportedExtensions(newExts),
+			WithMaxFileSize(newMaxSize),
+			WithToken(newToken),
+		)
+		
+		// Test WithExclusions
 		if len(importer.exclusions) != len(newExclusions) {
 			t.Errorf("Expected %d exclusions, got %d", len(newExclusions), len(importer.exclusions))
 		}
@@ -630,9 +671,7 @@ func TestGitHubImporter_Configuration(t *testing.T) {
 			}
 		}
 		
-		// Test SetSupportedExtensions
-		newExts := []string{".custom", ".another"}
-		importer.SetSupportedExtensions(newExts)
+		// Test WithSupportedExtensions
 		if len(importer.supportedExts) != len(newExts) {
 			t.Errorf("Expected %d supported extensions, got %d", len(newExts), len(importer.supportedExts))
 		}
@@ -642,16 +681,12 @@ func TestGitHubImporter_Configuration(t *testing.T) {
 			}
 		}
 		
-		// Test SetMaxFileSize
-		newMaxSize := int64(2048)
-		importer.SetMaxFileSize(newMaxSize)
+		// Test WithMaxFileSize
 		if importer.maxFileSize != newMaxSize {
 			t.Errorf("Expected max file size %d, got %d", newMaxSize, importer.maxFileSize)
 		}
 		
-		// Test SetToken
-		newToken := "test-token-123"
-		importer.SetToken(newToken)
+		// Test WithToken
 		if importer.token != newToken {
 			t.Errorf("Expected token %s, got %s", newToken, importer.token)
 		}
@@ -659,30 +694,17 @@ func TestGitHubImporter_Configuration(t *testing.T) {
 }
 
 func TestGitHubImporter_GitHubTokenEnvironment(t *testing.T) {
-	// Load environment variables from .env file
-	err := testutil.LoadEnvFromFile("../../../.env")
-	if err != nil {
-		t.Logf("Warning: Failed to load .env file: %v", err)
-	}
-
-	// Save original environment variable
-	originalToken := os.Getenv("GITHUB_TOKEN")
-	defer os.Setenv("GITHUB_TOKEN", originalToken)
-
-	// Test with token set
-	testToken := "test-github-token"
-	os.Setenv("GITHUB_TOKEN", testToken)
+	// Tokens only come from options; config.Load reads GITHUB_TOKEN
+	t.Setenv("GITHUB_TOKEN", "test-github-token")
 
 	importer := NewGitHubImporter()
-	if importer.token != testToken {
-		t.Errorf("Expected token from environment %s, got %s", testToken, importer.token)
+	if importer.token != "" {
+		t.Errorf("Expected no token without WithToken, got %s", importer.token)
 	}
 
-	// Test with no token
-	os.Unsetenv("GITHUB_TOKEN")
-	importer2 := NewGitHubImporter()
+	importer2 := NewGitHubImporter(WithToken(""))
 	if importer2.token != "" {
-		t.Errorf("Expected empty token when not set in environment, got %s", importer2.token)
+		t.Errorf("Expected an empty token to stay empty, got %s", importer2.token)
 	}
 }
 
@@ -733,3 +755,233 @@ func BenchmarkNewGitHubImporter(b *testing.B) {
 		NewGitHubImporter()
 	}
 }
+
+func TestContentHash(t *testing.T) {
+	tests := []struct {
+		name        string
+		a           string
+		b           string
+		expectEqual bool
+		description string
+	}{
+		{
+			name:        "identical content",
+			a:           "package main",
+			b:           "package main",
+			expectEqual: true,
+			description: "should hash identical bodies to the same key",
+		},
+		{
+			name:        "different content",
+			a:           "package main",
+			b:           "package main\n",
+			expectEqual: false,
+			description: "should hash different bodies to different keys",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hashA, hashB := contentHash(tt.a), contentHash(tt.b)
+			if (hashA == hashB) != tt.expectEqual {
+				t.Errorf("Expected equal=%v, got %s and %s", tt.expectEqual, hashA, hashB)
+			}
+			if len(hashA) != 64 {
+				t.Errorf("Expected 64 character hex hash, got %d characters", len(hashA))
+			}
+		})
+	}
+}
+
+// Test that a failed import reports why each file failed, so callers can tell auth failures from
+// missing files.
+func TestGitHubImporter_Import_TypedErrors(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/owner/repo/git/trees/main":
+			_ = json.NewEncoder(w).Encode(GitHubTreeResponse{Tree: []GitHubTreeItem{
+				{Path: "private.md", Type: "blob", Size: 10},
+				{Path: "deleted.md", Type: "blob", Size: 10},
+				{Path: "huge.md", Type: "blob", Size: defaultMaxFileSize + 1},
+			}})
+		case "/repos/owner/repo/contents/private.md":
+			w.WriteHeader(http.StatusUnauthorized)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer testServer.Close()
+
+	importer := NewGitHubImporter(
+		WithHTTPClient(testServer.Client()),
+		WithBaseURL(testServer.URL),
+		WithRateLimiter(nil),
+	)
+
+	_, err := importer.Import(context.Background(), "https://github.com/owner/repo", nil)
+	if !errors.Is(err, ErrNoFilesImported) {
+		t.Errorf("Expected ErrNoFilesImported, got %v", err)
+	}
+	if !errors.Is(err, ErrUnauthorized) || !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected both the 401 and the 404 to be reported, got %v", err)
+	}
+	if errors.Is(err, ErrForbidden) {
+		t.Errorf("Expected no 403 to be reported, got %v", err)
+	}
+
+	_, err = importer.getRepoTree(context.Background(), &GitHubRepoInfo{Owner: "owner", Repo: "missing", Ref: "main"})
+	if !errors.Is(err, ErrGitHubAPIRequestFailed) || !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected a missing repository to report ErrNotFound, got %v", err)
+	}
+}
+
+func TestGitHubImporter_skipReason(t *testing.T) {
+	importer := NewGitHubImporter()
+
+	tests := []struct {
+		name     string
+		item     GitHubTreeItem
+		expected string
+	}{
+		{name: "imported", item: GitHubTreeItem{Path: "docs/guide.md", Size: 10}, expected: ""},
+		{name: "too large", item: GitHubTreeItem{Path: "docs/big.md", Size: defaultMaxFileSize + 1},
+			expected: SkipTooLarge},
+		{name: "excluded", item: GitHubTreeItem{Path: "node_modules/pkg/README.md", Size: 10},
+			expected: SkipExcluded},
+		{name: "unsupported", item: GitHubTreeItem{Path: "logo.png", Size: 10}, expected: SkipUnsupported},
+		{name: "symlink", item: GitHubTreeItem{Path: "docs/link.md", Mode: "120000", Type: "blob", Size: 10},
+			expected: SkipSymlink},
+		{name: "submodule", item: GitHubTreeItem{Path: "vendor/lib", Mode: "160000", Type: "commit"},
+			expected: SkipSubmodule},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := importer.skipReason(tt.item); got != tt.expected {
+				t.Errorf("Expected skip reason %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestGitHubImporter_importFile_LFSPointer(t *testing.T) {
+	pointer := "version https://git-lfs.github.com/spec/v1\n" +
+		"oid sha256:4d7a214614ab2935c943f9e0ff69d22eadbb8f32b1258daaa5e2ca24d17e2393\nsize 12345\n"
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(GitHubFileResponse{
+			Content: base64.StdEncoding.EncodeToString([]byte(pointer)), Encoding: "base64",
+		})
+	}))
+	defer testServer.Close()
+
+	importer := NewGitHubImporter(
+		WithHTTPClient(testServer.Client()),
+		WithBaseURL(testServer.URL),
+		WithRateLimiter(nil),
+	)
+	repoInfo := &GitHubRepoInfo{Owner: "owner", Repo: "repo", Ref: "main"}
+
+	_, err := importer.importFile(context.Background(), repoInfo, GitHubTreeItem{Path: "docs/manual.md"}, nil)
+	if !errors.Is(err, errLFSPointer) {
+		t.Errorf("Expected errLFSPointer, got %v", err)
+	}
+	if isLFSPointer("version 1.2 of the docs") {
+		t.Error("Expected ordinary text not to be taken for an LFS pointer")
+	}
+}
+
+// Test that files are fetched concurrently, no more at once than the configured concurrency, and
+// that the report lists them in tree order whatever order they finish in.
+func TestGitHubImporter_Import_Concurrency(t *testing.T) {
+	const files = 12
+	var inFlight, peak atomic.Int32
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/repos/owner/repo/git/trees/main" {
+			tree := GitHubTreeResponse{}
+			for i := range files {
+				tree.Tree = append(tree.Tree, GitHubTreeItem{Path: fmt.Sprintf("doc%02d.md", i), Type: "blob", Size: 10})
+			}
+			_ = json.NewEncoder(w).Encode(tree)
+			return
+		}
+
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			seen := peak.Load()
+			if current <= seen || peak.CompareAndSwap(seen, current) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer testServer.Close()
+
+	importer := NewGitHubImporter(
+		WithHTTPClient(testServer.Client()),
+		WithBaseURL(testServer.URL),
+		WithRateLimiter(nil),
+		WithConcurrency(3),
+	)
+
+	_, err := importer.Import(context.Background(), "https://github.com/owner/repo", nil)
+	if !errors.Is(err, ErrNoFilesImported) || !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Expected every file to fail with ErrNotFound, got %v", err)
+	}
+	if got := peak.Load(); got < 2 || got > 3 {
+		t.Errorf("Expected between 2 and 3 files fetched at once, got %d", got)
+	}
+
+	var joined interface{ Unwrap() []error }
+	if !errors.As(err, &joined) {
+		t.Fatalf("Expected joined per-file errors, got %v", err)
+	}
+	errs := joined.Unwrap()
+	if len(errs) != 2 {
+		t.Fatalf("Expected ErrNoFilesImported and the report's errors, got %d errors", len(errs))
+	}
+	if !errors.As(errs[1], &joined) || len(joined.Unwrap()) != files {
+		t.Fatalf("Expected %d per-file errors, got %v", files, errs[1])
+	}
+	for i, fileErr := range joined.Unwrap() {
+		if want := fmt.Sprintf("doc%02d.md: ", i); !strings.HasPrefix(fileErr.Error(), want) {
+			t.Errorf("Expected error %d to be for %q, got %v", i, want, fileErr)
+		}
+	}
+}
+
+func TestGitHubImporter_Authorize(t *testing.T) {
+	importer := NewGitHubImporter(WithToken("ghp_configured"))
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		expected string
+	}{
+		{name: "configured token", ctx: context.Background(), expected: "token ghp_configured"},
+		{
+			name:     "token from the context",
+			ctx:      interfaces.WithCredentials(context.Background(), interfaces.Credentials{GitHubToken: "ghp_caller"}),
+			expected: "token ghp_caller",
+		},
+		{
+			name:     "credentials without a GitHub token",
+			ctx:      interfaces.WithCredentials(context.Background(), interfaces.Credentials{OpenAIAPIKey: "sk-x"}),
+			expected: "token ghp_configured",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequestWithContext(tt.ctx, http.MethodGet, "https://api.github.com/user", nil)
+			if err != nil {
+				t.Fatalf("Failed to create request: %v", err)
+			}
+			importer.authorize(req)
+			if got := req.Header.Get("Authorization"); got != tt.expected {
+				t.Errorf("Expected Authorization %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package importers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/code-sleuth/ike-go/pkg/dialect"
+	"github.com/code-sleuth/ike-go/pkg/util"
+)
+
+// Headers recorded with GitHub downloads, for telling on the next import whether a file changed.
+// gitHubEncodingHeader holds the encoding GitHub sent the content in; bodies are stored decoded,
+// and downloads recorded before that lack the header. gitHubLicenseHeader holds the SPDX
+// identifier of the repository's license, for the transformer to record with the document.
+const (
+	gitHubSHAHeader      = "X-GitHub-SHA"
+	gitHubETagHeader     = "ETag"
+	gitHubEncodingHeader = "X-GitHub-Encoding"
+	gitHubLicenseHeader  = "X-GitHub-License"
+)
+
+// errNotModified is returned by a conditional request when the file is unchanged.
+var errNotModified = errors.New("not modified")
+
+// fileVersion is a version of a repository file: its blob SHA, ETag, and the encoding GitHub sent
+// it in, and either its decoded content or, when the last import already stored it, the hash of
+// its stored body. license is that of the repository it is imported from.
+type fileVersion struct {
+	sha         string
+	etag        string
+	encoding    string
+	content     string
+	contentHash string
+	license     string
+}
+
+// cachedBlob returns the stored version of the blob with the given SHA, whichever repository,
+// branch, or path it was imported from. Failing to look it up only costs a request, so errors are
+// logged rather than returned.
+func (g *GitHubImporter) cachedBlob(ctx context.Context, sha string, db *sql.DB) (fileVersion, bool) {
+	if db == nil || sha == "" {
+		return fileVersion{}, false
+	}
+
+	version := fileVersion{sha: sha}
+	err := db.QueryRowContext(ctx, `SELECT content_hash, encoding FROM github_blobs WHERE sha = ?`, sha).
+		Scan(&version.contentHash, &version.encoding)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fileVersion{}, false
+	}
+	if err != nil {
+		g.logger.Warn().Err(err).Str("sha", sha).Msg("Failed to look up the cached blob")
+		return fileVersion{}, false
+	}
+	return version, true
+}
+
+// cacheBlob records that the blob with the given SHA is stored under contentHash. Versions without
+// an encoding were not fetched from GitHub, so their body is not known to be the blob's.
+func cacheBlob(ctx context.Context, db dbExecutor, sha, contentHash, encoding string) error {
+	if sha == "" || encoding == "" {
+		return nil
+	}
+	_, err := db.ExecContext(ctx, `INSERT INTO github_blobs (sha, content_hash, encoding, created_at)
+		VALUES (?, ?, ?, ?) ON CONFLICT (sha) DO NOTHING`,
+		sha, contentHash, encoding, dialect.SQLite.FormatTime(time.Now()))
+	return err
+}
+
+// previousVersion returns the version of the file at fileURL the last import stored. Failing to
+// look it up only costs a full request, so errors are logged rather than returned.
+func (g *GitHubImporter) previousVersion(ctx context.Context, fileURL string, db *sql.DB) (fileVersion, bool) {
+	// Without a database there is no earlier import to compare with
+	if db == nil {
+		return fileVersion{}, false
+	}
+	normalized, err := util.NormalizeURL(fileURL)
+	if err != nil {
+		return fileVersion{}, false
+	}
+
+	var headersJSON, contentHash sql.NullString
+	err = db.QueryRowContext(ctx, `SELECT d.headers, d.content_hash FROM downloads d
+		JOIN sources s ON s.id = d.source_id
+		WHERE s.raw_url = ? AND s.deleted_at IS NULL AND d.content_hash IS NOT NULL
+		ORDER BY d.downloaded_at DESC LIMIT 1`, normalized).Scan(&headersJSON, &contentHash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fileVersion{}, false
+	}
+	if err != nil {
+		g.logger.Warn().Err(err).Str("url", fileURL).Msg("Failed to look up the previous download")
+		return fileVersion{}, false
+	}
+
+	var headers map[string][]string
+	if err := json.Unmarshal([]byte(headersJSON.String), &headers); err != nil {
+		return fileVersion{}, false
+	}
+	// Bodies stored before content was decoded at import are fetched again rather than reused
+	encoding := headers[gitHubEncodingHeader]
+	if len(encoding) == 0 {
+		return fileVersion{}, false
+	}
+	version := fileVersion{encoding: encoding[0], contentHash: contentHash.String}
+	if values := headers[gitHubSHAHeader]; len(values) > 0 {
+		version.sha = values[0]
+	}
+	if values := headers[gitHubETagHeader]; len(values) > 0 {
+		version.etag = values[0]
+	}
+	return version, true
+}
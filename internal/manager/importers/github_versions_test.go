@@ -0,0 +1,88 @@
+package importers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGitHubImporter_getFileContent_Conditional(t *testing.T) {
+	const etag = `W/"abc123"`
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		_, _ = w.Write([]byte(`{"content": "aGVs\nbG8=\n", "encoding": "base64"}`))
+	}))
+	defer testServer.Close()
+
+	importer := NewGitHubImporter(
+		WithHTTPClient(testServer.Client()),
+		WithBaseURL(testServer.URL),
+		WithRateLimiter(nil),
+	)
+	repoInfo := &GitHubRepoInfo{Owner: "owner", Repo: "repo", Ref: "main"}
+
+	version, err := importer.getFileContent(context.Background(), repoInfo, "README.md", "")
+	if err != nil || version.content != "hello" || version.etag != etag || version.encoding != "base64" {
+		t.Errorf("Expected the decoded content with its ETag, got %+v, %v", version, err)
+	}
+
+	_, err = importer.getFileContent(context.Background(), repoInfo, "README.md", etag)
+	if !errors.Is(err, errNotModified) {
+		t.Errorf("Expected an unchanged file to return errNotModified, got %v", err)
+	}
+
+	version, err = importer.getFileContent(context.Background(), repoInfo, "README.md", `W/"stale"`)
+	if err != nil || version.content != "hello" {
+		t.Errorf("Expected a changed file to be fetched, got %q, %v", version.content, err)
+	}
+}
+
+func TestDecodeContent(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		encoding    string
+		expected    string
+		expectedErr bool
+	}{
+		{name: "wrapped base64", content: "aGVs\nbG8g\nd29y\nbGQ=\n", encoding: "base64", expected: "hello world"},
+		{name: "plain text", content: "hello", encoding: "", expected: "hello"},
+		{name: "windows-1252", content: "Y2Fm6Q==", encoding: "base64", expected: "café"},
+		{name: "invalid base64", content: "not base64!", encoding: "base64", expectedErr: true},
+		{name: "unsupported encoding", content: "", encoding: "none", expectedErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, err := decodeContent(tt.content, tt.encoding)
+			if tt.expectedErr {
+				if err == nil {
+					t.Errorf("Expected an error, got %q", content)
+				}
+				return
+			}
+			if err != nil || content != tt.expected {
+				t.Errorf("Expected %q, got %q, %v", tt.expected, content, err)
+			}
+		})
+	}
+	if _, err := decodeContent("", "none"); !errors.Is(err, ErrUnsupportedContentEncoding) {
+		t.Errorf("Expected ErrUnsupportedContentEncoding, got %v", err)
+	}
+}
+
+func TestGitHubImporter_cachedBlob_NoDatabase(t *testing.T) {
+	importer := NewGitHubImporter()
+	if _, ok := importer.cachedBlob(context.Background(), "abc", nil); ok {
+		t.Error("Expected no cached blob without a database")
+	}
+	// Versions without an encoding are not cached, so no database is needed
+	if err := cacheBlob(context.Background(), nil, "abc", "hash", ""); err != nil {
+		t.Errorf("Expected a version without an encoding to be ignored, got %v", err)
+	}
+}
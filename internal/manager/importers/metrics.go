@@ -0,0 +1,33 @@
+package importers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/code-sleuth/ike-go/pkg/metrics"
+)
+
+var (
+	requestsTotal = metrics.NewCounterVec("ike_importer_requests_total",
+		"HTTP requests sent by importers by host and status code (\"error\" when no response arrived).",
+		"host", "code")
+	requestDuration = metrics.NewHistogramVec("ike_importer_request_duration_seconds",
+		"Latency of importer HTTP requests by host, per attempt.", nil, "host")
+	retriesTotal = metrics.NewCounterVec("ike_importer_retries_total",
+		"Importer HTTP requests retried after a transient failure, by host.", "host")
+)
+
+// roundTrip sends req with next and records the attempt's status and latency.
+func roundTrip(next http.RoundTripper, req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := next.RoundTrip(req)
+
+	code := "error"
+	if err == nil {
+		code = strconv.Itoa(resp.StatusCode)
+	}
+	requestsTotal.With(req.URL.Host, code).Inc()
+	requestDuration.With(req.URL.Host).Observe(time.Since(start).Seconds())
+	return resp, err
+}
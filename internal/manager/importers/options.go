@@ -0,0 +1,177 @@
+package importers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Option configures an importer when it is created, as in
+// NewGitHubImporter(WithToken(token), WithConcurrency(8)). Options an importer has no use for,
+// such as WithPerPage for a GitHub importer, are ignored, so one set of options can configure
+// every importer.
+type Option func(*options)
+
+// options are the settings Options give; unset ones leave the importer's default.
+type options struct {
+	client           *http.Client
+	baseURL          string
+	token            string
+	concurrency      int
+	retryPolicy      *RetryPolicy
+	limiter          *HostLimiter
+	limiterSet       bool
+	timeout          time.Duration
+	maxRateLimitWait *time.Duration
+	exclusions       []string
+	supportedExts    []string
+	maxFileSize      int64
+	commitAuthors    bool
+	perPage          int
+	maxPages         int
+	auth             *WPAuth
+	logger           *zerolog.Logger
+}
+
+// newOptions returns the settings given by opts.
+func newOptions(opts []Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// configureClient returns client with the timeout, retry policy, and rate limiter the options
+// set applied.
+func (o *options) configureClient(client *http.Client) *http.Client {
+	if o.timeout > 0 {
+		client.Timeout = o.timeout
+	}
+	if o.retryPolicy != nil {
+		client = withRetries(client, *o.retryPolicy)
+	}
+	if o.limiterSet {
+		client = withRateLimit(client, o.limiter)
+	}
+	return client
+}
+
+// WithHTTPClient sends the importer's requests through client, such as one for a test server.
+// Requests are still retried and rate limited.
+func WithHTTPClient(client *http.Client) Option {
+	return func(o *options) {
+		o.client = client
+	}
+}
+
+// WithBaseURL sets the API the GitHub importer calls, such as a GitHub Enterprise server's.
+func WithBaseURL(baseURL string) Option {
+	return func(o *options) {
+		o.baseURL = baseURL
+	}
+}
+
+// WithToken sets the GitHub API token; without it, requests are unauthenticated.
+func WithToken(token string) Option {
+	return func(o *options) {
+		o.token = token
+	}
+}
+
+// WithConcurrency sets how many files or posts are fetched and stored at once.
+func WithConcurrency(concurrency int) Option {
+	return func(o *options) {
+		o.concurrency = concurrency
+	}
+}
+
+// WithRetryPolicy sets how API requests are retried after transient failures.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(o *options) {
+		o.retryPolicy = &policy
+	}
+}
+
+// WithRateLimiter sets the per-host limiter API requests wait on; nil disables rate limiting.
+func WithRateLimiter(limiter *HostLimiter) Option {
+	return func(o *options) {
+		o.limiter = limiter
+		o.limiterSet = true
+	}
+}
+
+// WithTimeout sets the HTTP client timeout.
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.timeout = timeout
+	}
+}
+
+// WithMaxRateLimitWait sets how long a GitHub request may wait for the rate limit to reset before
+// the file fails; zero fails as soon as the limit is exceeded.
+func WithMaxRateLimitWait(wait time.Duration) Option {
+	return func(o *options) {
+		o.maxRateLimitWait = &wait
+	}
+}
+
+// WithExclusions sets the paths and patterns the GitHub importer skips.
+func WithExclusions(exclusions []string) Option {
+	return func(o *options) {
+		o.exclusions = exclusions
+	}
+}
+
+// WithSupportedExtensions sets the file extensions the GitHub importer imports.
+func WithSupportedExtensions(extensions []string) Option {
+	return func(o *options) {
+		o.supportedExts = extensions
+	}
+}
+
+// WithMaxFileSize sets the size of the largest file the GitHub importer imports.
+func WithMaxFileSize(size int64) Option {
+	return func(o *options) {
+		o.maxFileSize = size
+	}
+}
+
+// WithCommitAuthors records the email of the author of each file's last commit as its source's
+// author email. It costs a request for every new or changed file.
+func WithCommitAuthors(enabled bool) Option {
+	return func(o *options) {
+		o.commitAuthors = enabled
+	}
+}
+
+// WithPerPage sets the number of posts the WordPress importer fetches per page.
+func WithPerPage(perPage int) Option {
+	return func(o *options) {
+		o.perPage = perPage
+	}
+}
+
+// WithMaxPages sets the maximum number of pages the WordPress importer fetches.
+func WithMaxPages(maxPages int) Option {
+	return func(o *options) {
+		o.maxPages = maxPages
+	}
+}
+
+// WithAuth sets the credentials WordPress API requests are sent with, in place of those from the
+// environment.
+func WithAuth(auth WPAuth) Option {
+	return func(o *options) {
+		o.auth = &auth
+	}
+}
+
+// WithLogger sends the importer's logs to logger, such as one from util.NewSlogLogger, in place of
+// the component logger it would create.
+func WithLogger(logger zerolog.Logger) Option {
+	return func(o *options) {
+		o.logger = &logger
+	}
+}
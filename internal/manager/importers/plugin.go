@@ -0,0 +1,213 @@
+package importers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
+	"github.com/code-sleuth/ike-go/internal/manager/plugins"
+	"github.com/code-sleuth/ike-go/pkg/dialect"
+	"github.com/code-sleuth/ike-go/pkg/util"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+var (
+	ErrURLNotHandledByPlugin = errors.New("URL not handled by plugin")
+	ErrNoItemsImported       = errors.New("plugin returned no items")
+)
+
+// PluginImporter imports sources through an external plugin. The plugin fetches the items and
+// the importer stores each one as a source and download, like the built-in importers do.
+type PluginImporter struct {
+	plugin *plugins.Plugin
+	logger zerolog.Logger
+}
+
+// NewPluginImporter creates an importer backed by plugin.
+func NewPluginImporter(plugin *plugins.Plugin) *PluginImporter {
+	return &PluginImporter{
+		plugin: plugin,
+		logger: util.ComponentLogger("importers.plugin", zerolog.ErrorLevel),
+	}
+}
+
+// GetSourceType returns the source type declared by the plugin.
+func (p *PluginImporter) GetSourceType() string {
+	return p.plugin.Manifest.SourceType
+}
+
+// ValidateSource checks the URL against the plugin's url_pattern.
+func (p *PluginImporter) ValidateSource(sourceURL string) error {
+	if !p.plugin.Matches(sourceURL) {
+		return fmt.Errorf("%w: %s", ErrURLNotHandledByPlugin, p.plugin.Manifest.Name)
+	}
+	return nil
+}
+
+// Import asks the plugin for the source's items and stores the ones not already imported.
+func (p *PluginImporter) Import(ctx context.Context, sourceURL string, db *sql.DB) (*interfaces.ImportResult, error) {
+	if err := p.ValidateSource(sourceURL); err != nil {
+		return nil, err
+	}
+
+	fetched, err := p.plugin.Import(ctx, plugins.ImportParams{
+		SourceURL: sourceURL,
+		Paths:     interfaces.PathsFromContext(ctx),
+	})
+	if err != nil {
+		p.logger.Error().Err(err).Str("source_url", sourceURL).Msg("Plugin import failed")
+		return nil, err
+	}
+	if len(fetched.Items) == 0 {
+		if len(interfaces.PathsFromContext(ctx)) > 0 {
+			return nil, interfaces.ErrNoMatchingPaths
+		}
+		return nil, fmt.Errorf("%w: %s", ErrNoItemsImported, p.plugin.Manifest.Name)
+	}
+
+	interfaces.ReportProgress(ctx, interfaces.ProgressEvent{
+		Stage: interfaces.StageDiscovered,
+		Item:  sourceURL,
+		Count: len(fetched.Items),
+	})
+
+	var lastResult *interfaces.ImportResult
+	report := &interfaces.ImportReport{}
+	checkpoint := interfaces.CheckpointFromContext(ctx)
+
+	for _, item := range fetched.Items {
+		key := item.Key
+		if key == "" {
+			key = item.URL
+		}
+
+		// Skip items an interrupted run already imported
+		if result, ok := checkpoint.Imported(key); ok {
+			lastResult = result
+			report.Add(interfaces.ItemReport{Key: key, Status: interfaces.ItemImported})
+			interfaces.ReportProgress(ctx, interfaces.ProgressEvent{
+				Stage: interfaces.StageImported, Item: key, Count: 1,
+			})
+			continue
+		}
+
+		result, err := p.importItem(ctx, item, db)
+		if err != nil {
+			report.Add(interfaces.ItemReport{
+				Key: key, Status: interfaces.ItemFailed, Err: fmt.Errorf("%s: %w", key, err),
+			})
+			p.logger.Error().Err(err).Str("item", key).Msg("Failed to import plugin item")
+			interfaces.ReportProgress(ctx, interfaces.ProgressEvent{
+				Stage: interfaces.StageFailed, Item: key, Count: 1, Err: err,
+			})
+			continue
+		}
+
+		lastResult = result
+		report.Add(interfaces.ItemReport{Key: key, Status: interfaces.ItemImported})
+		interfaces.ReportProgress(ctx, interfaces.ProgressEvent{
+			Stage: interfaces.StageImported, Item: key, Count: 1,
+		})
+		if err := checkpoint.RecordImport(ctx, key, result); err != nil {
+			p.logger.Warn().Err(err).Str("item", key).Msg("Failed to record import checkpoint")
+		}
+	}
+
+	if lastResult == nil {
+		return nil, fmt.Errorf("all imports failed: %w", report.Err())
+	}
+	lastResult.Report = report
+	if report.Failed > 0 {
+		p.logger.Warn().Err(report.Err()).Int("error_count", report.Failed).Msg("Plugin import completed with errors")
+		lastResult.Error = fmt.Errorf("%w: %w", ErrImportCompleted, report.Err())
+	}
+
+	return lastResult, nil
+}
+
+// importItem stores an item's source and download together.
+func (p *PluginImporter) importItem(
+	ctx context.Context,
+	item plugins.Item,
+	db *sql.DB,
+) (*interfaces.ImportResult, error) {
+	parsedURL, err := url.Parse(item.URL)
+	if err != nil || parsedURL.Host == "" {
+		return nil, fmt.Errorf("invalid item URL %q: %w", item.URL, err)
+	}
+
+	format := item.Format
+	if format == "" {
+		format = "text"
+	}
+	statusCode := item.StatusCode
+	if statusCode == 0 {
+		statusCode = http.StatusOK
+	}
+
+	result := &interfaces.ImportResult{}
+	err = withTx(ctx, db, func(tx *sql.Tx) error {
+		var err error
+		result.SourceID, err = upsertSource(ctx, tx, parsedURL.String(), format)
+		if err != nil {
+			return err
+		}
+		if err := recordACL(ctx, tx, result.SourceID, aclLabels(ctx, item.ACLLabels)); err != nil {
+			return err
+		}
+		result.DownloadID, err = p.createDownload(ctx, result.SourceID, statusCode, item, tx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// createDownload creates a download record for the item's body.
+func (p *PluginImporter) createDownload(
+	ctx context.Context,
+	sourceID string,
+	statusCode int,
+	item plugins.Item,
+	db dbExecutor,
+) (string, error) {
+	downloadID := uuid.New().String()
+	now := dialect.SQLite.FormatTime(time.Now())
+
+	headersJSON, err := json.Marshal(util.RedactHeaders(item.Headers))
+	if err != nil {
+		return "", err
+	}
+
+	hash, err := storeBody(ctx, db, item.Body)
+	if err != nil {
+		return "", err
+	}
+	existingID, found, err := findDownloadID(ctx, db, sourceID, hash)
+	if err != nil {
+		return "", err
+	}
+	if found {
+		return existingID, nil
+	}
+
+	query := `INSERT INTO downloads (id, source_id, attempted_at, downloaded_at, status_code, headers, content_hash)
+			  VALUES (?, ?, ?, ?, ?, ?, ?)`
+
+	_, err = db.ExecContext(ctx, query, downloadID, sourceID, now, now, statusCode, string(headersJSON), hash)
+	if err != nil {
+		return "", err
+	}
+
+	return downloadID, nil
+}
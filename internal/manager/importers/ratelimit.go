@@ -0,0 +1,161 @@
+package importers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultRateLimit is the default sustained request rate per host, in requests per second.
+	DefaultRateLimit = 5.0
+	// DefaultRateBurst is the default number of requests a host may receive back to back.
+	DefaultRateBurst = 10
+
+	// minRateShare is the smallest share of its configured rate a throttling host is slowed to.
+	minRateShare = 1.0 / 16
+	// rateRecovery is the factor the rate share of a host grows by with every accepted request.
+	rateRecovery = 1.05
+	// throttleCooldown is the least time between two slowdowns of a host, so the rejections of
+	// requests already sent when the first arrived only count once.
+	throttleCooldown = time.Second
+)
+
+// sharedLimiter is used by every importer unless SetRateLimiter is called, so imports running in
+// the same process share one budget per host.
+var sharedLimiter = NewHostLimiter(DefaultRateLimit, DefaultRateBurst)
+
+// HostLimiter is a token-bucket rate limiter keyed by host. Every importer request waits on it
+// before being sent, so large imports stay within what target servers tolerate. Hosts that
+// throttle requests are slowed down below their configured rate and sped up again gradually as
+// requests are accepted; see Observe.
+type HostLimiter struct {
+	rate      float64
+	burst     int
+	overrides map[string]hostLimit
+	buckets   map[string]*bucket
+	now       func() time.Time
+	mu        sync.Mutex
+}
+
+type hostLimit struct {
+	rate  float64
+	burst int
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	// share is the part of the configured rate the host currently gets, lowered while it throttles
+	share   float64
+	lastCut time.Time
+}
+
+// NewHostLimiter creates a limiter allowing rate requests per second to each host with bursts of
+// up to burst requests. A rate of zero or less disables limiting.
+func NewHostLimiter(rate float64, burst int) *HostLimiter {
+	return &HostLimiter{
+		rate:      rate,
+		burst:     max(burst, 1),
+		overrides: make(map[string]hostLimit),
+		buckets:   make(map[string]*bucket),
+		now:       time.Now,
+	}
+}
+
+// SetHostLimit overrides the rate and burst for a single host, such as api.github.com.
+func (l *HostLimiter) SetHostLimit(host string, rate float64, burst int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	host = normalizeHost(host)
+	l.overrides[host] = hostLimit{rate: rate, burst: max(burst, 1)}
+	delete(l.buckets, host)
+}
+
+// Wait blocks until a request to host is allowed or ctx is done.
+func (l *HostLimiter) Wait(ctx context.Context, host string) error {
+	wait := l.reserve(normalizeHost(host))
+	if wait <= 0 {
+		return nil
+	}
+	return sleep(ctx, wait)
+}
+
+// reserve takes a token for host and returns how long the caller must wait before using it.
+func (l *HostLimiter) reserve(host string) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	limit := hostLimit{rate: l.rate, burst: l.burst}
+	if override, ok := l.overrides[host]; ok {
+		limit = override
+	}
+	if limit.rate <= 0 {
+		return 0
+	}
+
+	now := l.now()
+	b, ok := l.buckets[host]
+	if !ok {
+		b = &bucket{tokens: float64(limit.burst), last: now, share: 1}
+		l.buckets[host] = b
+	}
+	rate := limit.rate * b.share
+
+	// Refill for the time elapsed since the last request, up to the burst size
+	b.tokens = min(b.tokens+now.Sub(b.last).Seconds()*rate, float64(limit.burst))
+	b.last = now
+
+	// Tokens may go negative: each waiting caller reserves the next free slot
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / rate * float64(time.Second))
+}
+
+// Observe adapts the rate of host to resp. A throttled response halves the rate the host gets,
+// down to minRateShare of its configured rate, and drops any saved-up burst; every other
+// response raises it by rateRecovery, up to the configured rate.
+func (l *HostLimiter) Observe(host string, resp *http.Response) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b, ok := l.buckets[normalizeHost(host)]
+	if !ok {
+		return
+	}
+	if !isThrottled(resp) {
+		b.share = min(b.share*rateRecovery, 1)
+		return
+	}
+
+	now := l.now()
+	if now.Sub(b.lastCut) < throttleCooldown {
+		return
+	}
+	b.lastCut = now
+	b.share = max(b.share/2, minRateShare)
+	b.tokens = min(b.tokens, 0)
+}
+
+// isThrottled reports whether resp rejected a request for exceeding a rate limit.
+func isThrottled(resp *http.Response) bool {
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusForbidden:
+		// GitHub reports an exhausted rate limit as 403 rather than 429
+		return resp.Header.Get("X-RateLimit-Remaining") == "0"
+	default:
+		return false
+	}
+}
+
+// normalizeHost folds case so EXAMPLE.com and example.com share a bucket.
+func normalizeHost(host string) string {
+	return strings.ToLower(host)
+}
@@ -0,0 +1,117 @@
+package importers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestHostLimiter_reserve(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter := NewHostLimiter(2, 2)
+	limiter.now = func() time.Time { return now }
+
+	tests := []struct {
+		name        string
+		host        string
+		advance     time.Duration
+		expected    time.Duration
+		description string
+	}{
+		{name: "first burst request", host: "example.com", expected: 0,
+			description: "should allow requests up to the burst immediately"},
+		{name: "second burst request", host: "EXAMPLE.com", expected: 0,
+			description: "should treat host names case-insensitively"},
+		{name: "over burst", host: "example.com", expected: 500 * time.Millisecond,
+			description: "should delay requests beyond the burst by 1/rate"},
+		{name: "queued behind", host: "example.com", expected: time.Second,
+			description: "should queue further requests behind earlier reservations"},
+		{name: "other host", host: "api.github.com", expected: 0,
+			description: "should keep a separate budget per host"},
+		{name: "refilled", host: "example.com", advance: 3 * time.Second, expected: 0,
+			description: "should refill tokens over time"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now = now.Add(tt.advance)
+			if got := limiter.reserve(normalizeHost(tt.host)); got != tt.expected {
+				t.Errorf("Expected wait %v, got %v for test: %s", tt.expected, got, tt.description)
+			}
+		})
+	}
+}
+
+func TestHostLimiter_Overrides(t *testing.T) {
+	limiter := NewHostLimiter(0, 1)
+	limiter.SetHostLimit("api.github.com", 1, 1)
+
+	if wait := limiter.reserve("example.com"); wait != 0 {
+		t.Errorf("Expected a zero rate to disable limiting, got wait %v", wait)
+	}
+	limiter.reserve("api.github.com")
+	if wait := limiter.reserve("api.github.com"); wait <= 0 {
+		t.Errorf("Expected the host override to limit requests, got wait %v", wait)
+	}
+}
+
+func TestHostLimiter_WaitCancelled(t *testing.T) {
+	limiter := NewHostLimiter(0.001, 1)
+	limiter.reserve("example.com")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := limiter.Wait(ctx, "example.com"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestHostLimiter_Observe(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter := NewHostLimiter(4, 1)
+	limiter.now = func() time.Time { return now }
+	throttled := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}}
+	accepted := &http.Response{StatusCode: http.StatusOK, Header: http.Header{}}
+
+	limiter.reserve("example.com")
+	limiter.Observe("example.com", throttled)
+	if wait := limiter.reserve("example.com"); wait != 500*time.Millisecond {
+		t.Errorf("Expected a throttled host to get half its rate, got wait %v", wait)
+	}
+
+	// Rejections of requests sent before the first one arrived count once
+	limiter.Observe("example.com", throttled)
+	if share := limiter.buckets["example.com"].share; share != 0.5 {
+		t.Errorf("Expected the rate to be cut once within the cooldown, got share %v", share)
+	}
+
+	for range 20 {
+		limiter.Observe("example.com", accepted)
+	}
+	if share := limiter.buckets["example.com"].share; share != 1 {
+		t.Errorf("Expected accepted requests to restore the configured rate, got share %v", share)
+	}
+}
+
+func TestHostLimiter_ObserveGitHubRateLimit(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter := NewHostLimiter(1, 1)
+	limiter.now = func() time.Time { return now }
+
+	for i := range 6 {
+		limiter.reserve("api.github.com")
+		limiter.Observe("api.github.com", &http.Response{
+			StatusCode: http.StatusForbidden,
+			Header:     http.Header{"X-Ratelimit-Remaining": {"0"}},
+		})
+		now = now.Add(time.Duration(i+1) * time.Minute)
+	}
+
+	b := limiter.buckets["api.github.com"]
+	if b.share != minRateShare {
+		t.Errorf("Expected repeated throttling to stop at %v of the rate, got %v", minRateShare, b.share)
+	}
+}
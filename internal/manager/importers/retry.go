@@ -0,0 +1,247 @@
+package importers
+
+import (
+	"context"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/code-sleuth/ike-go/pkg/util"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	// Default number of retries after the first attempt.
+	defaultMaxRetries = 3
+	// Default delay before the first retry; it doubles on every further retry.
+	defaultRetryBaseDelay = 500 * time.Millisecond
+	// Default cap on a single retry delay, including server-requested Retry-After delays.
+	defaultRetryMaxDelay = 30 * time.Second
+)
+
+// RetryPolicy controls how importer HTTP requests are retried after transient failures: network
+// errors, 429 Too Many Requests, 5xx responses, and GitHub's rate-limit 403s. Only idempotent
+// requests are retried.
+type RetryPolicy struct {
+	// MaxRetries is the number of retries after the first attempt; 0 disables retrying.
+	MaxRetries int
+	// BaseDelay is the delay before the first retry; each further retry doubles it.
+	BaseDelay time.Duration
+	// MaxDelay caps a single delay, including one requested by a Retry-After header.
+	MaxDelay time.Duration
+}
+
+// DefaultRetryPolicy returns the policy importers use unless SetRetryPolicy is called.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries: defaultMaxRetries,
+		BaseDelay:  defaultRetryBaseDelay,
+		MaxDelay:   defaultRetryMaxDelay,
+	}
+}
+
+// delay returns how long to wait before retry number attempt (starting at 0). A Retry-After
+// header on resp takes precedence over the exponential backoff; both are capped at MaxDelay.
+func (p RetryPolicy) delay(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if wait, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+			return min(wait, p.MaxDelay)
+		}
+		if wait, ok := rateLimitReset(resp); ok {
+			return min(wait, p.MaxDelay)
+		}
+	}
+
+	backoff := p.BaseDelay << attempt
+	if backoff <= 0 || backoff > p.MaxDelay {
+		backoff = p.MaxDelay
+	}
+
+	// Jitter spreads retries from concurrent workers over [backoff/2, backoff]
+	half := backoff / 2
+	return half + rand.N(half+1) // #nosec G404 -- jitter does not need a secure source
+}
+
+// retryAfter parses a Retry-After header given either in seconds or as an HTTP date.
+func retryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(time.Until(at), 0), true
+	}
+	return 0, false
+}
+
+// rateLimitReset returns the wait until a GitHub-style X-RateLimit-Reset time (epoch seconds)
+// when the response reports the rate limit as exhausted.
+func rateLimitReset(resp *http.Response) (time.Duration, bool) {
+	if resp.Header.Get("X-RateLimit-Remaining") != "0" {
+		return 0, false
+	}
+	reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return max(time.Until(time.Unix(reset, 0)), 0), true
+}
+
+// retryTransport is the HTTP layer shared by the importers. Before every attempt it waits on
+// the per-host rate limiter, and it retries transient failures according to policy before
+// handing the response to the importer.
+type retryTransport struct {
+	next    http.RoundTripper
+	policy  RetryPolicy
+	limiter *HostLimiter
+	logger  zerolog.Logger
+}
+
+// withRetries returns a copy of client whose transport retries according to policy. The caller's
+// client is left untouched.
+func withRetries(client *http.Client, policy RetryPolicy) *http.Client {
+	return withTransport(client, func(t *retryTransport) { t.policy = policy })
+}
+
+// withRateLimit returns a copy of client whose requests wait on limiter; nil disables limiting.
+func withRateLimit(client *http.Client, limiter *HostLimiter) *http.Client {
+	return withTransport(client, func(t *retryTransport) { t.limiter = limiter })
+}
+
+// withTransport returns a copy of client using a retryTransport adjusted by configure. Settings
+// already applied to client's retryTransport are kept.
+func withTransport(client *http.Client, configure func(t *retryTransport)) *http.Client {
+	transport := &retryTransport{
+		next:    client.Transport,
+		policy:  DefaultRetryPolicy(),
+		limiter: sharedLimiter,
+		logger:  util.NewLogger(zerolog.ErrorLevel),
+	}
+	if existing, ok := client.Transport.(*retryTransport); ok {
+		configured := *existing
+		transport = &configured
+	}
+	if transport.next == nil {
+		transport.next = http.DefaultTransport
+	}
+	configure(transport)
+
+	wrapped := *client
+	wrapped.Transport = transport
+	return &wrapped
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	if !isIdempotent(req) {
+		if err := t.wait(ctx, req); err != nil {
+			return nil, err
+		}
+		resp, err := roundTrip(t.next, req)
+		t.observe(req, resp)
+		return resp, err
+	}
+
+	for attempt := 0; ; attempt++ {
+		if err := t.wait(ctx, req); err != nil {
+			return nil, err
+		}
+		resp, err := roundTrip(t.next, req)
+		t.observe(req, resp)
+		if attempt >= t.policy.MaxRetries || !isTransient(resp, err) || ctx.Err() != nil {
+			return resp, err
+		}
+
+		// Give up with the last response rather than sleep past the caller's deadline
+		wait := t.policy.delay(attempt, resp)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+			return resp, err
+		}
+
+		retryReq, rewindErr := rewind(req)
+		if rewindErr != nil {
+			return resp, err
+		}
+
+		event := t.logger.Warn().Str("url", req.URL.String()).Int("attempt", attempt+1).Dur("delay", wait)
+		if resp != nil {
+			event = event.Int("status_code", resp.StatusCode)
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+		}
+		event.Err(err).Msg("Retrying transient HTTP failure")
+		retriesTotal.With(req.URL.Host).Inc()
+
+		if err := sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+		req = retryReq
+	}
+}
+
+// wait blocks until the rate limiter allows a request to req's host.
+func (t *retryTransport) wait(ctx context.Context, req *http.Request) error {
+	if t.limiter == nil {
+		return nil
+	}
+	return t.limiter.Wait(ctx, req.URL.Host)
+}
+
+// observe lets the rate limiter adapt to resp, if the request got one.
+func (t *retryTransport) observe(req *http.Request, resp *http.Response) {
+	if t.limiter == nil || resp == nil {
+		return
+	}
+	t.limiter.Observe(req.URL.Host, resp)
+}
+
+// isIdempotent reports whether req can safely be sent again.
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+	default:
+		return false
+	}
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// isTransient reports whether a response or error is worth retrying.
+func isTransient(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return isThrottled(resp) || resp.StatusCode >= http.StatusInternalServerError
+}
+
+// rewind returns req with a fresh body for another attempt.
+func rewind(req *http.Request) (*http.Request, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return req, nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	retryReq := req.Clone(req.Context())
+	retryReq.Body = body
+	return retryReq, nil
+}
+
+// sleep waits for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,196 @@
+package importers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testRetryPolicy() RetryPolicy {
+	return RetryPolicy{MaxRetries: 3, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+}
+
+func TestRetryTransport(t *testing.T) {
+	tests := []struct {
+		name             string
+		method           string
+		failures         int
+		failureStatus    int
+		expectedStatus   int
+		expectedRequests int32
+		description      string
+	}{
+		{
+			name:             "recovers from server errors",
+			method:           http.MethodGet,
+			failures:         2,
+			failureStatus:    http.StatusServiceUnavailable,
+			expectedStatus:   http.StatusOK,
+			expectedRequests: 3,
+			description:      "should retry 5xx responses until the request succeeds",
+		},
+		{
+			name:             "recovers from rate limiting",
+			method:           http.MethodGet,
+			failures:         1,
+			failureStatus:    http.StatusTooManyRequests,
+			expectedStatus:   http.StatusOK,
+			expectedRequests: 2,
+			description:      "should retry 429 responses",
+		},
+		{
+			name:             "gives up after max retries",
+			method:           http.MethodGet,
+			failures:         10,
+			failureStatus:    http.StatusBadGateway,
+			expectedStatus:   http.StatusBadGateway,
+			expectedRequests: 4,
+			description:      "should return the last response once retries are exhausted",
+		},
+		{
+			name:             "does not retry client errors",
+			method:           http.MethodGet,
+			failures:         1,
+			failureStatus:    http.StatusNotFound,
+			expectedStatus:   http.StatusNotFound,
+			expectedRequests: 1,
+			description:      "should not retry responses that will not change",
+		},
+		{
+			name:             "does not retry non-idempotent requests",
+			method:           http.MethodPost,
+			failures:         1,
+			failureStatus:    http.StatusServiceUnavailable,
+			expectedStatus:   http.StatusServiceUnavailable,
+			expectedRequests: 1,
+			description:      "should only retry requests that are safe to repeat",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				if int(requests.Add(1)) <= tt.failures {
+					w.WriteHeader(tt.failureStatus)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			client := withRetries(&http.Client{}, testRetryPolicy())
+			req, err := http.NewRequestWithContext(context.Background(), tt.method, server.URL,
+				strings.NewReader(""))
+			if err != nil {
+				t.Fatalf("Failed to create request: %v", err)
+			}
+			if tt.method == http.MethodGet {
+				req.Body = nil
+			}
+
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatalf("Unexpected error for test %s: %v", tt.description, err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d for test: %s", tt.expectedStatus, resp.StatusCode, tt.description)
+			}
+			if requests.Load() != tt.expectedRequests {
+				t.Errorf("Expected %d requests, got %d for test: %s",
+					tt.expectedRequests, requests.Load(), tt.description)
+			}
+		})
+	}
+}
+
+func TestRetryTransport_StopsAtDeadline(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	policy := testRetryPolicy()
+	policy.MaxDelay = time.Minute
+	client := withRetries(&http.Client{}, policy)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Expected the last response instead of an error, got %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusServiceUnavailable || requests.Load() != 1 {
+		t.Errorf("Expected a single 503 without waiting past the deadline, got %d after %d requests",
+			resp.StatusCode, requests.Load())
+	}
+}
+
+func TestRetryPolicy_delay(t *testing.T) {
+	policy := RetryPolicy{MaxRetries: 3, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+
+	tests := []struct {
+		name        string
+		attempt     int
+		header      http.Header
+		min, max    time.Duration
+		description string
+	}{
+		{
+			name:        "first retry",
+			attempt:     0,
+			min:         50 * time.Millisecond,
+			max:         100 * time.Millisecond,
+			description: "should jitter the base delay",
+		},
+		{
+			name:        "third retry",
+			attempt:     2,
+			min:         200 * time.Millisecond,
+			max:         400 * time.Millisecond,
+			description: "should double the delay per attempt",
+		},
+		{
+			name:        "capped",
+			attempt:     10,
+			min:         500 * time.Millisecond,
+			max:         time.Second,
+			description: "should never exceed the maximum delay",
+		},
+		{
+			name:        "retry after",
+			attempt:     0,
+			header:      http.Header{"Retry-After": []string{"2"}},
+			min:         time.Second,
+			max:         time.Second,
+			description: "should honour Retry-After up to the maximum delay",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{Header: tt.header}
+			if resp.Header == nil {
+				resp.Header = http.Header{}
+			}
+
+			got := policy.delay(tt.attempt, resp)
+			if got < tt.min || got > tt.max {
+				t.Errorf("Expected delay in [%v, %v], got %v for test: %s", tt.min, tt.max, got, tt.description)
+			}
+		})
+	}
+}
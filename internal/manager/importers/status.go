@@ -0,0 +1,43 @@
+package importers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+var (
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrNotFound     = errors.New("not found")
+)
+
+// StatusError is an unexpected HTTP status from a source. It matches ErrUnauthorized,
+// ErrForbidden, and ErrNotFound with errors.Is, so callers can tell a missing or invalid token
+// from content that no longer exists.
+type StatusError struct {
+	StatusCode int
+	URL        string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s returned %d %s", e.URL, e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// HTTPStatus returns the status code, so callers outside this package can classify the error.
+func (e *StatusError) HTTPStatus() int {
+	return e.StatusCode
+}
+
+// Is reports whether target is the sentinel error for the status code.
+func (e *StatusError) Is(target error) bool {
+	switch e.StatusCode {
+	case http.StatusUnauthorized:
+		return target == ErrUnauthorized
+	case http.StatusForbidden:
+		return target == ErrForbidden
+	case http.StatusNotFound:
+		return target == ErrNotFound
+	}
+	return false
+}
@@ -0,0 +1,197 @@
+package importers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
+	"github.com/code-sleuth/ike-go/pkg/dialect"
+	"github.com/code-sleuth/ike-go/pkg/util"
+
+	"github.com/google/uuid"
+)
+
+// dbExecutor is implemented by both *sql.DB and *sql.Tx, so record helpers can run
+// standalone or as part of an item's import transaction.
+type dbExecutor interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// withTx runs fn inside a transaction, committing when it succeeds and rolling back otherwise,
+// so an item's source and download rows are written together or not at all.
+func withTx(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return errors.Join(err, rollbackErr)
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// findSourceID returns the ID of the live source already recorded for rawURL, so retrying
+// an item reuses its source instead of creating a duplicate.
+func findSourceID(ctx context.Context, db dbExecutor, rawURL string) (string, bool, error) {
+	var sourceID string
+	err := db.QueryRowContext(ctx,
+		`SELECT id FROM sources WHERE raw_url = ? AND deleted_at IS NULL ORDER BY created_at LIMIT 1`, rawURL).
+		Scan(&sourceID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return sourceID, true, nil
+}
+
+// findDownloadID returns the ID of the newest download of the source with sourceID that holds
+// the body with hash and has not been transformed yet. An item retried after its transaction
+// committed reuses that download instead of recording a second one.
+func findDownloadID(ctx context.Context, db dbExecutor, sourceID, hash string) (string, bool, error) {
+	var downloadID string
+	err := db.QueryRowContext(ctx, `SELECT d.id FROM downloads d WHERE d.source_id = ? AND d.content_hash = ?
+			  AND NOT EXISTS (SELECT 1 FROM documents doc WHERE doc.download_id = d.id)
+			  ORDER BY d.attempted_at DESC LIMIT 1`, sourceID, hash).Scan(&downloadID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return downloadID, true, nil
+}
+
+// upsertSource records the live source for rawURL and returns its ID. The URL is normalized and its
+// credentials, such as an access_token query parameter, moved to the source's credentials first, so
+// the source can be fetched again without its URL carrying them. At most one live source exists per
+// normalized URL, so importing the same content again, even with new credentials, attaches new
+// downloads to the existing source rather than creating another. New sources are placed in the
+// collection carried by ctx; an existing source keeps its collection. The owners carried by ctx
+// are recorded on the source either way.
+func upsertSource(ctx context.Context, db dbExecutor, rawURL, format string) (string, error) {
+	normalized, err := util.NormalizeURL(rawURL)
+	if err != nil {
+		return "", err
+	}
+	normalized, secrets := util.SplitURLSecrets(normalized)
+	parsedURL, err := url.Parse(normalized)
+	if err != nil {
+		return "", err
+	}
+
+	now := dialect.SQLite.FormatTime(time.Now())
+	// The collection is recorded on first use
+	collection := interfaces.CollectionFromContext(ctx)
+	_, err = db.ExecContext(ctx, `INSERT INTO collections (name, created_at) VALUES (?, ?)
+			  ON CONFLICT (name) DO NOTHING`, collection, now)
+	if err != nil {
+		return "", err
+	}
+
+	query := `INSERT INTO sources (id, raw_url, scheme, host, path, query, active_domain, format, collection,
+			  created_at, updated_at)
+			  VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			  ON CONFLICT (raw_url) WHERE deleted_at IS NULL DO UPDATE SET updated_at = excluded.updated_at`
+
+	_, err = db.ExecContext(ctx, query, uuid.New().String(), normalized, parsedURL.Scheme, parsedURL.Host,
+		parsedURL.Path, parsedURL.RawQuery, 1, format, collection, now, now)
+	if err != nil {
+		return "", err
+	}
+
+	sourceID, found, err := findSourceID(ctx, db, normalized)
+	if err != nil {
+		return "", err
+	}
+	if !found {
+		return "", sql.ErrNoRows
+	}
+	if secrets != "" {
+		_, err = db.ExecContext(ctx, `INSERT INTO source_credentials (source_url, url_secrets, created_at, updated_at)
+				  VALUES (?, ?, ?, ?)
+				  ON CONFLICT (source_url) DO UPDATE SET url_secrets = excluded.url_secrets,
+				  updated_at = excluded.updated_at`, normalized, secrets, now, now)
+		if err != nil {
+			return "", err
+		}
+	}
+	if err := recordOwnership(ctx, db, sourceID, "", interfaces.OwnersFromContext(ctx)); err != nil {
+		return "", err
+	}
+	return sourceID, nil
+}
+
+// recordOwnership records owners as owners of the source with sourceID, keeping those recorded
+// before, and sets its author email unless authorEmail is empty.
+func recordOwnership(ctx context.Context, db dbExecutor, sourceID, authorEmail string, owners []string) error {
+	if authorEmail != "" {
+		_, err := db.ExecContext(ctx, `UPDATE sources SET author_email = ? WHERE id = ?`, authorEmail, sourceID)
+		if err != nil {
+			return err
+		}
+	}
+
+	now := dialect.SQLite.FormatTime(time.Now())
+	for _, owner := range owners {
+		if owner = strings.TrimSpace(owner); owner == "" {
+			continue
+		}
+		_, err := db.ExecContext(ctx, `INSERT INTO source_owners (source_id, owner, created_at) VALUES (?, ?, ?)
+			  ON CONFLICT (source_id, owner) DO NOTHING`, sourceID, owner, now)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// aclLabels returns the access-control labels carried by ctx or, when it carries none, derived,
+// the labels the importer read from the source itself.
+func aclLabels(ctx context.Context, derived []string) []string {
+	if given := interfaces.ACLLabelsFromContext(ctx); len(given) > 0 {
+		return given
+	}
+	return derived
+}
+
+// recordACL replaces the access-control labels of the source with sourceID with labels. A
+// source left without labels is returned by every search.
+func recordACL(ctx context.Context, db dbExecutor, sourceID string, labels []string) error {
+	if _, err := db.ExecContext(ctx, `DELETE FROM source_acl WHERE source_id = ?`, sourceID); err != nil {
+		return err
+	}
+
+	now := dialect.SQLite.FormatTime(time.Now())
+	for _, label := range cleanLabels(labels) {
+		_, err := db.ExecContext(ctx, `INSERT INTO source_acl (source_id, label, created_at) VALUES (?, ?, ?)`,
+			sourceID, label, now)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cleanLabels returns labels trimmed, without empty or repeated ones.
+func cleanLabels(labels []string) []string {
+	var cleaned []string
+	for _, label := range labels {
+		if label = strings.TrimSpace(label); label != "" && !slices.Contains(cleaned, label) {
+			cleaned = append(cleaned, label)
+		}
+	}
+	return cleaned
+}
@@ -0,0 +1,144 @@
+package importers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
+	"github.com/code-sleuth/ike-go/internal/manager/plugins"
+	"github.com/code-sleuth/ike-go/internal/manager/services"
+	"github.com/code-sleuth/ike-go/internal/manager/testutil"
+)
+
+// feedImporter records the source of every URL it is asked to import with upsertSource, and the
+// URLs themselves, and reports nothing changed so the engine stops after the import stage.
+type feedImporter struct {
+	fetched  []string
+	sourceID string
+}
+
+func (f *feedImporter) Import(ctx context.Context, sourceURL string, db *sql.DB) (*interfaces.ImportResult, error) {
+	f.fetched = append(f.fetched, sourceURL)
+	sourceID, err := upsertSource(ctx, db, sourceURL, "json")
+	if err != nil {
+		return nil, err
+	}
+	f.sourceID = sourceID
+	return nil, interfaces.ErrNoChanges
+}
+
+func (f *feedImporter) GetSourceType() string         { return "feed" }
+func (f *feedImporter) ValidateSource(_ string) error { return nil }
+
+func TestUpsertSource_ReprocessWithToken_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db := testutil.SetupTestDB(t)
+	defer testutil.CleanupTestDB(t, db)
+
+	const secret = "s3cret-feed-token"
+	sourceURL := "https://feeds.example.com/posts.json?access_token=" + secret + "&page=2"
+	importer := &feedImporter{}
+	engine := services.NewProcessingEngine()
+	if err := engine.RegisterImporter(importer); err != nil {
+		t.Fatalf("Failed to register importer: %v", err)
+	}
+	options := &interfaces.ProcessingOptions{EmbeddingModel: "text-embedding-3-small"}
+	ctx := context.Background()
+
+	if err := engine.ProcessSource(ctx, sourceURL, options, db); err != nil {
+		t.Fatalf("Failed to import source: %v", err)
+	}
+
+	var rawURL string
+	if err := db.QueryRow(`SELECT raw_url FROM sources WHERE id = ?`, importer.sourceID).Scan(&rawURL); err != nil {
+		t.Fatalf("Failed to query source: %v", err)
+	}
+	if strings.Contains(rawURL, secret) || strings.Contains(rawURL, "REDACTED") {
+		t.Errorf("Expected the token to be stripped from the stored URL, got %s", rawURL)
+	}
+
+	if _, err := engine.Reprocess(ctx, importer.sourceID, services.ReprocessImport, options, db); err != nil {
+		t.Fatalf("Failed to reprocess source: %v", err)
+	}
+	if len(importer.fetched) != 2 || !strings.Contains(importer.fetched[1], "access_token="+secret) {
+		t.Errorf("Expected the reprocess to fetch the URL with its token, got %v", importer.fetched)
+	}
+
+	_, err := db.Exec(`UPDATE sources SET raw_url = ? WHERE id = ?`,
+		"https://feeds.example.com/posts.json?access_token=REDACTED", importer.sourceID)
+	if err != nil {
+		t.Fatalf("Failed to redact source URL: %v", err)
+	}
+	_, err = engine.Reprocess(ctx, importer.sourceID, services.ReprocessImport, options, db)
+	if !errors.Is(err, services.ErrRedactedSourceURL) {
+		t.Errorf("Expected ErrRedactedSourceURL for a redacted URL, got %v", err)
+	}
+	if len(importer.fetched) != 2 {
+		t.Errorf("Expected a redacted URL not to be fetched, got %v", importer.fetched)
+	}
+}
+
+func TestWithTx_RollsBackSource_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db := testutil.SetupTestDB(t)
+	defer testutil.CleanupTestDB(t, db)
+
+	errCreateDownload := errors.New("create download failed")
+	err := withTx(context.Background(), db, func(tx *sql.Tx) error {
+		if _, err := upsertSource(context.Background(), tx, "https://example.com/posts/1", "json"); err != nil {
+			return err
+		}
+		return errCreateDownload
+	})
+	if !errors.Is(err, errCreateDownload) {
+		t.Fatalf("Expected the create download error, got %v", err)
+	}
+
+	if count := testutil.GetRecordCount(t, db, "sources"); count != 0 {
+		t.Errorf("Expected the source to be rolled back, got %d sources", count)
+	}
+	if count := testutil.GetRecordCount(t, db, "downloads"); count != 0 {
+		t.Errorf("Expected no downloads, got %d", count)
+	}
+}
+
+func TestPluginImporter_importItem_Retry_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db := testutil.SetupTestDB(t)
+	defer testutil.CleanupTestDB(t, db)
+
+	importer := NewPluginImporter(nil)
+	item := plugins.Item{URL: "https://example.com/posts/1", Body: `{"title": "First post"}`, Format: "json"}
+
+	first, err := importer.importItem(context.Background(), item, db)
+	if err != nil {
+		t.Fatalf("Failed to import item: %v", err)
+	}
+	retried, err := importer.importItem(context.Background(), item, db)
+	if err != nil {
+		t.Fatalf("Failed to retry item: %v", err)
+	}
+
+	if retried.SourceID != first.SourceID || retried.DownloadID != first.DownloadID {
+		t.Errorf("Expected the retry to reuse source %s and download %s, got %s and %s",
+			first.SourceID, first.DownloadID, retried.SourceID, retried.DownloadID)
+	}
+	if count := testutil.GetRecordCount(t, db, "sources"); count != 1 {
+		t.Errorf("Expected 1 source, got %d", count)
+	}
+	if count := testutil.GetRecordCount(t, db, "downloads"); count != 1 {
+		t.Errorf("Expected 1 download, got %d", count)
+	}
+}
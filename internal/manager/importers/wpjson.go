@@ -6,17 +6,22 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
+	"github.com/code-sleuth/ike-go/pkg/dialect"
+	"github.com/code-sleuth/ike-go/pkg/httpclient"
 	"github.com/code-sleuth/ike-go/pkg/util"
 
 	"github.com/google/uuid"
 	"github.com/rs/zerolog"
+	"golang.org/x/sync/errgroup"
 )
 
 const (
@@ -30,6 +35,15 @@ const (
 	defaultConcurrency = 5
 )
 
+// Pagination headers of WordPress REST API listings, and the error code of a page past the last.
+const (
+	wpTotalHeader      = "X-WP-Total"
+	wpTotalPagesHeader = "X-WP-TotalPages"
+	wpInvalidPageCode  = "rest_post_invalid_page_number"
+	// wpEmbedParam in an endpoint's query has posts fetched with their linked resources embedded
+	wpEmbedParam = "_embed"
+)
+
 var (
 	ErrNotWordPressAPI          = errors.New("not a WordPress JSON API endpoint")
 	ErrWPImportCompleted        = errors.New("import completed with errors")
@@ -44,21 +58,45 @@ type WPJSONImporter struct {
 	perPage     int
 	maxPages    int
 	concurrency int
+	auth        WPAuth
 	logger      zerolog.Logger
 }
 
-// NewWPJSONImporter creates a new WordPress JSON importer.
-func NewWPJSONImporter() *WPJSONImporter {
-	logger := util.NewLogger(zerolog.InfoLevel)
-	return &WPJSONImporter{
-		client: &http.Client{
-			Timeout: defaultWPHTTPTimeout * time.Second,
-		},
+// NewWPJSONImporter creates a new WordPress JSON importer configured by opts. Without WithAuth,
+// requests carry the credentials from the environment.
+func NewWPJSONImporter(opts ...Option) *WPJSONImporter {
+	o := newOptions(opts)
+	logger := util.ComponentLogger("importers.wpjson", zerolog.InfoLevel)
+	if o.logger != nil {
+		logger = *o.logger
+	}
+
+	client := o.client
+	if client == nil {
+		client = httpclient.New(defaultWPHTTPTimeout * time.Second)
+	}
+
+	w := &WPJSONImporter{
+		client:      o.configureClient(withRetries(client, DefaultRetryPolicy())),
 		perPage:     defaultPerPage,
 		maxPages:    maxPages,
 		concurrency: defaultConcurrency,
+		auth:        WPAuthFromEnv(),
 		logger:      logger,
 	}
+	if o.perPage > 0 {
+		w.perPage = o.perPage
+	}
+	if o.maxPages > 0 {
+		w.maxPages = o.maxPages
+	}
+	if o.concurrency > 0 {
+		w.concurrency = o.concurrency
+	}
+	if o.auth != nil {
+		w.auth = *o.auth
+	}
+	return w
 }
 
 // GetSourceType returns the source type this importer handles.
@@ -92,120 +130,255 @@ func (w *WPJSONImporter) Import(ctx context.Context, sourceURL string, db *sql.D
 
 	w.logger.Info().Str("Starting WP-JSON import for", sourceURL)
 
-	// Get post IDs from the endpoint
-	postIDs, err := w.getPostIDs(ctx, sourceURL)
-	if err != nil {
-		w.logger.Error().Err(err).Msg("failed to get post IDs")
-		return nil, err
+	// Get post IDs from the endpoint, unless the import is limited to specific posts
+	var postIDs []int
+	if paths := interfaces.PathsFromContext(ctx); len(paths) > 0 {
+		postIDs = postIDsFromPaths(paths)
+		if len(postIDs) == 0 {
+			return nil, interfaces.ErrNoMatchingPaths
+		}
+	} else {
+		var err error
+		postIDs, err = w.getPostIDs(ctx, sourceURL)
+		if err != nil {
+			w.logger.Error().Err(err).Msg("failed to get post IDs")
+			return nil, err
+		}
 	}
 
 	w.logger.Info().Int("Found posts to import", len(postIDs))
+	interfaces.ReportProgress(ctx, interfaces.ProgressEvent{
+		Stage: interfaces.StageDiscovered,
+		Item:  sourceURL,
+		Count: len(postIDs),
+	})
 
 	// Process posts concurrently
-	results := make(chan *interfaces.ImportResult, len(postIDs))
+	type postResult struct {
+		key    string
+		result *interfaces.ImportResult
+	}
+	results := make(chan postResult, len(postIDs))
 	semaphore := make(chan struct{}, w.concurrency)
+	checkpoint := interfaces.CheckpointFromContext(ctx)
 
 	for _, postID := range postIDs {
 		go func(id int) {
 			semaphore <- struct{}{}        // Acquire semaphore
 			defer func() { <-semaphore }() // Release semaphore
 
+			// Skip posts an interrupted run already imported
+			key := strconv.Itoa(id)
+			if result, ok := checkpoint.Imported(key); ok {
+				interfaces.ReportProgress(ctx, interfaces.ProgressEvent{
+					Stage: interfaces.StageImported, Item: key, Count: 1,
+				})
+				results <- postResult{key: key, result: result}
+				return
+			}
+
 			result := w.importPost(ctx, sourceURL, id, db)
-			results <- result
+			if result.Error == nil {
+				if err := checkpoint.RecordImport(ctx, key, result); err != nil {
+					w.logger.Warn().Err(err).Int("post_id", id).Msg("Failed to record import checkpoint")
+				}
+				interfaces.ReportProgress(ctx, interfaces.ProgressEvent{
+					Stage: interfaces.StageImported, Item: key, Count: 1,
+				})
+			} else {
+				interfaces.ReportProgress(ctx, interfaces.ProgressEvent{
+					Stage: interfaces.StageFailed, Item: key, Count: 1, Err: result.Error,
+				})
+			}
+			results <- postResult{key: key, result: result}
 		}(postID)
 	}
 
 	// Collect results
-	var errorsList []error
+	report := &interfaces.ImportReport{}
 	var lastResult *interfaces.ImportResult
 
 	for i := 0; i < len(postIDs); i++ {
-		result := <-results
-		if result.Error != nil {
-			errorsList = append(errorsList, result.Error)
+		posted := <-results
+		if posted.result.Error != nil {
+			report.Add(interfaces.ItemReport{
+				Key:    posted.key,
+				Status: interfaces.ItemFailed,
+				Err:    fmt.Errorf("post %s: %w", posted.key, posted.result.Error),
+			})
 		} else {
-			lastResult = result
+			lastResult = posted.result
+			report.Add(interfaces.ItemReport{Key: posted.key, Status: interfaces.ItemImported})
 		}
 	}
 
-	if len(errorsList) > 0 {
-		log.Printf("Import completed with %d errorsList out of %d posts", len(errorsList), len(postIDs))
+	if report.Failed > 0 {
+		log.Printf("Import completed with %d errorsList out of %d posts", report.Failed, len(postIDs))
 		// Return the last successful result, but include error info
 		if lastResult != nil {
-			w.logger.Error().Int("import completed with total errors", len(errorsList))
-			w.logger.Error().Err(errorsList[0]).Msg("import completed with errors")
+			w.logger.Error().Err(report.Err()).Int("error_count", report.Failed).Msg("import completed with errors")
 
-			lastResult.Error = ErrWPImportCompleted
+			lastResult.Error = fmt.Errorf("%w: %w", ErrWPImportCompleted, report.Err())
 		} else {
-			err := fmt.Errorf("all imports failed, first error: %w", errorsList[0])
+			err := fmt.Errorf("all imports failed: %w", report.Err())
 			w.logger.Err(err).Msg("all imports failed")
 			return nil, err
 		}
 	}
 
-	w.logger.Info().Int("WP-JSON import completed successfully for %d posts", len(postIDs)-len(errorsList))
+	w.logger.Info().Int("WP-JSON import completed successfully for %d posts", report.Imported)
 
 	// Return the last successful result (all posts are imported separately)
 	if lastResult != nil {
+		lastResult.Report = report
 		return lastResult, nil
 	}
 
 	return nil, ErrNoPostsImported
 }
 
-// getPostIDs fetches all post IDs from the WordPress JSON API.
+// postIDsFromPaths parses the post IDs an import is limited to, ignoring anything that is not one.
+func postIDsFromPaths(paths []string) []int {
+	var ids []int
+	for _, path := range paths {
+		if id, err := strconv.Atoi(path); err == nil && id > 0 {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// getPostIDs fetches all post IDs from the WordPress JSON API. The first page's X-WP-TotalPages
+// header says how many pages there are, so the rest are fetched concurrently; endpoints behind
+// proxies that strip the header are paged through until WordPress reports the page past the last.
 func (w *WPJSONImporter) getPostIDs(ctx context.Context, baseURL string) ([]int, error) {
-	var allPostIDs []int
-	page := 1
+	first, err := w.getPostPage(ctx, baseURL, 1)
+	if err != nil {
+		return nil, err
+	}
+	if !first.paged {
+		return w.probePostPages(ctx, baseURL, first)
+	}
 
-	for page <= w.maxPages {
-		// Build URL with pagination
-		reqURL := fmt.Sprintf("%s?page=%d&per_page=%d", baseURL, page, w.perPage)
+	pages := first.totalPages
+	if pages > w.maxPages {
+		w.logger.Warn().Int("total_pages", pages).Int("max_pages", w.maxPages).
+			Msg("endpoint has more pages than the import fetches")
+		pages = w.maxPages
+	}
 
-		req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
-		if err != nil {
-			w.logger.Error().Err(err).Msg("failed to create request")
-			return nil, err
-		}
+	pageIDs := make([][]int, max(pages, 1))
+	pageIDs[0] = first.ids
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.SetLimit(w.concurrency)
+	for page := 2; page <= pages; page++ {
+		group.Go(func() error {
+			// Posts deleted since the first page can leave later pages past the end, which is not an error
+			result, err := w.getPostPage(groupCtx, baseURL, page)
+			pageIDs[page-1] = result.ids
+			return err
+		})
+	}
+	if err := group.Wait(); err != nil {
+		return nil, err
+	}
 
-		resp, err := w.client.Do(req)
+	allPostIDs := make([]int, 0, first.total)
+	for _, ids := range pageIDs {
+		allPostIDs = append(allPostIDs, ids...)
+	}
+	return allPostIDs, nil
+}
+
+// probePostPages fetches the pages after first one at a time, until a page is past the end or empty.
+func (w *WPJSONImporter) probePostPages(ctx context.Context, baseURL string, first postPage) ([]int, error) {
+	allPostIDs := first.ids
+	last := first
+	for page := 2; page <= w.maxPages && !last.end && len(last.ids) > 0; page++ {
+		var err error
+		last, err = w.getPostPage(ctx, baseURL, page)
 		if err != nil {
-			w.logger.Error().Err(err).Msg("request failed")
 			return nil, err
 		}
-		defer resp.Body.Close()
+		allPostIDs = append(allPostIDs, last.ids...)
+	}
+	return allPostIDs, nil
+}
 
-		// WordPress returns 400 when no more pages
-		if resp.StatusCode == http.StatusBadRequest {
-			break
-		}
+// postPage is one page of an endpoint's post listing.
+type postPage struct {
+	ids []int
+	// paged is set when the response carried X-WP-TotalPages, giving totalPages and total
+	paged      bool
+	totalPages int
+	total      int
+	// end is set when the page is past the last one
+	end bool
+}
 
-		if resp.StatusCode != http.StatusOK {
-			w.logger.Error().Int("status code", resp.StatusCode).Msg("unexpected status code")
-			return nil, err
-		}
+// getPostPage fetches the post IDs on one page of the listing at baseURL. A page past the last
+// returns a postPage with end set; any other failure, including other 400s, is an error.
+func (w *WPJSONImporter) getPostPage(ctx context.Context, baseURL string, page int) (postPage, error) {
+	pageURL, err := url.Parse(baseURL)
+	if err != nil {
+		w.logger.Error().Err(err).Msg("invalid URL")
+		return postPage{}, err
+	}
+	query := pageURL.Query()
+	query.Set("page", strconv.Itoa(page))
+	query.Set("per_page", strconv.Itoa(w.perPage))
+	// Listing requests only need the IDs, not the embedded resources of every post
+	query.Del(wpEmbedParam)
+	pageURL.RawQuery = query.Encode()
+	reqURL := pageURL.String()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		w.logger.Error().Err(err).Msg("failed to create request")
+		return postPage{}, err
+	}
+	w.authorize(req)
 
-		var posts []map[string]interface{}
-		if err := json.NewDecoder(resp.Body).Decode(&posts); err != nil {
-			w.logger.Error().Err(err).Msg("failed to decode response")
-			return nil, err
-		}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		w.logger.Error().Err(err).Msg("request failed")
+		return postPage{}, err
+	}
+	defer resp.Body.Close()
 
-		if len(posts) == 0 {
-			break
+	// WordPress answers a page past the last with a 400 carrying this code
+	if resp.StatusCode == http.StatusBadRequest {
+		var body struct {
+			Code string `json:"code"`
 		}
-
-		// Extract post IDs
-		for _, post := range posts {
-			if id, ok := post["id"].(float64); ok {
-				allPostIDs = append(allPostIDs, int(id))
-			}
+		if err := json.NewDecoder(resp.Body).Decode(&body); err == nil && body.Code == wpInvalidPageCode {
+			return postPage{end: true}, nil
 		}
+	}
+	if resp.StatusCode != http.StatusOK {
+		w.logger.Error().Int("status code", resp.StatusCode).Int("page", page).Msg("unexpected status code")
+		return postPage{}, fmt.Errorf("%w: %w", ErrUnexpectedStatusCode,
+			&StatusError{StatusCode: resp.StatusCode, URL: reqURL})
+	}
 
-		page++
+	var posts []map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&posts); err != nil {
+		w.logger.Error().Err(err).Msg("failed to decode response")
+		return postPage{}, err
 	}
 
-	return allPostIDs, nil
+	result := postPage{ids: make([]int, 0, len(posts))}
+	for _, post := range posts {
+		if id, ok := post["id"].(float64); ok {
+			result.ids = append(result.ids, int(id))
+		}
+	}
+	if totalPages, err := strconv.Atoi(resp.Header.Get(wpTotalPagesHeader)); err == nil {
+		result.paged = true
+		result.totalPages = totalPages
+		result.total, _ = strconv.Atoi(resp.Header.Get(wpTotalHeader))
+	}
+	return result, nil
 }
 
 // importPost imports a single post by ID.
@@ -216,15 +389,16 @@ func (w *WPJSONImporter) importPost(
 	db *sql.DB,
 ) *interfaces.ImportResult {
 	// Build URL for individual post
-	postURL := fmt.Sprintf("%s/%d", baseURL, postID)
+	postURL, fetchURL := wpPostURL(baseURL, postID)
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, postURL, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fetchURL, nil)
 	if err != nil {
 		w.logger.Error().Err(err).Int("failed to create request for post id", postID)
 		return &interfaces.ImportResult{
 			Error: err,
 		}
 	}
+	w.authorize(req)
 
 	resp, err := w.client.Do(req)
 	if err != nil {
@@ -238,32 +412,55 @@ func (w *WPJSONImporter) importPost(
 	if resp.StatusCode != http.StatusOK {
 		w.logger.Error().Int("status code for post id", postID).Int("unexpected status code", resp.StatusCode)
 		return &interfaces.ImportResult{
-			Error: ErrUnexpectedPostStatusCode,
+			Error: fmt.Errorf("%w: %w", ErrUnexpectedPostStatusCode,
+				&StatusError{StatusCode: resp.StatusCode, URL: postURL}),
 		}
 	}
 
-	// Read response body
-	var postData map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&postData); err != nil {
-		w.logger.Error().Err(err).Int("failed to decode response for post id", postID)
+	// Read response body, transcoding posts of sites that don't serve UTF-8
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		w.logger.Error().Err(err).Int("failed to read response for post id", postID)
 		return &interfaces.ImportResult{
 			Error: err,
 		}
 	}
-
-	// Create source record
-	sourceID, err := w.createSource(ctx, postURL, db)
-	if err != nil {
-		w.logger.Error().Err(err).Int("failed to create source for post id", postID)
+	text, _ := util.ToUTF8(body, resp.Header.Get("Content-Type"))
+	var postData map[string]interface{}
+	if err := json.Unmarshal([]byte(text), &postData); err != nil {
+		w.logger.Error().Err(err).Int("failed to decode response for post id", postID)
 		return &interfaces.ImportResult{
 			Error: err,
 		}
 	}
 
-	// Create download record
-	downloadID, err := w.createDownload(ctx, sourceID, resp.StatusCode, resp.Header, postData, db)
+	// Create source and download records atomically
+	var sourceID, downloadID string
+	err = withTx(ctx, db, func(tx *sql.Tx) error {
+		var err error
+		sourceID, err = w.createSource(ctx, postURL, tx)
+		if err != nil {
+			w.logger.Error().Err(err).Int("failed to create source for post id", postID)
+			return err
+		}
+
+		if err := recordOwnership(ctx, tx, sourceID, "", wpPostOwners(postData)); err != nil {
+			w.logger.Error().Err(err).Int("failed to record owners for post id", postID)
+			return err
+		}
+		if err := recordACL(ctx, tx, sourceID, aclLabels(ctx, wpPostLabels(postData))); err != nil {
+			w.logger.Error().Err(err).Int("failed to record access labels for post id", postID)
+			return err
+		}
+
+		downloadID, err = w.createDownload(ctx, sourceID, resp.StatusCode, resp.Header, postData, tx)
+		if err != nil {
+			w.logger.Error().Err(err).Int("failed to create download for post id", postID)
+			return err
+		}
+		return nil
+	})
 	if err != nil {
-		w.logger.Error().Err(err).Int("failed to create download for post id", postID)
 		return &interfaces.ImportResult{
 			Error: err,
 		}
@@ -275,44 +472,90 @@ func (w *WPJSONImporter) importPost(
 	}
 }
 
-// createSource creates a source record in the database.
-func (w *WPJSONImporter) createSource(ctx context.Context, postURL string, db *sql.DB) (string, error) {
-	parsedURL, err := url.Parse(postURL)
+// wpPostURL returns the URL of the post with postID in the listing at baseURL, and the URL to
+// fetch it from. The listing's query, such as a status filter selecting drafts, only applies to
+// the listing, except for _embed, which has the post fetched with its author, featured media,
+// and terms embedded. Sources are keyed by the post's URL either way.
+func wpPostURL(baseURL string, postID int) (string, string) {
+	parsed, err := url.Parse(baseURL)
 	if err != nil {
-		w.logger.Error().Err(err).Str("post URL", postURL).Msg("failed to parse URL")
-		return "", err
+		postURL := strings.TrimSuffix(baseURL, "/") + "/" + strconv.Itoa(postID)
+		return postURL, postURL
+	}
+	embed, hasEmbed := parsed.Query()[wpEmbedParam]
+	parsed.RawQuery = ""
+	parsed.Fragment = ""
+	parsed.Path = strings.TrimSuffix(parsed.Path, "/") + "/" + strconv.Itoa(postID)
+	postURL := parsed.String()
+	if !hasEmbed {
+		return postURL, postURL
 	}
+	parsed.RawQuery = url.Values{wpEmbedParam: embed}.Encode()
+	return postURL, parsed.String()
+}
 
-	sourceID := uuid.New().String()
-	now := time.Now().Format(time.RFC3339)
+// wpPostOwners returns the owners of a post: its author's slug, or name when it has none. Only
+// posts fetched with _embed carry their author; the REST API never exposes authors' emails.
+func wpPostOwners(post map[string]interface{}) []string {
+	embedded, _ := post["_embedded"].(map[string]interface{})
+	authors, _ := embedded["author"].([]interface{})
+	if len(authors) == 0 {
+		return nil
+	}
+	author, _ := authors[0].(map[string]interface{})
+	for _, field := range []string{"slug", "name"} {
+		if value, ok := author[field].(string); ok && value != "" {
+			return []string{value}
+		}
+	}
+	return nil
+}
 
-	query := `INSERT INTO sources (id, raw_url, scheme, host, path, query, active_domain, format, created_at, updated_at)
-			  VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+// wpPostLabels returns the access-control label of a post: public once published, restricted when
+// password protected, and private for private posts and the drafts and scheduled posts an
+// authenticated import can read. A post without a status, as some custom endpoints return, has
+// none.
+func wpPostLabels(post map[string]interface{}) []string {
+	status, _ := post["status"].(string)
+	content, _ := post["content"].(map[string]interface{})
+	switch protected, _ := content["protected"].(bool); {
+	case status == "":
+		return nil
+	case protected:
+		return []string{"restricted"}
+	case status == "publish":
+		return []string{"public"}
+	default:
+		return []string{"private"}
+	}
+}
 
-	_, err = db.ExecContext(ctx, query, sourceID, postURL, parsedURL.Scheme, parsedURL.Host,
-		parsedURL.Path, parsedURL.RawQuery, 1, "json", now, now)
+// createSource creates a source record in the database, reusing an existing source for the same URL.
+// New sources are placed in the collection carried by ctx.
+func (w *WPJSONImporter) createSource(ctx context.Context, postURL string, db dbExecutor) (string, error) {
+	sourceID, err := upsertSource(ctx, db, postURL, "json")
 	if err != nil {
-		w.logger.Error().Err(err).Str("post URL", postURL).Msg("failed to insert source")
+		w.logger.Error().Err(err).Str("post URL", postURL).Msg("failed to upsert source")
 		return "", err
 	}
 
 	return sourceID, nil
 }
 
-// createDownload creates a download record in the database.
+// createDownload creates a download record in the database. Its body is stored once per distinct content.
 func (w *WPJSONImporter) createDownload(
 	ctx context.Context,
 	sourceID string,
 	statusCode int,
 	headers http.Header,
 	body map[string]interface{},
-	db *sql.DB,
+	db dbExecutor,
 ) (string, error) {
 	downloadID := uuid.New().String()
-	now := time.Now().Format(time.RFC3339)
+	now := dialect.SQLite.FormatTime(time.Now())
 
-	// Convert headers to JSON
-	headersJSON, err := json.Marshal(headers)
+	// Convert headers to JSON, without any credentials the server sent back
+	headersJSON, err := json.Marshal(util.RedactHeaders(headers))
 	if err != nil {
 		w.logger.Error().Err(err).Msg("failed to marshal headers")
 		return "", err
@@ -325,11 +568,25 @@ func (w *WPJSONImporter) createDownload(
 		return "", err
 	}
 
-	query := `INSERT INTO downloads (id, source_id, attempted_at, downloaded_at, status_code, headers, body)
+	hash, err := storeBody(ctx, db, string(bodyJSON))
+	if err != nil {
+		w.logger.Error().Err(err).Msg("failed to store body")
+		return "", err
+	}
+	existingID, found, err := findDownloadID(ctx, db, sourceID, hash)
+	if err != nil {
+		w.logger.Error().Err(err).Msg("failed to look up download")
+		return "", err
+	}
+	if found {
+		return existingID, nil
+	}
+
+	query := `INSERT INTO downloads (id, source_id, attempted_at, downloaded_at, status_code, headers, content_hash)
 			  VALUES (?, ?, ?, ?, ?, ?, ?)`
 
 	_, err = db.ExecContext(ctx, query, downloadID, sourceID, now, now, statusCode,
-		string(headersJSON), string(bodyJSON))
+		string(headersJSON), hash)
 	if err != nil {
 		w.logger.Error().Err(err).Msg("failed to insert download")
 		return "", err
@@ -337,23 +594,3 @@ func (w *WPJSONImporter) createDownload(
 
 	return downloadID, nil
 }
-
-// SetConcurrency sets the number of concurrent requests.
-func (w *WPJSONImporter) SetConcurrency(concurrency int) {
-	w.concurrency = concurrency
-}
-
-// SetPerPage sets the number of posts to fetch per page.
-func (w *WPJSONImporter) SetPerPage(perPage int) {
-	w.perPage = perPage
-}
-
-// SetMaxPages sets the maximum number of pages to fetch.
-func (w *WPJSONImporter) SetMaxPages(maxPages int) {
-	w.maxPages = maxPages
-}
-
-// SetTimeout sets the HTTP client timeout.
-func (w *WPJSONImporter) SetTimeout(timeout time.Duration) {
-	w.client.Timeout = timeout
-}
@@ -0,0 +1,89 @@
+package importers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestWPAuthFromEnv(t *testing.T) {
+	t.Setenv("WORDPRESS_USERNAME", "editor")
+	t.Setenv("WORDPRESS_APP_PASSWORD", "abcd efgh ijkl")
+	t.Setenv("WORDPRESS_TOKEN", "")
+	t.Setenv("WORDPRESS_HEADERS", "X-Staging-Key=s3cr%3Dt, X-Site = blog")
+	t.Setenv("WORDPRESS_AUTH_HOSTS", "Staging.example.com, ")
+
+	auth := WPAuthFromEnv()
+	expected := WPAuth{
+		Username:    "editor",
+		AppPassword: "abcd efgh ijkl",
+		Headers:     map[string]string{"X-Staging-Key": "s3cr=t", "X-Site": "blog"},
+		Hosts:       []string{"staging.example.com"},
+	}
+	if !reflect.DeepEqual(auth, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, auth)
+	}
+}
+
+func TestWPJSONImporter_authorize(t *testing.T) {
+	tests := []struct {
+		name          string
+		auth          WPAuth
+		url           string
+		expectedAuth  string
+		expectedExtra string
+	}{
+		{name: "none", url: "https://example.com/wp-json/wp/v2/posts"},
+		{name: "application password", auth: WPAuth{Username: "editor", AppPassword: "pass"},
+			url: "https://example.com/wp-json/wp/v2/posts", expectedAuth: "Basic ZWRpdG9yOnBhc3M="},
+		{name: "token over password", auth: WPAuth{Username: "editor", AppPassword: "pass", Token: "jwt"},
+			url: "https://example.com/wp-json/wp/v2/posts", expectedAuth: "Bearer jwt"},
+		{name: "headers", auth: WPAuth{Headers: map[string]string{"X-Extra": "1"}},
+			url: "https://example.com/wp-json/wp/v2/posts", expectedExtra: "1"},
+		{name: "allowed host", auth: WPAuth{Token: "jwt", Hosts: []string{"example.com"}},
+			url: "https://EXAMPLE.com:8443/wp-json/wp/v2/posts", expectedAuth: "Bearer jwt"},
+		{name: "other host", auth: WPAuth{Token: "jwt", Headers: map[string]string{"X-Extra": "1"},
+			Hosts: []string{"example.com"}}, url: "https://other.example/wp-json/wp/v2/posts"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			importer := NewWPJSONImporter(WithAuth(tt.auth))
+			req, _ := http.NewRequest(http.MethodGet, tt.url, nil)
+			importer.authorize(req)
+			if got := req.Header.Get("Authorization"); got != tt.expectedAuth {
+				t.Errorf("Expected Authorization %q, got %q", tt.expectedAuth, got)
+			}
+			if got := req.Header.Get("X-Extra"); got != tt.expectedExtra {
+				t.Errorf("Expected X-Extra %q, got %q", tt.expectedExtra, got)
+			}
+		})
+	}
+}
+
+func TestWPJSONImporter_GetPostIDs_Authenticated(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "editor" || pass != "pass" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("X-WP-TotalPages", "1")
+		_ = json.NewEncoder(w).Encode([]map[string]interface{}{{"id": float64(7)}})
+	}))
+	defer testServer.Close()
+
+	importer := NewWPJSONImporter(WithAuth(WPAuth{}))
+	_, err := importer.getPostIDs(context.Background(), testServer.URL+"/wp-json/wp/v2/posts?status=draft")
+	if !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("Expected ErrUnauthorized without credentials, got %v", err)
+	}
+
+	importer = NewWPJSONImporter(WithAuth(WPAuth{Username: "editor", AppPassword: "pass"}))
+	postIDs, err := importer.getPostIDs(context.Background(), testServer.URL+"/wp-json/wp/v2/posts?status=draft")
+	if err != nil || !reflect.DeepEqual(postIDs, []int{7}) {
+		t.Errorf("Expected the draft's ID, got %v, %v", postIDs, err)
+	}
+}
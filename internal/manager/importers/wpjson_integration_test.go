@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/code-sleuth/ike-go/internal/manager/testutil"
+	"github.com/code-sleuth/ike-go/pkg/compression"
 )
 
 func TestWPJSONImporter_ImportPost_Integration(t *testing.T) {
@@ -143,9 +144,15 @@ func TestWPJSONImporter_ImportPost_Integration(t *testing.T) {
 
 				// Verify download data
 				var statusCode int
-				var body string
-				query = "SELECT status_code, body FROM downloads WHERE id = ?"
-				err = db.QueryRow(query, result.DownloadID).Scan(&statusCode, &body)
+				var rawBody []byte
+				var encoding string
+				query = `SELECT d.status_code, b.body, b.body_encoding FROM downloads d
+					JOIN download_bodies b ON b.content_hash = d.content_hash WHERE d.id = ?`
+				err = db.QueryRow(query, result.DownloadID).Scan(&statusCode, &rawBody, &encoding)
+				body, decodeErr := compression.DecodeBody(rawBody, encoding)
+				if err == nil {
+					err = decodeErr
+				}
 				if err != nil {
 					t.Errorf("Failed to query download data: %v", err)
 				} else {
@@ -209,6 +216,7 @@ func TestWPJSONImporter_Import_FullIntegration(t *testing.T) {
 					json.NewEncoder(w).Encode(posts)
 				default:
 					w.WriteHeader(http.StatusBadRequest)
+					w.Write([]byte(`{"code":"rest_post_invalid_page_number"}`))
 				}
 			} else {
 				// Normal scenario - return both posts
@@ -228,6 +236,7 @@ func TestWPJSONImporter_Import_FullIntegration(t *testing.T) {
 					json.NewEncoder(w).Encode(posts)
 				default:
 					w.WriteHeader(http.StatusBadRequest)
+					w.Write([]byte(`{"code":"rest_post_invalid_page_number"}`))
 				}
 			}
 		} else if path == "/wp-json/wp/v2/posts/285969" {
@@ -288,8 +297,7 @@ func TestWPJSONImporter_Import_FullIntegration(t *testing.T) {
 	}))
 	defer testServer.Close()
 
-	importer := NewWPJSONImporter()
-	importer.SetConcurrency(1) // Set to 1 for predictable testing
+	importer := NewWPJSONImporter(WithConcurrency(1))
 
 	t.Run("full import workflow with comprehensive validation", func(t *testing.T) {
 		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
@@ -387,7 +395,8 @@ func TestWPJSONImporter_Import_FullIntegration(t *testing.T) {
 		}
 
 		// Enhanced validation: Check download content contains expected data
-		query = "SELECT body FROM downloads ORDER BY downloaded_at"
+		query = `SELECT b.body, b.body_encoding FROM downloads d
+		JOIN download_bodies b ON b.content_hash = d.content_hash ORDER BY d.downloaded_at`
 		rows, err = db.Query(query)
 		if err != nil {
 			t.Errorf("Failed to query downloads: %v", err)
@@ -396,12 +405,19 @@ func TestWPJSONImporter_Import_FullIntegration(t *testing.T) {
 			downloadCount := 0
 			for rows.Next() {
 				downloadCount++
-				var body string
-				err = rows.Scan(&body)
+				var rawBody []byte
+				var encoding string
+				err = rows.Scan(&rawBody, &encoding)
 				if err != nil {
 					t.Errorf("Failed to scan download body: %v", err)
 					continue
 				}
+				// Large bodies are stored gzip-compressed
+				body, err := compression.DecodeBody(rawBody, encoding)
+				if err != nil {
+					t.Errorf("Failed to decode download body: %v", err)
+					continue
+				}
 
 				// Verify body is valid JSON
 				var parsedBody map[string]interface{}
@@ -458,8 +474,8 @@ func TestWPJSONImporter_Import_FullIntegration(t *testing.T) {
 		}
 
 		// Test with importer configured for more aggressive pagination
-		importer.SetPerPage(1) // Force pagination with 1 post per page
-		importer.SetConcurrency(1) // Predictable ordering
+		// Force pagination with 1 post per page, fetched one at a time for predictable ordering
+		importer := NewWPJSONImporter(WithPerPage(1), WithConcurrency(1))
 
 		sourceURL := testServer.URL + "/wp-json/wp/v2/posts"
 		result, err := importer.Import(ctx, sourceURL, db)
@@ -484,9 +500,6 @@ func TestWPJSONImporter_Import_FullIntegration(t *testing.T) {
 		if finalDownloadCount != 2 {
 			t.Errorf("Expected 2 downloads after paginated import, got %d", finalDownloadCount)
 		}
-
-		// Reset pagination settings
-		importer.SetPerPage(100)
 	})
 }
 
@@ -589,7 +602,7 @@ func TestWPJSONImporter_DatabaseErrorHandling(t *testing.T) {
 		defer testServer.Close()
 
 		// Reduce concurrency to limit retry noise
-		importer.SetConcurrency(1)
+		importer := NewWPJSONImporter(WithConcurrency(1))
 
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
@@ -761,3 +774,56 @@ func TestWPJSONImporter_DatabaseErrorHandling(t *testing.T) {
 		}
 	})
 }
+
+func TestWPJSONImporter_ImportPost_RedactsSecrets_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db := testutil.SetupTestDB(t)
+	defer testutil.CleanupTestDB(t, db)
+
+	const secret = "s3cret-session-value"
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "wordpress_logged_in="+secret)
+		w.Header().Set("X-Auth-Token", secret)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":       float64(42),
+			"date_gmt": "2025-06-27T11:00:55",
+			"title":    map[string]interface{}{"rendered": "Private post"},
+			"content":  map[string]interface{}{"rendered": "<p>Draft</p>"},
+		})
+	}))
+	defer testServer.Close()
+
+	importer := NewWPJSONImporter()
+	importer.auth = WPAuth{Token: secret}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result := importer.importPost(ctx, testServer.URL+"/wp-json/wp/v2/posts", 42, db)
+	if result.Error != nil {
+		t.Fatalf("Unexpected error: %v", result.Error)
+	}
+
+	var headers string
+	if err := db.QueryRow(`SELECT headers FROM downloads WHERE id = ?`, result.DownloadID).Scan(&headers); err != nil {
+		t.Fatalf("Failed to query download headers: %v", err)
+	}
+	if strings.Contains(headers, secret) {
+		t.Errorf("Expected stored headers to be redacted, got %s", headers)
+	}
+
+	sourceID, err := upsertSource(ctx, db, testServer.URL+"/feed.json?access_token="+secret, "json")
+	if err != nil {
+		t.Fatalf("Failed to create source: %v", err)
+	}
+	var rawURL string
+	if err := db.QueryRow(`SELECT raw_url FROM sources WHERE id = ?`, sourceID).Scan(&rawURL); err != nil {
+		t.Fatalf("Failed to query source: %v", err)
+	}
+	if strings.Contains(rawURL, secret) {
+		t.Errorf("Expected stored URL to be redacted, got %s", rawURL)
+	}
+}
@@ -3,8 +3,12 @@ package importers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"sync"
 	"testing"
 	"time"
 )
@@ -145,8 +149,7 @@ func TestWPJSONImporter_GetPostIDs(t *testing.T) {
 	}))
 	defer testServer.Close()
 
-	importer := NewWPJSONImporter()
-	importer.SetPerPage(10) // Set smaller page size for testing
+	importer := NewWPJSONImporter(WithPerPage(10))
 
 	tests := []struct {
 		name        string
@@ -223,9 +226,8 @@ func TestWPJSONImporter_GetPostIDs_ErrorHandling(t *testing.T) {
 		baseURL := errorServer.URL + "/wp-json/wp/v2/posts"
 		_, err := importer.getPostIDs(ctx, baseURL)
 		
-		// The implementation logs error but doesn't return it
-		if err != nil {
-			t.Errorf("Expected no error for server error (should handle gracefully), got: %v", err)
+		if !errors.Is(err, ErrUnexpectedStatusCode) {
+			t.Errorf("Expected ErrUnexpectedStatusCode for server error, got: %v", err)
 		}
 	})
 	
@@ -257,8 +259,64 @@ func TestWPJSONImporter_GetPostIDs_ErrorHandling(t *testing.T) {
 			t.Error("Expected error for invalid URL")
 		}
 	})
+
+	t.Run("bad request other than a page past the end", func(t *testing.T) {
+		badRequestServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"code":"rest_invalid_param","message":"Invalid parameter(s): per_page"}`))
+		}))
+		defer badRequestServer.Close()
+
+		_, err := importer.getPostIDs(context.Background(), badRequestServer.URL+"/wp-json/wp/v2/posts")
+		if !errors.Is(err, ErrUnexpectedStatusCode) {
+			t.Errorf("Expected ErrUnexpectedStatusCode for an invalid parameter, got: %v", err)
+		}
+	})
 }
 
+func TestWPJSONImporter_GetPostIDs_TotalPages(t *testing.T) {
+	var mu sync.Mutex
+	var requested []string
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		mu.Lock()
+		requested = append(requested, r.URL.Query().Get("page"))
+		mu.Unlock()
+		if r.URL.Query().Get("status") != "publish" || r.URL.Query().Has("_embed") {
+			t.Errorf("Expected the endpoint's query to be kept without _embed, got %s", r.URL.RawQuery)
+		}
+
+		w.Header().Set("X-WP-Total", "5")
+		w.Header().Set("X-WP-TotalPages", "3")
+		posts := []map[string]interface{}{{"id": float64(page*2 - 1)}, {"id": float64(page * 2)}}
+		if page == 3 {
+			posts = posts[:1]
+		}
+		json.NewEncoder(w).Encode(posts)
+	}))
+	defer testServer.Close()
+
+	importer := NewWPJSONImporter(WithPerPage(2))
+
+	endpoint := testServer.URL + "/wp-json/wp/v2/posts?status=publish&_embed"
+	postIDs, err := importer.getPostIDs(context.Background(), endpoint)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(postIDs, []int{1, 2, 3, 4, 5}) {
+		t.Errorf("Expected post IDs 1 to 5 in page order, got %v", postIDs)
+	}
+	// Nothing past the last page is requested
+	if len(requested) != 3 {
+		t.Errorf("Expected 3 pages to be requested, got %v", requested)
+	}
+
+	importer = NewWPJSONImporter(WithPerPage(2), WithMaxPages(2))
+	postIDs, err = importer.getPostIDs(context.Background(), endpoint)
+	if err != nil || !reflect.DeepEqual(postIDs, []int{1, 2, 3, 4}) {
+		t.Errorf("Expected the pages past the maximum to be left out, got %v, %v", postIDs, err)
+	}
+}
 
 func TestWPJSONImporter_ContextCancellation(t *testing.T) {
 	// Test server that delays response
@@ -268,8 +326,7 @@ func TestWPJSONImporter_ContextCancellation(t *testing.T) {
 	}))
 	defer delayServer.Close()
 
-	importer := NewWPJSONImporter()
-	importer.SetTimeout(5 * time.Second) // Set longer timeout on client
+	importer := NewWPJSONImporter(WithTimeout(5 * time.Second))
 
 	t.Run("context cancellation during getPostIDs", func(t *testing.T) {
 		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
@@ -316,38 +373,31 @@ func TestWPJSONImporter_Configuration(t *testing.T) {
 		}
 	})
 	
-	t.Run("setter methods", func(t *testing.T) {
-		importer := NewWPJSONImporter()
+	t.Run("options", func(t *testing.T) {
+		defaultTimeout := NewWPJSONImporter().client.Timeout
+		newTimeout := 60 * time.Second
+		importer := NewWPJSONImporter(
+			WithConcurrency(10), WithPerPage(50), WithMaxPages(500), WithTimeout(newTimeout),
+		)
 		
-		// Test SetConcurrency
-		importer.SetConcurrency(10)
 		if importer.concurrency != 10 {
 			t.Errorf("Expected concurrency 10, got %d", importer.concurrency)
 		}
 		
-		// Test SetPerPage
-		importer.SetPerPage(50)
 		if importer.perPage != 50 {
 			t.Errorf("Expected perPage 50, got %d", importer.perPage)
 		}
 		
-		// Test SetMaxPages
-		importer.SetMaxPages(500)
 		if importer.maxPages != 500 {
 			t.Errorf("Expected maxPages 500, got %d", importer.maxPages)
 		}
 		
-		// Test SetTimeout
-		originalTimeout := importer.client.Timeout
-		newTimeout := 60 * time.Second
-		importer.SetTimeout(newTimeout)
-		
 		if importer.client.Timeout != newTimeout {
 			t.Errorf("Expected timeout %v, got %v", newTimeout, importer.client.Timeout)
 		}
 		
-		if importer.client.Timeout == originalTimeout {
-			t.Error("Timeout should have changed from original value")
+		if importer.client.Timeout == defaultTimeout {
+			t.Error("Timeout should have changed from the default")
 		}
 	})
 }
@@ -369,3 +419,80 @@ func BenchmarkNewWPJSONImporter(b *testing.B) {
 		NewWPJSONImporter()
 	}
 }
+
+func TestPostIDsFromPaths(t *testing.T) {
+	ids := postIDsFromPaths([]string{"42", "README.md", "0", "7"})
+	if len(ids) != 2 || ids[0] != 42 || ids[1] != 7 {
+		t.Errorf("Expected post IDs [42 7], got %v", ids)
+	}
+}
+
+func TestWPPostURL(t *testing.T) {
+	tests := []struct {
+		baseURL  string
+		postURL  string
+		fetchURL string
+	}{
+		{baseURL: "https://example.com/wp-json/wp/v2/posts", postURL: "https://example.com/wp-json/wp/v2/posts/42",
+			fetchURL: "https://example.com/wp-json/wp/v2/posts/42"},
+		{baseURL: "https://example.com/wp-json/wp/v2/posts/?status=draft,publish",
+			postURL:  "https://example.com/wp-json/wp/v2/posts/42",
+			fetchURL: "https://example.com/wp-json/wp/v2/posts/42"},
+		{baseURL: "https://example.com/wp-json/wp/v2/posts?_embed&status=draft",
+			postURL:  "https://example.com/wp-json/wp/v2/posts/42",
+			fetchURL: "https://example.com/wp-json/wp/v2/posts/42?_embed="},
+		{baseURL: "https://example.com/wp-json/wp/v2/posts?_embed=author,wp:term",
+			postURL:  "https://example.com/wp-json/wp/v2/posts/42",
+			fetchURL: "https://example.com/wp-json/wp/v2/posts/42?_embed=author%2Cwp%3Aterm"},
+	}
+	for _, tt := range tests {
+		postURL, fetchURL := wpPostURL(tt.baseURL, 42)
+		if postURL != tt.postURL || fetchURL != tt.fetchURL {
+			t.Errorf("Expected %s and %s for %s, got %s and %s", tt.postURL, tt.fetchURL, tt.baseURL, postURL, fetchURL)
+		}
+	}
+}
+
+func TestWPPostOwners(t *testing.T) {
+	tests := []struct {
+		name     string
+		post     map[string]interface{}
+		expected []string
+	}{
+		{name: "slug", expected: []string{"ada"}, post: map[string]interface{}{"_embedded": map[string]interface{}{
+			"author": []interface{}{map[string]interface{}{"slug": "ada", "name": "Ada Lovelace"}},
+		}}},
+		{name: "name without slug", expected: []string{"Ada Lovelace"}, post: map[string]interface{}{
+			"_embedded": map[string]interface{}{"author": []interface{}{map[string]interface{}{"name": "Ada Lovelace"}}},
+		}},
+		{name: "author not embedded", post: map[string]interface{}{
+			"_embedded": map[string]interface{}{"author": []interface{}{map[string]interface{}{"code": "rest_forbidden"}}},
+		}},
+		{name: "not fetched with _embed", post: map[string]interface{}{"author": float64(5)}},
+	}
+	for _, tt := range tests {
+		if got := wpPostOwners(tt.post); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestWPPostLabels(t *testing.T) {
+	tests := []struct {
+		name     string
+		post     map[string]interface{}
+		expected []string
+	}{
+		{name: "published", expected: []string{"public"}, post: map[string]interface{}{"status": "publish"}},
+		{name: "draft", expected: []string{"private"}, post: map[string]interface{}{"status": "draft"}},
+		{name: "password protected", expected: []string{"restricted"}, post: map[string]interface{}{
+			"status": "publish", "content": map[string]interface{}{"protected": true},
+		}},
+		{name: "no status", post: map[string]interface{}{"content": map[string]interface{}{"rendered": "<p>Hi</p>"}}},
+	}
+	for _, tt := range tests {
+		if got := wpPostLabels(tt.post); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.expected, got)
+		}
+	}
+}
@@ -0,0 +1,17 @@
+package interfaces
+
+import "context"
+
+type aclLabelsKey struct{}
+
+// WithACLLabels returns a context that makes importers label every source they import with the
+// listed access-control labels, such as a group, instead of those they derive from the source.
+func WithACLLabels(ctx context.Context, labels []string) context.Context {
+	return context.WithValue(ctx, aclLabelsKey{}, labels)
+}
+
+// ACLLabelsFromContext returns the labels set with WithACLLabels.
+func ACLLabelsFromContext(ctx context.Context) []string {
+	labels, _ := ctx.Value(aclLabelsKey{}).([]string)
+	return labels
+}
@@ -0,0 +1,31 @@
+package interfaces
+
+import "context"
+
+type (
+	actorKey struct{}
+	jobIDKey struct{}
+)
+
+// WithActor returns a context whose recorded events name actor, such as "cli", a worker ID, or a
+// webhook, as their cause.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the actor set with WithActor, or "" when there is none.
+func ActorFromContext(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
+
+// WithJobID returns a context whose recorded events belong to the queued job id.
+func WithJobID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, jobIDKey{}, id)
+}
+
+// JobIDFromContext returns the job ID set with WithJobID, or "" outside a job.
+func JobIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(jobIDKey{}).(string)
+	return id
+}
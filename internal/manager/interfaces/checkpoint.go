@@ -0,0 +1,36 @@
+package interfaces
+
+import "context"
+
+// Checkpoint records which items of a source an import has already fetched, so an interrupted
+// run can skip them when it is resumed.
+type Checkpoint interface {
+	// Imported returns the result recorded for the item key by an earlier attempt of the run.
+	Imported(key string) (*ImportResult, bool)
+
+	// RecordImport marks the item key as imported.
+	RecordImport(ctx context.Context, key string, result *ImportResult) error
+}
+
+type checkpointKey struct{}
+
+// noCheckpoint is used when no checkpoint is attached to the context; it records nothing.
+type noCheckpoint struct{}
+
+func (noCheckpoint) Imported(string) (*ImportResult, bool) { return nil, false }
+
+func (noCheckpoint) RecordImport(context.Context, string, *ImportResult) error { return nil }
+
+// WithCheckpoint returns a context that makes importers skip and record items through checkpoint.
+func WithCheckpoint(ctx context.Context, checkpoint Checkpoint) context.Context {
+	return context.WithValue(ctx, checkpointKey{}, checkpoint)
+}
+
+// CheckpointFromContext returns the checkpoint set with WithCheckpoint, or one that records
+// nothing.
+func CheckpointFromContext(ctx context.Context) Checkpoint {
+	if checkpoint, ok := ctx.Value(checkpointKey{}).(Checkpoint); ok && checkpoint != nil {
+		return checkpoint
+	}
+	return noCheckpoint{}
+}
@@ -0,0 +1,22 @@
+package interfaces
+
+import "context"
+
+// DefaultCollection is the collection sources belong to unless one is chosen at import.
+const DefaultCollection = "default"
+
+type collectionKey struct{}
+
+// WithCollection returns a context that makes importers place new sources in the named
+// collection.
+func WithCollection(ctx context.Context, collection string) context.Context {
+	return context.WithValue(ctx, collectionKey{}, collection)
+}
+
+// CollectionFromContext returns the collection set with WithCollection, or DefaultCollection.
+func CollectionFromContext(ctx context.Context) string {
+	if collection, ok := ctx.Value(collectionKey{}).(string); ok && collection != "" {
+		return collection
+	}
+	return DefaultCollection
+}
@@ -0,0 +1,40 @@
+package interfaces
+
+import "context"
+
+type credentialsKey struct{}
+
+// Credentials are API keys and tokens for one call, such as an import on behalf of a customer,
+// used in place of those the importers and embedders were created with. Empty fields leave the
+// created ones in use.
+type Credentials struct {
+	GitHubToken    string
+	OpenAIAPIKey   string
+	TogetherAPIKey string
+}
+
+// WithCredentials returns a context whose calls authenticate with credentials. Fields left empty
+// keep those of any credentials ctx already carries.
+func WithCredentials(ctx context.Context, credentials Credentials) context.Context {
+	return context.WithValue(ctx, credentialsKey{}, CredentialsFromContext(ctx).Merge(credentials))
+}
+
+// CredentialsFromContext returns the credentials set with WithCredentials.
+func CredentialsFromContext(ctx context.Context) Credentials {
+	credentials, _ := ctx.Value(credentialsKey{}).(Credentials)
+	return credentials
+}
+
+// Merge returns c with the non-empty fields of other in place of its own.
+func (c Credentials) Merge(other Credentials) Credentials {
+	if other.GitHubToken != "" {
+		c.GitHubToken = other.GitHubToken
+	}
+	if other.OpenAIAPIKey != "" {
+		c.OpenAIAPIKey = other.OpenAIAPIKey
+	}
+	if other.TogetherAPIKey != "" {
+		c.TogetherAPIKey = other.TogetherAPIKey
+	}
+	return c
+}
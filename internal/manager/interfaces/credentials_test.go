@@ -0,0 +1,21 @@
+package interfaces
+
+import (
+	"context"
+	"testing"
+)
+
+func TestWithCredentials(t *testing.T) {
+	ctx := context.Background()
+	if credentials := CredentialsFromContext(ctx); credentials != (Credentials{}) {
+		t.Errorf("Expected no credentials, got %+v", credentials)
+	}
+
+	ctx = WithCredentials(ctx, Credentials{GitHubToken: "ghp_a", OpenAIAPIKey: "sk-a"})
+	ctx = WithCredentials(ctx, Credentials{OpenAIAPIKey: "sk-b", TogetherAPIKey: "tg-b"})
+
+	want := Credentials{GitHubToken: "ghp_a", OpenAIAPIKey: "sk-b", TogetherAPIKey: "tg-b"}
+	if credentials := CredentialsFromContext(ctx); credentials != want {
+		t.Errorf("Expected %+v, got %+v", want, credentials)
+	}
+}
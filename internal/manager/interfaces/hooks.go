@@ -0,0 +1,56 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/code-sleuth/ike-go/internal/manager/models"
+)
+
+// HookStage names a pipeline stage hooks run around.
+type HookStage string
+
+const (
+	// HookImport runs around an importer fetching a source.
+	HookImport HookStage = "import"
+	// HookTransform runs around a download being turned into a document.
+	HookTransform HookStage = "transform"
+	// HookChunk runs around a document's content being split into chunks.
+	HookChunk HookStage = "chunk"
+	// HookEmbed runs around each chunk being embedded.
+	HookEmbed HookStage = "embed"
+)
+
+// HookPhase says whether a hook runs before or after its stage.
+type HookPhase string
+
+const (
+	HookBefore HookPhase = "before"
+	HookAfter  HookPhase = "after"
+)
+
+// HookPayload describes the work a hook runs around. Only the fields known at that point are set:
+//
+//   - import: SourceURL and Options, and Import once it has finished
+//   - transform: Options, Source, and Download, and Transform once it has finished
+//   - chunk: the transform fields, and Chunks once it has finished
+//   - embed: Chunk, and Embedding once it has been generated but before it is saved
+//
+// Hooks may modify what the fields point to, for example to enrich a document's content before
+// it is chunked or to redact a chunk before it is embedded.
+type HookPayload struct {
+	Stage     HookStage
+	Phase     HookPhase
+	SourceURL string
+	Options   *ProcessingOptions
+	Import    *ImportResult
+	Source    *models.Source
+	Download  *models.Download
+	Transform *TransformResult
+	Chunks    []*models.Chunk
+	Chunk     *models.Chunk
+	Embedding *models.Embedding
+}
+
+// Hook runs before or after a pipeline stage. An error fails the stage for the item being
+// processed, as if the stage itself had failed.
+type Hook func(ctx context.Context, payload *HookPayload) error
@@ -8,11 +8,32 @@ import (
 	"github.com/code-sleuth/ike-go/internal/manager/models"
 )
 
-// ImportResult represents the result of an import operation.
+// ImportResult represents the result of an import operation. Importers that import several items
+// return the last one imported, with Error set when others failed and Report describing each item.
 type ImportResult struct {
 	SourceID   string
 	DownloadID string
 	Error      error
+	Report     *ImportReport
+	// Revision is the version of the whole source the import covered, such as the tree a
+	// repository branch points at, for importers that import only what changed since the
+	// revision of the last completed run (see LastRevisionFromContext). Empty otherwise.
+	Revision string
+}
+
+// SourceResult is the outcome of one source processed by ProcessSources.
+type SourceResult struct {
+	SourceURL string
+	Err       error
+	Duration  time.Duration
+}
+
+// BatchReport is the per-source outcome of ProcessSources, in the order the URLs were given
+// with duplicates removed.
+type BatchReport struct {
+	Results   []SourceResult
+	Succeeded int
+	Failed    int
 }
 
 // TransformResult represents the result of a transformation operation.
@@ -64,6 +85,14 @@ type Chunker interface {
 	GetChunkingStrategy() string
 }
 
+// StreamingChunker is implemented by chunkers that can hand over a document's chunks one at a
+// time as they are cut, so the chunks of a very large document are never all in memory at once.
+type StreamingChunker interface {
+	// StreamChunks calls yield with each chunk of content in document order, and stops with the
+	// first error yield returns
+	StreamChunks(content string, maxTokens int, yield func(*models.Chunk) error) error
+}
+
 // Embedder defines the interface for generating vector embeddings.
 type Embedder interface {
 	// GenerateEmbedding creates a vector embedding for the given content
@@ -79,6 +108,54 @@ type Embedder interface {
 	GetMaxTokens() int
 }
 
+// Tokenizer defines the interface for splitting text into the tokens a model counts.
+type Tokenizer interface {
+	// Tokenize splits text into tokens that concatenate back to text
+	Tokenize(text string) ([]string, error)
+
+	// GetName returns the name stored with each chunk's token count
+	GetName() string
+}
+
+// TokenizingEmbedder is implemented by embedders that know the tokenizer of their model, so
+// chunks can be sized and counted the way the model counts them.
+type TokenizingEmbedder interface {
+	// GetTokenizer returns the tokenizer of the embedding model
+	GetTokenizer() Tokenizer
+}
+
+// BatchEmbedder is implemented by embedders whose API embeds several inputs in one request, so
+// the engine can embed a batch of chunks with a single call.
+type BatchEmbedder interface {
+	// GenerateEmbeddings creates a vector embedding for each of contents, in the order of contents
+	GenerateEmbeddings(ctx context.Context, contents []string) ([][]float32, error)
+}
+
+// TokenizingChunker is implemented by chunkers that can count tokens with a tokenizer other than
+// their own, such as an embedder's.
+type TokenizingChunker interface {
+	// WithTokenizer returns a chunker that counts tokens with tokenizer
+	WithTokenizer(tokenizer Tokenizer) Chunker
+}
+
+// Reranker defines the interface for re-scoring retrieved content against a query.
+type Reranker interface {
+	// Rerank returns a relevance score for each document, in the order given
+	Rerank(ctx context.Context, query string, documents []string) ([]float64, error)
+
+	// GetModelName returns the name of the reranking model
+	GetModelName() string
+}
+
+// QueryExpander defines the interface for generating alternative phrasings of a search query.
+type QueryExpander interface {
+	// Expand returns up to n paraphrases of the query, excluding the query itself
+	Expand(ctx context.Context, query string, n int) ([]string, error)
+
+	// GetModelName returns the name of the model generating paraphrases
+	GetModelName() string
+}
+
 // UpdateResult represents the result of an update operation.
 type UpdateResult struct {
 	SourceID     string
@@ -106,7 +183,37 @@ type ProcessingOptions struct {
 	ChunkStrategy  string
 	EmbeddingModel string
 	Concurrency    int
-	Timeout        time.Duration
+	// Timeout bounds a whole run, such as a queued import job; zero leaves it unbounded.
+	Timeout time.Duration
+	// ImportTimeout bounds the import of each source, TransformTimeout the transform of each
+	// download, and EmbedTimeout the embedding of each chunk, so one hung request fails its own
+	// stage instead of using up Timeout. Zero leaves the stage bounded only by Timeout.
+	ImportTimeout    time.Duration
+	TransformTimeout time.Duration
+	EmbedTimeout     time.Duration
+	// Collection places newly imported sources in the named collection; empty uses
+	// DefaultCollection.
+	Collection string
+	// Owners are recorded as owners of every source imported, such as the team responsible for
+	// them, besides the owners importers find at the source.
+	Owners []string
+	// ACLLabels are the access-control labels of every source imported, replacing those importers
+	// derive, such as a GitHub repository's visibility; source settings set them for the content
+	// under a URL. Searches only return labeled content to callers granted one of its labels.
+	ACLLabels []string
+	// Restart ignores any unfinished run for the source and imports it from the first item.
+	Restart bool
+	// Paths limits the import to these items, such as repository file paths or WordPress post
+	// IDs; empty imports the whole source.
+	Paths []string
+	// Force chunks and embeds documents even when their content is unchanged since the source
+	// was last embedded.
+	Force bool
+	// SourceConcurrency is how many sources ProcessSources processes at once; zero or less uses
+	// a default of 2.
+	SourceConcurrency int
+	// Progress, when set, receives progress events from the importer and the engine.
+	Progress ProgressFunc `json:"-"`
 }
 
 // ProcessingEngine orchestrates the complete import/transform/chunk/embed pipeline.
@@ -114,6 +221,14 @@ type ProcessingEngine interface {
 	// ProcessSource runs the complete pipeline for a source
 	ProcessSource(ctx context.Context, sourceURL string, options *ProcessingOptions, db *sql.DB) error
 
+	// ProcessSources runs the complete pipeline for several sources and reports on each
+	ProcessSources(
+		ctx context.Context,
+		sourceURLs []string,
+		options *ProcessingOptions,
+		db *sql.DB,
+	) (*BatchReport, error)
+
 	// ProcessDocument runs transform/chunk/embed for an existing download
 	ProcessDocument(ctx context.Context, downloadID string, options *ProcessingOptions, db *sql.DB) error
 
@@ -131,4 +246,10 @@ type ProcessingEngine interface {
 
 	// RegisterUpdater adds a new updater to the engine
 	RegisterUpdater(updater Updater) error
+
+	// RegisterHook adds a hook to run before or after a pipeline stage
+	RegisterHook(stage HookStage, phase HookPhase, hook Hook) error
+
+	// Shutdown stops accepting new work and waits for in-flight work to finish or checkpoint
+	Shutdown(ctx context.Context) error
 }
@@ -0,0 +1,17 @@
+package interfaces
+
+import "context"
+
+type ownersKey struct{}
+
+// WithOwners returns a context that makes importers record the listed owners, such as a team
+// name, on every source they import, besides any owners they find at the source itself.
+func WithOwners(ctx context.Context, owners []string) context.Context {
+	return context.WithValue(ctx, ownersKey{}, owners)
+}
+
+// OwnersFromContext returns the owners set with WithOwners.
+func OwnersFromContext(ctx context.Context) []string {
+	owners, _ := ctx.Value(ownersKey{}).([]string)
+	return owners
+}
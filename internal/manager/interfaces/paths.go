@@ -0,0 +1,44 @@
+package interfaces
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNoMatchingPaths is returned by importers when an import limited with WithPaths matches
+// nothing they would import, such as a push that only changed images.
+var ErrNoMatchingPaths = errors.New("no importable items match the requested paths")
+
+// ErrNoChanges is returned by importers when nothing they would import changed since the revision
+// set with WithLastRevision. They may return it with a result that carries only the Revision.
+var ErrNoChanges = errors.New("nothing changed since the last import")
+
+type (
+	pathsKey        struct{}
+	lastRevisionKey struct{}
+)
+
+// WithPaths returns a context that limits importers to the listed items, such as the repository
+// file paths changed by a push or the IDs of updated WordPress posts, instead of everything at
+// the source.
+func WithPaths(ctx context.Context, paths []string) context.Context {
+	return context.WithValue(ctx, pathsKey{}, paths)
+}
+
+// PathsFromContext returns the paths set with WithPaths; nil means the whole source.
+func PathsFromContext(ctx context.Context) []string {
+	paths, _ := ctx.Value(pathsKey{}).([]string)
+	return paths
+}
+
+// WithLastRevision returns a context that lets importers import only what changed since revision,
+// the ImportResult.Revision of the source's last completed run. Without it they import everything.
+func WithLastRevision(ctx context.Context, revision string) context.Context {
+	return context.WithValue(ctx, lastRevisionKey{}, revision)
+}
+
+// LastRevisionFromContext returns the revision set with WithLastRevision, or "" when there is none.
+func LastRevisionFromContext(ctx context.Context) string {
+	revision, _ := ctx.Value(lastRevisionKey{}).(string)
+	return revision
+}
@@ -0,0 +1,130 @@
+package interfaces
+
+import (
+	"context"
+	"sync"
+)
+
+// ProgressStage names a step of the pipeline reported through ProgressFunc.
+type ProgressStage string
+
+const (
+	// StageDiscovered reports items found at the source before any is fetched.
+	StageDiscovered ProgressStage = "discovered"
+	// StageImported reports an item whose download has been stored.
+	StageImported ProgressStage = "imported"
+	// StageTransformed reports a download turned into a document.
+	StageTransformed ProgressStage = "transformed"
+	// StageChunked reports the chunks a document was split into.
+	StageChunked ProgressStage = "chunked"
+	// StageEmbedded reports a chunk embedded and saved.
+	StageEmbedded ProgressStage = "embedded"
+	// StageSkipped reports a document whose content is unchanged and was not embedded again.
+	StageSkipped ProgressStage = "skipped"
+	// StageFailed reports an item or chunk that could not be processed.
+	StageFailed ProgressStage = "failed"
+	// StageWaiting reports a pause, such as for a rate limit to reset, with Item saying until when.
+	StageWaiting ProgressStage = "waiting"
+)
+
+// ProgressEvent is a single progress update. Count is how many units the event adds to its
+// stage: the number of items discovered, chunks produced, and so on.
+type ProgressEvent struct {
+	Stage ProgressStage
+	// Source is the URL of the source being imported when the event was reported, if any.
+	Source string
+	// Item identifies what the event is about, such as a file path, post ID, or document ID.
+	Item  string
+	Count int
+	Err   error
+}
+
+// ProgressFunc receives progress events. Importers and the engine report from several
+// goroutines, so implementations must be safe for concurrent use.
+type ProgressFunc func(event ProgressEvent)
+
+type (
+	progressKey       struct{}
+	progressSourceKey struct{}
+)
+
+// WithProgress returns a context that makes importers and the engine report progress to fn.
+func WithProgress(ctx context.Context, fn ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+// WithProgressSource returns a context whose progress events are attributed to the source at
+// sourceURL, so callers importing several sources at once can tell their progress apart.
+func WithProgressSource(ctx context.Context, sourceURL string) context.Context {
+	return context.WithValue(ctx, progressSourceKey{}, sourceURL)
+}
+
+// ReportProgress sends event to the ProgressFunc set with WithProgress, if any, filling in the
+// source set with WithProgressSource.
+func ReportProgress(ctx context.Context, event ProgressEvent) {
+	if fn, ok := ctx.Value(progressKey{}).(ProgressFunc); ok && fn != nil {
+		if event.Source == "" {
+			event.Source, _ = ctx.Value(progressSourceKey{}).(string)
+		}
+		fn(event)
+	}
+}
+
+// ProgressChannel returns a ProgressFunc that forwards events to ch. Events are dropped rather
+// than blocking the pipeline when ch is full.
+func ProgressChannel(ch chan<- ProgressEvent) ProgressFunc {
+	return func(event ProgressEvent) {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// ProgressCounts is a snapshot of the units reported per stage.
+type ProgressCounts struct {
+	Discovered  int
+	Imported    int
+	Transformed int
+	Chunked     int
+	Embedded    int
+	Skipped     int
+	Failed      int
+}
+
+// ProgressTracker totals progress events, for callers that render a progress bar rather than
+// react to individual events.
+type ProgressTracker struct {
+	mu     sync.Mutex
+	counts ProgressCounts
+}
+
+// Report adds event to the totals; pass it as a ProgressFunc.
+func (t *ProgressTracker) Report(event ProgressEvent) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	switch event.Stage {
+	case StageDiscovered:
+		t.counts.Discovered += event.Count
+	case StageImported:
+		t.counts.Imported += event.Count
+	case StageTransformed:
+		t.counts.Transformed += event.Count
+	case StageChunked:
+		t.counts.Chunked += event.Count
+	case StageEmbedded:
+		t.counts.Embedded += event.Count
+	case StageSkipped:
+		t.counts.Skipped += event.Count
+	case StageFailed:
+		t.counts.Failed += event.Count
+	}
+}
+
+// Counts returns the totals reported so far.
+func (t *ProgressTracker) Counts() ProgressCounts {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.counts
+}
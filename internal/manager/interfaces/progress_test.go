@@ -0,0 +1,69 @@
+package interfaces
+
+import (
+	"context"
+	"testing"
+)
+
+func TestProgressTracker(t *testing.T) {
+	tracker := &ProgressTracker{}
+	ctx := WithProgress(context.Background(), tracker.Report)
+
+	events := []ProgressEvent{
+		{Stage: StageDiscovered, Count: 3},
+		{Stage: StageImported, Item: "a.md", Count: 1},
+		{Stage: StageImported, Item: "b.md", Count: 1},
+		{Stage: StageFailed, Item: "c.md", Count: 1},
+		{Stage: StageTransformed, Item: "doc-1", Count: 1},
+		{Stage: StageChunked, Item: "doc-1", Count: 4},
+		{Stage: StageEmbedded, Item: "chunk-1", Count: 1},
+		{Stage: StageSkipped, Item: "doc-2", Count: 1},
+	}
+	for _, event := range events {
+		ReportProgress(ctx, event)
+	}
+
+	expected := ProgressCounts{Discovered: 3, Imported: 2, Transformed: 1, Chunked: 4, Embedded: 1, Skipped: 1,
+		Failed: 1}
+	if got := tracker.Counts(); got != expected {
+		t.Errorf("Expected counts %+v, got %+v", expected, got)
+	}
+}
+
+func TestReportProgress_WithoutReceiver(t *testing.T) {
+	// Reporting without a receiver must be a no-op so importers can report unconditionally
+	ReportProgress(context.Background(), ProgressEvent{Stage: StageImported, Count: 1})
+}
+
+func TestProgressChannel(t *testing.T) {
+	ch := make(chan ProgressEvent, 1)
+	report := ProgressChannel(ch)
+
+	report(ProgressEvent{Stage: StageImported, Item: "first", Count: 1})
+	report(ProgressEvent{Stage: StageImported, Item: "second", Count: 1})
+
+	if got := <-ch; got.Item != "first" {
+		t.Errorf("Expected the first event, got %s", got.Item)
+	}
+	select {
+	case event := <-ch:
+		t.Errorf("Expected the event sent to a full channel to be dropped, got %s", event.Item)
+	default:
+	}
+}
+
+func TestReportProgress_Source(t *testing.T) {
+	var events []ProgressEvent
+	ctx := WithProgress(context.Background(), func(event ProgressEvent) { events = append(events, event) })
+	ctx = WithProgressSource(ctx, "https://example.com/feed")
+
+	ReportProgress(ctx, ProgressEvent{Stage: StageImported, Count: 1})
+	ReportProgress(ctx, ProgressEvent{Stage: StageImported, Source: "https://example.com/other", Count: 1})
+
+	if events[0].Source != "https://example.com/feed" {
+		t.Errorf("Expected the event to be attributed to the context's source, got %q", events[0].Source)
+	}
+	if events[1].Source != "https://example.com/other" {
+		t.Errorf("Expected an explicit source to be kept, got %q", events[1].Source)
+	}
+}
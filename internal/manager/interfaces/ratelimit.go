@@ -0,0 +1,31 @@
+package interfaces
+
+import "context"
+
+// RateLimitEvent describes one response from a rate-limited API. Throttled is set when the
+// request was rejected for exceeding the limit, such as with 429 Too Many Requests. Limit and
+// Remaining come from the provider's rate-limit headers and are zero when it sent none.
+type RateLimitEvent struct {
+	Throttled bool
+	Limit     int
+	Remaining int
+}
+
+// RateLimitFunc receives rate-limit events. Embedders report from several goroutines, so
+// implementations must be safe for concurrent use.
+type RateLimitFunc func(event RateLimitEvent)
+
+type rateLimitKey struct{}
+
+// WithRateLimitObserver returns a context that makes embedders report every response to fn, so
+// the caller can adapt how many requests it sends at once.
+func WithRateLimitObserver(ctx context.Context, fn RateLimitFunc) context.Context {
+	return context.WithValue(ctx, rateLimitKey{}, fn)
+}
+
+// ReportRateLimit sends event to the RateLimitFunc set with WithRateLimitObserver, if any.
+func ReportRateLimit(ctx context.Context, event RateLimitEvent) {
+	if fn, ok := ctx.Value(rateLimitKey{}).(RateLimitFunc); ok && fn != nil {
+		fn(event)
+	}
+}
@@ -0,0 +1,69 @@
+package interfaces
+
+import "errors"
+
+// ItemStatus is the outcome of one item of an import.
+type ItemStatus string
+
+const (
+	// ItemImported is an item stored as a download, by this import or an interrupted one it resumed.
+	ItemImported ItemStatus = "imported"
+	// ItemSkipped is an item the importer chose not to fetch, such as a file over the size limit.
+	ItemSkipped ItemStatus = "skipped"
+	// ItemFailed is an item that could not be fetched or stored.
+	ItemFailed ItemStatus = "failed"
+)
+
+// ItemReport is the outcome of one item of an import. Key identifies the item within its source,
+// such as a file path or post ID. SkipReason is set for skipped items and Err for failed ones;
+// Err wraps the underlying error so callers can tell an authentication failure from a missing
+// item with errors.Is.
+type ItemReport struct {
+	Key        string
+	Status     ItemStatus
+	SkipReason string
+	Err        error
+}
+
+// ImportReport is the per-item outcome of an import.
+type ImportReport struct {
+	Items    []ItemReport
+	Imported int
+	Skipped  int
+	Failed   int
+}
+
+// Add records the outcome of an item.
+func (r *ImportReport) Add(item ItemReport) {
+	r.Items = append(r.Items, item)
+	switch item.Status {
+	case ItemImported:
+		r.Imported++
+	case ItemSkipped:
+		r.Skipped++
+	case ItemFailed:
+		r.Failed++
+	}
+}
+
+// Err joins the errors of the failed items, or returns nil when none failed.
+func (r *ImportReport) Err() error {
+	var errs []error
+	for _, item := range r.Items {
+		if item.Status == ItemFailed {
+			errs = append(errs, item.Err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// SkipCounts returns how many items were skipped for each reason.
+func (r *ImportReport) SkipCounts() map[string]int {
+	counts := make(map[string]int)
+	for _, item := range r.Items {
+		if item.Status == ItemSkipped {
+			counts[item.SkipReason]++
+		}
+	}
+	return counts
+}
@@ -0,0 +1,33 @@
+package interfaces
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestImportReport(t *testing.T) {
+	report := &ImportReport{}
+	if err := report.Err(); err != nil {
+		t.Errorf("Expected no error from an empty report, got %v", err)
+	}
+
+	errAuth := errors.New("unauthorized")
+	errGone := errors.New("not found")
+	report.Add(ItemReport{Key: "a.md", Status: ItemImported})
+	report.Add(ItemReport{Key: "b.md", Status: ItemSkipped, SkipReason: "too large"})
+	report.Add(ItemReport{Key: "c.md", Status: ItemFailed, Err: errAuth})
+	report.Add(ItemReport{Key: "d.md", Status: ItemFailed, Err: errGone})
+
+	if report.Imported != 1 || report.Skipped != 1 || report.Failed != 2 || len(report.Items) != 4 {
+		t.Errorf("Unexpected counts %+v", report)
+	}
+	if err := report.Err(); !errors.Is(err, errAuth) || !errors.Is(err, errGone) {
+		t.Errorf("Expected the failed items' errors joined, got %v", err)
+	}
+
+	report.Add(ItemReport{Key: "e.md", Status: ItemSkipped, SkipReason: "too large"})
+	report.Add(ItemReport{Key: "f.md", Status: ItemSkipped, SkipReason: "symbolic link"})
+	if counts := report.SkipCounts(); counts["too large"] != 2 || counts["symbolic link"] != 1 || len(counts) != 2 {
+		t.Errorf("Unexpected skip counts %v", counts)
+	}
+}
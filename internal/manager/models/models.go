@@ -5,17 +5,34 @@ import (
 )
 
 type Source struct {
-	ID           string    `json:"id"`
-	AuthorEmail  *string   `json:"author_email"`
-	RawURL       *string   `json:"raw_url"`
-	Scheme       *string   `json:"scheme"`
-	Host         *string   `json:"host"`
-	Path         *string   `json:"path"`
-	Query        *string   `json:"query"`
-	ActiveDomain int       `json:"active_domain"`
-	Format       *string   `json:"format"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID           string     `json:"id"`
+	AuthorEmail  *string    `json:"author_email"`
+	RawURL       *string    `json:"raw_url"`
+	Scheme       *string    `json:"scheme"`
+	Host         *string    `json:"host"`
+	Path         *string    `json:"path"`
+	Query        *string    `json:"query"`
+	ActiveDomain int        `json:"active_domain"`
+	Format       *string    `json:"format"`
+	Collection   string     `json:"collection"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	DeletedAt    *time.Time `json:"deleted_at"`
+}
+
+// Collection is a named corpus sources are grouped into; documents and chunks belong to the
+// collection of their source.
+type Collection struct {
+	Name        string    `json:"name"`
+	Description *string   `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// SourceOwner records a team or person as an owner of a source.
+type SourceOwner struct {
+	SourceID  string    `json:"source_id"`
+	Owner     string    `json:"owner"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 type Download struct {
@@ -26,6 +43,19 @@ type Download struct {
 	StatusCode   *int       `json:"status_code"`
 	Headers      string     `json:"headers"`
 	Body         *string    `json:"body"`
+	BodyEncoding string     `json:"body_encoding"`
+	ContentHash  string     `json:"content_hash,omitempty"`
+}
+
+// DumpSourceFormat is the format of sources imported from JSONL or CSV dumps.
+const DumpSourceFormat = "dump"
+
+// DumpRecord is one record of a JSONL or CSV dump as stored in its download body, so the
+// document can be built again without the dump file.
+type DumpRecord struct {
+	Title       string     `json:"title,omitempty"`
+	Body        string     `json:"body"`
+	PublishedAt *time.Time `json:"published_at,omitempty"`
 }
 
 type Document struct {
@@ -39,6 +69,10 @@ type Document struct {
 	PublishedAt  *time.Time `json:"published_at"`
 	ModifiedAt   *time.Time `json:"modified_at"`
 	WPVersion    *string    `json:"wp_version"`
+	DeletedAt    *time.Time `json:"deleted_at"`
+	// StaleAt is when the document outlived its source's TTL; stale documents are left out of
+	// search unless asked for.
+	StaleAt *time.Time `json:"stale_at,omitempty"`
 }
 
 type Chunk struct {
@@ -53,6 +87,8 @@ type Chunk struct {
 	TokenCount    *int    `json:"token_count"`
 	NaturalLang   *string `json:"natural_lang"`
 	CodeLang      *string `json:"code_lang"`
+	// Meta is stored in chunk_meta, each value as JSON under its key
+	Meta map[string]any `json:"meta,omitempty"`
 }
 
 type Tag struct {
@@ -76,6 +112,14 @@ type DocumentMeta struct {
 	CreatedAt  time.Time `json:"created_at"`
 }
 
+type ChunkMeta struct {
+	ID        string    `json:"id"`
+	ChunkID   string    `json:"chunk_id"`
+	Key       string    `json:"key"`
+	Meta      *string   `json:"meta"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
 type Embedding struct {
 	ID            string    `json:"id"`
 	Embedding1536 []float32 `json:"embedding_1536"`
@@ -94,3 +138,158 @@ type Request struct {
 	RequestedAt  time.Time `json:"requested_at"`
 	ResultChunks *string   `json:"result_chunks"`
 }
+
+// JobStatus is the state of a queued unit of processing work.
+type JobStatus string
+
+const (
+	JobQueued  JobStatus = "queued"
+	JobRunning JobStatus = "running"
+	JobFailed  JobStatus = "failed"
+	JobDone    JobStatus = "done"
+)
+
+// Job priorities. Workers run higher priorities first; any integer is allowed.
+const (
+	// JobPriorityLow is for bulk work such as scheduled re-imports.
+	JobPriorityLow = -10
+	// JobPriorityNormal is the default.
+	JobPriorityNormal = 0
+	// JobPriorityHigh is for interactive requests that should run next.
+	JobPriorityHigh = 10
+)
+
+type Job struct {
+	ID          string     `json:"id"`
+	Kind        string     `json:"kind"`
+	Payload     string     `json:"payload"`
+	Status      JobStatus  `json:"status"`
+	Priority    int        `json:"priority"`
+	Attempts    int        `json:"attempts"`
+	MaxAttempts int        `json:"max_attempts"`
+	Error       *string    `json:"error"`
+	WorkerID    *string    `json:"worker_id"`
+	LockedAt    *time.Time `json:"locked_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	FinishedAt  *time.Time `json:"finished_at"`
+}
+
+// SourceSettings overrides the processing options of content imported from SourceURL or any URL
+// under it. Nil fields keep the options the import was started with.
+type SourceSettings struct {
+	SourceURL      string  `json:"source_url"`
+	ChunkStrategy  *string `json:"chunk_strategy"`
+	MaxTokens      *int    `json:"max_tokens"`
+	EmbeddingModel *string `json:"embedding_model"`
+	Concurrency    *int    `json:"concurrency"`
+	// TTLSeconds is how long documents under SourceURL live, counted from when they were
+	// published, else indexed; nil keeps them forever.
+	TTLSeconds *int64 `json:"ttl_seconds,omitempty"`
+	// ExpiryAction is what happens to documents that outlive TTLSeconds; nil marks them stale.
+	ExpiryAction *ExpiryAction `json:"expiry_action,omitempty"`
+	// ACLLabels replace the access-control labels importers derive for content under SourceURL.
+	ACLLabels []string  `json:"acl_labels,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SourceCredentials are the API keys and tokens imports of content under SourceURL authenticate
+// with, in place of the process's own. Empty fields keep the credentials the import was started
+// with. URLSecrets are the credentials stripped from the source's URL when it was stored, as
+// util.SplitURLSecrets returns them.
+type SourceCredentials struct {
+	SourceURL      string    `json:"source_url"`
+	GitHubToken    string    `json:"github_token,omitempty"`
+	OpenAIAPIKey   string    `json:"openai_api_key,omitempty"`
+	TogetherAPIKey string    `json:"together_api_key,omitempty"`
+	URLSecrets     string    `json:"url_secrets,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// ExpiryAction is what happens to a document once it outlives its source's TTL.
+type ExpiryAction string
+
+const (
+	// ExpireStale keeps the document but leaves it out of search unless stale results are asked for.
+	ExpireStale ExpiryAction = "stale"
+	// ExpirePurge deletes the document with its chunks and embeddings.
+	ExpirePurge ExpiryAction = "purge"
+)
+
+// Schedule re-imports a source whenever its cron expression comes due. Options is the
+// JSON-encoded ProcessingOptions the scheduled imports run with.
+type Schedule struct {
+	ID        string     `json:"id"`
+	SourceURL string     `json:"source_url"`
+	Cron      string     `json:"cron"`
+	Options   string     `json:"options"`
+	Enabled   bool       `json:"enabled"`
+	Priority  int        `json:"priority"`
+	NextRunAt *time.Time `json:"next_run_at"`
+	LastRunAt *time.Time `json:"last_run_at"`
+	LastError *string    `json:"last_error"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// EventType is the kind of change an Event records.
+type EventType string
+
+const (
+	EventSourceImported  EventType = "source_imported"
+	EventDocumentCreated EventType = "document_created"
+	EventChunksEmbedded  EventType = "chunks_embedded"
+	EventUpdateDetected  EventType = "update_detected"
+	EventDocumentDeleted EventType = "document_deleted"
+	EventSourceDeleted   EventType = "source_deleted"
+	// EventJobFailed is recorded when a queued job fails its last attempt.
+	EventJobFailed EventType = "job_failed"
+)
+
+// Event records a change to a source or document. Actor names what caused it, such as "cli",
+// a worker ID, or a webhook, and JobID the queued job it happened in. Detail is a short
+// human-readable description such as the number of chunks embedded.
+type Event struct {
+	ID         string    `json:"id"`
+	Type       EventType `json:"type"`
+	SourceID   *string   `json:"source_id"`
+	DocumentID *string   `json:"document_id"`
+	JobID      *string   `json:"job_id"`
+	Actor      *string   `json:"actor"`
+	Detail     *string   `json:"detail"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// WebhookSubscription is an outbound webhook: events of EventTypes, or of every type when it is
+// empty, are POSTed to URL and signed with Secret.
+type WebhookSubscription struct {
+	ID         string      `json:"id"`
+	URL        string      `json:"url"`
+	Secret     string      `json:"-"`
+	EventTypes []EventType `json:"event_types"`
+	CreatedAt  time.Time   `json:"created_at"`
+	UpdatedAt  time.Time   `json:"updated_at"`
+}
+
+// WebhookDeliveryStatus is the state of an event's delivery to a webhook subscription.
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliveryDelivered WebhookDeliveryStatus = "delivered"
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "failed"
+)
+
+// WebhookDelivery is an event owed to a webhook subscription, with the URL and secret to deliver
+// it with.
+type WebhookDelivery struct {
+	SubscriptionID string                `json:"subscription_id"`
+	URL            string                `json:"url"`
+	Secret         string                `json:"-"`
+	Event          Event                 `json:"event"`
+	Status         WebhookDeliveryStatus `json:"status"`
+	Attempts       int                   `json:"attempts"`
+	LastError      *string               `json:"last_error"`
+}
@@ -0,0 +1,239 @@
+// Package notifier calls outbound webhooks when imports complete, documents change, or jobs fail,
+// so downstream systems can react without polling. Events are read from the event log, queued per
+// subscription, and delivered with retries while the daemon runs.
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/code-sleuth/ike-go/internal/manager/models"
+	"github.com/code-sleuth/ike-go/pkg/httpclient"
+	"github.com/code-sleuth/ike-go/pkg/util"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	// DefaultInterval is how often Run delivers new events.
+	DefaultInterval = 10 * time.Second
+	// MaxAttempts is how many times a delivery is tried before it is left failed.
+	MaxAttempts = 10
+
+	// deliveryBatch caps the deliveries attempted per pass.
+	deliveryBatch = 100
+	// deliveryTimeout bounds each POST to a subscriber.
+	deliveryTimeout = 10 * time.Second
+	// retryBase is the wait after the first failed attempt; it doubles with each further one.
+	retryBase = 30 * time.Second
+	// maxRetryWait caps the wait between attempts.
+	maxRetryWait = time.Hour
+	// lookback is how far before the previous pass events are queued from, to catch events whose
+	// transaction committed after a later event's.
+	lookback = 5 * time.Minute
+	// secretBytes is the size of generated secrets.
+	secretBytes = 32
+)
+
+// Headers sent with each delivery. SignatureHeader holds "sha256=" and the hex HMAC-SHA256 of
+// the body keyed with the subscription's secret, as for WordPress webhooks ike-go receives.
+const (
+	SignatureHeader = "X-Webhook-Signature"
+	EventHeader     = "X-Ike-Event"
+	DeliveryHeader  = "X-Ike-Delivery"
+)
+
+var (
+	ErrInvalidSubscription = errors.New("invalid webhook subscription")
+	ErrDeliveryRejected    = errors.New("webhook endpoint rejected the delivery")
+)
+
+// EventTypes are the event types a subscription can receive.
+var EventTypes = []models.EventType{
+	models.EventSourceImported,
+	models.EventDocumentCreated,
+	models.EventChunksEmbedded,
+	models.EventUpdateDetected,
+	models.EventDocumentDeleted,
+	models.EventSourceDeleted,
+	models.EventJobFailed,
+}
+
+// Store holds subscriptions and their deliveries; repository.WebhookSubscriptionRepository
+// implements it.
+type Store interface {
+	List() ([]models.WebhookSubscription, error)
+	QueueDeliveries(subscription models.WebhookSubscription, since time.Time) (int64, error)
+	Pending(now time.Time, limit int) ([]models.WebhookDelivery, error)
+	MarkDelivered(subscriptionID, eventID string) error
+	MarkFailed(subscriptionID, eventID string, cause error, next *time.Time) error
+}
+
+// Notifier delivers recorded events to the webhook subscriptions that receive them.
+type Notifier struct {
+	store  Store
+	client *http.Client
+	now    func() time.Time
+	logger zerolog.Logger
+	// since is when the previous pass queued events from; zero until the first pass, which
+	// catches up on everything recorded since each subscription was created.
+	since time.Time
+}
+
+func New(store Store) *Notifier {
+	return &Notifier{
+		store:  store,
+		client: httpclient.New(deliveryTimeout),
+		now:    time.Now,
+		logger: util.NewLogger(zerolog.ErrorLevel),
+	}
+}
+
+// NewSubscription validates rawURL and eventTypes and returns a subscription for them. An empty
+// secret is replaced with a random one, which the caller should show the user once.
+func NewSubscription(rawURL, secret string, eventTypes []models.EventType) (*models.WebhookSubscription, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("%w: URL must be absolute http or https, got %q", ErrInvalidSubscription, rawURL)
+	}
+	for _, eventType := range eventTypes {
+		if !slices.Contains(EventTypes, eventType) {
+			return nil, fmt.Errorf("%w: unknown event type %q", ErrInvalidSubscription, eventType)
+		}
+	}
+	if secret == "" {
+		buf := make([]byte, secretBytes)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, err
+		}
+		secret = hex.EncodeToString(buf)
+	}
+
+	return &models.WebhookSubscription{URL: rawURL, Secret: secret, EventTypes: eventTypes}, nil
+}
+
+// Run delivers new events every interval until ctx is cancelled. Failed passes are logged and
+// retried on the next tick rather than stopping the daemon.
+func (n *Notifier) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := n.Notify(ctx); err != nil && ctx.Err() == nil {
+			n.logger.Error().Err(err).Msg("Failed to deliver webhooks")
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Notify queues the events recorded since the previous pass for their subscriptions and
+// attempts the deliveries that are due, returning how many were delivered.
+func (n *Notifier) Notify(ctx context.Context) (int, error) {
+	start := n.now()
+	subscriptions, err := n.store.List()
+	if err != nil {
+		return 0, err
+	}
+	since := n.since
+	if !since.IsZero() {
+		since = since.Add(-lookback)
+	}
+	for _, subscription := range subscriptions {
+		if _, err := n.store.QueueDeliveries(subscription, since); err != nil {
+			return 0, err
+		}
+	}
+	n.since = start
+
+	deliveries, err := n.store.Pending(start, deliveryBatch)
+	if err != nil {
+		return 0, err
+	}
+	delivered := 0
+	for _, delivery := range deliveries {
+		if ctx.Err() != nil {
+			return delivered, ctx.Err()
+		}
+		if err := n.deliver(ctx, delivery); err != nil {
+			if ctx.Err() != nil {
+				// Shutting down says nothing about the endpoint; the delivery stays due
+				return delivered, ctx.Err()
+			}
+			n.logger.Warn().Err(err).Str("subscription_id", delivery.SubscriptionID).
+				Str("event_id", delivery.Event.ID).Msg("Webhook delivery failed")
+			if err := n.store.MarkFailed(delivery.SubscriptionID, delivery.Event.ID, err,
+				n.nextAttempt(delivery.Attempts+1)); err != nil {
+				return delivered, err
+			}
+			continue
+		}
+		if err := n.store.MarkDelivered(delivery.SubscriptionID, delivery.Event.ID); err != nil {
+			return delivered, err
+		}
+		delivered++
+	}
+	return delivered, nil
+}
+
+// nextAttempt returns when to retry a delivery that has failed attempts times, or nil once it has
+// used up MaxAttempts.
+func (n *Notifier) nextAttempt(attempts int) *time.Time {
+	if attempts >= MaxAttempts {
+		return nil
+	}
+	wait := min(retryBase<<(attempts-1), maxRetryWait)
+	next := n.now().Add(wait)
+	return &next
+}
+
+// deliver POSTs delivery's event as JSON. Any 2xx response counts as delivered.
+func (n *Notifier) deliver(ctx context.Context, delivery models.WebhookDelivery) error {
+	body, err := json.Marshal(delivery.Event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, string(delivery.Event.Type))
+	req.Header.Set(DeliveryHeader, delivery.Event.ID)
+	req.Header.Set(SignatureHeader, Sign(delivery.Secret, body))
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%w: %s", ErrDeliveryRejected, resp.Status)
+	}
+	return nil
+}
+
+// Sign returns the signature header value for body: "sha256=" and its hex HMAC-SHA256 keyed with
+// secret.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,159 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/code-sleuth/ike-go/internal/manager/models"
+)
+
+type markedFailure struct {
+	eventID string
+	next    *time.Time
+}
+
+type fakeStore struct {
+	subscriptions []models.WebhookSubscription
+	pending       []models.WebhookDelivery
+	queuedSince   []time.Time
+	delivered     []string
+	failed        []markedFailure
+}
+
+func (s *fakeStore) List() ([]models.WebhookSubscription, error) {
+	return s.subscriptions, nil
+}
+
+func (s *fakeStore) QueueDeliveries(_ models.WebhookSubscription, since time.Time) (int64, error) {
+	s.queuedSince = append(s.queuedSince, since)
+	return int64(len(s.pending)), nil
+}
+
+func (s *fakeStore) Pending(time.Time, int) ([]models.WebhookDelivery, error) {
+	return s.pending, nil
+}
+
+func (s *fakeStore) MarkDelivered(_, eventID string) error {
+	s.delivered = append(s.delivered, eventID)
+	return nil
+}
+
+func (s *fakeStore) MarkFailed(_, eventID string, _ error, next *time.Time) error {
+	s.failed = append(s.failed, markedFailure{eventID: eventID, next: next})
+	return nil
+}
+
+func TestNewSubscription(t *testing.T) {
+	subscription, err := NewSubscription("https://example.com/hooks", "", []models.EventType{models.EventJobFailed})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(subscription.Secret) != 2*secretBytes {
+		t.Errorf("Expected a generated hex secret, got %q", subscription.Secret)
+	}
+
+	subscription, err = NewSubscription("http://localhost:9000/", "s3cret", nil)
+	if err != nil || subscription.Secret != "s3cret" {
+		t.Errorf("Expected the given secret to be kept, got %+v, %v", subscription, err)
+	}
+
+	for _, tt := range []struct {
+		url        string
+		eventTypes []models.EventType
+	}{
+		{url: "example.com/hooks"},
+		{url: "ftp://example.com/hooks"},
+		{url: "https://example.com/hooks", eventTypes: []models.EventType{"import_finished"}},
+	} {
+		if _, err := NewSubscription(tt.url, "", tt.eventTypes); !errors.Is(err, ErrInvalidSubscription) {
+			t.Errorf("Expected ErrInvalidSubscription for %s %v, got %v", tt.url, tt.eventTypes, err)
+		}
+	}
+}
+
+func TestNotify(t *testing.T) {
+	var received []*http.Request
+	var bodies [][]byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = append(received, r)
+		bodies = append(bodies, body)
+		if r.Header.Get(DeliveryHeader) == "event-2" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	now := time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)
+	store := &fakeStore{
+		subscriptions: []models.WebhookSubscription{{ID: "sub-1", URL: server.URL, Secret: "s3cret"}},
+		pending: []models.WebhookDelivery{
+			{SubscriptionID: "sub-1", URL: server.URL, Secret: "s3cret",
+				Event: models.Event{ID: "event-1", Type: models.EventSourceImported}},
+			{SubscriptionID: "sub-1", URL: server.URL, Secret: "s3cret", Attempts: 2,
+				Event: models.Event{ID: "event-2", Type: models.EventJobFailed}},
+		},
+	}
+	notifier := New(store)
+	notifier.now = func() time.Time { return now }
+
+	delivered, err := notifier.Notify(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if delivered != 1 || len(store.delivered) != 1 || store.delivered[0] != "event-1" {
+		t.Errorf("Expected event-1 to be delivered, got %d: %v", delivered, store.delivered)
+	}
+
+	if len(received) != 2 {
+		t.Fatalf("Expected 2 requests, got %d", len(received))
+	}
+	if got := received[0].Header.Get(SignatureHeader); got != Sign("s3cret", bodies[0]) {
+		t.Errorf("Expected the body to be signed with the subscription's secret, got %s", got)
+	}
+	if got := received[0].Header.Get(EventHeader); got != string(models.EventSourceImported) {
+		t.Errorf("Expected the event type header, got %s", got)
+	}
+	var event models.Event
+	if err := json.Unmarshal(bodies[0], &event); err != nil || event.ID != "event-1" {
+		t.Errorf("Expected the event as the body, got %s (%v)", bodies[0], err)
+	}
+
+	// The failed third attempt is retried after 30s doubled twice
+	if len(store.failed) != 1 || store.failed[0].next == nil || !store.failed[0].next.Equal(now.Add(2*time.Minute)) {
+		t.Errorf("Expected event-2 to be retried in 2 minutes, got %+v", store.failed)
+	}
+
+	// The first pass catches up from the start; later ones look back from the previous pass
+	if _, err := notifier.Notify(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(store.queuedSince) != 2 || !store.queuedSince[0].IsZero() ||
+		!store.queuedSince[1].Equal(now.Add(-lookback)) {
+		t.Errorf("Expected deliveries queued since zero and then %v, got %v", now.Add(-lookback), store.queuedSince)
+	}
+}
+
+func TestNextAttempt(t *testing.T) {
+	now := time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)
+	notifier := New(&fakeStore{})
+	notifier.now = func() time.Time { return now }
+
+	if next := notifier.nextAttempt(1); next == nil || !next.Equal(now.Add(retryBase)) {
+		t.Errorf("Expected the first retry after %v, got %v", retryBase, next)
+	}
+	if next := notifier.nextAttempt(MaxAttempts - 1); next == nil || !next.Equal(now.Add(maxRetryWait)) {
+		t.Errorf("Expected retries to wait at most %v, got %v", maxRetryWait, next)
+	}
+	if next := notifier.nextAttempt(MaxAttempts); next != nil {
+		t.Errorf("Expected no retry after %d attempts, got %v", MaxAttempts, next)
+	}
+}
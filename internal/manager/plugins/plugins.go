@@ -0,0 +1,276 @@
+// Package plugins runs external importer and transformer plugins. A plugin is an executable that
+// answers one JSON-RPC 2.0 request per run: the request is written to its stdin, and it writes a
+// single response to stdout before exiting. Anything it writes to stderr is included in errors.
+//
+// Every plugin must answer "describe" with its Manifest. Plugins that declare an importer answer
+// "import" with ImportParams and ImportResult, and plugins that declare a transformer answer
+// "transform" with TransformParams and TransformResult.
+package plugins
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/code-sleuth/ike-go/pkg/util"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	// ProtocolVersion is the plugin protocol version this build speaks.
+	ProtocolVersion = 1
+
+	// Methods plugins answer.
+	MethodDescribe  = "describe"
+	MethodImport    = "import"
+	MethodTransform = "transform"
+
+	// describeTimeout bounds how long a plugin may take to describe itself.
+	describeTimeout = 10 * time.Second
+	// maxStderr is how much of a plugin's stderr is kept for error messages.
+	maxStderr = 4096
+)
+
+var (
+	ErrInvalidManifest = errors.New("invalid plugin manifest")
+	ErrPluginFailed    = errors.New("plugin failed")
+	ErrUnsupported     = errors.New("plugin does not support method")
+)
+
+// Manifest describes a plugin. URLPattern is a regular expression matched against import URLs
+// and the URLs of imported items, so it decides both which imports the plugin handles and which
+// stored sources its transformer processes.
+type Manifest struct {
+	Name            string `json:"name"`
+	SourceType      string `json:"source_type"`
+	URLPattern      string `json:"url_pattern"`
+	Importer        bool   `json:"importer"`
+	Transformer     bool   `json:"transformer"`
+	ProtocolVersion int    `json:"protocol_version"`
+}
+
+// ImportParams are the parameters of an "import" request. Paths limits the import to these items
+// when it is set, as for webhook-triggered re-imports.
+type ImportParams struct {
+	SourceURL string   `json:"source_url"`
+	Paths     []string `json:"paths,omitempty"`
+}
+
+// ImportResult is the response to an "import" request.
+type ImportResult struct {
+	Items []Item `json:"items"`
+}
+
+// Item is one fetched item. Key identifies it within the source so a resumed import skips it;
+// it defaults to URL. Format is the body's format, such as "markdown" or "html".
+type Item struct {
+	Key        string              `json:"key,omitempty"`
+	URL        string              `json:"url"`
+	Body       string              `json:"body"`
+	Format     string              `json:"format,omitempty"`
+	StatusCode int                 `json:"status_code,omitempty"`
+	Headers    map[string][]string `json:"headers,omitempty"`
+	// ACLLabels are the access-control labels of the item, such as the groups a Confluence
+	// page's restrictions allow; searches only return labeled items to callers granted one.
+	ACLLabels []string `json:"acl_labels,omitempty"`
+}
+
+// TransformParams are the parameters of a "transform" request.
+type TransformParams struct {
+	URL    string `json:"url"`
+	Body   string `json:"body"`
+	Format string `json:"format,omitempty"`
+}
+
+// TransformResult is the response to a "transform" request. Content is the text that is chunked
+// and embedded; Metadata is stored with the document.
+type TransformResult struct {
+	Content     string                 `json:"content"`
+	Language    string                 `json:"language,omitempty"`
+	PublishedAt *time.Time             `json:"published_at,omitempty"`
+	ModifiedAt  *time.Time             `json:"modified_at,omitempty"`
+	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// Plugin is a described plugin executable.
+type Plugin struct {
+	Path     string   `json:"path"`
+	Manifest Manifest `json:"manifest"`
+	pattern  *regexp.Regexp
+}
+
+type request struct {
+	JSONRPC string      `json:"jsonrpc"`
+	ID      int         `json:"id"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      int             `json:"id"`
+	Result  json.RawMessage `json:"result"`
+	Error   *rpcError       `json:"error"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Discover loads every executable file in dir, in name order. A missing dir has no plugins.
+// Plugins that fail to load, such as ones with an invalid manifest, are logged and skipped so
+// the rest still load.
+func Discover(ctx context.Context, dir string) ([]*Plugin, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	logger := util.ComponentLogger("plugins", zerolog.WarnLevel)
+	var plugins []*Plugin
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		info, err := entry.Info()
+		if err != nil {
+			logger.Warn().Err(err).Str("path", path).Msg("Skipping plugin")
+			continue
+		}
+		if !info.Mode().IsRegular() || info.Mode().Perm()&0o111 == 0 {
+			continue
+		}
+
+		plugin, err := Load(ctx, path)
+		if err != nil {
+			logger.Warn().Err(err).Str("path", path).Msg("Skipping plugin that failed to load")
+			continue
+		}
+		plugins = append(plugins, plugin)
+	}
+
+	return plugins, nil
+}
+
+// Load runs the plugin at path to read and validate its manifest.
+func Load(ctx context.Context, path string) (*Plugin, error) {
+	ctx, cancel := context.WithTimeout(ctx, describeTimeout)
+	defer cancel()
+
+	plugin := &Plugin{Path: path}
+	if err := plugin.call(ctx, MethodDescribe, nil, &plugin.Manifest); err != nil {
+		return nil, err
+	}
+
+	manifest := plugin.Manifest
+	switch {
+	case manifest.Name == "" || manifest.SourceType == "":
+		return nil, fmt.Errorf("%w: %s: name and source_type are required", ErrInvalidManifest, path)
+	case manifest.ProtocolVersion != ProtocolVersion:
+		return nil, fmt.Errorf("%w: %s: protocol_version %d, expected %d", ErrInvalidManifest, path,
+			manifest.ProtocolVersion, ProtocolVersion)
+	case !manifest.Importer && !manifest.Transformer:
+		return nil, fmt.Errorf("%w: %s: provides neither an importer nor a transformer", ErrInvalidManifest, path)
+	}
+
+	pattern, err := regexp.Compile(manifest.URLPattern)
+	if err != nil || manifest.URLPattern == "" {
+		return nil, fmt.Errorf("%w: %s: url_pattern must be a regular expression", ErrInvalidManifest, path)
+	}
+	plugin.pattern = pattern
+
+	return plugin, nil
+}
+
+// Matches reports whether url is handled by the plugin.
+func (p *Plugin) Matches(url string) bool {
+	return p.pattern.MatchString(url)
+}
+
+// Import asks the plugin to fetch the items of a source.
+func (p *Plugin) Import(ctx context.Context, params ImportParams) (*ImportResult, error) {
+	if !p.Manifest.Importer {
+		return nil, fmt.Errorf("%w: %s: %s", ErrUnsupported, p.Manifest.Name, MethodImport)
+	}
+	var result ImportResult
+	if err := p.call(ctx, MethodImport, params, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Transform asks the plugin to turn a downloaded item into document content.
+func (p *Plugin) Transform(ctx context.Context, params TransformParams) (*TransformResult, error) {
+	if !p.Manifest.Transformer {
+		return nil, fmt.Errorf("%w: %s: %s", ErrUnsupported, p.Manifest.Name, MethodTransform)
+	}
+	var result TransformResult
+	if err := p.call(ctx, MethodTransform, params, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// call runs the plugin with a single request and decodes its result into result.
+func (p *Plugin) call(ctx context.Context, method string, params, result interface{}) error {
+	input, err := json.Marshal(request{JSONRPC: "2.0", ID: 1, Method: method, Params: params})
+	if err != nil {
+		return err
+	}
+
+	var stdout bytes.Buffer
+	stderr := &tailBuffer{limit: maxStderr}
+	cmd := exec.CommandContext(ctx, p.Path) // #nosec G204 -- plugins are executables the operator installed
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%w: %s %s: %w: %s", ErrPluginFailed, filepath.Base(p.Path), method, err,
+			stderr.String())
+	}
+
+	var resp response
+	if err := json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+		return fmt.Errorf("%w: %s %s: invalid response: %w", ErrPluginFailed, filepath.Base(p.Path), method, err)
+	}
+	if resp.Error != nil {
+		return fmt.Errorf("%w: %s %s: %s (code %d)", ErrPluginFailed, filepath.Base(p.Path), method,
+			resp.Error.Message, resp.Error.Code)
+	}
+
+	if err := json.Unmarshal(resp.Result, result); err != nil {
+		return fmt.Errorf("%w: %s %s: invalid result: %w", ErrPluginFailed, filepath.Base(p.Path), method, err)
+	}
+	return nil
+}
+
+// tailBuffer keeps the last limit bytes written to it.
+type tailBuffer struct {
+	buf   []byte
+	limit int
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.buf = append(b.buf, p...)
+	if len(b.buf) > b.limit {
+		b.buf = b.buf[len(b.buf)-b.limit:]
+	}
+	return len(p), nil
+}
+
+func (b *tailBuffer) String() string {
+	return string(bytes.TrimSpace(b.buf))
+}
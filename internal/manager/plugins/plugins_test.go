@@ -0,0 +1,175 @@
+package plugins
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const describeResponse = `{"jsonrpc":"2.0","id":1,"result":{"name":"notes","source_type":"notes",` +
+	`"url_pattern":"^https://notes[.]example[.]com/","importer":true,"transformer":true,"protocol_version":1}}`
+
+// notesPlugin answers describe, import, and transform with fixed responses and rejects any other method.
+const notesPlugin = `#!/bin/sh
+request=$(cat)
+case "$request" in
+*'"describe"'*)
+	echo '` + describeResponse + `' ;;
+*'"import"'*)
+	echo '{"jsonrpc":"2.0","id":1,"result":{"items":[{"key":"a","url":"https://notes.example.com/a",` +
+	`"body":"alpha","format":"markdown"}]}}' ;;
+*'"transform"'*)
+	echo '{"jsonrpc":"2.0","id":1,"result":{"content":"ALPHA","language":"en","metadata":{"words":1}}}' ;;
+*)
+	echo '{"jsonrpc":"2.0","id":1,"error":{"code":-32601,"message":"method not found"}}' ;;
+esac
+`
+
+func writePlugin(t *testing.T, dir, name, script string, mode os.FileMode) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(script), mode); err != nil {
+		t.Fatalf("Failed to write plugin: %v", err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name        string
+		script      string
+		expectedErr error
+		description string
+	}{
+		{name: "valid", script: notesPlugin,
+			description: "should load a plugin that describes itself"},
+		{name: "missing name", expectedErr: ErrInvalidManifest,
+			script: "#!/bin/sh\necho '{\"jsonrpc\":\"2.0\",\"id\":1,\"result\":{\"source_type\":\"x\"," +
+				"\"url_pattern\":\"x\",\"importer\":true,\"protocol_version\":1}}'\n",
+			description: "should reject a manifest without a name"},
+		{name: "wrong protocol", expectedErr: ErrInvalidManifest,
+			script: "#!/bin/sh\necho '{\"jsonrpc\":\"2.0\",\"id\":1,\"result\":{\"name\":\"x\",\"source_type\":\"x\"," +
+				"\"url_pattern\":\"x\",\"importer\":true,\"protocol_version\":2}}'\n",
+			description: "should reject an unknown protocol version"},
+		{name: "no capabilities", expectedErr: ErrInvalidManifest,
+			script: "#!/bin/sh\necho '{\"jsonrpc\":\"2.0\",\"id\":1,\"result\":{\"name\":\"x\",\"source_type\":\"x\"," +
+				"\"url_pattern\":\"x\",\"protocol_version\":1}}'\n",
+			description: "should reject a plugin that provides nothing"},
+		{name: "bad pattern", expectedErr: ErrInvalidManifest,
+			script: "#!/bin/sh\necho '{\"jsonrpc\":\"2.0\",\"id\":1,\"result\":{\"name\":\"x\",\"source_type\":\"x\"," +
+				"\"url_pattern\":\"(\",\"importer\":true,\"protocol_version\":1}}'\n",
+			description: "should reject an invalid url_pattern"},
+		{name: "error response", expectedErr: ErrPluginFailed,
+			script:      "#!/bin/sh\necho '{\"jsonrpc\":\"2.0\",\"id\":1,\"error\":{\"code\":1,\"message\":\"boom\"}}'\n",
+			description: "should surface a JSON-RPC error"},
+		{name: "exit failure", expectedErr: ErrPluginFailed,
+			script:      "#!/bin/sh\necho 'missing token' >&2\nexit 3\n",
+			description: "should surface a failing exit"},
+		{name: "invalid output", expectedErr: ErrPluginFailed,
+			script:      "#!/bin/sh\necho 'hello'\n",
+			description: "should reject a response that is not JSON"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writePlugin(t, dir, strings.ReplaceAll(tt.name, " ", "-"), tt.script, 0o755)
+
+			plugin, err := Load(context.Background(), path)
+			if tt.expectedErr != nil {
+				if !errors.Is(err, tt.expectedErr) {
+					t.Errorf("Expected %v, got %v for test: %s", tt.expectedErr, err, tt.description)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error for test %s: %v", tt.description, err)
+			}
+			if plugin.Manifest.Name != "notes" || !plugin.Manifest.Importer || !plugin.Manifest.Transformer {
+				t.Errorf("Unexpected manifest %+v", plugin.Manifest)
+			}
+		})
+	}
+}
+
+func TestLoad_StderrInError(t *testing.T) {
+	path := writePlugin(t, t.TempDir(), "failing", "#!/bin/sh\necho 'missing token' >&2\nexit 3\n", 0o755)
+
+	_, err := Load(context.Background(), path)
+	if err == nil || !strings.Contains(err.Error(), "missing token") {
+		t.Errorf("Expected the plugin's stderr in the error, got %v", err)
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	dir := t.TempDir()
+	writePlugin(t, dir, "notes", notesPlugin, 0o755)
+	writePlugin(t, dir, "README", "not a plugin", 0o644)
+	if err := os.Mkdir(filepath.Join(dir, "subdir"), 0o755); err != nil {
+		t.Fatalf("Failed to create subdir: %v", err)
+	}
+
+	found, err := Discover(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(found) != 1 || found[0].Manifest.Name != "notes" {
+		t.Errorf("Expected only the executable plugin, got %d plugins", len(found))
+	}
+
+	found, err = Discover(context.Background(), filepath.Join(dir, "missing"))
+	if err != nil || len(found) != 0 {
+		t.Errorf("Expected no plugins and no error for a missing dir, got %d plugins and %v", len(found), err)
+	}
+}
+
+func TestDiscover_SkipsBrokenPlugins(t *testing.T) {
+	dir := t.TempDir()
+	writePlugin(t, dir, "broken", "#!/bin/sh\necho 'not json'\n", 0o755)
+	writePlugin(t, dir, "notes", notesPlugin, 0o755)
+
+	found, err := Discover(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("Expected a broken plugin not to fail discovery, got %v", err)
+	}
+	if len(found) != 1 || found[0].Manifest.Name != "notes" {
+		t.Errorf("Expected only the working plugin, got %d plugins", len(found))
+	}
+}
+
+func TestPlugin_ImportAndTransform(t *testing.T) {
+	plugin, err := Load(context.Background(), writePlugin(t, t.TempDir(), "notes", notesPlugin, 0o755))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !plugin.Matches("https://notes.example.com/team") || plugin.Matches("https://example.com/") {
+		t.Error("Expected the url_pattern to decide which URLs match")
+	}
+
+	imported, err := plugin.Import(context.Background(), ImportParams{SourceURL: "https://notes.example.com/team"})
+	if err != nil {
+		t.Fatalf("Unexpected import error: %v", err)
+	}
+	if len(imported.Items) != 1 || imported.Items[0].Body != "alpha" || imported.Items[0].Format != "markdown" {
+		t.Errorf("Unexpected import result %+v", imported)
+	}
+
+	transformed, err := plugin.Transform(context.Background(), TransformParams{URL: "https://notes.example.com/a",
+		Body: "alpha"})
+	if err != nil {
+		t.Fatalf("Unexpected transform error: %v", err)
+	}
+	if transformed.Content != "ALPHA" || transformed.Language != "en" || transformed.Metadata["words"] != float64(1) {
+		t.Errorf("Unexpected transform result %+v", transformed)
+	}
+
+	plugin.Manifest.Transformer = false
+	if _, err := plugin.Transform(context.Background(), TransformParams{}); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Expected ErrUnsupported for an undeclared method, got %v", err)
+	}
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/code-sleuth/ike-go/pkg/db"
+	"github.com/code-sleuth/ike-go/pkg/util"
+
+	"github.com/rs/zerolog"
+)
+
+// LabelSummary is an access-control label with the live sources labeled with it and their
+// documents.
+type LabelSummary struct {
+	Label     string `json:"label"`
+	Sources   int    `json:"sources"`
+	Documents int    `json:"documents"`
+}
+
+// ACLRepository manages the access-control labels of sources, which searches enforce on their
+// documents.
+type ACLRepository struct {
+	db     *db.DB
+	logger zerolog.Logger
+}
+
+func NewACLRepository(database *db.DB) *ACLRepository {
+	logger := util.NewLogger(zerolog.ErrorLevel)
+	return &ACLRepository{
+		db:     database,
+		logger: logger,
+	}
+}
+
+// Set replaces the labels of the source with sourceID; no labels make it searchable by everyone.
+// The next import of the source replaces them again with the labels it finds.
+func (r *ACLRepository) Set(sourceID string, labels []string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to begin transaction")
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if _, err := tx.Exec(r.db.Rebind(`DELETE FROM source_acl WHERE source_id = ?`), sourceID); err != nil {
+		r.logger.Error().Err(err).Str("source_id", sourceID).Msg("Failed to clear source labels")
+		return err
+	}
+	now := r.db.Dialect().FormatTime(time.Now())
+	var added []string
+	for _, label := range labels {
+		if label = strings.TrimSpace(label); label == "" || slices.Contains(added, label) {
+			continue
+		}
+		query := `INSERT INTO source_acl (source_id, label, created_at) VALUES (?, ?, ?)`
+		if _, err := tx.Exec(r.db.Rebind(query), sourceID, label, now); err != nil {
+			r.logger.Error().Err(err).Str("source_id", sourceID).Str("label", label).Msg("Failed to add source label")
+			return err
+		}
+		added = append(added, label)
+	}
+	return tx.Commit()
+}
+
+// List returns every label of a live source ordered by label, with what it covers.
+func (r *ACLRepository) List() ([]LabelSummary, error) {
+	query := `SELECT a.label, COUNT(DISTINCT s.id), COUNT(DISTINCT d.id)
+		FROM source_acl a
+		JOIN sources s ON s.id = a.source_id AND s.deleted_at IS NULL
+		LEFT JOIN documents d ON d.source_id = s.id AND d.deleted_at IS NULL
+		GROUP BY a.label ORDER BY a.label`
+	rows, err := r.db.Reader().Query(query)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to list labels")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var summaries []LabelSummary
+	for rows.Next() {
+		var summary LabelSummary
+		if err := rows.Scan(&summary.Label, &summary.Sources, &summary.Documents); err != nil {
+			r.logger.Error().Err(err).Msg("Failed to scan label")
+			return nil, err
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries, rows.Err()
+}
@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/code-sleuth/ike-go/internal/manager/models"
+	"github.com/code-sleuth/ike-go/pkg/db"
+	"github.com/code-sleuth/ike-go/pkg/dialect"
+	"github.com/code-sleuth/ike-go/pkg/util"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+// ChunkMetaRepository stores metadata of single chunks, such as heading paths, line ranges, and
+// page numbers, each value as JSON under its key.
+type ChunkMetaRepository struct {
+	db     *db.DB
+	logger zerolog.Logger
+}
+
+func NewChunkMetaRepository(database *db.DB) *ChunkMetaRepository {
+	logger := util.NewLogger(zerolog.ErrorLevel)
+	return &ChunkMetaRepository{
+		db:     database,
+		logger: logger,
+	}
+}
+
+// Set stores value as the metadata of chunkID under key, replacing any value already there.
+func (r *ChunkMetaRepository) Set(chunkID, key string, value any) error {
+	metaJSON, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	query := r.db.Dialect().Upsert("chunk_meta",
+		[]string{"id", "chunk_id", "key", "meta", "created_at"},
+		[]string{"chunk_id", "key"},
+		[]string{"meta", "created_at"})
+	_, err = r.db.Exec(r.db.Rebind(query), uuid.New().String(), chunkID, key, string(metaJSON),
+		r.db.Dialect().FormatTime(time.Now()))
+	if err != nil {
+		r.logger.Error().Err(err).Str("chunk_id", chunkID).Str("key", key).Msg("Failed to save chunk metadata")
+	}
+	return err
+}
+
+// List returns the metadata of chunkID ordered by key.
+func (r *ChunkMetaRepository) List(chunkID string) ([]models.ChunkMeta, error) {
+	query := `SELECT id, chunk_id, "key", meta, created_at FROM chunk_meta WHERE chunk_id = ? ORDER BY "key"`
+	return r.query(query, chunkID)
+}
+
+// ListByDocument returns the metadata of every chunk of documentID, by chunk ID.
+func (r *ChunkMetaRepository) ListByDocument(documentID string) (map[string][]models.ChunkMeta, error) {
+	query := `SELECT m.id, m.chunk_id, m."key", m.meta, m.created_at FROM chunk_meta m
+			  JOIN chunks c ON c.id = m.chunk_id
+			  WHERE c.document_id = ? ORDER BY m.chunk_id, m."key"`
+	all, err := r.query(query, documentID)
+	if err != nil {
+		return nil, err
+	}
+
+	byChunk := make(map[string][]models.ChunkMeta)
+	for _, meta := range all {
+		byChunk[meta.ChunkID] = append(byChunk[meta.ChunkID], meta)
+	}
+	return byChunk, nil
+}
+
+func (r *ChunkMetaRepository) query(query string, args ...interface{}) ([]models.ChunkMeta, error) {
+	rows, err := r.db.Reader().Query(r.db.Rebind(query), args...)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to query chunk metadata")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var all []models.ChunkMeta
+	for rows.Next() {
+		meta, err := scanChunkMeta(rows)
+		if err != nil {
+			r.logger.Error().Err(err).Msg("Failed to scan chunk metadata")
+			return nil, err
+		}
+		all = append(all, *meta)
+	}
+	return all, rows.Err()
+}
+
+func scanChunkMeta(row rowScanner) (*models.ChunkMeta, error) {
+	var meta models.ChunkMeta
+	var value sql.NullString
+	var createdAtStr string
+	if err := row.Scan(&meta.ID, &meta.ChunkID, &meta.Key, &value, &createdAtStr); err != nil {
+		return nil, err
+	}
+	if value.Valid {
+		meta.Meta = &value.String
+	}
+
+	var err error
+	if meta.CreatedAt, err = dialect.ParseTime(createdAtStr); err != nil {
+		return nil, err
+	}
+	return &meta, nil
+}
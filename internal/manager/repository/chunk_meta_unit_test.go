@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/code-sleuth/ike-go/pkg/db"
+)
+
+// Test NewChunkMetaRepository constructor
+func TestNewChunkMetaRepository_Unit(t *testing.T) {
+	dbWrapper := &db.DB{}
+	repo := NewChunkMetaRepository(dbWrapper)
+
+	if repo == nil {
+		t.Fatal("Expected non-nil repository")
+	}
+	if repo.db != dbWrapper {
+		t.Error("Expected database to be set correctly")
+	}
+}
+
+// chunkMetaRow scans a fixed chunk_meta row.
+type chunkMetaRow struct {
+	meta any
+}
+
+func (r chunkMetaRow) Scan(dest ...any) error {
+	*dest[0].(*string) = "meta-1"
+	*dest[1].(*string) = "chunk-1"
+	*dest[2].(*string) = "heading_path"
+	if r.meta != nil {
+		if err := dest[3].(interface{ Scan(any) error }).Scan(r.meta); err != nil {
+			return err
+		}
+	}
+	*dest[4].(*string) = "2026-01-02T03:04:05Z"
+	return nil
+}
+
+func TestScanChunkMeta(t *testing.T) {
+	meta, err := scanChunkMeta(chunkMetaRow{meta: `["Install","Linux"]`})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if meta.ChunkID != "chunk-1" || meta.Key != "heading_path" || meta.Meta == nil ||
+		*meta.Meta != `["Install","Linux"]` {
+		t.Errorf("Unexpected metadata %+v", meta)
+	}
+	if want := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC); !meta.CreatedAt.Equal(want) {
+		t.Errorf("Expected created_at %v, got %v", want, meta.CreatedAt)
+	}
+
+	meta, err = scanChunkMeta(chunkMetaRow{})
+	if err != nil || meta.Meta != nil {
+		t.Errorf("Expected NULL metadata to scan as nil, got %+v, %v", meta, err)
+	}
+}
@@ -0,0 +1,256 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
+	"github.com/code-sleuth/ike-go/internal/manager/models"
+	"github.com/code-sleuth/ike-go/pkg/db"
+	"github.com/code-sleuth/ike-go/pkg/dialect"
+	"github.com/code-sleuth/ike-go/pkg/util"
+
+	"github.com/rs/zerolog"
+)
+
+var (
+	ErrCollectionExists   = errors.New("collection already exists")
+	ErrCollectionNotFound = errors.New("collection not found")
+	// ErrDefaultCollection is returned for deleting the collection sources are placed in when
+	// none is chosen.
+	ErrDefaultCollection = errors.New("the default collection cannot be deleted")
+)
+
+// CollectionSummary is a collection with the live sources, documents, chunks, and embeddings in it.
+type CollectionSummary struct {
+	models.Collection
+	Sources    int `json:"sources"`
+	Documents  int `json:"documents"`
+	Chunks     int `json:"chunks"`
+	Embeddings int `json:"embeddings"`
+}
+
+// CollectionRepository manages the named corpora sources are grouped into.
+type CollectionRepository struct {
+	db     *db.DB
+	logger zerolog.Logger
+}
+
+func NewCollectionRepository(database *db.DB) *CollectionRepository {
+	logger := util.NewLogger(zerolog.ErrorLevel)
+	return &CollectionRepository{
+		db:     database,
+		logger: logger,
+	}
+}
+
+// Create records a new collection, or returns ErrCollectionExists.
+func (r *CollectionRepository) Create(collection *models.Collection) error {
+	if collection.CreatedAt.IsZero() {
+		collection.CreatedAt = time.Now().UTC()
+	}
+	query := `INSERT INTO collections (name, description, created_at) VALUES (?, ?, ?) ON CONFLICT (name) DO NOTHING`
+	result, err := r.db.Exec(r.db.Rebind(query), collection.Name, collection.Description,
+		r.db.Dialect().FormatTime(collection.CreatedAt))
+	if err != nil {
+		r.logger.Error().Err(err).Str("collection", collection.Name).Msg("Failed to create collection")
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrCollectionExists
+	}
+	return nil
+}
+
+// List returns every collection ordered by name, with what it holds.
+func (r *CollectionRepository) List() ([]CollectionSummary, error) {
+	rows, err := r.db.Reader().Query(`SELECT name, description, created_at FROM collections ORDER BY name`)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to list collections")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var summaries []CollectionSummary
+	index := make(map[string]*CollectionSummary)
+	for rows.Next() {
+		collection, err := scanCollection(rows)
+		if err != nil {
+			r.logger.Error().Err(err).Msg("Failed to scan collection")
+			return nil, err
+		}
+		summaries = append(summaries, CollectionSummary{Collection: *collection})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for i := range summaries {
+		index[summaries[i].Name] = &summaries[i]
+	}
+
+	counts := []struct {
+		query string
+		field func(summary *CollectionSummary) *int
+	}{
+		{
+			`SELECT collection, COUNT(*) FROM sources WHERE deleted_at IS NULL GROUP BY collection`,
+			func(summary *CollectionSummary) *int { return &summary.Sources },
+		},
+		{
+			`SELECT s.collection, COUNT(*) FROM documents d JOIN sources s ON s.id = d.source_id
+				WHERE d.deleted_at IS NULL AND s.deleted_at IS NULL GROUP BY s.collection`,
+			func(summary *CollectionSummary) *int { return &summary.Documents },
+		},
+		{
+			`SELECT s.collection, COUNT(*) FROM chunks c JOIN documents d ON d.id = c.document_id
+				JOIN sources s ON s.id = d.source_id
+				WHERE d.deleted_at IS NULL AND s.deleted_at IS NULL GROUP BY s.collection`,
+			func(summary *CollectionSummary) *int { return &summary.Chunks },
+		},
+		{
+			`SELECT s.collection, COUNT(*) FROM embeddings e
+				JOIN chunks c ON e.object_type = 'chunk' AND c.id = e.object_id
+				JOIN documents d ON d.id = c.document_id JOIN sources s ON s.id = d.source_id
+				WHERE d.deleted_at IS NULL AND s.deleted_at IS NULL GROUP BY s.collection`,
+			func(summary *CollectionSummary) *int { return &summary.Embeddings },
+		},
+	}
+	for _, count := range counts {
+		err := countBy(r.db.Reader(), count.query, nil, func(key string, n int) {
+			if summary, ok := index[key]; ok {
+				*count.field(summary) = n
+			}
+		})
+		if err != nil {
+			r.logger.Error().Err(err).Msg("Failed to count collection contents")
+			return nil, err
+		}
+	}
+
+	return summaries, nil
+}
+
+// Get returns the named collection.
+func (r *CollectionRepository) Get(name string) (*models.Collection, error) {
+	query := `SELECT name, description, created_at FROM collections WHERE name = ?`
+	collection, err := scanCollection(r.db.Reader().QueryRow(r.db.Rebind(query), name))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrCollectionNotFound
+	}
+	if err != nil {
+		r.logger.Error().Err(err).Str("collection", name).Msg("Failed to get collection")
+		return nil, err
+	}
+	return collection, nil
+}
+
+// Delete removes the named collection and soft-deletes its live sources with their documents, as
+// SourceRepository.Delete does, returning how many sources were deleted. With purge, every source
+// of the collection, live or soft-deleted, is permanently removed with its content instead.
+// Restoring a soft-deleted source brings its collection back.
+func (r *CollectionRepository) Delete(name string, purge bool) (int, error) {
+	if name == interfaces.DefaultCollection {
+		return 0, ErrDefaultCollection
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to begin transaction")
+		return 0, err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	result, err := tx.Exec(r.db.Rebind(`DELETE FROM collections WHERE name = ?`), name)
+	if err != nil {
+		r.logger.Error().Err(err).Str("collection", name).Msg("Failed to delete collection")
+		return 0, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	if affected == 0 {
+		return 0, ErrCollectionNotFound
+	}
+
+	sourceIDs := `SELECT id FROM sources WHERE collection = ?`
+	detail := "purged"
+	if !purge {
+		sourceIDs += ` AND deleted_at IS NULL`
+		detail = "soft-deleted"
+	}
+	ids, err := queryIDs(tx, r.db.Rebind(sourceIDs), name)
+	if err != nil {
+		r.logger.Error().Err(err).Str("collection", name).Msg("Failed to list collection sources")
+		return 0, err
+	}
+	for _, id := range ids {
+		if err := recordSourceDeletion(tx, r.db.Dialect(), id, detail+" with collection "+name); err != nil {
+			r.logger.Error().Err(err).Str("source_id", id).Msg("Failed to record source deletion")
+			return 0, err
+		}
+	}
+
+	if purge {
+		err = purgeSources(tx, r.db.Dialect(), sourceIDs, name)
+	} else {
+		err = softDeleteSources(tx, r.db.Dialect(), sourceIDs, name)
+	}
+	if err != nil {
+		r.logger.Error().Err(err).Str("collection", name).Msg("Failed to delete collection sources")
+		return 0, err
+	}
+
+	return len(ids), tx.Commit()
+}
+
+// softDeleteSources soft-deletes the live sources selected by the sourceIDs subquery and their live
+// documents, sharing one deletion timestamp so SourceRepository.Restore brings back each batch.
+func softDeleteSources(tx *sql.Tx, d dialect.Dialect, sourceIDs string, args ...interface{}) error {
+	deletedAt := d.FormatTime(time.Now())
+	// #nosec G202 -- the subquery is a constant, values are bound through args
+	statements := []string{
+		`UPDATE documents SET deleted_at = ? WHERE deleted_at IS NULL AND source_id IN (` + sourceIDs + `)`,
+		`UPDATE sources SET deleted_at = ? WHERE deleted_at IS NULL AND id IN (` + sourceIDs + `)`,
+	}
+	for _, statement := range statements {
+		if _, err := tx.Exec(d.Rebind(statement), append([]interface{}{deletedAt}, args...)...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ensureCollection records the named collection unless it exists, so sources placed in a
+// collection by name always find it listed.
+func ensureCollection(ctx context.Context, exec Execer, d dialect.Dialect, name string) error {
+	query := `INSERT INTO collections (name, created_at) VALUES (?, ?) ON CONFLICT (name) DO NOTHING`
+	_, err := exec.ExecContext(ctx, d.Rebind(query), collectionOrDefault(name), d.FormatTime(time.Now()))
+	return err
+}
+
+func scanCollection(row rowScanner) (*models.Collection, error) {
+	var collection models.Collection
+	var description sql.NullString
+	var createdAtStr string
+	if err := row.Scan(&collection.Name, &description, &createdAtStr); err != nil {
+		return nil, err
+	}
+	if description.Valid {
+		collection.Description = &description.String
+	}
+
+	var err error
+	if collection.CreatedAt, err = dialect.ParseTime(createdAtStr); err != nil {
+		return nil, err
+	}
+	return &collection, nil
+}
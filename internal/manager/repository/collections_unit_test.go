@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
+	"github.com/code-sleuth/ike-go/pkg/db"
+)
+
+// Test NewCollectionRepository constructor
+func TestNewCollectionRepository_Unit(t *testing.T) {
+	dbWrapper := &db.DB{}
+	repo := NewCollectionRepository(dbWrapper)
+
+	if repo == nil {
+		t.Fatal("Expected non-nil repository")
+	}
+	if repo.db != dbWrapper {
+		t.Error("Expected database to be set correctly")
+	}
+}
+
+// The default collection is refused before the database is touched
+func TestCollectionRepository_Delete_Default(t *testing.T) {
+	repo := NewCollectionRepository(&db.DB{})
+	if _, err := repo.Delete(interfaces.DefaultCollection, false); !errors.Is(err, ErrDefaultCollection) {
+		t.Errorf("Expected ErrDefaultCollection, got %v", err)
+	}
+}
+
+func TestScoped(t *testing.T) {
+	query, args := scoped("SELECT 1 WHERE x", " AND collection = ?", "wiki")
+	if query != "SELECT 1 WHERE x AND collection = ?" || len(args) != 1 || args[0] != "wiki" {
+		t.Errorf("Expected the scope bound to the collection, got %q %v", query, args)
+	}
+
+	for _, tt := range []struct{ scope, collection string }{{" AND collection = ?", ""}, {"", "wiki"}} {
+		query, args := scoped("SELECT 1", tt.scope, tt.collection)
+		if query != "SELECT 1" || args != nil {
+			t.Errorf("Expected the query unscoped for %+v, got %q %v", tt, query, args)
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/code-sleuth/ike-go/internal/manager/models"
+	"github.com/code-sleuth/ike-go/pkg/db"
+	"github.com/code-sleuth/ike-go/pkg/dialect"
+	"github.com/code-sleuth/ike-go/pkg/util"
+
+	"github.com/rs/zerolog"
+)
+
+var errDocumentNotFound = errors.New("document not found")
+
+type DocumentRepository struct {
+	db     *db.DB
+	logger zerolog.Logger
+}
+
+func NewDocumentRepository(database *db.DB) *DocumentRepository {
+	logger := util.NewLogger(zerolog.ErrorLevel)
+	return &DocumentRepository{
+		db:     database,
+		logger: logger,
+	}
+}
+
+func (r *DocumentRepository) GetByID(id string) (*models.Document, error) {
+	query := `
+		SELECT id, source_id, download_id, format, indexed_at, min_chunk_size, max_chunk_size,
+		published_at, modified_at, wp_version, stale_at
+		FROM documents WHERE id = ? AND deleted_at IS NULL
+	`
+	document, err := scanDocument(r.db.Reader().QueryRow(r.db.Rebind(query), id))
+	if errors.Is(err, sql.ErrNoRows) {
+		r.logger.Error().Str("document_id", id).Msg("Document not found")
+		return nil, errDocumentNotFound
+	}
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to get document")
+		return nil, err
+	}
+
+	return document, nil
+}
+
+// DocumentListOptions filters, sorts, and pages DocumentRepository.ListWithOptions.
+type DocumentListOptions struct {
+	ListOptions
+	SourceID      string
+	Format        string
+	IndexedAfter  time.Time
+	IndexedBefore time.Time
+}
+
+var documentSortFields = map[string]bool{
+	"indexed_at":   true,
+	"published_at": true,
+	"modified_at":  true,
+	"format":       true,
+}
+
+// List returns every live document, most recently indexed first.
+func (r *DocumentRepository) List() ([]models.Document, error) {
+	return r.ListWithOptions(DocumentListOptions{})
+}
+
+// ListWithOptions returns the live documents matching opts.
+func (r *DocumentRepository) ListWithOptions(opts DocumentListOptions) ([]models.Document, error) {
+	var where filters
+	where.add("deleted_at IS NULL")
+	if opts.SourceID != "" {
+		where.add("source_id = ?", opts.SourceID)
+	}
+	if opts.Format != "" {
+		where.add("format = ?", opts.Format)
+	}
+	where.addTimeRange(r.db.Dialect(), "indexed_at", opts.IndexedAfter, opts.IndexedBefore)
+
+	tail, tailArgs, err := opts.orderAndLimit(documentSortFields, "indexed_at", SortDesc)
+	if err != nil {
+		return nil, err
+	}
+
+	// #nosec G202 -- clauses are built from constants, values are bound through args
+	query := `
+		SELECT id, source_id, download_id, format, indexed_at, min_chunk_size, max_chunk_size,
+		published_at, modified_at, wp_version, stale_at
+		FROM documents` + where.where() + tail
+	rows, err := r.db.Reader().Query(r.db.Rebind(query), append(where.args, tailArgs...)...)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to list documents")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var documents []models.Document
+	for rows.Next() {
+		document, err := scanDocument(rows)
+		if err != nil {
+			r.logger.Error().Err(err).Msg("Failed to scan document")
+			return nil, err
+		}
+		documents = append(documents, *document)
+	}
+
+	return documents, rows.Err()
+}
+
+// Delete soft-deletes a document. Use Restore to undo it or Purge to remove it permanently.
+func (r *DocumentRepository) Delete(id string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to begin transaction")
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	query := `UPDATE documents SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL`
+	result, err := tx.Exec(r.db.Rebind(query), r.db.Dialect().FormatTime(time.Now()), id)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to delete document")
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected > 0 {
+		if err := RecordDocumentDeletion(context.Background(), tx, r.db.Dialect(), id, "soft-deleted"); err != nil {
+			r.logger.Error().Err(err).Str("document_id", id).Msg("Failed to record document deletion")
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Restore undoes a soft delete.
+func (r *DocumentRepository) Restore(id string) error {
+	query := `UPDATE documents SET deleted_at = NULL WHERE id = ? AND deleted_at IS NOT NULL`
+	result, err := r.db.Exec(r.db.Rebind(query), id)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to restore document")
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		r.logger.Error().Str("document_id", id).Msg("Deleted document not found")
+		return errDocumentNotFound
+	}
+	return nil
+}
+
+// Purge permanently removes a document together with its metadata, tags, chunks, and embeddings.
+func (r *DocumentRepository) Purge(id string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to begin transaction")
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if err := RecordDocumentDeletion(context.Background(), tx, r.db.Dialect(), id, "purged"); err != nil {
+		r.logger.Error().Err(err).Str("document_id", id).Msg("Failed to record document deletion")
+		return err
+	}
+	if err := purgeDocuments(tx, r.db.Dialect(), `SELECT ? AS id`, id); err != nil {
+		r.logger.Error().Err(err).Str("document_id", id).Msg("Failed to purge document")
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanDocument(row rowScanner) (*models.Document, error) {
+	var document models.Document
+	var indexedAt, publishedAt, modifiedAt, staleAt sql.NullString
+	err := row.Scan(&document.ID, &document.SourceID, &document.DownloadID, &document.Format, &indexedAt,
+		&document.MinChunkSize, &document.MaxChunkSize, &publishedAt, &modifiedAt, &document.WPVersion, &staleAt)
+	if err != nil {
+		return nil, err
+	}
+
+	document.IndexedAt = parseNullTime(indexedAt)
+	document.PublishedAt = parseNullTime(publishedAt)
+	document.ModifiedAt = parseNullTime(modifiedAt)
+	document.StaleAt = parseNullTime(staleAt)
+
+	return &document, nil
+}
+
+func parseNullTime(value sql.NullString) *time.Time {
+	if !value.Valid {
+		return nil
+	}
+	if t, err := dialect.ParseTime(value.String); err == nil {
+		return &t
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/code-sleuth/ike-go/pkg/db"
+)
+
+// Test NewDocumentRepository constructor
+func TestNewDocumentRepository_Unit(t *testing.T) {
+	dbWrapper := &db.DB{}
+	repo := NewDocumentRepository(dbWrapper)
+
+	if repo == nil {
+		t.Fatal("Expected non-nil repository")
+	}
+	if repo.db != dbWrapper {
+		t.Error("Expected database to be set correctly")
+	}
+}
+
+// Test nullable timestamp parsing
+func TestParseNullTime(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    sql.NullString
+		expected *time.Time
+	}{
+		{
+			name:     "null value",
+			value:    sql.NullString{},
+			expected: nil,
+		},
+		{
+			name:     "RFC3339 with offset",
+			value:    sql.NullString{String: "2025-06-01T10:00:00+02:00", Valid: true},
+			expected: timePtr(time.Date(2025, 6, 1, 8, 0, 0, 0, time.UTC)),
+		},
+		{
+			name:     "SQLite datetime format",
+			value:    sql.NullString{String: "2025-06-01 10:00:00", Valid: true},
+			expected: timePtr(time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)),
+		},
+		{
+			name:     "unparseable value",
+			value:    sql.NullString{String: "yesterday", Valid: true},
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := parseNullTime(tt.value)
+			if tt.expected == nil {
+				if result != nil {
+					t.Errorf("Expected nil, got %v", result)
+				}
+				return
+			}
+			if result == nil || !result.Equal(*tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
@@ -0,0 +1,224 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/code-sleuth/ike-go/internal/manager/models"
+	"github.com/code-sleuth/ike-go/pkg/blobstore"
+	"github.com/code-sleuth/ike-go/pkg/compression"
+	"github.com/code-sleuth/ike-go/pkg/db"
+	"github.com/code-sleuth/ike-go/pkg/util"
+
+	"github.com/rs/zerolog"
+)
+
+const compressBatchSize = 100
+
+var errDownloadNotFound = errors.New("download not found")
+
+type DownloadRepository struct {
+	db     *db.DB
+	logger zerolog.Logger
+}
+
+func NewDownloadRepository(database *db.DB) *DownloadRepository {
+	logger := util.NewLogger(zerolog.ErrorLevel)
+	return &DownloadRepository{
+		db:     database,
+		logger: logger,
+	}
+}
+
+// GetByID returns a download with its body decompressed, resolving bodies stored in download_bodies
+// and the blob store.
+func (r *DownloadRepository) GetByID(id string) (*models.Download, error) {
+	query := `
+		SELECT d.id, d.source_id, d.attempted_at, d.downloaded_at, d.status_code, d.headers,
+			COALESCE(b.body, d.body), COALESCE(b.body_encoding, d.body_encoding), d.content_hash, b.blob_key
+		FROM downloads d LEFT JOIN download_bodies b ON b.content_hash = d.content_hash
+		WHERE d.id = ?
+	`
+	row := r.db.Reader().QueryRow(r.db.Rebind(query), id)
+
+	var download models.Download
+	var attemptedAt, downloadedAt, body, contentHash, blobKey sql.NullString
+	var statusCode sql.NullInt32
+	err := row.Scan(&download.ID, &download.SourceID, &attemptedAt, &downloadedAt,
+		&statusCode, &download.Headers, &body, &download.BodyEncoding, &contentHash, &blobKey)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		r.logger.Error().Str("download_id", id).Msg("Download not found")
+		return nil, errDownloadNotFound
+	}
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to get download")
+		return nil, err
+	}
+
+	download.AttemptedAt = parseNullTime(attemptedAt)
+	download.DownloadedAt = parseNullTime(downloadedAt)
+	if statusCode.Valid {
+		code := int(statusCode.Int32)
+		download.StatusCode = &code
+	}
+	if contentHash.Valid {
+		download.ContentHash = contentHash.String
+	}
+	switch {
+	case blobKey.Valid:
+		decoded, err := blobstore.Default().ReadBody(context.Background(), blobKey.String, download.BodyEncoding)
+		if err != nil {
+			r.logger.Error().Err(err).Str("download_id", id).Msg("Failed to read download body")
+			return nil, err
+		}
+		download.Body = &decoded
+	case body.Valid:
+		decoded, err := compression.DecodeBody([]byte(body.String), download.BodyEncoding)
+		if err != nil {
+			r.logger.Error().Err(err).Str("download_id", id).Msg("Failed to decode download body")
+			return nil, err
+		}
+		download.Body = &decoded
+	}
+
+	return &download, nil
+}
+
+// DownloadListOptions filters, sorts, and pages DownloadRepository.List.
+type DownloadListOptions struct {
+	ListOptions
+	SourceID         string
+	StatusCode       *int
+	DownloadedAfter  time.Time
+	DownloadedBefore time.Time
+}
+
+var downloadSortFields = map[string]bool{
+	"downloaded_at": true,
+	"attempted_at":  true,
+	"status_code":   true,
+}
+
+// List returns the downloads matching opts, newest first by default. Bodies are not loaded;
+// use GetByID to fetch one.
+func (r *DownloadRepository) List(opts DownloadListOptions) ([]models.Download, error) {
+	var where filters
+	if opts.SourceID != "" {
+		where.add("source_id = ?", opts.SourceID)
+	}
+	if opts.StatusCode != nil {
+		where.add("status_code = ?", *opts.StatusCode)
+	}
+	where.addTimeRange(r.db.Dialect(), "downloaded_at", opts.DownloadedAfter, opts.DownloadedBefore)
+
+	tail, tailArgs, err := opts.orderAndLimit(downloadSortFields, "downloaded_at", SortDesc)
+	if err != nil {
+		return nil, err
+	}
+
+	// #nosec G202 -- clauses are built from constants, values are bound through args
+	query := `
+		SELECT id, source_id, attempted_at, downloaded_at, status_code, headers, body_encoding, content_hash
+		FROM downloads` + where.where() + tail
+	rows, err := r.db.Reader().Query(r.db.Rebind(query), append(where.args, tailArgs...)...)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to list downloads")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var downloads []models.Download
+	for rows.Next() {
+		var download models.Download
+		var attemptedAt, downloadedAt, contentHash sql.NullString
+		var statusCode sql.NullInt32
+		err := rows.Scan(&download.ID, &download.SourceID, &attemptedAt, &downloadedAt,
+			&statusCode, &download.Headers, &download.BodyEncoding, &contentHash)
+		if err != nil {
+			r.logger.Error().Err(err).Msg("Failed to scan download")
+			return nil, err
+		}
+
+		download.AttemptedAt = parseNullTime(attemptedAt)
+		download.DownloadedAt = parseNullTime(downloadedAt)
+		if statusCode.Valid {
+			code := int(statusCode.Int32)
+			download.StatusCode = &code
+		}
+		download.ContentHash = contentHash.String
+
+		downloads = append(downloads, download)
+	}
+
+	return downloads, rows.Err()
+}
+
+// CompressBodies gzips the bodies of existing uncompressed downloads that are at least minSize bytes,
+// returning the number of rows rewritten. Rows are walked by ID in batches so large tables are not
+// loaded into memory at once.
+func (r *DownloadRepository) CompressBodies(minSize int) (int, error) {
+	query := `
+		SELECT id, body FROM downloads
+		WHERE body_encoding = ? AND body IS NOT NULL AND length(body) >= ? AND id > ?
+		ORDER BY id LIMIT ?
+	`
+
+	compressed := 0
+	lastID := ""
+	for {
+		batch, err := r.identityBodies(query, minSize, lastID)
+		if err != nil {
+			return compressed, err
+		}
+		if len(batch) == 0 {
+			return compressed, nil
+		}
+
+		for _, download := range batch {
+			lastID = download.ID
+
+			value, encoding, err := compression.EncodeBody(*download.Body, minSize)
+			if err != nil {
+				r.logger.Error().Err(err).Str("download_id", download.ID).Msg("Failed to compress body")
+				return compressed, err
+			}
+			if encoding == compression.EncodingIdentity {
+				continue
+			}
+
+			_, err = r.db.Exec(r.db.Rebind(`UPDATE downloads SET body = ?, body_encoding = ? WHERE id = ?`),
+				value, encoding, download.ID)
+			if err != nil {
+				r.logger.Error().Err(err).Str("download_id", download.ID).Msg("Failed to update body")
+				return compressed, err
+			}
+			compressed++
+		}
+	}
+}
+
+func (r *DownloadRepository) identityBodies(query string, minSize int, afterID string) ([]models.Download, error) {
+	rows, err := r.db.Query(r.db.Rebind(query), compression.EncodingIdentity, minSize, afterID, compressBatchSize)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to query uncompressed downloads")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var downloads []models.Download
+	for rows.Next() {
+		var download models.Download
+		var body string
+		if err := rows.Scan(&download.ID, &body); err != nil {
+			r.logger.Error().Err(err).Msg("Failed to scan download")
+			return nil, err
+		}
+		download.Body = &body
+		downloads = append(downloads, download)
+	}
+
+	return downloads, rows.Err()
+}
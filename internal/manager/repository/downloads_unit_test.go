@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/code-sleuth/ike-go/pkg/db"
+)
+
+// Test NewDownloadRepository constructor
+func TestNewDownloadRepository_Unit(t *testing.T) {
+	dbWrapper := &db.DB{}
+	repo := NewDownloadRepository(dbWrapper)
+
+	if repo == nil {
+		t.Fatal("Expected non-nil repository")
+	}
+	if repo.db != dbWrapper {
+		t.Error("Expected database to be set correctly")
+	}
+}
+
+// Test error constants
+func TestDownloadRepository_ErrorConstants(t *testing.T) {
+	if errDownloadNotFound.Error() != "download not found" {
+		t.Errorf("Expected 'download not found', got '%s'", errDownloadNotFound.Error())
+	}
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/code-sleuth/ike-go/pkg/db"
+	"github.com/code-sleuth/ike-go/pkg/util"
+	"github.com/code-sleuth/ike-go/pkg/vector"
+
+	"github.com/rs/zerolog"
+)
+
+// embeddingColumns lists the vector columns of the embeddings table.
+var embeddingColumns = []string{"embedding_768", "embedding_1536", "embedding_3072"}
+
+type EmbeddingRepository struct {
+	db     *db.DB
+	logger zerolog.Logger
+}
+
+func NewEmbeddingRepository(database *db.DB) *EmbeddingRepository {
+	logger := util.NewLogger(zerolog.ErrorLevel)
+	return &EmbeddingRepository{
+		db:     database,
+		logger: logger,
+	}
+}
+
+// ConvertLegacyVectors re-encodes embeddings still stored in the old "[[0.1 0.2 ...]]" text format
+// as float32 BLOBs, returning the number of vectors converted.
+func (r *EmbeddingRepository) ConvertLegacyVectors() (int, error) {
+	converted := 0
+	for _, column := range embeddingColumns {
+		count, err := r.convertColumn(column)
+		converted += count
+		if err != nil {
+			return converted, err
+		}
+	}
+	return converted, nil
+}
+
+func (r *EmbeddingRepository) convertColumn(column string) (int, error) {
+	// #nosec G201 -- column names come from embeddingColumns, not user input
+	selectQuery := fmt.Sprintf(`SELECT id, %s FROM embeddings WHERE typeof(%s) = 'text' LIMIT ?`, column, column)
+	// #nosec G201 -- column names come from embeddingColumns, not user input
+	updateQuery := fmt.Sprintf(`UPDATE embeddings SET %s = ? WHERE id = ?`, column)
+
+	converted := 0
+	for {
+		legacy, err := r.legacyVectors(selectQuery)
+		if err != nil {
+			return converted, err
+		}
+		if len(legacy) == 0 {
+			return converted, nil
+		}
+
+		for id, text := range legacy {
+			values, err := vector.ParseLegacy(text)
+			if err != nil {
+				r.logger.Error().Err(err).Str("embedding_id", id).Msg("Failed to parse legacy vector")
+				return converted, err
+			}
+			if _, err := r.db.Exec(r.db.Rebind(updateQuery), vector.Encode(values), id); err != nil {
+				r.logger.Error().Err(err).Str("embedding_id", id).Msg("Failed to update vector")
+				return converted, err
+			}
+			converted++
+		}
+	}
+}
+
+func (r *EmbeddingRepository) legacyVectors(query string) (map[string]string, error) {
+	rows, err := r.db.Query(r.db.Rebind(query), compressBatchSize)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to query legacy vectors")
+		return nil, err
+	}
+	defer rows.Close()
+
+	legacy := make(map[string]string)
+	for rows.Next() {
+		var id, text string
+		if err := rows.Scan(&id, &text); err != nil {
+			r.logger.Error().Err(err).Msg("Failed to scan legacy vector")
+			return nil, err
+		}
+		legacy[id] = text
+	}
+
+	return legacy, rows.Err()
+}
@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/code-sleuth/ike-go/pkg/db"
+)
+
+// Test NewEmbeddingRepository constructor
+func TestNewEmbeddingRepository_Unit(t *testing.T) {
+	dbWrapper := &db.DB{}
+	repo := NewEmbeddingRepository(dbWrapper)
+
+	if repo == nil {
+		t.Fatal("Expected non-nil repository")
+	}
+	if repo.db != dbWrapper {
+		t.Error("Expected database to be set correctly")
+	}
+}
+
+// Test every vector column is converted
+func TestEmbeddingRepository_Columns(t *testing.T) {
+	expected := map[string]bool{"embedding_768": true, "embedding_1536": true, "embedding_3072": true}
+	if len(embeddingColumns) != len(expected) {
+		t.Fatalf("Expected %d columns, got %d", len(expected), len(embeddingColumns))
+	}
+	for _, column := range embeddingColumns {
+		if !expected[column] {
+			t.Errorf("Unexpected column %s", column)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
+	"github.com/code-sleuth/ike-go/internal/manager/models"
+	"github.com/code-sleuth/ike-go/pkg/db"
+	"github.com/code-sleuth/ike-go/pkg/dialect"
+	"github.com/code-sleuth/ike-go/pkg/util"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+// Execer runs a statement; *sql.DB and *sql.Tx implement it, so events can be recorded in the
+// transaction that makes the change they describe.
+type Execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// RecordEvent appends event to the events table. ID and CreatedAt are filled in when unset, and
+// Actor and JobID are taken from ctx (see interfaces.WithActor and interfaces.WithJobID) unless
+// already set.
+func RecordEvent(ctx context.Context, exec Execer, d dialect.Dialect, event *models.Event) error {
+	if event.ID == "" {
+		event.ID = uuid.New().String()
+	}
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now().UTC()
+	}
+	if event.Actor == nil {
+		event.Actor = optionalString(interfaces.ActorFromContext(ctx))
+	}
+	if event.JobID == nil {
+		event.JobID = optionalString(interfaces.JobIDFromContext(ctx))
+	}
+
+	query := `
+		INSERT INTO events (id, type, source_id, document_id, job_id, actor, detail, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err := exec.ExecContext(ctx, d.Rebind(query), event.ID, event.Type, event.SourceID, event.DocumentID,
+		event.JobID, event.Actor, event.Detail, d.FormatTime(event.CreatedAt))
+	return err
+}
+
+// optionalString returns nil for "" so empty values are stored as NULL.
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// EventRepository reads the log of changes made to sources and documents.
+type EventRepository struct {
+	db     *db.DB
+	logger zerolog.Logger
+}
+
+func NewEventRepository(database *db.DB) *EventRepository {
+	logger := util.NewLogger(zerolog.ErrorLevel)
+	return &EventRepository{
+		db:     database,
+		logger: logger,
+	}
+}
+
+// EventListOptions filters, sorts, and pages EventRepository.List.
+type EventListOptions struct {
+	ListOptions
+	Type       models.EventType
+	SourceID   string
+	DocumentID string
+	JobID      string
+	After      time.Time
+	Before     time.Time
+}
+
+var eventSortFields = map[string]bool{
+	"created_at": true,
+	"type":       true,
+}
+
+// List returns the events matching opts, most recent first.
+func (r *EventRepository) List(opts EventListOptions) ([]models.Event, error) {
+	var where filters
+	if opts.Type != "" {
+		where.add("type = ?", opts.Type)
+	}
+	if opts.SourceID != "" {
+		where.add("source_id = ?", opts.SourceID)
+	}
+	if opts.DocumentID != "" {
+		where.add("document_id = ?", opts.DocumentID)
+	}
+	if opts.JobID != "" {
+		where.add("job_id = ?", opts.JobID)
+	}
+	where.addTimeRange(r.db.Dialect(), "created_at", opts.After, opts.Before)
+
+	tail, tailArgs, err := opts.orderAndLimit(eventSortFields, "created_at", SortDesc)
+	if err != nil {
+		return nil, err
+	}
+
+	// #nosec G202 -- clauses are built from constants, values are bound through args
+	query := `SELECT id, type, source_id, document_id, job_id, actor, detail, created_at
+		FROM events` + where.where() + tail
+	rows, err := r.db.Reader().Query(r.db.Rebind(query), append(where.args, tailArgs...)...)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to list events")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []models.Event
+	for rows.Next() {
+		var event models.Event
+		var createdAt string
+		err := rows.Scan(&event.ID, &event.Type, &event.SourceID, &event.DocumentID, &event.JobID, &event.Actor,
+			&event.Detail, &createdAt)
+		if err != nil {
+			r.logger.Error().Err(err).Msg("Failed to scan event")
+			return nil, err
+		}
+		if event.CreatedAt, err = dialect.ParseTime(createdAt); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+// RecordDocumentDeletion records in tx that document id was deleted; detail says how. Nothing is
+// recorded for a document that does not exist.
+func RecordDocumentDeletion(ctx context.Context, tx *sql.Tx, d dialect.Dialect, id, detail string) error {
+	var sourceID sql.NullString
+	err := tx.QueryRowContext(ctx, d.Rebind(`SELECT source_id FROM documents WHERE id = ?`), id).Scan(&sourceID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return RecordEvent(ctx, tx, d, &models.Event{
+		Type: models.EventDocumentDeleted, SourceID: optionalString(sourceID.String), DocumentID: &id,
+		Detail: &detail,
+	})
+}
+
+// recordSourceDeletion records in tx that source id was deleted; detail says how.
+func recordSourceDeletion(tx *sql.Tx, d dialect.Dialect, id, detail string) error {
+	return RecordEvent(context.Background(), tx, d, &models.Event{
+		Type: models.EventSourceDeleted, SourceID: &id, Detail: &detail,
+	})
+}
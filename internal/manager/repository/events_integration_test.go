@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
+	"github.com/code-sleuth/ike-go/internal/manager/models"
+	"github.com/code-sleuth/ike-go/internal/manager/testutil"
+	"github.com/code-sleuth/ike-go/pkg/db"
+)
+
+func TestEventRepository_List_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	testDB := testutil.SetupTestDB(t)
+	defer testutil.CleanupTestDB(t, testDB)
+
+	dbWrapper := &db.DB{DB: testDB}
+	repo := NewEventRepository(dbWrapper)
+	ctx := interfaces.WithActor(context.Background(), "cli")
+
+	sourceID, documentID := "events-source", "events-document"
+	base := time.Now().UTC().Add(-time.Hour).Truncate(time.Second)
+	for i, event := range []*models.Event{
+		{Type: models.EventSourceImported, SourceID: &sourceID},
+		{Type: models.EventDocumentCreated, SourceID: &sourceID, DocumentID: &documentID},
+		{Type: models.EventChunksEmbedded, SourceID: &sourceID, DocumentID: &documentID},
+	} {
+		event.CreatedAt = base.Add(time.Duration(i) * time.Minute)
+		if err := RecordEvent(ctx, testDB, dbWrapper.Dialect(), event); err != nil {
+			t.Fatalf("Failed to record event: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		opts     EventListOptions
+		expected []models.EventType
+	}{
+		{name: "by source", opts: EventListOptions{SourceID: sourceID}, expected: []models.EventType{
+			models.EventChunksEmbedded, models.EventDocumentCreated, models.EventSourceImported,
+		}},
+		{name: "by document", opts: EventListOptions{DocumentID: documentID}, expected: []models.EventType{
+			models.EventChunksEmbedded, models.EventDocumentCreated,
+		}},
+		{name: "by type", opts: EventListOptions{Type: models.EventSourceImported}, expected: []models.EventType{
+			models.EventSourceImported,
+		}},
+		{name: "after", opts: EventListOptions{After: base.Add(90 * time.Second)}, expected: []models.EventType{
+			models.EventChunksEmbedded,
+		}},
+		{name: "limit", opts: EventListOptions{ListOptions: ListOptions{Limit: 1}}, expected: []models.EventType{
+			models.EventChunksEmbedded,
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, err := repo.List(tt.opts)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(events) != len(tt.expected) {
+				t.Fatalf("Expected %d events, got %d", len(tt.expected), len(events))
+			}
+			for i, event := range events {
+				if event.Type != tt.expected[i] {
+					t.Errorf("Expected event %d to be %s, got %s", i, tt.expected[i], event.Type)
+				}
+				if event.Actor == nil || *event.Actor != "cli" {
+					t.Errorf("Expected actor cli, got %v", event.Actor)
+				}
+			}
+		})
+	}
+}
+
+func TestSourceRepository_Delete_RecordsEvent_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	testDB := testutil.SetupTestDB(t)
+	defer testutil.CleanupTestDB(t, testDB)
+
+	dbWrapper := &db.DB{DB: testDB}
+	sources := NewSourceRepository(dbWrapper)
+	source := &models.Source{
+		ID:        "deleted-source",
+		RawURL:    stringPtrInteg("https://example.com/wp-json/wp/v2/posts"),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := sources.Create(source); err != nil {
+		t.Fatalf("Failed to create source: %v", err)
+	}
+
+	// Deleting twice only records the first deletion
+	for range 2 {
+		if err := sources.Delete(source.ID); err != nil {
+			t.Fatalf("Failed to delete source: %v", err)
+		}
+	}
+
+	events, err := NewEventRepository(dbWrapper).List(EventListOptions{SourceID: source.ID})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(events) != 1 || events[0].Type != models.EventSourceDeleted || *events[0].Detail != "soft-deleted" {
+		t.Errorf("Expected one soft-deleted source_deleted event, got %+v", events)
+	}
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
+	"github.com/code-sleuth/ike-go/internal/manager/models"
+	"github.com/code-sleuth/ike-go/pkg/db"
+	"github.com/code-sleuth/ike-go/pkg/dialect"
+)
+
+type recordingExecer struct {
+	query string
+	args  []interface{}
+}
+
+func (r *recordingExecer) ExecContext(_ context.Context, query string, args ...interface{}) (sql.Result, error) {
+	r.query, r.args = query, args
+	return nil, nil
+}
+
+// Test NewEventRepository constructor
+func TestNewEventRepository_Unit(t *testing.T) {
+	dbWrapper := &db.DB{}
+	repo := NewEventRepository(dbWrapper)
+
+	if repo == nil {
+		t.Fatal("Expected non-nil repository")
+	}
+	if repo.db != dbWrapper {
+		t.Error("Expected database to be set correctly")
+	}
+}
+
+func TestRecordEvent_Unit(t *testing.T) {
+	ctx := interfaces.WithJobID(interfaces.WithActor(context.Background(), "worker-1"), "job-1")
+	documentID := "doc-1"
+	event := &models.Event{Type: models.EventChunksEmbedded, DocumentID: &documentID}
+
+	exec := &recordingExecer{}
+	if err := RecordEvent(ctx, exec, dialect.Postgres, event); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if event.ID == "" || event.CreatedAt.IsZero() {
+		t.Errorf("Expected ID and CreatedAt to be filled in, got %+v", event)
+	}
+	if event.Actor == nil || *event.Actor != "worker-1" || event.JobID == nil || *event.JobID != "job-1" {
+		t.Errorf("Expected actor and job ID from the context, got %v and %v", event.Actor, event.JobID)
+	}
+	if len(exec.args) != 8 || exec.args[1] != models.EventChunksEmbedded || exec.args[2] != (*string)(nil) {
+		t.Errorf("Unexpected arguments: %v", exec.args)
+	}
+	if !strings.Contains(exec.query, "$8") {
+		t.Errorf("Expected the query to be rebound for the dialect, got %s", exec.query)
+	}
+
+	// Values set on the event win over the context, and an empty context records no actor
+	jobID := "job-2"
+	event = &models.Event{Type: models.EventUpdateDetected, JobID: &jobID}
+	if err := RecordEvent(context.Background(), exec, dialect.SQLite, event); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if event.Actor != nil || *event.JobID != "job-2" {
+		t.Errorf("Expected no actor and job-2, got %v and %v", event.Actor, *event.JobID)
+	}
+}
@@ -0,0 +1,191 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/code-sleuth/ike-go/internal/manager/models"
+	"github.com/code-sleuth/ike-go/pkg/util"
+)
+
+// documentAge is the time a document's TTL counts from: when it was published, else indexed.
+// Documents with neither never expire.
+const documentAge = `COALESCE(published_at, indexed_at)`
+
+// ExpiryResult counts the documents an expiry sweep changed.
+type ExpiryResult struct {
+	// Stale is the number of documents marked stale.
+	Stale int `json:"stale"`
+	// Purged is the number of documents deleted with their chunks and embeddings.
+	Purged int `json:"purged"`
+	// Fresh is the number of stale documents no longer past their TTL, such as after it was
+	// raised or cleared, that are searchable again.
+	Fresh int `json:"fresh"`
+}
+
+// expiryPolicy is the TTL a source's documents live by, from the longest source_settings URL the
+// source falls under.
+type expiryPolicy struct {
+	settingsURL string
+	ttl         sql.NullInt64
+	action      sql.NullString
+}
+
+// Expire applies the TTLs stored in source_settings as of now. The live documents of each source
+// that are older than its TTL are marked stale or, when the settings say so, purged; stale
+// documents that are no longer past their TTL are made searchable again.
+func (r *DocumentRepository) Expire(now time.Time) (*ExpiryResult, error) {
+	policies, err := r.expiryPolicies()
+	if err != nil {
+		return nil, err
+	}
+
+	var result ExpiryResult
+	for sourceID, policy := range policies {
+		if err := r.expireSource(sourceID, policy, now, &result); err != nil {
+			r.logger.Error().Err(err).Str("source_id", sourceID).Msg("Failed to expire documents")
+			return nil, err
+		}
+	}
+	return &result, nil
+}
+
+// expiryPolicies returns the policy of every live source, resolving each source's URL against the
+// stored settings the same way imports do.
+func (r *DocumentRepository) expiryPolicies() (map[string]expiryPolicy, error) {
+	rows, err := r.db.Reader().Query(`SELECT source_url, ttl_seconds, expiry_action FROM source_settings`)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to list source settings")
+		return nil, err
+	}
+	var all []expiryPolicy
+	for rows.Next() {
+		var policy expiryPolicy
+		if err := rows.Scan(&policy.settingsURL, &policy.ttl, &policy.action); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		all = append(all, policy)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = r.db.Reader().Query(`SELECT id, raw_url FROM sources
+		WHERE deleted_at IS NULL AND raw_url IS NOT NULL`)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to list sources")
+		return nil, err
+	}
+	defer rows.Close()
+
+	policies := make(map[string]expiryPolicy)
+	for rows.Next() {
+		var sourceID, rawURL string
+		if err := rows.Scan(&sourceID, &rawURL); err != nil {
+			return nil, err
+		}
+		policies[sourceID] = resolveExpiryPolicy(all, rawURL)
+	}
+	return policies, rows.Err()
+}
+
+// resolveExpiryPolicy returns the policy of the longest settings URL rawURL falls under, or a
+// policy without a TTL when none do.
+func resolveExpiryPolicy(all []expiryPolicy, rawURL string) expiryPolicy {
+	var policy expiryPolicy
+	for _, candidate := range all {
+		if util.UnderURL(rawURL, candidate.settingsURL) && len(candidate.settingsURL) > len(policy.settingsURL) {
+			policy = candidate
+		}
+	}
+	return policy
+}
+
+// expireSource applies policy to the documents of the source with sourceID in one transaction,
+// adding what changed to result.
+func (r *DocumentRepository) expireSource(sourceID string, policy expiryPolicy, now time.Time,
+	result *ExpiryResult) error {
+	d := r.db.Dialect()
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if !policy.ttl.Valid {
+		fresh, err := execCount(tx, d.Rebind(`UPDATE documents SET stale_at = NULL
+			WHERE source_id = ? AND stale_at IS NOT NULL`), sourceID)
+		if err != nil {
+			return err
+		}
+		result.Fresh += fresh
+		return tx.Commit()
+	}
+
+	cutoff := d.FormatTime(now.Add(-time.Duration(policy.ttl.Int64) * time.Second))
+	// #nosec G202 -- documentAge is a constant
+	fresh, err := execCount(tx, d.Rebind(`UPDATE documents SET stale_at = NULL
+		WHERE source_id = ? AND stale_at IS NOT NULL AND `+documentAge+` >= ?`), sourceID, cutoff)
+	if err != nil {
+		return err
+	}
+	result.Fresh += fresh
+
+	if models.ExpiryAction(policy.action.String) != models.ExpirePurge {
+		// #nosec G202 -- documentAge is a constant
+		stale, err := execCount(tx, d.Rebind(`UPDATE documents SET stale_at = ?
+			WHERE source_id = ? AND deleted_at IS NULL AND stale_at IS NULL AND `+documentAge+` < ?`),
+			d.FormatTime(now), sourceID, cutoff)
+		if err != nil {
+			return err
+		}
+		result.Stale += stale
+		return tx.Commit()
+	}
+
+	// #nosec G202 -- documentAge is a constant
+	rows, err := tx.Query(d.Rebind(`SELECT id FROM documents
+		WHERE source_id = ? AND deleted_at IS NULL AND `+documentAge+` < ?`), sourceID, cutoff)
+	if err != nil {
+		return err
+	}
+	var expired []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		expired = append(expired, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, id := range expired {
+		if err := RecordDocumentDeletion(context.Background(), tx, d, id, "expired"); err != nil {
+			return err
+		}
+		if err := purgeDocuments(tx, d, `SELECT ? AS id`, id); err != nil {
+			return err
+		}
+	}
+	result.Purged += len(expired)
+	return tx.Commit()
+}
+
+// execCount executes query in tx and returns the number of rows it affected.
+func execCount(tx *sql.Tx, query string, args ...interface{}) (int, error) {
+	res, err := tx.Exec(query, args...)
+	if err != nil {
+		return 0, err
+	}
+	affected, err := res.RowsAffected()
+	return int(affected), err
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func TestResolveExpiryPolicy(t *testing.T) {
+	all := []expiryPolicy{
+		{settingsURL: "https://example.com/wp-json/wp/v2/posts", ttl: sql.NullInt64{Int64: 86400, Valid: true},
+			action: sql.NullString{String: "purge", Valid: true}},
+		{settingsURL: "https://example.com/wp-json/wp/v2/posts/42"},
+		{settingsURL: "https://example.com", ttl: sql.NullInt64{Int64: 3600, Valid: true}},
+	}
+
+	tests := []struct {
+		name        string
+		rawURL      string
+		expectedURL string
+		expectedTTL sql.NullInt64
+	}{
+		{
+			name:        "longest match wins",
+			rawURL:      "https://example.com/wp-json/wp/v2/posts/7",
+			expectedURL: "https://example.com/wp-json/wp/v2/posts",
+			expectedTTL: sql.NullInt64{Int64: 86400, Valid: true},
+		},
+		{
+			name:        "longer settings without a TTL keep documents forever",
+			rawURL:      "https://example.com/wp-json/wp/v2/posts/42",
+			expectedURL: "https://example.com/wp-json/wp/v2/posts/42",
+		},
+		{
+			name:        "host-wide settings",
+			rawURL:      "https://example.com/about",
+			expectedURL: "https://example.com",
+			expectedTTL: sql.NullInt64{Int64: 3600, Valid: true},
+		},
+		{
+			name:   "no settings",
+			rawURL: "https://github.com/owner/repo",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := resolveExpiryPolicy(all, tt.rawURL)
+			if policy.settingsURL != tt.expectedURL || policy.ttl != tt.expectedTTL {
+				t.Errorf("Expected settings %q with TTL %v, got %q with %v", tt.expectedURL, tt.expectedTTL,
+					policy.settingsURL, policy.ttl)
+			}
+		})
+	}
+}
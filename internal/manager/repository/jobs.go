@@ -0,0 +1,418 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/code-sleuth/ike-go/internal/manager/models"
+	"github.com/code-sleuth/ike-go/pkg/db"
+	"github.com/code-sleuth/ike-go/pkg/dialect"
+	"github.com/code-sleuth/ike-go/pkg/util"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+// DefaultMaxAttempts is how many times a job is claimed before it is left failed.
+const DefaultMaxAttempts = 3
+
+// claimRetries bounds how often Claim retries after losing a race for a job to another worker.
+const claimRetries = 5
+
+var (
+	// ErrNoJobs is returned by Claim when nothing is queued.
+	ErrNoJobs = errors.New("no queued jobs")
+	// ErrJobLost is returned when a worker updates a job it no longer holds, because its lock
+	// expired and the job was requeued, failed, or claimed by another worker.
+	ErrJobLost     = errors.New("job is no longer held by this worker")
+	errJobNotFound = errors.New("job not found")
+)
+
+// JobRepository is the database-backed work queue. Workers claim jobs with an UPDATE guarded on
+// the queued status, so several processes can drain the same database without taking a job twice.
+type JobRepository struct {
+	db     *db.DB
+	logger zerolog.Logger
+}
+
+func NewJobRepository(database *db.DB) *JobRepository {
+	logger := util.NewLogger(zerolog.ErrorLevel)
+	return &JobRepository{
+		db:     database,
+		logger: logger,
+	}
+}
+
+// Enqueue adds job to the queue. ID, status, and timestamps are filled in when unset.
+func (r *JobRepository) Enqueue(job *models.Job) error {
+	now := time.Now().UTC()
+	if job.ID == "" {
+		job.ID = uuid.New().String()
+	}
+	if job.MaxAttempts <= 0 {
+		job.MaxAttempts = DefaultMaxAttempts
+	}
+	job.Status = models.JobQueued
+	job.CreatedAt = now
+	job.UpdatedAt = now
+
+	query := `
+		INSERT INTO jobs (id, kind, payload, status, priority, attempts, max_attempts, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err := r.db.Exec(r.db.Rebind(query), job.ID, job.Kind, job.Payload, string(job.Status), job.Priority,
+		job.Attempts, job.MaxAttempts, r.db.Dialect().FormatTime(job.CreatedAt),
+		r.db.Dialect().FormatTime(job.UpdatedAt))
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to enqueue job")
+	}
+	return err
+}
+
+// Claim marks the queued job with the highest priority, oldest first, as running on behalf of
+// workerID and returns it. It returns ErrNoJobs when the queue is empty.
+func (r *JobRepository) Claim(workerID string) (*models.Job, error) {
+	for range claimRetries {
+		var id string
+		query := `SELECT id FROM jobs WHERE status = ? ORDER BY priority DESC, created_at, id LIMIT 1`
+		err := r.db.QueryRow(r.db.Rebind(query), string(models.JobQueued)).Scan(&id)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoJobs
+		}
+		if err != nil {
+			r.logger.Error().Err(err).Msg("Failed to find queued job")
+			return nil, err
+		}
+
+		now := r.db.Dialect().FormatTime(time.Now())
+		query = `
+			UPDATE jobs SET status = ?, worker_id = ?, attempts = attempts + 1, locked_at = ?, updated_at = ?
+			WHERE id = ? AND status = ?
+		`
+		result, err := r.db.Exec(r.db.Rebind(query), string(models.JobRunning), workerID, now, now, id,
+			string(models.JobQueued))
+		if err != nil {
+			r.logger.Error().Err(err).Str("job_id", id).Msg("Failed to claim job")
+			return nil, err
+		}
+		claimed, err := result.RowsAffected()
+		if err != nil {
+			return nil, err
+		}
+		if claimed == 1 {
+			return r.GetByID(id)
+		}
+
+		// Another worker claimed the job first
+		r.logger.Debug().Str("job_id", id).Msg("Lost job claim, retrying")
+	}
+
+	return nil, ErrNoJobs
+}
+
+// Heartbeat renews workerID's lock on a running job, so RequeueStale leaves it alone while the
+// worker is still running it. It returns ErrJobLost when the worker no longer holds the job.
+func (r *JobRepository) Heartbeat(id, workerID string) error {
+	now := r.db.Dialect().FormatTime(time.Now())
+	query := `UPDATE jobs SET locked_at = ?, updated_at = ? WHERE id = ? AND worker_id = ? AND status = ?`
+	result, err := r.db.Exec(r.db.Rebind(query), now, now, id, workerID, string(models.JobRunning))
+	if err != nil {
+		r.logger.Error().Err(err).Str("job_id", id).Msg("Failed to renew job lock")
+		return err
+	}
+	return heldJob(result)
+}
+
+// Complete marks a job workerID is running as done. It returns ErrJobLost when the worker no
+// longer holds the job.
+func (r *JobRepository) Complete(id, workerID string) error {
+	now := r.db.Dialect().FormatTime(time.Now())
+	query := `
+		UPDATE jobs SET status = ?, error = NULL, locked_at = NULL, updated_at = ?, finished_at = ?
+		WHERE id = ? AND worker_id = ? AND status = ?
+	`
+	result, err := r.db.Exec(r.db.Rebind(query), string(models.JobDone), now, now, id, workerID,
+		string(models.JobRunning))
+	if err != nil {
+		r.logger.Error().Err(err).Str("job_id", id).Msg("Failed to complete job")
+		return err
+	}
+	return heldJob(result)
+}
+
+// Fail records cause for a job workerID is running. The job is queued again while it has
+// attempts left and is left failed otherwise, which is recorded as a job_failed event. It
+// returns ErrJobLost when the worker no longer holds the job.
+func (r *JobRepository) Fail(id, workerID string, cause error) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to begin transaction")
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	now := r.db.Dialect().FormatTime(time.Now())
+	query := `
+		UPDATE jobs SET status = CASE WHEN attempts < max_attempts THEN ? ELSE ? END,
+		error = ?, locked_at = NULL, updated_at = ?,
+		finished_at = CASE WHEN attempts < max_attempts THEN NULL ELSE ? END
+		WHERE id = ? AND worker_id = ? AND status = ?
+	`
+	result, err := tx.Exec(r.db.Rebind(query), string(models.JobQueued), string(models.JobFailed),
+		util.RedactString(cause.Error()), now, now, id, workerID, string(models.JobRunning))
+	if err != nil {
+		r.logger.Error().Err(err).Str("job_id", id).Msg("Failed to record job failure")
+		return err
+	}
+	if err := heldJob(result); err != nil {
+		return err
+	}
+
+	var status, kind string
+	var attempts int
+	err = tx.QueryRow(r.db.Rebind(`SELECT status, kind, attempts FROM jobs WHERE id = ?`), id).
+		Scan(&status, &kind, &attempts)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		r.logger.Error().Err(err).Str("job_id", id).Msg("Failed to get failed job")
+		return err
+	}
+	if status == string(models.JobFailed) {
+		detail := fmt.Sprintf("%s job failed after %d attempts: %v", kind, attempts, cause)
+		err := RecordEvent(context.Background(), tx, r.db.Dialect(), &models.Event{
+			Type: models.EventJobFailed, JobID: &id, Actor: optionalString(workerID), Detail: &detail,
+		})
+		if err != nil {
+			r.logger.Error().Err(err).Str("job_id", id).Msg("Failed to record job failed event")
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Release returns a job workerID is running to the queue without counting the attempt, for
+// workers that are shutting down before the job finished. It returns ErrJobLost when the worker
+// no longer holds the job.
+func (r *JobRepository) Release(id, workerID string) error {
+	query := `
+		UPDATE jobs SET status = ?, attempts = attempts - 1, worker_id = NULL, locked_at = NULL, updated_at = ?
+		WHERE id = ? AND worker_id = ? AND status = ?
+	`
+	result, err := r.db.Exec(r.db.Rebind(query), string(models.JobQueued), r.db.Dialect().FormatTime(time.Now()),
+		id, workerID, string(models.JobRunning))
+	if err != nil {
+		r.logger.Error().Err(err).Str("job_id", id).Msg("Failed to release job")
+		return err
+	}
+	return heldJob(result)
+}
+
+// heldJob returns ErrJobLost when result, of an update guarded on the worker holding the job,
+// changed no row.
+func heldJob(result sql.Result) error {
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		return ErrJobLost
+	}
+	return nil
+}
+
+// RequeueStale returns running jobs whose lock was last renewed longer ago than lease to the
+// queue, recovering work from workers that crashed or were killed. Stale jobs that have used all
+// their attempts are left failed instead, recorded as job_failed events, so a job that kills its
+// worker is not retried forever. It returns the number of jobs requeued.
+func (r *JobRepository) RequeueStale(lease time.Duration) (int64, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to begin transaction")
+		return 0, err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	cutoff := r.db.Dialect().FormatTime(time.Now().Add(-lease))
+	if err := r.failExhausted(tx, cutoff); err != nil {
+		return 0, err
+	}
+
+	query := `
+		UPDATE jobs SET status = ?, worker_id = NULL, locked_at = NULL, updated_at = ?
+		WHERE status = ? AND locked_at < ? AND attempts < max_attempts
+	`
+	result, err := tx.Exec(r.db.Rebind(query), string(models.JobQueued), r.db.Dialect().FormatTime(time.Now()),
+		string(models.JobRunning), cutoff)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to requeue stale jobs")
+		return 0, err
+	}
+	requeued, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return requeued, tx.Commit()
+}
+
+// failExhausted leaves failed the running jobs locked before cutoff that have no attempts left.
+func (r *JobRepository) failExhausted(tx *sql.Tx, cutoff string) error {
+	query := `
+		SELECT id, kind, attempts, worker_id FROM jobs
+		WHERE status = ? AND locked_at < ? AND attempts >= max_attempts
+	`
+	rows, err := tx.Query(r.db.Rebind(query), string(models.JobRunning), cutoff)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to find exhausted stale jobs")
+		return err
+	}
+	type staleJob struct {
+		id, kind string
+		attempts int
+		workerID sql.NullString
+	}
+	var stale []staleJob
+	for rows.Next() {
+		var job staleJob
+		if err := rows.Scan(&job.id, &job.kind, &job.attempts, &job.workerID); err != nil {
+			rows.Close()
+			return err
+		}
+		stale = append(stale, job)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	now := r.db.Dialect().FormatTime(time.Now())
+	for _, job := range stale {
+		cause := fmt.Sprintf("lease expired on attempt %d: the worker stopped renewing its lock", job.attempts)
+		query := `
+			UPDATE jobs SET status = ?, error = ?, locked_at = NULL, updated_at = ?, finished_at = ?
+			WHERE id = ? AND status = ? AND locked_at < ?
+		`
+		result, err := tx.Exec(r.db.Rebind(query), string(models.JobFailed), cause, now, now, job.id,
+			string(models.JobRunning), cutoff)
+		if err != nil {
+			r.logger.Error().Err(err).Str("job_id", job.id).Msg("Failed to fail stale job")
+			return err
+		}
+		if heldJob(result) != nil {
+			// Renewed or finished since it was found
+			continue
+		}
+
+		detail := fmt.Sprintf("%s job failed after %d attempts: %s", job.kind, job.attempts, cause)
+		err = RecordEvent(context.Background(), tx, r.db.Dialect(), &models.Event{
+			Type: models.EventJobFailed, JobID: &job.id, Actor: optionalString(job.workerID.String), Detail: &detail,
+		})
+		if err != nil {
+			r.logger.Error().Err(err).Str("job_id", job.id).Msg("Failed to record job failed event")
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *JobRepository) GetByID(id string) (*models.Job, error) {
+	query := `
+		SELECT id, kind, payload, status, priority, attempts, max_attempts, error, worker_id, locked_at,
+		       created_at, updated_at, finished_at
+		FROM jobs WHERE id = ?
+	`
+	job, err := scanJob(r.db.QueryRow(r.db.Rebind(query), id))
+	if errors.Is(err, sql.ErrNoRows) {
+		r.logger.Error().Str("job_id", id).Msg("Job not found")
+		return nil, errJobNotFound
+	}
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to get job")
+		return nil, err
+	}
+	return job, nil
+}
+
+// JobListOptions filters, sorts, and pages JobRepository.ListWithOptions.
+type JobListOptions struct {
+	ListOptions
+	Status models.JobStatus
+	Kind   string
+}
+
+var jobSortFields = map[string]bool{
+	"created_at": true,
+	"updated_at": true,
+	"status":     true,
+	"kind":       true,
+	"priority":   true,
+}
+
+// ListWithOptions returns the jobs matching opts, newest first by default.
+func (r *JobRepository) ListWithOptions(opts JobListOptions) ([]models.Job, error) {
+	var where filters
+	if opts.Status != "" {
+		where.add("status = ?", string(opts.Status))
+	}
+	if opts.Kind != "" {
+		where.add("kind = ?", opts.Kind)
+	}
+
+	tail, tailArgs, err := opts.orderAndLimit(jobSortFields, "created_at", SortDesc)
+	if err != nil {
+		return nil, err
+	}
+
+	// #nosec G202 -- clauses are built from constants, values are bound through args
+	query := `
+		SELECT id, kind, payload, status, priority, attempts, max_attempts, error, worker_id, locked_at,
+		       created_at, updated_at, finished_at
+		FROM jobs` + where.where() + tail
+	rows, err := r.db.Reader().Query(r.db.Rebind(query), append(where.args, tailArgs...)...)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to list jobs")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jobs []models.Job
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			r.logger.Error().Err(err).Msg("Failed to scan job")
+			return nil, err
+		}
+		jobs = append(jobs, *job)
+	}
+
+	return jobs, rows.Err()
+}
+
+func scanJob(row rowScanner) (*models.Job, error) {
+	var job models.Job
+	var status, createdAtStr, updatedAtStr string
+	var lockedAt, finishedAt sql.NullString
+	err := row.Scan(&job.ID, &job.Kind, &job.Payload, &status, &job.Priority, &job.Attempts, &job.MaxAttempts,
+		&job.Error, &job.WorkerID, &lockedAt, &createdAtStr, &updatedAtStr, &finishedAt)
+	if err != nil {
+		return nil, err
+	}
+	job.Status = models.JobStatus(status)
+
+	if job.CreatedAt, err = dialect.ParseTime(createdAtStr); err != nil {
+		return nil, err
+	}
+	if job.UpdatedAt, err = dialect.ParseTime(updatedAtStr); err != nil {
+		return nil, err
+	}
+	job.LockedAt = parseNullTime(lockedAt)
+	job.FinishedAt = parseNullTime(finishedAt)
+
+	return &job, nil
+}
@@ -0,0 +1,257 @@
+package repository
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/code-sleuth/ike-go/internal/manager/models"
+	"github.com/code-sleuth/ike-go/internal/manager/testutil"
+	"github.com/code-sleuth/ike-go/pkg/db"
+)
+
+func TestJobRepository_Claim_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	testDB := testutil.SetupTestDB(t)
+	defer testutil.CleanupTestDB(t, testDB)
+
+	repo := NewJobRepository(&db.DB{DB: testDB})
+
+	t.Run("two workers, one job", func(t *testing.T) {
+		job := &models.Job{Kind: "process_source", Payload: `{"url":"https://github.com/owner/repo"}`}
+		if err := repo.Enqueue(job); err != nil {
+			t.Fatalf("Failed to enqueue job: %v", err)
+		}
+
+		var wg sync.WaitGroup
+		start := make(chan struct{})
+		claimed := make([]*models.Job, 2)
+		errs := make([]error, 2)
+		for i, workerID := range []string{"worker-1", "worker-2"} {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				<-start
+				claimed[i], errs[i] = repo.Claim(workerID)
+			}()
+		}
+		close(start)
+		wg.Wait()
+
+		var winners int
+		for i := range claimed {
+			switch {
+			case errs[i] == nil:
+				winners++
+				if claimed[i].ID != job.ID || claimed[i].Status != models.JobRunning || claimed[i].Attempts != 1 {
+					t.Errorf("Unexpected claimed job %+v", claimed[i])
+				}
+			case !errors.Is(errs[i], ErrNoJobs):
+				t.Errorf("Expected the losing worker to get ErrNoJobs, got %v", errs[i])
+			}
+		}
+		if winners != 1 {
+			t.Errorf("Expected exactly one worker to claim the job, got %d", winners)
+		}
+	})
+
+	t.Run("highest priority first", func(t *testing.T) {
+		// IDs sort in enqueue order, so jobs enqueued within the same timestamp keep it
+		for _, job := range []*models.Job{
+			{ID: "priority-1-low", Priority: -5},
+			{ID: "priority-2-normal"},
+			{ID: "priority-3-high", Priority: 10},
+			{ID: "priority-4-normal"},
+			{ID: "priority-5-high", Priority: 10},
+		} {
+			job.Kind, job.Payload = "process_source", "{}"
+			if err := repo.Enqueue(job); err != nil {
+				t.Fatalf("Failed to enqueue job: %v", err)
+			}
+		}
+
+		var order []string
+		for {
+			job, err := repo.Claim("worker-1")
+			if errors.Is(err, ErrNoJobs) {
+				break
+			}
+			if err != nil {
+				t.Fatalf("Failed to claim job: %v", err)
+			}
+			order = append(order, job.ID)
+		}
+
+		expected := []string{
+			"priority-3-high", "priority-5-high", "priority-2-normal", "priority-4-normal", "priority-1-low",
+		}
+		if !slices.Equal(order, expected) {
+			t.Errorf("Expected jobs claimed in order %v, got %v", expected, order)
+		}
+	})
+}
+
+func TestJobRepository_Fail_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	testDB := testutil.SetupTestDB(t)
+	defer testutil.CleanupTestDB(t, testDB)
+
+	dbWrapper := &db.DB{DB: testDB}
+	repo := NewJobRepository(dbWrapper)
+
+	job := &models.Job{Kind: "process_source", Payload: "{}", MaxAttempts: 2}
+	if err := repo.Enqueue(job); err != nil {
+		t.Fatalf("Failed to enqueue job: %v", err)
+	}
+
+	for attempt := 1; attempt <= job.MaxAttempts; attempt++ {
+		if _, err := repo.Claim("worker-1"); err != nil {
+			t.Fatalf("Failed to claim job for attempt %d: %v", attempt, err)
+		}
+		if err := repo.Fail(job.ID, "worker-1", errors.New("fetch failed")); err != nil {
+			t.Fatalf("Failed to fail job: %v", err)
+		}
+
+		failed, err := repo.GetByID(job.ID)
+		if err != nil {
+			t.Fatalf("Failed to get job: %v", err)
+		}
+		if failed.Attempts != attempt || failed.Error == nil || *failed.Error != "fetch failed" {
+			t.Errorf("Unexpected job after attempt %d: %+v", attempt, failed)
+		}
+		if attempt < job.MaxAttempts && (failed.Status != models.JobQueued || failed.FinishedAt != nil) {
+			t.Errorf("Expected the job queued again after attempt %d, got %s", attempt, failed.Status)
+		}
+		if attempt == job.MaxAttempts && (failed.Status != models.JobFailed || failed.FinishedAt == nil) {
+			t.Errorf("Expected the job failed after its last attempt, got %s", failed.Status)
+		}
+	}
+
+	if _, err := repo.Claim("worker-1"); !errors.Is(err, ErrNoJobs) {
+		t.Errorf("Expected a failed job not to be claimed again, got %v", err)
+	}
+
+	events, err := NewEventRepository(dbWrapper).List(EventListOptions{JobID: job.ID})
+	if err != nil {
+		t.Fatalf("Failed to list events: %v", err)
+	}
+	if len(events) != 1 || events[0].Type != models.EventJobFailed {
+		t.Errorf("Expected one job_failed event, got %+v", events)
+	}
+}
+
+func TestJobRepository_RequeueStale_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	testDB := testutil.SetupTestDB(t)
+	defer testutil.CleanupTestDB(t, testDB)
+
+	dbWrapper := &db.DB{DB: testDB}
+	repo := NewJobRepository(dbWrapper)
+
+	stale := &models.Job{Kind: "process_source", Payload: "{}"}
+	exhausted := &models.Job{Kind: "process_source", Payload: "{}", MaxAttempts: 1}
+	renewed := &models.Job{Kind: "process_source", Payload: "{}"}
+	fresh := &models.Job{Kind: "process_source", Payload: "{}"}
+	for _, job := range []*models.Job{stale, exhausted, renewed, fresh} {
+		if err := repo.Enqueue(job); err != nil {
+			t.Fatalf("Failed to enqueue job: %v", err)
+		}
+		if _, err := repo.Claim("worker-1"); err != nil {
+			t.Fatalf("Failed to claim job: %v", err)
+		}
+	}
+
+	// The worker holding the first three jobs stopped renewing their locks two hours ago, then
+	// renewed one of them
+	for _, job := range []*models.Job{stale, exhausted, renewed} {
+		_, err := testDB.Exec(`UPDATE jobs SET locked_at = ? WHERE id = ?`,
+			dbWrapper.Dialect().FormatTime(time.Now().Add(-2*time.Hour)), job.ID)
+		if err != nil {
+			t.Fatalf("Failed to age job lock: %v", err)
+		}
+	}
+	if err := repo.Heartbeat(renewed.ID, "worker-1"); err != nil {
+		t.Fatalf("Failed to renew job lock: %v", err)
+	}
+
+	requeued, err := repo.RequeueStale(time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to requeue stale jobs: %v", err)
+	}
+	if requeued != 1 {
+		t.Errorf("Expected 1 job requeued, got %d", requeued)
+	}
+
+	for _, tt := range []struct {
+		job      *models.Job
+		expected models.JobStatus
+	}{
+		{job: stale, expected: models.JobQueued},
+		{job: exhausted, expected: models.JobFailed},
+		{job: renewed, expected: models.JobRunning},
+		{job: fresh, expected: models.JobRunning},
+	} {
+		job, err := repo.GetByID(tt.job.ID)
+		if err != nil {
+			t.Fatalf("Failed to get job: %v", err)
+		}
+		if job.Status != tt.expected {
+			t.Errorf("Expected job %s to be %s, got %s", job.ID, tt.expected, job.Status)
+		}
+		if tt.expected == models.JobQueued && (job.WorkerID != nil || job.LockedAt != nil) {
+			t.Errorf("Expected the requeued job's lock to be cleared, got %+v", job)
+		}
+		if tt.expected == models.JobFailed && (job.FinishedAt == nil || job.Error == nil) {
+			t.Errorf("Expected the exhausted job to be finished with an error, got %+v", job)
+		}
+	}
+
+	events, err := NewEventRepository(dbWrapper).List(EventListOptions{JobID: exhausted.ID})
+	if err != nil {
+		t.Fatalf("Failed to list events: %v", err)
+	}
+	if len(events) != 1 || events[0].Type != models.EventJobFailed {
+		t.Errorf("Expected one job_failed event for the exhausted job, got %+v", events)
+	}
+
+	reclaimed, err := repo.Claim("worker-2")
+	if err != nil {
+		t.Fatalf("Failed to claim requeued job: %v", err)
+	}
+	if reclaimed.ID != stale.ID || reclaimed.Attempts != 2 {
+		t.Errorf("Expected the requeued job claimed on its second attempt, got %+v", reclaimed)
+	}
+
+	// The first worker finishing late must not overwrite the new holder's state
+	for name, transition := range map[string]func() error{
+		"heartbeat": func() error { return repo.Heartbeat(stale.ID, "worker-1") },
+		"complete":  func() error { return repo.Complete(stale.ID, "worker-1") },
+		"fail":      func() error { return repo.Fail(stale.ID, "worker-1", errors.New("too late")) },
+		"release":   func() error { return repo.Release(stale.ID, "worker-1") },
+	} {
+		if err := transition(); !errors.Is(err, ErrJobLost) {
+			t.Errorf("Expected ErrJobLost for the stale worker's %s, got %v", name, err)
+		}
+	}
+	job, err := repo.GetByID(stale.ID)
+	if err != nil {
+		t.Fatalf("Failed to get job: %v", err)
+	}
+	if job.Status != models.JobRunning || job.WorkerID == nil || *job.WorkerID != "worker-2" || job.Attempts != 2 {
+		t.Errorf("Expected the job to stay running on worker-2, got %+v", job)
+	}
+	if err := repo.Complete(stale.ID, "worker-2"); err != nil {
+		t.Errorf("Expected the holder to complete the job, got %v", err)
+	}
+}
@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/code-sleuth/ike-go/pkg/db"
+)
+
+// Test NewJobRepository constructor
+func TestNewJobRepository_Unit(t *testing.T) {
+	dbWrapper := &db.DB{}
+	repo := NewJobRepository(dbWrapper)
+
+	if repo == nil {
+		t.Fatal("Expected non-nil repository")
+	}
+	if repo.db != dbWrapper {
+		t.Error("Expected database to be set correctly")
+	}
+}
+
+// Test error constants
+func TestJobRepository_ErrorConstants(t *testing.T) {
+	if errJobNotFound.Error() != "job not found" {
+		t.Errorf("Expected 'job not found', got '%s'", errJobNotFound.Error())
+	}
+	if ErrNoJobs.Error() != "no queued jobs" {
+		t.Errorf("Expected 'no queued jobs', got '%s'", ErrNoJobs.Error())
+	}
+}
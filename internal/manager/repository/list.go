@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/code-sleuth/ike-go/pkg/dialect"
+)
+
+// SortOrder is the direction a List result is ordered in.
+type SortOrder string
+
+const (
+	SortDefault SortOrder = ""
+	SortAsc     SortOrder = "asc"
+	SortDesc    SortOrder = "desc"
+)
+
+var (
+	errInvalidSortField  = errors.New("invalid sort field")
+	errInvalidSortOrder  = errors.New("invalid sort order")
+	errInvalidPagination = errors.New("invalid pagination")
+)
+
+// ListOptions holds the paging and sorting settings shared by repository List methods.
+// The zero value returns every row in the repository's default order.
+type ListOptions struct {
+	// Limit caps the number of rows returned; 0 means no limit.
+	Limit int
+	// Offset skips this many rows before returning results.
+	Offset int
+	// SortBy names the column to order by; empty uses the repository default.
+	SortBy string
+	// Order is the sort direction; SortDefault uses the repository default.
+	Order SortOrder
+}
+
+// filters collects the WHERE clauses and bind arguments for a List query.
+type filters struct {
+	clauses []string
+	args    []interface{}
+}
+
+func (f *filters) add(clause string, args ...interface{}) {
+	f.clauses = append(f.clauses, clause)
+	f.args = append(f.args, args...)
+}
+
+// addTimeRange filters column to [after, before); zero times are ignored. Bounds are formatted
+// the way d stores timestamps, and since columns hold a mix of layouts, both sides are normalized
+// through datetime().
+func (f *filters) addTimeRange(d dialect.Dialect, column string, after, before time.Time) {
+	if !after.IsZero() {
+		f.add("datetime("+column+") >= datetime(?)", d.FormatTime(after))
+	}
+	if !before.IsZero() {
+		f.add("datetime("+column+") < datetime(?)", d.FormatTime(before))
+	}
+}
+
+func (f *filters) where() string {
+	if len(f.clauses) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(f.clauses, " AND ")
+}
+
+// orderAndLimit validates the options and renders the ORDER BY / LIMIT / OFFSET tail of a query.
+// sortable lists the columns callers may sort by; id is always appended as a tie-breaker so pages
+// are stable.
+func (o ListOptions) orderAndLimit(
+	sortable map[string]bool,
+	defaultSort string,
+	defaultOrder SortOrder,
+) (string, []interface{}, error) {
+	if o.Limit < 0 || o.Offset < 0 {
+		return "", nil, fmt.Errorf("%w: limit and offset must not be negative", errInvalidPagination)
+	}
+
+	sortBy := o.SortBy
+	if sortBy == "" {
+		sortBy = defaultSort
+	}
+	if !sortable[sortBy] {
+		return "", nil, fmt.Errorf("%w: %s", errInvalidSortField, sortBy)
+	}
+
+	order := o.Order
+	if order == SortDefault {
+		order = defaultOrder
+	}
+	if order != SortAsc && order != SortDesc {
+		return "", nil, fmt.Errorf("%w: %s", errInvalidSortOrder, order)
+	}
+
+	direction := strings.ToUpper(string(order))
+	clause := fmt.Sprintf(" ORDER BY %s %s, id %s", sortBy, direction, direction)
+
+	var args []interface{}
+	if o.Limit > 0 || o.Offset > 0 {
+		// SQLite treats a negative LIMIT as unbounded, which allows an offset without a limit
+		limit := o.Limit
+		if limit == 0 {
+			limit = -1
+		}
+		clause += " LIMIT ? OFFSET ?"
+		args = append(args, limit, o.Offset)
+	}
+
+	return clause, args, nil
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/code-sleuth/ike-go/pkg/dialect"
+)
+
+func TestListOptions_OrderAndLimit(t *testing.T) {
+	sortable := map[string]bool{"created_at": true, "host": true}
+
+	tests := []struct {
+		name         string
+		opts         ListOptions
+		expected     string
+		expectedArgs []interface{}
+		expectedErr  error
+		description  string
+	}{
+		{
+			name:        "defaults",
+			opts:        ListOptions{},
+			expected:    " ORDER BY created_at DESC, id DESC",
+			description: "should use the default sort without paging",
+		},
+		{
+			name:         "limit and offset",
+			opts:         ListOptions{Limit: 10, Offset: 20, SortBy: "host", Order: SortAsc},
+			expected:     " ORDER BY host ASC, id ASC LIMIT ? OFFSET ?",
+			expectedArgs: []interface{}{10, 20},
+			description:  "should bind limit and offset",
+		},
+		{
+			name:         "offset without limit",
+			opts:         ListOptions{Offset: 5},
+			expected:     " ORDER BY created_at DESC, id DESC LIMIT ? OFFSET ?",
+			expectedArgs: []interface{}{-1, 5},
+			description:  "should use an unbounded limit when only an offset is given",
+		},
+		{
+			name:        "unknown sort field",
+			opts:        ListOptions{SortBy: "id; DROP TABLE sources"},
+			expectedErr: errInvalidSortField,
+			description: "should reject columns outside the allowlist",
+		},
+		{
+			name:        "unknown order",
+			opts:        ListOptions{Order: "sideways"},
+			expectedErr: errInvalidSortOrder,
+			description: "should reject unknown sort directions",
+		},
+		{
+			name:        "negative limit",
+			opts:        ListOptions{Limit: -1},
+			expectedErr: errInvalidPagination,
+			description: "should reject negative paging values",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clause, args, err := tt.opts.orderAndLimit(sortable, "created_at", SortDesc)
+			if tt.expectedErr != nil {
+				if !errors.Is(err, tt.expectedErr) {
+					t.Errorf("Expected error %v, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if clause != tt.expected {
+				t.Errorf("Expected clause %q, got %q", tt.expected, clause)
+			}
+			if len(args) != len(tt.expectedArgs) {
+				t.Fatalf("Expected %d args, got %d", len(tt.expectedArgs), len(args))
+			}
+			for i := range args {
+				if args[i] != tt.expectedArgs[i] {
+					t.Errorf("Expected arg %d to be %v, got %v", i, tt.expectedArgs[i], args[i])
+				}
+			}
+		})
+	}
+}
+
+func TestFilters_Where(t *testing.T) {
+	var where filters
+	if where.where() != "" {
+		t.Errorf("Expected empty WHERE clause, got %q", where.where())
+	}
+
+	after := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	where.add("deleted_at IS NULL")
+	where.add("host = ?", "github.com")
+	where.addTimeRange(dialect.SQLite, "created_at", after, time.Time{})
+
+	expected := " WHERE deleted_at IS NULL AND host = ? AND datetime(created_at) >= datetime(?)"
+	if where.where() != expected {
+		t.Errorf("Expected %q, got %q", expected, where.where())
+	}
+	if len(where.args) != 2 || where.args[0] != "github.com" || where.args[1] != "2024-01-02T03:04:05Z" {
+		t.Errorf("Expected host and timestamp args, got %v", where.args)
+	}
+}
@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/code-sleuth/ike-go/internal/manager/models"
+	"github.com/code-sleuth/ike-go/pkg/db"
+	"github.com/code-sleuth/ike-go/pkg/dialect"
+	"github.com/code-sleuth/ike-go/pkg/util"
+
+	"github.com/rs/zerolog"
+)
+
+var ErrOwnerNotFound = errors.New("source owner not found")
+
+// OwnerSummary is an owner with the live sources it owns and their documents and chunks.
+type OwnerSummary struct {
+	Owner     string `json:"owner"`
+	Sources   int    `json:"sources"`
+	Documents int    `json:"documents"`
+	Chunks    int    `json:"chunks"`
+}
+
+// OwnerRepository manages the teams and people recorded as owning sources, such as those named in
+// a repository's CODEOWNERS or given at import with --owner.
+type OwnerRepository struct {
+	db     *db.DB
+	logger zerolog.Logger
+}
+
+func NewOwnerRepository(database *db.DB) *OwnerRepository {
+	logger := util.NewLogger(zerolog.ErrorLevel)
+	return &OwnerRepository{
+		db:     database,
+		logger: logger,
+	}
+}
+
+// Add records owner as an owner of the source with sourceID. Adding an owner twice is a no-op.
+func (r *OwnerRepository) Add(sourceID, owner string) error {
+	owner = strings.TrimSpace(owner)
+	query := `INSERT INTO source_owners (source_id, owner, created_at) VALUES (?, ?, ?)
+		ON CONFLICT (source_id, owner) DO NOTHING`
+	_, err := r.db.Exec(r.db.Rebind(query), sourceID, owner, r.db.Dialect().FormatTime(time.Now()))
+	if err != nil {
+		r.logger.Error().Err(err).Str("source_id", sourceID).Str("owner", owner).Msg("Failed to add source owner")
+	}
+	return err
+}
+
+// Remove stops owner owning the source with sourceID, or returns ErrOwnerNotFound.
+func (r *OwnerRepository) Remove(sourceID, owner string) error {
+	result, err := r.db.Exec(r.db.Rebind(`DELETE FROM source_owners WHERE source_id = ? AND owner = ?`),
+		sourceID, strings.TrimSpace(owner))
+	if err != nil {
+		r.logger.Error().Err(err).Str("source_id", sourceID).Str("owner", owner).Msg("Failed to remove source owner")
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrOwnerNotFound
+	}
+	return nil
+}
+
+// ListBySource returns the owners of the source with sourceID ordered by owner.
+func (r *OwnerRepository) ListBySource(sourceID string) ([]models.SourceOwner, error) {
+	query := `SELECT source_id, owner, created_at FROM source_owners WHERE source_id = ? ORDER BY owner`
+	rows, err := r.db.Reader().Query(r.db.Rebind(query), sourceID)
+	if err != nil {
+		r.logger.Error().Err(err).Str("source_id", sourceID).Msg("Failed to list source owners")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var owners []models.SourceOwner
+	for rows.Next() {
+		owner, err := scanSourceOwner(rows)
+		if err != nil {
+			r.logger.Error().Err(err).Msg("Failed to scan source owner")
+			return nil, err
+		}
+		owners = append(owners, *owner)
+	}
+	return owners, rows.Err()
+}
+
+// List returns every owner of a live source ordered by owner, with what they own.
+func (r *OwnerRepository) List() ([]OwnerSummary, error) {
+	query := `SELECT o.owner, COUNT(DISTINCT s.id), COUNT(DISTINCT d.id), COUNT(DISTINCT c.id)
+		FROM source_owners o
+		JOIN sources s ON s.id = o.source_id AND s.deleted_at IS NULL
+		LEFT JOIN documents d ON d.source_id = s.id AND d.deleted_at IS NULL
+		LEFT JOIN chunks c ON c.document_id = d.id
+		GROUP BY o.owner ORDER BY o.owner`
+	rows, err := r.db.Reader().Query(query)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to list owners")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var summaries []OwnerSummary
+	for rows.Next() {
+		var summary OwnerSummary
+		if err := rows.Scan(&summary.Owner, &summary.Sources, &summary.Documents, &summary.Chunks); err != nil {
+			r.logger.Error().Err(err).Msg("Failed to scan owner")
+			return nil, err
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries, rows.Err()
+}
+
+func scanSourceOwner(row rowScanner) (*models.SourceOwner, error) {
+	var owner models.SourceOwner
+	var createdAtStr string
+	if err := row.Scan(&owner.SourceID, &owner.Owner, &createdAtStr); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if owner.CreatedAt, err = dialect.ParseTime(createdAtStr); err != nil {
+		return nil, err
+	}
+	return &owner, nil
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/code-sleuth/ike-go/pkg/db"
+)
+
+// Test NewOwnerRepository constructor
+func TestNewOwnerRepository_Unit(t *testing.T) {
+	dbWrapper := &db.DB{}
+	repo := NewOwnerRepository(dbWrapper)
+
+	if repo == nil {
+		t.Fatal("Expected non-nil repository")
+	}
+	if repo.db != dbWrapper {
+		t.Error("Expected database to be set correctly")
+	}
+}
+
+// sourceOwnerRow scans a fixed source_owners row.
+type sourceOwnerRow struct{}
+
+func (sourceOwnerRow) Scan(dest ...any) error {
+	*dest[0].(*string) = "source-1"
+	*dest[1].(*string) = "@org/docs-team"
+	*dest[2].(*string) = "2026-01-02T03:04:05Z"
+	return nil
+}
+
+func TestScanSourceOwner(t *testing.T) {
+	owner, err := scanSourceOwner(sourceOwnerRow{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if owner.SourceID != "source-1" || owner.Owner != "@org/docs-team" {
+		t.Errorf("Unexpected owner %+v", owner)
+	}
+	if want := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC); !owner.CreatedAt.Equal(want) {
+		t.Errorf("Expected created_at %v, got %v", want, owner.CreatedAt)
+	}
+}
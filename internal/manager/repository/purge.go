@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/code-sleuth/ike-go/pkg/dialect"
+)
+
+// purgeSources deletes the sources selected by the sourceIDs subquery and everything that hangs off them.
+// Rows are removed leaf-first so foreign keys are never left dangling.
+func purgeSources(tx *sql.Tx, d dialect.Dialect, sourceIDs string, args ...interface{}) error {
+	documentIDs := `SELECT id FROM documents WHERE source_id IN (` + sourceIDs + `)`
+	if err := purgeDocuments(tx, d, documentIDs, args...); err != nil {
+		return err
+	}
+
+	statements := []string{
+		`DELETE FROM downloads WHERE source_id IN (` + sourceIDs + `)`,     // #nosec G202 -- subquery is a constant
+		`DELETE FROM source_owners WHERE source_id IN (` + sourceIDs + `)`, // #nosec G202 -- subquery is a constant
+		`DELETE FROM source_acl WHERE source_id IN (` + sourceIDs + `)`,    // #nosec G202 -- subquery is a constant
+		`DELETE FROM sources WHERE id IN (` + sourceIDs + `)`,              // #nosec G202 -- subquery is a constant
+	}
+	for _, statement := range statements {
+		if _, err := tx.Exec(d.Rebind(statement), args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// purgeDocuments deletes the documents selected by the documentIDs subquery together with their
+// embeddings, chunks and their metadata, dead-lettered chunks, tags, and metadata.
+func purgeDocuments(tx *sql.Tx, d dialect.Dialect, documentIDs string, args ...interface{}) error {
+	chunkIDs := `SELECT id FROM chunks WHERE document_id IN (` + documentIDs + `)`
+
+	// #nosec G202 -- subqueries are constants, values are bound through args
+	statements := []string{
+		`DELETE FROM embeddings WHERE object_type = 'chunk' AND object_id IN (` + chunkIDs + `)`,
+		`DELETE FROM chunk_meta WHERE chunk_id IN (` + chunkIDs + `)`,
+		`DELETE FROM chunks WHERE document_id IN (` + documentIDs + `)`,
+		`DELETE FROM failed_chunks WHERE document_id IN (` + documentIDs + `)`,
+		`DELETE FROM document_meta WHERE document_id IN (` + documentIDs + `)`,
+		`DELETE FROM document_tags WHERE document_id IN (` + documentIDs + `)`,
+		`DELETE FROM documents WHERE id IN (` + documentIDs + `)`,
+	}
+	for _, statement := range statements {
+		if _, err := tx.Exec(d.Rebind(statement), args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
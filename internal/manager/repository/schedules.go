@@ -0,0 +1,201 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/code-sleuth/ike-go/internal/manager/models"
+	"github.com/code-sleuth/ike-go/pkg/db"
+	"github.com/code-sleuth/ike-go/pkg/dialect"
+	"github.com/code-sleuth/ike-go/pkg/util"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+var errScheduleNotFound = errors.New("schedule not found")
+
+const scheduleColumns = `id, source_url, cron, options, enabled, priority, next_run_at, last_run_at, last_error,
+		       created_at, updated_at`
+
+// ScheduleRepository stores the cron schedules the daemon re-imports sources on.
+type ScheduleRepository struct {
+	db     *db.DB
+	logger zerolog.Logger
+}
+
+func NewScheduleRepository(database *db.DB) *ScheduleRepository {
+	logger := util.NewLogger(zerolog.ErrorLevel)
+	return &ScheduleRepository{
+		db:     database,
+		logger: logger,
+	}
+}
+
+// Create stores schedule. ID and timestamps are filled in when unset.
+func (r *ScheduleRepository) Create(schedule *models.Schedule) error {
+	now := time.Now().UTC()
+	if schedule.ID == "" {
+		schedule.ID = uuid.New().String()
+	}
+	schedule.CreatedAt = now
+	schedule.UpdatedAt = now
+
+	query := `
+		INSERT INTO schedules (id, source_url, cron, options, enabled, priority, next_run_at, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err := r.db.Exec(r.db.Rebind(query), schedule.ID, schedule.SourceURL, schedule.Cron, schedule.Options,
+		boolToInt(schedule.Enabled), schedule.Priority, r.formatOptionalTime(schedule.NextRunAt),
+		r.db.Dialect().FormatTime(schedule.CreatedAt), r.db.Dialect().FormatTime(schedule.UpdatedAt))
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to create schedule")
+	}
+	return err
+}
+
+func (r *ScheduleRepository) GetByID(id string) (*models.Schedule, error) {
+	// #nosec G202 -- the column list is a constant
+	query := `SELECT ` + scheduleColumns + ` FROM schedules WHERE id = ?`
+	schedule, err := scanSchedule(r.db.Reader().QueryRow(r.db.Rebind(query), id))
+	if errors.Is(err, sql.ErrNoRows) {
+		r.logger.Error().Str("schedule_id", id).Msg("Schedule not found")
+		return nil, errScheduleNotFound
+	}
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to get schedule")
+		return nil, err
+	}
+	return schedule, nil
+}
+
+// List returns every schedule ordered by source URL.
+func (r *ScheduleRepository) List() ([]models.Schedule, error) {
+	// #nosec G202 -- the column list is a constant
+	query := `SELECT ` + scheduleColumns + ` FROM schedules ORDER BY source_url`
+	return r.query(query)
+}
+
+// Due returns the enabled schedules whose next run is at or before now, oldest first.
+func (r *ScheduleRepository) Due(now time.Time) ([]models.Schedule, error) {
+	// #nosec G202 -- the column list is a constant
+	query := `SELECT ` + scheduleColumns + ` FROM schedules
+		WHERE enabled = 1 AND next_run_at IS NOT NULL AND next_run_at <= ?
+		ORDER BY next_run_at, id`
+	return r.query(query, r.db.Dialect().FormatTime(now))
+}
+
+// MarkRun records that schedule id ran at ranAt and is next due at next; a nil next leaves it
+// with no further runs. A non-nil runErr is kept as the schedule's last error.
+func (r *ScheduleRepository) MarkRun(id string, ranAt time.Time, next *time.Time, runErr error) error {
+	var lastError *string
+	if runErr != nil {
+		msg := util.RedactString(runErr.Error())
+		lastError = &msg
+	}
+
+	query := `
+		UPDATE schedules SET last_run_at = ?, next_run_at = ?, last_error = ?, updated_at = ?
+		WHERE id = ?
+	`
+	_, err := r.db.Exec(r.db.Rebind(query), r.db.Dialect().FormatTime(ranAt), r.formatOptionalTime(next),
+		lastError, r.db.Dialect().FormatTime(time.Now()), id)
+	if err != nil {
+		r.logger.Error().Err(err).Str("schedule_id", id).Msg("Failed to record schedule run")
+	}
+	return err
+}
+
+// SetEnabled pauses or resumes a schedule. Resuming sets its next run to next.
+func (r *ScheduleRepository) SetEnabled(id string, enabled bool, next *time.Time) error {
+	query := `UPDATE schedules SET enabled = ?, next_run_at = ?, updated_at = ? WHERE id = ?`
+	result, err := r.db.Exec(r.db.Rebind(query), boolToInt(enabled), r.formatOptionalTime(next),
+		r.db.Dialect().FormatTime(time.Now()), id)
+	if err != nil {
+		r.logger.Error().Err(err).Str("schedule_id", id).Msg("Failed to update schedule")
+		return err
+	}
+	return r.requireFound(result, id)
+}
+
+func (r *ScheduleRepository) Delete(id string) error {
+	result, err := r.db.Exec(r.db.Rebind(`DELETE FROM schedules WHERE id = ?`), id)
+	if err != nil {
+		r.logger.Error().Err(err).Str("schedule_id", id).Msg("Failed to delete schedule")
+		return err
+	}
+	return r.requireFound(result, id)
+}
+
+// requireFound returns errScheduleNotFound when result touched no rows.
+func (r *ScheduleRepository) requireFound(result sql.Result, id string) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		r.logger.Error().Str("schedule_id", id).Msg("Schedule not found")
+		return errScheduleNotFound
+	}
+	return nil
+}
+
+func (r *ScheduleRepository) query(query string, args ...interface{}) ([]models.Schedule, error) {
+	rows, err := r.db.Reader().Query(r.db.Rebind(query), args...)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to list schedules")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var schedules []models.Schedule
+	for rows.Next() {
+		schedule, err := scanSchedule(rows)
+		if err != nil {
+			r.logger.Error().Err(err).Msg("Failed to scan schedule")
+			return nil, err
+		}
+		schedules = append(schedules, *schedule)
+	}
+
+	return schedules, rows.Err()
+}
+
+func (r *ScheduleRepository) formatOptionalTime(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return r.db.Dialect().FormatTime(*t)
+}
+
+func scanSchedule(row rowScanner) (*models.Schedule, error) {
+	var schedule models.Schedule
+	var enabled int
+	var createdAtStr, updatedAtStr string
+	var nextRunAt, lastRunAt sql.NullString
+	err := row.Scan(&schedule.ID, &schedule.SourceURL, &schedule.Cron, &schedule.Options, &enabled,
+		&schedule.Priority, &nextRunAt, &lastRunAt, &schedule.LastError, &createdAtStr, &updatedAtStr)
+	if err != nil {
+		return nil, err
+	}
+	schedule.Enabled = enabled != 0
+
+	if schedule.CreatedAt, err = dialect.ParseTime(createdAtStr); err != nil {
+		return nil, err
+	}
+	if schedule.UpdatedAt, err = dialect.ParseTime(updatedAtStr); err != nil {
+		return nil, err
+	}
+	schedule.NextRunAt = parseNullTime(nextRunAt)
+	schedule.LastRunAt = parseNullTime(lastRunAt)
+
+	return &schedule, nil
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
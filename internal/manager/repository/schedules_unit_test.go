@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/code-sleuth/ike-go/pkg/db"
+)
+
+// Test NewScheduleRepository constructor
+func TestNewScheduleRepository_Unit(t *testing.T) {
+	dbWrapper := &db.DB{}
+	repo := NewScheduleRepository(dbWrapper)
+
+	if repo == nil {
+		t.Fatal("Expected non-nil repository")
+	}
+	if repo.db != dbWrapper {
+		t.Error("Expected database to be set correctly")
+	}
+}
+
+// Test error constants
+func TestScheduleRepository_ErrorConstants(t *testing.T) {
+	if errScheduleNotFound.Error() != "schedule not found" {
+		t.Errorf("Expected 'schedule not found', got '%s'", errScheduleNotFound.Error())
+	}
+}
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/code-sleuth/ike-go/internal/manager/models"
+	"github.com/code-sleuth/ike-go/pkg/db"
+	"github.com/code-sleuth/ike-go/pkg/dialect"
+	"github.com/code-sleuth/ike-go/pkg/util"
+
+	"github.com/rs/zerolog"
+)
+
+var errSourceCredentialsNotFound = errors.New("source credentials not found")
+
+const sourceCredentialsColumns = `source_url, github_token, openai_api_key, together_api_key, url_secrets,
+	created_at, updated_at`
+
+// SourceCredentialsRepository stores the per-source credentials imports authenticate with.
+type SourceCredentialsRepository struct {
+	db     *db.DB
+	logger zerolog.Logger
+}
+
+func NewSourceCredentialsRepository(database *db.DB) *SourceCredentialsRepository {
+	logger := util.NewLogger(zerolog.ErrorLevel)
+	return &SourceCredentialsRepository{
+		db:     database,
+		logger: logger,
+	}
+}
+
+// Set creates or replaces the credentials for credentials.SourceURL. URL secrets are kept, since
+// only imports record them.
+func (r *SourceCredentialsRepository) Set(credentials *models.SourceCredentials) error {
+	now := time.Now().UTC()
+	credentials.CreatedAt = now
+	credentials.UpdatedAt = now
+
+	query := r.db.Dialect().Upsert("source_credentials",
+		[]string{"source_url", "github_token", "openai_api_key", "together_api_key", "created_at", "updated_at"},
+		[]string{"source_url"},
+		[]string{"github_token", "openai_api_key", "together_api_key", "updated_at"})
+	_, err := r.db.Exec(r.db.Rebind(query), credentials.SourceURL, nullString(credentials.GitHubToken),
+		nullString(credentials.OpenAIAPIKey), nullString(credentials.TogetherAPIKey),
+		r.db.Dialect().FormatTime(credentials.CreatedAt), r.db.Dialect().FormatTime(credentials.UpdatedAt))
+	if err != nil {
+		r.logger.Error().Err(err).Str("source_url", credentials.SourceURL).Msg("Failed to save source credentials")
+	}
+	return err
+}
+
+func (r *SourceCredentialsRepository) Get(sourceURL string) (*models.SourceCredentials, error) {
+	// #nosec G202 -- the column list is a constant
+	query := `SELECT ` + sourceCredentialsColumns + ` FROM source_credentials WHERE source_url = ?`
+	credentials, err := scanSourceCredentials(r.db.Reader().QueryRow(r.db.Rebind(query), sourceURL))
+	if errors.Is(err, sql.ErrNoRows) {
+		r.logger.Error().Str("source_url", sourceURL).Msg("Source credentials not found")
+		return nil, errSourceCredentialsNotFound
+	}
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to get source credentials")
+		return nil, err
+	}
+	return credentials, nil
+}
+
+// List returns the credentials of every source ordered by URL.
+func (r *SourceCredentialsRepository) List() ([]models.SourceCredentials, error) {
+	// #nosec G202 -- the column list is a constant
+	query := `SELECT ` + sourceCredentialsColumns + ` FROM source_credentials ORDER BY source_url`
+	rows, err := r.db.Reader().Query(query)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to list source credentials")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var all []models.SourceCredentials
+	for rows.Next() {
+		credentials, err := scanSourceCredentials(rows)
+		if err != nil {
+			r.logger.Error().Err(err).Msg("Failed to scan source credentials")
+			return nil, err
+		}
+		all = append(all, *credentials)
+	}
+
+	return all, rows.Err()
+}
+
+func (r *SourceCredentialsRepository) Delete(sourceURL string) error {
+	result, err := r.db.Exec(r.db.Rebind(`DELETE FROM source_credentials WHERE source_url = ?`), sourceURL)
+	if err != nil {
+		r.logger.Error().Err(err).Str("source_url", sourceURL).Msg("Failed to delete source credentials")
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		r.logger.Error().Str("source_url", sourceURL).Msg("Source credentials not found")
+		return errSourceCredentialsNotFound
+	}
+	return nil
+}
+
+// saveURLSecrets records secrets, the credentials util.SplitURLSecrets stripped from sourceURL, in
+// its source credentials, keeping any others stored for it.
+func saveURLSecrets(ctx context.Context, exec Execer, d dialect.Dialect, sourceURL, secrets string) error {
+	now := d.FormatTime(time.Now())
+	query := d.Upsert("source_credentials", []string{"source_url", "url_secrets", "created_at", "updated_at"},
+		[]string{"source_url"}, []string{"url_secrets", "updated_at"})
+	_, err := exec.ExecContext(ctx, d.Rebind(query), sourceURL, secrets, now, now)
+	return err
+}
+
+func scanSourceCredentials(row rowScanner) (*models.SourceCredentials, error) {
+	var credentials models.SourceCredentials
+	var githubToken, openAIAPIKey, togetherAPIKey, urlSecrets sql.NullString
+	var createdAtStr, updatedAtStr string
+	err := row.Scan(&credentials.SourceURL, &githubToken, &openAIAPIKey, &togetherAPIKey, &urlSecrets,
+		&createdAtStr, &updatedAtStr)
+	if err != nil {
+		return nil, err
+	}
+	credentials.GitHubToken = githubToken.String
+	credentials.OpenAIAPIKey = openAIAPIKey.String
+	credentials.TogetherAPIKey = togetherAPIKey.String
+	credentials.URLSecrets = urlSecrets.String
+
+	if credentials.CreatedAt, err = dialect.ParseTime(createdAtStr); err != nil {
+		return nil, err
+	}
+	if credentials.UpdatedAt, err = dialect.ParseTime(updatedAtStr); err != nil {
+		return nil, err
+	}
+
+	return &credentials, nil
+}
+
+// nullString stores an unset credential as NULL rather than an empty string.
+func nullString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
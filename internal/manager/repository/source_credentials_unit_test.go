@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/code-sleuth/ike-go/pkg/db"
+)
+
+// Test NewSourceCredentialsRepository constructor
+func TestNewSourceCredentialsRepository_Unit(t *testing.T) {
+	dbWrapper := &db.DB{}
+	repo := NewSourceCredentialsRepository(dbWrapper)
+
+	if repo == nil {
+		t.Fatal("Expected non-nil repository")
+	}
+	if repo.db != dbWrapper {
+		t.Error("Expected database to be set correctly")
+	}
+}
+
+// Test error constants
+func TestSourceCredentialsRepository_ErrorConstants(t *testing.T) {
+	if errSourceCredentialsNotFound.Error() != "source credentials not found" {
+		t.Errorf("Expected 'source credentials not found', got '%s'", errSourceCredentialsNotFound.Error())
+	}
+}
+
+func TestNullString(t *testing.T) {
+	if nullString("").Valid {
+		t.Error("Expected an empty credential to be stored as NULL")
+	}
+	if value := nullString("ghp_x"); !value.Valid || value.String != "ghp_x" {
+		t.Errorf("Expected a valid ghp_x, got %+v", value)
+	}
+}
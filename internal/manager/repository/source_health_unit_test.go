@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"testing"
+	"time"
+)
+
+// sourceHealthRow scans a fixed row of source health.
+type sourceHealthRow struct {
+	lastSuccessAt any
+}
+
+func (r sourceHealthRow) Scan(dest ...any) error {
+	*dest[0].(*string) = "source-1"
+	url := "https://example.com/feed"
+	*dest[1].(**string) = &url
+	*dest[2].(*string) = "default"
+	if r.lastSuccessAt != nil {
+		if err := dest[3].(interface{ Scan(any) error }).Scan(r.lastSuccessAt); err != nil {
+			return err
+		}
+	}
+	message := "401 Bad credentials"
+	*dest[4].(**string) = &message
+	*dest[5].(*int) = 3
+	return nil
+}
+
+func TestScanSourceHealth(t *testing.T) {
+	health, err := scanSourceHealth(sourceHealthRow{lastSuccessAt: "2026-01-02T03:04:05Z"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if health.SourceID != "source-1" || health.ConsecutiveFailures != 3 || health.LastError == nil ||
+		*health.LastError != "401 Bad credentials" {
+		t.Errorf("Unexpected health %+v", health)
+	}
+	if want := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC); health.LastSuccessAt == nil ||
+		!health.LastSuccessAt.Equal(want) {
+		t.Errorf("Expected last success %v, got %v", want, health.LastSuccessAt)
+	}
+
+	health, err = scanSourceHealth(sourceHealthRow{})
+	if err != nil || health.LastSuccessAt != nil {
+		t.Errorf("Expected a source never imported to have no last success, got %+v, %v", health, err)
+	}
+}
@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/code-sleuth/ike-go/internal/manager/models"
+	"github.com/code-sleuth/ike-go/pkg/db"
+	"github.com/code-sleuth/ike-go/pkg/dialect"
+	"github.com/code-sleuth/ike-go/pkg/util"
+
+	"github.com/rs/zerolog"
+)
+
+var errSourceSettingsNotFound = errors.New("source settings not found")
+
+const sourceSettingsColumns = `source_url, chunk_strategy, max_tokens, embedding_model, concurrency, ttl_seconds,
+		       expiry_action, acl_labels, created_at, updated_at`
+
+// SourceSettingsRepository stores per-source overrides of the processing options.
+type SourceSettingsRepository struct {
+	db     *db.DB
+	logger zerolog.Logger
+}
+
+func NewSourceSettingsRepository(database *db.DB) *SourceSettingsRepository {
+	logger := util.NewLogger(zerolog.ErrorLevel)
+	return &SourceSettingsRepository{
+		db:     database,
+		logger: logger,
+	}
+}
+
+// Set creates or replaces the settings for settings.SourceURL.
+func (r *SourceSettingsRepository) Set(settings *models.SourceSettings) error {
+	now := time.Now().UTC()
+	settings.CreatedAt = now
+	settings.UpdatedAt = now
+
+	var aclLabels *string
+	if len(settings.ACLLabels) > 0 {
+		labelsJSON, err := json.Marshal(settings.ACLLabels)
+		if err != nil {
+			return err
+		}
+		v := string(labelsJSON)
+		aclLabels = &v
+	}
+
+	query := r.db.Dialect().Upsert("source_settings",
+		[]string{"source_url", "chunk_strategy", "max_tokens", "embedding_model", "concurrency", "ttl_seconds",
+			"expiry_action", "acl_labels", "created_at", "updated_at"},
+		[]string{"source_url"},
+		[]string{"chunk_strategy", "max_tokens", "embedding_model", "concurrency", "ttl_seconds", "expiry_action",
+			"acl_labels", "updated_at"})
+	_, err := r.db.Exec(r.db.Rebind(query), settings.SourceURL, settings.ChunkStrategy, settings.MaxTokens,
+		settings.EmbeddingModel, settings.Concurrency, settings.TTLSeconds, settings.ExpiryAction,
+		aclLabels, r.db.Dialect().FormatTime(settings.CreatedAt), r.db.Dialect().FormatTime(settings.UpdatedAt))
+	if err != nil {
+		r.logger.Error().Err(err).Str("source_url", settings.SourceURL).Msg("Failed to save source settings")
+	}
+	return err
+}
+
+func (r *SourceSettingsRepository) Get(sourceURL string) (*models.SourceSettings, error) {
+	// #nosec G202 -- the column list is a constant
+	query := `SELECT ` + sourceSettingsColumns + ` FROM source_settings WHERE source_url = ?`
+	settings, err := scanSourceSettings(r.db.Reader().QueryRow(r.db.Rebind(query), sourceURL))
+	if errors.Is(err, sql.ErrNoRows) {
+		r.logger.Error().Str("source_url", sourceURL).Msg("Source settings not found")
+		return nil, errSourceSettingsNotFound
+	}
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to get source settings")
+		return nil, err
+	}
+	return settings, nil
+}
+
+// List returns the settings of every source ordered by URL.
+func (r *SourceSettingsRepository) List() ([]models.SourceSettings, error) {
+	// #nosec G202 -- the column list is a constant
+	query := `SELECT ` + sourceSettingsColumns + ` FROM source_settings ORDER BY source_url`
+	rows, err := r.db.Reader().Query(query)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to list source settings")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var all []models.SourceSettings
+	for rows.Next() {
+		settings, err := scanSourceSettings(rows)
+		if err != nil {
+			r.logger.Error().Err(err).Msg("Failed to scan source settings")
+			return nil, err
+		}
+		all = append(all, *settings)
+	}
+
+	return all, rows.Err()
+}
+
+func (r *SourceSettingsRepository) Delete(sourceURL string) error {
+	result, err := r.db.Exec(r.db.Rebind(`DELETE FROM source_settings WHERE source_url = ?`), sourceURL)
+	if err != nil {
+		r.logger.Error().Err(err).Str("source_url", sourceURL).Msg("Failed to delete source settings")
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		r.logger.Error().Str("source_url", sourceURL).Msg("Source settings not found")
+		return errSourceSettingsNotFound
+	}
+	return nil
+}
+
+func scanSourceSettings(row rowScanner) (*models.SourceSettings, error) {
+	var settings models.SourceSettings
+	var chunkStrategy, embeddingModel, expiryAction, aclLabels sql.NullString
+	var maxTokens, concurrency, ttlSeconds sql.NullInt64
+	var createdAtStr, updatedAtStr string
+	err := row.Scan(&settings.SourceURL, &chunkStrategy, &maxTokens, &embeddingModel, &concurrency, &ttlSeconds,
+		&expiryAction, &aclLabels, &createdAtStr, &updatedAtStr)
+	if err != nil {
+		return nil, err
+	}
+
+	if chunkStrategy.Valid {
+		settings.ChunkStrategy = &chunkStrategy.String
+	}
+	if embeddingModel.Valid {
+		settings.EmbeddingModel = &embeddingModel.String
+	}
+	if maxTokens.Valid {
+		v := int(maxTokens.Int64)
+		settings.MaxTokens = &v
+	}
+	if concurrency.Valid {
+		v := int(concurrency.Int64)
+		settings.Concurrency = &v
+	}
+	if ttlSeconds.Valid {
+		settings.TTLSeconds = &ttlSeconds.Int64
+	}
+	if expiryAction.Valid {
+		action := models.ExpiryAction(expiryAction.String)
+		settings.ExpiryAction = &action
+	}
+	if aclLabels.Valid {
+		if err := json.Unmarshal([]byte(aclLabels.String), &settings.ACLLabels); err != nil {
+			return nil, err
+		}
+	}
+
+	if settings.CreatedAt, err = dialect.ParseTime(createdAtStr); err != nil {
+		return nil, err
+	}
+	if settings.UpdatedAt, err = dialect.ParseTime(updatedAtStr); err != nil {
+		return nil, err
+	}
+
+	return &settings, nil
+}
@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/code-sleuth/ike-go/pkg/db"
+)
+
+// Test NewSourceSettingsRepository constructor
+func TestNewSourceSettingsRepository_Unit(t *testing.T) {
+	dbWrapper := &db.DB{}
+	repo := NewSourceSettingsRepository(dbWrapper)
+
+	if repo == nil {
+		t.Fatal("Expected non-nil repository")
+	}
+	if repo.db != dbWrapper {
+		t.Error("Expected database to be set correctly")
+	}
+}
+
+// Test error constants
+func TestSourceSettingsRepository_ErrorConstants(t *testing.T) {
+	if errSourceSettingsNotFound.Error() != "source settings not found" {
+		t.Errorf("Expected 'source settings not found', got '%s'", errSourceSettingsNotFound.Error())
+	}
+}
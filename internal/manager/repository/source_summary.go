@@ -0,0 +1,204 @@
+package repository
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/code-sleuth/ike-go/internal/manager/models"
+	"github.com/code-sleuth/ike-go/pkg/dialect"
+)
+
+// SourceSummary is a source together with what it has produced: its live documents, their chunks
+// and embeddings, when it was last downloaded from, and its most recent failure.
+type SourceSummary struct {
+	models.Source
+	Documents      int        `json:"documents"`
+	Chunks         int        `json:"chunks"`
+	Embeddings     int        `json:"embeddings"`
+	LastImportedAt *time.Time `json:"last_imported_at"`
+	// LastError is the most recent error of an item of the source that failed to process or of
+	// one of its chunks that failed to embed; it stays set after a later import succeeds.
+	LastError   *string    `json:"last_error"`
+	LastErrorAt *time.Time `json:"last_error_at"`
+	// Owners are the teams and people recorded as owning the source, in order.
+	Owners []string `json:"owners"`
+	// Labels are the access-control labels searches enforce on the source's documents, in order.
+	Labels []string `json:"labels"`
+	// LastSuccessAt, LastImportError, and ConsecutiveFailures are the source's health; see
+	// SourceHealth.
+	LastSuccessAt       *time.Time `json:"last_success_at"`
+	LastImportError     *string    `json:"last_import_error"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+}
+
+// Summarize returns a summary of each of sources, in the same order.
+func (r *SourceRepository) Summarize(sources []models.Source) ([]SourceSummary, error) {
+	summaries := make([]SourceSummary, len(sources))
+	if len(sources) == 0 {
+		return summaries, nil
+	}
+
+	index := make(map[string]*SourceSummary, len(sources))
+	ids := make([]interface{}, len(sources))
+	for i, source := range sources {
+		summaries[i].Source = source
+		index[source.ID] = &summaries[i]
+		ids[i] = source.ID
+	}
+	in := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", ") + ")"
+
+	counts := []struct {
+		query string
+		field func(summary *SourceSummary) *int
+	}{
+		{
+			`SELECT source_id, COUNT(*) FROM documents WHERE deleted_at IS NULL AND source_id IN ` + in +
+				` GROUP BY source_id`,
+			func(summary *SourceSummary) *int { return &summary.Documents },
+		},
+		{
+			`SELECT d.source_id, COUNT(*) FROM chunks c JOIN documents d ON d.id = c.document_id
+				WHERE d.deleted_at IS NULL AND d.source_id IN ` + in + ` GROUP BY d.source_id`,
+			func(summary *SourceSummary) *int { return &summary.Chunks },
+		},
+		{
+			`SELECT d.source_id, COUNT(*) FROM embeddings e
+				JOIN chunks c ON e.object_type = 'chunk' AND c.id = e.object_id
+				JOIN documents d ON d.id = c.document_id
+				WHERE d.deleted_at IS NULL AND d.source_id IN ` + in + ` GROUP BY d.source_id`,
+			func(summary *SourceSummary) *int { return &summary.Embeddings },
+		},
+	}
+	for _, count := range counts {
+		err := r.eachRow(count.query, ids, func(rows *sql.Rows) error {
+			var sourceID string
+			var n int
+			if err := rows.Scan(&sourceID, &n); err != nil {
+				return err
+			}
+			*count.field(index[sourceID]) = n
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	query := `SELECT source_id, MAX(downloaded_at) FROM downloads WHERE downloaded_at IS NOT NULL
+		AND source_id IN ` + in + ` GROUP BY source_id`
+	err := r.eachRow(query, ids, func(rows *sql.Rows) error {
+		var sourceID, downloadedAt string
+		if err := rows.Scan(&sourceID, &downloadedAt); err != nil {
+			return err
+		}
+		t, err := dialect.ParseTime(downloadedAt)
+		if err != nil {
+			return err
+		}
+		index[sourceID].LastImportedAt = &t
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	query = `SELECT source_id, updated_at, COALESCE(error, '') FROM pipeline_run_items
+			WHERE status = 'failed' AND source_id IN ` + in + `
+		UNION ALL
+		SELECT d.source_id, f.updated_at, f.error FROM failed_chunks f JOIN documents d ON d.id = f.document_id
+			WHERE d.source_id IN ` + in
+	err = r.eachRow(query, append(ids, ids...), func(rows *sql.Rows) error {
+		var sourceID, updatedAt, message string
+		if err := rows.Scan(&sourceID, &updatedAt, &message); err != nil {
+			return err
+		}
+		t, err := dialect.ParseTime(updatedAt)
+		if err != nil {
+			return err
+		}
+		summary := index[sourceID]
+		if summary.LastErrorAt == nil || t.After(*summary.LastErrorAt) {
+			summary.LastError, summary.LastErrorAt = &message, &t
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	query = `SELECT id, raw_url, collection, last_success_at, last_error, consecutive_failure_count
+		FROM sources WHERE id IN ` + in
+	err = r.eachRow(query, ids, func(rows *sql.Rows) error {
+		health, err := scanSourceHealth(rows)
+		if err != nil {
+			return err
+		}
+		summary := index[health.SourceID]
+		summary.LastSuccessAt, summary.LastImportError = health.LastSuccessAt, health.LastError
+		summary.ConsecutiveFailures = health.ConsecutiveFailures
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	query = `SELECT source_id, owner FROM source_owners WHERE source_id IN ` + in + ` ORDER BY source_id, owner`
+	err = r.eachRow(query, ids, func(rows *sql.Rows) error {
+		var sourceID, owner string
+		if err := rows.Scan(&sourceID, &owner); err != nil {
+			return err
+		}
+		index[sourceID].Owners = append(index[sourceID].Owners, owner)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	query = `SELECT source_id, label FROM source_acl WHERE source_id IN ` + in + ` ORDER BY source_id, label`
+	err = r.eachRow(query, ids, func(rows *sql.Rows) error {
+		var sourceID, label string
+		if err := rows.Scan(&sourceID, &label); err != nil {
+			return err
+		}
+		index[sourceID].Labels = append(index[sourceID].Labels, label)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return summaries, nil
+}
+
+// GetSummary returns the summary of the live source with id.
+func (r *SourceRepository) GetSummary(id string) (*SourceSummary, error) {
+	source, err := r.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	summaries, err := r.Summarize([]models.Source{*source})
+	if err != nil {
+		return nil, err
+	}
+	return &summaries[0], nil
+}
+
+// eachRow runs query with args and calls scan for every row.
+func (r *SourceRepository) eachRow(query string, args []interface{}, scan func(rows *sql.Rows) error) error {
+	// #nosec G202 -- queries are built from constants, source IDs are bound through args
+	rows, err := r.db.Reader().Query(r.db.Rebind(query), args...)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to summarize sources")
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/code-sleuth/ike-go/internal/manager/models"
+	"github.com/code-sleuth/ike-go/internal/manager/testutil"
+	"github.com/code-sleuth/ike-go/pkg/db"
+)
+
+func TestSourceRepository_Summarize_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	testDB := testutil.SetupTestDB(t)
+	defer testutil.CleanupTestDB(t, testDB)
+
+	dbWrapper := &db.DB{DB: testDB}
+	repo := NewSourceRepository(dbWrapper)
+
+	statements := []string{
+		`INSERT INTO sources (id, raw_url, active_domain) VALUES ('summary-source', 'https://example.com/summary', 1),
+			('summary-empty', 'https://example.com/empty', 1)`,
+		`INSERT INTO downloads (id, source_id, downloaded_at, headers) VALUES
+			('summary-download-1', 'summary-source', '2025-01-01T00:00:00Z', '{}'),
+			('summary-download-2', 'summary-source', '2025-02-01T00:00:00Z', '{}')`,
+		`INSERT INTO documents (id, source_id, download_id, min_chunk_size, max_chunk_size) VALUES
+			('summary-doc', 'summary-source', 'summary-download-2', 1, 100)`,
+		`INSERT INTO chunks (id, document_id, body) VALUES ('summary-chunk-1', 'summary-doc', 'a'),
+			('summary-chunk-2', 'summary-doc', 'b')`,
+		`INSERT INTO embeddings (id, model, object_id) VALUES ('summary-embedding', 'm', 'summary-chunk-1')`,
+		`INSERT INTO failed_chunks (chunk_id, document_id, chunk, model, error, created_at, updated_at) VALUES
+			('summary-chunk-3', 'summary-doc', '{}', 'm', 'rate limited',
+			'2025-03-01T00:00:00Z', '2025-03-01T00:00:00Z')`,
+	}
+	for _, statement := range statements {
+		if _, err := testDB.Exec(statement); err != nil {
+			t.Fatalf("Failed to insert test data: %v", err)
+		}
+	}
+
+	summaries, err := repo.Summarize([]models.Source{{ID: "summary-source"}, {ID: "summary-empty"}})
+	if err != nil {
+		t.Fatalf("Failed to summarize sources: %v", err)
+	}
+	if len(summaries) != 2 || summaries[0].ID != "summary-source" || summaries[1].ID != "summary-empty" {
+		t.Fatalf("Expected a summary per source in order, got %+v", summaries)
+	}
+
+	summary := summaries[0]
+	if summary.Documents != 1 || summary.Chunks != 2 || summary.Embeddings != 1 {
+		t.Errorf("Expected 1 document, 2 chunks, and 1 embedding, got %d, %d, and %d",
+			summary.Documents, summary.Chunks, summary.Embeddings)
+	}
+	if summary.LastImportedAt == nil || summary.LastImportedAt.Month() != 2 {
+		t.Errorf("Expected the last import to be the latest download, got %v", summary.LastImportedAt)
+	}
+	if summary.LastError == nil || *summary.LastError != "rate limited" {
+		t.Errorf("Expected the failed chunk's error, got %v", summary.LastError)
+	}
+
+	empty := summaries[1]
+	if empty.Documents != 0 || empty.LastImportedAt != nil || empty.LastError != nil {
+		t.Errorf("Expected an empty summary for a source with no content, got %+v", empty)
+	}
+}
@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/code-sleuth/ike-go/pkg/db"
+)
+
+func TestSourceRepository_Summarize_NoSources(t *testing.T) {
+	// No query runs for an empty list, so no database is needed
+	summaries, err := NewSourceRepository(&db.DB{}).Summarize(nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(summaries) != 0 {
+		t.Errorf("Expected no summaries, got %d", len(summaries))
+	}
+}
@@ -1,22 +1,26 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
+	"net/url"
 	"time"
 
+	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
 	"github.com/code-sleuth/ike-go/internal/manager/models"
 	"github.com/code-sleuth/ike-go/pkg/db"
+	"github.com/code-sleuth/ike-go/pkg/dialect"
 	"github.com/code-sleuth/ike-go/pkg/util"
 
 	"github.com/rs/zerolog"
 )
 
-var (
-	errSourceNotFound             = errors.New("source not found")
-	errUnsupportedTimestampFormat = errors.New("unsupported timestamp format")
-)
+// ErrDuplicateSource is returned when creating a source for a URL that already has a live source.
+var ErrDuplicateSource = errors.New("a source already exists for this URL")
+
+var errSourceNotFound = errors.New("source not found")
 
 type SourceRepository struct {
 	db     *db.DB
@@ -31,33 +35,97 @@ func NewSourceRepository(database *db.DB) *SourceRepository {
 	}
 }
 
+// Create records a new source. Its URL is normalized and its credentials moved to the source's
+// credentials first, and the scheme, host, path, and query not set by the caller are taken from it;
+// if a live source already has that URL, Create returns ErrDuplicateSource rather than recording
+// the same page twice. Its collection is created if needed.
 func (r *SourceRepository) Create(source *models.Source) error {
+	var secrets string
+	if source.RawURL != nil {
+		var err error
+		if secrets, err = r.normalizeSourceURL(source); err != nil {
+			return err
+		}
+	}
+	if err := ensureCollection(context.Background(), r.db, r.db.Dialect(), source.Collection); err != nil {
+		r.logger.Error().Err(err).Str("collection", source.Collection).Msg("Failed to create collection")
+		return err
+	}
+
 	query := `
 		INSERT INTO sources (id, author_email, raw_url, scheme, host, path, 
-		                     query, active_domain, format, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		                     query, active_domain, format, collection, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
-	_, err := r.db.Exec(query, source.ID, source.AuthorEmail, source.RawURL, source.Scheme,
+	_, err := r.db.Exec(r.db.Rebind(query), source.ID, source.AuthorEmail, source.RawURL, source.Scheme,
 		source.Host, source.Path, source.Query, source.ActiveDomain, source.Format,
-		source.CreatedAt.Format("2006-01-02T15:04:05Z"), source.UpdatedAt.Format("2006-01-02T15:04:05Z"))
+		collectionOrDefault(source.Collection), r.db.Dialect().FormatTime(source.CreatedAt),
+		r.db.Dialect().FormatTime(source.UpdatedAt))
 	if err != nil {
 		r.logger.Error().Err(err).Msg("Failed to create source")
+		return err
 	}
-	return err
+	if secrets != "" {
+		if err := saveURLSecrets(context.Background(), r.db, r.db.Dialect(), *source.RawURL, secrets); err != nil {
+			r.logger.Error().Err(err).Msg("Failed to save source URL secrets")
+			return err
+		}
+	}
+	return nil
+}
+
+// normalizeSourceURL normalizes source.RawURL, stripping its credentials, and checks that no live
+// source already has it. It returns the credentials stripped, as util.SplitURLSecrets does.
+func (r *SourceRepository) normalizeSourceURL(source *models.Source) (string, error) {
+	normalized, err := util.NormalizeURL(*source.RawURL)
+	if err != nil {
+		return "", err
+	}
+	normalized, secrets := util.SplitURLSecrets(normalized)
+	parsed, err := url.Parse(normalized)
+	if err != nil {
+		return "", err
+	}
+	source.RawURL = &normalized
+	if source.Scheme == nil {
+		source.Scheme = &parsed.Scheme
+	}
+	if source.Host == nil {
+		source.Host = &parsed.Host
+	}
+	if source.Path == nil {
+		source.Path = &parsed.Path
+	}
+	if source.Query == nil {
+		source.Query = &parsed.RawQuery
+	}
+
+	var existingID string
+	err = r.db.Reader().QueryRow(r.db.Rebind(`SELECT id FROM sources WHERE raw_url = ? AND deleted_at IS NULL`),
+		normalized).Scan(&existingID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return secrets, nil
+	}
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to look up source URL")
+		return "", err
+	}
+	return "", fmt.Errorf("%w: %s is source %s", ErrDuplicateSource, normalized, existingID)
 }
 
 func (r *SourceRepository) GetByID(id string) (*models.Source, error) {
 	query := `
-		SELECT id, author_email, raw_url, scheme, host, path, query, active_domain, format, created_at, updated_at
-		FROM sources WHERE id = ?
+		SELECT id, author_email, raw_url, scheme, host, path, query, active_domain, format, collection,
+		       created_at, updated_at
+		FROM sources WHERE id = ? AND deleted_at IS NULL
 	`
-	row := r.db.QueryRow(query, id)
+	row := r.db.Reader().QueryRow(r.db.Rebind(query), id)
 
 	var source models.Source
 	var createdAtStr, updatedAtStr string
 	err := row.Scan(&source.ID, &source.AuthorEmail, &source.RawURL, &source.Scheme,
-		&source.Host, &source.Path, &source.Query, &source.ActiveDomain, &source.Format,
+		&source.Host, &source.Path, &source.Query, &source.ActiveDomain, &source.Format, &source.Collection,
 		&createdAtStr, &updatedAtStr)
 
 	if errors.Is(err, sql.ErrNoRows) {
@@ -70,13 +138,13 @@ func (r *SourceRepository) GetByID(id string) (*models.Source, error) {
 	}
 
 	// Parse timestamp strings - try multiple formats
-	source.CreatedAt, err = parseTimestamp(createdAtStr)
+	source.CreatedAt, err = dialect.ParseTime(createdAtStr)
 	if err != nil {
 		r.logger.Error().Err(err).Str("created_at", createdAtStr).Msg("Failed to parse created_at")
 		return nil, err
 	}
 
-	source.UpdatedAt, err = parseTimestamp(updatedAtStr)
+	source.UpdatedAt, err = dialect.ParseTime(updatedAtStr)
 	if err != nil {
 		r.logger.Error().Err(err).Str("updated_at", updatedAtStr).Msg("Failed to parse updated_at")
 		return nil, err
@@ -85,27 +153,80 @@ func (r *SourceRepository) GetByID(id string) (*models.Source, error) {
 	return &source, nil
 }
 
+// SourceListOptions filters, sorts, and pages SourceRepository.ListWithOptions.
+type SourceListOptions struct {
+	ListOptions
+	Host          string
+	Format        string
+	Collection    string
+	Owner         string
+	Label         string
+	ActiveDomain  *int
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+}
+
+var sourceSortFields = map[string]bool{
+	"created_at": true,
+	"updated_at": true,
+	"host":       true,
+	"format":     true,
+	"raw_url":    true,
+	"collection": true,
+}
+
+// List returns every live source, newest first.
 func (r *SourceRepository) List() ([]models.Source, error) {
-	query := `
-		SELECT id, author_email, raw_url, scheme, host, path, query, active_domain, format, created_at, updated_at
-		FROM sources ORDER BY created_at DESC
-	`
-	rows, err := r.db.Query(query)
+	return r.ListWithOptions(SourceListOptions{})
+}
+
+// ListWithOptions returns the live sources matching opts.
+func (r *SourceRepository) ListWithOptions(opts SourceListOptions) ([]models.Source, error) {
+	var where filters
+	where.add("deleted_at IS NULL")
+	if opts.Host != "" {
+		where.add("host = ?", opts.Host)
+	}
+	if opts.Format != "" {
+		where.add("format = ?", opts.Format)
+	}
+	if opts.Collection != "" {
+		where.add("collection = ?", opts.Collection)
+	}
+	if opts.Owner != "" {
+		where.add("id IN (SELECT source_id FROM source_owners WHERE owner = ?)", opts.Owner)
+	}
+	if opts.Label != "" {
+		where.add("id IN (SELECT source_id FROM source_acl WHERE label = ?)", opts.Label)
+	}
+	if opts.ActiveDomain != nil {
+		where.add("active_domain = ?", *opts.ActiveDomain)
+	}
+	where.addTimeRange(r.db.Dialect(), "created_at", opts.CreatedAfter, opts.CreatedBefore)
+
+	tail, tailArgs, err := opts.orderAndLimit(sourceSortFields, "created_at", SortDesc)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
 
-	if err = rows.Err(); err != nil {
+	// #nosec G202 -- clauses are built from constants, values are bound through args
+	query := `
+		SELECT id, author_email, raw_url, scheme, host, path, query, active_domain, format, collection,
+		       created_at, updated_at
+		FROM sources` + where.where() + tail
+	rows, err := r.db.Reader().Query(r.db.Rebind(query), append(where.args, tailArgs...)...)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to list sources")
 		return nil, err
 	}
+	defer rows.Close()
 
 	var sources []models.Source
 	for rows.Next() {
 		var source models.Source
 		var createdAtStr, updatedAtStr string
 		err := rows.Scan(&source.ID, &source.AuthorEmail, &source.RawURL, &source.Scheme,
-			&source.Host, &source.Path, &source.Query, &source.ActiveDomain, &source.Format,
+			&source.Host, &source.Path, &source.Query, &source.ActiveDomain, &source.Format, &source.Collection,
 			&createdAtStr, &updatedAtStr)
 		if err != nil {
 			r.logger.Error().Err(err).Msg("Failed to scan source")
@@ -113,13 +234,13 @@ func (r *SourceRepository) List() ([]models.Source, error) {
 		}
 
 		// Parse timestamp strings - try multiple formats
-		source.CreatedAt, err = parseTimestamp(createdAtStr)
+		source.CreatedAt, err = dialect.ParseTime(createdAtStr)
 		if err != nil {
 			r.logger.Error().Err(err).Str("created_at", createdAtStr).Msg("Failed to parse created_at")
 			return nil, err
 		}
 
-		source.UpdatedAt, err = parseTimestamp(updatedAtStr)
+		source.UpdatedAt, err = dialect.ParseTime(updatedAtStr)
 		if err != nil {
 			r.logger.Error().Err(err).Str("updated_at", updatedAtStr).Msg("Failed to parse updated_at")
 			return nil, err
@@ -128,45 +249,308 @@ func (r *SourceRepository) List() ([]models.Source, error) {
 		sources = append(sources, source)
 	}
 
-	return sources, nil
+	return sources, rows.Err()
 }
 
+// Update saves source, creating its collection if needed.
 func (r *SourceRepository) Update(source *models.Source) error {
+	if err := ensureCollection(context.Background(), r.db, r.db.Dialect(), source.Collection); err != nil {
+		r.logger.Error().Err(err).Str("collection", source.Collection).Msg("Failed to create collection")
+		return err
+	}
+	// #nosec G202 -- the dialect's timestamp expression is a constant
 	query := `
 		UPDATE sources SET author_email = ?, raw_url = ?, scheme = ?, host = ?, path = ?, 
-		query = ?, active_domain = ?, format = ?, updated_at = datetime('now')
+		query = ?, active_domain = ?, format = ?, collection = ?, updated_at = ` + r.db.Dialect().Now() + `
 		WHERE id = ?
 	`
-	_, err := r.db.Exec(query, source.AuthorEmail, source.RawURL, source.Scheme,
+	_, err := r.db.Exec(r.db.Rebind(query), source.AuthorEmail, source.RawURL, source.Scheme,
 		source.Host, source.Path, source.Query, source.ActiveDomain, source.Format,
-		source.ID)
+		collectionOrDefault(source.Collection), source.ID)
 	if err != nil {
 		r.logger.Error().Err(err).Msg("Failed to update source")
 	}
 	return err
 }
 
+// Delete soft-deletes a source and its live documents. Use Restore to undo it or Purge to remove
+// the source permanently.
 func (r *SourceRepository) Delete(id string) error {
-	query := `DELETE FROM sources WHERE id = ?`
-	_, err := r.db.Exec(query, id)
+	tx, err := r.db.Begin()
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to begin transaction")
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	deletedAt := r.db.Dialect().FormatTime(time.Now())
+
+	query := `UPDATE sources SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL`
+	result, err := tx.Exec(r.db.Rebind(query), deletedAt, id)
 	if err != nil {
 		r.logger.Error().Err(err).Msg("Failed to delete source")
+		return err
 	}
-	return err
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected > 0 {
+		if err := recordSourceDeletion(tx, r.db.Dialect(), id, "soft-deleted"); err != nil {
+			r.logger.Error().Err(err).Str("source_id", id).Msg("Failed to record source deletion")
+			return err
+		}
+	}
+
+	// Documents share the source's deletion timestamp so Restore brings back exactly this batch
+	query = `UPDATE documents SET deleted_at = ? WHERE source_id = ? AND deleted_at IS NULL`
+	_, err = tx.Exec(r.db.Rebind(query), deletedAt, id)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to delete source documents")
+		return err
+	}
+
+	return tx.Commit()
 }
 
-// parseTimestamp handles multiple timestamp formats used by SQLite.
-func parseTimestamp(timestampStr string) (time.Time, error) {
-	// Try ISO 8601 format first (default creation format)
-	if t, err := time.Parse("2006-01-02T15:04:05Z", timestampStr); err == nil {
-		return t, nil
+// Restore undoes a soft delete, bringing back the source and the documents deleted with it, and
+// its collection if that was deleted too.
+func (r *SourceRepository) Restore(id string) error {
+	var deletedAt, collection string
+	query := `SELECT deleted_at, collection FROM sources WHERE id = ? AND deleted_at IS NOT NULL`
+	err := r.db.QueryRow(r.db.Rebind(query), id).Scan(&deletedAt, &collection)
+	if errors.Is(err, sql.ErrNoRows) {
+		r.logger.Error().Str("source_id", id).Msg("Deleted source not found")
+		return errSourceNotFound
+	}
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to get deleted source")
+		return err
 	}
 
-	// Try SQLite datetime() format (used by updates)
-	if t, err := time.Parse("2006-01-02 15:04:05", timestampStr); err == nil {
-		return t, nil
+	tx, err := r.db.Begin()
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to begin transaction")
+		return err
 	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
 
-	// If both fail, return error
-	return time.Time{}, fmt.Errorf("%w: %s", errUnsupportedTimestampFormat, timestampStr)
+	if _, err := tx.Exec(r.db.Rebind(`UPDATE sources SET deleted_at = NULL WHERE id = ?`), id); err != nil {
+		r.logger.Error().Err(err).Msg("Failed to restore source")
+		return err
+	}
+	if err := ensureCollection(context.Background(), tx, r.db.Dialect(), collection); err != nil {
+		r.logger.Error().Err(err).Str("collection", collection).Msg("Failed to restore collection")
+		return err
+	}
+	query = `UPDATE documents SET deleted_at = NULL WHERE source_id = ? AND deleted_at = ?`
+	_, err = tx.Exec(r.db.Rebind(query), id, deletedAt)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to restore source documents")
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Purge permanently removes a source, whether or not it was soft-deleted, together with its
+// downloads, documents, metadata, tags, chunks, and embeddings in one transaction. Embeddings are
+// only stored in the database, so nothing outside it is left pointing at the source.
+func (r *SourceRepository) Purge(id string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to begin transaction")
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	var exists int
+	err = tx.QueryRow(r.db.Rebind(`SELECT 1 FROM sources WHERE id = ?`), id).Scan(&exists)
+	if errors.Is(err, sql.ErrNoRows) {
+		r.logger.Error().Str("source_id", id).Msg("Source not found")
+		return errSourceNotFound
+	}
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to get source")
+		return err
+	}
+
+	if err := recordSourceDeletion(tx, r.db.Dialect(), id, "purged"); err != nil {
+		r.logger.Error().Err(err).Str("source_id", id).Msg("Failed to record source deletion")
+		return err
+	}
+	if err := purgeSources(tx, r.db.Dialect(), `SELECT ? AS id`, id); err != nil {
+		r.logger.Error().Err(err).Str("source_id", id).Msg("Failed to purge source")
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// PurgeDeleted permanently removes every source soft-deleted before the cutoff and returns how many were purged.
+func (r *SourceRepository) PurgeDeleted(before time.Time) (int, error) {
+	cutoff := r.db.Dialect().FormatTime(before)
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to begin transaction")
+		return 0, err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	deletedSourceIDs := `SELECT id FROM sources WHERE deleted_at IS NOT NULL AND deleted_at < ?`
+	ids, err := queryIDs(tx, r.db.Rebind(deletedSourceIDs), cutoff)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to list deleted sources")
+		return 0, err
+	}
+	for _, id := range ids {
+		if err := recordSourceDeletion(tx, r.db.Dialect(), id, "purged"); err != nil {
+			r.logger.Error().Err(err).Str("source_id", id).Msg("Failed to record source deletion")
+			return 0, err
+		}
+	}
+
+	err = purgeSources(tx, r.db.Dialect(), deletedSourceIDs, cutoff)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to purge deleted sources")
+		return 0, err
+	}
+
+	return len(ids), tx.Commit()
+}
+
+// MergeDuplicates normalizes the URLs of live sources recorded before their current normalization
+// and merges the sources that now share a URL into the oldest of them, as migration 0015 did for
+// identical URLs: their downloads, documents, and run items move to it, it gains their owners and
+// access-control labels, and the duplicates are soft-deleted. It returns how many sources were merged away.
+func (r *SourceRepository) MergeDuplicates() (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to begin transaction")
+		return 0, err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	rows, err := tx.Query(`SELECT id, raw_url FROM sources WHERE deleted_at IS NULL AND raw_url IS NOT NULL
+		ORDER BY created_at, id`)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to list sources")
+		return 0, err
+	}
+	type renamedSource struct{ id, url string }
+	var renamed []renamedSource
+	keepIDs := make(map[string]string)
+	duplicates := make(map[string]string)
+	for rows.Next() {
+		var id, rawURL string
+		if err := rows.Scan(&id, &rawURL); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		normalized, err := util.NormalizeURL(rawURL)
+		if err != nil {
+			// Left as recorded, since nothing else can normalize to a URL that is not absolute
+			continue
+		}
+		if keepID, ok := keepIDs[normalized]; ok {
+			duplicates[id] = keepID
+			continue
+		}
+		keepIDs[normalized] = id
+		if normalized != rawURL {
+			renamed = append(renamed, renamedSource{id: id, url: normalized})
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	deletedAt := r.db.Dialect().FormatTime(time.Now())
+	for duplicateID, keepID := range duplicates {
+		for _, table := range []string{"downloads", "documents", "pipeline_run_items"} {
+			// #nosec G202 -- table names are constants
+			query := `UPDATE ` + table + ` SET source_id = ? WHERE source_id = ?`
+			if _, err := tx.Exec(r.db.Rebind(query), keepID, duplicateID); err != nil {
+				r.logger.Error().Err(err).Str("source_id", duplicateID).Msgf("Failed to move %s", table)
+				return 0, err
+			}
+		}
+		// The kept source gains the owners of its duplicates
+		query := `INSERT INTO source_owners (source_id, owner, created_at)
+			SELECT ?, owner, created_at FROM source_owners WHERE source_id = ?
+			ON CONFLICT (source_id, owner) DO NOTHING`
+		if _, err := tx.Exec(r.db.Rebind(query), keepID, duplicateID); err != nil {
+			r.logger.Error().Err(err).Str("source_id", duplicateID).Msg("Failed to move source owners")
+			return 0, err
+		}
+		// and their access-control labels, which the next import of the source replaces
+		query = `INSERT INTO source_acl (source_id, label, created_at)
+			SELECT ?, label, created_at FROM source_acl WHERE source_id = ?
+			ON CONFLICT (source_id, label) DO NOTHING`
+		if _, err := tx.Exec(r.db.Rebind(query), keepID, duplicateID); err != nil {
+			r.logger.Error().Err(err).Str("source_id", duplicateID).Msg("Failed to move source labels")
+			return 0, err
+		}
+		query = `UPDATE sources SET deleted_at = ? WHERE id = ?`
+		if _, err := tx.Exec(r.db.Rebind(query), deletedAt, duplicateID); err != nil {
+			r.logger.Error().Err(err).Str("source_id", duplicateID).Msg("Failed to delete duplicate source")
+			return 0, err
+		}
+	}
+
+	// Renamed once the duplicates holding their new URL are deleted, to keep live URLs unique
+	for _, source := range renamed {
+		parsed, err := url.Parse(source.url)
+		if err != nil {
+			return 0, err
+		}
+		query := `UPDATE sources SET raw_url = ?, scheme = ?, host = ?, path = ?, query = ? WHERE id = ?`
+		_, err = tx.Exec(r.db.Rebind(query), source.url, parsed.Scheme, parsed.Host, parsed.Path, parsed.RawQuery,
+			source.id)
+		if err != nil {
+			r.logger.Error().Err(err).Str("source_id", source.id).Msg("Failed to normalize source URL")
+			return 0, err
+		}
+	}
+
+	return len(duplicates), tx.Commit()
+}
+
+// queryIDs returns the IDs selected by query.
+func queryIDs(tx *sql.Tx, query string, args ...interface{}) ([]string, error) {
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// collectionOrDefault returns collection, or the default collection when it is empty.
+func collectionOrDefault(collection string) string {
+	if collection == "" {
+		return interfaces.DefaultCollection
+	}
+	return collection
 }
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"errors"
 	"testing"
 	"time"
 
@@ -643,3 +644,122 @@ func TestSourceRepository_FullWorkflow_Integration(t *testing.T) {
 func stringPtrInteg(s string) *string {
 	return &s
 }
+
+func TestSourceRepository_Purge_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	testDB := testutil.SetupTestDB(t)
+	defer testutil.CleanupTestDB(t, testDB)
+
+	dbWrapper := &db.DB{DB: testDB}
+	repo := NewSourceRepository(dbWrapper)
+
+	statements := []string{
+		`INSERT INTO sources (id, raw_url, active_domain) VALUES ('purge-source', 'https://example.com/purge', 1),
+			('purge-other', 'https://example.com/other', 1)`,
+		`INSERT INTO downloads (id, source_id, headers) VALUES ('purge-download', 'purge-source', '{}'),
+			('purge-other-download', 'purge-other', '{}')`,
+		`INSERT INTO documents (id, source_id, download_id, min_chunk_size, max_chunk_size) VALUES
+			('purge-doc', 'purge-source', 'purge-download', 1, 100),
+			('purge-other-doc', 'purge-other', 'purge-other-download', 1, 100)`,
+		`INSERT INTO document_meta (id, document_id, key, meta) VALUES ('purge-meta', 'purge-doc', 'title', '{}')`,
+		`INSERT INTO chunks (id, document_id, body) VALUES ('purge-chunk', 'purge-doc', 'a'),
+			('purge-other-chunk', 'purge-other-doc', 'b')`,
+		`INSERT INTO embeddings (id, model, object_id) VALUES ('purge-embedding', 'm', 'purge-chunk'),
+			('purge-other-embedding', 'm', 'purge-other-chunk')`,
+	}
+	for _, statement := range statements {
+		if _, err := testDB.Exec(statement); err != nil {
+			t.Fatalf("Failed to insert test data: %v", err)
+		}
+	}
+
+	if err := repo.Purge("purge-source"); err != nil {
+		t.Fatalf("Failed to purge source: %v", err)
+	}
+
+	remaining := map[string]int{
+		"sources":       1,
+		"downloads":     1,
+		"documents":     1,
+		"document_meta": 0,
+		"chunks":        1,
+		"embeddings":    1,
+	}
+	for table, expected := range remaining {
+		var count int
+		if err := testDB.QueryRow(`SELECT COUNT(*) FROM ` + table).Scan(&count); err != nil {
+			t.Fatalf("Failed to count %s: %v", table, err)
+		}
+		if count != expected {
+			t.Errorf("Expected %d rows left in %s, got %d", expected, table, count)
+		}
+	}
+
+	if err := repo.Purge("purge-source"); !errors.Is(err, errSourceNotFound) {
+		t.Errorf("Expected errSourceNotFound purging a missing source, got %v", err)
+	}
+}
+
+func TestSourceRepository_MergeDuplicates_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	testDB := testutil.SetupTestDB(t)
+	defer testutil.CleanupTestDB(t, testDB)
+
+	dbWrapper := &db.DB{DB: testDB}
+	repo := NewSourceRepository(dbWrapper)
+
+	statements := []string{
+		`INSERT INTO sources (id, raw_url, active_domain, created_at) VALUES
+			('merge-keep', 'https://example.com/post?utm_source=feed', 1, '2024-01-01T00:00:00Z'),
+			('merge-dup', 'https://example.com/post', 1, '2024-02-01T00:00:00Z'),
+			('merge-other', 'https://example.com/other', 1, '2024-01-01T00:00:00Z')`,
+		`INSERT INTO downloads (id, source_id, headers) VALUES ('merge-download', 'merge-dup', '{}')`,
+		`INSERT INTO documents (id, source_id, download_id, min_chunk_size, max_chunk_size) VALUES
+			('merge-doc', 'merge-dup', 'merge-download', 1, 100)`,
+	}
+	for _, statement := range statements {
+		if _, err := testDB.Exec(statement); err != nil {
+			t.Fatalf("Failed to insert test data: %v", err)
+		}
+	}
+
+	merged, err := repo.MergeDuplicates()
+	if err != nil {
+		t.Fatalf("Failed to merge duplicate sources: %v", err)
+	}
+	if merged != 1 {
+		t.Errorf("Expected 1 merged source, got %d", merged)
+	}
+
+	source, err := repo.GetByID("merge-keep")
+	if err != nil {
+		t.Fatalf("Failed to get kept source: %v", err)
+	}
+	if *source.RawURL != "https://example.com/post" {
+		t.Errorf("Expected normalized URL, got %s", *source.RawURL)
+	}
+	if _, err := repo.GetByID("merge-dup"); !errors.Is(err, errSourceNotFound) {
+		t.Errorf("Expected duplicate to be deleted, got %v", err)
+	}
+	var sourceID string
+	if err := testDB.QueryRow(`SELECT source_id FROM documents WHERE id = 'merge-doc'`).Scan(&sourceID); err != nil {
+		t.Fatalf("Failed to get document: %v", err)
+	}
+	if sourceID != "merge-keep" {
+		t.Errorf("Expected document to move to merge-keep, got %s", sourceID)
+	}
+
+	err = repo.Create(&models.Source{
+		ID: "merge-new", RawURL: stringPtrInteg("HTTPS://Example.com/post/?gclid=x"), ActiveDomain: 1,
+		CreatedAt: time.Now(), UpdatedAt: time.Now(),
+	})
+	if !errors.Is(err, ErrDuplicateSource) {
+		t.Errorf("Expected ErrDuplicateSource, got %v", err)
+	}
+}
@@ -0,0 +1,222 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/code-sleuth/ike-go/internal/manager/models"
+	"github.com/code-sleuth/ike-go/pkg/db"
+	"github.com/code-sleuth/ike-go/pkg/dialect"
+	"github.com/code-sleuth/ike-go/pkg/util"
+
+	"github.com/rs/zerolog"
+)
+
+// Status counts what the database holds and what is still outstanding. Soft-deleted sources and
+// documents, and the chunks and embeddings under them, are not counted.
+type Status struct {
+	// Collection is the collection counted, or empty for the whole database. Jobs and resumable runs
+	// are counted for the whole database either way.
+	Collection string `json:"collection,omitempty"`
+	Sources    int    `json:"sources"`
+	Documents  int    `json:"documents"`
+	Chunks     int    `json:"chunks"`
+	// Embeddings counts embeddings by model.
+	Embeddings   map[string]int `json:"embeddings"`
+	FailedChunks int            `json:"failed_chunks"`
+	// Jobs counts queued jobs by status.
+	Jobs map[models.JobStatus]int `json:"jobs"`
+	// ResumableRuns counts pipeline runs that did not complete and will be resumed by the next
+	// import of their source.
+	ResumableRuns int `json:"resumable_runs"`
+}
+
+// StatusRepository summarizes the database for operators.
+type StatusRepository struct {
+	db     *db.DB
+	logger zerolog.Logger
+}
+
+func NewStatusRepository(database *db.DB) *StatusRepository {
+	logger := util.NewLogger(zerolog.ErrorLevel)
+	return &StatusRepository{
+		db:     database,
+		logger: logger,
+	}
+}
+
+// Get returns the current counts.
+func (r *StatusRepository) Get() (*Status, error) {
+	return r.GetCollection("")
+}
+
+// GetCollection returns the current counts of the named collection, or of the whole database when
+// collection is empty.
+func (r *StatusRepository) GetCollection(collection string) (*Status, error) {
+	status := &Status{
+		Collection: collection,
+		Embeddings: make(map[string]int),
+		Jobs:       make(map[models.JobStatus]int),
+	}
+
+	// scope is appended to a count's query, binding the collection, when one is given
+	const inCollection = ` AND d.source_id IN (SELECT id FROM sources WHERE collection = ?)`
+	counts := []struct {
+		query string
+		scope string
+		dest  *int
+	}{
+		{`SELECT COUNT(*) FROM sources WHERE deleted_at IS NULL`, ` AND collection = ?`, &status.Sources},
+		{`SELECT COUNT(*) FROM documents d WHERE d.deleted_at IS NULL`, inCollection, &status.Documents},
+		{`SELECT COUNT(*) FROM chunks c JOIN documents d ON d.id = c.document_id
+			WHERE d.deleted_at IS NULL`, inCollection, &status.Chunks},
+		{`SELECT COUNT(*) FROM failed_chunks`, ` WHERE document_id IN (SELECT d.id FROM documents d
+			JOIN sources s ON s.id = d.source_id WHERE s.collection = ?)`, &status.FailedChunks},
+		{`SELECT COUNT(*) FROM pipeline_runs WHERE status NOT IN ('completed', 'abandoned')`, "",
+			&status.ResumableRuns},
+	}
+	for _, count := range counts {
+		query, args := scoped(count.query, count.scope, collection)
+		if err := r.db.Reader().QueryRow(r.db.Rebind(query), args...).Scan(count.dest); err != nil {
+			r.logger.Error().Err(err).Msg("Failed to count status")
+			return nil, err
+		}
+	}
+
+	query, args := scoped(`SELECT e.model, COUNT(*) FROM embeddings e
+		JOIN chunks c ON e.object_type = 'chunk' AND c.id = e.object_id
+		JOIN documents d ON d.id = c.document_id
+		WHERE d.deleted_at IS NULL`, inCollection, collection)
+	err := countBy(r.db.Reader(), r.db.Rebind(query+` GROUP BY e.model`), args, func(key string, n int) {
+		status.Embeddings[key] = n
+	})
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to count status")
+		return nil, err
+	}
+
+	err = countBy(r.db.Reader(), `SELECT status, COUNT(*) FROM jobs GROUP BY status`, nil, func(key string, n int) {
+		status.Jobs[models.JobStatus(key)] = n
+	})
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to count status")
+		return nil, err
+	}
+
+	return status, nil
+}
+
+// scoped appends scope to query, and returns collection as its argument, when both are set.
+func scoped(query, scope, collection string) (string, []interface{}) {
+	if collection == "" || scope == "" {
+		return query, nil
+	}
+	return query + scope, []interface{}{collection}
+}
+
+// countBy runs query, which selects a key and a count per row, and passes each row to add.
+func countBy(database *sql.DB, query string, args []interface{}, add func(key string, n int)) error {
+	rows, err := database.Query(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var key sql.NullString
+		var n int
+		if err := rows.Scan(&key, &n); err != nil {
+			return err
+		}
+		add(key.String, n)
+	}
+	return rows.Err()
+}
+
+// RunProgress is how far a pipeline run still in progress has got. Items counts the items the
+// import has recorded so far, which grows while the import is running.
+type RunProgress struct {
+	ID        string    `json:"id"`
+	SourceURL string    `json:"source_url"`
+	StartedAt time.Time `json:"started_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Items     int       `json:"items"`
+	Done      int       `json:"done"`
+	Failed    int       `json:"failed"`
+}
+
+// ActiveRuns returns the pipeline runs in progress, oldest first. A run whose process died stays
+// in progress until its source is imported again; UpdatedAt tells it from a live one.
+func (r *StatusRepository) ActiveRuns() ([]RunProgress, error) {
+	query := `SELECT r.id, r.source_url, r.started_at, r.updated_at, COUNT(i.item_key),
+			COALESCE(SUM(CASE WHEN i.status = 'done' THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN i.status = 'failed' THEN 1 ELSE 0 END), 0)
+		FROM pipeline_runs r LEFT JOIN pipeline_run_items i ON i.run_id = r.id
+		WHERE r.status = 'running'
+		GROUP BY r.id, r.source_url, r.started_at, r.updated_at
+		ORDER BY r.started_at, r.id`
+	rows, err := r.db.Reader().Query(query)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to list active runs")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var runs []RunProgress
+	for rows.Next() {
+		var run RunProgress
+		var startedAt, updatedAt string
+		err := rows.Scan(&run.ID, &run.SourceURL, &startedAt, &updatedAt, &run.Items, &run.Done, &run.Failed)
+		if err != nil {
+			return nil, err
+		}
+		if run.StartedAt, err = dialect.ParseTime(startedAt); err != nil {
+			return nil, err
+		}
+		if run.UpdatedAt, err = dialect.ParseTime(updatedAt); err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
+}
+
+// Failure is a recent error: a failed job, a pipeline run item that failed, or a chunk
+// dead-lettered in failed_chunks. Kind is "job", "item", or "chunk", and ID the job ID, item key,
+// or chunk ID.
+type Failure struct {
+	At    time.Time `json:"at"`
+	Kind  string    `json:"kind"`
+	ID    string    `json:"id"`
+	Error string    `json:"error"`
+}
+
+// RecentFailures returns up to limit of the most recent failures, newest first.
+func (r *StatusRepository) RecentFailures(limit int) ([]Failure, error) {
+	query := `SELECT updated_at, 'job', id, COALESCE(error, '') FROM jobs WHERE status = 'failed'
+		UNION ALL
+		SELECT updated_at, 'item', item_key, COALESCE(error, '') FROM pipeline_run_items WHERE status = 'failed'
+		UNION ALL
+		SELECT updated_at, 'chunk', chunk_id, error FROM failed_chunks
+		ORDER BY 1 DESC LIMIT ?`
+	rows, err := r.db.Reader().Query(r.db.Rebind(query), limit)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to list recent failures")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var failures []Failure
+	for rows.Next() {
+		var failure Failure
+		var at string
+		if err := rows.Scan(&at, &failure.Kind, &failure.ID, &failure.Error); err != nil {
+			return nil, err
+		}
+		if failure.At, err = dialect.ParseTime(at); err != nil {
+			return nil, err
+		}
+		failures = append(failures, failure)
+	}
+	return failures, rows.Err()
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/code-sleuth/ike-go/internal/manager/models"
+	"github.com/code-sleuth/ike-go/internal/manager/testutil"
+	"github.com/code-sleuth/ike-go/pkg/db"
+)
+
+func TestStatusRepository_Get_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	testDB := testutil.SetupTestDB(t)
+	defer testutil.CleanupTestDB(t, testDB)
+
+	dbWrapper := &db.DB{DB: testDB}
+	repo := NewStatusRepository(dbWrapper)
+
+	before, err := repo.Get()
+	if err != nil {
+		t.Fatalf("Failed to get status: %v", err)
+	}
+
+	job := &models.Job{ID: "status-job", Kind: "import", Payload: "{}", MaxAttempts: 1}
+	if err := NewJobRepository(dbWrapper).Enqueue(job); err != nil {
+		t.Fatalf("Failed to enqueue job: %v", err)
+	}
+	defer func() {
+		_, _ = testDB.Exec(`DELETE FROM jobs WHERE id = ?`, job.ID)
+	}()
+
+	after, err := repo.Get()
+	if err != nil {
+		t.Fatalf("Failed to get status: %v", err)
+	}
+	if after.Jobs[models.JobQueued] != before.Jobs[models.JobQueued]+1 {
+		t.Errorf("Expected one more queued job, got %d before and %d after",
+			before.Jobs[models.JobQueued], after.Jobs[models.JobQueued])
+	}
+}
+
+func TestStatusRepository_ActiveRunsAndFailures_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	testDB := testutil.SetupTestDB(t)
+	defer testutil.CleanupTestDB(t, testDB)
+
+	dbWrapper := &db.DB{DB: testDB}
+	repo := NewStatusRepository(dbWrapper)
+
+	statements := []string{
+		`INSERT INTO pipeline_runs (id, source_url, status, started_at, updated_at)
+			VALUES ('status-run', 'https://example.com/status', 'running',
+			'2025-01-01T00:00:00Z', '2025-01-01T00:01:00Z')`,
+		`INSERT INTO pipeline_run_items (run_id, item_key, position, download_id, status, error, updated_at)
+			VALUES ('status-run', 'a', 0, 'download-a', 'done', NULL, '2025-01-01T00:00:30Z'),
+			('status-run', 'b', 1, 'download-b', 'failed', 'transform failed', '2100-01-01T00:00:00Z'),
+			('status-run', 'c', 2, 'download-c', 'imported', NULL, '2025-01-01T00:01:00Z')`,
+	}
+	for _, statement := range statements {
+		if _, err := testDB.Exec(statement); err != nil {
+			t.Fatalf("Failed to insert test data: %v", err)
+		}
+	}
+	defer func() {
+		_, _ = testDB.Exec(`DELETE FROM pipeline_run_items WHERE run_id = 'status-run'`)
+		_, _ = testDB.Exec(`DELETE FROM pipeline_runs WHERE id = 'status-run'`)
+	}()
+
+	runs, err := repo.ActiveRuns()
+	if err != nil {
+		t.Fatalf("Failed to list active runs: %v", err)
+	}
+	var found bool
+	for _, run := range runs {
+		if run.ID == "status-run" {
+			found = true
+			if run.Items != 3 || run.Done != 1 || run.Failed != 1 {
+				t.Errorf("Expected 3 items with 1 done and 1 failed, got %+v", run)
+			}
+		}
+	}
+	if !found {
+		t.Error("Expected the running run to be listed")
+	}
+
+	failures, err := repo.RecentFailures(1)
+	if err != nil {
+		t.Fatalf("Failed to list recent failures: %v", err)
+	}
+	if len(failures) != 1 || failures[0].Kind != "item" || failures[0].ID != "b" {
+		t.Errorf("Expected the newest failure to be item b, got %+v", failures)
+	}
+}
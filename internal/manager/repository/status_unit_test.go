@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/code-sleuth/ike-go/pkg/db"
+)
+
+// Test NewStatusRepository constructor
+func TestNewStatusRepository_Unit(t *testing.T) {
+	dbWrapper := &db.DB{}
+	repo := NewStatusRepository(dbWrapper)
+
+	if repo == nil {
+		t.Fatal("Expected non-nil repository")
+	}
+	if repo.db != dbWrapper {
+		t.Error("Expected database to be set correctly")
+	}
+}
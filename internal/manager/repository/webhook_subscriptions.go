@@ -0,0 +1,278 @@
+package repository
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/code-sleuth/ike-go/internal/manager/models"
+	"github.com/code-sleuth/ike-go/pkg/db"
+	"github.com/code-sleuth/ike-go/pkg/dialect"
+	"github.com/code-sleuth/ike-go/pkg/util"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+// ErrWebhookSubscriptionNotFound is exported, unlike the other not-found errors, so the HTTP API
+// can answer 404 for an unknown subscription.
+var ErrWebhookSubscriptionNotFound = errors.New("webhook subscription not found")
+
+// WebhookSubscriptionRepository stores outbound webhook subscriptions and the deliveries of events
+// to them.
+type WebhookSubscriptionRepository struct {
+	db     *db.DB
+	logger zerolog.Logger
+}
+
+func NewWebhookSubscriptionRepository(database *db.DB) *WebhookSubscriptionRepository {
+	logger := util.NewLogger(zerolog.ErrorLevel)
+	return &WebhookSubscriptionRepository{
+		db:     database,
+		logger: logger,
+	}
+}
+
+// Create stores subscription. ID and timestamps are filled in when unset.
+func (r *WebhookSubscriptionRepository) Create(subscription *models.WebhookSubscription) error {
+	now := time.Now().UTC()
+	if subscription.ID == "" {
+		subscription.ID = uuid.New().String()
+	}
+	subscription.CreatedAt = now
+	subscription.UpdatedAt = now
+
+	query := `
+		INSERT INTO webhook_subscriptions (id, url, secret, event_types, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+	_, err := r.db.Exec(r.db.Rebind(query), subscription.ID, subscription.URL, subscription.Secret,
+		joinEventTypes(subscription.EventTypes), r.db.Dialect().FormatTime(subscription.CreatedAt),
+		r.db.Dialect().FormatTime(subscription.UpdatedAt))
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to create webhook subscription")
+	}
+	return err
+}
+
+// List returns every subscription, oldest first.
+func (r *WebhookSubscriptionRepository) List() ([]models.WebhookSubscription, error) {
+	query := `SELECT id, url, secret, event_types, created_at, updated_at FROM webhook_subscriptions
+		ORDER BY created_at, id`
+	rows, err := r.db.Reader().Query(r.db.Rebind(query))
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to list webhook subscriptions")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subscriptions []models.WebhookSubscription
+	for rows.Next() {
+		var subscription models.WebhookSubscription
+		var eventTypes, createdAt, updatedAt string
+		err := rows.Scan(&subscription.ID, &subscription.URL, &subscription.Secret, &eventTypes, &createdAt,
+			&updatedAt)
+		if err != nil {
+			r.logger.Error().Err(err).Msg("Failed to scan webhook subscription")
+			return nil, err
+		}
+		subscription.EventTypes = splitEventTypes(eventTypes)
+		if subscription.CreatedAt, err = dialect.ParseTime(createdAt); err != nil {
+			return nil, err
+		}
+		if subscription.UpdatedAt, err = dialect.ParseTime(updatedAt); err != nil {
+			return nil, err
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+
+	return subscriptions, rows.Err()
+}
+
+// Delete removes a subscription together with its deliveries, so nothing more is sent to it.
+func (r *WebhookSubscriptionRepository) Delete(id string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to begin transaction")
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if _, err := tx.Exec(r.db.Rebind(`DELETE FROM webhook_deliveries WHERE subscription_id = ?`), id); err != nil {
+		r.logger.Error().Err(err).Str("subscription_id", id).Msg("Failed to delete webhook deliveries")
+		return err
+	}
+	result, err := tx.Exec(r.db.Rebind(`DELETE FROM webhook_subscriptions WHERE id = ?`), id)
+	if err != nil {
+		r.logger.Error().Err(err).Str("subscription_id", id).Msg("Failed to delete webhook subscription")
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		r.logger.Error().Str("subscription_id", id).Msg("Webhook subscription not found")
+		return ErrWebhookSubscriptionNotFound
+	}
+
+	return tx.Commit()
+}
+
+// QueueDeliveries adds a pending delivery, due now, for every event subscription receives that
+// was recorded since it was created and since since, and returns how many were added. Events
+// already queued for the subscription are skipped, so overlapping calls are safe.
+func (r *WebhookSubscriptionRepository) QueueDeliveries(
+	subscription models.WebhookSubscription,
+	since time.Time,
+) (int64, error) {
+	if since.Before(subscription.CreatedAt) {
+		since = subscription.CreatedAt
+	}
+	now := r.db.Dialect().FormatTime(time.Now())
+	args := []interface{}{subscription.ID, string(models.WebhookDeliveryPending), now, now, now,
+		r.db.Dialect().FormatTime(since), subscription.ID}
+
+	var typeFilter string
+	if len(subscription.EventTypes) > 0 {
+		typeFilter = " AND e.type IN (" + strings.TrimSuffix(strings.Repeat("?, ", len(subscription.EventTypes)),
+			", ") + ")"
+		for _, eventType := range subscription.EventTypes {
+			args = append(args, string(eventType))
+		}
+	}
+
+	// #nosec G202 -- the type filter only holds placeholders, values are bound through args
+	query := `
+		INSERT INTO webhook_deliveries (subscription_id, event_id, status, attempts, next_attempt_at, created_at,
+			updated_at)
+		SELECT ?, e.id, ?, 0, ?, ?, ? FROM events e
+		WHERE e.created_at >= ? AND NOT EXISTS (
+			SELECT 1 FROM webhook_deliveries d WHERE d.subscription_id = ? AND d.event_id = e.id
+		)` + typeFilter
+	result, err := r.db.Exec(r.db.Rebind(query), args...)
+	if err != nil {
+		r.logger.Error().Err(err).Str("subscription_id", subscription.ID).Msg("Failed to queue webhook deliveries")
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// Pending returns up to limit pending deliveries due at or before now, oldest event first.
+func (r *WebhookSubscriptionRepository) Pending(now time.Time, limit int) ([]models.WebhookDelivery, error) {
+	query := `
+		SELECT d.subscription_id, s.url, s.secret, d.status, d.attempts, d.last_error,
+		       e.id, e.type, e.source_id, e.document_id, e.job_id, e.actor, e.detail, e.created_at
+		FROM webhook_deliveries d
+		JOIN webhook_subscriptions s ON s.id = d.subscription_id
+		JOIN events e ON e.id = d.event_id
+		WHERE d.status = ? AND d.next_attempt_at <= ?
+		ORDER BY e.created_at, e.id
+		LIMIT ?
+	`
+	return r.queryDeliveries(query, string(models.WebhookDeliveryPending), r.db.Dialect().FormatTime(now), limit)
+}
+
+// Deliveries returns the most recent limit deliveries to subscription id, newest event first.
+func (r *WebhookSubscriptionRepository) Deliveries(id string, limit int) ([]models.WebhookDelivery, error) {
+	query := `
+		SELECT d.subscription_id, s.url, s.secret, d.status, d.attempts, d.last_error,
+		       e.id, e.type, e.source_id, e.document_id, e.job_id, e.actor, e.detail, e.created_at
+		FROM webhook_deliveries d
+		JOIN webhook_subscriptions s ON s.id = d.subscription_id
+		JOIN events e ON e.id = d.event_id
+		WHERE d.subscription_id = ?
+		ORDER BY e.created_at DESC, e.id DESC
+		LIMIT ?
+	`
+	return r.queryDeliveries(query, id, limit)
+}
+
+// MarkDelivered records that the event was accepted by the subscription's endpoint.
+func (r *WebhookSubscriptionRepository) MarkDelivered(subscriptionID, eventID string) error {
+	query := `
+		UPDATE webhook_deliveries SET status = ?, attempts = attempts + 1, last_error = NULL, updated_at = ?
+		WHERE subscription_id = ? AND event_id = ?
+	`
+	_, err := r.db.Exec(r.db.Rebind(query), string(models.WebhookDeliveryDelivered),
+		r.db.Dialect().FormatTime(time.Now()), subscriptionID, eventID)
+	if err != nil {
+		r.logger.Error().Err(err).Str("subscription_id", subscriptionID).Msg("Failed to record webhook delivery")
+	}
+	return err
+}
+
+// MarkFailed records a failed attempt to deliver the event. The delivery is retried at next, or
+// left failed when next is nil.
+func (r *WebhookSubscriptionRepository) MarkFailed(subscriptionID, eventID string, cause error, next *time.Time) error {
+	status, nextAttempt := string(models.WebhookDeliveryFailed), time.Now()
+	if next != nil {
+		status, nextAttempt = string(models.WebhookDeliveryPending), *next
+	}
+
+	query := `
+		UPDATE webhook_deliveries SET status = ?, attempts = attempts + 1, last_error = ?, next_attempt_at = ?,
+		updated_at = ?
+		WHERE subscription_id = ? AND event_id = ?
+	`
+	_, err := r.db.Exec(r.db.Rebind(query), status, cause.Error(), r.db.Dialect().FormatTime(nextAttempt),
+		r.db.Dialect().FormatTime(time.Now()), subscriptionID, eventID)
+	if err != nil {
+		r.logger.Error().Err(err).Str("subscription_id", subscriptionID).Msg("Failed to record webhook failure")
+	}
+	return err
+}
+
+func (r *WebhookSubscriptionRepository) queryDeliveries(
+	query string,
+	args ...interface{},
+) ([]models.WebhookDelivery, error) {
+	rows, err := r.db.Reader().Query(r.db.Rebind(query), args...)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to list webhook deliveries")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []models.WebhookDelivery
+	for rows.Next() {
+		var delivery models.WebhookDelivery
+		var createdAt string
+		err := rows.Scan(&delivery.SubscriptionID, &delivery.URL, &delivery.Secret, &delivery.Status,
+			&delivery.Attempts, &delivery.LastError, &delivery.Event.ID, &delivery.Event.Type,
+			&delivery.Event.SourceID, &delivery.Event.DocumentID, &delivery.Event.JobID, &delivery.Event.Actor,
+			&delivery.Event.Detail, &createdAt)
+		if err != nil {
+			r.logger.Error().Err(err).Msg("Failed to scan webhook delivery")
+			return nil, err
+		}
+		if delivery.Event.CreatedAt, err = dialect.ParseTime(createdAt); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, rows.Err()
+}
+
+// joinEventTypes stores eventTypes as a comma-separated list; empty means every type.
+func joinEventTypes(eventTypes []models.EventType) string {
+	names := make([]string, len(eventTypes))
+	for i, eventType := range eventTypes {
+		names[i] = string(eventType)
+	}
+	return strings.Join(names, ",")
+}
+
+func splitEventTypes(s string) []models.EventType {
+	eventTypes := []models.EventType{}
+	if s == "" {
+		return eventTypes
+	}
+	for _, name := range strings.Split(s, ",") {
+		eventTypes = append(eventTypes, models.EventType(name))
+	}
+	return eventTypes
+}
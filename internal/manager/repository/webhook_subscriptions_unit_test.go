@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/code-sleuth/ike-go/internal/manager/models"
+	"github.com/code-sleuth/ike-go/pkg/db"
+)
+
+// Test NewWebhookSubscriptionRepository constructor
+func TestNewWebhookSubscriptionRepository_Unit(t *testing.T) {
+	dbWrapper := &db.DB{}
+	repo := NewWebhookSubscriptionRepository(dbWrapper)
+
+	if repo == nil {
+		t.Fatal("Expected non-nil repository")
+	}
+	if repo.db != dbWrapper {
+		t.Error("Expected database to be set correctly")
+	}
+}
+
+func TestEventTypes_RoundTrip(t *testing.T) {
+	eventTypes := []models.EventType{models.EventSourceImported, models.EventJobFailed}
+	stored := joinEventTypes(eventTypes)
+	if stored != "source_imported,job_failed" {
+		t.Errorf("Expected a comma-separated list, got %q", stored)
+	}
+	if got := splitEventTypes(stored); !slices.Equal(got, eventTypes) {
+		t.Errorf("Expected %v, got %v", eventTypes, got)
+	}
+
+	// Every type is stored as an empty list, which reads back as empty rather than nil
+	if stored := joinEventTypes(nil); stored != "" {
+		t.Errorf("Expected an empty list, got %q", stored)
+	}
+	if got := splitEventTypes(""); got == nil || len(got) != 0 {
+		t.Errorf("Expected an empty, non-nil list, got %#v", got)
+	}
+}
@@ -0,0 +1,81 @@
+package rerankers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+var timeout = 30 * time.Second
+
+// rankedResult is a document position and its relevance score as returned by a rerank API.
+type rankedResult struct {
+	Index int     `json:"index"`
+	Score float64 `json:"relevance_score"`
+}
+
+// postJSON sends request as JSON to url and decodes the JSON response into response.
+func postJSON(
+	ctx context.Context,
+	client *http.Client,
+	url, apiKey string,
+	request, response interface{},
+	logger zerolog.Logger,
+) error {
+	requestBody, err := json.Marshal(request)
+	if err != nil {
+		logger.Err(err).Msg("failed to marshal request")
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(requestBody))
+	if err != nil {
+		logger.Err(err).Msg("failed to create request")
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if apiKey != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", apiKey))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		logger.Err(err).Msg("failed to make request")
+		return err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			logger.Error().Err(err).Msg("Failed to close response body")
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		logger.Error().Int("status_code", resp.StatusCode).Msg("API request failed")
+		return fmt.Errorf("%w: status %d", ErrAPIRequestFailed, resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(response); err != nil {
+		logger.Err(err).Msg("failed to decode response")
+		return err
+	}
+	return nil
+}
+
+// scoresByIndex converts ranked results into one score per input document, in input order.
+// Documents the API did not return keep a score of 0.
+func scoresByIndex(results []rankedResult, count int) ([]float64, error) {
+	scores := make([]float64, count)
+	for _, result := range results {
+		if result.Index < 0 || result.Index >= count {
+			return nil, fmt.Errorf("%w: %d", ErrInvalidResultIndex, result.Index)
+		}
+		scores[result.Index] = result.Score
+	}
+	return scores, nil
+}
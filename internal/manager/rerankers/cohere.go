@@ -0,0 +1,99 @@
+package rerankers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/code-sleuth/ike-go/pkg/httpclient"
+	"github.com/code-sleuth/ike-go/pkg/util"
+
+	"github.com/rs/zerolog"
+)
+
+// CohereReranker implements reranking using Cohere's Rerank API.
+type CohereReranker struct {
+	apiKey     string
+	model      string
+	httpClient *http.Client
+	apiURL     string
+	logger     zerolog.Logger
+}
+
+// CohereRerankRequest represents the request structure for the Cohere rerank API.
+type CohereRerankRequest struct {
+	Model     string   `json:"model"`
+	Query     string   `json:"query"`
+	Documents []string `json:"documents"`
+	TopN      int      `json:"top_n"`
+}
+
+// CohereRerankResponse represents the response structure from the Cohere rerank API.
+type CohereRerankResponse struct {
+	Results []rankedResult `json:"results"`
+}
+
+// NewCohereReranker creates a new Cohere reranker for model, configured by opts.
+func NewCohereReranker(model string, opts ...Option) (*CohereReranker, error) {
+	o := newOptions(opts)
+	logger := util.ComponentLogger("rerankers.cohere", zerolog.ErrorLevel)
+	if o.logger != nil {
+		logger = *o.logger
+	}
+	apiKey := o.apiKey
+	if apiKey == "" {
+		logger.Error().Msg("Cohere API key not set")
+		return nil, ErrAPIKeyNotSet
+	}
+
+	switch model {
+	case "rerank-v3.5", "rerank-english-v3.0", "rerank-multilingual-v3.0":
+	default:
+		logger.Error().Str("unsupported model", model).Err(ErrUnsupportedModel)
+		return nil, ErrUnsupportedModel
+	}
+
+	httpClient := o.httpClient
+	if httpClient == nil {
+		httpClient = httpclient.New(timeout)
+	}
+
+	apiURL := o.apiURL
+	if apiURL == "" {
+		apiURL = "https://api.cohere.com/v2/rerank"
+	}
+
+	return &CohereReranker{
+		apiKey:     apiKey,
+		model:      model,
+		httpClient: httpClient,
+		apiURL:     apiURL,
+		logger:     logger,
+	}, nil
+}
+
+// Rerank scores each document's relevance to the query.
+func (c *CohereReranker) Rerank(ctx context.Context, query string, documents []string) ([]float64, error) {
+	if len(documents) == 0 {
+		return nil, nil
+	}
+
+	request := CohereRerankRequest{
+		Model:     c.model,
+		Query:     query,
+		Documents: documents,
+		TopN:      len(documents),
+	}
+
+	var response CohereRerankResponse
+	if err := postJSON(ctx, c.httpClient, c.apiURL, c.apiKey, request, &response, c.logger); err != nil {
+		return nil, err
+	}
+
+	c.logger.Debug().Str("model", c.model).Int("documents", len(documents)).Msg("Reranked documents")
+	return scoresByIndex(response.Results, len(documents))
+}
+
+// GetModelName returns the name of the reranking model.
+func (c *CohereReranker) GetModelName() string {
+	return c.model
+}
@@ -0,0 +1,96 @@
+package rerankers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/code-sleuth/ike-go/pkg/httpclient"
+	"github.com/code-sleuth/ike-go/pkg/util"
+
+	"github.com/rs/zerolog"
+)
+
+// CrossEncoderReranker implements reranking with a locally hosted cross-encoder served by
+// Hugging Face text-embeddings-inference (or any server exposing its /rerank endpoint).
+type CrossEncoderReranker struct {
+	apiKey     string
+	model      string
+	httpClient *http.Client
+	apiURL     string
+	logger     zerolog.Logger
+}
+
+// CrossEncoderRerankRequest represents the request structure for the /rerank endpoint.
+type CrossEncoderRerankRequest struct {
+	Query string   `json:"query"`
+	Texts []string `json:"texts"`
+}
+
+// CrossEncoderRerankResult represents one scored text from the /rerank endpoint.
+type CrossEncoderRerankResult struct {
+	Index int     `json:"index"`
+	Score float64 `json:"score"`
+}
+
+// NewCrossEncoderReranker creates a cross-encoder reranker for the server WithBaseURL or WithAPIURL
+// gives. model only names the served model; the server decides which model runs.
+func NewCrossEncoderReranker(model string, opts ...Option) (*CrossEncoderReranker, error) {
+	o := newOptions(opts)
+	logger := util.ComponentLogger("rerankers.crossencoder", zerolog.ErrorLevel)
+	if o.logger != nil {
+		logger = *o.logger
+	}
+
+	apiURL := o.apiURL
+	if apiURL == "" {
+		if o.baseURL == "" {
+			logger.Error().Msg("Reranker URL not set")
+			return nil, ErrURLNotSet
+		}
+		apiURL = strings.TrimSuffix(o.baseURL, "/") + "/rerank"
+	}
+
+	httpClient := o.httpClient
+	if httpClient == nil {
+		httpClient = httpclient.New(timeout)
+	}
+
+	return &CrossEncoderReranker{
+		apiKey:     o.apiKey,
+		model:      model,
+		httpClient: httpClient,
+		apiURL:     apiURL,
+		logger:     logger,
+	}, nil
+}
+
+// Rerank scores each document's relevance to the query.
+func (c *CrossEncoderReranker) Rerank(ctx context.Context, query string, documents []string) ([]float64, error) {
+	if len(documents) == 0 {
+		return nil, nil
+	}
+
+	request := CrossEncoderRerankRequest{
+		Query: query,
+		Texts: documents,
+	}
+
+	var response []CrossEncoderRerankResult
+	if err := postJSON(ctx, c.httpClient, c.apiURL, c.apiKey, request, &response, c.logger); err != nil {
+		return nil, err
+	}
+
+	results := make([]rankedResult, len(response))
+	for i, result := range response {
+		results[i] = rankedResult{Index: result.Index, Score: result.Score}
+	}
+
+	c.logger.Debug().Str("model", c.model).Int("documents", len(documents)).Msg("Reranked documents")
+	return scoresByIndex(results, len(documents))
+}
+
+// GetModelName returns the name of the reranking model.
+func (c *CrossEncoderReranker) GetModelName() string {
+	return c.model
+}
@@ -0,0 +1,11 @@
+package rerankers
+
+import "errors"
+
+var (
+	ErrAPIKeyNotSet       = errors.New("API key not set")
+	ErrURLNotSet          = errors.New("reranker URL not set")
+	ErrUnsupportedModel   = errors.New("unsupported model")
+	ErrAPIRequestFailed   = errors.New("API request failed")
+	ErrInvalidResultIndex = errors.New("reranker returned an invalid result index")
+)
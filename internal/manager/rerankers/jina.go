@@ -0,0 +1,99 @@
+package rerankers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/code-sleuth/ike-go/pkg/httpclient"
+	"github.com/code-sleuth/ike-go/pkg/util"
+
+	"github.com/rs/zerolog"
+)
+
+// JinaReranker implements reranking using Jina AI's reranker API.
+type JinaReranker struct {
+	apiKey     string
+	model      string
+	httpClient *http.Client
+	apiURL     string
+	logger     zerolog.Logger
+}
+
+// JinaRerankRequest represents the request structure for the Jina rerank API.
+type JinaRerankRequest struct {
+	Model     string   `json:"model"`
+	Query     string   `json:"query"`
+	Documents []string `json:"documents"`
+	TopN      int      `json:"top_n"`
+}
+
+// JinaRerankResponse represents the response structure from the Jina rerank API.
+type JinaRerankResponse struct {
+	Results []rankedResult `json:"results"`
+}
+
+// NewJinaReranker creates a new Jina reranker for model, configured by opts.
+func NewJinaReranker(model string, opts ...Option) (*JinaReranker, error) {
+	o := newOptions(opts)
+	logger := util.ComponentLogger("rerankers.jina", zerolog.ErrorLevel)
+	if o.logger != nil {
+		logger = *o.logger
+	}
+	apiKey := o.apiKey
+	if apiKey == "" {
+		logger.Error().Msg("Jina API key not set")
+		return nil, ErrAPIKeyNotSet
+	}
+
+	switch model {
+	case "jina-reranker-v2-base-multilingual", "jina-reranker-m0", "jina-colbert-v2":
+	default:
+		logger.Error().Str("unsupported model", model).Err(ErrUnsupportedModel)
+		return nil, ErrUnsupportedModel
+	}
+
+	httpClient := o.httpClient
+	if httpClient == nil {
+		httpClient = httpclient.New(timeout)
+	}
+
+	apiURL := o.apiURL
+	if apiURL == "" {
+		apiURL = "https://api.jina.ai/v1/rerank"
+	}
+
+	return &JinaReranker{
+		apiKey:     apiKey,
+		model:      model,
+		httpClient: httpClient,
+		apiURL:     apiURL,
+		logger:     logger,
+	}, nil
+}
+
+// Rerank scores each document's relevance to the query.
+func (j *JinaReranker) Rerank(ctx context.Context, query string, documents []string) ([]float64, error) {
+	if len(documents) == 0 {
+		return nil, nil
+	}
+
+	request := JinaRerankRequest{
+		Model:     j.model,
+		Query:     query,
+		Documents: documents,
+		TopN:      len(documents),
+	}
+
+	var response JinaRerankResponse
+	if err := postJSON(ctx, j.httpClient, j.apiURL, j.apiKey, request, &response, j.logger); err != nil {
+		return nil, err
+	}
+
+	j.logger.Debug().Str("model", j.model).Int("documents", len(documents)).Msg("Reranked documents")
+	return scoresByIndex(response.Results, len(documents))
+}
+
+// GetModelName returns the name of the reranking model.
+func (j *JinaReranker) GetModelName() string {
+	return j.model
+}
@@ -0,0 +1,67 @@
+package rerankers
+
+import (
+	"net/http"
+
+	"github.com/rs/zerolog"
+)
+
+// Option configures a reranker when it is created, as in
+// NewCohereReranker(model, WithAPIKey(key)).
+type Option func(*options)
+
+// options are the settings Options give; unset ones leave the reranker's default.
+type options struct {
+	httpClient *http.Client
+	apiURL     string
+	apiKey     string
+	baseURL    string
+	logger     *zerolog.Logger
+}
+
+// newOptions returns the settings given by opts.
+func newOptions(opts []Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithHTTPClient sends the reranker's requests through client, such as one for a test server.
+func WithHTTPClient(client *http.Client) Option {
+	return func(o *options) {
+		o.httpClient = client
+	}
+}
+
+// WithAPIURL sets the endpoint the reranker calls, such as a proxy's.
+func WithAPIURL(apiURL string) Option {
+	return func(o *options) {
+		o.apiURL = apiURL
+	}
+}
+
+// WithAPIKey sets the API key. Cohere and Jina rerankers can't be created without one; the
+// cross-encoder server may not need one.
+func WithAPIKey(apiKey string) Option {
+	return func(o *options) {
+		o.apiKey = apiKey
+	}
+}
+
+// WithBaseURL sets the address of the cross-encoder server; without it or WithAPIURL, creating
+// the cross-encoder reranker fails with ErrURLNotSet.
+func WithBaseURL(baseURL string) Option {
+	return func(o *options) {
+		o.baseURL = baseURL
+	}
+}
+
+// WithLogger sends the reranker's logs to logger, such as one from util.NewSlogLogger, in place of
+// the component logger it would create.
+func WithLogger(logger zerolog.Logger) Option {
+	return func(o *options) {
+		o.logger = &logger
+	}
+}
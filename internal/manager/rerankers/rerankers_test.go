@@ -0,0 +1,144 @@
+package rerankers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestNewCohereReranker(t *testing.T) {
+	tests := []struct {
+		name        string
+		model       string
+		apiKey      string
+		expectedErr error
+		description string
+	}{
+		{
+			name:        "valid model",
+			model:       "rerank-v3.5",
+			apiKey:      "test-api-key",
+			description: "should create reranker for a supported model",
+		},
+		{
+			name:        "unsupported model",
+			model:       "unsupported-model",
+			apiKey:      "test-api-key",
+			expectedErr: ErrUnsupportedModel,
+			description: "should reject unsupported models",
+		},
+		{
+			name:        "missing api key",
+			model:       "rerank-v3.5",
+			expectedErr: ErrAPIKeyNotSet,
+			description: "should require an API key",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reranker, err := NewCohereReranker(tt.model, WithAPIKey(tt.apiKey))
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("Expected error %v, got %v (%s)", tt.expectedErr, err, tt.description)
+			}
+			if err == nil && reranker.GetModelName() != tt.model {
+				t.Errorf("Expected model %s, got %s", tt.model, reranker.GetModelName())
+			}
+		})
+	}
+}
+
+func TestCohereReranker_Rerank(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer test-api-key" {
+			t.Errorf("Expected bearer token, got %q", r.Header.Get("Authorization"))
+		}
+		var request CohereRerankRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("Failed to decode request: %v", err)
+		}
+		if request.TopN != len(request.Documents) {
+			t.Errorf("Expected top_n %d, got %d", len(request.Documents), request.TopN)
+		}
+		_ = json.NewEncoder(w).Encode(CohereRerankResponse{Results: []rankedResult{
+			{Index: 1, Score: 0.9},
+			{Index: 0, Score: 0.2},
+		}})
+	}))
+	defer server.Close()
+
+	reranker, err := NewCohereReranker("rerank-v3.5", WithHTTPClient(server.Client()), WithAPIURL(server.URL),
+		WithAPIKey("test-api-key"))
+	if err != nil {
+		t.Fatalf("Failed to create reranker: %v", err)
+	}
+
+	scores, err := reranker.Rerank(context.Background(), "query", []string{"first", "second"})
+	if err != nil {
+		t.Fatalf("Failed to rerank: %v", err)
+	}
+	if !reflect.DeepEqual(scores, []float64{0.2, 0.9}) {
+		t.Errorf("Expected scores in input order, got %v", scores)
+	}
+}
+
+func TestJinaReranker_Rerank_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	reranker, err := NewJinaReranker("jina-reranker-v2-base-multilingual", WithHTTPClient(server.Client()),
+		WithAPIURL(server.URL), WithAPIKey("test-api-key"))
+	if err != nil {
+		t.Fatalf("Failed to create reranker: %v", err)
+	}
+
+	if _, err := reranker.Rerank(context.Background(), "query", []string{"doc"}); !errors.Is(err, ErrAPIRequestFailed) {
+		t.Errorf("Expected %v, got %v", ErrAPIRequestFailed, err)
+	}
+}
+
+func TestCrossEncoderReranker_Rerank(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rerank" {
+			t.Errorf("Expected /rerank, got %s", r.URL.Path)
+		}
+		_ = json.NewEncoder(w).Encode([]CrossEncoderRerankResult{
+			{Index: 0, Score: 0.4},
+			{Index: 2, Score: 0.8},
+		})
+	}))
+	defer server.Close()
+
+	reranker, err := NewCrossEncoderReranker("bge-reranker-base", WithBaseURL(server.URL+"/"))
+	if err != nil {
+		t.Fatalf("Failed to create reranker: %v", err)
+	}
+
+	scores, err := reranker.Rerank(context.Background(), "query", []string{"a", "b", "c"})
+	if err != nil {
+		t.Fatalf("Failed to rerank: %v", err)
+	}
+	if !reflect.DeepEqual(scores, []float64{0.4, 0, 0.8}) {
+		t.Errorf("Expected [0.4 0 0.8], got %v", scores)
+	}
+}
+
+func TestNewCrossEncoderReranker_MissingURL(t *testing.T) {
+	t.Setenv("RERANKER_URL", "http://localhost:8080")
+
+	if _, err := NewCrossEncoderReranker("bge-reranker-base"); !errors.Is(err, ErrURLNotSet) {
+		t.Errorf("Expected %v, got %v", ErrURLNotSet, err)
+	}
+}
+
+func TestScoresByIndex_InvalidIndex(t *testing.T) {
+	if _, err := scoresByIndex([]rankedResult{{Index: 3}}, 2); !errors.Is(err, ErrInvalidResultIndex) {
+		t.Errorf("Expected %v, got %v", ErrInvalidResultIndex, err)
+	}
+}
@@ -0,0 +1,112 @@
+package scheduler
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseCron_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+	}{
+		{name: "empty", expr: ""},
+		{name: "too few fields", expr: "* * * *"},
+		{name: "too many fields", expr: "* * * * * *"},
+		{name: "minute out of range", expr: "60 * * * *"},
+		{name: "zero day of month", expr: "0 0 0 * *"},
+		{name: "reversed range", expr: "0 10-2 * * *"},
+		{name: "zero step", expr: "*/0 * * * *"},
+		{name: "not a number", expr: "a * * * *"},
+		{name: "unknown macro", expr: "@fortnightly"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseCron(tt.expr)
+			if !errors.Is(err, ErrInvalidCron) {
+				t.Errorf("Expected ErrInvalidCron for %q, got %v", tt.expr, err)
+			}
+		})
+	}
+}
+
+func TestCron_Next(t *testing.T) {
+	// Wednesday
+	from := time.Date(2025, time.January, 15, 10, 7, 30, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		expr     string
+		expected time.Time
+	}{
+		{
+			name:     "every minute",
+			expr:     "* * * * *",
+			expected: time.Date(2025, time.January, 15, 10, 8, 0, 0, time.UTC),
+		},
+		{
+			name:     "step",
+			expr:     "*/15 * * * *",
+			expected: time.Date(2025, time.January, 15, 10, 15, 0, 0, time.UTC),
+		},
+		{
+			name:     "list",
+			expr:     "5,50 * * * *",
+			expected: time.Date(2025, time.January, 15, 10, 50, 0, 0, time.UTC),
+		},
+		{
+			name:     "daily at 3am rolls to tomorrow",
+			expr:     "0 3 * * *",
+			expected: time.Date(2025, time.January, 16, 3, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "weekdays range",
+			expr:     "30 9 * * 1-5",
+			expected: time.Date(2025, time.January, 16, 9, 30, 0, 0, time.UTC),
+		},
+		{
+			name:     "sunday as 7",
+			expr:     "0 0 * * 7",
+			expected: time.Date(2025, time.January, 19, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "monthly macro",
+			expr:     "@monthly",
+			expected: time.Date(2025, time.February, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "hourly macro",
+			expr:     "@hourly",
+			expected: time.Date(2025, time.January, 15, 11, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "day of month or day of week",
+			expr:     "0 0 20 * 5",
+			expected: time.Date(2025, time.January, 17, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "leap day",
+			expr:     "0 0 29 2 *",
+			expected: time.Date(2028, time.February, 29, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "never matches",
+			expr:     "0 0 30 2 *",
+			expected: time.Time{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cron, err := ParseCron(tt.expr)
+			if err != nil {
+				t.Fatalf("Expected no error parsing %q, got %v", tt.expr, err)
+			}
+			if got := cron.Next(from); !got.Equal(tt.expected) {
+				t.Errorf("Expected next run %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
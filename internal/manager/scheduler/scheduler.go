@@ -0,0 +1,164 @@
+// Package scheduler re-imports sources on cron schedules so corpora stay fresh while the daemon
+// runs. Due schedules are turned into import jobs on the job queue, where workers pick them up.
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
+	"github.com/code-sleuth/ike-go/internal/manager/models"
+	"github.com/code-sleuth/ike-go/internal/manager/services"
+	"github.com/code-sleuth/ike-go/pkg/util"
+
+	"github.com/rs/zerolog"
+)
+
+// DefaultInterval is how often Run checks for due schedules. Cron resolution is one minute.
+const DefaultInterval = time.Minute
+
+var ErrInvalidSchedule = errors.New("invalid schedule")
+
+// Store holds the schedules; repository.ScheduleRepository implements it.
+type Store interface {
+	Due(now time.Time) ([]models.Schedule, error)
+	MarkRun(id string, ranAt time.Time, next *time.Time, runErr error) error
+}
+
+// Queue receives the import jobs of due schedules; repository.JobRepository implements it.
+type Queue interface {
+	Enqueue(job *models.Job) error
+}
+
+// Scheduler enqueues an import job for every schedule that comes due.
+type Scheduler struct {
+	store  Store
+	queue  Queue
+	now    func() time.Time
+	logger zerolog.Logger
+}
+
+func New(store Store, queue Queue) *Scheduler {
+	return &Scheduler{
+		store:  store,
+		queue:  queue,
+		now:    time.Now,
+		logger: util.NewLogger(zerolog.ErrorLevel),
+	}
+}
+
+// NewSchedule validates expr and returns an enabled schedule for sourceURL, first due at the
+// next time expr matches after now. Its imports are enqueued at models.JobPriorityLow so they
+// give way to interactive imports.
+func NewSchedule(
+	sourceURL, expr string,
+	options *interfaces.ProcessingOptions,
+	now time.Time,
+) (*models.Schedule, error) {
+	if sourceURL == "" || options == nil {
+		return nil, ErrInvalidSchedule
+	}
+	cron, err := ParseCron(expr)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(options)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.Schedule{
+		SourceURL: sourceURL,
+		Cron:      expr,
+		Options:   string(data),
+		Enabled:   true,
+		Priority:  models.JobPriorityLow,
+		NextRunAt: nextRun(cron, now),
+	}, nil
+}
+
+// Tick enqueues the schedules due now and advances each to its next run. Runs missed while the
+// daemon was down are coalesced into one. It returns how many jobs were enqueued.
+func (s *Scheduler) Tick(ctx context.Context) (int, error) {
+	now := s.now()
+	due, err := s.store.Due(now)
+	if err != nil {
+		return 0, err
+	}
+
+	var enqueued int
+	for i := range due {
+		if err := ctx.Err(); err != nil {
+			return enqueued, err
+		}
+
+		schedule := &due[i]
+		next, runErr := s.enqueue(schedule, now)
+		if runErr != nil {
+			s.logger.Error().Err(runErr).Str("schedule_id", schedule.ID).Str("source_url", schedule.SourceURL).
+				Msg("Failed to enqueue scheduled import")
+		} else {
+			enqueued++
+		}
+
+		if err := s.store.MarkRun(schedule.ID, now, next, runErr); err != nil {
+			return enqueued, err
+		}
+	}
+
+	return enqueued, nil
+}
+
+// enqueue adds the schedule's import job and returns when it is next due. A schedule whose
+// expression no longer parses is given no next run so it stops firing.
+func (s *Scheduler) enqueue(schedule *models.Schedule, now time.Time) (*time.Time, error) {
+	cron, err := ParseCron(schedule.Cron)
+	if err != nil {
+		return nil, err
+	}
+	next := nextRun(cron, now)
+
+	var options interfaces.ProcessingOptions
+	if err := json.Unmarshal([]byte(schedule.Options), &options); err != nil {
+		return next, errors.Join(ErrInvalidSchedule, err)
+	}
+	// A scheduled run is a fresh import, never a resume of the previous one
+	options.Restart = true
+
+	job, err := services.NewImportJob(schedule.SourceURL, &options)
+	if err != nil {
+		return next, err
+	}
+	job.Priority = schedule.Priority
+	return next, s.queue.Enqueue(job)
+}
+
+// Run calls Tick every interval until ctx is cancelled.
+func (s *Scheduler) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if enqueued, err := s.Tick(ctx); err != nil && ctx.Err() == nil {
+			return err
+		} else if enqueued > 0 {
+			s.logger.Info().Int("job_count", enqueued).Msg("Enqueued scheduled imports")
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func nextRun(cron *Cron, after time.Time) *time.Time {
+	next := cron.Next(after)
+	if next.IsZero() {
+		return nil
+	}
+	return &next
+}
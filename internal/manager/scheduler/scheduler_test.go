@@ -0,0 +1,120 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
+	"github.com/code-sleuth/ike-go/internal/manager/models"
+	"github.com/code-sleuth/ike-go/internal/manager/services"
+)
+
+type markedRun struct {
+	id     string
+	next   *time.Time
+	runErr error
+}
+
+type fakeStore struct {
+	due  []models.Schedule
+	runs []markedRun
+}
+
+func (s *fakeStore) Due(time.Time) ([]models.Schedule, error) {
+	return s.due, nil
+}
+
+func (s *fakeStore) MarkRun(id string, _ time.Time, next *time.Time, runErr error) error {
+	s.runs = append(s.runs, markedRun{id: id, next: next, runErr: runErr})
+	return nil
+}
+
+type fakeQueue struct {
+	jobs []*models.Job
+}
+
+func (q *fakeQueue) Enqueue(job *models.Job) error {
+	q.jobs = append(q.jobs, job)
+	return nil
+}
+
+func TestNewSchedule(t *testing.T) {
+	now := time.Date(2025, time.January, 15, 10, 7, 0, 0, time.UTC)
+	options := &interfaces.ProcessingOptions{EmbeddingModel: "text-embedding-3-small", Collection: "docs"}
+
+	schedule, err := NewSchedule("https://github.com/owner/repo", "@daily", options, now)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !schedule.Enabled || schedule.Priority != models.JobPriorityLow {
+		t.Errorf("Expected an enabled low-priority schedule, got %+v", schedule)
+	}
+	expected := time.Date(2025, time.January, 16, 0, 0, 0, 0, time.UTC)
+	if schedule.NextRunAt == nil || !schedule.NextRunAt.Equal(expected) {
+		t.Errorf("Expected next run %v, got %v", expected, schedule.NextRunAt)
+	}
+
+	if _, err := NewSchedule("https://github.com/owner/repo", "bad", options, now); !errors.Is(err, ErrInvalidCron) {
+		t.Errorf("Expected ErrInvalidCron, got %v", err)
+	}
+	if _, err := NewSchedule("", "@daily", options, now); !errors.Is(err, ErrInvalidSchedule) {
+		t.Errorf("Expected ErrInvalidSchedule, got %v", err)
+	}
+}
+
+func TestScheduler_Tick(t *testing.T) {
+	now := time.Date(2025, time.January, 15, 10, 0, 0, 0, time.UTC)
+	store := &fakeStore{
+		due: []models.Schedule{
+			{
+				ID:        "s1",
+				SourceURL: "https://github.com/owner/repo",
+				Cron:      "0 * * * *",
+				Options:   `{"collection":"docs"}`,
+				Priority:  models.JobPriorityLow,
+			},
+			{ID: "s2", SourceURL: "https://example.com/wp-json/wp/v2/posts", Cron: "not cron", Options: `{}`},
+		},
+	}
+	queue := &fakeQueue{}
+
+	s := New(store, queue)
+	s.now = func() time.Time { return now }
+
+	enqueued, err := s.Tick(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if enqueued != 1 || len(queue.jobs) != 1 {
+		t.Fatalf("Expected 1 job enqueued, got %d", len(queue.jobs))
+	}
+
+	if queue.jobs[0].Priority != models.JobPriorityLow {
+		t.Errorf("Expected the schedule's priority %d, got %d", models.JobPriorityLow, queue.jobs[0].Priority)
+	}
+
+	var payload services.ImportJob
+	if err := json.Unmarshal([]byte(queue.jobs[0].Payload), &payload); err != nil {
+		t.Fatalf("Expected valid payload, got %v", err)
+	}
+	if payload.SourceURL != "https://github.com/owner/repo" {
+		t.Errorf("Expected source URL of s1, got %s", payload.SourceURL)
+	}
+	if payload.Options.Collection != "docs" || !payload.Options.Restart {
+		t.Errorf("Expected schedule options with restart, got %+v", payload.Options)
+	}
+
+	if len(store.runs) != 2 {
+		t.Fatalf("Expected both schedules marked, got %d", len(store.runs))
+	}
+	expected := time.Date(2025, time.January, 15, 11, 0, 0, 0, time.UTC)
+	if store.runs[0].next == nil || !store.runs[0].next.Equal(expected) || store.runs[0].runErr != nil {
+		t.Errorf("Expected s1 next run %v without error, got %+v", expected, store.runs[0])
+	}
+	if store.runs[1].next != nil || !errors.Is(store.runs[1].runErr, ErrInvalidCron) {
+		t.Errorf("Expected s2 to stop with ErrInvalidCron, got %+v", store.runs[1])
+	}
+}
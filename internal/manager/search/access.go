@@ -0,0 +1,32 @@
+package search
+
+import "strings"
+
+// PublicLabel is the access-control label of content every caller may search, like content
+// without labels.
+const PublicLabel = "public"
+
+// Access is who a search runs for. A search only returns documents whose source has no
+// access-control labels, is labeled PublicLabel, or has one of Labels, so the zero value
+// searches public content only.
+type Access struct {
+	Labels []string
+	// Unrestricted searches every document whatever its labels, for trusted callers such as the
+	// command line and holders of the admin token.
+	Unrestricted bool
+}
+
+// clause returns the SQL condition, prefixed with AND, restricting documents d to those a
+// caller with the access may search, along with its arguments.
+func (a Access) clause() (string, []interface{}) {
+	if a.Unrestricted {
+		return "", nil
+	}
+	args := []interface{}{PublicLabel}
+	for _, label := range a.Labels {
+		args = append(args, label)
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(args)), ", ")
+	return " AND (NOT EXISTS (SELECT 1 FROM source_acl acl WHERE acl.source_id = d.source_id)" +
+		" OR d.source_id IN (SELECT source_id FROM source_acl WHERE label IN (" + placeholders + ")))", args
+}
@@ -0,0 +1,302 @@
+package search
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/code-sleuth/ike-go/pkg/db"
+)
+
+const (
+	// DefaultTokenBudget is the context size assembled when no budget is configured.
+	DefaultTokenBudget = 4000
+	// DefaultNeighbors is the number of chunks stitched on each side of a hit.
+	DefaultNeighbors = 1
+	// maxOverlapScan bounds how far overlapping text between adjacent chunks is searched for.
+	maxOverlapScan = 2000
+)
+
+// TokenCounter counts tokens the way the consuming model will; chunkers.TokenChunker
+// satisfies it.
+type TokenCounter interface {
+	CountTokens(text string) (int, error)
+}
+
+// Citation identifies the source of one passage in an assembled context.
+type Citation struct {
+	SourceID   string   `json:"source_id"`
+	SourceURL  string   `json:"source_url"`
+	DocumentID string   `json:"document_id"`
+	ChunkIDs   []string `json:"chunk_ids"`
+}
+
+// Passage is a run of adjacent chunks from one document, stitched into a single text.
+type Passage struct {
+	Number int    `json:"number"`
+	Text   string `json:"text"`
+	Tokens int    `json:"tokens"`
+}
+
+// Context is retrieved content ready to be placed in a prompt. Text numbers each passage as
+// [n], and Citations maps those numbers back to where the passage came from.
+type Context struct {
+	Text      string           `json:"text"`
+	Passages  []Passage        `json:"passages"`
+	Citations map[int]Citation `json:"citations"`
+	Tokens    int              `json:"tokens"`
+}
+
+// contextChunk is a chunk with the links and source details needed to stitch it.
+type contextChunk struct {
+	id         string
+	documentID string
+	parentID   string
+	leftID     string
+	rightID    string
+	body       string
+	tokens     int
+	sourceID   string
+	sourceURL  string
+}
+
+// ContextBuilder assembles search results into prompt context. Each hit is widened to its
+// parent chunk and neighbouring chunks, overlapping text between adjacent chunks is removed,
+// chunks already used are skipped, and assembly stops at the token budget.
+type ContextBuilder struct {
+	load      func(ctx context.Context, id string) (*contextChunk, error)
+	counter   TokenCounter
+	budget    int
+	neighbors int
+}
+
+// NewContextBuilder creates a context builder that reads chunks from the database.
+func NewContextBuilder(database *db.DB) *ContextBuilder {
+	return &ContextBuilder{
+		load:      chunkLoader(database),
+		budget:    DefaultTokenBudget,
+		neighbors: DefaultNeighbors,
+	}
+}
+
+// SetTokenBudget sets the maximum number of tokens in the assembled context.
+func (b *ContextBuilder) SetTokenBudget(budget int) {
+	if budget > 0 {
+		b.budget = budget
+	}
+}
+
+// SetNeighbors sets how many chunks are stitched on each side of a hit; 0 disables stitching.
+func (b *ContextBuilder) SetNeighbors(neighbors int) {
+	if neighbors >= 0 {
+		b.neighbors = neighbors
+	}
+}
+
+// SetTokenCounter sets the counter used for chunks without a stored token count. Without one,
+// tokens are estimated at four characters each.
+func (b *ContextBuilder) SetTokenCounter(counter TokenCounter) {
+	b.counter = counter
+}
+
+// passage is a passage being assembled; chunks are kept in document order.
+type passage struct {
+	chunks []*contextChunk
+}
+
+// Build assembles context from results, most relevant first, until the token budget is spent.
+func (b *ContextBuilder) Build(ctx context.Context, results []Result) (*Context, error) {
+	var passages []*passage
+	used := make(map[string]bool)
+	remaining := b.budget
+
+	for _, result := range results {
+		hit, err := b.load(ctx, result.ChunkID)
+		if err != nil {
+			return nil, err
+		}
+
+		// Prefer the parent chunk, which contains the hit and its surrounding section
+		if hit.parentID != "" {
+			parent, err := b.load(ctx, hit.parentID)
+			if err != nil {
+				return nil, err
+			}
+			hit = parent
+		}
+		if used[hit.id] {
+			continue
+		}
+		if b.tokenCount(hit) > remaining {
+			continue
+		}
+
+		current := &passage{chunks: []*contextChunk{hit}}
+		used[hit.id] = true
+		remaining -= b.tokenCount(hit)
+
+		// Widen alternately left and right so the hit stays centred
+		left, right := hit, hit
+		for step := 0; step < b.neighbors; step++ {
+			if left, err = b.stitch(ctx, left, left.leftID, used, &remaining, func(c *contextChunk) {
+				current.chunks = append([]*contextChunk{c}, current.chunks...)
+			}); err != nil {
+				return nil, err
+			}
+			if right, err = b.stitch(ctx, right, right.rightID, used, &remaining, func(c *contextChunk) {
+				current.chunks = append(current.chunks, c)
+			}); err != nil {
+				return nil, err
+			}
+		}
+
+		passages = mergePassage(passages, current)
+	}
+
+	return b.assemble(passages), nil
+}
+
+// stitch adds the neighbour with the given ID when it is unused and fits the budget, returning
+// the new edge of the passage. The edge is unchanged when the neighbour is not added.
+func (b *ContextBuilder) stitch(
+	ctx context.Context,
+	edge *contextChunk,
+	neighborID string,
+	used map[string]bool,
+	remaining *int,
+	add func(*contextChunk),
+) (*contextChunk, error) {
+	if edge == nil || neighborID == "" || used[neighborID] {
+		return edge, nil
+	}
+
+	neighbor, err := b.load(ctx, neighborID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return edge, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := b.tokenCount(neighbor)
+	if tokens > *remaining {
+		return edge, nil
+	}
+	used[neighbor.id] = true
+	*remaining -= tokens
+	add(neighbor)
+	return neighbor, nil
+}
+
+// mergePassage joins current onto an existing passage it directly continues, otherwise appends
+// it as a new passage.
+func mergePassage(passages []*passage, current *passage) []*passage {
+	first, last := current.chunks[0], current.chunks[len(current.chunks)-1]
+	for _, existing := range passages {
+		existingFirst := existing.chunks[0]
+		existingLast := existing.chunks[len(existing.chunks)-1]
+		switch {
+		case existingLast.rightID == first.id:
+			existing.chunks = append(existing.chunks, current.chunks...)
+			return passages
+		case existingFirst.leftID == last.id:
+			existing.chunks = append(current.chunks, existing.chunks...)
+			return passages
+		}
+	}
+	return append(passages, current)
+}
+
+// assemble numbers the passages and renders the final context.
+func (b *ContextBuilder) assemble(passages []*passage) *Context {
+	result := &Context{
+		Passages:  make([]Passage, 0, len(passages)),
+		Citations: make(map[int]Citation, len(passages)),
+	}
+
+	sections := make([]string, 0, len(passages))
+	for i, p := range passages {
+		number := i + 1
+		text := p.chunks[0].body
+		tokens := b.tokenCount(p.chunks[0])
+		chunkIDs := []string{p.chunks[0].id}
+		for _, c := range p.chunks[1:] {
+			text = joinOverlapping(text, c.body)
+			tokens += b.tokenCount(c)
+			chunkIDs = append(chunkIDs, c.id)
+		}
+
+		result.Passages = append(result.Passages, Passage{Number: number, Text: text, Tokens: tokens})
+		result.Citations[number] = Citation{
+			SourceID:   p.chunks[0].sourceID,
+			SourceURL:  p.chunks[0].sourceURL,
+			DocumentID: p.chunks[0].documentID,
+			ChunkIDs:   chunkIDs,
+		}
+		result.Tokens += tokens
+		sections = append(sections, fmt.Sprintf("[%d] %s", number, text))
+	}
+
+	result.Text = strings.Join(sections, "\n\n")
+	return result
+}
+
+// joinOverlapping appends next to text, dropping the longest prefix of next that repeats the
+// end of text. Chunkers that overlap windows otherwise duplicate that text.
+func joinOverlapping(text, next string) string {
+	limit := min(len(text), len(next), maxOverlapScan)
+	for size := limit; size > 0; size-- {
+		if strings.HasSuffix(text, next[:size]) {
+			return text + next[size:]
+		}
+	}
+	separator := "\n"
+	if strings.HasSuffix(text, "\n") || strings.HasPrefix(next, "\n") {
+		separator = ""
+	}
+	return text + separator + next
+}
+
+// tokenCount returns the chunk's stored token count, counting or estimating it when absent.
+func (b *ContextBuilder) tokenCount(c *contextChunk) int {
+	if c.tokens > 0 {
+		return c.tokens
+	}
+	if b.counter != nil {
+		if count, err := b.counter.CountTokens(c.body); err == nil {
+			c.tokens = count
+			return count
+		}
+	}
+	c.tokens = (len(c.body) + 3) / 4
+	return c.tokens
+}
+
+// chunkLoader returns a function that loads chunks by ID, caching them for the builder's use.
+func chunkLoader(database *db.DB) func(ctx context.Context, id string) (*contextChunk, error) {
+	cache := make(map[string]*contextChunk)
+	return func(ctx context.Context, id string) (*contextChunk, error) {
+		if c, ok := cache[id]; ok {
+			return c, nil
+		}
+
+		query := `SELECT c.id, c.document_id, COALESCE(c.parent_chunk_id, ''), COALESCE(c.left_chunk_id, ''),
+			COALESCE(c.right_chunk_id, ''), COALESCE(c.body, ''), COALESCE(c.token_count, 0),
+			d.source_id, COALESCE(s.raw_url, '')
+			FROM chunks c
+			JOIN documents d ON d.id = c.document_id
+			JOIN sources s ON s.id = d.source_id
+			WHERE c.id = ? AND d.deleted_at IS NULL`
+		c := &contextChunk{}
+		err := database.Reader().QueryRowContext(ctx, database.Rebind(query), id).Scan(
+			&c.id, &c.documentID, &c.parentID, &c.leftID, &c.rightID, &c.body, &c.tokens,
+			&c.sourceID, &c.sourceURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load chunk %s: %w", id, err)
+		}
+		cache[id] = c
+		return c, nil
+	}
+}
@@ -0,0 +1,126 @@
+package search
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// newTestContextBuilder returns a builder over an in-memory chain of chunks c1 <-> c2 <-> ... <-> cn
+// in one document.
+func newTestContextBuilder(bodies ...string) *ContextBuilder {
+	chunks := make(map[string]*contextChunk, len(bodies))
+	for i, body := range bodies {
+		c := &contextChunk{
+			id:         fmt.Sprintf("c%d", i+1),
+			documentID: "doc-1",
+			body:       body,
+			tokens:     len(strings.Fields(body)),
+			sourceID:   "source-1",
+			sourceURL:  "https://example.com/post",
+		}
+		if i > 0 {
+			c.leftID = fmt.Sprintf("c%d", i)
+		}
+		if i < len(bodies)-1 {
+			c.rightID = fmt.Sprintf("c%d", i+2)
+		}
+		chunks[c.id] = c
+	}
+
+	return &ContextBuilder{
+		load: func(_ context.Context, id string) (*contextChunk, error) {
+			c, ok := chunks[id]
+			if !ok {
+				return nil, sql.ErrNoRows
+			}
+			copied := *c
+			return &copied, nil
+		},
+		budget:    DefaultTokenBudget,
+		neighbors: DefaultNeighbors,
+	}
+}
+
+func TestContextBuilder_StitchesNeighbors(t *testing.T) {
+	builder := newTestContextBuilder("one two", "three four", "five six", "seven eight")
+
+	built, err := builder.Build(context.Background(), []Result{{ChunkID: "c2"}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(built.Passages) != 1 {
+		t.Fatalf("Expected 1 passage, got %d", len(built.Passages))
+	}
+	if built.Passages[0].Text != "one two\nthree four\nfive six" {
+		t.Errorf("Expected c1-c3 stitched in order, got %q", built.Passages[0].Text)
+	}
+	if !reflect.DeepEqual(built.Citations[1].ChunkIDs, []string{"c1", "c2", "c3"}) {
+		t.Errorf("Expected citation for c1-c3, got %v", built.Citations[1].ChunkIDs)
+	}
+	if built.Citations[1].SourceURL != "https://example.com/post" {
+		t.Errorf("Expected source URL in citation, got %q", built.Citations[1].SourceURL)
+	}
+	if !strings.HasPrefix(built.Text, "[1] ") {
+		t.Errorf("Expected numbered passage, got %q", built.Text)
+	}
+}
+
+func TestContextBuilder_MergesAndDeduplicates(t *testing.T) {
+	builder := newTestContextBuilder("a a", "b b", "c c", "d d", "e e")
+	builder.SetNeighbors(0)
+
+	built, err := builder.Build(context.Background(), []Result{{ChunkID: "c2"}, {ChunkID: "c3"}, {ChunkID: "c2"}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(built.Passages) != 1 {
+		t.Fatalf("Expected adjacent hits to merge into 1 passage, got %d", len(built.Passages))
+	}
+	if built.Tokens != 4 {
+		t.Errorf("Expected duplicate hits to be counted once (4 tokens), got %d", built.Tokens)
+	}
+}
+
+func TestContextBuilder_TokenBudget(t *testing.T) {
+	builder := newTestContextBuilder("one two three", "four five six", "seven eight nine")
+	builder.SetTokenBudget(4)
+
+	built, err := builder.Build(context.Background(), []Result{{ChunkID: "c2"}, {ChunkID: "c3"}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if built.Tokens > 4 {
+		t.Errorf("Expected at most 4 tokens, got %d", built.Tokens)
+	}
+	if len(built.Passages) != 1 || built.Passages[0].Text != "four five six" {
+		t.Errorf("Expected only the top hit to fit, got %+v", built.Passages)
+	}
+}
+
+func TestJoinOverlapping(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		next     string
+		expected string
+	}{
+		{name: "overlap", text: "the quick brown fox", next: "brown fox jumps", expected: "the quick brown fox jumps"},
+		{name: "no overlap", text: "first", next: "second", expected: "first\nsecond"},
+		{name: "trailing newline", text: "first\n", next: "second", expected: "first\nsecond"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := joinOverlapping(tt.text, tt.next); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
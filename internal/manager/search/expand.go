@@ -0,0 +1,83 @@
+package search
+
+import (
+	"context"
+	"sort"
+)
+
+// expand returns the query followed by its paraphrases when opts.Expander is set.
+func (s *Searcher) expand(ctx context.Context, query string, opts Options) ([]string, error) {
+	if opts.Expander == nil {
+		return []string{query}, nil
+	}
+
+	n := opts.Expansions
+	if n <= 0 {
+		n = DefaultExpansions
+	}
+	paraphrases, err := opts.Expander.Expand(ctx, query, n)
+	if err != nil {
+		s.logger.Error().Err(err).Str("model", opts.Expander.GetModelName()).Msg("Failed to expand query")
+		return nil, err
+	}
+	s.logger.Debug().Strs("paraphrases", paraphrases).Msg("Expanded query")
+	return append([]string{query}, paraphrases...), nil
+}
+
+// keywordAll runs keyword search for every query and unions the hits.
+func (s *Searcher) keywordAll(ctx context.Context, queries []string, limit int, filters Filters) ([]Hit, error) {
+	lists := make([][]Hit, 0, len(queries))
+	for _, query := range queries {
+		hits, err := s.Keyword(ctx, query, limit, filters)
+		if err != nil {
+			return nil, err
+		}
+		lists = append(lists, hits)
+	}
+	return unionHits(limit, lists...), nil
+}
+
+// vectorAll runs vector search for every query and unions the hits.
+func (s *Searcher) vectorAll(ctx context.Context, queries []string, limit int, filters Filters) ([]Hit, error) {
+	lists := make([][]Hit, 0, len(queries))
+	for _, query := range queries {
+		hits, err := s.Vector(ctx, query, limit, filters)
+		if err != nil {
+			return nil, err
+		}
+		lists = append(lists, hits)
+	}
+	return unionHits(limit, lists...), nil
+}
+
+// unionHits merges hit lists, keeping each chunk's best score, and returns the top limit by
+// score. A single list is returned unchanged.
+func unionHits(limit int, lists ...[]Hit) []Hit {
+	if len(lists) == 1 {
+		return lists[0]
+	}
+
+	best := make(map[string]float64)
+	for _, hits := range lists {
+		for _, hit := range hits {
+			if score, ok := best[hit.ChunkID]; !ok || hit.Score > score {
+				best[hit.ChunkID] = hit.Score
+			}
+		}
+	}
+
+	union := make([]Hit, 0, len(best))
+	for chunkID, score := range best {
+		union = append(union, Hit{ChunkID: chunkID, Score: score})
+	}
+	sort.Slice(union, func(i, j int) bool {
+		if union[i].Score != union[j].Score {
+			return union[i].Score > union[j].Score
+		}
+		return union[i].ChunkID < union[j].ChunkID
+	})
+	if limit > 0 && len(union) > limit {
+		union = union[:limit]
+	}
+	return union
+}
@@ -0,0 +1,107 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrInvalidFilter = errors.New("invalid search filter")
+
+// Filters restricts a search to part of the corpus. Empty fields match everything.
+type Filters struct {
+	// Collection scopes the search to sources in one collection.
+	Collection string   `json:"collection,omitempty"`
+	Host       string   `json:"host,omitempty"`
+	SourceIDs  []string `json:"source_ids,omitempty"`
+	Format     string   `json:"format,omitempty"`
+	// Owner scopes the search to sources recorded as owned by a team or person.
+	Owner string `json:"owner,omitempty"`
+	// IncludeStale also searches documents that outlived their source's TTL.
+	IncludeStale bool `json:"include_stale,omitempty"`
+	// Access is who the search runs for. It is never read from requests: whoever runs the
+	// search sets it from the caller's credentials.
+	Access Access `json:"-"`
+}
+
+// ParseFilters parses key=value filter expressions such as host=github.com. Supported keys are
+// collection, host, source (repeatable), format, and owner.
+func ParseFilters(expressions []string) (Filters, error) {
+	var filters Filters
+	for _, expression := range expressions {
+		key, value, ok := strings.Cut(expression, "=")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if !ok || value == "" {
+			return Filters{}, fmt.Errorf("%w: %q (expected key=value)", ErrInvalidFilter, expression)
+		}
+
+		switch strings.ToLower(key) {
+		case "collection":
+			filters.Collection = value
+		case "host":
+			filters.Host = value
+		case "source":
+			filters.SourceIDs = append(filters.SourceIDs, value)
+		case "format":
+			filters.Format = value
+		case "owner":
+			filters.Owner = value
+		default:
+			return Filters{}, fmt.Errorf("%w: unknown key %q", ErrInvalidFilter, key)
+		}
+	}
+	return filters, nil
+}
+
+// clause returns SQL conditions, each prefixed with AND, restricting documents d and sources s
+// to the filters, along with their arguments.
+func (f Filters) clause() (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+
+	if f.Collection != "" {
+		conditions = append(conditions, "s.collection = ?")
+		args = append(args, f.Collection)
+	}
+	if f.Host != "" {
+		conditions = append(conditions, "s.host = ?")
+		args = append(args, f.Host)
+	}
+	if len(f.SourceIDs) > 0 {
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(f.SourceIDs)), ", ")
+		conditions = append(conditions, "d.source_id IN ("+placeholders+")")
+		for _, id := range f.SourceIDs {
+			args = append(args, id)
+		}
+	}
+	if f.Format != "" {
+		conditions = append(conditions, "d.format = ?")
+		args = append(args, f.Format)
+	}
+	if f.Owner != "" {
+		conditions = append(conditions, "d.source_id IN (SELECT source_id FROM source_owners WHERE owner = ?)")
+		args = append(args, f.Owner)
+	}
+
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return " AND " + strings.Join(conditions, " AND "), args
+}
+
+// staleClause returns the SQL condition, prefixed with AND, leaving stale documents d out of
+// results unless IncludeStale is set.
+func (f Filters) staleClause() string {
+	if f.IncludeStale {
+		return ""
+	}
+	return " AND d.stale_at IS NULL"
+}
+
+// conditions returns every SQL condition, each prefixed with AND, restricting documents d and
+// sources s to what the search may return, along with their arguments.
+func (f Filters) conditions() (string, []interface{}) {
+	filterClause, args := f.clause()
+	accessClause, accessArgs := f.Access.clause()
+	return f.staleClause() + filterClause + accessClause, append(args, accessArgs...)
+}
@@ -0,0 +1,104 @@
+package search
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// BM25 tuning parameters with their customary defaults.
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// terms splits a query into lower-cased search terms. Underscores, dots, and dashes stay part of
+// a term so code identifiers such as os.ReadFile or max_tokens are matched whole.
+func terms(query string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '.' && r != '-'
+	})
+
+	seen := make(map[string]bool, len(fields))
+	var result []string
+	for _, field := range fields {
+		field = strings.Trim(field, ".-")
+		if field == "" || seen[field] {
+			continue
+		}
+		seen[field] = true
+		result = append(result, field)
+	}
+	return result
+}
+
+// candidate is a chunk that matched at least one query term.
+type candidate struct {
+	chunkID string
+	body    string
+}
+
+// rankBM25 scores candidates against the query terms with BM25 for databases without FTS5.
+// totalChunks is the size of the whole corpus, used for inverse document frequency; statistics
+// other than that come from the candidates themselves.
+func rankBM25(queryTerms []string, candidates []candidate, totalChunks int) []Hit {
+	if len(candidates) == 0 || len(queryTerms) == 0 {
+		return nil
+	}
+
+	frequencies := make([]map[string]int, len(candidates))
+	lengths := make([]int, len(candidates))
+	documentFrequency := make(map[string]int, len(queryTerms))
+	totalLength := 0
+
+	for i, c := range candidates {
+		body := strings.ToLower(c.body)
+		lengths[i] = len(strings.Fields(body))
+		totalLength += lengths[i]
+
+		frequencies[i] = make(map[string]int, len(queryTerms))
+		for _, term := range queryTerms {
+			if count := strings.Count(body, term); count > 0 {
+				frequencies[i][term] = count
+				documentFrequency[term]++
+			}
+		}
+	}
+
+	if totalChunks < len(candidates) {
+		totalChunks = len(candidates)
+	}
+	avgLength := math.Max(float64(totalLength)/float64(len(candidates)), 1)
+
+	hits := make([]Hit, 0, len(candidates))
+	for i, c := range candidates {
+		score := 0.0
+		for term, tf := range frequencies[i] {
+			df := float64(documentFrequency[term])
+			idf := math.Log(1 + (float64(totalChunks)-df+0.5)/(df+0.5))
+			norm := float64(tf) * (bm25K1 + 1) /
+				(float64(tf) + bm25K1*(1-bm25B+bm25B*float64(lengths[i])/avgLength))
+			score += idf * norm
+		}
+		if score > 0 {
+			hits = append(hits, Hit{ChunkID: c.chunkID, Score: score})
+		}
+	}
+	return hits
+}
+
+// escapeLike escapes LIKE wildcards so terms are matched literally with ESCAPE '\'.
+func escapeLike(term string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(term)
+}
+
+// matchExpression builds an FTS5 query matching any of the terms. Each term is quoted so FTS5
+// syntax characters are taken literally; terms containing separators such as os.ReadFile become
+// phrase queries.
+func matchExpression(queryTerms []string) string {
+	quoted := make([]string, len(queryTerms))
+	for i, term := range queryTerms {
+		quoted[i] = `"` + strings.ReplaceAll(term, `"`, `""`) + `"`
+	}
+	return strings.Join(quoted, " OR ")
+}
@@ -0,0 +1,133 @@
+package search
+
+import (
+	"context"
+	"strings"
+)
+
+// Similarity returns how alike two results are, from 0 (unrelated) to 1 (identical).
+type Similarity func(a, b Result) float64
+
+// MMR re-ranks results with maximal marginal relevance and returns at most limit of them. Each
+// step picks the result maximising (1-diversity)*relevance - diversity*similarity, where relevance
+// is the result's score scaled to [0, 1] and similarity is its highest similarity to a result
+// already picked. A diversity of 0 keeps the original order.
+func MMR(results []Result, diversity float64, limit int, similarity Similarity) []Result {
+	diversity = clamp(diversity)
+	if limit <= 0 || limit > len(results) {
+		limit = len(results)
+	}
+	if len(results) == 0 {
+		return results
+	}
+
+	maxScore := 0.0
+	for _, result := range results {
+		if result.Score > maxScore {
+			maxScore = result.Score
+		}
+	}
+
+	remaining := append([]Result(nil), results...)
+	selected := make([]Result, 0, limit)
+	for len(selected) < limit {
+		best, bestValue := 0, 0.0
+		for i, candidate := range remaining {
+			relevance := 0.0
+			if maxScore > 0 {
+				relevance = candidate.Score / maxScore
+			}
+			redundancy := 0.0
+			for _, picked := range selected {
+				if sim := similarity(candidate, picked); sim > redundancy {
+					redundancy = sim
+				}
+			}
+			value := (1-diversity)*relevance - diversity*redundancy
+			if i == 0 || value > bestValue {
+				best, bestValue = i, value
+			}
+		}
+		selected = append(selected, remaining[best])
+		remaining = append(remaining[:best], remaining[best+1:]...)
+	}
+	return selected
+}
+
+// textSimilarity is the Jaccard similarity of the two results' term sets. It is used when no
+// embeddings are available for the results.
+func textSimilarity(a, b Result) float64 {
+	termsA, termsB := terms(a.Body), terms(b.Body)
+	if len(termsA) == 0 || len(termsB) == 0 {
+		return 0
+	}
+
+	set := make(map[string]bool, len(termsA))
+	for _, term := range termsA {
+		set[term] = true
+	}
+	shared := 0
+	for _, term := range termsB {
+		if set[term] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(termsA)+len(termsB)-shared)
+}
+
+// vectorSimilarity compares results by the cosine similarity of their embeddings, falling back
+// to text similarity for results without one.
+func vectorSimilarity(vectors map[string][]float32) Similarity {
+	return func(a, b Result) float64 {
+		va, okA := vectors[a.ChunkID]
+		vb, okB := vectors[b.ChunkID]
+		if !okA || !okB {
+			return textSimilarity(a, b)
+		}
+		return cosine(va, vb)
+	}
+}
+
+// similarity returns the measure used to diversify results: embedding similarity when the
+// searcher has an embedder, text similarity otherwise.
+func (s *Searcher) similarity(ctx context.Context, results []Result) (Similarity, error) {
+	if s.embedder == nil {
+		return textSimilarity, nil
+	}
+
+	args := make([]interface{}, 0, len(results)+1)
+	args = append(args, s.embedder.GetModelName())
+	for _, result := range results {
+		args = append(args, result.ChunkID)
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(results)), ", ")
+	// #nosec G202 -- placeholders are constants, IDs are bound through args
+	query := `SELECT object_id, COALESCE(embedding_768, embedding_1536, embedding_3072),
+		typeof(COALESCE(embedding_768, embedding_1536, embedding_3072))
+		FROM embeddings WHERE object_type = 'chunk' AND model = ? AND object_id IN (` + placeholders + `)`
+	rows, err := s.db.Reader().QueryContext(ctx, s.db.Rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	vectors := make(map[string][]float32, len(results))
+	for rows.Next() {
+		var chunkID string
+		var raw []byte
+		var storage string
+		if err := rows.Scan(&chunkID, &raw, &storage); err != nil {
+			return nil, err
+		}
+		values, err := decodeVector(raw, storage)
+		if err != nil || len(values) == 0 {
+			continue
+		}
+		vectors[chunkID] = values
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return vectorSimilarity(vectors), nil
+}
@@ -0,0 +1,49 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/code-sleuth/ike-go/pkg/dialect"
+)
+
+// DefaultRecencyWeight is the share of the score subject to time decay when recency ranking is
+// enabled without an explicit weight.
+const DefaultRecencyWeight = 0.3
+
+// applyRecency decays each result's score by the age of its document, so that with halfLife of
+// 30 days a month-old document keeps half of the weighted share of its score. weight is the
+// share of the score subject to decay; results without a timestamp get no boost and are treated
+// as infinitely old. Results are re-sorted by the adjusted score.
+func applyRecency(results []Result, now time.Time, halfLife time.Duration, weight float64) []Result {
+	if halfLife <= 0 || len(results) == 0 {
+		return results
+	}
+	weight = clamp(weight)
+
+	adjusted := append([]Result(nil), results...)
+	for i := range adjusted {
+		freshness := 0.0
+		if updated := adjusted[i].UpdatedAt; updated != nil {
+			age := max(now.Sub(*updated), 0)
+			freshness = math.Pow(0.5, age.Hours()/halfLife.Hours())
+		}
+		adjusted[i].Score *= (1 - weight) + weight*freshness
+	}
+
+	sort.SliceStable(adjusted, func(i, j int) bool {
+		return adjusted[i].Score > adjusted[j].Score
+	})
+	return adjusted
+}
+
+// parseDocumentTime parses a stored document timestamp, returning nil when it is empty or
+// malformed.
+func parseDocumentTime(value string) *time.Time {
+	parsed, err := dialect.ParseTime(value)
+	if err != nil {
+		return nil
+	}
+	return &parsed
+}
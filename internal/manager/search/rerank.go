@@ -0,0 +1,49 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
+)
+
+// rerank re-scores the first topN results with the reranker and orders them by the new score.
+// Results beyond topN keep their order and follow the reranked ones.
+func rerank(
+	ctx context.Context,
+	reranker interfaces.Reranker,
+	query string,
+	results []Result,
+	topN int,
+) ([]Result, error) {
+	if topN <= 0 || topN > len(results) {
+		topN = len(results)
+	}
+	if topN == 0 {
+		return results, nil
+	}
+
+	documents := make([]string, topN)
+	for i := range documents {
+		documents[i] = results[i].Body
+	}
+
+	scores, err := reranker.Rerank(ctx, query, documents)
+	if err != nil {
+		return nil, err
+	}
+	if len(scores) != topN {
+		return nil, fmt.Errorf("%w: got %d scores for %d documents", ErrRerankMismatch, len(scores), topN)
+	}
+
+	reranked := append([]Result(nil), results...)
+	head := reranked[:topN]
+	for i := range head {
+		head[i].Score = scores[i]
+	}
+	sort.SliceStable(head, func(i, j int) bool {
+		return head[i].Score > head[j].Score
+	})
+	return reranked, nil
+}
@@ -0,0 +1,62 @@
+package search
+
+import "sort"
+
+// DefaultRRFK is the rank constant from the original reciprocal rank fusion paper. Larger values
+// flatten the difference between top and lower ranks.
+const DefaultRRFK = 60
+
+// Hit is a single ranked match from one retriever, best first.
+type Hit struct {
+	ChunkID string
+	Score   float64
+}
+
+// FuseRRF merges keyword and vector rankings with weighted reciprocal rank fusion. weight is the
+// share given to the vector ranking, from 0 (keyword only) to 1 (vector only); each list
+// contributes share / (k + rank) for every chunk it contains. Results are ordered by fused score.
+func FuseRRF(keyword, vector []Hit, weight float64, k int) []Result {
+	if k <= 0 {
+		k = DefaultRRFK
+	}
+	weight = clamp(weight)
+
+	results := make(map[string]*Result)
+	add := func(hits []Hit, share float64, setRank func(r *Result, rank int)) {
+		for i, hit := range hits {
+			rank := i + 1
+			result, ok := results[hit.ChunkID]
+			if !ok {
+				result = &Result{ChunkID: hit.ChunkID}
+				results[hit.ChunkID] = result
+			}
+			setRank(result, rank)
+			result.Score += share / float64(k+rank)
+		}
+	}
+	add(keyword, 1-weight, func(r *Result, rank int) { r.KeywordRank = rank })
+	add(vector, weight, func(r *Result, rank int) { r.VectorRank = rank })
+
+	fused := make([]Result, 0, len(results))
+	for _, result := range results {
+		fused = append(fused, *result)
+	}
+	sort.Slice(fused, func(i, j int) bool {
+		if fused[i].Score != fused[j].Score {
+			return fused[i].Score > fused[j].Score
+		}
+		return fused[i].ChunkID < fused[j].ChunkID
+	})
+	return fused
+}
+
+func clamp(weight float64) float64 {
+	switch {
+	case weight < 0:
+		return 0
+	case weight > 1:
+		return 1
+	default:
+		return weight
+	}
+}
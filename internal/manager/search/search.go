@@ -0,0 +1,418 @@
+package search
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
+	"github.com/code-sleuth/ike-go/pkg/db"
+	"github.com/code-sleuth/ike-go/pkg/dialect"
+	"github.com/code-sleuth/ike-go/pkg/util"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	// DefaultLimit is the number of results returned when Options.Limit is not set.
+	DefaultLimit = 10
+	// DefaultWeight balances keyword and vector rankings equally.
+	DefaultWeight = 0.5
+	// DefaultExpansions is the number of query paraphrases generated when expansion is enabled.
+	DefaultExpansions = 3
+	// candidateMultiplier controls how many results each retriever contributes before fusion.
+	candidateMultiplier = 4
+)
+
+var (
+	ErrEmptyQuery      = errors.New("search query is empty")
+	ErrNoEmbedder      = errors.New("vector search requires an embedder")
+	ErrUnsupportedMode = errors.New("unsupported search mode")
+	ErrRerankMismatch  = errors.New("reranker returned the wrong number of scores")
+)
+
+// Mode selects which retrievers a search uses.
+type Mode string
+
+const (
+	ModeHybrid  Mode = "hybrid"
+	ModeKeyword Mode = "keyword"
+	ModeVector  Mode = "vector"
+)
+
+// Options configures a search.
+type Options struct {
+	Mode  Mode
+	Limit int
+	// Weight is the share of the fused score given to vector results, from 0 (keyword only)
+	// to 1 (vector only). Only used in hybrid mode.
+	Weight float64
+	// Filters restricts results to part of the corpus.
+	Filters Filters
+	// RRFK is the reciprocal rank fusion constant; 0 uses DefaultRRFK.
+	RRFK int
+	// Diversity enables maximal marginal relevance re-ranking when greater than 0. Higher values,
+	// up to 1, trade relevance for results that differ from each other.
+	Diversity float64
+	// Reranker, when set, re-scores the top RerankTopN results before they are returned.
+	Reranker interfaces.Reranker
+	// RerankTopN is the number of results passed to the reranker; 0 reranks every candidate
+	// (Limit * 4).
+	RerankTopN int
+	// RecencyHalfLife enables time-decay ranking when set: a document this old keeps half of the
+	// decaying share of its score. Ages come from documents.modified_at, else published_at.
+	RecencyHalfLife time.Duration
+	// RecencyWeight is the share of the score subject to decay; 0 uses DefaultRecencyWeight.
+	RecencyWeight float64
+	// Expander, when set, generates paraphrases of the query whose results are unioned with the
+	// original query's before fusion. Each paraphrase costs an LLM call and an embedding.
+	Expander interfaces.QueryExpander
+	// Expansions is the number of paraphrases to generate; 0 uses DefaultExpansions.
+	Expansions int
+	// SnippetWidth is the length of highlighted snippets; 0 uses DefaultSnippetWidth.
+	SnippetWidth int
+}
+
+// Result is a chunk returned by a search. Ranks are 1-based positions in each retriever's
+// results and 0 when the retriever did not return the chunk.
+type Result struct {
+	ChunkID     string  `json:"chunk_id"`
+	DocumentID  string  `json:"document_id"`
+	SourceID    string  `json:"source_id"`
+	SourceURL   string  `json:"source_url"`
+	Body        string  `json:"body"`
+	Score       float64 `json:"score"`
+	KeywordRank int     `json:"keyword_rank,omitempty"`
+	VectorRank  int     `json:"vector_rank,omitempty"`
+	// UpdatedAt is when the document was last modified or, failing that, published.
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+	// Snippet is a highlighted excerpt explaining why the chunk matched.
+	Snippet *Highlighted `json:"snippet,omitempty"`
+	// CanonicalURL is the URL the document declares canonical; syndicated copies of a page share
+	// it, so results can be deduplicated by it.
+	CanonicalURL string `json:"canonical_url,omitempty"`
+	// License is the SPDX license expression of the document, such as one read from its repository.
+	License string `json:"license,omitempty"`
+}
+
+// Searcher retrieves chunks of live documents by keyword, by vector similarity, or both.
+type Searcher struct {
+	db       *db.DB
+	embedder interfaces.Embedder
+	logger   zerolog.Logger
+}
+
+// NewSearcher creates a searcher. embedder embeds queries for vector search and selects which
+// model's embeddings are compared; it may be nil for keyword-only search.
+func NewSearcher(database *db.DB, embedder interfaces.Embedder) *Searcher {
+	logger := util.NewLogger(zerolog.ErrorLevel)
+	return &Searcher{
+		db:       database,
+		embedder: embedder,
+		logger:   logger,
+	}
+}
+
+// Search runs the retrievers selected by opts.Mode and returns the best matching chunks.
+func (s *Searcher) Search(ctx context.Context, query string, opts Options) ([]Result, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, ErrEmptyQuery
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = DefaultLimit
+	}
+	if opts.Mode == "" {
+		opts.Mode = ModeHybrid
+	}
+
+	// Diversification and re-ranking need a wider pool of candidates to choose from
+	pool := opts.Limit
+	if opts.Diversity > 0 || opts.Reranker != nil || opts.RecencyHalfLife > 0 {
+		pool *= candidateMultiplier
+	}
+	if opts.Reranker != nil && opts.RerankTopN > pool {
+		pool = opts.RerankTopN
+	}
+
+	queries, err := s.expand(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []Result
+	switch opts.Mode {
+	case ModeKeyword:
+		hits, err := s.keywordAll(ctx, queries, pool, opts.Filters)
+		if err != nil {
+			return nil, err
+		}
+		results = FuseRRF(hits, nil, 0, opts.RRFK)
+	case ModeVector:
+		hits, err := s.vectorAll(ctx, queries, pool, opts.Filters)
+		if err != nil {
+			return nil, err
+		}
+		results = FuseRRF(nil, hits, 1, opts.RRFK)
+	case ModeHybrid:
+		candidates := max(pool, opts.Limit*candidateMultiplier)
+		keywordHits, err := s.keywordAll(ctx, queries, candidates, opts.Filters)
+		if err != nil {
+			return nil, err
+		}
+		vectorHits, err := s.vectorAll(ctx, queries, candidates, opts.Filters)
+		if err != nil {
+			return nil, err
+		}
+		results = FuseRRF(keywordHits, vectorHits, opts.Weight, opts.RRFK)
+	default:
+		return nil, ErrUnsupportedMode
+	}
+
+	if len(results) > pool {
+		results = results[:pool]
+	}
+	if err := s.hydrate(ctx, results); err != nil {
+		return nil, err
+	}
+
+	if opts.Reranker != nil {
+		results, err = rerank(ctx, opts.Reranker, query, results, opts.RerankTopN)
+		if err != nil {
+			s.logger.Error().Err(err).Str("model", opts.Reranker.GetModelName()).Msg("Failed to rerank results")
+			return nil, err
+		}
+	}
+
+	if opts.RecencyHalfLife > 0 {
+		weight := opts.RecencyWeight
+		if weight == 0 {
+			weight = DefaultRecencyWeight
+		}
+		results = applyRecency(results, time.Now().UTC(), opts.RecencyHalfLife, weight)
+	}
+
+	if opts.Diversity > 0 {
+		similarity, err := s.similarity(ctx, results)
+		if err != nil {
+			s.logger.Error().Err(err).Msg("Failed to load embeddings for diversification")
+			return nil, err
+		}
+		results = MMR(results, opts.Diversity, opts.Limit, similarity)
+	}
+
+	if len(results) > opts.Limit {
+		results = results[:opts.Limit]
+	}
+	for i := range results {
+		snippet := Highlight(results[i].Body, query, opts.SnippetWidth)
+		results[i].Snippet = &snippet
+	}
+	return results, nil
+}
+
+// Keyword returns chunks containing the query terms, ranked by BM25. On SQLite the chunks_fts
+// index is used; other databases fall back to scanning chunk bodies.
+func (s *Searcher) Keyword(ctx context.Context, query string, limit int, filters Filters) ([]Hit, error) {
+	queryTerms := terms(query)
+	if len(queryTerms) == 0 {
+		return nil, nil
+	}
+	if s.db.Dialect() == dialect.SQLite {
+		return s.keywordFTS(ctx, queryTerms, limit, filters)
+	}
+	return s.keywordScan(ctx, queryTerms, limit, filters)
+}
+
+// keywordFTS ranks chunks with the FTS5 bm25() function. bm25() returns lower values for better
+// matches, so scores are negated to keep higher-is-better ordering.
+func (s *Searcher) keywordFTS(ctx context.Context, queryTerms []string, limit int, filters Filters) ([]Hit, error) {
+	filterClause, filterArgs := filters.conditions()
+	args := append([]interface{}{matchExpression(queryTerms)}, filterArgs...)
+	args = append(args, limit)
+
+	// #nosec G202 -- the filter clause is built from constants, values are bound through args
+	query := `SELECT chunks_fts.chunk_id, -bm25(chunks_fts) FROM chunks_fts
+		JOIN chunks c ON c.id = chunks_fts.chunk_id
+		JOIN documents d ON d.id = c.document_id
+		JOIN sources s ON s.id = d.source_id
+		WHERE chunks_fts MATCH ? AND d.deleted_at IS NULL` + filterClause + `
+		ORDER BY bm25(chunks_fts), chunks_fts.chunk_id
+		LIMIT ?`
+	rows, err := s.db.Reader().QueryContext(ctx, query, args...)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to run keyword search")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hits []Hit
+	for rows.Next() {
+		var hit Hit
+		if err := rows.Scan(&hit.ChunkID, &hit.Score); err != nil {
+			return nil, err
+		}
+		hits = append(hits, hit)
+	}
+	return hits, rows.Err()
+}
+
+// keywordScan finds candidate chunks with LIKE and ranks them with BM25 computed in Go.
+func (s *Searcher) keywordScan(ctx context.Context, queryTerms []string, limit int, filters Filters) ([]Hit, error) {
+	clauses := make([]string, len(queryTerms))
+	args := make([]interface{}, len(queryTerms))
+	for i, term := range queryTerms {
+		clauses[i] = `lower(c.body) LIKE ? ESCAPE '\'`
+		args[i] = "%" + escapeLike(term) + "%"
+	}
+
+	filterClause, filterArgs := filters.conditions()
+	args = append(args, filterArgs...)
+
+	// #nosec G202 -- clauses are constants, terms are bound through args
+	query := `SELECT c.id, c.body FROM chunks c
+		JOIN documents d ON d.id = c.document_id
+		JOIN sources s ON s.id = d.source_id
+		WHERE d.deleted_at IS NULL AND (` + strings.Join(clauses, " OR ") + `)` + filterClause
+	rows, err := s.db.Reader().QueryContext(ctx, s.db.Rebind(query), args...)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to run keyword search")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var candidates []candidate
+	for rows.Next() {
+		var c candidate
+		var body sql.NullString
+		if err := rows.Scan(&c.chunkID, &body); err != nil {
+			return nil, err
+		}
+		c.body = body.String
+		candidates = append(candidates, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var totalChunks int
+	if err := s.db.Reader().QueryRowContext(ctx, `SELECT COUNT(*) FROM chunks`).Scan(&totalChunks); err != nil {
+		return nil, err
+	}
+
+	return topHits(rankBM25(queryTerms, candidates, totalChunks), limit), nil
+}
+
+// Vector embeds the query and returns the chunks whose embeddings are most similar to it by
+// cosine similarity. Only embeddings produced by the searcher's embedder model are compared.
+func (s *Searcher) Vector(ctx context.Context, query string, limit int, filters Filters) ([]Hit, error) {
+	if s.embedder == nil {
+		return nil, ErrNoEmbedder
+	}
+
+	queryVector, err := s.embedder.GenerateEmbedding(ctx, query)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to embed query")
+		return nil, err
+	}
+
+	filterClause, filterArgs := filters.conditions()
+	args := append([]interface{}{s.embedder.GetModelName()}, filterArgs...)
+
+	// #nosec G202 -- the filter clause is built from constants, values are bound through args
+	query = `SELECT e.object_id, COALESCE(e.embedding_768, e.embedding_1536, e.embedding_3072),
+		typeof(COALESCE(e.embedding_768, e.embedding_1536, e.embedding_3072))
+		FROM embeddings e
+		JOIN chunks c ON c.id = e.object_id
+		JOIN documents d ON d.id = c.document_id
+		JOIN sources s ON s.id = d.source_id
+		WHERE e.object_type = 'chunk' AND e.model = ? AND d.deleted_at IS NULL` + filterClause
+	rows, err := s.db.Reader().QueryContext(ctx, s.db.Rebind(query), args...)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to run vector search")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hits []Hit
+	for rows.Next() {
+		var chunkID string
+		var raw []byte
+		var storage sql.NullString
+		if err := rows.Scan(&chunkID, &raw, &storage); err != nil {
+			return nil, err
+		}
+		values, err := decodeVector(raw, storage.String)
+		if err != nil || len(values) == 0 {
+			s.logger.Warn().Err(err).Str("chunk_id", chunkID).Msg("Skipping undecodable embedding")
+			continue
+		}
+		hits = append(hits, Hit{ChunkID: chunkID, Score: cosine(queryVector, values)})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return topHits(hits, limit), nil
+}
+
+// hydrate fills in the document, source, and body of each result.
+func (s *Searcher) hydrate(ctx context.Context, results []Result) error {
+	if len(results) == 0 {
+		return nil
+	}
+
+	index := make(map[string]int, len(results))
+	args := make([]interface{}, len(results))
+	for i, result := range results {
+		index[result.ChunkID] = i
+		args[i] = result.ChunkID
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(results)), ", ")
+	// #nosec G202 -- placeholders are constants, IDs are bound through args
+	query := `SELECT c.id, c.document_id, c.body, d.source_id, COALESCE(s.raw_url, ''),
+		COALESCE(d.modified_at, d.published_at, ''),
+		COALESCE((SELECT meta FROM document_meta WHERE document_id = d.id AND "key" = 'canonical_url'), ''),
+		COALESCE((SELECT meta FROM document_meta WHERE document_id = d.id AND "key" = 'license'), '')
+		FROM chunks c
+		JOIN documents d ON d.id = c.document_id
+		JOIN sources s ON s.id = d.source_id
+		WHERE c.id IN (` + placeholders + `)`
+	rows, err := s.db.Reader().QueryContext(ctx, s.db.Rebind(query), args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id, documentID, sourceID, sourceURL, updatedAt, canonicalURL, license string
+		var body sql.NullString
+		if err := rows.Scan(&id, &documentID, &body, &sourceID, &sourceURL, &updatedAt, &canonicalURL,
+			&license); err != nil {
+			return err
+		}
+		if i, ok := index[id]; ok {
+			results[i].DocumentID = documentID
+			results[i].SourceID = sourceID
+			results[i].SourceURL = sourceURL
+			results[i].Body = body.String
+			results[i].UpdatedAt = parseDocumentTime(updatedAt)
+			results[i].CanonicalURL = metaString(canonicalURL)
+			results[i].License = metaString(license)
+		}
+	}
+	return rows.Err()
+}
+
+// metaString returns a string stored in document_meta, which transformers store either as JSON
+// or, as plugins do, as is.
+func metaString(meta string) string {
+	var value string
+	if err := json.Unmarshal([]byte(meta), &value); err == nil {
+		return value
+	}
+	return meta
+}
@@ -0,0 +1,635 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestFuseRRF(t *testing.T) {
+	keyword := []Hit{{ChunkID: "a"}, {ChunkID: "b"}}
+	vector := []Hit{{ChunkID: "b"}, {ChunkID: "c"}}
+
+	tests := []struct {
+		name        string
+		weight      float64
+		expected    []string
+		description string
+	}{
+		{
+			name:        "balanced",
+			weight:      0.5,
+			expected:    []string{"b", "a", "c"},
+			description: "chunks found by both retrievers should rank first",
+		},
+		{
+			name:        "keyword only",
+			weight:      0,
+			expected:    []string{"a", "b", "c"},
+			description: "weight 0 should follow the keyword ranking",
+		},
+		{
+			name:        "vector only",
+			weight:      1,
+			expected:    []string{"b", "c", "a"},
+			description: "weight 1 should follow the vector ranking",
+		},
+		{
+			name:        "out of range",
+			weight:      7,
+			expected:    []string{"b", "c", "a"},
+			description: "weights above 1 should be clamped",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := FuseRRF(keyword, vector, tt.weight, 0)
+			ids := make([]string, len(results))
+			for i, result := range results {
+				ids[i] = result.ChunkID
+			}
+			if !reflect.DeepEqual(ids, tt.expected) {
+				t.Errorf("Expected order %v, got %v (%s)", tt.expected, ids, tt.description)
+			}
+		})
+	}
+}
+
+func TestFuseRRF_Ranks(t *testing.T) {
+	results := FuseRRF([]Hit{{ChunkID: "a"}, {ChunkID: "b"}}, []Hit{{ChunkID: "b"}}, 0.5, DefaultRRFK)
+
+	for _, result := range results {
+		if result.ChunkID != "b" {
+			continue
+		}
+		if result.KeywordRank != 2 || result.VectorRank != 1 {
+			t.Errorf("Expected ranks 2 and 1, got %d and %d", result.KeywordRank, result.VectorRank)
+		}
+		expected := 0.5/float64(DefaultRRFK+2) + 0.5/float64(DefaultRRFK+1)
+		if math.Abs(result.Score-expected) > 1e-12 {
+			t.Errorf("Expected score %f, got %f", expected, result.Score)
+		}
+	}
+}
+
+func TestCosine(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     []float32
+		expected float64
+	}{
+		{name: "identical", a: []float32{1, 2, 3}, b: []float32{1, 2, 3}, expected: 1},
+		{name: "orthogonal", a: []float32{1, 0}, b: []float32{0, 1}, expected: 0},
+		{name: "opposite", a: []float32{1, 1}, b: []float32{-1, -1}, expected: -1},
+		{name: "length mismatch", a: []float32{1, 2}, b: []float32{1}, expected: 0},
+		{name: "zero vector", a: []float32{0, 0}, b: []float32{1, 1}, expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cosine(tt.a, tt.b); math.Abs(got-tt.expected) > 1e-6 {
+				t.Errorf("Expected %f, got %f", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestTopHits(t *testing.T) {
+	hits := []Hit{{ChunkID: "a", Score: 0.1}, {ChunkID: "b", Score: 0.9}, {ChunkID: "c", Score: 0.5}}
+
+	top := topHits(hits, 2)
+	if len(top) != 2 || top[0].ChunkID != "b" || top[1].ChunkID != "c" {
+		t.Errorf("Expected [b c], got %v", top)
+	}
+}
+
+func TestTerms(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		expected []string
+	}{
+		{name: "words", query: "Connection Refused", expected: []string{"connection", "refused"}},
+		{name: "identifiers", query: "os.ReadFile max_tokens", expected: []string{"os.readfile", "max_tokens"}},
+		{name: "punctuation", query: `"dial tcp: i/o timeout."`, expected: []string{"dial", "tcp", "i", "o", "timeout"}},
+		{name: "duplicates", query: "retry Retry", expected: []string{"retry"}},
+		{name: "empty", query: "  ", expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := terms(tt.query); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestRankBM25(t *testing.T) {
+	candidates := []candidate{
+		{chunkID: "short", body: "connection refused"},
+		{chunkID: "long", body: "the server logged connection refused after several unrelated retries and timeouts"},
+		{chunkID: "partial", body: "connection pooling"},
+	}
+
+	hits := topHits(rankBM25([]string{"connection", "refused"}, candidates, 100), 0)
+	if len(hits) != 3 {
+		t.Fatalf("Expected 3 hits, got %d", len(hits))
+	}
+	if hits[0].ChunkID != "short" || hits[2].ChunkID != "partial" {
+		t.Errorf("Expected short first and partial last, got %v", hits)
+	}
+}
+
+func TestEscapeLike(t *testing.T) {
+	if got := escapeLike(`100%_done\`); got != `100\%\_done\\` {
+		t.Errorf("Expected escaped wildcards, got %s", got)
+	}
+}
+
+func TestMatchExpression(t *testing.T) {
+	tests := []struct {
+		name     string
+		terms    []string
+		expected string
+	}{
+		{name: "single", terms: []string{"retry"}, expected: `"retry"`},
+		{name: "any term", terms: []string{"connection", "refused"}, expected: `"connection" OR "refused"`},
+		{name: "identifier", terms: []string{"os.readfile"}, expected: `"os.readfile"`},
+		{name: "quotes", terms: []string{`say"hi`}, expected: `"say""hi"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchExpression(tt.terms); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestMMR(t *testing.T) {
+	results := []Result{
+		{ChunkID: "a", Score: 1.0, Body: "install the cli with go install"},
+		{ChunkID: "b", Score: 0.95, Body: "install the cli with go install"},
+		{ChunkID: "c", Score: 0.6, Body: "configure retries in the config file"},
+	}
+
+	tests := []struct {
+		name        string
+		diversity   float64
+		expected    []string
+		description string
+	}{
+		{
+			name:        "relevance only",
+			diversity:   0,
+			expected:    []string{"a", "b"},
+			description: "diversity 0 should keep the original order",
+		},
+		{
+			name:        "diversified",
+			diversity:   0.5,
+			expected:    []string{"a", "c"},
+			description: "near-duplicates should give way to different results",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selected := MMR(results, tt.diversity, 2, textSimilarity)
+			ids := make([]string, len(selected))
+			for i, result := range selected {
+				ids[i] = result.ChunkID
+			}
+			if !reflect.DeepEqual(ids, tt.expected) {
+				t.Errorf("Expected %v, got %v (%s)", tt.expected, ids, tt.description)
+			}
+		})
+	}
+}
+
+func TestVectorSimilarity(t *testing.T) {
+	similarity := vectorSimilarity(map[string][]float32{
+		"a": {1, 0},
+		"b": {1, 0},
+	})
+
+	if got := similarity(Result{ChunkID: "a"}, Result{ChunkID: "b"}); math.Abs(got-1) > 1e-6 {
+		t.Errorf("Expected 1, got %f", got)
+	}
+	got := similarity(Result{ChunkID: "a", Body: "same text"}, Result{ChunkID: "c", Body: "same text"})
+	if math.Abs(got-1) > 1e-6 {
+		t.Errorf("Expected text fallback similarity 1, got %f", got)
+	}
+}
+
+type stubReranker struct {
+	scores []float64
+	err    error
+}
+
+func (r *stubReranker) Rerank(_ context.Context, _ string, documents []string) ([]float64, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	return r.scores[:min(len(r.scores), len(documents))], nil
+}
+
+func (r *stubReranker) GetModelName() string {
+	return "stub"
+}
+
+func TestRerank(t *testing.T) {
+	results := []Result{
+		{ChunkID: "a", Score: 0.3},
+		{ChunkID: "b", Score: 0.2},
+		{ChunkID: "c", Score: 0.1},
+	}
+
+	tests := []struct {
+		name        string
+		reranker    *stubReranker
+		topN        int
+		expected    []string
+		expectedErr error
+		description string
+	}{
+		{
+			name:        "all results",
+			reranker:    &stubReranker{scores: []float64{0.1, 0.9, 0.5}},
+			expected:    []string{"b", "c", "a"},
+			description: "results should be ordered by reranker score",
+		},
+		{
+			name:        "top n",
+			reranker:    &stubReranker{scores: []float64{0.1, 0.9}},
+			topN:        2,
+			expected:    []string{"b", "a", "c"},
+			description: "results beyond top n should keep their position",
+		},
+		{
+			name:        "score mismatch",
+			reranker:    &stubReranker{scores: []float64{0.1}},
+			expectedErr: ErrRerankMismatch,
+			description: "a reranker returning too few scores should fail",
+		},
+		{
+			name:        "reranker error",
+			reranker:    &stubReranker{err: errors.New("boom")},
+			description: "reranker errors should be returned",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reranked, err := rerank(context.Background(), tt.reranker, "query", results, tt.topN)
+			if tt.expected == nil {
+				if err == nil {
+					t.Fatalf("Expected error (%s)", tt.description)
+				}
+				if tt.expectedErr != nil && !errors.Is(err, tt.expectedErr) {
+					t.Errorf("Expected %v, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			ids := make([]string, len(reranked))
+			for i, result := range reranked {
+				ids[i] = result.ChunkID
+			}
+			if !reflect.DeepEqual(ids, tt.expected) {
+				t.Errorf("Expected %v, got %v (%s)", tt.expected, ids, tt.description)
+			}
+			if results[0].ChunkID != "a" {
+				t.Errorf("Expected input results to be left unchanged")
+			}
+		})
+	}
+}
+
+func TestSnippet(t *testing.T) {
+	body := strings.Repeat("lorem ipsum ", 20) + "the connection was refused by the server " + strings.Repeat("dolor sit ", 20)
+
+	tests := []struct {
+		name        string
+		body        string
+		query       string
+		width       int
+		contains    string
+		prefix      string
+		description string
+	}{
+		{
+			name:        "short body",
+			body:        "connection   refused\n",
+			query:       "refused",
+			width:       40,
+			contains:    "connection refused",
+			description: "short bodies should be returned whole with whitespace collapsed",
+		},
+		{
+			name:        "centred on match",
+			body:        body,
+			query:       "refused",
+			width:       40,
+			contains:    "refused",
+			prefix:      "…",
+			description: "the snippet should include the first matching term",
+		},
+		{
+			name:        "no match",
+			body:        body,
+			query:       "missing",
+			width:       40,
+			contains:    "lorem ipsum",
+			description: "without a match the snippet should start at the beginning",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snippet := Snippet(tt.body, tt.query, tt.width)
+			if !strings.Contains(snippet, tt.contains) {
+				t.Errorf("Expected snippet to contain %q, got %q (%s)", tt.contains, snippet, tt.description)
+			}
+			if tt.prefix != "" && !strings.HasPrefix(snippet, tt.prefix) {
+				t.Errorf("Expected snippet to start with %q, got %q", tt.prefix, snippet)
+			}
+			if n := utf8.RuneCountInString(snippet); n > tt.width+2 {
+				t.Errorf("Expected at most %d characters, got %d", tt.width+2, n)
+			}
+		})
+	}
+}
+
+func TestParseFilters(t *testing.T) {
+	filters, err := ParseFilters([]string{
+		"collection=docs", "host=github.com", "source=a", "source=b", "format=json", "owner=@org/docs",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := Filters{
+		Collection: "docs", Host: "github.com", SourceIDs: []string{"a", "b"}, Format: "json", Owner: "@org/docs",
+	}
+	if !reflect.DeepEqual(filters, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, filters)
+	}
+
+	for _, expression := range []string{"host", "host=", "colour=red"} {
+		if _, err := ParseFilters([]string{expression}); !errors.Is(err, ErrInvalidFilter) {
+			t.Errorf("Expected %v for %q, got %v", ErrInvalidFilter, expression, err)
+		}
+	}
+}
+
+func TestFilters_Clause(t *testing.T) {
+	clause, args := Filters{}.clause()
+	if clause != "" || args != nil {
+		t.Errorf("Expected no clause for empty filters, got %q %v", clause, args)
+	}
+
+	clause, args = Filters{Host: "github.com", SourceIDs: []string{"a", "b"}}.clause()
+	expected := " AND s.host = ? AND d.source_id IN (?, ?)"
+	if clause != expected {
+		t.Errorf("Expected %q, got %q", expected, clause)
+	}
+
+	clause, _ = Filters{Collection: "docs"}.clause()
+	expected = " AND s.collection = ?"
+	if clause != expected {
+		t.Errorf("Expected %q, got %q", expected, clause)
+	}
+	if !reflect.DeepEqual(args, []interface{}{"github.com", "a", "b"}) {
+		t.Errorf("Expected host and source args, got %v", args)
+	}
+
+	clause, args = Filters{Owner: "@org/docs"}.clause()
+	expected = " AND d.source_id IN (SELECT source_id FROM source_owners WHERE owner = ?)"
+	if clause != expected || !reflect.DeepEqual(args, []interface{}{"@org/docs"}) {
+		t.Errorf("Expected %q with the owner, got %q %v", expected, clause, args)
+	}
+}
+
+func TestFilters_StaleClause(t *testing.T) {
+	if clause := (Filters{}).staleClause(); clause != " AND d.stale_at IS NULL" {
+		t.Errorf("Expected stale documents to be left out by default, got %q", clause)
+	}
+	if clause := (Filters{IncludeStale: true}).staleClause(); clause != "" {
+		t.Errorf("Expected no clause when stale documents are included, got %q", clause)
+	}
+}
+
+func TestAccess_Clause(t *testing.T) {
+	if clause, args := (Access{Unrestricted: true}).clause(); clause != "" || len(args) != 0 {
+		t.Errorf("Expected no clause for unrestricted access, got %q %v", clause, args)
+	}
+
+	clause, args := (Access{}).clause()
+	if !strings.Contains(clause, "NOT EXISTS") || !strings.Contains(clause, "label IN (?)") {
+		t.Errorf("Expected unlabeled and public sources to be searched without labels, got %q", clause)
+	}
+	if !reflect.DeepEqual(args, []interface{}{PublicLabel}) {
+		t.Errorf("Expected only the public label, got %v", args)
+	}
+
+	clause, args = (Access{Labels: []string{"support", "sre"}}).clause()
+	if !strings.Contains(clause, "label IN (?, ?, ?)") {
+		t.Errorf("Expected a placeholder for public and each label, got %q", clause)
+	}
+	if !reflect.DeepEqual(args, []interface{}{PublicLabel, "support", "sre"}) {
+		t.Errorf("Expected public and the granted labels, got %v", args)
+	}
+}
+
+func TestMetaString(t *testing.T) {
+	if got := metaString(`"https://example.com/post"`); got != "https://example.com/post" {
+		t.Errorf("Expected JSON strings to be decoded, got %q", got)
+	}
+	if got := metaString("MIT"); got != "MIT" {
+		t.Errorf("Expected values stored as is to be returned unchanged, got %q", got)
+	}
+}
+
+func TestApplyRecency(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	fresh := now.Add(-24 * time.Hour)
+	stale := now.Add(-365 * 24 * time.Hour)
+
+	results := []Result{
+		{ChunkID: "stale", Score: 1.0, UpdatedAt: &stale},
+		{ChunkID: "fresh", Score: 0.9, UpdatedAt: &fresh},
+		{ChunkID: "undated", Score: 0.95},
+	}
+
+	tests := []struct {
+		name        string
+		halfLife    time.Duration
+		weight      float64
+		expected    []string
+		description string
+	}{
+		{
+			name:        "disabled",
+			halfLife:    0,
+			weight:      0.5,
+			expected:    []string{"stale", "fresh", "undated"},
+			description: "a zero half-life should leave the order unchanged",
+		},
+		{
+			name:        "fresh first",
+			halfLife:    30 * 24 * time.Hour,
+			weight:      0.5,
+			expected:    []string{"fresh", "stale", "undated"},
+			description: "recent documents should outrank stale copies",
+		},
+		{
+			name:        "zero weight",
+			halfLife:    30 * 24 * time.Hour,
+			weight:      0,
+			expected:    []string{"stale", "undated", "fresh"},
+			description: "weight 0 should keep original scores",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adjusted := applyRecency(results, now, tt.halfLife, tt.weight)
+			ids := make([]string, len(adjusted))
+			for i, result := range adjusted {
+				ids[i] = result.ChunkID
+			}
+			if !reflect.DeepEqual(ids, tt.expected) {
+				t.Errorf("Expected %v, got %v (%s)", tt.expected, ids, tt.description)
+			}
+		})
+	}
+}
+
+func TestParseDocumentTime(t *testing.T) {
+	for _, value := range []string{"2025-01-02T03:04:05Z", "2025-01-02T03:04:05", "2025-01-02 03:04:05"} {
+		parsed := parseDocumentTime(value)
+		if parsed == nil || !parsed.Equal(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)) {
+			t.Errorf("Expected %q to parse, got %v", value, parsed)
+		}
+	}
+	if parseDocumentTime("") != nil || parseDocumentTime("yesterday") != nil {
+		t.Errorf("Expected empty and malformed timestamps to be nil")
+	}
+}
+
+func TestUnionHits(t *testing.T) {
+	original := []Hit{{ChunkID: "a", Score: 0.9}, {ChunkID: "b", Score: 0.5}}
+	paraphrase := []Hit{{ChunkID: "b", Score: 0.8}, {ChunkID: "c", Score: 0.7}}
+
+	union := unionHits(0, original, paraphrase)
+	expected := []Hit{{ChunkID: "a", Score: 0.9}, {ChunkID: "b", Score: 0.8}, {ChunkID: "c", Score: 0.7}}
+	if !reflect.DeepEqual(union, expected) {
+		t.Errorf("Expected %v, got %v", expected, union)
+	}
+
+	if limited := unionHits(2, original, paraphrase); len(limited) != 2 {
+		t.Errorf("Expected 2 hits, got %d", len(limited))
+	}
+	if single := unionHits(1, original); !reflect.DeepEqual(single, original) {
+		t.Errorf("Expected a single list to be returned unchanged, got %v", single)
+	}
+}
+
+type stubExpander struct {
+	paraphrases []string
+}
+
+func (e *stubExpander) Expand(_ context.Context, _ string, n int) ([]string, error) {
+	return e.paraphrases[:min(n, len(e.paraphrases))], nil
+}
+
+func (e *stubExpander) GetModelName() string {
+	return "stub"
+}
+
+func TestSearcher_Expand(t *testing.T) {
+	searcher := NewSearcher(nil, nil)
+
+	queries, err := searcher.expand(context.Background(), "rotate keys", Options{})
+	if err != nil || !reflect.DeepEqual(queries, []string{"rotate keys"}) {
+		t.Errorf("Expected only the original query without an expander, got %v (%v)", queries, err)
+	}
+
+	expander := &stubExpander{paraphrases: []string{"renew keys", "replace credentials", "cycle secrets"}}
+	queries, err = searcher.expand(context.Background(), "rotate keys", Options{Expander: expander, Expansions: 2})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(queries, []string{"rotate keys", "renew keys", "replace credentials"}) {
+		t.Errorf("Expected the original query and 2 paraphrases, got %v", queries)
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		name          string
+		body          string
+		query         string
+		expectedMatch string
+		expectedSpans []string
+		description   string
+	}{
+		{
+			name:          "keyword terms",
+			body:          "Rotate the API key, then rotate again.",
+			query:         "rotate key",
+			expectedMatch: MatchKeyword,
+			expectedSpans: []string{"Rotate", "key", "rotate"},
+			description:   "every occurrence of a query term should be highlighted",
+		},
+		{
+			name:          "nearest sentence",
+			body:          "Welcome to the docs. Keys are rotated on a schedule. See the FAQ.",
+			query:         "rotations scheduling",
+			expectedMatch: MatchSemantic,
+			expectedSpans: []string{"Keys are rotated on a schedule."},
+			description:   "the sentence sharing the most stems should be highlighted",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			highlighted := Highlight(tt.body, tt.query, 0)
+			if highlighted.Match != tt.expectedMatch {
+				t.Errorf("Expected %s match, got %s", tt.expectedMatch, highlighted.Match)
+			}
+			spans := make([]string, len(highlighted.Highlights))
+			for i, span := range highlighted.Highlights {
+				spans[i] = highlighted.Text[span.Start:span.End]
+			}
+			if !reflect.DeepEqual(spans, tt.expectedSpans) {
+				t.Errorf("Expected spans %q, got %q (%s)", tt.expectedSpans, spans, tt.description)
+			}
+		})
+	}
+}
+
+func TestHighlight_TrimmedWindow(t *testing.T) {
+	body := strings.Repeat("filler words here. ", 30) + "Credentials are rotated monthly by the platform team. " +
+		strings.Repeat("more filler text. ", 30)
+
+	highlighted := Highlight(body, "rotations platforms", 40)
+	if len(highlighted.Highlights) != 1 {
+		t.Fatalf("Expected 1 highlight, got %d", len(highlighted.Highlights))
+	}
+	span := highlighted.Highlights[0]
+	text := highlighted.Text[span.Start:span.End]
+	if !strings.Contains("Credentials are rotated monthly by the platform team.", text) || text == "" {
+		t.Errorf("Expected the highlight to cover part of the sentence, got %q in %q", text, highlighted.Text)
+	}
+}
@@ -0,0 +1,221 @@
+package search
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultSnippetWidth is the number of characters of context shown around a match.
+const DefaultSnippetWidth = 160
+
+// stemLength is the prefix length compared when matching query terms against sentences, so
+// "rotate" matches "rotating" and "rotation".
+const stemLength = 5
+
+// Match types reported with highlighted snippets.
+const (
+	MatchKeyword  = "keyword"
+	MatchSemantic = "semantic"
+)
+
+// Span is a highlighted range of a snippet, as byte offsets into its text.
+type Span struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// Highlighted is a snippet with the ranges that explain why the chunk matched. For keyword
+// matches the spans cover occurrences of query terms; for semantic matches they cover the
+// sentence closest to the query.
+type Highlighted struct {
+	Text       string `json:"text"`
+	Highlights []Span `json:"highlights,omitempty"`
+	Match      string `json:"match"`
+}
+
+// Snippet returns about width characters of body centred on the first occurrence of a query
+// term, with whitespace collapsed and ellipses marking trimmed text. When no term occurs, the
+// start of the body is returned.
+func Snippet(body, query string, width int) string {
+	text := strings.Join(strings.Fields(body), " ")
+	return window(text, firstMatch(text, terms(query)), width)
+}
+
+// Highlight returns a snippet of body with the query's matches highlighted. Bodies containing
+// query terms are highlighted term by term. Otherwise, as for chunks found only by vector
+// search, the sentence sharing the most word stems with the query is extracted and highlighted.
+func Highlight(body, query string, width int) Highlighted {
+	text := strings.Join(strings.Fields(body), " ")
+	queryTerms := terms(query)
+
+	if match := firstMatch(text, queryTerms); match >= 0 {
+		snippet := window(text, match, width)
+		return Highlighted{
+			Text:       snippet,
+			Highlights: termSpans(snippet, queryTerms),
+			Match:      MatchKeyword,
+		}
+	}
+
+	sentence, start := nearestSentence(text, queryTerms)
+	snippet, offset := clip(text, start+len(sentence)/2, width)
+	highlighted := Highlighted{Text: snippet, Match: MatchSemantic}
+	if sentence == "" {
+		return highlighted
+	}
+
+	// The sentence may have been trimmed to fit the window; highlight the part that remains
+	visible := strings.TrimRight(strings.TrimLeft(snippet, "…"), "…")
+	visibleStart := strings.Index(snippet, visible)
+	from := max(start+offset, visibleStart)
+	to := min(start+len(sentence)+offset, visibleStart+len(visible))
+	if from < to {
+		highlighted.Highlights = []Span{{Start: from, End: to}}
+	}
+	return highlighted
+}
+
+// firstMatch returns the byte offset of the earliest query term in text, or -1.
+func firstMatch(text string, queryTerms []string) int {
+	match := -1
+	lower := strings.ToLower(text)
+	if len(lower) != len(text) {
+		return match
+	}
+	for _, term := range queryTerms {
+		if i := strings.Index(lower, term); i >= 0 && (match < 0 || i < match) {
+			match = i
+		}
+	}
+	return match
+}
+
+// window returns about width characters of text centred on the byte offset center, with
+// ellipses marking trimmed text. A negative center starts the window at the beginning.
+func window(text string, center, width int) string {
+	snippet, _ := clip(text, center, width)
+	return snippet
+}
+
+// clip is window that also returns the byte offset in the snippet corresponding to the start of
+// text, so offsets into text can be translated into offsets into the snippet by adding it.
+func clip(text string, center, width int) (string, int) {
+	if width <= 0 {
+		width = DefaultSnippetWidth
+	}
+	runes := []rune(text)
+	if len(runes) <= width {
+		return text, 0
+	}
+
+	start := 0
+	if center > 0 {
+		// convert the byte offset into a rune offset and centre the window on it
+		start = utf8.RuneCountInString(text[:min(center, len(text))]) - width/2
+	}
+	start = max(0, min(start, len(runes)-width))
+	end := start + width
+
+	from := len(string(runes[:start]))
+	to := from + len(string(runes[start:end]))
+	body := text[from:to]
+	trimmed := strings.TrimLeft(body, " ")
+	from += len(body) - len(trimmed)
+
+	snippet := strings.TrimRight(trimmed, " ")
+	offset := -from
+	if start > 0 {
+		snippet = "…" + snippet
+		offset += len("…")
+	}
+	if end < len(runes) {
+		snippet += "…"
+	}
+	return snippet, offset
+}
+
+// termSpans returns the merged ranges of snippet covered by query terms.
+func termSpans(snippet string, queryTerms []string) []Span {
+	lower := strings.ToLower(snippet)
+	if len(lower) != len(snippet) {
+		return nil
+	}
+
+	var spans []Span
+	for _, term := range queryTerms {
+		for from := 0; from < len(lower); {
+			i := strings.Index(lower[from:], term)
+			if i < 0 {
+				break
+			}
+			spans = append(spans, Span{Start: from + i, End: from + i + len(term)})
+			from += i + len(term)
+		}
+	}
+	if len(spans) == 0 {
+		return nil
+	}
+
+	sort.Slice(spans, func(i, j int) bool { return spans[i].Start < spans[j].Start })
+	merged := spans[:1]
+	for _, span := range spans[1:] {
+		last := &merged[len(merged)-1]
+		if span.Start <= last.End {
+			last.End = max(last.End, span.End)
+			continue
+		}
+		merged = append(merged, span)
+	}
+	return merged
+}
+
+// nearestSentence returns the sentence of text sharing the most word stems with the query and
+// its byte offset, preferring earlier sentences on ties.
+func nearestSentence(text string, queryTerms []string) (string, int) {
+	stems := make(map[string]bool, len(queryTerms))
+	for _, term := range queryTerms {
+		stems[stem(term)] = true
+	}
+
+	best, bestStart, bestScore := "", 0, -1
+	start := 0
+	for start < len(text) {
+		end := sentenceEnd(text, start)
+		sentence := strings.TrimSpace(text[start:end])
+		if sentence != "" {
+			score := 0
+			for _, word := range terms(sentence) {
+				if stems[stem(word)] {
+					score++
+				}
+			}
+			if score > bestScore {
+				best, bestStart, bestScore = sentence, start+strings.Index(text[start:end], sentence), score
+			}
+		}
+		start = end
+	}
+	return best, bestStart
+}
+
+// sentenceEnd returns the byte offset just past the sentence beginning at start.
+func sentenceEnd(text string, start int) int {
+	for i := start; i < len(text); i++ {
+		switch text[i] {
+		case '.', '!', '?':
+			if i+1 == len(text) || text[i+1] == ' ' {
+				return i + 1
+			}
+		}
+	}
+	return len(text)
+}
+
+// stem truncates a term to its first stemLength characters.
+func stem(term string) string {
+	if utf8.RuneCountInString(term) <= stemLength {
+		return term
+	}
+	return string([]rune(term)[:stemLength])
+}
@@ -0,0 +1,55 @@
+package search
+
+import (
+	"math"
+	"sort"
+
+	"github.com/code-sleuth/ike-go/pkg/vector"
+)
+
+// cosine returns the cosine similarity of a and b, or 0 when they differ in length or either is
+// all zeros.
+func cosine(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+
+	var dot, normA, normB float64
+	for i := range a {
+		x, y := float64(a[i]), float64(b[i])
+		dot += x * y
+		normA += x * x
+		normB += y * y
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// topHits sorts hits by descending score and keeps the first limit.
+func topHits(hits []Hit, limit int) []Hit {
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].ChunkID < hits[j].ChunkID
+	})
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits
+}
+
+// decodeVector decodes a stored vector, accepting text vectors not yet converted by
+// `ike-go migrate --convert-embeddings`.
+func decodeVector(raw []byte, storage string) ([]float32, error) {
+	switch storage {
+	case "null", "":
+		return nil, nil
+	case "text":
+		return vector.ParseLegacy(string(raw))
+	default:
+		return vector.Decode(raw)
+	}
+}
@@ -0,0 +1,42 @@
+package server
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/code-sleuth/ike-go/internal/manager/search"
+)
+
+// searchGrant is the access to labeled content given to requests bearing token.
+type searchGrant struct {
+	token  string
+	access search.Access
+}
+
+// GrantSearchAccess gives searches bearing token in an "Authorization: Bearer" header access to
+// the content labeled with access.Labels, or to everything when access.Unrestricted is set.
+// Searches without a token only see unlabeled and public content; ones with an unknown token are
+// refused. Grants must be made before the server starts.
+func (s *Server) GrantSearchAccess(token string, access search.Access) {
+	s.grants = append(s.grants, searchGrant{token: token, access: access})
+}
+
+// searchAccess returns the access of r's bearer token, and false when it bears one that wasn't
+// granted any.
+func (s *Server) searchAccess(r *http.Request) (search.Access, bool) {
+	header := r.Header.Get("Authorization")
+	if header == "" {
+		return search.Access{}, true
+	}
+	bearer, ok := strings.CutPrefix(header, "Bearer ")
+	if !ok || bearer == "" {
+		return search.Access{}, false
+	}
+	for _, grant := range s.grants {
+		if subtle.ConstantTimeCompare([]byte(bearer), []byte(grant.token)) == 1 {
+			return grant.access, true
+		}
+	}
+	return search.Access{}, false
+}
@@ -0,0 +1,63 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/code-sleuth/ike-go/internal/manager/search"
+)
+
+func TestHandleSearch_Access(t *testing.T) {
+	tests := []struct {
+		name           string
+		authorization  string
+		expectedStatus int
+		expectedAccess search.Access
+	}{
+		{name: "no token", expectedStatus: http.StatusOK},
+		{name: "granted labels", authorization: "Bearer support-token", expectedStatus: http.StatusOK,
+			expectedAccess: search.Access{Labels: []string{"support", "sre"}}},
+		{name: "admin token", authorization: "Bearer admin-token", expectedStatus: http.StatusOK,
+			expectedAccess: search.Access{Unrestricted: true}},
+		{name: "unknown token", authorization: "Bearer guess", expectedStatus: http.StatusUnauthorized},
+		{name: "not a bearer token", authorization: "Basic c3VwcG9ydA==", expectedStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			searcher := &stubSearcher{}
+			srv := NewServer(searcher)
+			srv.GrantSearchAccess("support-token", search.Access{Labels: []string{"support", "sre"}})
+			srv.GrantSearchAccess("admin-token", search.Access{Unrestricted: true})
+
+			request := httptest.NewRequest(http.MethodPost, "/v1/search", strings.NewReader(`{"query": "x"}`))
+			if tt.authorization != "" {
+				request.Header.Set("Authorization", tt.authorization)
+			}
+			recorder := httptest.NewRecorder()
+			srv.Handler().ServeHTTP(recorder, request)
+
+			if recorder.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedStatus, recorder.Code, recorder.Body.String())
+			}
+			if !reflect.DeepEqual(searcher.opts.Filters.Access, tt.expectedAccess) {
+				t.Errorf("Expected access %+v, got %+v", tt.expectedAccess, searcher.opts.Filters.Access)
+			}
+		})
+	}
+}
+
+func TestHandleSearch_AccessNotInBody(t *testing.T) {
+	handler := NewServer(&stubSearcher{}).Handler()
+
+	body := `{"query": "x", "filters": {"access": {"Unrestricted": true}}}`
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/v1/search", strings.NewReader(body)))
+
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("Expected callers not to grant themselves access, got status %d", recorder.Code)
+	}
+}
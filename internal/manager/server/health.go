@@ -0,0 +1,112 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// healthCheckTimeout bounds each check, so a hung dependency fails the probe instead of the
+// orchestrator's request timing out.
+const healthCheckTimeout = 5 * time.Second
+
+const (
+	statusOK          = "ok"
+	statusUnavailable = "unavailable"
+)
+
+// HealthCheck verifies one dependency the server needs to serve requests.
+type HealthCheck struct {
+	Name string
+	// Check returns nil while the dependency is usable.
+	Check func(ctx context.Context) error
+	// Liveness runs the check for /healthz as well as /readyz. Only dependencies whose failure a
+	// restart can fix belong there; the rest only take the server out of rotation.
+	Liveness bool
+	// CacheFor reuses a result for this long, for checks that cost something to run such as
+	// embedding a probe. Zero runs the check on every request.
+	CacheFor time.Duration
+}
+
+// HealthResponse is the body of GET /healthz and GET /readyz. Checks maps each check's name to
+// "ok" or the error it failed with.
+type HealthResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+// HandleHealth registers GET /healthz and GET /readyz. /readyz runs every check and /healthz the
+// liveness ones; both respond 200 when all of them pass and 503 otherwise, so orchestrators can
+// restart the server or stop routing traffic to it.
+func (s *Server) HandleHealth(checks ...HealthCheck) {
+	var liveness, readiness []*cachedCheck
+	for _, check := range checks {
+		cached := &cachedCheck{HealthCheck: check}
+		readiness = append(readiness, cached)
+		if check.Liveness {
+			liveness = append(liveness, cached)
+		}
+	}
+	s.mux.HandleFunc("GET /healthz", s.healthHandler(liveness))
+	s.mux.HandleFunc("GET /readyz", s.healthHandler(readiness))
+}
+
+// healthHandler runs checks concurrently and reports their results.
+func (s *Server) healthHandler(checks []*cachedCheck) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		errs := make([]error, len(checks))
+		var wg sync.WaitGroup
+		for i, check := range checks {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				errs[i] = check.run(r.Context())
+			}()
+		}
+		wg.Wait()
+
+		response := HealthResponse{Status: statusOK, Checks: make(map[string]string, len(checks))}
+		status := http.StatusOK
+		for i, check := range checks {
+			if errs[i] != nil {
+				response.Checks[check.Name] = errs[i].Error()
+				response.Status, status = statusUnavailable, http.StatusServiceUnavailable
+				continue
+			}
+			response.Checks[check.Name] = statusOK
+		}
+		if status != http.StatusOK {
+			s.logger.Error().Str("path", r.URL.Path).Interface("checks", response.Checks).Msg("Health check failed")
+		}
+		s.writeJSON(w, status, response)
+	}
+}
+
+// cachedCheck runs a HealthCheck, reusing its last result for CacheFor.
+type cachedCheck struct {
+	HealthCheck
+
+	mu      sync.Mutex
+	checked time.Time
+	err     error
+}
+
+func (c *cachedCheck) run(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.CacheFor > 0 && !c.checked.IsZero() && time.Since(c.checked) < c.CacheFor {
+		return c.err
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+	err := c.Check(checkCtx)
+	if ctx.Err() != nil {
+		// The prober went away, which says nothing about the dependency
+		return err
+	}
+	c.err, c.checked = err, time.Now()
+	return err
+}
@@ -0,0 +1,75 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHandleHealth(t *testing.T) {
+	errEmbedder := errors.New("embedder unreachable")
+	srv := NewServer(&stubSearcher{})
+	srv.HandleHealth(
+		HealthCheck{Name: "database", Check: func(context.Context) error { return nil }, Liveness: true},
+		HealthCheck{Name: "embedder", Check: func(context.Context) error { return errEmbedder }},
+	)
+	handler := srv.Handler()
+
+	tests := []struct {
+		path           string
+		expectedStatus int
+		expectedChecks map[string]string
+	}{
+		{path: "/healthz", expectedStatus: http.StatusOK, expectedChecks: map[string]string{"database": "ok"}},
+		{path: "/readyz", expectedStatus: http.StatusServiceUnavailable,
+			expectedChecks: map[string]string{"database": "ok", "embedder": errEmbedder.Error()}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if recorder.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedStatus, recorder.Code, recorder.Body.String())
+			}
+			var response HealthResponse
+			if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if len(response.Checks) != len(tt.expectedChecks) {
+				t.Errorf("Expected checks %v, got %v", tt.expectedChecks, response.Checks)
+			}
+			for name, expected := range tt.expectedChecks {
+				if response.Checks[name] != expected {
+					t.Errorf("Expected check %s to be %q, got %q", name, expected, response.Checks[name])
+				}
+			}
+		})
+	}
+}
+
+func TestHandleHealth_CachesResults(t *testing.T) {
+	calls := 0
+	srv := NewServer(&stubSearcher{})
+	srv.HandleHealth(HealthCheck{Name: "embedder", Check: func(context.Context) error {
+		calls++
+		return nil
+	}, CacheFor: time.Hour})
+	handler := srv.Handler()
+
+	for range 3 {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", recorder.Code)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("Expected the check to run once within CacheFor, ran %d times", calls)
+	}
+}
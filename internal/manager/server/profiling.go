@@ -0,0 +1,17 @@
+package server
+
+import (
+	"net/http/pprof"
+)
+
+// HandleProfiling registers the runtime profiles of net/http/pprof under /debug/pprof/, so
+// "go tool pprof" can profile a running server. Profiles expose the process' memory and stacks,
+// so requests must carry token in an "Authorization: Bearer" header, as for /v1/webhooks.
+func (s *Server) HandleProfiling(token string) {
+	s.mux.HandleFunc("GET /debug/pprof/", s.requireToken(token, pprof.Index))
+	s.mux.HandleFunc("GET /debug/pprof/cmdline", s.requireToken(token, pprof.Cmdline))
+	s.mux.HandleFunc("GET /debug/pprof/profile", s.requireToken(token, pprof.Profile))
+	s.mux.HandleFunc("GET /debug/pprof/symbol", s.requireToken(token, pprof.Symbol))
+	s.mux.HandleFunc("POST /debug/pprof/symbol", s.requireToken(token, pprof.Symbol))
+	s.mux.HandleFunc("GET /debug/pprof/trace", s.requireToken(token, pprof.Trace))
+}
@@ -0,0 +1,56 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandleProfiling(t *testing.T) {
+	srv := NewServer(&stubSearcher{})
+	srv.HandleProfiling("secret")
+	handler := srv.Handler()
+
+	tests := []struct {
+		name           string
+		path           string
+		authorization  string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{name: "index", path: "/debug/pprof/", authorization: "Bearer secret",
+			expectedStatus: http.StatusOK, expectedBody: "goroutine"},
+		{name: "named profile", path: "/debug/pprof/heap?debug=1", authorization: "Bearer secret",
+			expectedStatus: http.StatusOK, expectedBody: "heap profile"},
+		{name: "missing token", path: "/debug/pprof/", expectedStatus: http.StatusUnauthorized},
+		{name: "wrong token", path: "/debug/pprof/goroutine", authorization: "Bearer guess",
+			expectedStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.authorization != "" {
+				request.Header.Set("Authorization", tt.authorization)
+			}
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+
+			if recorder.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedStatus, recorder.Code, recorder.Body.String())
+			}
+			if !strings.Contains(recorder.Body.String(), tt.expectedBody) {
+				t.Errorf("Expected body to contain %q, got %s", tt.expectedBody, recorder.Body.String())
+			}
+		})
+	}
+}
+
+func TestNewServer_NoProfilingByDefault(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	NewServer(&stubSearcher{}).Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/debug/pprof/", nil))
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf("Expected profiles not to be served unless enabled, got status %d", recorder.Code)
+	}
+}
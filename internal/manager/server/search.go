@@ -0,0 +1,148 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/code-sleuth/ike-go/internal/manager/search"
+)
+
+// maxTopK bounds the number of results a single request may ask for.
+const maxTopK = 100
+
+var ErrInvalidTopK = errors.New("top_k must be between 1 and 100")
+
+// SearchRequest is the body of POST /v1/search.
+type SearchRequest struct {
+	Query     string         `json:"query"`
+	TopK      int            `json:"top_k,omitempty"`
+	Filters   search.Filters `json:"filters,omitempty"`
+	Mode      search.Mode    `json:"mode,omitempty"`
+	Weight    *float64       `json:"weight,omitempty"`
+	Diversity float64        `json:"diversity,omitempty"`
+	// RecencyHalfLife is a Go duration such as "720h"; empty disables recency ranking.
+	RecencyHalfLife string  `json:"recency_half_life,omitempty"`
+	RecencyWeight   float64 `json:"recency_weight,omitempty"`
+}
+
+// Citation identifies where a result came from so applications can attribute it.
+type Citation struct {
+	SourceID   string     `json:"source_id"`
+	SourceURL  string     `json:"source_url"`
+	DocumentID string     `json:"document_id"`
+	ChunkID    string     `json:"chunk_id"`
+	UpdatedAt  *time.Time `json:"updated_at,omitempty"`
+	// CanonicalURL is shared by syndicated copies of a page, for deduplicating results.
+	CanonicalURL string `json:"canonical_url,omitempty"`
+	// License is the SPDX license expression of the document, so it can be respected.
+	License string `json:"license,omitempty"`
+}
+
+// SearchResult is a single ranked chunk in a search response.
+type SearchResult struct {
+	Rank        int                 `json:"rank"`
+	ChunkID     string              `json:"chunk_id"`
+	Score       float64             `json:"score"`
+	Body        string              `json:"body"`
+	Snippet     *search.Highlighted `json:"snippet,omitempty"`
+	KeywordRank int                 `json:"keyword_rank,omitempty"`
+	VectorRank  int                 `json:"vector_rank,omitempty"`
+	Citation    Citation            `json:"citation"`
+}
+
+// SearchResponse is the body returned by POST /v1/search.
+type SearchResponse struct {
+	Query   string         `json:"query"`
+	Results []SearchResult `json:"results"`
+	TookMS  int64          `json:"took_ms"`
+}
+
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+
+	access, ok := s.searchAccess(r)
+	if !ok {
+		s.writeError(w, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+
+	var request SearchRequest
+	if err := decodeJSON(w, r, &request); err != nil {
+		s.writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+	if request.TopK == 0 {
+		request.TopK = search.DefaultLimit
+	}
+	if request.TopK < 0 || request.TopK > maxTopK {
+		s.writeError(w, http.StatusBadRequest, ErrInvalidTopK)
+		return
+	}
+
+	var halfLife time.Duration
+	if request.RecencyHalfLife != "" {
+		parsed, err := time.ParseDuration(request.RecencyHalfLife)
+		if err != nil || parsed < 0 {
+			s.writeError(w, http.StatusBadRequest, fmt.Errorf("invalid recency_half_life: %q", request.RecencyHalfLife))
+			return
+		}
+		halfLife = parsed
+	}
+	request.Filters.Access = access
+
+	weight := search.DefaultWeight
+	if request.Weight != nil {
+		weight = *request.Weight
+	}
+
+	results, err := s.searcher.Search(r.Context(), request.Query, search.Options{
+		Mode:      request.Mode,
+		Limit:     request.TopK,
+		Weight:    weight,
+		Diversity: request.Diversity,
+		Filters:   request.Filters,
+
+		RecencyHalfLife: halfLife,
+		RecencyWeight:   request.RecencyWeight,
+	})
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, search.ErrEmptyQuery) || errors.Is(err, search.ErrUnsupportedMode) {
+			status = http.StatusBadRequest
+		}
+		s.logger.Error().Err(err).Str("query", request.Query).Msg("Search request failed")
+		s.writeError(w, status, err)
+		return
+	}
+
+	response := SearchResponse{
+		Query:   request.Query,
+		Results: make([]SearchResult, len(results)),
+	}
+	for i, result := range results {
+		response.Results[i] = SearchResult{
+			Rank:        i + 1,
+			ChunkID:     result.ChunkID,
+			Score:       result.Score,
+			Body:        result.Body,
+			Snippet:     result.Snippet,
+			KeywordRank: result.KeywordRank,
+			VectorRank:  result.VectorRank,
+			Citation: Citation{
+				SourceID:   result.SourceID,
+				SourceURL:  result.SourceURL,
+				DocumentID: result.DocumentID,
+				ChunkID:    result.ChunkID,
+				UpdatedAt:  result.UpdatedAt,
+
+				CanonicalURL: result.CanonicalURL,
+				License:      result.License,
+			},
+		}
+	}
+	response.TookMS = time.Since(start).Milliseconds()
+
+	s.writeJSON(w, http.StatusOK, response)
+}
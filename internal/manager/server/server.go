@@ -0,0 +1,126 @@
+// Package server exposes ike-go over HTTP for application integration.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/code-sleuth/ike-go/internal/manager/search"
+	"github.com/code-sleuth/ike-go/pkg/metrics"
+	"github.com/code-sleuth/ike-go/pkg/util"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	// DefaultAddr is the address the server listens on when none is configured.
+	DefaultAddr = ":8080"
+
+	readHeaderTimeout = 10 * time.Second
+	shutdownTimeout   = 30 * time.Second
+	// maxRequestBody bounds JSON request bodies.
+	maxRequestBody = 1 << 20
+)
+
+// Searcher runs searches for the HTTP API.
+type Searcher interface {
+	Search(ctx context.Context, query string, opts search.Options) ([]search.Result, error)
+}
+
+// Server serves the HTTP API. Routes are registered on an http.ServeMux, so additional
+// endpoints can be attached with Handle before the server starts.
+type Server struct {
+	mux      *http.ServeMux
+	searcher Searcher
+	grants   []searchGrant
+	logger   zerolog.Logger
+}
+
+// NewServer creates a server with the search API and the Prometheus metrics endpoint registered.
+func NewServer(searcher Searcher) *Server {
+	logger := util.NewLogger(zerolog.ErrorLevel)
+	s := &Server{
+		mux:      http.NewServeMux(),
+		searcher: searcher,
+		logger:   logger,
+	}
+
+	s.mux.HandleFunc("POST /v1/search", s.handleSearch)
+	s.mux.Handle("GET /metrics", metrics.Handler())
+	return s
+}
+
+// Handle registers an additional handler for the given pattern.
+func (s *Server) Handle(pattern string, handler http.Handler) {
+	s.mux.Handle(pattern, handler)
+}
+
+// Handler returns the server's root HTTP handler.
+func (s *Server) Handler() http.Handler {
+	return s.mux
+}
+
+// ListenAndServe serves on addr until ctx is cancelled, then shuts down gracefully, giving
+// in-flight requests time to finish.
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	if addr == "" {
+		addr = DefaultAddr
+	}
+
+	httpServer := &http.Server{
+		Addr:              addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+
+	errChan := make(chan error, 1)
+	go func() {
+		s.logger.Info().Str("addr", addr).Msg("HTTP server listening")
+		errChan <- httpServer.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errChan:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	if err := <-errChan; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// errorResponse is the body returned for failed requests.
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// writeJSON writes value as a JSON response with the given status code.
+func (s *Server) writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		s.logger.Error().Err(err).Msg("Failed to write response")
+	}
+}
+
+// writeError writes err as a JSON error response.
+func (s *Server) writeError(w http.ResponseWriter, status int, err error) {
+	s.writeJSON(w, status, errorResponse{Error: err.Error()})
+}
+
+// decodeJSON decodes a bounded JSON request body into value, rejecting unknown fields.
+func decodeJSON(w http.ResponseWriter, r *http.Request, value interface{}) error {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBody))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(value)
+}
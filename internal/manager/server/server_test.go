@@ -0,0 +1,148 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/code-sleuth/ike-go/internal/manager/search"
+)
+
+type stubSearcher struct {
+	results []search.Result
+	err     error
+	query   string
+	opts    search.Options
+}
+
+func (s *stubSearcher) Search(_ context.Context, query string, opts search.Options) ([]search.Result, error) {
+	s.query = query
+	s.opts = opts
+	if s.err != nil {
+		return nil, s.err
+	}
+	if strings.TrimSpace(query) == "" {
+		return nil, search.ErrEmptyQuery
+	}
+	return s.results, nil
+}
+
+func TestHandleSearch(t *testing.T) {
+	searcher := &stubSearcher{results: []search.Result{{
+		ChunkID:    "chunk-1",
+		DocumentID: "doc-1",
+		SourceID:   "source-1",
+		SourceURL:  "https://github.com/owner/repo/blob/main/README.md",
+		Body:       "Rotate keys with ike-go keys rotate.",
+		Score:      0.5,
+		Snippet:    &search.Highlighted{Text: "Rotate keys", Match: search.MatchKeyword},
+		License:    "MIT",
+	}}}
+	handler := NewServer(searcher).Handler()
+
+	body := `{"query": "rotate keys", "top_k": 5, "filters": {"host": "github.com"}}`
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/v1/search", strings.NewReader(body)))
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	if searcher.opts.Limit != 5 || searcher.opts.Filters.Host != "github.com" {
+		t.Errorf("Expected top_k and filters to be passed through, got %+v", searcher.opts)
+	}
+	if searcher.opts.Weight != search.DefaultWeight {
+		t.Errorf("Expected default weight %f, got %f", search.DefaultWeight, searcher.opts.Weight)
+	}
+
+	var response SearchResponse
+	if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(response.Results) != 1 {
+		t.Fatalf("Expected 1 result, got %d", len(response.Results))
+	}
+	result := response.Results[0]
+	if result.Rank != 1 || result.Citation.SourceURL != "https://github.com/owner/repo/blob/main/README.md" {
+		t.Errorf("Expected rank and citation metadata, got %+v", result)
+	}
+	if result.Citation.License != "MIT" {
+		t.Errorf("Expected the document's license in the citation, got %+v", result.Citation)
+	}
+	if result.Snippet == nil || result.Snippet.Match != search.MatchKeyword {
+		t.Errorf("Expected the highlighted snippet to be returned, got %+v", result.Snippet)
+	}
+}
+
+func TestHandleSearch_BadRequests(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		body           string
+		expectedStatus int
+		description    string
+	}{
+		{
+			name:           "invalid json",
+			method:         http.MethodPost,
+			body:           `{"query":`,
+			expectedStatus: http.StatusBadRequest,
+			description:    "malformed bodies should be rejected",
+		},
+		{
+			name:           "unknown field",
+			method:         http.MethodPost,
+			body:           `{"query": "x", "topk": 5}`,
+			expectedStatus: http.StatusBadRequest,
+			description:    "misspelled fields should be rejected",
+		},
+		{
+			name:           "empty query",
+			method:         http.MethodPost,
+			body:           `{"query": " "}`,
+			expectedStatus: http.StatusBadRequest,
+			description:    "empty queries should be rejected",
+		},
+		{
+			name:           "top_k too large",
+			method:         http.MethodPost,
+			body:           `{"query": "x", "top_k": 1000}`,
+			expectedStatus: http.StatusBadRequest,
+			description:    "top_k should be bounded",
+		},
+		{
+			name:           "wrong method",
+			method:         http.MethodGet,
+			expectedStatus: http.StatusMethodNotAllowed,
+			description:    "only POST should be accepted",
+		},
+	}
+
+	handler := NewServer(&stubSearcher{}).Handler()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(tt.method, "/v1/search", strings.NewReader(tt.body)))
+
+			if recorder.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d (%s)", tt.expectedStatus, recorder.Code, tt.description)
+			}
+		})
+	}
+}
+
+func TestHandleMetrics(t *testing.T) {
+	handler := NewServer(&stubSearcher{}).Handler()
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", recorder.Code)
+	}
+	if contentType := recorder.Header().Get("Content-Type"); !strings.HasPrefix(contentType, "text/plain") {
+		t.Errorf("Expected the Prometheus text format, got %q", contentType)
+	}
+}
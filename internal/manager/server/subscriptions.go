@@ -0,0 +1,104 @@
+package server
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/code-sleuth/ike-go/internal/manager/models"
+	"github.com/code-sleuth/ike-go/internal/manager/notifier"
+	"github.com/code-sleuth/ike-go/internal/manager/repository"
+)
+
+var ErrUnauthorized = errors.New("missing or invalid bearer token")
+
+// WebhookSubscriptionStore stores outbound webhook subscriptions;
+// repository.WebhookSubscriptionRepository implements it.
+type WebhookSubscriptionStore interface {
+	Create(subscription *models.WebhookSubscription) error
+	List() ([]models.WebhookSubscription, error)
+	Delete(id string) error
+}
+
+// WebhookSubscriptionRequest is the body of POST /v1/webhooks. An empty Secret is generated;
+// empty EventTypes subscribes to every event type.
+type WebhookSubscriptionRequest struct {
+	URL        string             `json:"url"`
+	Secret     string             `json:"secret,omitempty"`
+	EventTypes []models.EventType `json:"event_types,omitempty"`
+}
+
+// CreatedWebhookSubscription is the body returned by POST /v1/webhooks. It is the only response
+// that includes the secret deliveries are signed with.
+type CreatedWebhookSubscription struct {
+	models.WebhookSubscription
+	Secret string `json:"secret"`
+}
+
+// HandleWebhookSubscriptions registers GET /v1/webhooks, POST /v1/webhooks, and
+// DELETE /v1/webhooks/{id} for managing the outbound webhooks the daemon calls. Requests must
+// carry token in an "Authorization: Bearer" header, since subscribers receive the corpus' events.
+func (s *Server) HandleWebhookSubscriptions(token string, store WebhookSubscriptionStore) {
+	s.mux.HandleFunc("GET /v1/webhooks", s.requireToken(token, func(w http.ResponseWriter, _ *http.Request) {
+		subscriptions, err := store.List()
+		if err != nil {
+			s.writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		if subscriptions == nil {
+			subscriptions = []models.WebhookSubscription{}
+		}
+		s.writeJSON(w, http.StatusOK, subscriptions)
+	}))
+
+	s.mux.HandleFunc("POST /v1/webhooks", s.requireToken(token, func(w http.ResponseWriter, r *http.Request) {
+		var request WebhookSubscriptionRequest
+		if err := decodeJSON(w, r, &request); err != nil {
+			s.writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+			return
+		}
+		subscription, err := notifier.NewSubscription(request.URL, request.Secret, request.EventTypes)
+		if err != nil {
+			s.writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		if err := store.Create(subscription); err != nil {
+			s.writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		if subscription.EventTypes == nil {
+			subscription.EventTypes = []models.EventType{}
+		}
+		s.writeJSON(w, http.StatusCreated, CreatedWebhookSubscription{
+			WebhookSubscription: *subscription,
+			Secret:              subscription.Secret,
+		})
+	}))
+
+	s.mux.HandleFunc("DELETE /v1/webhooks/{id}", s.requireToken(token, func(w http.ResponseWriter, r *http.Request) {
+		err := store.Delete(r.PathValue("id"))
+		if errors.Is(err, repository.ErrWebhookSubscriptionNotFound) {
+			s.writeError(w, http.StatusNotFound, err)
+			return
+		}
+		if err != nil {
+			s.writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+}
+
+// requireToken wraps next so it only runs for requests bearing token.
+func (s *Server) requireToken(token string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || token == "" || subtle.ConstantTimeCompare([]byte(bearer), []byte(token)) != 1 {
+			s.writeError(w, http.StatusUnauthorized, ErrUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
@@ -0,0 +1,110 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/code-sleuth/ike-go/internal/manager/models"
+	"github.com/code-sleuth/ike-go/internal/manager/repository"
+)
+
+const testAdminToken = "admin-token"
+
+type stubSubscriptionStore struct {
+	subscriptions []models.WebhookSubscription
+}
+
+func (s *stubSubscriptionStore) Create(subscription *models.WebhookSubscription) error {
+	subscription.ID = "sub-1"
+	s.subscriptions = append(s.subscriptions, *subscription)
+	return nil
+}
+
+func (s *stubSubscriptionStore) List() ([]models.WebhookSubscription, error) {
+	return s.subscriptions, nil
+}
+
+func (s *stubSubscriptionStore) Delete(id string) error {
+	for i, subscription := range s.subscriptions {
+		if subscription.ID == id {
+			s.subscriptions = append(s.subscriptions[:i], s.subscriptions[i+1:]...)
+			return nil
+		}
+	}
+	return repository.ErrWebhookSubscriptionNotFound
+}
+
+func TestHandleWebhookSubscriptions(t *testing.T) {
+	store := &stubSubscriptionStore{}
+	srv := NewServer(&stubSearcher{})
+	srv.HandleWebhookSubscriptions(testAdminToken, store)
+
+	do := func(method, path, body, token string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(method, path, strings.NewReader(body))
+		if token != "" {
+			request.Header.Set("Authorization", "Bearer "+token)
+		}
+		recorder := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		token          string
+		expectedStatus int
+	}{
+		{name: "no token", method: http.MethodGet, path: "/v1/webhooks", expectedStatus: http.StatusUnauthorized},
+		{name: "wrong token", method: http.MethodGet, path: "/v1/webhooks", token: "guess",
+			expectedStatus: http.StatusUnauthorized},
+		{name: "empty list", method: http.MethodGet, path: "/v1/webhooks", token: testAdminToken,
+			expectedStatus: http.StatusOK},
+		{name: "invalid URL", method: http.MethodPost, path: "/v1/webhooks", body: `{"url": "example.com"}`,
+			token: testAdminToken, expectedStatus: http.StatusBadRequest},
+		{name: "unknown event type", method: http.MethodPost, path: "/v1/webhooks",
+			body: `{"url": "https://example.com/hook", "event_types": ["nope"]}`, token: testAdminToken,
+			expectedStatus: http.StatusBadRequest},
+		{name: "unknown subscription", method: http.MethodDelete, path: "/v1/webhooks/missing",
+			token: testAdminToken, expectedStatus: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if recorder := do(tt.method, tt.path, tt.body, tt.token); recorder.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedStatus, recorder.Code, recorder.Body)
+			}
+		})
+	}
+
+	recorder := do(http.MethodPost, "/v1/webhooks",
+		`{"url": "https://example.com/hook", "event_types": ["job_failed"]}`, testAdminToken)
+	if recorder.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", recorder.Code, recorder.Body)
+	}
+	var created CreatedWebhookSubscription
+	if err := json.Unmarshal(recorder.Body.Bytes(), &created); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if created.ID != "sub-1" || created.Secret == "" || store.subscriptions[0].Secret != created.Secret {
+		t.Errorf("Expected the created subscription with its generated secret, got %+v", created)
+	}
+
+	// The secret is only shown when the subscription is created
+	recorder = do(http.MethodGet, "/v1/webhooks", "", testAdminToken)
+	if strings.Contains(recorder.Body.String(), created.Secret) {
+		t.Errorf("Expected the list to leave out secrets, got %s", recorder.Body)
+	}
+
+	recorder = do(http.MethodDelete, "/v1/webhooks/sub-1", "", testAdminToken)
+	if recorder.Code != http.StatusNoContent {
+		t.Errorf("Expected status 204, got %d", recorder.Code)
+	}
+	if len(store.subscriptions) != 0 {
+		t.Errorf("Expected the subscription to be deleted, got %+v", store.subscriptions)
+	}
+}
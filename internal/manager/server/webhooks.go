@@ -0,0 +1,178 @@
+package server
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// maxWebhookBody bounds webhook payloads; push events with many commits are far larger than
+// search requests.
+const maxWebhookBody = 10 << 20
+
+var (
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	ErrInvalidPayload   = errors.New("invalid webhook payload")
+)
+
+// GitHubPushReceiver enqueues re-imports for the files changed by a push. services.WebhookReceiver
+// implements it.
+type GitHubPushReceiver interface {
+	GitHubPush(ctx context.Context, repository, branch string, paths []string) ([]string, error)
+}
+
+// WordPressPostReceiver enqueues a re-import of a single post. services.WebhookReceiver
+// implements it.
+type WordPressPostReceiver interface {
+	WordPressPost(ctx context.Context, endpoint string, postID int) ([]string, error)
+}
+
+// WordPressPostEvent is the body of POST /webhooks/wordpress. Endpoint is the WP-JSON
+// collection the post was imported from, such as https://example.com/wp-json/wp/v2/posts.
+type WordPressPostEvent struct {
+	Endpoint string `json:"endpoint"`
+	PostID   int    `json:"post_id"`
+}
+
+// WebhookResponse is the body returned for an accepted webhook delivery.
+type WebhookResponse struct {
+	Jobs []string `json:"jobs"`
+}
+
+// githubPushEvent is the part of GitHub's push event payload the receiver needs.
+type githubPushEvent struct {
+	Ref        string `json:"ref"`
+	Deleted    bool   `json:"deleted"`
+	Repository struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+	Commits []struct {
+		Added    []string `json:"added"`
+		Modified []string `json:"modified"`
+	} `json:"commits"`
+}
+
+// HandleGitHubWebhook registers POST /webhooks/github. Deliveries must be signed with secret;
+// push events to a branch are passed to receiver with the paths they added or modified, and
+// every other event is acknowledged and ignored.
+func (s *Server) HandleGitHubWebhook(secret string, receiver GitHubPushReceiver) {
+	s.mux.HandleFunc("POST /webhooks/github", func(w http.ResponseWriter, r *http.Request) {
+		body, ok := s.readSignedBody(w, r, secret, r.Header.Get("X-Hub-Signature-256"))
+		if !ok {
+			return
+		}
+
+		if r.Header.Get("X-GitHub-Event") != "push" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		var event githubPushEvent
+		if err := json.Unmarshal(body, &event); err != nil || event.Repository.FullName == "" {
+			s.writeError(w, http.StatusBadRequest, ErrInvalidPayload)
+			return
+		}
+
+		// Tag pushes and branch deletions leave nothing to import
+		branch, ok := strings.CutPrefix(event.Ref, "refs/heads/")
+		if !ok || event.Deleted {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		jobs, err := receiver.GitHubPush(r.Context(), event.Repository.FullName, branch, event.changedPaths())
+		if err != nil {
+			s.logger.Error().Err(err).Str("repository", event.Repository.FullName).Msg("Failed to handle push")
+			s.writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		s.writeJSON(w, http.StatusAccepted, WebhookResponse{Jobs: jobs})
+	})
+}
+
+// HandleWordPressWebhook registers POST /webhooks/wordpress for a WordPress hook to call when a
+// post is published or updated. The body is a WordPressPostEvent signed with secret in the
+// X-Webhook-Signature header, as "sha256=" followed by the hex HMAC-SHA256 of the body.
+func (s *Server) HandleWordPressWebhook(secret string, receiver WordPressPostReceiver) {
+	s.mux.HandleFunc("POST /webhooks/wordpress", func(w http.ResponseWriter, r *http.Request) {
+		body, ok := s.readSignedBody(w, r, secret, r.Header.Get("X-Webhook-Signature"))
+		if !ok {
+			return
+		}
+
+		var event WordPressPostEvent
+		if err := json.Unmarshal(body, &event); err != nil ||
+			!strings.Contains(event.Endpoint, "/wp-json/") || event.PostID <= 0 {
+			s.writeError(w, http.StatusBadRequest, ErrInvalidPayload)
+			return
+		}
+
+		jobs, err := receiver.WordPressPost(r.Context(), event.Endpoint, event.PostID)
+		if err != nil {
+			s.logger.Error().Err(err).Str("endpoint", event.Endpoint).Int("post_id", event.PostID).
+				Msg("Failed to handle post update")
+			s.writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		s.writeJSON(w, http.StatusAccepted, WebhookResponse{Jobs: jobs})
+	})
+}
+
+// readSignedBody reads the request body and checks it against signature. On failure it writes
+// the error response and returns false.
+func (s *Server) readSignedBody(w http.ResponseWriter, r *http.Request, secret, signature string) ([]byte, bool) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBody))
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, ErrInvalidPayload)
+		return nil, false
+	}
+	if !validSignature(secret, body, signature) {
+		s.writeError(w, http.StatusUnauthorized, ErrInvalidSignature)
+		return nil, false
+	}
+	return body, true
+}
+
+// changedPaths returns the distinct paths added or modified across the push's commits.
+func (e *githubPushEvent) changedPaths() []string {
+	seen := make(map[string]bool)
+	var paths []string
+	add := func(changed []string) {
+		for _, path := range changed {
+			if !seen[path] {
+				seen[path] = true
+				paths = append(paths, path)
+			}
+		}
+	}
+	for _, commit := range e.Commits {
+		add(commit.Added)
+		add(commit.Modified)
+	}
+	return paths
+}
+
+// validSignature checks a "sha256=<hex>" signature header, an HMAC-SHA256 of body keyed with the
+// webhook secret, as sent by GitHub in X-Hub-Signature-256.
+func validSignature(secret string, body []byte, signature string) bool {
+	digest, ok := strings.CutPrefix(signature, "sha256=")
+	if !ok || secret == "" {
+		return false
+	}
+	expected, err := hex.DecodeString(digest)
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
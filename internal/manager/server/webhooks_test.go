@@ -0,0 +1,231 @@
+package server
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testWebhookSecret = "s3cret"
+
+type stubPushReceiver struct {
+	calls      int
+	repository string
+	branch     string
+	paths      []string
+}
+
+func (r *stubPushReceiver) GitHubPush(_ context.Context, repository, branch string, paths []string) ([]string, error) {
+	r.calls++
+	r.repository = repository
+	r.branch = branch
+	r.paths = paths
+	return []string{"job-1"}, nil
+}
+
+func sign(body string) string {
+	mac := hmac.New(sha256.New, []byte(testWebhookSecret))
+	mac.Write([]byte(body))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestHandleGitHubWebhook(t *testing.T) {
+	push := `{
+		"ref": "refs/heads/main",
+		"repository": {"full_name": "owner/repo"},
+		"commits": [
+			{"added": ["docs/new.md"], "modified": ["README.md"], "removed": ["old.md"]},
+			{"added": [], "modified": ["README.md", "src/main.go"], "removed": []}
+		]
+	}`
+
+	tests := []struct {
+		name           string
+		event          string
+		body           string
+		signature      string
+		expectedStatus int
+		expectedCalls  int
+		description    string
+	}{
+		{
+			name:           "push",
+			event:          "push",
+			body:           push,
+			signature:      sign(push),
+			expectedStatus: http.StatusAccepted,
+			expectedCalls:  1,
+			description:    "should pass the added and modified paths to the receiver",
+		},
+		{
+			name:           "bad signature",
+			event:          "push",
+			body:           push,
+			signature:      sign(push + " "),
+			expectedStatus: http.StatusUnauthorized,
+			description:    "should reject deliveries not signed with the secret",
+		},
+		{
+			name:           "missing signature",
+			event:          "push",
+			body:           push,
+			expectedStatus: http.StatusUnauthorized,
+			description:    "should reject unsigned deliveries",
+		},
+		{
+			name:           "ping",
+			event:          "ping",
+			body:           `{"zen": "Keep it logically awesome."}`,
+			signature:      sign(`{"zen": "Keep it logically awesome."}`),
+			expectedStatus: http.StatusNoContent,
+			description:    "should acknowledge other events without enqueueing",
+		},
+		{
+			name:           "tag push",
+			event:          "push",
+			body:           `{"ref": "refs/tags/v1.0.0", "repository": {"full_name": "owner/repo"}}`,
+			signature:      sign(`{"ref": "refs/tags/v1.0.0", "repository": {"full_name": "owner/repo"}}`),
+			expectedStatus: http.StatusNoContent,
+			description:    "should ignore pushes that are not to a branch",
+		},
+		{
+			name:           "malformed payload",
+			event:          "push",
+			body:           `{"ref": `,
+			signature:      sign(`{"ref": `),
+			expectedStatus: http.StatusBadRequest,
+			description:    "should reject payloads that are not push events",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			receiver := &stubPushReceiver{}
+			srv := NewServer(&stubSearcher{})
+			srv.HandleGitHubWebhook(testWebhookSecret, receiver)
+
+			request := httptest.NewRequest(http.MethodPost, "/webhooks/github", strings.NewReader(tt.body))
+			request.Header.Set("X-GitHub-Event", tt.event)
+			if tt.signature != "" {
+				request.Header.Set("X-Hub-Signature-256", tt.signature)
+			}
+			recorder := httptest.NewRecorder()
+			srv.Handler().ServeHTTP(recorder, request)
+
+			if recorder.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d for test: %s", tt.expectedStatus, recorder.Code, tt.description)
+			}
+			if receiver.calls != tt.expectedCalls {
+				t.Errorf("Expected %d receiver calls, got %d for test: %s", tt.expectedCalls, receiver.calls,
+					tt.description)
+			}
+			if tt.expectedCalls == 0 {
+				return
+			}
+
+			if receiver.repository != "owner/repo" || receiver.branch != "main" {
+				t.Errorf("Expected owner/repo at main, got %s at %s", receiver.repository, receiver.branch)
+			}
+			expectedPaths := []string{"docs/new.md", "README.md", "src/main.go"}
+			if !reflect.DeepEqual(receiver.paths, expectedPaths) {
+				t.Errorf("Expected paths %v, got %v", expectedPaths, receiver.paths)
+			}
+
+			var response WebhookResponse
+			if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+				t.Fatalf("Expected JSON response, got %v", err)
+			}
+			if len(response.Jobs) != 1 || response.Jobs[0] != "job-1" {
+				t.Errorf("Expected the enqueued job in the response, got %v", response.Jobs)
+			}
+		})
+	}
+}
+
+type stubPostReceiver struct {
+	calls    int
+	endpoint string
+	postID   int
+}
+
+func (r *stubPostReceiver) WordPressPost(_ context.Context, endpoint string, postID int) ([]string, error) {
+	r.calls++
+	r.endpoint = endpoint
+	r.postID = postID
+	return []string{"job-1"}, nil
+}
+
+func TestHandleWordPressWebhook(t *testing.T) {
+	update := `{"endpoint": "https://example.com/wp-json/wp/v2/posts", "post_id": 42, "status": "publish"}`
+
+	tests := []struct {
+		name           string
+		body           string
+		signature      string
+		expectedStatus int
+		expectedCalls  int
+		description    string
+	}{
+		{
+			name:           "post updated",
+			body:           update,
+			signature:      sign(update),
+			expectedStatus: http.StatusAccepted,
+			expectedCalls:  1,
+			description:    "should pass the endpoint and post ID to the receiver",
+		},
+		{
+			name:           "bad signature",
+			body:           update,
+			signature:      "sha256=00",
+			expectedStatus: http.StatusUnauthorized,
+			description:    "should reject deliveries not signed with the secret",
+		},
+		{
+			name:           "not a WP-JSON endpoint",
+			body:           `{"endpoint": "https://example.com/posts", "post_id": 42}`,
+			signature:      sign(`{"endpoint": "https://example.com/posts", "post_id": 42}`),
+			expectedStatus: http.StatusBadRequest,
+			description:    "should reject endpoints the WP-JSON importer cannot import",
+		},
+		{
+			name:           "missing post ID",
+			body:           `{"endpoint": "https://example.com/wp-json/wp/v2/posts"}`,
+			signature:      sign(`{"endpoint": "https://example.com/wp-json/wp/v2/posts"}`),
+			expectedStatus: http.StatusBadRequest,
+			description:    "should require a post ID",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			receiver := &stubPostReceiver{}
+			srv := NewServer(&stubSearcher{})
+			srv.HandleWordPressWebhook(testWebhookSecret, receiver)
+
+			request := httptest.NewRequest(http.MethodPost, "/webhooks/wordpress", strings.NewReader(tt.body))
+			request.Header.Set("X-Webhook-Signature", tt.signature)
+			recorder := httptest.NewRecorder()
+			srv.Handler().ServeHTTP(recorder, request)
+
+			if recorder.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d for test: %s", tt.expectedStatus, recorder.Code, tt.description)
+			}
+			if receiver.calls != tt.expectedCalls {
+				t.Errorf("Expected %d receiver calls, got %d for test: %s", tt.expectedCalls, receiver.calls,
+					tt.description)
+			}
+			if tt.expectedCalls > 0 &&
+				(receiver.endpoint != "https://example.com/wp-json/wp/v2/posts" || receiver.postID != 42) {
+				t.Errorf("Expected post 42 of the posts endpoint, got %d of %s", receiver.postID, receiver.endpoint)
+			}
+		})
+	}
+}
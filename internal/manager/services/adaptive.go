@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
+)
+
+const (
+	// decreaseInterval is the least time between two cuts of the limit, so the 429s of requests
+	// that were already in flight when the first arrived only count once.
+	decreaseInterval = time.Second
+	// lowRemainingShare is the share of a provider's request window below which the limit stops
+	// growing and is lowered by one instead.
+	lowRemainingShare = 0.1
+)
+
+// adaptiveLimit bounds how many embedding requests run at once across the engine and adapts the
+// bound to the provider's rate limits: it is halved when a request is throttled, lowered by one
+// when the provider's rate-limit headers show the window nearly used up, and raised by one after
+// a full round of requests succeeds, up to the maximum set with SetEmbeddingLimit. A static
+// Concurrency that is too high for the provider's limits is thereby brought down to what it
+// accepts, and raised again once the pressure is gone.
+type adaptiveLimit struct {
+	mu        sync.Mutex
+	max       int
+	limit     int
+	inFlight  int
+	successes int
+	lastCut   time.Time
+	// released is closed and replaced whenever a request may start, to wake waiting callers
+	released chan struct{}
+	now      func() time.Time
+}
+
+func newAdaptiveLimit(maxLimit int) *adaptiveLimit {
+	maxLimit = max(maxLimit, 1)
+	embedConcurrency.With().Set(float64(maxLimit))
+	return &adaptiveLimit{
+		max:      maxLimit,
+		limit:    maxLimit,
+		released: make(chan struct{}),
+		now:      time.Now,
+	}
+}
+
+// Acquire blocks until a request may start or ctx is done.
+func (l *adaptiveLimit) Acquire(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		if l.inFlight < l.limit {
+			l.inFlight++
+			l.mu.Unlock()
+			return nil
+		}
+		released := l.released
+		l.mu.Unlock()
+
+		select {
+		case <-released:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Release ends a request started with Acquire.
+func (l *adaptiveLimit) Release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inFlight--
+	l.wake()
+}
+
+// Limit returns how many requests may currently run at once.
+func (l *adaptiveLimit) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.limit
+}
+
+// Observe adapts the limit to a response; it is the RateLimitFunc the engine gives embedders and
+// is called while the request is still in flight. Cuts start from the requests in flight rather
+// than the limit, which callers with a lower Concurrency option may never reach, and the limit
+// only grows while it is what holds requests back.
+func (l *adaptiveLimit) Observe(event interfaces.RateLimitEvent) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	current := min(l.limit, l.inFlight)
+	lowRemaining := event.Limit > 0 && float64(event.Remaining) < float64(event.Limit)*lowRemainingShare
+	switch {
+	case event.Throttled:
+		l.cut(current / 2)
+	case lowRemaining:
+		l.cut(current - 1)
+	case l.inFlight >= l.limit && l.limit < l.max:
+		l.successes++
+		if l.successes >= l.limit {
+			l.successes = 0
+			l.setLimit(l.limit + 1)
+			l.wake()
+		}
+	}
+}
+
+// cut lowers the limit to limit, at least 1, unless it was already cut within decreaseInterval.
+func (l *adaptiveLimit) cut(limit int) {
+	l.successes = 0
+	now := l.now()
+	if now.Sub(l.lastCut) < decreaseInterval {
+		return
+	}
+	l.lastCut = now
+	l.setLimit(max(limit, 1))
+}
+
+func (l *adaptiveLimit) setLimit(limit int) {
+	l.limit = limit
+	embedConcurrency.With().Set(float64(limit))
+}
+
+// wake lets the callers waiting in Acquire check the limit again. l.mu must be held.
+func (l *adaptiveLimit) wake() {
+	close(l.released)
+	l.released = make(chan struct{})
+}
@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
+)
+
+// acquireN takes n slots of l, failing the test if one is not free.
+func acquireN(t *testing.T, l *adaptiveLimit, n int) {
+	t.Helper()
+	for range n {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		err := l.Acquire(ctx)
+		cancel()
+		if err != nil {
+			t.Fatalf("Failed to acquire slot: %v", err)
+		}
+	}
+}
+
+func TestAdaptiveLimit_Observe(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	limit := newAdaptiveLimit(8)
+	limit.now = func() time.Time { return now }
+	acquireN(t, limit, 8)
+
+	limit.Observe(interfaces.RateLimitEvent{Throttled: true})
+	if got := limit.Limit(); got != 4 {
+		t.Errorf("Expected a throttled request to halve the limit to 4, got %d", got)
+	}
+
+	// The other requests in flight when the first 429 arrived do not cut the limit again
+	limit.Observe(interfaces.RateLimitEvent{Throttled: true})
+	if got := limit.Limit(); got != 4 {
+		t.Errorf("Expected the limit to be cut once within the cooldown, got %d", got)
+	}
+
+	now = now.Add(decreaseInterval)
+	limit.Observe(interfaces.RateLimitEvent{Limit: 100, Remaining: 5})
+	if got := limit.Limit(); got != 3 {
+		t.Errorf("Expected a nearly used-up window to lower the limit to 3, got %d", got)
+	}
+
+	for range 3 {
+		limit.Observe(interfaces.RateLimitEvent{Limit: 100, Remaining: 50})
+	}
+	if got := limit.Limit(); got != 4 {
+		t.Errorf("Expected a full round of successes to raise the limit to 4, got %d", got)
+	}
+
+	for range 100 {
+		limit.Observe(interfaces.RateLimitEvent{})
+	}
+	if got := limit.Limit(); got != 8 {
+		t.Errorf("Expected the limit to grow back to its maximum of 8, got %d", got)
+	}
+}
+
+func TestAdaptiveLimit_CutsFromInFlight(t *testing.T) {
+	limit := newAdaptiveLimit(32)
+	acquireN(t, limit, 6)
+
+	limit.Observe(interfaces.RateLimitEvent{Throttled: true})
+	if got := limit.Limit(); got != 3 {
+		t.Errorf("Expected the limit to be half the 6 requests in flight, got %d", got)
+	}
+	for range 4 {
+		limit.Release()
+	}
+
+	// Growing the limit is pointless while fewer requests than it allows are running
+	for range 10 {
+		limit.Observe(interfaces.RateLimitEvent{})
+	}
+	if got := limit.Limit(); got != 3 {
+		t.Errorf("Expected the limit to stay at 3 while it is not reached, got %d", got)
+	}
+}
+
+func TestAdaptiveLimit_Acquire(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	limit := newAdaptiveLimit(2)
+	limit.now = func() time.Time { return now }
+	acquireN(t, limit, 2)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := limit.Acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected Acquire to block while the limit is reached, got %v", err)
+	}
+
+	acquired := make(chan error, 1)
+	go func() {
+		acquired <- limit.Acquire(context.Background())
+	}()
+
+	// After a cut to 1, one release still leaves the limit reached
+	limit.Observe(interfaces.RateLimitEvent{Throttled: true})
+	limit.Release()
+	select {
+	case err := <-acquired:
+		t.Fatalf("Expected Acquire to wait for the lowered limit, got %v", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	limit.Release()
+	select {
+	case err := <-acquired:
+		if err != nil {
+			t.Fatalf("Expected Acquire to succeed, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected Acquire to return once a slot was released")
+	}
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
+)
+
+// defaultSourceConcurrency is how many sources ProcessSources runs at once by default. Each
+// source already embeds its chunks concurrently, so a small pool is enough.
+const defaultSourceConcurrency = 2
+
+var ErrBatchIncomplete = errors.New("batch completed with failed sources")
+
+// ProcessSources runs ProcessSource for every distinct URL in sourceURLs with a bounded pool of
+// workers and reports the outcome of each. A failing source does not stop the others; the
+// returned error summarizes the failures, while the report holds each source's own error.
+func (e *ProcessingEngine) ProcessSources(
+	ctx context.Context,
+	sourceURLs []string,
+	options *interfaces.ProcessingOptions,
+	db *sql.DB,
+) (*interfaces.BatchReport, error) {
+	urls := dedupeURLs(sourceURLs)
+	report := &interfaces.BatchReport{Results: make([]interfaces.SourceResult, len(urls))}
+
+	ctx, done, err := e.begin(ctx)
+	if err != nil {
+		for i, url := range urls {
+			report.Results[i] = interfaces.SourceResult{SourceURL: url, Err: err}
+		}
+		report.Failed = len(urls)
+		return report, err
+	}
+	defer done()
+
+	workers := defaultSourceConcurrency
+	if options != nil && options.SourceConcurrency > 0 {
+		workers = options.SourceConcurrency
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range min(workers, len(urls)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				start := time.Now()
+				err := e.processSource(ctx, urls[i], options, db)
+				report.Results[i] = interfaces.SourceResult{SourceURL: urls[i], Err: err, Duration: time.Since(start)}
+			}
+		}()
+	}
+
+	// Sources not started before cancellation or shutdown are reported with the reason
+	for i, url := range urls {
+		if e.stopping() {
+			report.Results[i] = interfaces.SourceResult{SourceURL: url, Err: ErrShuttingDown}
+			continue
+		}
+		if ctx.Err() != nil {
+			report.Results[i] = interfaces.SourceResult{SourceURL: url, Err: context.Cause(ctx)}
+			continue
+		}
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			report.Results[i] = interfaces.SourceResult{SourceURL: url, Err: context.Cause(ctx)}
+		case <-e.closing:
+			report.Results[i] = interfaces.SourceResult{SourceURL: url, Err: ErrShuttingDown}
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	var firstErr error
+	for _, result := range report.Results {
+		if result.Err == nil {
+			report.Succeeded++
+			continue
+		}
+		report.Failed++
+		if firstErr == nil {
+			firstErr = fmt.Errorf("%s: %w", result.SourceURL, result.Err)
+		}
+	}
+
+	e.logger.Info().
+		Int("source_count", len(urls)).
+		Int("succeeded", report.Succeeded).
+		Int("failed", report.Failed).
+		Msg("Batch processing finished")
+
+	if report.Failed > 0 {
+		return report, fmt.Errorf("%w: %d of %d sources failed, first error: %w", ErrBatchIncomplete,
+			report.Failed, len(urls), firstErr)
+	}
+	return report, nil
+}
+
+// dedupeURLs drops blank and repeated URLs, keeping the first occurrence of each.
+func dedupeURLs(sourceURLs []string) []string {
+	seen := make(map[string]bool, len(sourceURLs))
+	urls := make([]string, 0, len(sourceURLs))
+	for _, url := range sourceURLs {
+		url = strings.TrimSpace(url)
+		if url == "" || seen[url] {
+			continue
+		}
+		seen[url] = true
+		urls = append(urls, url)
+	}
+	return urls
+}
@@ -0,0 +1,57 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
+)
+
+func TestDedupeURLs(t *testing.T) {
+	urls := dedupeURLs([]string{
+		"https://a.example", " https://a.example ", "", "https://b.example", "https://a.example",
+	})
+
+	expected := []string{"https://a.example", "https://b.example"}
+	if !reflect.DeepEqual(urls, expected) {
+		t.Errorf("Expected %v, got %v", expected, urls)
+	}
+}
+
+// Test that ProcessSources reports every source, in order, when they fail before touching the
+// database.
+func TestProcessingEngine_ProcessSources(t *testing.T) {
+	engine := NewProcessingEngine()
+	options := &interfaces.ProcessingOptions{SourceConcurrency: 3}
+
+	report, err := engine.ProcessSources(context.Background(),
+		[]string{"https://a.example", "https://b.example", "https://a.example"}, options, nil)
+
+	if !errors.Is(err, ErrBatchIncomplete) || !errors.Is(err, ErrNoImporterCanHandle) {
+		t.Errorf("Expected ErrBatchIncomplete wrapping ErrNoImporterCanHandle, got %v", err)
+	}
+	if len(report.Results) != 2 || report.Failed != 2 || report.Succeeded != 0 {
+		t.Fatalf("Expected 2 failed results, got %+v", report)
+	}
+	for i, url := range []string{"https://a.example", "https://b.example"} {
+		if report.Results[i].SourceURL != url || !errors.Is(report.Results[i].Err, ErrNoImporterCanHandle) {
+			t.Errorf("Expected result %d for %s to fail with ErrNoImporterCanHandle, got %+v", i, url,
+				report.Results[i])
+		}
+	}
+}
+
+func TestProcessingEngine_ProcessSources_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	report, err := NewProcessingEngine().ProcessSources(ctx, []string{"https://a.example"}, nil, nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if report.Failed != 1 || !errors.Is(report.Results[0].Err, context.Canceled) {
+		t.Errorf("Expected the unstarted source to report cancellation, got %+v", report.Results)
+	}
+}
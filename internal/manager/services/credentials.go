@@ -0,0 +1,75 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
+	"github.com/code-sleuth/ike-go/pkg/util"
+)
+
+// applySourceCredentials returns ctx carrying the credentials stored for the longest
+// source_credentials URL that rawURL falls under. Credentials ctx already carries, such as those a
+// caller passed for the import, win over stored ones.
+func (e *ProcessingEngine) applySourceCredentials(
+	ctx context.Context,
+	db *sql.DB,
+	rawURL string,
+) (context.Context, error) {
+	query := `SELECT source_url, github_token, openai_api_key, together_api_key
+			  FROM source_credentials WHERE SUBSTR(CAST(? AS TEXT), 1, LENGTH(source_url)) = source_url`
+	rows, err := db.QueryContext(ctx, e.dialect.Rebind(query), rawURL)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	bestURL := ""
+	var best *interfaces.Credentials
+	for rows.Next() {
+		var sourceURL string
+		var githubToken, openAIAPIKey, togetherAPIKey sql.NullString
+		if err := rows.Scan(&sourceURL, &githubToken, &openAIAPIKey, &togetherAPIKey); err != nil {
+			return nil, err
+		}
+		if util.UnderURL(rawURL, sourceURL) && (best == nil || len(sourceURL) > len(bestURL)) {
+			bestURL = sourceURL
+			best = &interfaces.Credentials{
+				GitHubToken:    githubToken.String,
+				OpenAIAPIKey:   openAIAPIKey.String,
+				TogetherAPIKey: togetherAPIKey.String,
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if best == nil {
+		return ctx, nil
+	}
+	e.logger.Debug().Str("url", rawURL).Str("credentials_url", bestURL).Msg("Applying source credentials")
+	return interfaces.WithCredentials(ctx, best.Merge(interfaces.CredentialsFromContext(ctx))), nil
+}
+
+// sourceFetchURL returns the URL to fetch the source stored under sourceURL from, with the
+// credentials stripped from it when it was stored put back. Sources stored with their credentials
+// replaced by util.Redacted can't be fetched again, since the server would get the placeholder.
+func (e *ProcessingEngine) sourceFetchURL(ctx context.Context, db *sql.DB, sourceURL string) (string, error) {
+	if util.IsRedactedURL(sourceURL) {
+		return "", fmt.Errorf("%w: import %s again from its URL with credentials", ErrRedactedSourceURL, sourceURL)
+	}
+
+	var secrets sql.NullString
+	query := `SELECT url_secrets FROM source_credentials WHERE source_url = ?`
+	err := db.QueryRowContext(ctx, e.dialect.Rebind(query), sourceURL).Scan(&secrets)
+	if errors.Is(err, sql.ErrNoRows) {
+		return sourceURL, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return util.JoinURLSecrets(sourceURL, secrets.String)
+}
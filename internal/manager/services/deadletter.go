@@ -0,0 +1,133 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/code-sleuth/ike-go/internal/manager/models"
+)
+
+// FailedChunk is a dead-lettered chunk that could not be embedded or saved.
+type FailedChunk struct {
+	Chunk    models.Chunk `json:"chunk"`
+	Model    string       `json:"model"`
+	Error    string       `json:"error"`
+	Attempts int          `json:"attempts"`
+}
+
+// RetryReport summarizes a RetryFailedChunks pass.
+type RetryReport struct {
+	Retried   int `json:"retried"`
+	Succeeded int `json:"succeeded"`
+	Failed    int `json:"failed"`
+}
+
+// recordFailedChunk stores chunk in failed_chunks with the error that stopped it. Recording a
+// chunk that is already dead-lettered refreshes its error.
+func (e *ProcessingEngine) recordFailedChunk(
+	ctx context.Context,
+	chunk *models.Chunk,
+	model string,
+	cause error,
+	db *sql.DB,
+) error {
+	data, err := json.Marshal(chunk)
+	if err != nil {
+		return err
+	}
+
+	now := e.dialect.FormatTime(time.Now())
+	query := e.dialect.Upsert("failed_chunks",
+		[]string{"chunk_id", "document_id", "chunk", "model", "error", "created_at", "updated_at"},
+		[]string{"chunk_id"},
+		[]string{"chunk", "model", "error", "updated_at"})
+
+	// Recording ignores cancellation so chunks interrupted by a cancelled run are not lost
+	_, err = db.ExecContext(context.WithoutCancel(ctx), e.dialect.Rebind(query), chunk.ID, chunk.DocumentID,
+		string(data), model, cause.Error(), now, now)
+	return err
+}
+
+// FailedChunks returns up to limit dead-lettered chunks, oldest first; limit <= 0 returns all.
+func (e *ProcessingEngine) FailedChunks(ctx context.Context, db *sql.DB, limit int) ([]FailedChunk, error) {
+	query := `SELECT chunk, model, error, attempts FROM failed_chunks ORDER BY created_at, chunk_id`
+	args := []interface{}{}
+	if limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, limit)
+	}
+
+	rows, err := db.QueryContext(ctx, e.dialect.Rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var failed []FailedChunk
+	for rows.Next() {
+		var chunk string
+		var fc FailedChunk
+		if err := rows.Scan(&chunk, &fc.Model, &fc.Error, &fc.Attempts); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(chunk), &fc.Chunk); err != nil {
+			return nil, err
+		}
+		failed = append(failed, fc)
+	}
+
+	return failed, rows.Err()
+}
+
+// RetryFailedChunks re-processes up to limit dead-lettered chunks with the embedder each was
+// originally embedded with. Chunks that succeed leave failed_chunks; chunks that fail again
+// stay with their new error and attempt count.
+func (e *ProcessingEngine) RetryFailedChunks(ctx context.Context, db *sql.DB, limit int) (*RetryReport, error) {
+	failed, err := e.FailedChunks(ctx, db, limit)
+	if err != nil {
+		e.logger.Error().Err(err).Msg("Failed to load failed chunks")
+		return nil, err
+	}
+
+	report := &RetryReport{}
+	for _, fc := range failed {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+
+		chunk := fc.Chunk
+		report.Retried++
+
+		embedder, cause := e.embedderFor(fc.Model)
+		if cause == nil {
+			cause = e.processChunk(ctx, &chunk, embedder, db).Error
+		}
+
+		if cause == nil {
+			report.Succeeded++
+			_, err := db.ExecContext(ctx, e.dialect.Rebind(`DELETE FROM failed_chunks WHERE chunk_id = ?`), chunk.ID)
+			if err != nil {
+				return report, err
+			}
+			continue
+		}
+
+		report.Failed++
+		e.logger.Error().Err(cause).Str("chunk_id", chunk.ID).Msg("Retry of failed chunk failed")
+		query := `UPDATE failed_chunks SET error = ?, attempts = attempts + 1, updated_at = ? WHERE chunk_id = ?`
+		_, err := db.ExecContext(ctx, e.dialect.Rebind(query), cause.Error(), e.dialect.FormatTime(time.Now()),
+			chunk.ID)
+		if err != nil {
+			return report, err
+		}
+	}
+
+	if report.Failed > 0 {
+		return report, fmt.Errorf("%w: %d of %d retried chunks failed again", ErrChunkProcessingFailed,
+			report.Failed, report.Retried)
+	}
+	return report, nil
+}
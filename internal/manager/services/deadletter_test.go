@@ -0,0 +1,49 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/code-sleuth/ike-go/internal/manager/models"
+)
+
+// Test that processChunk reports failures before anything is saved, so the chunk can be
+// dead-lettered and retried later.
+func TestProcessingEngine_processChunk_Failures(t *testing.T) {
+	embedErr := errors.New("rate limited")
+
+	tests := []struct {
+		name        string
+		embedder    *mockEmbedder
+		expectedErr error
+		description string
+	}{
+		{
+			name:        "embedding error",
+			embedder:    &mockEmbedder{modelName: "text-embedding-3-small", dimension: 1536, embedError: embedErr},
+			expectedErr: embedErr,
+			description: "should return the embedder's error",
+		},
+		{
+			name:        "unsupported dimension",
+			embedder:    &mockEmbedder{modelName: "odd-model", dimension: 999, embedding: make([]float32, 999)},
+			expectedErr: ErrUnsupportedEmbeddingDim,
+			description: "should reject unsupported embedding dimensions",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunk := &models.Chunk{ID: "chunk-1", DocumentID: "doc-1", Body: stringPtr("content")}
+
+			result := NewProcessingEngine().processChunk(context.Background(), chunk, tt.embedder, nil)
+			if !errors.Is(result.Error, tt.expectedErr) {
+				t.Errorf("Expected error %v, got %v for test: %s", tt.expectedErr, result.Error, tt.description)
+			}
+			if result.Chunk.ID != "chunk-1" {
+				t.Errorf("Expected the chunk ID to be kept for retries, got %s", result.Chunk.ID)
+			}
+		})
+	}
+}
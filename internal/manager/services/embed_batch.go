@@ -0,0 +1,188 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"iter"
+	"slices"
+
+	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
+	"github.com/code-sleuth/ike-go/internal/manager/models"
+	"github.com/code-sleuth/ike-go/pkg/tracing"
+
+	"github.com/google/uuid"
+)
+
+// batchChunks splits chunks, in order, into batches of at most the engine's batch size whose
+// tokens add up to at most its token budget. A chunk over the budget by itself is batched alone.
+func (e *ProcessingEngine) batchChunks(chunks []*models.Chunk) [][]*models.Chunk {
+	return slices.Collect(e.batchStream(slices.Values(chunks)))
+}
+
+// batchStream is batchChunks for chunks handed over one at a time; each batch is handed over as
+// soon as it is full.
+func (e *ProcessingEngine) batchStream(chunks iter.Seq[*models.Chunk]) iter.Seq[[]*models.Chunk] {
+	e.mu.RLock()
+	size, budget := e.batchSize, e.batchTokens
+	e.mu.RUnlock()
+
+	return func(yield func([]*models.Chunk) bool) {
+		var batch []*models.Chunk
+		tokens := 0
+		for chunk := range chunks {
+			count := chunkTokens(chunk)
+			if len(batch) > 0 && (len(batch) >= size || (budget > 0 && tokens+count > budget)) {
+				if !yield(batch) {
+					return
+				}
+				batch, tokens = nil, 0
+			}
+			batch = append(batch, chunk)
+			tokens += count
+		}
+		if len(batch) > 0 {
+			yield(batch)
+		}
+	}
+}
+
+// chunkTokens returns the chunk's token count, estimated at four bytes a token when the chunker
+// did not count them.
+func chunkTokens(chunk *models.Chunk) int {
+	if chunk.TokenCount != nil {
+		return *chunk.TokenCount
+	}
+	if chunk.Body == nil {
+		return 0
+	}
+	return (len(*chunk.Body) + 3) / 4
+}
+
+// embedBatch embeds batch with one request to embedder once a slot of the shared embedding pool
+// is free, and saves each chunk with its embedding. A failed request fails every chunk of the
+// batch, and each is dead-lettered as if embedded alone.
+func (e *ProcessingEngine) embedBatch(
+	ctx context.Context,
+	batch []*models.Chunk,
+	documentID string,
+	embedder interfaces.Embedder,
+	batchEmbedder interfaces.BatchEmbedder,
+	db *sql.DB,
+) []*interfaces.ChunkResult {
+	results := make([]*interfaces.ChunkResult, len(batch))
+	for i, chunk := range batch {
+		chunk.DocumentID = documentID
+		if chunk.ID == "" {
+			chunk.ID = uuid.New().String()
+		}
+		results[i] = &interfaces.ChunkResult{Chunk: chunk}
+	}
+
+	e.mu.RLock()
+	slots := e.embedSlots
+	e.mu.RUnlock()
+
+	if err := slots.Acquire(ctx); err != nil {
+		for _, result := range results {
+			result.Error = err
+		}
+		return results
+	}
+	defer slots.Release()
+	ctx = interfaces.WithRateLimitObserver(ctx, slots.Observe)
+
+	embedCtx, span := tracing.Start(ctx, spanEmbed, tracing.String("document.id", documentID),
+		tracing.String("embedding.model", embedder.GetModelName()), tracing.Int("chunk.count", len(batch)))
+	embedCtx, cancel := withStageTimeout(embedCtx, interfaces.HookEmbed, embedTimeout(ctx))
+	embeddings, errs := e.embedChunks(embedCtx, batch, embedder, batchEmbedder)
+	for i, result := range results {
+		result.Embedding, result.Error = embeddings[i], stageError(embedCtx, errs[i])
+	}
+	cancel()
+	endSpan(span, firstError(results))
+
+	// Save each chunk together with its embedding
+	for _, result := range results {
+		if result.Error != nil {
+			continue
+		}
+		saveCtx, span := tracing.Start(ctx, spanSave, tracing.String("chunk.id", result.Chunk.ID),
+			tracing.String("document.id", documentID))
+		if err := e.saveChunkAndEmbedding(saveCtx, result.Chunk, result.Embedding, db); err != nil {
+			e.logger.Error().Err(err).Str("chunk_id", result.Chunk.ID).Msg("Failed to save chunk and embedding")
+			result.Error = err
+		}
+		endSpan(span, result.Error)
+	}
+	return results
+}
+
+// embedChunks is embed for a batch of chunks: the embed hooks run around each chunk, and the
+// bodies of those the before hooks let through are embedded with one request.
+func (e *ProcessingEngine) embedChunks(
+	ctx context.Context,
+	chunks []*models.Chunk,
+	embedder interfaces.Embedder,
+	batchEmbedder interfaces.BatchEmbedder,
+) ([]*models.Embedding, []error) {
+	embeddings := make([]*models.Embedding, len(chunks))
+	errs := make([]error, len(chunks))
+	hooks := make([]*interfaces.HookPayload, len(chunks))
+
+	var pending []int
+	var bodies []string
+	for i, chunk := range chunks {
+		hooks[i] = &interfaces.HookPayload{Stage: interfaces.HookEmbed, Phase: interfaces.HookBefore, Chunk: chunk}
+		if err := e.runHooks(ctx, hooks[i]); err != nil {
+			errs[i] = err
+			continue
+		}
+		switch {
+		case chunk.Body == nil:
+			// A chunk without a body has no embedding
+		case *chunk.Body == "":
+			// Embedders reject empty content, which would fail the whole batch; only this chunk fails
+			embeddings[i], errs[i] = e.embedVector(ctx, chunk, embedder)
+		default:
+			pending = append(pending, i)
+			bodies = append(bodies, *chunk.Body)
+		}
+	}
+
+	if len(bodies) > 0 {
+		vectors, err := batchEmbedder.GenerateEmbeddings(ctx, bodies)
+		if err == nil && len(vectors) != len(bodies) {
+			err = fmt.Errorf("%w: %d embeddings for %d chunks", ErrEmbeddingCountMismatch, len(vectors), len(bodies))
+		}
+		for n, i := range pending {
+			if err != nil {
+				errs[i] = fmt.Errorf("embedding generation failed: %w", err)
+				continue
+			}
+			embeddings[i], errs[i] = e.newEmbedding(chunks[i], embedder, vectors[n])
+		}
+	}
+
+	for i := range chunks {
+		if errs[i] != nil {
+			embeddings[i] = nil
+			continue
+		}
+		hooks[i].Phase, hooks[i].Embedding = interfaces.HookAfter, embeddings[i]
+		if err := e.runHooks(ctx, hooks[i]); err != nil {
+			embeddings[i], errs[i] = nil, err
+		}
+	}
+	return embeddings, errs
+}
+
+// firstError returns the first error of results, or nil.
+func firstError(results []*interfaces.ChunkResult) error {
+	for _, result := range results {
+		if result.Error != nil {
+			return result.Error
+		}
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
+	"github.com/code-sleuth/ike-go/internal/manager/models"
+)
+
+// batchEmbedder is a mockEmbedder that also embeds batches, recording the size of each.
+type batchEmbedder struct {
+	mockEmbedder
+	batches []int
+	// count, when set, is how many embeddings a batch request returns
+	count int
+}
+
+func (b *batchEmbedder) GenerateEmbeddings(_ context.Context, contents []string) ([][]float32, error) {
+	b.batches = append(b.batches, len(contents))
+	if b.embedError != nil {
+		return nil, b.embedError
+	}
+	count := len(contents)
+	if b.count > 0 {
+		count = b.count
+	}
+	vectors := make([][]float32, count)
+	for i := range vectors {
+		vectors[i] = b.embedding
+	}
+	return vectors, nil
+}
+
+func TestProcessingEngine_batchChunks(t *testing.T) {
+	chunk := func(tokens int) *models.Chunk {
+		return &models.Chunk{TokenCount: &tokens}
+	}
+	body := strings.Repeat("x", 400)
+
+	tests := []struct {
+		name     string
+		size     int
+		tokens   int
+		chunks   []*models.Chunk
+		expected []int
+	}{
+		{name: "by size", size: 2, tokens: 1000,
+			chunks: []*models.Chunk{chunk(1), chunk(1), chunk(1), chunk(1), chunk(1)}, expected: []int{2, 2, 1}},
+		{name: "by tokens", size: 10, tokens: 100,
+			chunks: []*models.Chunk{chunk(60), chunk(30), chunk(20), chunk(100)}, expected: []int{2, 1, 1}},
+		{name: "chunk over the budget alone", size: 10, tokens: 100,
+			chunks: []*models.Chunk{chunk(10), chunk(150), chunk(10)}, expected: []int{1, 1, 1}},
+		{name: "tokens estimated from body", size: 10, tokens: 150,
+			chunks: []*models.Chunk{{Body: &body}, {Body: &body}}, expected: []int{1, 1}},
+		{name: "no token budget", size: 3, tokens: 0,
+			chunks: []*models.Chunk{chunk(1000), chunk(1000), chunk(1000)}, expected: []int{3}},
+		{name: "no chunks", size: 3, tokens: 100, expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := NewProcessingEngine()
+			engine.SetEmbeddingBatch(tt.size, tt.tokens)
+
+			batches := engine.batchChunks(tt.chunks)
+			if len(batches) != len(tt.expected) {
+				t.Fatalf("Expected %d batches, got %d", len(tt.expected), len(batches))
+			}
+			next := 0
+			for i, batch := range batches {
+				if len(batch) != tt.expected[i] {
+					t.Errorf("Expected batch %d to hold %d chunks, got %d", i, tt.expected[i], len(batch))
+				}
+				for _, c := range batch {
+					if c != tt.chunks[next] {
+						t.Errorf("Expected chunks to stay in order")
+					}
+					next++
+				}
+			}
+		})
+	}
+}
+
+func TestProcessingEngine_embedChunks(t *testing.T) {
+	body := func(s string) *string { return &s }
+	newChunks := func() []*models.Chunk {
+		return []*models.Chunk{
+			{ID: "a", Body: body("first")},
+			{ID: "b"},
+			{ID: "c", Body: body("third")},
+			{ID: "d", Body: body("")},
+		}
+	}
+	embedding := make([]float32, embeddingDim768)
+
+	t.Run("one request for the batch", func(t *testing.T) {
+		engine := NewProcessingEngine()
+		embedder := &batchEmbedder{mockEmbedder: mockEmbedder{
+			modelName: "batch", dimension: embeddingDim768, embedding: embedding,
+		}}
+		chunks := newChunks()
+
+		embeddings, errs := engine.embedChunks(context.Background(), chunks, embedder, embedder)
+		if len(embedder.batches) != 1 || embedder.batches[0] != 2 {
+			t.Errorf("Expected one request for the 2 chunks with bodies, got %v", embedder.batches)
+		}
+		for _, i := range []int{0, 2} {
+			if errs[i] != nil || embeddings[i] == nil || embeddings[i].ObjectID != chunks[i].ID {
+				t.Errorf("Expected chunk %s to be embedded, got %v, %v", chunks[i].ID, embeddings[i], errs[i])
+			}
+		}
+		if errs[1] != nil || embeddings[1] != nil {
+			t.Errorf("Expected a chunk without a body to have no embedding, got %v, %v", embeddings[1], errs[1])
+		}
+		// The empty body was embedded with a request of its own, so it could not fail the batch
+		if errs[3] != nil || embeddings[3] == nil {
+			t.Errorf("Expected the empty chunk to be embedded alone, got %v, %v", embeddings[3], errs[3])
+		}
+	})
+
+	t.Run("failed request fails every chunk", func(t *testing.T) {
+		engine := NewProcessingEngine()
+		failure := errors.New("provider down")
+		embedder := &batchEmbedder{mockEmbedder: mockEmbedder{
+			modelName: "batch", dimension: embeddingDim768, embedError: failure,
+		}}
+		chunks := newChunks()[:3]
+
+		embeddings, errs := engine.embedChunks(context.Background(), chunks, embedder, embedder)
+		for _, i := range []int{0, 2} {
+			if !errors.Is(errs[i], failure) || embeddings[i] != nil {
+				t.Errorf("Expected chunk %s to fail with the request, got %v", chunks[i].ID, errs[i])
+			}
+		}
+		if errs[1] != nil {
+			t.Errorf("Expected a chunk without a body not to be sent, got %v", errs[1])
+		}
+	})
+
+	t.Run("missing embeddings", func(t *testing.T) {
+		engine := NewProcessingEngine()
+		embedder := &batchEmbedder{mockEmbedder: mockEmbedder{
+			modelName: "batch", dimension: embeddingDim768, embedding: embedding,
+		}, count: 1}
+		chunks := newChunks()[:3]
+
+		_, errs := engine.embedChunks(context.Background(), chunks, embedder, embedder)
+		if !errors.Is(errs[0], ErrEmbeddingCountMismatch) || !errors.Is(errs[2], ErrEmbeddingCountMismatch) {
+			t.Errorf("Expected ErrEmbeddingCountMismatch, got %v", errs)
+		}
+	})
+
+	t.Run("hooks run around each chunk", func(t *testing.T) {
+		engine := NewProcessingEngine()
+		rejected := errors.New("rejected")
+		var after []string
+		if err := engine.RegisterHook(interfaces.HookEmbed, interfaces.HookBefore,
+			func(_ context.Context, payload *interfaces.HookPayload) error {
+				if payload.Chunk.ID == "a" {
+					return rejected
+				}
+				return nil
+			}); err != nil {
+			t.Fatal(err)
+		}
+		if err := engine.RegisterHook(interfaces.HookEmbed, interfaces.HookAfter,
+			func(_ context.Context, payload *interfaces.HookPayload) error {
+				after = append(after, payload.Chunk.ID)
+				return nil
+			}); err != nil {
+			t.Fatal(err)
+		}
+		embedder := &batchEmbedder{mockEmbedder: mockEmbedder{
+			modelName: "batch", dimension: embeddingDim768, embedding: embedding,
+		}}
+		chunks := newChunks()[:3]
+
+		_, errs := engine.embedChunks(context.Background(), chunks, embedder, embedder)
+		if !errors.Is(errs[0], rejected) {
+			t.Errorf("Expected the rejected chunk to fail, got %v", errs[0])
+		}
+		if len(embedder.batches) != 1 || embedder.batches[0] != 1 {
+			t.Errorf("Expected only the accepted chunk with a body to be sent, got %v", embedder.batches)
+		}
+		if strings.Join(after, ",") != "b,c" {
+			t.Errorf("Expected the after hooks to run for b and c, got %v", after)
+		}
+	})
+}
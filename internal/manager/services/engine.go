@@ -3,17 +3,28 @@ package services
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"iter"
+	"net/http"
+	"slices"
 	"sync"
 	"time"
 
 	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
 	"github.com/code-sleuth/ike-go/internal/manager/models"
+	"github.com/code-sleuth/ike-go/pkg/blobstore"
+	"github.com/code-sleuth/ike-go/pkg/compression"
+	"github.com/code-sleuth/ike-go/pkg/dialect"
+	"github.com/code-sleuth/ike-go/pkg/stmtcache"
+	"github.com/code-sleuth/ike-go/pkg/tracing"
 	"github.com/code-sleuth/ike-go/pkg/util"
+	"github.com/code-sleuth/ike-go/pkg/vector"
 
 	"github.com/google/uuid"
 	"github.com/rs/zerolog"
+	"golang.org/x/sync/errgroup"
 )
 
 const (
@@ -21,6 +32,17 @@ const (
 	embeddingDim768  = 768
 	embeddingDim1536 = 1536
 	embeddingDim3072 = 3072
+
+	// defaultEmbeddingLimit is how many chunks the engine embeds at once across all documents.
+	defaultEmbeddingLimit = 32
+)
+
+// DefaultEmbeddingBatchSize is the most chunks embedded with one request to embedders that take
+// batches, and DefaultEmbeddingBatchTokens the most tokens, well under OpenAI's limit of 300,000
+// per request.
+const (
+	DefaultEmbeddingBatchSize   = 64
+	DefaultEmbeddingBatchTokens = 100000
 )
 
 var (
@@ -41,6 +63,8 @@ var (
 	ErrChunkProcessingFailed     = errors.New("chunk processing failed")
 	ErrUnsupportedEmbeddingDim   = errors.New("unsupported embedding dimension")
 	ErrNoEmbeddingVector         = errors.New("no embedding vector found")
+	ErrEmbeddingDimMismatch      = errors.New("embedding vector does not match its dimension")
+	ErrEmbeddingCountMismatch    = errors.New("embedder returned a different number of embeddings than chunks")
 )
 
 // ProcessingEngine implements the main processing pipeline.
@@ -50,19 +74,82 @@ type ProcessingEngine struct {
 	chunkers     map[string]interfaces.Chunker
 	embedders    map[string]interfaces.Embedder
 	updaters     map[string]interfaces.Updater
+	hooks        map[hookKey][]interfaces.Hook
+	newEmbedder  func(model string) (interfaces.Embedder, error)
+	embedSlots   *adaptiveLimit
+	batchSize    int
+	batchTokens  int
+	dialect      dialect.Dialect
 	logger       zerolog.Logger
 	mu           sync.RWMutex
+
+	// Shutdown state: closing is closed when draining starts, and abort is cancelled when the
+	// Shutdown deadline passes.
+	lifecycle   sync.Mutex
+	draining    bool
+	closing     chan struct{}
+	abort       context.Context
+	cancelAbort context.CancelFunc
+	inflight    sync.WaitGroup
+}
+
+// dialectSetter is implemented by components that issue their own SQL.
+type dialectSetter interface {
+	SetDialect(d dialect.Dialect)
+}
+
+// sourceMatcher is implemented by transformers that recognize their own sources, such as those of
+// plugins, which the host heuristic cannot tell apart.
+type sourceMatcher interface {
+	MatchesSource(source *models.Source) bool
 }
 
-// NewProcessingEngine creates a new processing engine.
-func NewProcessingEngine() *ProcessingEngine {
-	return &ProcessingEngine{
+// EngineOption configures a ProcessingEngine when it is created.
+type EngineOption func(*ProcessingEngine)
+
+// WithLogger sends the engine's logs to logger, such as one from util.NewSlogLogger, in place of
+// the "engine" component logger.
+func WithLogger(logger zerolog.Logger) EngineOption {
+	return func(e *ProcessingEngine) {
+		e.logger = logger
+	}
+}
+
+// NewProcessingEngine creates a new processing engine configured by opts.
+func NewProcessingEngine(opts ...EngineOption) *ProcessingEngine {
+	abort, cancelAbort := context.WithCancel(context.Background())
+	e := &ProcessingEngine{
 		importers:    make(map[string]interfaces.Importer),
 		transformers: make(map[string]interfaces.Transformer),
 		chunkers:     make(map[string]interfaces.Chunker),
 		embedders:    make(map[string]interfaces.Embedder),
 		updaters:     make(map[string]interfaces.Updater),
-		logger:       util.NewLogger(zerolog.ErrorLevel),
+		hooks:        make(map[hookKey][]interfaces.Hook),
+		embedSlots:   newAdaptiveLimit(defaultEmbeddingLimit),
+		batchSize:    DefaultEmbeddingBatchSize,
+		batchTokens:  DefaultEmbeddingBatchTokens,
+		dialect:      dialect.SQLite,
+		logger:       util.ComponentLogger("engine", zerolog.ErrorLevel),
+		closing:      make(chan struct{}),
+		abort:        abort,
+		cancelAbort:  cancelAbort,
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// SetDialect sets the SQL dialect used by the engine and by registered transformers.
+func (e *ProcessingEngine) SetDialect(d dialect.Dialect) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.dialect = d
+	for _, transformer := range e.transformers {
+		if setter, ok := transformer.(dialectSetter); ok {
+			setter.SetDialect(d)
+		}
 	}
 }
 
@@ -97,6 +184,9 @@ func (e *ProcessingEngine) RegisterTransformer(transformer interfaces.Transforme
 		return err
 	}
 
+	if setter, ok := transformer.(dialectSetter); ok {
+		setter.SetDialect(e.dialect)
+	}
 	e.transformers[sourceType] = transformer
 	e.logger.Info().Str("source_type", sourceType).Msg("Registered transformer")
 	return err
@@ -138,6 +228,64 @@ func (e *ProcessingEngine) RegisterEmbedder(embedder interfaces.Embedder) error
 	return err
 }
 
+// SetEmbeddingLimit sets the most chunks the engine embeds at once across all documents and
+// sources, on top of the per-document concurrency option. The engine embeds fewer at once while
+// the embedding provider is rate limiting it; see adaptiveLimit. It must be called before
+// processing starts.
+func (e *ProcessingEngine) SetEmbeddingLimit(limit int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.embedSlots = newAdaptiveLimit(limit)
+}
+
+// SetEmbeddingBatch sets the most chunks, and the most tokens across them, embedded with one
+// request to embedders that take batches (see interfaces.BatchEmbedder). A batch then holds one
+// slot of the shared embedding pool. A size of 1 embeds every chunk with a request of its own.
+func (e *ProcessingEngine) SetEmbeddingBatch(size, tokens int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.batchSize = max(size, 1)
+	e.batchTokens = tokens
+}
+
+// SetEmbedderFactory sets the function used to create the embedder for a model that has not been
+// registered, such as one named by source settings or a queued job.
+func (e *ProcessingEngine) SetEmbedderFactory(factory func(model string) (interfaces.Embedder, error)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.newEmbedder = factory
+}
+
+// embedderFor returns the embedder registered for model, creating it with the embedder factory
+// when there is none.
+func (e *ProcessingEngine) embedderFor(model string) (interfaces.Embedder, error) {
+	e.mu.RLock()
+	embedder, exists := e.embedders[model]
+	factory := e.newEmbedder
+	e.mu.RUnlock()
+
+	if exists {
+		return embedder, nil
+	}
+	if factory == nil {
+		return nil, ErrNoEmbedderRegistered
+	}
+
+	embedder, err := factory(model)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrNoEmbedderRegistered, err)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if registered, exists := e.embedders[model]; exists {
+		return registered, nil
+	}
+	e.embedders[model] = embedder
+	e.logger.Info().Str("model_name", model).Msg("Created embedder")
+	return embedder, nil
+}
+
 // RegisterUpdater adds a new updater to the engine.
 func (e *ProcessingEngine) RegisterUpdater(updater interfaces.Updater) error {
 	e.mu.Lock()
@@ -162,6 +310,39 @@ func (e *ProcessingEngine) ProcessSource(
 	sourceURL string,
 	options *interfaces.ProcessingOptions,
 	db *sql.DB,
+) error {
+	ctx, done, err := e.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	return e.processSource(ctx, sourceURL, options, db)
+}
+
+// processSource runs the pipeline for a source and records the run's metrics and span.
+func (e *ProcessingEngine) processSource(
+	ctx context.Context,
+	sourceURL string,
+	options *interfaces.ProcessingOptions,
+	db *sql.DB,
+) error {
+	runsInProgress.With().Inc()
+	defer runsInProgress.With().Dec()
+
+	ctx, span := tracing.Start(ctx, spanProcessSource, tracing.String("source.url", sourceURL))
+	start := time.Now()
+	err := e.runSource(ctx, sourceURL, options, db)
+	observeRun(start, err)
+	endSpan(span, err)
+	return err
+}
+
+func (e *ProcessingEngine) runSource(
+	ctx context.Context,
+	sourceURL string,
+	options *interfaces.ProcessingOptions,
+	db *sql.DB,
 ) error {
 	// Determine source type from URL
 	sourceType, err := e.determineSourceType(sourceURL)
@@ -180,16 +361,191 @@ func (e *ProcessingEngine) ProcessSource(
 		return ErrNoImporterRegistered
 	}
 
+	if options != nil && options.Collection != "" {
+		ctx = interfaces.WithCollection(ctx, options.Collection)
+	}
+	if options != nil && len(options.Owners) > 0 {
+		ctx = interfaces.WithOwners(ctx, options.Owners)
+	}
+	// Labels stored in the settings for the URL hold for every import of it, including webhook
+	// re-imports that don't carry the options the source was first imported with. Settings are
+	// stored under URLs without credentials, as sources are.
+	settingsURL := sourceURL
+	if normalized, err := util.NormalizeURL(sourceURL); err == nil {
+		settingsURL, _ = util.SplitURLSecrets(normalized)
+	}
+	if options != nil && db != nil {
+		options, err = e.applySourceSettings(ctx, db, settingsURL, options)
+		if err != nil {
+			e.logger.Error().Err(err).Str("source_url", sourceURL).Msg("Failed to load source settings")
+			return err
+		}
+	}
+	if db != nil {
+		ctx, err = e.applySourceCredentials(ctx, db, settingsURL)
+		if err != nil {
+			e.logger.Error().Err(err).Str("source_url", sourceURL).Msg("Failed to load source credentials")
+			return err
+		}
+	}
+	if options != nil && len(options.ACLLabels) > 0 {
+		ctx = interfaces.WithACLLabels(ctx, options.ACLLabels)
+	}
+	if options != nil && options.Progress != nil {
+		ctx = interfaces.WithProgressSource(interfaces.WithProgress(ctx, options.Progress), sourceURL)
+	}
+	if options != nil && len(options.Paths) > 0 {
+		ctx = interfaces.WithPaths(ctx, options.Paths)
+	}
+
+	err = e.runHooks(ctx, &interfaces.HookPayload{
+		Stage: interfaces.HookImport, Phase: interfaces.HookBefore, SourceURL: sourceURL, Options: options,
+	})
+	if err != nil {
+		e.logger.Error().Err(err).Str("source_url", sourceURL).Msg("Import hook failed")
+		return err
+	}
+
+	// Resume an interrupted run for this URL, or start a new one
+	run, err := e.startRun(ctx, db, sourceURL, options != nil && options.Restart)
+	if err != nil {
+		e.logger.Error().Err(err).Str("source_url", sourceURL).Msg("Failed to start pipeline run")
+		return err
+	}
+	ctx = interfaces.WithCheckpoint(ctx, run)
+	if options != nil {
+		run.model = options.EmbeddingModel
+	}
+	// Importers that can tell what changed since the last completed run import only that, unless
+	// every document is to be embedded again. A resumed run imports everything, since the failed
+	// attempt may have stored items it never embedded.
+	if !run.resumed && (options == nil || !options.Force) {
+		ctx = interfaces.WithLastRevision(ctx, e.lastRevision(ctx, db, sourceURL, run.model))
+	}
+
 	// Import the content
 	e.logger.Info().Str("source_url", sourceURL).Str("source_type", sourceType).Msg("Starting import")
-	importResult, err := importer.Import(ctx, sourceURL, db)
+	importCtx, span := tracing.Start(ctx, spanImport,
+		tracing.String("source.url", sourceURL), tracing.String("source.type", sourceType))
+	var importTimeout time.Duration
+	if options != nil {
+		importTimeout = options.ImportTimeout
+	}
+	importCtx, cancel := withStageTimeout(importCtx, interfaces.HookImport, importTimeout)
+	start := time.Now()
+	importResult, err := importer.Import(importCtx, sourceURL, db)
+	err = stageError(importCtx, err)
+	cancel()
+	if !nothingToImport(err) {
+		observeStage(interfaces.HookImport, start, err)
+		span.RecordError(err)
+	}
+	if importResult != nil {
+		run.revision = importResult.Revision
+		span.SetAttributes(tracing.String("source.id", importResult.SourceID))
+		if report := importResult.Report; report != nil {
+			span.SetAttributes(tracing.Int("import.imported", report.Imported),
+				tracing.Int("import.skipped", report.Skipped), tracing.Int("import.failed", report.Failed))
+		}
+	}
+	span.End()
+	if nothingToImport(err) {
+		// Nothing the importer handles changed, so there is nothing to process
+		e.logger.Info().Err(err).Str("source_url", sourceURL).Msg("No importable items changed")
+		e.finishRun(ctx, run, nil)
+		return nil
+	}
 	if err != nil {
 		e.logger.Error().Err(err).Str("source_url", sourceURL).Msg("Import failed")
+		e.finishRun(ctx, run, err)
+		return err
+	}
+	observeImport(sourceType, importResult.Report)
+	if report := importResult.Report; report != nil {
+		e.logger.Info().Str("source_url", sourceURL).Int("imported", report.Imported).
+			Int("skipped", report.Skipped).Interface("skip_reasons", report.SkipCounts()).
+			Int("failed", report.Failed).Msg("Import finished")
+	}
+	if importResult.Error != nil {
+		// Items that failed to import are not retried here; the next import of the source fetches them
+		e.logger.Warn().Err(importResult.Error).Str("source_url", sourceURL).Msg("Import completed with errors")
+	}
+	err = e.runHooks(ctx, &interfaces.HookPayload{
+		Stage: interfaces.HookImport, Phase: interfaces.HookAfter, SourceURL: sourceURL, Options: options,
+		Import: importResult,
+	})
+	if err != nil {
+		e.logger.Error().Err(err).Str("source_url", sourceURL).Msg("Import hook failed")
+		e.finishRun(ctx, run, err)
+		return err
+	}
+	e.recordEvent(ctx, db, models.EventSourceImported, importResult.SourceID, "",
+		importDetail(sourceURL, importResult.Report))
+
+	// Importers that do not record checkpoints only report their last download
+	if run.empty() {
+		if err := run.RecordImport(ctx, importResult.DownloadID, importResult); err != nil {
+			e.logger.Error().Err(err).Str("run_id", run.id).Msg("Failed to record import")
+			return err
+		}
+	}
+
+	// Process the imported content, skipping items an earlier attempt already finished
+	items := run.pending()
+	var failed int
+	var firstErr error
+	for _, item := range items {
+		// Leave the remaining items for the next attempt to resume once Shutdown starts
+		if e.stopping() {
+			e.logger.Info().Str("run_id", run.id).Msg("Stopping pipeline run for shutdown")
+			e.finishRun(ctx, run, ErrShuttingDown)
+			return ErrShuttingDown
+		}
+
+		err := e.processDocument(ctx, item.result.DownloadID, options, db)
+		if ctx.Err() != nil {
+			e.finishRun(ctx, run, context.Cause(ctx))
+			return context.Cause(ctx)
+		}
+
+		status := itemStatusDone
+		if err != nil {
+			status = itemStatusFailed
+			failed++
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+		if err := run.setItemStatus(ctx, item, status, err); err != nil {
+			e.logger.Error().Err(err).Str("run_id", run.id).Str("item", item.key).Msg("Failed to record item status")
+		}
+	}
+
+	if failed > 0 {
+		err := fmt.Errorf("%w: %d of %d items failed, first error: %w", ErrRunIncomplete, failed, len(items), firstErr)
+		e.finishRun(ctx, run, err)
 		return err
 	}
 
-	// Process the imported content
-	return e.ProcessDocument(ctx, importResult.DownloadID, options, db)
+	e.finishRun(ctx, run, nil)
+	return nil
+}
+
+// nothingToImport reports whether err says the importer found nothing to import: no item matched
+// the requested paths, or nothing changed since the last completed run.
+func nothingToImport(err error) bool {
+	return errors.Is(err, interfaces.ErrNoMatchingPaths) || errors.Is(err, interfaces.ErrNoChanges)
+}
+
+// finishRun records the outcome of run and the health of its sources; a failure to do so is
+// logged rather than returned so it does not mask the pipeline's own result.
+func (e *ProcessingEngine) finishRun(ctx context.Context, run *pipelineRun, cause error) {
+	if err := run.finish(ctx, cause); err != nil {
+		e.logger.Error().Err(err).Str("run_id", run.id).Msg("Failed to record pipeline run status")
+	}
+	if err := run.recordHealth(ctx, cause); err != nil {
+		e.logger.Error().Err(err).Str("run_id", run.id).Msg("Failed to record source health")
+	}
 }
 
 // ProcessDocument runs transform/chunk/embed for an existing download.
@@ -199,6 +555,57 @@ func (e *ProcessingEngine) ProcessDocument(
 	options *interfaces.ProcessingOptions,
 	db *sql.DB,
 ) error {
+	ctx, done, err := e.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	return e.processDocument(ctx, downloadID, options, db)
+}
+
+func (e *ProcessingEngine) processDocument(
+	ctx context.Context,
+	downloadID string,
+	options *interfaces.ProcessingOptions,
+	db *sql.DB,
+) error {
+	return e.processDownload(ctx, downloadID, "", options, db)
+}
+
+// processDownload transforms, chunks, and embeds a download within a span carrying its IDs. When
+// replaceID is set, the new document stays hidden until all of its chunks are saved and then
+// takes the place of the replaced document; see replaceDocument.
+func (e *ProcessingEngine) processDownload(
+	ctx context.Context,
+	downloadID, replaceID string,
+	options *interfaces.ProcessingOptions,
+	db *sql.DB,
+) error {
+	ctx, span := tracing.Start(ctx, spanProcessDoc, tracing.String("download.id", downloadID))
+	if replaceID != "" {
+		span.SetAttributes(tracing.String("document.replaces", replaceID))
+	}
+	err := e.runDownload(ctx, downloadID, replaceID, options, db)
+	endSpan(span, err)
+	return err
+}
+
+func (e *ProcessingEngine) runDownload(
+	ctx context.Context,
+	downloadID, replaceID string,
+	options *interfaces.ProcessingOptions,
+	db *sql.DB,
+) error {
+	outcome := "failed"
+	defer func() {
+		documentsTotal.With(outcome).Inc()
+	}()
+
+	if options != nil && options.Progress != nil {
+		ctx = interfaces.WithProgress(ctx, options.Progress)
+	}
+
 	// Get the download
 	download, err := e.getDownload(ctx, downloadID, db)
 	if err != nil {
@@ -212,6 +619,21 @@ func (e *ProcessingEngine) ProcessDocument(
 		e.logger.Error().Err(err).Str("download_id", downloadID).Msg("Failed to get source")
 		return err
 	}
+	tracing.SpanFromContext(ctx).SetAttributes(tracing.String("source.id", source.ID))
+
+	// Settings stored for the source override the options the import was started with
+	if source.RawURL != nil {
+		options, err = e.applySourceSettings(ctx, db, *source.RawURL, options)
+		if err != nil {
+			e.logger.Error().Err(err).Str("download_id", downloadID).Msg("Failed to load source settings")
+			return err
+		}
+		ctx, err = e.applySourceCredentials(ctx, db, *source.RawURL)
+		if err != nil {
+			e.logger.Error().Err(err).Str("download_id", downloadID).Msg("Failed to load source credentials")
+			return err
+		}
+	}
 
 	// Determine source type from source
 	sourceType, err := e.determineSourceTypeFromSource(source)
@@ -234,12 +656,128 @@ func (e *ProcessingEngine) ProcessDocument(
 
 	// Transform the content
 	e.logger.Info().Str("download_id", downloadID).Str("source_type", sourceType).Msg("Starting transformation")
-	transformResult, err := transformer.Transform(ctx, download, db)
+	hook := &interfaces.HookPayload{
+		Stage: interfaces.HookTransform, Phase: interfaces.HookBefore, Options: options, Source: source,
+		Download: download,
+	}
+	transformCtx, span := tracing.Start(ctx, spanTransform,
+		tracing.String("download.id", downloadID), tracing.String("source.type", sourceType))
+	transformCtx, cancel := withStageTimeout(transformCtx, interfaces.HookTransform, options.TransformTimeout)
+	start := time.Now()
+	err = e.runHooks(transformCtx, hook)
+	var transformResult *interfaces.TransformResult
+	if err == nil {
+		transformResult, err = transformer.Transform(transformCtx, download, db)
+	}
+	if err == nil {
+		hook.Phase, hook.Transform = interfaces.HookAfter, transformResult
+		err = e.runHooks(transformCtx, hook)
+	}
+	err = stageError(transformCtx, err)
+	cancel()
+	observeStage(interfaces.HookTransform, start, err)
+	if err == nil {
+		span.SetAttributes(tracing.String("document.id", transformResult.Document.ID))
+		tracing.SpanFromContext(ctx).SetAttributes(tracing.String("document.id", transformResult.Document.ID))
+	}
+	endSpan(span, err)
 	if err != nil {
 		e.logger.Error().Err(err).Str("download_id", downloadID).Msg("Transformation failed")
+		interfaces.ReportProgress(ctx, interfaces.ProgressEvent{
+			Stage: interfaces.StageFailed, Item: downloadID, Count: 1, Err: err,
+		})
+		return err
+	}
+	interfaces.ReportProgress(ctx, interfaces.ProgressEvent{
+		Stage: interfaces.StageTransformed, Item: transformResult.Document.ID, Count: 1,
+	})
+
+	// Hide the replacement until it is complete, so searches keep finding the document it replaces
+	if replaceID != "" {
+		document := transformResult.Document
+		err := e.stageDocument(ctx, db, document.ID, contentHash(transformResult.Content))
+		if err == nil {
+			err = e.chunkAndEmbed(ctx, downloadID, transformResult, hook, options, db)
+		}
+		if err := e.replaceDocument(ctx, db, replaceID, document.ID, err); err != nil {
+			return err
+		}
+		e.recordEvent(ctx, db, models.EventDocumentCreated, document.SourceID, document.ID,
+			fmt.Sprintf("re-chunked download %s, replacing document %s", downloadID, replaceID))
+		outcome = "processed"
+		return nil
+	}
+
+	// Skip chunking and embedding when the source's content has not changed since it was embedded
+	if !options.Force {
+		document := transformResult.Document
+		unchanged, err := e.skipUnchanged(ctx, db, document.ID, document.SourceID,
+			contentHash(transformResult.Content), options.EmbeddingModel)
+		if err != nil {
+			e.logger.Error().Err(err).Str("document_id", document.ID).Msg("Failed to compare content hash")
+			return err
+		}
+		if unchanged {
+			outcome = "skipped"
+			interfaces.ReportProgress(ctx, interfaces.ProgressEvent{
+				Stage: interfaces.StageSkipped, Item: document.ID, Count: 1,
+			})
+			return nil
+		}
+	}
+	e.recordEvent(ctx, db, models.EventDocumentCreated, transformResult.Document.SourceID,
+		transformResult.Document.ID, "transformed download "+downloadID)
+
+	if err := e.chunkAndEmbed(ctx, downloadID, transformResult, hook, options, db); err != nil {
 		return err
 	}
+	outcome = "processed"
+	return nil
+}
 
+// chunkerFor returns chunker counting tokens with embedder's tokenizer when both support it, so
+// chunk limits and token counts match the embedding model rather than the chunker's default.
+func chunkerFor(chunker interfaces.Chunker, embedder interfaces.Embedder) interfaces.Chunker {
+	tokenizingChunker, ok := chunker.(interfaces.TokenizingChunker)
+	if !ok {
+		return chunker
+	}
+	tokenizingEmbedder, ok := embedder.(interfaces.TokenizingEmbedder)
+	if !ok {
+		return chunker
+	}
+	return tokenizingChunker.WithTokenizer(tokenizingEmbedder.GetTokenizer())
+}
+
+// linkChunks assigns chunks to their document, gives those without an ID one, and links each to
+// its neighbours in document order, so neighbouring chunks can be found at query time. Links
+// chunkers set themselves, such as a parent, are kept.
+func linkChunks(chunks []*models.Chunk, documentID string) {
+	for _, chunk := range chunks {
+		chunk.DocumentID = documentID
+		if chunk.ID == "" {
+			chunk.ID = uuid.New().String()
+		}
+	}
+	for i, chunk := range chunks {
+		if i > 0 && chunk.LeftChunkID == nil {
+			chunk.LeftChunkID = &chunks[i-1].ID
+		}
+		if i < len(chunks)-1 && chunk.RightChunkID == nil {
+			chunk.RightChunkID = &chunks[i+1].ID
+		}
+	}
+}
+
+// chunkAndEmbed splits a transformed document into chunks, then embeds and saves each of them.
+func (e *ProcessingEngine) chunkAndEmbed(
+	ctx context.Context,
+	downloadID string,
+	transformResult *interfaces.TransformResult,
+	hook *interfaces.HookPayload,
+	options *interfaces.ProcessingOptions,
+	db *sql.DB,
+) error {
 	// Get the chunker
 	e.mu.RLock()
 	chunker, exists := e.chunkers[options.ChunkStrategy]
@@ -253,15 +791,21 @@ func (e *ProcessingEngine) ProcessDocument(
 	}
 
 	// Get the embedder
-	e.mu.RLock()
-	embedder, exists := e.embedders[options.EmbeddingModel]
-	e.mu.RUnlock()
-
-	if !exists {
+	embedder, err := e.embedderFor(options.EmbeddingModel)
+	if err != nil {
 		e.logger.Error().
+			Err(err).
 			Str("download_id", downloadID).
 			Msgf("No embedder registered for model: %s", options.EmbeddingModel)
-		return ErrNoEmbedderRegistered
+		return err
+	}
+	chunker = chunkerFor(chunker, embedder)
+
+	// Hooks after chunking are handed every chunk of the document, so only without them can chunks
+	// be embedded as they are cut
+	streaming, ok := chunker.(interfaces.StreamingChunker)
+	if ok && !e.hasHooks(interfaces.HookChunk, interfaces.HookAfter) {
+		return e.streamAndEmbed(ctx, streaming, transformResult, hook, options, embedder, db)
 	}
 
 	// Chunk the content
@@ -270,11 +814,35 @@ func (e *ProcessingEngine) ProcessDocument(
 		Str("chunk_strategy", options.ChunkStrategy).
 		Int("max_tokens", options.MaxTokens).
 		Msg("Starting chunking")
-	chunks, err := chunker.ChunkDocument(transformResult.Content, options.MaxTokens)
+	hook.Stage, hook.Phase = interfaces.HookChunk, interfaces.HookBefore
+	var chunks []*models.Chunk
+	chunkCtx, span := tracing.Start(ctx, spanChunk, tracing.String("document.id", transformResult.Document.ID),
+		tracing.String("chunk.strategy", options.ChunkStrategy))
+	start := time.Now()
+	err = e.runHooks(chunkCtx, hook)
+	if err == nil {
+		chunks, err = chunker.ChunkDocument(transformResult.Content, options.MaxTokens)
+	}
+	if err == nil {
+		linkChunks(chunks, transformResult.Document.ID)
+	}
+	if err == nil {
+		hook.Phase, hook.Chunks = interfaces.HookAfter, chunks
+		err = e.runHooks(chunkCtx, hook)
+	}
+	observeStage(interfaces.HookChunk, start, err)
+	span.SetAttributes(tracing.Int("chunk.count", len(chunks)))
+	endSpan(span, err)
 	if err != nil {
 		e.logger.Error().Err(err).Str("document_id", transformResult.Document.ID).Msg("Chunking failed")
+		interfaces.ReportProgress(ctx, interfaces.ProgressEvent{
+			Stage: interfaces.StageFailed, Item: transformResult.Document.ID, Count: 1, Err: err,
+		})
 		return err
 	}
+	interfaces.ReportProgress(ctx, interfaces.ProgressEvent{
+		Stage: interfaces.StageChunked, Item: transformResult.Document.ID, Count: len(chunks),
+	})
 
 	// Process chunks concurrently
 	e.logger.Info().
@@ -282,7 +850,19 @@ func (e *ProcessingEngine) ProcessDocument(
 		Str("embedding_model", options.EmbeddingModel).
 		Int("concurrency", options.Concurrency).
 		Msg("Starting embedding")
-	return e.processChunks(ctx, chunks, transformResult.Document.ID, embedder, db, options.Concurrency)
+	embedCtx, span := tracing.Start(ctx, spanEmbedChunks, tracing.String("document.id", transformResult.Document.ID),
+		tracing.String("embedding.model", options.EmbeddingModel), tracing.Int("chunk.count", len(chunks)))
+	embedCtx = withEmbedTimeout(embedCtx, options.EmbedTimeout)
+	start = time.Now()
+	err = e.processChunks(embedCtx, chunks, transformResult.Document.ID, embedder, db, options.Concurrency)
+	// Embedding failures are counted per chunk by processChunks
+	observeStage(interfaces.HookEmbed, start, nil)
+	endSpan(span, err)
+	if err == nil {
+		e.recordEvent(ctx, db, models.EventChunksEmbedded, transformResult.Document.SourceID,
+			transformResult.Document.ID, fmt.Sprintf("%d chunks embedded with %s", len(chunks), options.EmbeddingModel))
+	}
+	return err
 }
 
 // Helper methods
@@ -303,6 +883,15 @@ func (e *ProcessingEngine) determineSourceType(sourceURL string) (string, error)
 }
 
 func (e *ProcessingEngine) determineSourceTypeFromSource(source *models.Source) (string, error) {
+	e.mu.RLock()
+	for sourceType, transformer := range e.transformers {
+		if matcher, ok := transformer.(sourceMatcher); ok && matcher.MatchesSource(source) {
+			e.mu.RUnlock()
+			return sourceType, nil
+		}
+	}
+	e.mu.RUnlock()
+
 	// For now, we'll use a simple heuristic based on the host
 	// TODO: This could be extended to use a more sophisticated detection system
 	if source.Host != nil {
@@ -324,18 +913,20 @@ func (e *ProcessingEngine) determineSourceTypeFromSource(source *models.Source)
 }
 
 func (e *ProcessingEngine) getDownload(ctx context.Context, downloadID string, db *sql.DB) (*models.Download, error) {
-	query := `SELECT id, source_id, attempted_at, downloaded_at, status_code, headers, body 
-			 FROM downloads WHERE id = ?`
+	query := `SELECT d.id, d.source_id, d.attempted_at, d.downloaded_at, d.status_code, d.headers,
+			 COALESCE(b.body, d.body), COALESCE(b.body_encoding, d.body_encoding), d.content_hash, b.blob_key
+			 FROM downloads d LEFT JOIN download_bodies b ON b.content_hash = d.content_hash
+			 WHERE d.id = ?`
 
-	row := db.QueryRowContext(ctx, query, downloadID)
+	row := db.QueryRowContext(ctx, e.dialect.Rebind(query), downloadID)
 
 	var download models.Download
 	var attemptedAt, downloadedAt sql.NullString
 	var statusCode sql.NullInt32
-	var body sql.NullString
+	var body, contentHash, blobKey sql.NullString
 
 	err := row.Scan(&download.ID, &download.SourceID, &attemptedAt, &downloadedAt,
-		&statusCode, &download.Headers, &body)
+		&statusCode, &download.Headers, &body, &download.BodyEncoding, &contentHash, &blobKey)
 	if err != nil {
 		e.logger.Error().Err(err).Str("download_id", downloadID).Msg("Failed to get download")
 		return nil, err
@@ -343,13 +934,13 @@ func (e *ProcessingEngine) getDownload(ctx context.Context, downloadID string, d
 
 	// Handle nullable fields
 	if attemptedAt.Valid {
-		if t, err := time.Parse(time.RFC3339, attemptedAt.String); err == nil {
+		if t, err := dialect.ParseTime(attemptedAt.String); err == nil {
 			e.logger.Debug().Str("download_id", downloadID).Str("attempted_at", attemptedAt.String).Msg("Attempted at")
 			download.AttemptedAt = &t
 		}
 	}
 	if downloadedAt.Valid {
-		if t, err := time.Parse(time.RFC3339, downloadedAt.String); err == nil {
+		if t, err := dialect.ParseTime(downloadedAt.String); err == nil {
 			e.logger.Debug().
 				Str("download_id", downloadID).
 				Str("downloaded_at", downloadedAt.String).
@@ -361,19 +952,45 @@ func (e *ProcessingEngine) getDownload(ctx context.Context, downloadID string, d
 		code := int(statusCode.Int32)
 		download.StatusCode = &code
 	}
-	if body.Valid {
-		download.Body = &body.String
+	if contentHash.Valid {
+		download.ContentHash = contentHash.String
+	}
+	if blobKey.Valid {
+		decoded, err := blobstore.Default().ReadBody(ctx, blobKey.String, download.BodyEncoding)
+		if err != nil {
+			e.logger.Error().Err(err).Str("download_id", downloadID).Msg("Failed to read download body")
+			return nil, err
+		}
+		download.Body = &decoded
+	} else if body.Valid {
+		decoded, err := compression.DecodeBody([]byte(body.String), download.BodyEncoding)
+		if err != nil {
+			e.logger.Error().Err(err).Str("download_id", downloadID).Msg("Failed to decode download body")
+			return nil, err
+		}
+		// Bodies stored before importers transcoded them may be in a legacy charset
+		text, _ := util.ToUTF8([]byte(decoded), headerContentType(download.Headers))
+		download.Body = &text
 	}
 
 	return &download, nil
 }
 
+// headerContentType returns the Content-Type recorded in a download's headers JSON, if any.
+func headerContentType(headersJSON string) string {
+	var headers http.Header
+	if err := json.Unmarshal([]byte(headersJSON), &headers); err != nil {
+		return ""
+	}
+	return headers.Get("Content-Type")
+}
+
 func (e *ProcessingEngine) getSource(ctx context.Context, sourceID string, db *sql.DB) (*models.Source, error) {
 	query := `SELECT id, author_email, raw_url, scheme, host, path, query, active_domain, 
 			 format, created_at, updated_at 
 			 FROM sources WHERE id = ?`
 
-	row := db.QueryRowContext(ctx, query, sourceID)
+	row := db.QueryRowContext(ctx, e.dialect.Rebind(query), sourceID)
 
 	var source models.Source
 	var authorEmail, rawURL, scheme, host, path, queryParam, format sql.NullString
@@ -410,16 +1027,21 @@ func (e *ProcessingEngine) getSource(ctx context.Context, sourceID string, db *s
 	}
 
 	// Parse timestamps
-	if createdAt, err := time.Parse(time.RFC3339, createdAtStr); err == nil {
+	if createdAt, err := dialect.ParseTime(createdAtStr); err == nil {
 		source.CreatedAt = createdAt
 	}
-	if updatedAt, err := time.Parse(time.RFC3339, updatedAtStr); err == nil {
+	if updatedAt, err := dialect.ParseTime(updatedAtStr); err == nil {
 		source.UpdatedAt = updatedAt
 	}
 
 	return &source, nil
 }
 
+// processChunks embeds and saves chunks with up to concurrency of them in flight, each also
+// holding a slot of the engine's shared embedding pool. Embedders that take batches are sent
+// chunks in batches (see batchChunks), and then up to concurrency batches are in flight, each
+// holding one slot. Chunks are dispatched and their results collected as they complete, so memory
+// stays bounded by the concurrency rather than the number of chunks.
 func (e *ProcessingEngine) processChunks(
 	ctx context.Context,
 	chunks []*models.Chunk,
@@ -428,111 +1050,266 @@ func (e *ProcessingEngine) processChunks(
 	db *sql.DB,
 	concurrency int,
 ) error {
-	// Channel for chunk processing
-	chunkChan := make(chan *models.Chunk, len(chunks))
-	resultChan := make(chan *interfaces.ChunkResult, len(chunks))
+	return e.processChunkStream(ctx, slices.Values(chunks), documentID, embedder, db, concurrency)
+}
 
-	// Start workers
-	for i := 0; i < concurrency; i++ {
-		go e.chunkWorker(ctx, chunkChan, resultChan, documentID, embedder, db)
+// processChunkStream is processChunks for chunks handed over one at a time, which are taken from
+// chunks only as fast as they are embedded.
+func (e *ProcessingEngine) processChunkStream(
+	ctx context.Context,
+	chunks iter.Seq[*models.Chunk],
+	documentID string,
+	embedder interfaces.Embedder,
+	db *sql.DB,
+	concurrency int,
+) error {
+	if concurrency < 1 {
+		concurrency = 1
 	}
 
-	// Send chunks to workers
-	for _, chunk := range chunks {
-		chunkChan <- chunk
-	}
-	close(chunkChan)
+	// Dispatch chunks, or batches of them for embedders that take batches; the group blocks once
+	// concurrency chunks or batches are in flight
+	resultChan := make(chan *interfaces.ChunkResult, concurrency)
+	batchEmbedder, batching := embedder.(interfaces.BatchEmbedder)
+	total := 0
+	go func() {
+		var group errgroup.Group
+		group.SetLimit(concurrency)
+		if batching {
+			for batch := range e.batchStream(chunks) {
+				total += len(batch)
+				group.Go(func() error {
+					for _, result := range e.embedBatch(ctx, batch, documentID, embedder, batchEmbedder, db) {
+						resultChan <- result
+					}
+					return nil
+				})
+			}
+		} else {
+			for chunk := range chunks {
+				total++
+				group.Go(func() error {
+					resultChan <- e.embedChunk(ctx, chunk, documentID, embedder, db)
+					return nil
+				})
+			}
+		}
+		_ = group.Wait()
+		close(resultChan)
+	}()
 
-	// Collect results
+	// Collect results; failed chunks are dead-lettered so retry-failed can re-process them
 	var errorsList []error
-	for i := 0; i < len(chunks); i++ {
-		result := <-resultChan
+	for result := range resultChan {
 		if result.Error != nil {
+			chunksEmbedded.With(embedder.GetModelName(), "failed").Inc()
+			observeFailure(interfaces.HookEmbed, result.Error)
 			errorsList = append(errorsList, result.Error)
+			if err := e.recordFailedChunk(ctx, result.Chunk, embedder.GetModelName(), result.Error, db); err != nil {
+				e.logger.Error().Err(err).Str("chunk_id", result.Chunk.ID).Msg("Failed to record failed chunk")
+			}
+			interfaces.ReportProgress(ctx, interfaces.ProgressEvent{
+				Stage: interfaces.StageFailed, Item: result.Chunk.ID, Count: 1, Err: result.Error,
+			})
+			continue
 		}
+		chunksEmbedded.With(embedder.GetModelName(), "embedded").Inc()
+		interfaces.ReportProgress(ctx, interfaces.ProgressEvent{
+			Stage: interfaces.StageEmbedded, Item: result.Chunk.ID, Count: 1,
+		})
 	}
 
-	var err error
 	if len(errorsList) > 0 {
 		e.logger.Error().Errs("errors", errorsList).Msg("Chunk processing failed")
-		err = ErrChunkProcessingFailed
-		return err
+		// total is read after resultChan is closed, once every chunk has been dispatched
+		return fmt.Errorf("%w: %d of %d chunks recorded in failed_chunks", ErrChunkProcessingFailed,
+			len(errorsList), total)
 	}
 
-	return err
+	return nil
 }
 
-func (e *ProcessingEngine) chunkWorker(
+// embedChunk assigns chunk to its document and processes it once a slot of the shared embedding
+// pool is free. Chunks keep the ID linkChunks gave them, so their neighbours' links stay valid.
+func (e *ProcessingEngine) embedChunk(
 	ctx context.Context,
-	chunkChan <-chan *models.Chunk,
-	resultChan chan<- *interfaces.ChunkResult,
+	chunk *models.Chunk,
 	documentID string,
 	embedder interfaces.Embedder,
 	db *sql.DB,
-) {
-	for chunk := range chunkChan {
-		result := &interfaces.ChunkResult{
-			Chunk: chunk,
-		}
-
-		// Set document ID and generate UUID
-		chunk.DocumentID = documentID
+) *interfaces.ChunkResult {
+	chunk.DocumentID = documentID
+	if chunk.ID == "" {
 		chunk.ID = uuid.New().String()
+	}
 
-		// Generate embedding
-		if chunk.Body != nil {
-			embedding, err := embedder.GenerateEmbedding(ctx, *chunk.Body)
-			if err != nil {
-				result.Error = fmt.Errorf("embedding generation failed: %w", err)
-				resultChan <- result
-				continue
-			}
+	e.mu.RLock()
+	slots := e.embedSlots
+	e.mu.RUnlock()
 
-			// Create embedding record
-			modelName := embedder.GetModelName()
-			result.Embedding = &models.Embedding{
-				ID:         uuid.New().String(),
-				Model:      &modelName,
-				EmbeddedAt: time.Now(),
-				ObjectID:   chunk.ID,
-				ObjectType: "chunk",
-			}
+	if err := slots.Acquire(ctx); err != nil {
+		return &interfaces.ChunkResult{Chunk: chunk, Error: err}
+	}
+	defer slots.Release()
 
-			// Set appropriate embedding field based on dimension
-			switch embedder.GetDimension() {
-			case embeddingDim768:
-				result.Embedding.Embedding768 = embedding
-			case embeddingDim1536:
-				result.Embedding.Embedding1536 = embedding
-			case embeddingDim3072:
-				result.Embedding.Embedding3072 = embedding
-			default:
-				e.logger.Error().
-					Str("model_name", modelName).
-					Int("dimension", embedder.GetDimension()).
-					Msg("Unsupported embedding dimension")
-				result.Error = ErrUnsupportedEmbeddingDim
-				resultChan <- result
-				continue
-			}
-		}
+	return e.processChunk(interfaces.WithRateLimitObserver(ctx, slots.Observe), chunk, embedder, db)
+}
+
+// processChunk embeds chunk and saves it together with its embedding.
+func (e *ProcessingEngine) processChunk(
+	ctx context.Context,
+	chunk *models.Chunk,
+	embedder interfaces.Embedder,
+	db *sql.DB,
+) *interfaces.ChunkResult {
+	result := &interfaces.ChunkResult{
+		Chunk: chunk,
+	}
+
+	attrs := []tracing.Attribute{
+		tracing.String("chunk.id", chunk.ID), tracing.String("document.id", chunk.DocumentID),
+	}
+	embedCtx, span := tracing.Start(ctx, spanEmbed,
+		append(attrs, tracing.String("embedding.model", embedder.GetModelName()))...)
+	embedCtx, cancel := withStageTimeout(embedCtx, interfaces.HookEmbed, embedTimeout(ctx))
+	result.Embedding, result.Error = e.embed(embedCtx, chunk, embedder)
+	result.Error = stageError(embedCtx, result.Error)
+	cancel()
+	endSpan(span, result.Error)
+	if result.Error != nil {
+		return result
+	}
 
-		// Save chunk and embedding to database
-		if err := e.saveChunkAndEmbedding(ctx, chunk, result.Embedding, db); err != nil {
-			e.logger.Error().Err(err).Str("chunk_id", chunk.ID).Msg("Failed to save chunk and embedding")
-			result.Error = err
+	// Save chunk and embedding to database
+	saveCtx, span := tracing.Start(ctx, spanSave, attrs...)
+	if err := e.saveChunkAndEmbedding(saveCtx, chunk, result.Embedding, db); err != nil {
+		e.logger.Error().Err(err).Str("chunk_id", chunk.ID).Msg("Failed to save chunk and embedding")
+		result.Error = err
+	}
+	endSpan(span, result.Error)
+
+	return result
+}
+
+// embed runs the embed hooks around generating chunk's embedding with embedder. A chunk without
+// a body has no embedding.
+func (e *ProcessingEngine) embed(
+	ctx context.Context,
+	chunk *models.Chunk,
+	embedder interfaces.Embedder,
+) (*models.Embedding, error) {
+	hook := &interfaces.HookPayload{Stage: interfaces.HookEmbed, Phase: interfaces.HookBefore, Chunk: chunk}
+	if err := e.runHooks(ctx, hook); err != nil {
+		return nil, err
+	}
+
+	// Generate embedding
+	var result *models.Embedding
+	if chunk.Body != nil {
+		var err error
+		result, err = e.embedVector(ctx, chunk, embedder)
+		if err != nil {
+			return nil, err
 		}
+	}
+
+	hook.Phase, hook.Embedding = interfaces.HookAfter, result
+	if err := e.runHooks(ctx, hook); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
 
-		resultChan <- result
+// embedVector generates the embedding of chunk's body with embedder.
+func (e *ProcessingEngine) embedVector(
+	ctx context.Context,
+	chunk *models.Chunk,
+	embedder interfaces.Embedder,
+) (*models.Embedding, error) {
+	vector, err := embedder.GenerateEmbedding(ctx, *chunk.Body)
+	if err != nil {
+		return nil, fmt.Errorf("embedding generation failed: %w", err)
 	}
+	return e.newEmbedding(chunk, embedder, vector)
 }
 
+// newEmbedding returns the embedding record of chunk's vector, generated by embedder, with the
+// vector in the field for its dimension.
+func (e *ProcessingEngine) newEmbedding(
+	chunk *models.Chunk,
+	embedder interfaces.Embedder,
+	vector []float32,
+) (*models.Embedding, error) {
+	modelName := embedder.GetModelName()
+	if len(vector) != embedder.GetDimension() {
+		return nil, fmt.Errorf("%w: %s returned %d dimensions, expected %d", ErrEmbeddingDimMismatch,
+			modelName, len(vector), embedder.GetDimension())
+	}
+
+	// Create embedding record
+	result := &models.Embedding{
+		ID:         uuid.New().String(),
+		Model:      &modelName,
+		EmbeddedAt: time.Now(),
+		ObjectID:   chunk.ID,
+		ObjectType: "chunk",
+	}
+
+	// Set appropriate embedding field based on dimension
+	switch embedder.GetDimension() {
+	case embeddingDim768:
+		result.Embedding768 = vector
+	case embeddingDim1536:
+		result.Embedding1536 = vector
+	case embeddingDim3072:
+		result.Embedding3072 = vector
+	default:
+		e.logger.Error().
+			Str("model_name", modelName).
+			Int("dimension", embedder.GetDimension()).
+			Msg("Unsupported embedding dimension")
+		return nil, ErrUnsupportedEmbeddingDim
+	}
+	return result, nil
+}
+
+// Queries saveChunkAndEmbedding runs for every chunk.
+const (
+	chunkInsertQuery = `INSERT INTO chunks (id, document_id, parent_chunk_id, left_chunk_id, right_chunk_id,
+					body, byte_size, tokenizer, token_count, natural_lang, code_lang)
+					VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	chunkFTSInsertQuery  = `INSERT INTO chunks_fts (chunk_id, body) VALUES (?, ?)`
+	chunkMetaInsertQuery = `INSERT INTO chunk_meta (id, chunk_id, "key", meta, created_at) VALUES (?, ?, ?, ?, ?)`
+)
+
 func (e *ProcessingEngine) saveChunkAndEmbedding(
 	ctx context.Context,
 	chunk *models.Chunk,
 	embedding *models.Embedding,
 	db *sql.DB,
 ) error {
+	// Prepare the inserts before the transaction takes a connection; see stmtcache.Prepare
+	chunkStmt, err := stmtcache.Prepare(ctx, db, e.dialect.Rebind(chunkInsertQuery))
+	if err != nil {
+		e.logger.Error().Err(err).Msg("Failed to prepare chunk insert")
+		return err
+	}
+	var ftsStmt, embeddingStmt *sql.Stmt
+	// Index the chunk body for keyword search; FTS5 is only available on SQLite
+	if e.dialect == dialect.SQLite {
+		ftsStmt, err = stmtcache.Prepare(ctx, db, chunkFTSInsertQuery)
+		if err != nil {
+			e.logger.Error().Err(err).Msg("Failed to prepare chunk index insert")
+			return err
+		}
+	}
+	if embedding != nil {
+		embeddingStmt, err = e.prepareEmbeddingInsert(ctx, db, embedding)
+		if err != nil {
+			return err
+		}
+	}
+
 	tx, err := db.BeginTx(ctx, nil)
 	if err != nil {
 		e.logger.Error().Err(err).Msg("Failed to begin transaction")
@@ -545,12 +1322,7 @@ func (e *ProcessingEngine) saveChunkAndEmbedding(
 		}
 	}(tx)
 
-	// Insert chunk
-	chunkQuery := `INSERT INTO chunks (id, document_id, parent_chunk_id, left_chunk_id, right_chunk_id, 
-					body, byte_size, tokenizer, token_count, natural_lang, code_lang)
-					VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
-
-	_, err = tx.ExecContext(ctx, chunkQuery, chunk.ID, chunk.DocumentID, chunk.ParentChunkID,
+	_, err = tx.StmtContext(ctx, chunkStmt).ExecContext(ctx, chunk.ID, chunk.DocumentID, chunk.ParentChunkID,
 		chunk.LeftChunkID, chunk.RightChunkID, chunk.Body, chunk.ByteSize, chunk.Tokenizer,
 		chunk.TokenCount, chunk.NaturalLang, chunk.CodeLang)
 	if err != nil {
@@ -558,44 +1330,138 @@ func (e *ProcessingEngine) saveChunkAndEmbedding(
 		return err
 	}
 
-	// Insert embedding
-	if embedding != nil {
-		var embeddingQuery string
-		var embeddingValue []float32
-
-		switch {
-		case embedding.Embedding768 != nil:
-			embeddingQuery = `INSERT INTO embeddings (id, embedding_768, model, embedded_at, object_id, object_type)
-							VALUES (?, ?, ?, ?, ?, ?)`
-			embeddingValue = embedding.Embedding768
-		case embedding.Embedding1536 != nil:
-			embeddingQuery = `INSERT INTO embeddings (id, embedding_1536, model, embedded_at, object_id, object_type)
-							VALUES (?, ?, ?, ?, ?, ?)`
-			embeddingValue = embedding.Embedding1536
-		case embedding.Embedding3072 != nil:
-			embeddingQuery = `INSERT INTO embeddings (id, embedding_3072, model, embedded_at, object_id, object_type)
-							VALUES (?, ?, ?, ?, ?, ?)`
-			embeddingValue = embedding.Embedding3072
-		default:
-			return ErrNoEmbeddingVector
-		}
-
-		// Convert embedding to string format for SQLite
-		embeddingStr := fmt.Sprintf("[%v]", embeddingValue)
-
-		modelName := ""
-		if embedding.Model != nil {
-			modelName = *embedding.Model
-		}
-
-		_, err = tx.ExecContext(ctx, embeddingQuery, embedding.ID, embeddingStr,
-			modelName, embedding.EmbeddedAt.Format(time.RFC3339),
-			embedding.ObjectID, embedding.ObjectType)
-		if err != nil {
-			e.logger.Error().Err(err).Str("embedding_id", embedding.ID).Msg("Failed to insert embedding")
+	if ftsStmt != nil {
+		if _, err := tx.StmtContext(ctx, ftsStmt).ExecContext(ctx, chunk.ID, chunk.Body); err != nil {
+			e.logger.Error().Err(err).Str("chunk_id", chunk.ID).Msg("Failed to index chunk")
+			return err
+		}
+	}
+
+	if err := e.insertChunkMeta(ctx, tx, chunk); err != nil {
+		e.logger.Error().Err(err).Str("chunk_id", chunk.ID).Msg("Failed to save chunk metadata")
+		return err
+	}
+
+	if embeddingStmt != nil {
+		if err := e.insertEmbedding(ctx, tx, embeddingStmt, embedding); err != nil {
 			return err
 		}
 	}
 
 	return tx.Commit()
 }
+
+// insertChunkMeta stores the metadata chunkers and hooks attached to chunk, each value as JSON.
+func (e *ProcessingEngine) insertChunkMeta(ctx context.Context, tx *sql.Tx, chunk *models.Chunk) error {
+	if len(chunk.Meta) == 0 {
+		return nil
+	}
+	now := e.dialect.FormatTime(time.Now())
+	for key, value := range chunk.Meta {
+		metaJSON, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("chunk metadata %q: %w", key, err)
+		}
+		_, err = tx.ExecContext(ctx, e.dialect.Rebind(chunkMetaInsertQuery), uuid.New().String(), chunk.ID, key,
+			string(metaJSON), now)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateEmbedding checks that embedding has exactly one vector, of the length its column
+// declares, so a vector changed by a hook or built by hand is not stored in the wrong column.
+func validateEmbedding(embedding *models.Embedding) error {
+	vectors := []struct {
+		values    []float32
+		dimension int
+	}{
+		{embedding.Embedding768, embeddingDim768},
+		{embedding.Embedding1536, embeddingDim1536},
+		{embedding.Embedding3072, embeddingDim3072},
+	}
+	found := 0
+	for _, vector := range vectors {
+		if vector.values == nil {
+			continue
+		}
+		found++
+		if len(vector.values) != vector.dimension {
+			return fmt.Errorf("%w: %d values stored as a %d-dimension vector", ErrEmbeddingDimMismatch,
+				len(vector.values), vector.dimension)
+		}
+	}
+	switch found {
+	case 0:
+		return ErrNoEmbeddingVector
+	case 1:
+		return nil
+	default:
+		return fmt.Errorf("%w: %d vectors set", ErrEmbeddingDimMismatch, found)
+	}
+}
+
+// prepareEmbeddingInsert checks embedding and prepares the insert storing it in the column for its
+// dimension. Call it before beginning the transaction the insert runs in; see stmtcache.Prepare.
+func (e *ProcessingEngine) prepareEmbeddingInsert(
+	ctx context.Context,
+	db *sql.DB,
+	embedding *models.Embedding,
+) (*sql.Stmt, error) {
+	if err := validateEmbedding(embedding); err != nil {
+		e.logger.Error().Err(err).Str("embedding_id", embedding.ID).Msg("Refusing to store malformed embedding")
+		return nil, err
+	}
+	query, _ := embeddingInsert(embedding)
+	stmt, err := stmtcache.Prepare(ctx, db, e.dialect.Rebind(query))
+	if err != nil {
+		e.logger.Error().Err(err).Str("embedding_id", embedding.ID).Msg("Failed to prepare embedding insert")
+		return nil, err
+	}
+	return stmt, nil
+}
+
+// embeddingInsert returns the query storing embedding in the column for its dimension, and the
+// vector it stores.
+func embeddingInsert(embedding *models.Embedding) (string, []float32) {
+	switch {
+	case embedding.Embedding768 != nil:
+		return `INSERT INTO embeddings (id, embedding_768, model, embedded_at, object_id, object_type)
+						VALUES (?, ?, ?, ?, ?, ?)`, embedding.Embedding768
+	case embedding.Embedding1536 != nil:
+		return `INSERT INTO embeddings (id, embedding_1536, model, embedded_at, object_id, object_type)
+						VALUES (?, ?, ?, ?, ?, ?)`, embedding.Embedding1536
+	default:
+		return `INSERT INTO embeddings (id, embedding_3072, model, embedded_at, object_id, object_type)
+						VALUES (?, ?, ?, ?, ?, ?)`, embedding.Embedding3072
+	}
+}
+
+// insertEmbedding stores embedding in tx with stmt, prepared by prepareEmbeddingInsert.
+func (e *ProcessingEngine) insertEmbedding(
+	ctx context.Context,
+	tx *sql.Tx,
+	stmt *sql.Stmt,
+	embedding *models.Embedding,
+) error {
+	_, embeddingValue := embeddingInsert(embedding)
+
+	// Store the vector as a little-endian float32 BLOB
+	embeddingBlob := vector.Encode(embeddingValue)
+
+	modelName := ""
+	if embedding.Model != nil {
+		modelName = *embedding.Model
+	}
+
+	_, err := tx.StmtContext(ctx, stmt).ExecContext(ctx, embedding.ID, embeddingBlob,
+		modelName, e.dialect.FormatTime(embedding.EmbeddedAt),
+		embedding.ObjectID, embedding.ObjectType)
+	if err != nil {
+		e.logger.Error().Err(err).Str("embedding_id", embedding.ID).Msg("Failed to insert embedding")
+		return err
+	}
+	return nil
+}
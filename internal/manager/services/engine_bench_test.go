@@ -0,0 +1,203 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/code-sleuth/ike-go/internal/manager/chunkers"
+	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
+	"github.com/code-sleuth/ike-go/internal/manager/models"
+	"github.com/code-sleuth/ike-go/internal/manager/testutil"
+
+	"github.com/google/uuid"
+	"golang.org/x/sync/errgroup"
+)
+
+// benchImporter stores a source and a download of body for every URL it imports, so each
+// benchmark iteration is a new source the engine chunks and embeds in full.
+type benchImporter struct {
+	body string
+}
+
+func (b *benchImporter) Import(ctx context.Context, sourceURL string, db *sql.DB) (*interfaces.ImportResult, error) {
+	sourceID, downloadID := uuid.New().String(), uuid.New().String()
+	_, err := db.ExecContext(ctx, `INSERT INTO sources (id, raw_url, host, active_domain) VALUES (?, ?, 'github.com', 1)`,
+		sourceID, sourceURL)
+	if err != nil {
+		return nil, err
+	}
+	_, err = db.ExecContext(ctx, `INSERT INTO downloads (id, source_id, headers, attempted_at, downloaded_at, status_code, body)
+		VALUES (?, ?, '{}', ?, ?, 200, ?)`, downloadID, sourceID, time.Now().UTC().Format(time.RFC3339),
+		time.Now().UTC().Format(time.RFC3339), b.body)
+	if err != nil {
+		return nil, err
+	}
+	return &interfaces.ImportResult{SourceID: sourceID, DownloadID: downloadID}, nil
+}
+
+func (b *benchImporter) GetSourceType() string         { return "github" }
+func (b *benchImporter) ValidateSource(_ string) error { return nil }
+
+// benchTransformer stores a document whose content is the download's body.
+type benchTransformer struct{}
+
+func (benchTransformer) Transform(
+	ctx context.Context,
+	download *models.Download,
+	db *sql.DB,
+) (*interfaces.TransformResult, error) {
+	document := &models.Document{ID: uuid.New().String(), SourceID: download.SourceID, DownloadID: download.ID}
+	_, err := db.ExecContext(ctx, `INSERT INTO documents (id, source_id, download_id, min_chunk_size, max_chunk_size)
+		VALUES (?, ?, ?, 100, 8191)`, document.ID, document.SourceID, document.DownloadID)
+	if err != nil {
+		return nil, err
+	}
+	return &interfaces.TransformResult{Document: document, Content: *download.Body}, nil
+}
+
+func (benchTransformer) GetSourceType() string              { return "github" }
+func (benchTransformer) CanTransform(*models.Download) bool { return true }
+
+// benchEmbedder returns the same vector for every content, one at a time or in batches, at no cost,
+// so the benchmarks measure the engine rather than an embedding API.
+type benchEmbedder struct {
+	mockEmbedder
+}
+
+func (b *benchEmbedder) GenerateEmbeddings(_ context.Context, contents []string) ([][]float32, error) {
+	vectors := make([][]float32, len(contents))
+	for i := range vectors {
+		vectors[i] = b.embedding
+	}
+	return vectors, nil
+}
+
+// benchContent returns about size bytes of prose, varied enough that the tokenizer doesn't see one
+// repeated token.
+func benchContent(size int) string {
+	var builder strings.Builder
+	for i := 0; builder.Len() < size; i++ {
+		fmt.Fprintf(&builder, "Paragraph %d explains how the importer fetches page %d, transforms it into "+
+			"markdown, and hands chunk %d to the embedder before saving it.\n\n", i, i*7, i*13)
+	}
+	return builder.String()
+}
+
+// BenchmarkProcessingEngine_ProcessSource measures the whole pipeline, importing, transforming,
+// chunking with the token chunker, embedding with a free mock embedder, and saving, so regressions
+// in chunking and database writes show up. It needs the test database from .env:
+//
+//	go test ./internal/manager/services -run '^$' -bench ProcessSource -benchmem
+func BenchmarkProcessingEngine_ProcessSource(b *testing.B) {
+	if testing.Short() {
+		b.Skip("Skipping integration benchmark in short mode")
+	}
+	db := testutil.SetupTestDB(b)
+	defer testutil.CleanupTestDB(b, db)
+
+	chunker, err := chunkers.NewTokenChunker()
+	if err != nil {
+		b.Fatalf("Failed to create chunker: %v", err)
+	}
+	embedding := make([]float32, 1536)
+	for i := range embedding {
+		embedding[i] = float32(i%17) / 17
+	}
+
+	for _, size := range []int{16 << 10, 256 << 10} {
+		for _, batchSize := range []int{1, DefaultEmbeddingBatchSize} {
+			b.Run(fmt.Sprintf("content=%dKiB/batch=%d", size>>10, batchSize), func(b *testing.B) {
+				engine := NewProcessingEngine()
+				_ = engine.RegisterImporter(&benchImporter{body: benchContent(size)})
+				_ = engine.RegisterTransformer(benchTransformer{})
+				_ = engine.RegisterChunker(chunker)
+				_ = engine.RegisterEmbedder(&benchEmbedder{mockEmbedder{
+					modelName: "text-embedding-3-small", dimension: 1536, maxTokens: 8191, embedding: embedding,
+				}})
+				engine.SetEmbeddingBatch(batchSize, DefaultEmbeddingBatchTokens)
+				options := &interfaces.ProcessingOptions{
+					MaxTokens:      512,
+					ChunkStrategy:  "token",
+					EmbeddingModel: "text-embedding-3-small",
+					Concurrency:    4,
+				}
+
+				b.SetBytes(int64(size))
+				for i := 0; b.Loop(); i++ {
+					sourceURL := fmt.Sprintf("https://github.com/bench/repo/blob/main/doc-%d.md", i)
+					if err := engine.ProcessSource(b.Context(), sourceURL, options, db); err != nil {
+						b.Fatalf("Failed to process source: %v", err)
+					}
+				}
+			})
+		}
+	}
+}
+
+// benchChunksPerDocument is how many chunks BenchmarkProcessingEngine_SaveChunks saves per iteration,
+// as one document of bulk ingest.
+const benchChunksPerDocument = 20
+
+// BenchmarkProcessingEngine_SaveChunks measures bulk ingest throughput, saving the chunks and
+// embeddings of a document with one to eight concurrent writers, as Concurrency sets, so contention
+// for the database's write lock shows up. It needs the test database from .env:
+//
+//	go test ./internal/manager/services -run '^$' -bench SaveChunks
+func BenchmarkProcessingEngine_SaveChunks(b *testing.B) {
+	if testing.Short() {
+		b.Skip("Skipping integration benchmark in short mode")
+	}
+	db := testutil.SetupTestDB(b)
+	defer testutil.CleanupTestDB(b, db)
+
+	body := benchContent(2048)
+	sourceURL := "https://github.com/bench/repo/blob/main/ingest.md"
+	result, err := (&benchImporter{body: body}).Import(b.Context(), sourceURL, db)
+	if err != nil {
+		b.Fatalf("Failed to import source: %v", err)
+	}
+	document := &models.Document{ID: uuid.New().String()}
+	_, err = db.ExecContext(b.Context(), `INSERT INTO documents (id, source_id, download_id, min_chunk_size, max_chunk_size)
+		VALUES (?, ?, ?, 100, 8191)`, document.ID, result.SourceID, result.DownloadID)
+	if err != nil {
+		b.Fatalf("Failed to create document: %v", err)
+	}
+
+	embedder := &benchEmbedder{mockEmbedder{
+		modelName: "text-embedding-3-small", dimension: 1536, maxTokens: 8191, embedding: make([]float32, 1536),
+	}}
+	byteSize, tokenizer := len(body), "cl100k_base"
+
+	for _, writers := range []int{1, 4, 8} {
+		b.Run(fmt.Sprintf("writers=%d", writers), func(b *testing.B) {
+			engine := NewProcessingEngine()
+			b.SetBytes(int64(benchChunksPerDocument * byteSize))
+
+			for b.Loop() {
+				var group errgroup.Group
+				group.SetLimit(writers)
+				for range benchChunksPerDocument {
+					group.Go(func() error {
+						chunk := &models.Chunk{
+							ID: uuid.New().String(), DocumentID: document.ID, Body: &body, ByteSize: &byteSize,
+							Tokenizer: &tokenizer,
+						}
+						embedding, err := engine.newEmbedding(chunk, embedder, embedder.embedding)
+						if err != nil {
+							return err
+						}
+						return engine.saveChunkAndEmbedding(b.Context(), chunk, embedding, db)
+					})
+				}
+				if err := group.Wait(); err != nil {
+					b.Fatalf("Failed to save chunks: %v", err)
+				}
+			}
+			b.ReportMetric(float64(b.N*benchChunksPerDocument)/b.Elapsed().Seconds(), "chunks/s")
+		})
+	}
+}
@@ -1,13 +1,16 @@
 package services
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
 	"github.com/code-sleuth/ike-go/internal/manager/models"
+	"github.com/rs/zerolog"
 )
 
 // Mock implementations for testing
@@ -133,6 +136,16 @@ func TestNewProcessingEngine(t *testing.T) {
 	}
 }
 
+func TestNewProcessingEngine_WithLogger(t *testing.T) {
+	var output bytes.Buffer
+	engine := NewProcessingEngine(WithLogger(zerolog.New(&output)))
+
+	engine.logger.Info().Msg("routed")
+	if !strings.Contains(output.String(), "routed") {
+		t.Errorf("Expected the engine to log through the given logger, got %q", output.String())
+	}
+}
+
 // Test RegisterImporter
 func TestProcessingEngine_RegisterImporter(t *testing.T) {
 	tests := []struct {
@@ -349,6 +362,42 @@ func TestProcessingEngine_RegisterEmbedder(t *testing.T) {
 	}
 }
 
+// Test embedderFor creating unregistered embedders with the factory
+func TestProcessingEngine_embedderFor(t *testing.T) {
+	engine := NewProcessingEngine()
+	if _, err := engine.embedderFor("text-embedding-3-large"); !errors.Is(err, ErrNoEmbedderRegistered) {
+		t.Errorf("Expected ErrNoEmbedderRegistered without a factory, got %v", err)
+	}
+
+	errUnsupported := errors.New("unsupported model")
+	var created int
+	engine.SetEmbedderFactory(func(model string) (interfaces.Embedder, error) {
+		if model == "unknown" {
+			return nil, errUnsupported
+		}
+		created++
+		return &mockEmbedder{modelName: model}, nil
+	})
+
+	for range 2 {
+		embedder, err := engine.embedderFor("text-embedding-3-large")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if embedder.GetModelName() != "text-embedding-3-large" {
+			t.Errorf("Expected text-embedding-3-large, got %s", embedder.GetModelName())
+		}
+	}
+	if created != 1 {
+		t.Errorf("Expected the embedder to be created once and reused, got %d creations", created)
+	}
+
+	_, err := engine.embedderFor("unknown")
+	if !errors.Is(err, ErrNoEmbedderRegistered) || !errors.Is(err, errUnsupported) {
+		t.Errorf("Expected ErrNoEmbedderRegistered wrapping the factory error, got %v", err)
+	}
+}
+
 // Test RegisterUpdater
 func TestProcessingEngine_RegisterUpdater(t *testing.T) {
 	tests := []struct {
@@ -3,10 +3,16 @@ package services
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/code-sleuth/ike-go/internal/manager/chunkers"
 	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
 	"github.com/code-sleuth/ike-go/internal/manager/models"
+	"github.com/code-sleuth/ike-go/internal/manager/tokenizers"
 )
 
 // Test chunk worker logic without database operations
@@ -432,3 +438,178 @@ func TestProcessingEngine_ProcessingOptions(t *testing.T) {
 		})
 	}
 }
+
+// Test that the shared embedding pool caps chunks embedded at once across callers
+func TestProcessingEngine_embedChunk_SharedPool(t *testing.T) {
+	engine := NewProcessingEngine()
+	engine.SetEmbeddingLimit(2)
+
+	var active, peak atomic.Int32
+	errStop := errors.New("stop before saving")
+	err := engine.RegisterHook(interfaces.HookEmbed, interfaces.HookBefore,
+		func(context.Context, *interfaces.HookPayload) error {
+			n := active.Add(1)
+			defer active.Add(-1)
+			for {
+				current := peak.Load()
+				if n <= current || peak.CompareAndSwap(current, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			return errStop
+		})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	embedder := &mockEmbedder{modelName: "text-embedding-ada-002", dimension: 1536}
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			chunk := &models.Chunk{Body: stringPtr("content")}
+			result := engine.embedChunk(context.Background(), chunk, "doc-123", embedder, nil)
+			if result.Chunk.ID == "" {
+				t.Error("Expected chunk ID to be generated")
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := peak.Load(); got != 2 {
+		t.Errorf("Expected at most 2 chunks embedded at once, got %d", got)
+	}
+}
+
+// Test that a chunk waiting for the pool gives up when its context is cancelled
+func TestProcessingEngine_embedChunk_Cancelled(t *testing.T) {
+	engine := NewProcessingEngine()
+	engine.SetEmbeddingLimit(1)
+	if err := engine.embedSlots.Acquire(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer engine.embedSlots.Release()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	embedder := &mockEmbedder{modelName: "text-embedding-ada-002", dimension: 1536}
+	result := engine.embedChunk(ctx, &models.Chunk{Body: stringPtr("content")}, "doc-123", embedder, nil)
+	if !errors.Is(result.Error, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", result.Error)
+	}
+}
+
+type tokenizingEmbedder struct {
+	mockEmbedder
+}
+
+func (m *tokenizingEmbedder) GetTokenizer() interfaces.Tokenizer {
+	return tokenizers.WordPiece{}
+}
+
+// Test that chunks are sized and counted with the embedder's tokenizer when it has one
+func TestChunkerFor(t *testing.T) {
+	tokenChunker, err := chunkers.NewTokenChunker()
+	if err != nil {
+		t.Fatalf("Failed to create token chunker: %v", err)
+	}
+	content := "Tokenization differs between models."
+
+	chunks, err := chunkerFor(tokenChunker, &mockEmbedder{}).ChunkDocument(content, 100)
+	if err != nil || len(chunks) != 1 || *chunks[0].Tokenizer != "cl100k_base" {
+		t.Fatalf("Expected one cl100k_base chunk, got %v, %v", chunks, err)
+	}
+
+	chunks, err = chunkerFor(tokenChunker, &tokenizingEmbedder{}).ChunkDocument(content, 100)
+	if err != nil || len(chunks) != 1 {
+		t.Fatalf("Expected one chunk, got %v, %v", chunks, err)
+	}
+	// Words of seven or more characters are two pieces, and the period is one more token
+	if *chunks[0].Tokenizer != "wordpiece" || *chunks[0].TokenCount != 8 {
+		t.Errorf("Expected 8 wordpiece tokens, got %d %s", *chunks[0].TokenCount, *chunks[0].Tokenizer)
+	}
+
+	mock := &mockChunker{strategy: "mock"}
+	if chunkerFor(mock, &tokenizingEmbedder{}) != mock {
+		t.Error("Expected a chunker without tokenizer support to be used as is")
+	}
+}
+
+// Test that chunks get IDs and links to their neighbours before they are embedded
+func TestLinkChunks(t *testing.T) {
+	parentID := "parent"
+	chunks := []*models.Chunk{{ID: "first"}, {Body: stringPtr("content")}, {ParentChunkID: &parentID}}
+	linkChunks(chunks, "doc-123")
+
+	for i, chunk := range chunks {
+		if chunk.ID == "" || chunk.DocumentID != "doc-123" {
+			t.Fatalf("Chunk %d was not assigned an ID and document: %+v", i, chunk)
+		}
+	}
+	if chunks[0].ID != "first" {
+		t.Errorf("Expected the chunker's ID to be kept, got %s", chunks[0].ID)
+	}
+	if chunks[0].LeftChunkID != nil || *chunks[0].RightChunkID != chunks[1].ID ||
+		*chunks[1].LeftChunkID != "first" || *chunks[1].RightChunkID != chunks[2].ID ||
+		*chunks[2].LeftChunkID != chunks[1].ID || chunks[2].RightChunkID != nil {
+		t.Error("Expected each chunk to link to its neighbours")
+	}
+	if *chunks[2].ParentChunkID != "parent" {
+		t.Errorf("Expected the parent link to be kept, got %s", *chunks[2].ParentChunkID)
+	}
+
+	// embedChunk keeps the linked ID
+	engine := NewProcessingEngine()
+	embedder := &mockEmbedder{modelName: "text-embedding-ada-002", dimension: 1536, embedError: errors.New("failed")}
+	result := engine.embedChunk(context.Background(), chunks[1], "doc-123", embedder, nil)
+	if result.Chunk.ID != *chunks[0].RightChunkID {
+		t.Errorf("Expected the chunk to keep its ID, got %s", result.Chunk.ID)
+	}
+}
+
+// Test that a vector of the wrong length fails before it is saved
+func TestProcessingEngine_embed_DimensionMismatch(t *testing.T) {
+	engine := NewProcessingEngine()
+	embedder := &mockEmbedder{modelName: "text-embedding-3-small", dimension: 1536, embedding: make([]float32, 1535)}
+	_, err := engine.embed(context.Background(), &models.Chunk{ID: "chunk-1", Body: stringPtr("content")}, embedder)
+	if !errors.Is(err, ErrEmbeddingDimMismatch) {
+		t.Errorf("Expected ErrEmbeddingDimMismatch, got %v", err)
+	}
+}
+
+func TestValidateEmbedding(t *testing.T) {
+	tests := []struct {
+		name        string
+		embedding   models.Embedding
+		expectedErr error
+	}{
+		{name: "768", embedding: models.Embedding{Embedding768: make([]float32, 768)}},
+		{name: "3072", embedding: models.Embedding{Embedding3072: make([]float32, 3072)}},
+		{name: "none", expectedErr: ErrNoEmbeddingVector},
+		{name: "short", embedding: models.Embedding{Embedding1536: make([]float32, 768)},
+			expectedErr: ErrEmbeddingDimMismatch},
+		{name: "two vectors", embedding: models.Embedding{Embedding768: make([]float32, 768),
+			Embedding1536: make([]float32, 1536)}, expectedErr: ErrEmbeddingDimMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateEmbedding(&tt.embedding); !errors.Is(err, tt.expectedErr) {
+				t.Errorf("Expected %v, got %v", tt.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestNothingToImport(t *testing.T) {
+	for _, err := range []error{interfaces.ErrNoMatchingPaths, fmt.Errorf("import: %w", interfaces.ErrNoChanges)} {
+		if !nothingToImport(err) {
+			t.Errorf("Expected %v to mean nothing to import", err)
+		}
+	}
+	if nothingToImport(nil) || nothingToImport(errors.New("boom")) {
+		t.Error("Expected only ErrNoMatchingPaths and ErrNoChanges to mean nothing to import")
+	}
+}
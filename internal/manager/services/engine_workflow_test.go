@@ -317,21 +317,7 @@ func TestProcessingEngine_chunkWorker(t *testing.T) {
 			engine := NewProcessingEngine()
 			embedder, chunks := tt.setup()
 
-			// Create channels for worker communication
-			chunkChan := make(chan *models.Chunk, len(chunks))
-			resultChan := make(chan *interfaces.ChunkResult, len(chunks))
-
-			// Send chunks to worker
-			for _, chunk := range chunks {
-				chunkChan <- chunk
-			}
-			close(chunkChan)
-
-			// Run worker in goroutine
-			go engine.chunkWorker(context.Background(), chunkChan, resultChan, "doc-123", embedder, testDB)
-
-			// Collect results
-			result := <-resultChan
+			result := engine.embedChunk(context.Background(), chunks[0], "doc-123", embedder, testDB)
 
 			if tt.expectError && result.Error == nil {
 				t.Errorf("Expected error but got none for test: %s", tt.description)
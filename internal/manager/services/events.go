@@ -0,0 +1,42 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
+	"github.com/code-sleuth/ike-go/internal/manager/models"
+	"github.com/code-sleuth/ike-go/internal/manager/repository"
+)
+
+// recordEvent appends an event to the event log. A failure is logged rather than returned so the
+// log never fails the pipeline it describes.
+func (e *ProcessingEngine) recordEvent(
+	ctx context.Context,
+	db *sql.DB,
+	eventType models.EventType,
+	sourceID, documentID, detail string,
+) {
+	event := &models.Event{Type: eventType, SourceID: optional(sourceID), DocumentID: optional(documentID),
+		Detail: optional(detail)}
+	if err := repository.RecordEvent(context.WithoutCancel(ctx), db, e.dialect, event); err != nil {
+		e.logger.Error().Err(err).Str("event_type", string(eventType)).Msg("Failed to record event")
+	}
+}
+
+// importDetail describes an import for the event log.
+func importDetail(sourceURL string, report *interfaces.ImportReport) string {
+	if report == nil {
+		return sourceURL
+	}
+	return fmt.Sprintf("%s: %d imported, %d skipped, %d failed", sourceURL, report.Imported, report.Skipped,
+		report.Failed)
+}
+
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,30 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
+)
+
+func TestImportDetail(t *testing.T) {
+	tests := []struct {
+		name     string
+		report   *interfaces.ImportReport
+		expected string
+	}{
+		{name: "without report", expected: "https://github.com/owner/repo"},
+		{
+			name:     "with report",
+			report:   &interfaces.ImportReport{Imported: 3, Skipped: 2, Failed: 1},
+			expected: "https://github.com/owner/repo: 3 imported, 2 skipped, 1 failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := importDetail("https://github.com/owner/repo", tt.report); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/code-sleuth/ike-go/pkg/util"
+)
+
+// runSourceIDs selects the sources any run of a URL imported, so a run that fails before
+// importing anything still marks the sources earlier runs created as failing.
+const runSourceIDs = `SELECT i.source_id FROM pipeline_run_items i JOIN pipeline_runs r ON r.id = i.run_id
+	WHERE r.source_url = ? AND i.source_id IS NOT NULL`
+
+// failedItemSourceIDs selects the sources of the items of a run that failed to process.
+const failedItemSourceIDs = `SELECT source_id FROM pipeline_run_items
+	WHERE run_id = ? AND status = 'failed' AND source_id IS NOT NULL`
+
+// recordHealth updates the health of the sources of the run's URL after it finished with cause.
+// A run that completed, or that only failed to process some items, is a success for every source
+// but those of the failed items; any other failure, such as an importer that can no longer reach
+// a feed or is refused with a revoked token, fails them all. Runs stopped by shutdown or
+// cancellation say nothing about the sources and are not recorded.
+func (r *pipelineRun) recordHealth(ctx context.Context, cause error) error {
+	if errors.Is(cause, ErrShuttingDown) || errors.Is(cause, context.Canceled) {
+		return nil
+	}
+	ctx = context.WithoutCancel(ctx)
+	now := r.dialect.FormatTime(r.now())
+
+	if cause != nil && !errors.Is(cause, ErrRunIncomplete) {
+		// #nosec G202 -- the subquery is a constant
+		query := `UPDATE sources SET last_error = ?, consecutive_failure_count = consecutive_failure_count + 1
+			WHERE deleted_at IS NULL AND id IN (` + runSourceIDs + `)`
+		_, err := r.db.ExecContext(ctx, r.dialect.Rebind(query), util.RedactString(cause.Error()), r.sourceURL)
+		return err
+	}
+
+	// #nosec G202 -- the subqueries are constants
+	query := `UPDATE sources SET last_success_at = ?, last_error = NULL, consecutive_failure_count = 0
+		WHERE deleted_at IS NULL AND id IN (` + runSourceIDs + `) AND id NOT IN (` + failedItemSourceIDs + `)`
+	if _, err := r.db.ExecContext(ctx, r.dialect.Rebind(query), now, r.sourceURL, r.id); err != nil {
+		return err
+	}
+	if cause == nil {
+		return nil
+	}
+
+	// Each source of a failed item keeps the error its item failed with
+	// #nosec G202 -- the subquery is a constant
+	query = `UPDATE sources SET consecutive_failure_count = consecutive_failure_count + 1,
+		last_error = COALESCE((SELECT i.error FROM pipeline_run_items i
+			WHERE i.run_id = ? AND i.source_id = sources.id AND i.status = 'failed' LIMIT 1), ?)
+		WHERE deleted_at IS NULL AND id IN (` + failedItemSourceIDs + `)`
+	_, err := r.db.ExecContext(ctx, r.dialect.Rebind(query), r.id, util.RedactString(cause.Error()), r.id)
+	return err
+}
@@ -0,0 +1,85 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/code-sleuth/ike-go/internal/manager/testutil"
+	"github.com/code-sleuth/ike-go/pkg/dialect"
+)
+
+// Runs stopped by shutdown or cancellation don't touch the database.
+func TestPipelineRun_recordHealth_Interrupted(t *testing.T) {
+	run := &pipelineRun{dialect: dialect.SQLite, now: time.Now}
+	for _, cause := range []error{ErrShuttingDown, fmt.Errorf("embed: %w", context.Canceled)} {
+		if err := run.recordHealth(context.Background(), cause); err != nil {
+			t.Errorf("Expected %v not to be recorded, got %v", cause, err)
+		}
+	}
+}
+
+func TestPipelineRun_recordHealth_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db := testutil.SetupTestDB(t)
+	defer testutil.CleanupTestDB(t, db)
+
+	sourceURL := "https://github.com/owner/repo"
+	statements := []string{
+		`INSERT INTO sources (id, raw_url, host, active_domain) VALUES
+			('source-1', '` + sourceURL + `/blob/main/a.md', 'github.com', 1),
+			('source-2', '` + sourceURL + `/blob/main/b.md', 'github.com', 1)`,
+		`INSERT INTO pipeline_runs (id, source_url, status, started_at, updated_at)
+			VALUES ('run-1', '` + sourceURL + `', 'running', '2024-01-01T00:00:00Z', '2024-01-01T00:00:00Z')`,
+		`INSERT INTO pipeline_run_items (run_id, item_key, position, source_id, download_id, status, error, updated_at)
+			VALUES ('run-1', 'a.md', 0, 'source-1', 'download-1', 'done', NULL, '2024-01-01T00:00:00Z'),
+			       ('run-1', 'b.md', 1, 'source-2', 'download-2', 'failed', 'embedding failed',
+			        '2024-01-01T00:00:00Z')`,
+	}
+	for _, statement := range statements {
+		if _, err := db.Exec(statement); err != nil {
+			t.Fatalf("Failed to set up test data: %v", err)
+		}
+	}
+
+	run := &pipelineRun{id: "run-1", sourceURL: sourceURL, db: db, dialect: dialect.SQLite, now: time.Now}
+	health := func(id string) (bool, string, int) {
+		var lastSuccess, lastError *string
+		var failures int
+		err := db.QueryRow(`SELECT last_success_at, last_error, consecutive_failure_count FROM sources WHERE id = ?`,
+			id).Scan(&lastSuccess, &lastError, &failures)
+		if err != nil {
+			t.Fatalf("Failed to read health of %s: %v", id, err)
+		}
+		message := ""
+		if lastError != nil {
+			message = *lastError
+		}
+		return lastSuccess != nil, message, failures
+	}
+
+	if err := run.recordHealth(context.Background(), fmt.Errorf("%w: 1 of 2 items failed", ErrRunIncomplete)); err != nil {
+		t.Fatalf("Failed to record health: %v", err)
+	}
+	if succeeded, message, failures := health("source-1"); !succeeded || message != "" || failures != 0 {
+		t.Errorf("Expected source-1 healthy, got %v %q %d", succeeded, message, failures)
+	}
+	if succeeded, message, failures := health("source-2"); succeeded || message != "embedding failed" || failures != 1 {
+		t.Errorf("Expected source-2 to fail with its item's error, got %v %q %d", succeeded, message, failures)
+	}
+
+	if err := run.recordHealth(context.Background(), errors.New("401 Bad credentials")); err != nil {
+		t.Fatalf("Failed to record health: %v", err)
+	}
+	if _, message, failures := health("source-1"); message != "401 Bad credentials" || failures != 1 {
+		t.Errorf("Expected an import failure to fail source-1, got %q %d", message, failures)
+	}
+	if _, _, failures := health("source-2"); failures != 2 {
+		t.Errorf("Expected source-2 to have failed twice in a row, got %d", failures)
+	}
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
+)
+
+var (
+	ErrInvalidHook = errors.New("invalid hook stage or phase")
+	ErrHookFailed  = errors.New("hook failed")
+)
+
+// hookKey identifies the hooks that run at one point of the pipeline.
+type hookKey struct {
+	stage interfaces.HookStage
+	phase interfaces.HookPhase
+}
+
+// RegisterHook adds hook to run before or after stage. Hooks at the same point run in the order
+// they were registered, and the first error stops the rest.
+func (e *ProcessingEngine) RegisterHook(
+	stage interfaces.HookStage,
+	phase interfaces.HookPhase,
+	hook interfaces.Hook,
+) error {
+	switch stage {
+	case interfaces.HookImport, interfaces.HookTransform, interfaces.HookChunk, interfaces.HookEmbed:
+	default:
+		return fmt.Errorf("%w: %q", ErrInvalidHook, stage)
+	}
+	if phase != interfaces.HookBefore && phase != interfaces.HookAfter {
+		return fmt.Errorf("%w: %q", ErrInvalidHook, phase)
+	}
+	if hook == nil {
+		return fmt.Errorf("%w: nil hook", ErrInvalidHook)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	key := hookKey{stage: stage, phase: phase}
+	e.hooks[key] = append(e.hooks[key], hook)
+	e.logger.Info().Str("stage", string(stage)).Str("phase", string(phase)).Msg("Registered hook")
+	return nil
+}
+
+// hasHooks reports whether any hook is registered to run at stage and phase.
+func (e *ProcessingEngine) hasHooks(stage interfaces.HookStage, phase interfaces.HookPhase) bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return len(e.hooks[hookKey{stage: stage, phase: phase}]) > 0
+}
+
+// runHooks runs the hooks registered for the payload's stage and phase.
+func (e *ProcessingEngine) runHooks(ctx context.Context, payload *interfaces.HookPayload) error {
+	e.mu.RLock()
+	hooks := e.hooks[hookKey{stage: payload.Stage, phase: payload.Phase}]
+	e.mu.RUnlock()
+
+	for _, hook := range hooks {
+		if err := hook(ctx, payload); err != nil {
+			return fmt.Errorf("%w: %s %s: %w", ErrHookFailed, payload.Phase, payload.Stage, err)
+		}
+	}
+	return nil
+}
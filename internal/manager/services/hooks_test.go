@@ -0,0 +1,124 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
+	"github.com/code-sleuth/ike-go/internal/manager/models"
+)
+
+func TestProcessingEngine_RegisterHook(t *testing.T) {
+	noop := func(context.Context, *interfaces.HookPayload) error { return nil }
+
+	tests := []struct {
+		name        string
+		stage       interfaces.HookStage
+		phase       interfaces.HookPhase
+		hook        interfaces.Hook
+		expectedErr error
+		description string
+	}{
+		{name: "valid", stage: interfaces.HookEmbed, phase: interfaces.HookBefore, hook: noop,
+			description: "should register a hook for a known stage and phase"},
+		{name: "unknown stage", stage: "upload", phase: interfaces.HookBefore, hook: noop,
+			expectedErr: ErrInvalidHook, description: "should reject an unknown stage"},
+		{name: "unknown phase", stage: interfaces.HookImport, phase: "during", hook: noop,
+			expectedErr: ErrInvalidHook, description: "should reject an unknown phase"},
+		{name: "nil hook", stage: interfaces.HookImport, phase: interfaces.HookAfter,
+			expectedErr: ErrInvalidHook, description: "should reject a nil hook"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewProcessingEngine().RegisterHook(tt.stage, tt.phase, tt.hook)
+			if !errors.Is(err, tt.expectedErr) {
+				t.Errorf("Expected error %v, got %v for test: %s", tt.expectedErr, err, tt.description)
+			}
+		})
+	}
+}
+
+// Test that hooks run in registration order and the first error stops the rest.
+func TestProcessingEngine_runHooks(t *testing.T) {
+	engine := NewProcessingEngine()
+	hookErr := errors.New("invalid content")
+
+	var calls []string
+	register := func(name string, err error) {
+		hook := func(_ context.Context, payload *interfaces.HookPayload) error {
+			calls = append(calls, name+":"+string(payload.Phase))
+			return err
+		}
+		if err := engine.RegisterHook(interfaces.HookChunk, interfaces.HookBefore, hook); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	register("first", nil)
+	register("second", hookErr)
+	register("third", nil)
+
+	err := engine.runHooks(context.Background(), &interfaces.HookPayload{
+		Stage: interfaces.HookChunk, Phase: interfaces.HookBefore,
+	})
+	if !errors.Is(err, ErrHookFailed) || !errors.Is(err, hookErr) {
+		t.Errorf("Expected the hook's error wrapped in ErrHookFailed, got %v", err)
+	}
+	if len(calls) != 2 || calls[0] != "first:before" || calls[1] != "second:before" {
+		t.Errorf("Expected the first two hooks to run in order, got %v", calls)
+	}
+
+	err = engine.runHooks(context.Background(), &interfaces.HookPayload{
+		Stage: interfaces.HookChunk, Phase: interfaces.HookAfter,
+	})
+	if err != nil {
+		t.Errorf("Expected no hooks to run after chunking, got %v", err)
+	}
+}
+
+// Test that embed hooks see the chunk and its embedding, and that their errors fail the chunk
+// before anything is saved.
+func TestProcessingEngine_processChunk_Hooks(t *testing.T) {
+	hookErr := errors.New("rejected")
+	embedder := &mockEmbedder{modelName: "text-embedding-3-small", dimension: 1536,
+		embedding: make([]float32, 1536)}
+
+	tests := []struct {
+		name        string
+		phase       interfaces.HookPhase
+		description string
+	}{
+		{name: "before embed", phase: interfaces.HookBefore,
+			description: "should stop the chunk before it is embedded"},
+		{name: "after embed", phase: interfaces.HookAfter,
+			description: "should stop the chunk before it is saved"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := NewProcessingEngine()
+			var seen *interfaces.HookPayload
+			err := engine.RegisterHook(interfaces.HookEmbed, tt.phase,
+				func(_ context.Context, payload *interfaces.HookPayload) error {
+					seen = payload
+					return hookErr
+				})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			chunk := &models.Chunk{ID: "chunk-1", DocumentID: "doc-1", Body: stringPtr("content")}
+			result := engine.processChunk(context.Background(), chunk, embedder, nil)
+			if !errors.Is(result.Error, hookErr) {
+				t.Errorf("Expected the hook's error, got %v for test: %s", result.Error, tt.description)
+			}
+			if seen == nil || seen.Chunk != chunk {
+				t.Fatalf("Expected the hook to receive the chunk for test: %s", tt.description)
+			}
+			if (seen.Embedding != nil) != (tt.phase == interfaces.HookAfter) {
+				t.Errorf("Expected the embedding only after embedding, got %v", seen.Embedding)
+			}
+		})
+	}
+}
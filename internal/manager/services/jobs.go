@@ -0,0 +1,262 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
+	"github.com/code-sleuth/ike-go/internal/manager/models"
+	"github.com/code-sleuth/ike-go/internal/manager/repository"
+)
+
+// Job kinds understood by RunJob.
+const (
+	// JobKindImport runs ProcessSource for a URL.
+	JobKindImport = "import"
+	// JobKindProcess runs ProcessDocument for an existing download.
+	JobKindProcess = "process"
+)
+
+var (
+	ErrUnknownJobKind = errors.New("unknown job kind")
+	ErrInvalidJob     = errors.New("invalid job payload")
+)
+
+// JobQueue is the work queue drained by the engine; repository.JobRepository implements it.
+// Heartbeat, Complete, Fail, and Release return repository.ErrJobLost when workerID no longer
+// holds the job.
+type JobQueue interface {
+	Claim(workerID string) (*models.Job, error)
+	Heartbeat(id, workerID string) error
+	Complete(id, workerID string) error
+	Fail(id, workerID string, cause error) error
+	Release(id, workerID string) error
+	RequeueStale(lease time.Duration) (int64, error)
+}
+
+// ImportJob is the payload of a JobKindImport job.
+type ImportJob struct {
+	SourceURL string                        `json:"source_url"`
+	Options   *interfaces.ProcessingOptions `json:"options"`
+}
+
+// ProcessJob is the payload of a JobKindProcess job.
+type ProcessJob struct {
+	DownloadID string                        `json:"download_id"`
+	Options    *interfaces.ProcessingOptions `json:"options"`
+}
+
+// NewImportJob returns a job that imports and processes sourceURL when a worker runs it.
+func NewImportJob(sourceURL string, options *interfaces.ProcessingOptions) (*models.Job, error) {
+	return newJob(JobKindImport, ImportJob{SourceURL: sourceURL, Options: options})
+}
+
+// NewProcessJob returns a job that transforms, chunks, and embeds an existing download.
+func NewProcessJob(downloadID string, options *interfaces.ProcessingOptions) (*models.Job, error) {
+	return newJob(JobKindProcess, ProcessJob{DownloadID: downloadID, Options: options})
+}
+
+func newJob(kind string, payload interface{}) (*models.Job, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	return &models.Job{Kind: kind, Payload: string(data)}, nil
+}
+
+// RunJob executes a single claimed job.
+func (e *ProcessingEngine) RunJob(ctx context.Context, job *models.Job, db *sql.DB) error {
+	ctx, done, err := e.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer done()
+	ctx = interfaces.WithJobID(ctx, job.ID)
+
+	switch job.Kind {
+	case JobKindImport:
+		var payload ImportJob
+		if err := decodeJob(job, &payload); err != nil || payload.SourceURL == "" || payload.Options == nil {
+			return fmt.Errorf("%w: %s", ErrInvalidJob, job.ID)
+		}
+		ctx, cancel := withJobTimeout(ctx, payload.Options)
+		defer cancel()
+		return e.processSource(ctx, payload.SourceURL, payload.Options, db)
+	case JobKindProcess:
+		var payload ProcessJob
+		if err := decodeJob(job, &payload); err != nil || payload.DownloadID == "" || payload.Options == nil {
+			return fmt.Errorf("%w: %s", ErrInvalidJob, job.ID)
+		}
+		ctx, cancel := withJobTimeout(ctx, payload.Options)
+		defer cancel()
+		return e.processDocument(ctx, payload.DownloadID, payload.Options, db)
+	default:
+		return fmt.Errorf("%w: %s", ErrUnknownJobKind, job.Kind)
+	}
+}
+
+func decodeJob(job *models.Job, payload interface{}) error {
+	return json.Unmarshal([]byte(job.Payload), payload)
+}
+
+// withJobTimeout bounds ctx by the job's configured timeout, if any.
+func withJobTimeout(
+	ctx context.Context,
+	options *interfaces.ProcessingOptions,
+) (context.Context, context.CancelFunc) {
+	if options == nil || options.Timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, options.Timeout)
+}
+
+// Drain claims and runs jobs until the queue is empty, ctx is cancelled, or Shutdown is called,
+// and returns how many jobs it ran. A failing job is recorded on the queue and does not stop the
+// drain; a job interrupted by cancellation or shutdown is released for another worker. The lock
+// on the running job is renewed every third of lease, and a job the worker loses, because its
+// lock expired anyway, is cancelled and left to its new holder.
+func (e *ProcessingEngine) Drain(
+	ctx context.Context,
+	queue JobQueue,
+	db *sql.DB,
+	workerID string,
+	lease time.Duration,
+) (int, error) {
+	var ran int
+	for ctx.Err() == nil {
+		if e.stopping() {
+			return ran, nil
+		}
+
+		job, err := queue.Claim(workerID)
+		if errors.Is(err, repository.ErrNoJobs) {
+			return ran, nil
+		}
+		if err != nil {
+			return ran, err
+		}
+
+		e.logger.Info().
+			Str("job_id", job.ID).
+			Str("kind", job.Kind).
+			Int("attempt", job.Attempts).
+			Msg("Running job")
+		jobCtx, cancelJob := context.WithCancel(ctx)
+		stopHeartbeat := e.heartbeat(queue, job.ID, workerID, lease, cancelJob)
+		runErr := e.RunJob(interfaces.WithActor(jobCtx, workerID), job, db)
+		lost := stopHeartbeat()
+		cancelJob()
+		ran++
+
+		switch {
+		case lost:
+			err = repository.ErrJobLost
+		case ctx.Err() != nil, runErr != nil && e.stopping():
+			// An interrupted job is not a failed attempt
+			jobsTotal.With(job.Kind, "released").Inc()
+			err = queue.Release(job.ID, workerID)
+		case runErr != nil:
+			e.logger.Error().Err(runErr).Str("job_id", job.ID).Msg("Job failed")
+			jobsTotal.With(job.Kind, "failed").Inc()
+			err = queue.Fail(job.ID, workerID, runErr)
+		default:
+			jobsTotal.With(job.Kind, "done").Inc()
+			err = queue.Complete(job.ID, workerID)
+		}
+		if errors.Is(err, repository.ErrJobLost) {
+			// Whoever holds the job now records its outcome
+			e.logger.Warn().Str("job_id", job.ID).Msg("Lost job to another worker")
+		} else if err != nil {
+			return ran, err
+		}
+	}
+
+	return ran, ctx.Err()
+}
+
+// heartbeat renews workerID's lock on a running job every third of lease until the returned
+// function is called, which reports whether the job was lost. Losing the job calls cancel, so
+// the worker stops running what another worker may already have claimed.
+func (e *ProcessingEngine) heartbeat(
+	queue JobQueue,
+	jobID, workerID string,
+	lease time.Duration,
+	cancel context.CancelFunc,
+) func() bool {
+	interval := lease / 3
+	if interval <= 0 {
+		return func() bool { return false }
+	}
+
+	var lost atomic.Bool
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+
+			err := queue.Heartbeat(jobID, workerID)
+			if errors.Is(err, repository.ErrJobLost) {
+				lost.Store(true)
+				cancel()
+				return
+			}
+			if err != nil {
+				// The lock holds until the lease runs out, so a later renewal may still succeed
+				e.logger.Warn().Err(err).Str("job_id", jobID).Msg("Failed to renew job lock")
+			}
+		}
+	}()
+
+	return func() bool {
+		close(stop)
+		<-stopped
+		return lost.Load()
+	}
+}
+
+// Work drains the queue every poll interval until ctx is cancelled or Shutdown is called. Before
+// each drain it requeues jobs whose worker has held them longer than lease, so work from crashed
+// workers is picked up.
+func (e *ProcessingEngine) Work(
+	ctx context.Context,
+	queue JobQueue,
+	db *sql.DB,
+	workerID string,
+	poll, lease time.Duration,
+) error {
+	ticker := time.NewTicker(poll)
+	defer ticker.Stop()
+
+	for {
+		if requeued, err := queue.RequeueStale(lease); err != nil {
+			return err
+		} else if requeued > 0 {
+			e.logger.Warn().Int64("job_count", requeued).Msg("Requeued stale jobs")
+		}
+
+		if _, err := e.Drain(ctx, queue, db, workerID, lease); err != nil && ctx.Err() == nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-e.closing:
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
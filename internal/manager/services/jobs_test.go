@@ -0,0 +1,209 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
+	"github.com/code-sleuth/ike-go/internal/manager/models"
+	"github.com/code-sleuth/ike-go/internal/manager/repository"
+)
+
+// fakeQueue is an in-memory JobQueue that records what happened to each job. With lost set, the
+// worker no longer holds any job.
+type fakeQueue struct {
+	queued     []*models.Job
+	completed  []string
+	failed     []string
+	released   []string
+	heartbeats atomic.Int32
+	lost       bool
+}
+
+func (q *fakeQueue) Claim(string) (*models.Job, error) {
+	if len(q.queued) == 0 {
+		return nil, repository.ErrNoJobs
+	}
+	job := q.queued[0]
+	q.queued = q.queued[1:]
+	job.Attempts++
+	return job, nil
+}
+
+func (q *fakeQueue) Heartbeat(string, string) error {
+	q.heartbeats.Add(1)
+	if q.lost {
+		return repository.ErrJobLost
+	}
+	return nil
+}
+
+func (q *fakeQueue) Complete(id, _ string) error {
+	if q.lost {
+		return repository.ErrJobLost
+	}
+	q.completed = append(q.completed, id)
+	return nil
+}
+
+func (q *fakeQueue) Fail(id, _ string, _ error) error {
+	if q.lost {
+		return repository.ErrJobLost
+	}
+	q.failed = append(q.failed, id)
+	return nil
+}
+
+func (q *fakeQueue) Release(id, _ string) error {
+	if q.lost {
+		return repository.ErrJobLost
+	}
+	q.released = append(q.released, id)
+	return nil
+}
+
+func (q *fakeQueue) RequeueStale(time.Duration) (int64, error) { return 0, nil }
+
+func TestNewImportJob(t *testing.T) {
+	options := &interfaces.ProcessingOptions{ChunkStrategy: "token", EmbeddingModel: "text-embedding-3-small"}
+
+	job, err := NewImportJob("https://github.com/owner/repo", options)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if job.Kind != JobKindImport {
+		t.Errorf("Expected kind %s, got %s", JobKindImport, job.Kind)
+	}
+
+	var payload ImportJob
+	if err := json.Unmarshal([]byte(job.Payload), &payload); err != nil {
+		t.Fatalf("Expected a JSON payload, got %v", err)
+	}
+	if payload.SourceURL != "https://github.com/owner/repo" || payload.Options.ChunkStrategy != "token" {
+		t.Errorf("Expected payload to round-trip, got %+v", payload)
+	}
+}
+
+func TestProcessingEngine_RunJob_Invalid(t *testing.T) {
+	tests := []struct {
+		name        string
+		job         *models.Job
+		expectedErr error
+		description string
+	}{
+		{
+			name:        "unknown kind",
+			job:         &models.Job{ID: "job-1", Kind: "reindex", Payload: "{}"},
+			expectedErr: ErrUnknownJobKind,
+			description: "should reject kinds the engine does not run",
+		},
+		{
+			name:        "malformed payload",
+			job:         &models.Job{ID: "job-2", Kind: JobKindImport, Payload: "not json"},
+			expectedErr: ErrInvalidJob,
+			description: "should reject payloads that do not decode",
+		},
+		{
+			name:        "missing options",
+			job:         &models.Job{ID: "job-3", Kind: JobKindProcess, Payload: `{"download_id":"download-1"}`},
+			expectedErr: ErrInvalidJob,
+			description: "should reject process jobs without processing options",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewProcessingEngine().RunJob(context.Background(), tt.job, nil)
+			if !errors.Is(err, tt.expectedErr) {
+				t.Errorf("Expected error %v, got %v for test: %s", tt.expectedErr, err, tt.description)
+			}
+		})
+	}
+}
+
+func TestProcessingEngine_Drain(t *testing.T) {
+	queue := &fakeQueue{queued: []*models.Job{
+		{ID: "job-1", Kind: "reindex", Payload: "{}"},
+		{ID: "job-2", Kind: JobKindImport, Payload: "not json"},
+	}}
+
+	ran, err := NewProcessingEngine().Drain(context.Background(), queue, nil, "worker-1", time.Hour)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if ran != 2 {
+		t.Errorf("Expected 2 jobs run, got %d", ran)
+	}
+	if len(queue.failed) != 2 || len(queue.completed) != 0 {
+		t.Errorf("Expected both jobs to be recorded as failed, got failed=%v completed=%v",
+			queue.failed, queue.completed)
+	}
+}
+
+func TestProcessingEngine_Drain_Cancelled(t *testing.T) {
+	queue := &fakeQueue{queued: []*models.Job{{ID: "job-1", Kind: JobKindImport, Payload: "{}"}}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	ran, err := NewProcessingEngine().Drain(ctx, queue, nil, "worker-1", time.Hour)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if ran != 0 || len(queue.queued) != 1 {
+		t.Errorf("Expected no job to be claimed after cancellation, got ran=%d queued=%d", ran, len(queue.queued))
+	}
+}
+
+func TestProcessingEngine_Drain_LostJob(t *testing.T) {
+	queue := &fakeQueue{queued: []*models.Job{{ID: "job-1", Kind: "reindex", Payload: "{}"}}, lost: true}
+
+	ran, err := NewProcessingEngine().Drain(context.Background(), queue, nil, "worker-1", time.Hour)
+	if err != nil {
+		t.Fatalf("Expected a lost job not to stop the drain, got %v", err)
+	}
+	if ran != 1 || len(queue.failed) != 0 || len(queue.completed) != 0 {
+		t.Errorf("Expected the lost job's outcome to be left to its holder, got ran=%d failed=%v completed=%v",
+			ran, queue.failed, queue.completed)
+	}
+}
+
+func TestProcessingEngine_Heartbeat(t *testing.T) {
+	t.Run("renews the lock while the job runs", func(t *testing.T) {
+		queue := &fakeQueue{}
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		stop := NewProcessingEngine().heartbeat(queue, "job-1", "worker-1", 30*time.Millisecond, cancel)
+		time.Sleep(50 * time.Millisecond)
+		if lost := stop(); lost {
+			t.Error("Expected the job not to be lost")
+		}
+		if queue.heartbeats.Load() == 0 {
+			t.Error("Expected the lock to be renewed")
+		}
+		if ctx.Err() != nil {
+			t.Error("Expected the job not to be cancelled")
+		}
+	})
+
+	t.Run("cancels a lost job", func(t *testing.T) {
+		queue := &fakeQueue{lost: true}
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		stop := NewProcessingEngine().heartbeat(queue, "job-1", "worker-1", 3*time.Millisecond, cancel)
+		select {
+		case <-ctx.Done():
+		case <-time.After(time.Second):
+			t.Fatal("Expected the lost job to be cancelled")
+		}
+		if lost := stop(); !lost {
+			t.Error("Expected the job to be reported lost")
+		}
+	})
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
+	"github.com/code-sleuth/ike-go/pkg/metrics"
+)
+
+// stageBuckets are histogram buckets in seconds for pipeline stages, which range from a single
+// transform to a multi-hour import.
+var stageBuckets = []float64{.1, .5, 1, 5, 15, 30, 60, 300, 900, 1800, 3600, 7200}
+
+var (
+	importItems = metrics.NewCounterVec("ike_import_items_total",
+		"Items imported, skipped, or failed by source type.", "source_type", "status")
+	documentsTotal = metrics.NewCounterVec("ike_documents_total",
+		"Downloads transformed into documents by outcome: processed, skipped as unchanged, or failed.", "status")
+	chunksEmbedded = metrics.NewCounterVec("ike_chunks_embedded_total",
+		"Chunks embedded and saved, or failed, by embedding model.", "model", "status")
+	stageFailures = metrics.NewCounterVec("ike_pipeline_failures_total",
+		"Pipeline failures by stage and type of failure.", "stage", "type")
+	stageDuration = metrics.NewHistogramVec("ike_pipeline_stage_duration_seconds",
+		"Duration of pipeline stages; run is a whole source from import to the last embedding.",
+		stageBuckets, "stage")
+	runsInProgress = metrics.NewGaugeVec("ike_pipeline_runs_in_progress",
+		"Source pipeline runs in progress.")
+	runsTotal = metrics.NewCounterVec("ike_pipeline_runs_total",
+		"Finished source pipeline runs by status.", "status")
+	lastSuccess = metrics.NewGaugeVec("ike_pipeline_last_success_timestamp_seconds",
+		"Unix time the last source pipeline run succeeded.")
+	jobsTotal = metrics.NewCounterVec("ike_jobs_total",
+		"Jobs run by workers by kind and outcome: done, failed, or released for another worker.", "kind", "status")
+	embedConcurrency = metrics.NewGaugeVec("ike_embedding_concurrency_limit",
+		"Embedding requests allowed at once, lowered while the provider rate limits them.")
+)
+
+// stageRun labels the duration of a whole source pipeline run.
+const stageRun interfaces.HookStage = "run"
+
+// observeStage records how long stage took since start and, when err is set, its failure.
+func observeStage(stage interfaces.HookStage, start time.Time, err error) {
+	stageDuration.With(string(stage)).Observe(time.Since(start).Seconds())
+	if err != nil {
+		observeFailure(stage, err)
+	}
+}
+
+// observeFailure counts a failure of stage by failureType.
+func observeFailure(stage interfaces.HookStage, err error) {
+	stageFailures.With(string(stage), failureType(err)).Inc()
+}
+
+// observeRun records the outcome of a source pipeline run started at start.
+func observeRun(start time.Time, err error) {
+	observeStage(stageRun, start, err)
+	if err != nil {
+		runsTotal.With("failed").Inc()
+		return
+	}
+	runsTotal.With("succeeded").Inc()
+	lastSuccess.With().Set(float64(time.Now().Unix()))
+}
+
+// observeImport counts the items of an import report by status.
+func observeImport(sourceType string, report *interfaces.ImportReport) {
+	if report == nil {
+		return
+	}
+	importItems.With(sourceType, string(interfaces.ItemImported)).Add(float64(report.Imported))
+	importItems.With(sourceType, string(interfaces.ItemSkipped)).Add(float64(report.Skipped))
+	importItems.With(sourceType, string(interfaces.ItemFailed)).Add(float64(report.Failed))
+}
+
+// failureType classifies err for the failure metrics, so alerts can tell an expired token or a
+// provider outage from content that has gone missing.
+func failureType(err error) string {
+	var status interface{ HTTPStatus() int }
+	switch {
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, ErrShuttingDown):
+		return "shutdown"
+	case errors.Is(err, ErrHookFailed):
+		return "hook"
+	case errors.As(err, &status):
+		switch code := status.HTTPStatus(); {
+		case code == http.StatusUnauthorized, code == http.StatusForbidden:
+			return "auth"
+		case code == http.StatusNotFound:
+			return "not_found"
+		case code == http.StatusTooManyRequests:
+			return "rate_limited"
+		case code >= http.StatusInternalServerError:
+			return "server_error"
+		default:
+			return "http_error"
+		}
+	default:
+		return "other"
+	}
+}
@@ -0,0 +1,64 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
+)
+
+// statusError stands in for an importer's HTTP status error.
+type statusError int
+
+func (e statusError) Error() string   { return fmt.Sprintf("status %d", int(e)) }
+func (e statusError) HTTPStatus() int { return int(e) }
+
+func TestFailureType(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{name: "cancelled", err: fmt.Errorf("import: %w", context.Canceled), expected: "canceled"},
+		{name: "deadline", err: context.DeadlineExceeded, expected: "timeout"},
+		{name: "shutdown", err: ErrShuttingDown, expected: "shutdown"},
+		{name: "hook", err: fmt.Errorf("%w: %w", ErrHookFailed, errors.New("rejected")), expected: "hook"},
+		{name: "unauthorized", err: fmt.Errorf("fetch: %w", statusError(http.StatusUnauthorized)), expected: "auth"},
+		{name: "not found", err: statusError(http.StatusNotFound), expected: "not_found"},
+		{name: "rate limited", err: statusError(http.StatusTooManyRequests), expected: "rate_limited"},
+		{name: "outage", err: statusError(http.StatusBadGateway), expected: "server_error"},
+		{name: "bad request", err: statusError(http.StatusBadRequest), expected: "http_error"},
+		{name: "other", err: errors.New("disk full"), expected: "other"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := failureType(tt.err); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+// Test that import reports are counted by source type and status.
+func TestObserveImport(t *testing.T) {
+	report := &interfaces.ImportReport{}
+	report.Add(interfaces.ItemReport{Key: "README.md", Status: interfaces.ItemImported})
+	report.Add(interfaces.ItemReport{Key: "docs/guide.md", Status: interfaces.ItemImported})
+	report.Add(interfaces.ItemReport{Key: "logo.png", Status: interfaces.ItemSkipped})
+
+	observeImport("metrics-test", report)
+	observeImport("metrics-test", nil)
+
+	expected := map[interfaces.ItemStatus]float64{
+		interfaces.ItemImported: 2, interfaces.ItemSkipped: 1, interfaces.ItemFailed: 0,
+	}
+	for status, count := range expected {
+		if got := importItems.With("metrics-test", string(status)).Value(); got != count {
+			t.Errorf("Expected %v %s items, got %v", count, status, got)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
+	"github.com/code-sleuth/ike-go/internal/manager/repository"
+	"github.com/code-sleuth/ike-go/pkg/util"
+)
+
+var ErrRechunkIncomplete = errors.New("rechunk completed with failed documents")
+
+// RechunkFilter selects the documents Rechunk processes again. Empty fields match every source.
+type RechunkFilter struct {
+	SourceID   string
+	SourceURL  string
+	Collection string
+}
+
+// RechunkReport summarizes a Rechunk pass.
+type RechunkReport struct {
+	Documents int `json:"documents"`
+	Replaced  int `json:"replaced"`
+	Failed    int `json:"failed"`
+}
+
+// Rechunk runs transform/chunk/embed again for the stored downloads of the live documents
+// filter selects, for example after changing the chunk strategy or embedding model. Nothing is
+// fetched from the network. Each document is replaced by a new one only once all of the new
+// document's chunks are saved, in a single transaction, so searches see either the old chunks
+// or the new ones; a document that fails keeps its old chunks.
+func (e *ProcessingEngine) Rechunk(
+	ctx context.Context,
+	filter RechunkFilter,
+	options *interfaces.ProcessingOptions,
+	db *sql.DB,
+) (*RechunkReport, error) {
+	ctx, done, err := e.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	documents, err := e.rechunkDocuments(ctx, db, filter)
+	if err != nil {
+		e.logger.Error().Err(err).Msg("Failed to load documents to rechunk")
+		return nil, err
+	}
+
+	report := &RechunkReport{Documents: len(documents)}
+	var firstErr error
+	for _, document := range documents {
+		if e.stopping() {
+			return report, ErrShuttingDown
+		}
+
+		err := e.processDownload(ctx, document.downloadID, document.id, options, db)
+		if ctx.Err() != nil {
+			return report, context.Cause(ctx)
+		}
+		if err != nil {
+			report.Failed++
+			if firstErr == nil {
+				firstErr = err
+			}
+			e.logger.Error().Err(err).Str("document_id", document.id).Msg("Rechunk of document failed")
+			continue
+		}
+		report.Replaced++
+	}
+
+	if report.Failed > 0 {
+		return report, fmt.Errorf("%w: %d of %d documents failed, first error: %w", ErrRechunkIncomplete,
+			report.Failed, report.Documents, firstErr)
+	}
+	return report, nil
+}
+
+// rechunkDocument is a live document and the download it was transformed from.
+type rechunkDocument struct {
+	id         string
+	downloadID string
+}
+
+// rechunkDocuments returns the live documents of the live sources filter selects. They are all
+// loaded up front so the replacements Rechunk creates are not picked up again.
+func (e *ProcessingEngine) rechunkDocuments(
+	ctx context.Context,
+	db *sql.DB,
+	filter RechunkFilter,
+) ([]rechunkDocument, error) {
+	query := `SELECT d.id, d.download_id FROM documents d JOIN sources s ON s.id = d.source_id
+		WHERE d.deleted_at IS NULL AND s.deleted_at IS NULL`
+	var args []interface{}
+	if filter.SourceID != "" {
+		query += ` AND s.id = ?`
+		args = append(args, filter.SourceID)
+	}
+	if filter.SourceURL != "" {
+		sourceURL, err := util.NormalizeURL(filter.SourceURL)
+		if err != nil {
+			return nil, err
+		}
+		query += ` AND s.raw_url = ?`
+		args = append(args, sourceURL)
+	}
+	if filter.Collection != "" {
+		query += ` AND s.collection = ?`
+		args = append(args, filter.Collection)
+	}
+	query += ` ORDER BY d.id`
+
+	rows, err := db.QueryContext(ctx, e.dialect.Rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var documents []rechunkDocument
+	for rows.Next() {
+		var document rechunkDocument
+		if err := rows.Scan(&document.id, &document.downloadID); err != nil {
+			return nil, err
+		}
+		documents = append(documents, document)
+	}
+	return documents, rows.Err()
+}
+
+// stageDocument records hash on a freshly transformed replacement document and hides it from
+// searches until replaceDocument swaps it in.
+func (e *ProcessingEngine) stageDocument(ctx context.Context, db *sql.DB, documentID, hash string) error {
+	query := `UPDATE documents SET content_hash = ?, deleted_at = ? WHERE id = ?`
+	_, err := db.ExecContext(ctx, e.dialect.Rebind(query), hash, e.dialect.FormatTime(time.Now()), documentID)
+	return err
+}
+
+// replaceDocument finishes a staged replacement. When cause is nil, the replaced document and
+// everything that hangs off it are deleted and the replacement is made live in one transaction;
+// otherwise the replacement is deleted instead and cause is returned.
+func (e *ProcessingEngine) replaceDocument(
+	ctx context.Context,
+	db *sql.DB,
+	replacedID, replacementID string,
+	cause error,
+) error {
+	// Finishing ignores cancellation so an interrupted replacement is not left behind hidden
+	ctx = context.WithoutCancel(ctx)
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Join(cause, err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if cause != nil {
+		if err := e.deleteDocument(ctx, tx, replacementID); err != nil {
+			return errors.Join(cause, err)
+		}
+		return errors.Join(cause, tx.Commit())
+	}
+
+	err = repository.RecordDocumentDeletion(ctx, tx, e.dialect, replacedID, "replaced by document "+replacementID)
+	if err != nil {
+		return err
+	}
+	if err := e.deleteDocument(ctx, tx, replacedID); err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, e.dialect.Rebind(`UPDATE documents SET deleted_at = NULL WHERE id = ?`),
+		replacementID)
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	e.logger.Info().
+		Str("document_id", replacementID).
+		Str("replaced_id", replacedID).
+		Msg("Replaced document chunks and embeddings")
+	return nil
+}
+
+// deleteDocument deletes a document with its embeddings, chunks and their metadata, dead-lettered
+// chunks, tags, and metadata, leaf-first so foreign keys are never left dangling.
+func (e *ProcessingEngine) deleteDocument(ctx context.Context, tx *sql.Tx, documentID string) error {
+	statements := []string{
+		`DELETE FROM embeddings WHERE object_type = 'chunk'
+			AND object_id IN (SELECT id FROM chunks WHERE document_id = ?)`,
+		`DELETE FROM chunk_meta WHERE chunk_id IN (SELECT id FROM chunks WHERE document_id = ?)`,
+		`DELETE FROM chunks WHERE document_id = ?`,
+		`DELETE FROM failed_chunks WHERE document_id = ?`,
+		`DELETE FROM document_meta WHERE document_id = ?`,
+		`DELETE FROM document_tags WHERE document_id = ?`,
+		`DELETE FROM documents WHERE id = ?`,
+	}
+	for _, statement := range statements {
+		if _, err := tx.ExecContext(ctx, e.dialect.Rebind(statement), documentID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
+	"github.com/code-sleuth/ike-go/internal/manager/models"
+	"github.com/code-sleuth/ike-go/internal/manager/testutil"
+
+	"github.com/google/uuid"
+)
+
+// storingTransformer stores a new document for every download it transforms, like the real
+// transformers do.
+type storingTransformer struct{}
+
+func (storingTransformer) Transform(
+	ctx context.Context,
+	download *models.Download,
+	db *sql.DB,
+) (*interfaces.TransformResult, error) {
+	document := &models.Document{ID: uuid.New().String(), SourceID: download.SourceID, DownloadID: download.ID}
+	_, err := db.ExecContext(ctx, `INSERT INTO documents (id, source_id, download_id, min_chunk_size, max_chunk_size)
+		VALUES (?, ?, ?, 100, 1000)`, document.ID, document.SourceID, document.DownloadID)
+	if err != nil {
+		return nil, err
+	}
+	return &interfaces.TransformResult{Document: document, Content: "# Title\n\nBody"}, nil
+}
+
+func (storingTransformer) GetSourceType() string              { return "github" }
+func (storingTransformer) CanTransform(*models.Download) bool { return true }
+
+// Test that Rechunk replaces a document's chunks only when every new chunk is saved.
+func TestProcessingEngine_Rechunk_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db := testutil.SetupTestDB(t)
+	defer testutil.CleanupTestDB(t, db)
+
+	sourceURL := "https://github.com/owner/repo/blob/main/README.md"
+	statements := []string{
+		`INSERT INTO sources (id, raw_url, host, active_domain)
+			VALUES ('source-1', '` + sourceURL + `', 'github.com', 1)`,
+		`INSERT INTO downloads (id, source_id, headers, attempted_at, body)
+			VALUES ('download-1', 'source-1', '{}', '2024-01-01T00:00:00Z', 'content')`,
+		`INSERT INTO documents (id, source_id, download_id, min_chunk_size, max_chunk_size)
+			VALUES ('document-1', 'source-1', 'download-1', 100, 1000)`,
+		`INSERT INTO chunks (id, document_id, body) VALUES ('chunk-1', 'document-1', 'old body')`,
+	}
+	for _, statement := range statements {
+		if _, err := db.Exec(statement); err != nil {
+			t.Fatalf("Failed to set up test data: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name           string
+		embedError     error
+		expectedErr    error
+		expectReplaced bool
+		description    string
+	}{
+		{name: "embedding fails", embedError: errors.New("rate limited"), expectedErr: ErrRechunkIncomplete,
+			description: "should keep the old chunks and drop the partial replacement"},
+		{name: "success", expectReplaced: true,
+			description: "should swap the old document for the re-chunked one"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := NewProcessingEngine()
+			body := "new body"
+			_ = engine.RegisterTransformer(storingTransformer{})
+			_ = engine.RegisterChunker(&mockChunker{strategy: "heading", chunks: []*models.Chunk{{Body: &body}}})
+			_ = engine.RegisterEmbedder(&mockEmbedder{modelName: "text-embedding-3-small", dimension: 1536,
+				embedding: make([]float32, 1536), embedError: tt.embedError})
+
+			options := &interfaces.ProcessingOptions{
+				ChunkStrategy: "heading", EmbeddingModel: "text-embedding-3-small", Concurrency: 1,
+			}
+			report, err := engine.Rechunk(context.Background(), RechunkFilter{SourceURL: sourceURL}, options, db)
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("Expected error %v, got %v for test: %s", tt.expectedErr, err, tt.description)
+			}
+			if report.Documents != 1 {
+				t.Errorf("Expected 1 document to be re-chunked, got %d", report.Documents)
+			}
+
+			var bodies []string
+			rows, err := db.Query(`SELECT c.body FROM chunks c JOIN documents d ON d.id = c.document_id
+				WHERE d.deleted_at IS NULL`)
+			if err != nil {
+				t.Fatalf("Failed to query chunks: %v", err)
+			}
+			defer rows.Close()
+			for rows.Next() {
+				var chunkBody string
+				if err := rows.Scan(&chunkBody); err != nil {
+					t.Fatalf("Failed to scan chunk: %v", err)
+				}
+				bodies = append(bodies, chunkBody)
+			}
+
+			expected := "old body"
+			if tt.expectReplaced {
+				expected = body
+			}
+			if len(bodies) != 1 || bodies[0] != expected {
+				t.Errorf("Expected only %q to be searchable, got %v for test: %s", expected, bodies, tt.description)
+			}
+			if count := testutil.GetRecordCount(t, db, "documents"); count != 1 {
+				t.Errorf("Expected 1 document to remain, got %d for test: %s", count, tt.description)
+			}
+		})
+	}
+}
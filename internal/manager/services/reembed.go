@@ -0,0 +1,217 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
+	"github.com/code-sleuth/ike-go/internal/manager/models"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// defaultReembedBatchSize is how many chunks Reembed loads at a time.
+const defaultReembedBatchSize = 500
+
+var (
+	ErrInvalidReembed    = errors.New("invalid re-embed models")
+	ErrReembedIncomplete = errors.New("re-embed completed with failed chunks")
+)
+
+// ReembedOptions configures a Reembed pass. From and To name the embedding models to migrate
+// between. DeleteOld removes From's embeddings once every chunk has one from To.
+type ReembedOptions struct {
+	From        string
+	To          string
+	DeleteOld   bool
+	Concurrency int
+	BatchSize   int
+}
+
+// ReembedReport summarizes a Reembed pass.
+type ReembedReport struct {
+	Chunks   int   `json:"chunks"`
+	Embedded int   `json:"embedded"`
+	Failed   int   `json:"failed"`
+	Deleted  int64 `json:"deleted"`
+}
+
+// Reembed generates embeddings with options.To for every chunk embedded with options.From that
+// does not have one yet, storing each in the column for the new model's dimension. Chunks are
+// read from the database, so nothing is fetched or chunked again, and a pass that is interrupted
+// or has failures can be run again to pick up the remaining chunks. The old model's embeddings
+// are only deleted when options.DeleteOld is set and no chunk failed.
+func (e *ProcessingEngine) Reembed(ctx context.Context, options ReembedOptions, db *sql.DB) (*ReembedReport, error) {
+	if options.From == "" || options.To == "" || options.From == options.To {
+		return nil, fmt.Errorf("%w: from %q to %q", ErrInvalidReembed, options.From, options.To)
+	}
+
+	ctx, done, err := e.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	embedder, err := e.embedderFor(options.To)
+	if err != nil {
+		return nil, err
+	}
+
+	concurrency := max(options.Concurrency, 1)
+	batchSize := options.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultReembedBatchSize
+	}
+
+	report := &ReembedReport{}
+	var mu sync.Mutex
+	var firstErr error
+	for afterID := ""; ; {
+		if e.stopping() {
+			return report, ErrShuttingDown
+		}
+
+		chunks, err := e.reembedChunks(ctx, db, options, afterID, batchSize)
+		if err != nil {
+			e.logger.Error().Err(err).Msg("Failed to load chunks to re-embed")
+			return report, err
+		}
+		if len(chunks) == 0 {
+			break
+		}
+		afterID = chunks[len(chunks)-1].ID
+		report.Chunks += len(chunks)
+
+		var group errgroup.Group
+		group.SetLimit(concurrency)
+		for _, chunk := range chunks {
+			group.Go(func() error {
+				err := e.reembedChunk(ctx, chunk, embedder, db, false)
+
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil {
+					chunksEmbedded.With(options.To, "failed").Inc()
+					observeFailure(interfaces.HookEmbed, err)
+					report.Failed++
+					if firstErr == nil {
+						firstErr = err
+					}
+					e.logger.Error().Err(err).Str("chunk_id", chunk.ID).Msg("Re-embedding chunk failed")
+					return nil
+				}
+				chunksEmbedded.With(options.To, "embedded").Inc()
+				report.Embedded++
+				return nil
+			})
+		}
+		_ = group.Wait()
+
+		if ctx.Err() != nil {
+			return report, context.Cause(ctx)
+		}
+		e.logger.Info().Int("embedded", report.Embedded).Int("failed", report.Failed).Msg("Re-embedded batch")
+	}
+
+	if report.Failed > 0 {
+		return report, fmt.Errorf("%w: %d of %d chunks failed, first error: %w", ErrReembedIncomplete,
+			report.Failed, report.Chunks, firstErr)
+	}
+
+	if options.DeleteOld {
+		query := `DELETE FROM embeddings WHERE object_type = 'chunk' AND model = ?`
+		result, err := db.ExecContext(ctx, e.dialect.Rebind(query), options.From)
+		if err != nil {
+			return report, err
+		}
+		if report.Deleted, err = result.RowsAffected(); err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+// reembedChunks returns up to limit chunks after afterID, in ID order, that have an embedding
+// from options.From but none from options.To.
+func (e *ProcessingEngine) reembedChunks(
+	ctx context.Context,
+	db *sql.DB,
+	options ReembedOptions,
+	afterID string,
+	limit int,
+) ([]*models.Chunk, error) {
+	query := `SELECT c.id, c.document_id, c.body FROM chunks c
+		JOIN embeddings old ON old.object_id = c.id AND old.object_type = 'chunk' AND old.model = ?
+		WHERE c.id > ? AND c.body IS NOT NULL
+		  AND NOT EXISTS (
+		      SELECT 1 FROM embeddings em WHERE em.object_id = c.id AND em.object_type = 'chunk' AND em.model = ?)
+		ORDER BY c.id
+		LIMIT ?`
+	rows, err := db.QueryContext(ctx, e.dialect.Rebind(query), options.From, afterID, options.To, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var chunks []*models.Chunk
+	for rows.Next() {
+		chunk := &models.Chunk{}
+		if err := rows.Scan(&chunk.ID, &chunk.DocumentID, &chunk.Body); err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, chunk)
+	}
+	return chunks, rows.Err()
+}
+
+// reembedChunk embeds chunk once a slot of the shared embedding pool is free and stores the
+// embedding next to the chunk's existing ones. When replace is set, the chunk's embeddings from
+// the same model are deleted in the same transaction, so searches never see it without one.
+func (e *ProcessingEngine) reembedChunk(
+	ctx context.Context,
+	chunk *models.Chunk,
+	embedder interfaces.Embedder,
+	db *sql.DB,
+	replace bool,
+) error {
+	e.mu.RLock()
+	slots := e.embedSlots
+	e.mu.RUnlock()
+
+	if err := slots.Acquire(ctx); err != nil {
+		return err
+	}
+	embedding, err := e.embed(interfaces.WithRateLimitObserver(ctx, slots.Observe), chunk, embedder)
+	slots.Release()
+	if err != nil {
+		return err
+	}
+
+	// Prepare the insert before the transaction takes a connection; see stmtcache.Prepare
+	stmt, err := e.prepareEmbeddingInsert(ctx, db, embedding)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if replace {
+		query := `DELETE FROM embeddings WHERE object_type = 'chunk' AND object_id = ? AND model = ?`
+		if _, err := tx.ExecContext(ctx, e.dialect.Rebind(query), chunk.ID, embedder.GetModelName()); err != nil {
+			return err
+		}
+	}
+	if err := e.insertEmbedding(ctx, tx, stmt, embedding); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,35 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestProcessingEngine_Reembed_Invalid(t *testing.T) {
+	tests := []struct {
+		name        string
+		options     ReembedOptions
+		expectedErr error
+		description string
+	}{
+		{name: "missing from", options: ReembedOptions{To: "text-embedding-3-large"},
+			expectedErr: ErrInvalidReembed, description: "should require the model to migrate from"},
+		{name: "same model", options: ReembedOptions{From: "text-embedding-3-large", To: "text-embedding-3-large"},
+			expectedErr: ErrInvalidReembed, description: "should reject migrating a model to itself"},
+		{name: "unknown target", options: ReembedOptions{From: "text-embedding-ada-002", To: "unknown"},
+			expectedErr: ErrNoEmbedderRegistered, description: "should fail before reading chunks"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := NewProcessingEngine().Reembed(context.Background(), tt.options, nil)
+			if !errors.Is(err, tt.expectedErr) {
+				t.Errorf("Expected error %v, got %v for test: %s", tt.expectedErr, err, tt.description)
+			}
+			if report != nil {
+				t.Errorf("Expected no report, got %+v", report)
+			}
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
+	"github.com/code-sleuth/ike-go/internal/manager/models"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// ReprocessStage is the pipeline stage Reprocess starts from.
+type ReprocessStage string
+
+const (
+	// ReprocessImport fetches the source again and processes everything it returns.
+	ReprocessImport ReprocessStage = "import"
+	// ReprocessTransform re-runs transform, chunking, and embedding from the stored downloads.
+	ReprocessTransform ReprocessStage = "transform"
+	// ReprocessChunk re-chunks and re-embeds. Transformed documents are not stored, so it starts
+	// from the stored downloads like ReprocessTransform; neither fetches anything.
+	ReprocessChunk ReprocessStage = "chunk"
+	// ReprocessEmbed embeds the existing chunks again, replacing their embeddings.
+	ReprocessEmbed ReprocessStage = "embed"
+)
+
+var (
+	ErrInvalidReprocessStage = errors.New("invalid reprocess stage")
+	ErrReprocessIncomplete   = errors.New("reprocess completed with failures")
+	ErrRedactedSourceURL     = errors.New("source URL has redacted credentials")
+)
+
+// ReprocessReport summarizes a Reprocess pass. Documents and Replaced are counted from the
+// transform and chunk stages, Chunks and Embedded from the embed stage; Failed counts documents or
+// chunks respectively. An import reports nothing beyond its error.
+type ReprocessReport struct {
+	Stage     ReprocessStage `json:"stage"`
+	Documents int            `json:"documents"`
+	Replaced  int            `json:"replaced"`
+	Chunks    int            `json:"chunks"`
+	Embedded  int            `json:"embedded"`
+	Failed    int            `json:"failed"`
+}
+
+// Reprocess re-runs the pipeline for the live source with sourceID from stage from, reusing what
+// earlier stages stored:
+//
+//   - import fetches the source again, with the credentials stripped from its URL when it was
+//     stored, and re-embeds every document, changed or not;
+//   - transform and chunk replace each document from its stored download, as Rechunk does;
+//   - embed replaces the embeddings of the source's chunks from options.EmbeddingModel in place.
+func (e *ProcessingEngine) Reprocess(
+	ctx context.Context,
+	sourceID string,
+	from ReprocessStage,
+	options *interfaces.ProcessingOptions,
+	db *sql.DB,
+) (*ReprocessReport, error) {
+	report := &ReprocessReport{Stage: from}
+	switch from {
+	case ReprocessImport:
+		source, err := e.getSource(ctx, sourceID, db)
+		if err != nil {
+			return nil, err
+		}
+		if source.RawURL == nil {
+			return nil, fmt.Errorf("%w: source %s has no URL to import from", ErrInvalidReprocessStage, sourceID)
+		}
+		sourceURL, err := e.sourceFetchURL(ctx, db, *source.RawURL)
+		if err != nil {
+			return nil, err
+		}
+		importOptions := *options
+		importOptions.Restart, importOptions.Force = true, true
+		return report, e.ProcessSource(ctx, sourceURL, &importOptions, db)
+
+	case ReprocessTransform, ReprocessChunk:
+		rechunk, err := e.Rechunk(ctx, RechunkFilter{SourceID: sourceID}, options, db)
+		if rechunk != nil {
+			report.Documents, report.Replaced, report.Failed = rechunk.Documents, rechunk.Replaced, rechunk.Failed
+		}
+		return report, err
+
+	case ReprocessEmbed:
+		return report, e.reprocessEmbeddings(ctx, sourceID, options, db, report)
+
+	default:
+		return nil, fmt.Errorf("%w: %q, expected import, transform, chunk, or embed", ErrInvalidReprocessStage, from)
+	}
+}
+
+// reprocessEmbeddings embeds every chunk of the live documents of sourceID with
+// options.EmbeddingModel, replacing the chunk's embedding from that model if it has one. Chunks
+// are loaded in batches, so memory stays bounded on large sources.
+func (e *ProcessingEngine) reprocessEmbeddings(
+	ctx context.Context,
+	sourceID string,
+	options *interfaces.ProcessingOptions,
+	db *sql.DB,
+	report *ReprocessReport,
+) error {
+	ctx, done, err := e.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	embedder, err := e.embedderFor(options.EmbeddingModel)
+	if err != nil {
+		return err
+	}
+	ctx = withEmbedTimeout(ctx, options.EmbedTimeout)
+
+	var mu sync.Mutex
+	var firstErr error
+	for afterID := ""; ; {
+		if e.stopping() {
+			return ErrShuttingDown
+		}
+
+		chunks, err := e.sourceChunks(ctx, db, sourceID, afterID, defaultReembedBatchSize)
+		if err != nil {
+			e.logger.Error().Err(err).Str("source_id", sourceID).Msg("Failed to load chunks to re-embed")
+			return err
+		}
+		if len(chunks) == 0 {
+			break
+		}
+		afterID = chunks[len(chunks)-1].ID
+		report.Chunks += len(chunks)
+
+		var group errgroup.Group
+		group.SetLimit(max(options.Concurrency, 1))
+		for _, chunk := range chunks {
+			group.Go(func() error {
+				err := e.reembedChunk(ctx, chunk, embedder, db, true)
+
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil {
+					chunksEmbedded.With(embedder.GetModelName(), "failed").Inc()
+					observeFailure(interfaces.HookEmbed, err)
+					report.Failed++
+					if firstErr == nil {
+						firstErr = err
+					}
+					e.logger.Error().Err(err).Str("chunk_id", chunk.ID).Msg("Re-embedding chunk failed")
+					return nil
+				}
+				chunksEmbedded.With(embedder.GetModelName(), "embedded").Inc()
+				report.Embedded++
+				return nil
+			})
+		}
+		_ = group.Wait()
+
+		if ctx.Err() != nil {
+			return context.Cause(ctx)
+		}
+	}
+
+	if report.Failed > 0 {
+		return fmt.Errorf("%w: %d of %d chunks failed, first error: %w", ErrReprocessIncomplete,
+			report.Failed, report.Chunks, firstErr)
+	}
+	return nil
+}
+
+// sourceChunks returns up to limit chunks with a body after afterID, in ID order, of the live
+// documents of sourceID.
+func (e *ProcessingEngine) sourceChunks(
+	ctx context.Context,
+	db *sql.DB,
+	sourceID, afterID string,
+	limit int,
+) ([]*models.Chunk, error) {
+	query := `SELECT c.id, c.document_id, c.body FROM chunks c JOIN documents d ON d.id = c.document_id
+		WHERE d.source_id = ? AND d.deleted_at IS NULL AND c.body IS NOT NULL AND c.id > ?
+		ORDER BY c.id
+		LIMIT ?`
+	rows, err := db.QueryContext(ctx, e.dialect.Rebind(query), sourceID, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var chunks []*models.Chunk
+	for rows.Next() {
+		chunk := &models.Chunk{}
+		if err := rows.Scan(&chunk.ID, &chunk.DocumentID, &chunk.Body); err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, chunk)
+	}
+	return chunks, rows.Err()
+}
@@ -0,0 +1,44 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
+)
+
+func TestProcessingEngine_Reprocess_Invalid(t *testing.T) {
+	tests := []struct {
+		name        string
+		from        ReprocessStage
+		model       string
+		expectedErr error
+		description string
+	}{
+		{name: "unknown stage", from: "download", model: "text-embedding-3-small",
+			expectedErr: ErrInvalidReprocessStage, description: "should reject stages it cannot start from"},
+		{name: "empty stage", from: "", model: "text-embedding-3-small",
+			expectedErr: ErrInvalidReprocessStage, description: "should require a stage"},
+		{name: "unknown model", from: ReprocessEmbed, model: "unknown",
+			expectedErr: ErrNoEmbedderRegistered, description: "should fail before reading chunks"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := &interfaces.ProcessingOptions{EmbeddingModel: tt.model}
+			_, err := NewProcessingEngine().Reprocess(context.Background(), "source-1", tt.from, options, nil)
+			if !errors.Is(err, tt.expectedErr) {
+				t.Errorf("Expected error %v, got %v for test: %s", tt.expectedErr, err, tt.description)
+			}
+		})
+	}
+}
+
+func TestProcessingEngine_SourceFetchURL_Redacted(t *testing.T) {
+	_, err := NewProcessingEngine().sourceFetchURL(context.Background(), nil,
+		"https://example.com/feed.json?access_token=REDACTED")
+	if !errors.Is(err, ErrRedactedSourceURL) {
+		t.Errorf("Expected ErrRedactedSourceURL, got %v", err)
+	}
+}
@@ -0,0 +1,285 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
+	"github.com/code-sleuth/ike-go/pkg/dialect"
+	"github.com/code-sleuth/ike-go/pkg/util"
+
+	"github.com/google/uuid"
+)
+
+// Run and item statuses stored in pipeline_runs and pipeline_run_items.
+const (
+	runStatusRunning   = "running"
+	runStatusCompleted = "completed"
+	runStatusFailed    = "failed"
+	// runStatusAbandoned marks an unfinished run discarded by a restart; it is never resumed
+	runStatusAbandoned = "abandoned"
+
+	itemStatusImported = "imported"
+	itemStatusDone     = "done"
+	itemStatusFailed   = "failed"
+)
+
+var ErrRunIncomplete = errors.New("pipeline run incomplete")
+
+// runItem is one imported item of a pipeline run.
+type runItem struct {
+	key      string
+	position int
+	result   interfaces.ImportResult
+	status   string
+}
+
+// pipelineRun is a resumable ProcessSource invocation. It is handed to importers as their
+// interfaces.Checkpoint, so items fetched by an earlier attempt are skipped on resume.
+type pipelineRun struct {
+	id string
+	// sourceURL is the URL the run imports, with its credentials redacted
+	sourceURL string
+	db        *sql.DB
+	dialect   dialect.Dialect
+	now       func() time.Time
+	mu        sync.Mutex
+	items     map[string]*runItem
+	// resumed is set when the run continues an earlier, unfinished attempt
+	resumed bool
+	// revision and model are recorded when the run completes; see lastRevision
+	revision string
+	model    string
+}
+
+// startRun resumes the newest unfinished run for sourceURL, or starts a new one when there is
+// none or restart is set. A restart abandons the unfinished runs it replaces, so later imports
+// don't resume them. Runs are recorded under the URL without its credentials; see runURL.
+func (e *ProcessingEngine) startRun(
+	ctx context.Context,
+	db *sql.DB,
+	sourceURL string,
+	restart bool,
+) (*pipelineRun, error) {
+	sourceURL = runURL(sourceURL)
+	run := &pipelineRun{
+		sourceURL: sourceURL,
+		db:        db,
+		dialect:   e.dialect,
+		now:       time.Now,
+		items:     make(map[string]*runItem),
+	}
+	now := run.dialect.FormatTime(run.now())
+
+	if !restart {
+		query := `SELECT id FROM pipeline_runs WHERE source_url = ? AND status NOT IN (?, ?)
+				  ORDER BY started_at DESC LIMIT 1`
+		err := db.QueryRowContext(ctx, e.dialect.Rebind(query), sourceURL, runStatusCompleted, runStatusAbandoned).
+			Scan(&run.id)
+		switch {
+		case err == nil:
+			if err := run.load(ctx); err != nil {
+				return nil, err
+			}
+			_, err = db.ExecContext(ctx,
+				e.dialect.Rebind(`UPDATE pipeline_runs SET status = ?, error = NULL, updated_at = ? WHERE id = ?`),
+				runStatusRunning, now, run.id)
+			if err != nil {
+				return nil, err
+			}
+			run.resumed = true
+			e.logger.Info().
+				Str("run_id", run.id).
+				Int("item_count", len(run.items)).
+				Str("source_url", sourceURL).
+				Msg("Resuming pipeline run")
+			return run, nil
+		case !errors.Is(err, sql.ErrNoRows):
+			return nil, err
+		}
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if restart {
+		query := `UPDATE pipeline_runs SET status = ?, updated_at = ?, finished_at = ?
+				  WHERE source_url = ? AND status NOT IN (?, ?)`
+		if _, err := tx.ExecContext(ctx, e.dialect.Rebind(query), runStatusAbandoned, now, now, sourceURL,
+			runStatusCompleted, runStatusAbandoned); err != nil {
+			return nil, err
+		}
+	}
+
+	run.id = uuid.New().String()
+	query := `INSERT INTO pipeline_runs (id, source_url, status, started_at, updated_at) VALUES (?, ?, ?, ?, ?)`
+	if _, err := tx.ExecContext(ctx, e.dialect.Rebind(query), run.id, sourceURL, runStatusRunning,
+		now, now); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return run, nil
+}
+
+// runURL returns sourceURL without its credentials, such as an access_token query parameter, so
+// they are not stored with its runs and an import with new credentials resumes the same run.
+func runURL(sourceURL string) string {
+	stripped, _ := util.SplitURLSecrets(sourceURL)
+	return stripped
+}
+
+// lastRevision returns the revision imported by the newest completed run for sourceURL that
+// embedded with model, or "" when there is none. Failing to look it up only costs a full import,
+// so errors are logged rather than returned.
+func (e *ProcessingEngine) lastRevision(ctx context.Context, db *sql.DB, sourceURL, model string) string {
+	query := `SELECT revision FROM pipeline_runs
+			  WHERE source_url = ? AND status = ? AND embedding_model = ? AND revision IS NOT NULL
+			  ORDER BY finished_at DESC LIMIT 1`
+	var revision string
+	err := db.QueryRowContext(ctx, e.dialect.Rebind(query), runURL(sourceURL), runStatusCompleted, model).
+		Scan(&revision)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		e.logger.Warn().Err(err).Str("source_url", sourceURL).Msg("Failed to look up the last imported revision")
+	}
+	return revision
+}
+
+// load reads the items recorded by earlier attempts of the run.
+func (r *pipelineRun) load(ctx context.Context) error {
+	query := `SELECT item_key, position, COALESCE(source_id, ''), download_id, status
+			  FROM pipeline_run_items WHERE run_id = ?`
+
+	rows, err := r.db.QueryContext(ctx, r.dialect.Rebind(query), r.id)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item runItem
+		if err := rows.Scan(&item.key, &item.position, &item.result.SourceID, &item.result.DownloadID,
+			&item.status); err != nil {
+			return err
+		}
+		r.items[item.key] = &item
+	}
+
+	return rows.Err()
+}
+
+// Imported implements interfaces.Checkpoint.
+func (r *pipelineRun) Imported(key string) (*interfaces.ImportResult, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	item, ok := r.items[key]
+	if !ok {
+		return nil, false
+	}
+	result := item.result
+	return &result, true
+}
+
+// RecordImport implements interfaces.Checkpoint.
+func (r *pipelineRun) RecordImport(ctx context.Context, key string, result *interfaces.ImportResult) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	item := &runItem{
+		key:      key,
+		position: len(r.items),
+		result:   interfaces.ImportResult{SourceID: result.SourceID, DownloadID: result.DownloadID},
+		status:   itemStatusImported,
+	}
+
+	query := r.dialect.Upsert("pipeline_run_items",
+		[]string{"run_id", "item_key", "position", "source_id", "download_id", "status", "updated_at"},
+		[]string{"run_id", "item_key"},
+		[]string{"source_id", "download_id", "status", "updated_at"})
+
+	_, err := r.db.ExecContext(context.WithoutCancel(ctx), r.dialect.Rebind(query), r.id, key, item.position,
+		item.result.SourceID, item.result.DownloadID, item.status, r.dialect.FormatTime(r.now()))
+	if err != nil {
+		return err
+	}
+
+	r.items[key] = item
+	return nil
+}
+
+// empty reports whether no item has been recorded for the run.
+func (r *pipelineRun) empty() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.items) == 0
+}
+
+// pending returns the items that still need processing, in import order.
+func (r *pipelineRun) pending() []*runItem {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	items := make([]*runItem, 0, len(r.items))
+	for _, item := range r.items {
+		if item.status != itemStatusDone {
+			items = append(items, item)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].position < items[j].position })
+
+	return items
+}
+
+// setItemStatus stores the outcome of processing item. Bookkeeping writes ignore cancellation so
+// a cancelled run still records how far it got.
+func (r *pipelineRun) setItemStatus(ctx context.Context, item *runItem, status string, cause error) error {
+	r.mu.Lock()
+	item.status = status
+	r.mu.Unlock()
+
+	query := `UPDATE pipeline_run_items SET status = ?, error = ?, updated_at = ? WHERE run_id = ? AND item_key = ?`
+	_, err := r.db.ExecContext(context.WithoutCancel(ctx), r.dialect.Rebind(query), status, errorText(cause),
+		r.dialect.FormatTime(r.now()), r.id, item.key)
+	return err
+}
+
+// finish marks the run completed, with the revision it imported, or failed with cause so the next
+// ProcessSource resumes it.
+func (r *pipelineRun) finish(ctx context.Context, cause error) error {
+	now := r.dialect.FormatTime(r.now())
+
+	var err error
+	if cause == nil {
+		query := `UPDATE pipeline_runs SET status = ?, error = NULL, updated_at = ?, finished_at = ?, revision = ?,
+				  embedding_model = ? WHERE id = ?`
+		_, err = r.db.ExecContext(context.WithoutCancel(ctx), r.dialect.Rebind(query), runStatusCompleted,
+			now, now, sql.NullString{String: r.revision, Valid: r.revision != ""}, r.model, r.id)
+	} else {
+		query := `UPDATE pipeline_runs SET status = ?, error = ?, updated_at = ? WHERE id = ?`
+		_, err = r.db.ExecContext(context.WithoutCancel(ctx), r.dialect.Rebind(query), runStatusFailed,
+			util.RedactString(cause.Error()), now, r.id)
+	}
+
+	return err
+}
+
+// errorText returns the message of err for a nullable error column.
+func errorText(err error) sql.NullString {
+	if err == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: util.RedactString(err.Error()), Valid: true}
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
+	"github.com/code-sleuth/ike-go/internal/manager/testutil"
+)
+
+func TestPipelineRun_pending(t *testing.T) {
+	run := &pipelineRun{items: map[string]*runItem{
+		"c.md": {key: "c.md", position: 2, status: itemStatusImported},
+		"a.md": {key: "a.md", position: 0, status: itemStatusDone},
+		"b.md": {key: "b.md", position: 1, status: itemStatusFailed},
+		"d.md": {key: "d.md", position: 3, status: itemStatusImported},
+	}}
+
+	pending := run.pending()
+
+	expected := []string{"b.md", "c.md", "d.md"}
+	if len(pending) != len(expected) {
+		t.Fatalf("Expected %d pending items, got %d", len(expected), len(pending))
+	}
+	for i, key := range expected {
+		if pending[i].key != key {
+			t.Errorf("Expected pending item %d to be %s, got %s", i, key, pending[i].key)
+		}
+	}
+}
+
+func TestPipelineRun_Imported(t *testing.T) {
+	run := &pipelineRun{items: map[string]*runItem{
+		"docs/intro.md": {
+			key:    "docs/intro.md",
+			result: interfaces.ImportResult{SourceID: "source-1", DownloadID: "download-1"},
+			status: itemStatusDone,
+		},
+	}}
+
+	tests := []struct {
+		name           string
+		key            string
+		expectFound    bool
+		expectedResult string
+		description    string
+	}{
+		{
+			name:           "recorded item",
+			key:            "docs/intro.md",
+			expectFound:    true,
+			expectedResult: "download-1",
+			description:    "should return the download recorded by an earlier attempt",
+		},
+		{
+			name:        "new item",
+			key:         "docs/setup.md",
+			expectFound: false,
+			description: "should not skip items the run has not seen",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, found := run.Imported(tt.key)
+			if found != tt.expectFound {
+				t.Fatalf("Expected found %v, got %v for test: %s", tt.expectFound, found, tt.description)
+			}
+			if found && result.DownloadID != tt.expectedResult {
+				t.Errorf("Expected download %s, got %s", tt.expectedResult, result.DownloadID)
+			}
+		})
+	}
+}
+
+func TestCheckpointFromContext(t *testing.T) {
+	run := &pipelineRun{items: map[string]*runItem{}}
+
+	if _, ok := interfaces.CheckpointFromContext(context.Background()).Imported("any"); ok {
+		t.Errorf("Expected a context without checkpoint to skip nothing")
+	}
+	ctx := interfaces.WithCheckpoint(context.Background(), run)
+	if checkpoint := interfaces.CheckpointFromContext(ctx); checkpoint != run {
+		t.Errorf("Expected the attached run, got %v", checkpoint)
+	}
+}
+
+func TestErrorText(t *testing.T) {
+	if errorText(nil).Valid {
+		t.Error("Expected no error text for a nil error")
+	}
+
+	text := errorText(fmt.Errorf("fetch %q: %w", "https://example.com/feed?access_token=s3cret", io.EOF))
+	if !text.Valid || strings.Contains(text.String, "s3cret") {
+		t.Errorf("Expected the token to be redacted, got %q", text.String)
+	}
+}
+
+// A restart abandons the interrupted run, so the next import resumes the restarted one instead.
+func TestProcessingEngine_startRun_Restart_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db := testutil.SetupTestDB(t)
+	defer testutil.CleanupTestDB(t, db)
+
+	sourceURL := "https://github.com/owner/restarted-repo"
+	t.Cleanup(func() {
+		_, _ = db.Exec(`DELETE FROM pipeline_runs WHERE source_url = ?`, sourceURL)
+	})
+
+	engine := NewProcessingEngine()
+	ctx := context.Background()
+
+	interrupted, err := engine.startRun(ctx, db, sourceURL, false)
+	if err != nil {
+		t.Fatalf("Failed to start run: %v", err)
+	}
+	restarted, err := engine.startRun(ctx, db, sourceURL, true)
+	if err != nil {
+		t.Fatalf("Failed to restart run: %v", err)
+	}
+	if restarted.id == interrupted.id || restarted.resumed {
+		t.Fatalf("Expected restart to start a new run, got %s (resumed %v)", restarted.id, restarted.resumed)
+	}
+
+	var status string
+	if err := db.QueryRow(`SELECT status FROM pipeline_runs WHERE id = ?`, interrupted.id).Scan(&status); err != nil {
+		t.Fatalf("Failed to read run status: %v", err)
+	}
+	if status != runStatusAbandoned {
+		t.Errorf("Expected interrupted run to be %s, got %s", runStatusAbandoned, status)
+	}
+
+	resumed, err := engine.startRun(ctx, db, sourceURL, false)
+	if err != nil {
+		t.Fatalf("Failed to resume run: %v", err)
+	}
+	if resumed.id != restarted.id || !resumed.resumed {
+		t.Errorf("Expected run %s to be resumed, got %s (resumed %v)", restarted.id, resumed.id, resumed.resumed)
+	}
+}
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
+	"github.com/code-sleuth/ike-go/pkg/util"
+)
+
+// sourceSettings is a row of source_settings; invalid columns leave the option unchanged.
+type sourceSettings struct {
+	sourceURL      string
+	chunkStrategy  sql.NullString
+	maxTokens      sql.NullInt64
+	embeddingModel sql.NullString
+	concurrency    sql.NullInt64
+	aclLabels      sql.NullString
+}
+
+// apply returns a copy of options with the settings' overrides applied.
+func (s *sourceSettings) apply(options *interfaces.ProcessingOptions) *interfaces.ProcessingOptions {
+	resolved := *options
+	if s.chunkStrategy.Valid {
+		resolved.ChunkStrategy = s.chunkStrategy.String
+	}
+	if s.maxTokens.Valid {
+		resolved.MaxTokens = int(s.maxTokens.Int64)
+	}
+	if s.embeddingModel.Valid {
+		resolved.EmbeddingModel = s.embeddingModel.String
+	}
+	if s.concurrency.Valid {
+		resolved.Concurrency = int(s.concurrency.Int64)
+	}
+	if s.aclLabels.Valid {
+		var labels []string
+		if err := json.Unmarshal([]byte(s.aclLabels.String), &labels); err == nil && len(labels) > 0 {
+			resolved.ACLLabels = labels
+		}
+	}
+	return &resolved
+}
+
+// applySourceSettings returns options with the overrides stored for the longest source_settings
+// URL that rawURL falls under, or options itself when none do.
+func (e *ProcessingEngine) applySourceSettings(
+	ctx context.Context,
+	db *sql.DB,
+	rawURL string,
+	options *interfaces.ProcessingOptions,
+) (*interfaces.ProcessingOptions, error) {
+	query := `SELECT source_url, chunk_strategy, max_tokens, embedding_model, concurrency, acl_labels
+			  FROM source_settings WHERE SUBSTR(CAST(? AS TEXT), 1, LENGTH(source_url)) = source_url`
+	rows, err := db.QueryContext(ctx, e.dialect.Rebind(query), rawURL)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var best *sourceSettings
+	for rows.Next() {
+		var s sourceSettings
+		if err := rows.Scan(&s.sourceURL, &s.chunkStrategy, &s.maxTokens, &s.embeddingModel,
+			&s.concurrency, &s.aclLabels); err != nil {
+			return nil, err
+		}
+		if util.UnderURL(rawURL, s.sourceURL) && (best == nil || len(s.sourceURL) > len(best.sourceURL)) {
+			best = &s
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if best == nil {
+		return options, nil
+	}
+	e.logger.Debug().Str("url", rawURL).Str("settings_url", best.sourceURL).Msg("Applying source settings")
+	return best.apply(options), nil
+}
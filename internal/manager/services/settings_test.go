@@ -0,0 +1,36 @@
+package services
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
+)
+
+func TestSourceSettings_Apply(t *testing.T) {
+	options := &interfaces.ProcessingOptions{
+		MaxTokens:      8191,
+		ChunkStrategy:  "token",
+		EmbeddingModel: "text-embedding-3-small",
+		Concurrency:    5,
+		Collection:     "docs",
+	}
+	settings := &sourceSettings{
+		chunkStrategy: sql.NullString{String: "heading", Valid: true},
+		maxTokens:     sql.NullInt64{Int64: 512, Valid: true},
+	}
+
+	resolved := settings.apply(options)
+
+	if resolved.ChunkStrategy != "heading" || resolved.MaxTokens != 512 {
+		t.Errorf("Expected the overridden strategy and tokens, got %s and %d", resolved.ChunkStrategy,
+			resolved.MaxTokens)
+	}
+	if resolved.EmbeddingModel != "text-embedding-3-small" || resolved.Concurrency != 5 ||
+		resolved.Collection != "docs" {
+		t.Errorf("Expected options without overrides to be kept, got %+v", resolved)
+	}
+	if options.ChunkStrategy != "token" || options.MaxTokens != 8191 {
+		t.Errorf("Expected the original options to be left unchanged, got %+v", options)
+	}
+}
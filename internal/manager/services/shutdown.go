@@ -0,0 +1,71 @@
+package services
+
+import (
+	"context"
+	"errors"
+)
+
+var ErrShuttingDown = errors.New("processing engine is shutting down")
+
+// begin registers a call as in-flight work that Shutdown waits for. The returned context is
+// cancelled with ErrShuttingDown as its cause if Shutdown's deadline passes first; done must be
+// called when the work returns. Once Shutdown has started, begin refuses new work.
+func (e *ProcessingEngine) begin(ctx context.Context) (context.Context, func(), error) {
+	e.lifecycle.Lock()
+	defer e.lifecycle.Unlock()
+
+	if e.draining {
+		return ctx, func() {}, ErrShuttingDown
+	}
+	e.inflight.Add(1)
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	stop := context.AfterFunc(e.abort, func() { cancel(ErrShuttingDown) })
+	return ctx, func() {
+		stop()
+		cancel(nil)
+		e.inflight.Done()
+	}, nil
+}
+
+// stopping reports whether Shutdown has been called.
+func (e *ProcessingEngine) stopping() bool {
+	select {
+	case <-e.closing:
+		return true
+	default:
+		return false
+	}
+}
+
+// Shutdown stops the engine accepting new work and waits for in-flight calls to return. Sources
+// stop between documents, so the document being embedded is finished and the rest of the run is
+// left for the next ProcessSource to resume; job workers stop claiming jobs. If ctx ends first,
+// in-flight work is cancelled, its run and job are left resumable, and Shutdown returns ctx's
+// error once that work has returned. Shutdown is safe to call more than once.
+func (e *ProcessingEngine) Shutdown(ctx context.Context) error {
+	e.lifecycle.Lock()
+	if !e.draining {
+		e.draining = true
+		close(e.closing)
+	}
+	e.lifecycle.Unlock()
+
+	e.logger.Info().Msg("Draining processing engine")
+	done := make(chan struct{})
+	go func() {
+		e.inflight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+	}
+
+	e.logger.Warn().Msg("Shutdown deadline reached, cancelling in-flight work")
+	e.cancelAbort()
+	<-done
+	return ctx.Err()
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/code-sleuth/ike-go/internal/manager/models"
+)
+
+// Test that a shut-down engine refuses new work through every entry point.
+func TestProcessingEngine_Shutdown_RefusesNewWork(t *testing.T) {
+	engine := NewProcessingEngine()
+	if err := engine.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Expected an idle engine to shut down cleanly, got %v", err)
+	}
+	if err := engine.Shutdown(context.Background()); err != nil {
+		t.Errorf("Expected a second Shutdown to succeed, got %v", err)
+	}
+
+	ctx := context.Background()
+	if err := engine.ProcessSource(ctx, "https://github.com/owner/repo", nil, nil); !errors.Is(err, ErrShuttingDown) {
+		t.Errorf("Expected ProcessSource to return ErrShuttingDown, got %v", err)
+	}
+	if err := engine.ProcessDocument(ctx, "download-1", nil, nil); !errors.Is(err, ErrShuttingDown) {
+		t.Errorf("Expected ProcessDocument to return ErrShuttingDown, got %v", err)
+	}
+	if err := engine.RunJob(ctx, &models.Job{Kind: JobKindImport}, nil); !errors.Is(err, ErrShuttingDown) {
+		t.Errorf("Expected RunJob to return ErrShuttingDown, got %v", err)
+	}
+	if _, err := engine.Rechunk(ctx, RechunkFilter{}, nil, nil); !errors.Is(err, ErrShuttingDown) {
+		t.Errorf("Expected Rechunk to return ErrShuttingDown, got %v", err)
+	}
+	reembed := ReembedOptions{From: "text-embedding-ada-002", To: "text-embedding-3-large"}
+	if _, err := engine.Reembed(ctx, reembed, nil); !errors.Is(err, ErrShuttingDown) {
+		t.Errorf("Expected Reembed to return ErrShuttingDown, got %v", err)
+	}
+
+	report, err := engine.ProcessSources(ctx, []string{"https://a.example", "https://b.example"}, nil, nil)
+	if !errors.Is(err, ErrShuttingDown) {
+		t.Errorf("Expected ProcessSources to return ErrShuttingDown, got %v", err)
+	}
+	if report.Failed != 2 || !errors.Is(report.Results[1].Err, ErrShuttingDown) {
+		t.Errorf("Expected every source to report ErrShuttingDown, got %+v", report.Results)
+	}
+}
+
+// Test that Drain stops claiming jobs once Shutdown has been called.
+func TestProcessingEngine_Shutdown_StopsDrain(t *testing.T) {
+	engine := NewProcessingEngine()
+	if err := engine.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	queue := &fakeQueue{queued: []*models.Job{{ID: "job-1", Kind: JobKindImport}}}
+	ran, err := engine.Drain(context.Background(), queue, nil, "worker-1", time.Hour)
+	if err != nil || ran != 0 {
+		t.Errorf("Expected no jobs to run, got %d (err %v)", ran, err)
+	}
+	if len(queue.queued) != 1 {
+		t.Errorf("Expected the job to stay queued, got %d queued", len(queue.queued))
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- engine.Work(context.Background(), queue, nil, "worker-1", time.Hour, time.Hour) }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Expected Work to return nil, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Error("Expected Work to return after Shutdown")
+	}
+}
+
+// Test that Shutdown waits for in-flight work that finishes within the deadline.
+func TestProcessingEngine_Shutdown_WaitsForInFlight(t *testing.T) {
+	engine := NewProcessingEngine()
+	ctx, done, err := engine.begin(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	shutdown := make(chan error, 1)
+	go func() { shutdown <- engine.Shutdown(context.Background()) }()
+
+	select {
+	case err := <-shutdown:
+		t.Fatalf("Expected Shutdown to wait for in-flight work, got %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if ctx.Err() != nil {
+		t.Errorf("Expected in-flight work to keep running while draining, got %v", ctx.Err())
+	}
+
+	done()
+	if err := <-shutdown; err != nil {
+		t.Errorf("Expected Shutdown to succeed once work finished, got %v", err)
+	}
+}
+
+// Test that in-flight work still running at the deadline is cancelled with ErrShuttingDown.
+func TestProcessingEngine_Shutdown_Deadline(t *testing.T) {
+	engine := NewProcessingEngine()
+	ctx, done, err := engine.begin(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	cause := make(chan error, 1)
+	go func() {
+		<-ctx.Done()
+		cause <- context.Cause(ctx)
+		done()
+	}()
+
+	deadline, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := engine.Shutdown(deadline); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	if err := <-cause; !errors.Is(err, ErrShuttingDown) {
+		t.Errorf("Expected in-flight work to be cancelled with ErrShuttingDown, got %v", err)
+	}
+}
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"iter"
+	"time"
+
+	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
+	"github.com/code-sleuth/ike-go/internal/manager/models"
+	"github.com/code-sleuth/ike-go/pkg/tracing"
+
+	"github.com/google/uuid"
+)
+
+// errStopStream ends a chunker's stream when its chunks are no longer wanted.
+var errStopStream = errors.New("chunk stream stopped")
+
+// streamAndEmbed is chunkAndEmbed for chunkers that stream: each chunk is embedded and saved as
+// soon as it is cut, and the chunker is only asked for more as fast as they are embedded, so only
+// the chunks in flight are in memory. Chunks saved before the chunker fails are kept, as are those
+// of a document whose embedding partly fails.
+func (e *ProcessingEngine) streamAndEmbed(
+	ctx context.Context,
+	chunker interfaces.StreamingChunker,
+	transformResult *interfaces.TransformResult,
+	hook *interfaces.HookPayload,
+	options *interfaces.ProcessingOptions,
+	embedder interfaces.Embedder,
+	db *sql.DB,
+) error {
+	documentID := transformResult.Document.ID
+	e.logger.Info().
+		Str("document_id", documentID).
+		Str("chunk_strategy", options.ChunkStrategy).
+		Int("max_tokens", options.MaxTokens).
+		Str("embedding_model", options.EmbeddingModel).
+		Int("concurrency", options.Concurrency).
+		Msg("Starting streamed chunking and embedding")
+
+	hook.Stage, hook.Phase = interfaces.HookChunk, interfaces.HookBefore
+	if err := e.runHooks(ctx, hook); err != nil {
+		observeStage(interfaces.HookChunk, time.Now(), err)
+		e.logger.Error().Err(err).Str("document_id", documentID).Msg("Chunking failed")
+		interfaces.ReportProgress(ctx, interfaces.ProgressEvent{
+			Stage: interfaces.StageFailed, Item: documentID, Count: 1, Err: err,
+		})
+		return err
+	}
+
+	embedCtx, span := tracing.Start(ctx, spanEmbedChunks, tracing.String("document.id", documentID),
+		tracing.String("chunk.strategy", options.ChunkStrategy),
+		tracing.String("embedding.model", options.EmbeddingModel))
+	embedCtx = withEmbedTimeout(embedCtx, options.EmbedTimeout)
+	start := time.Now()
+
+	// count and chunkErr are read once processChunkStream has returned, after the stream has ended
+	count := 0
+	var chunkErr error
+	chunks := func(yield func(*models.Chunk) bool) {
+		for chunk := range chunkStream(chunker, transformResult.Content, options.MaxTokens, &chunkErr) {
+			count++
+			interfaces.ReportProgress(ctx, interfaces.ProgressEvent{
+				Stage: interfaces.StageChunked, Item: documentID, Count: 1,
+			})
+			if !yield(chunk) {
+				return
+			}
+		}
+	}
+	err := e.processChunkStream(embedCtx, linkStream(chunks, documentID), documentID, embedder, db,
+		options.Concurrency)
+
+	observeStage(interfaces.HookChunk, start, chunkErr)
+	// Embedding failures are counted per chunk by processChunkStream
+	observeStage(interfaces.HookEmbed, start, nil)
+	span.SetAttributes(tracing.Int("chunk.count", count))
+	if chunkErr != nil {
+		e.logger.Error().Err(chunkErr).Str("document_id", documentID).Msg("Chunking failed")
+		interfaces.ReportProgress(ctx, interfaces.ProgressEvent{
+			Stage: interfaces.StageFailed, Item: documentID, Count: 1, Err: chunkErr,
+		})
+		err = errors.Join(chunkErr, err)
+	}
+	endSpan(span, err)
+	if err == nil {
+		e.recordEvent(ctx, db, models.EventChunksEmbedded, transformResult.Document.SourceID, documentID,
+			fmt.Sprintf("%d chunks embedded with %s", count, options.EmbeddingModel))
+	}
+	return err
+}
+
+// chunkStream returns the chunks chunker cuts from content, as it cuts them. Once the sequence
+// ends, *err holds the chunker's error, if any.
+func chunkStream(
+	chunker interfaces.StreamingChunker,
+	content string,
+	maxTokens int,
+	err *error,
+) iter.Seq[*models.Chunk] {
+	return func(yield func(*models.Chunk) bool) {
+		*err = chunker.StreamChunks(content, maxTokens, func(chunk *models.Chunk) error {
+			if !yield(chunk) {
+				return errStopStream
+			}
+			return nil
+		})
+		if errors.Is(*err, errStopStream) {
+			*err = nil
+		}
+	}
+}
+
+// linkStream is linkChunks for chunks handed over one at a time: each chunk is held back until the
+// next is cut, so its right neighbour is known when it is handed on.
+func linkStream(chunks iter.Seq[*models.Chunk], documentID string) iter.Seq[*models.Chunk] {
+	return func(yield func(*models.Chunk) bool) {
+		var previous *models.Chunk
+		for chunk := range chunks {
+			chunk.DocumentID = documentID
+			if chunk.ID == "" {
+				chunk.ID = uuid.New().String()
+			}
+			if previous != nil {
+				if chunk.LeftChunkID == nil {
+					chunk.LeftChunkID = &previous.ID
+				}
+				if previous.RightChunkID == nil {
+					previous.RightChunkID = &chunk.ID
+				}
+				if !yield(previous) {
+					return
+				}
+			}
+			previous = chunk
+		}
+		if previous != nil {
+			yield(previous)
+		}
+	}
+}
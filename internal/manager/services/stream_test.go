@@ -0,0 +1,78 @@
+package services
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/code-sleuth/ike-go/internal/manager/models"
+)
+
+// streamingChunker streams the chunks it was given, then fails with err if set.
+type streamingChunker struct {
+	chunks []*models.Chunk
+	err    error
+}
+
+func (s *streamingChunker) StreamChunks(_ string, _ int, yield func(*models.Chunk) error) error {
+	for _, chunk := range s.chunks {
+		if err := yield(chunk); err != nil {
+			return err
+		}
+	}
+	return s.err
+}
+
+func TestLinkStream(t *testing.T) {
+	parent := "parent"
+	chunks := []*models.Chunk{{ID: "a"}, {}, {ID: "c", LeftChunkID: &parent}}
+
+	linked := slices.Collect(linkStream(slices.Values(chunks), "doc"))
+	if len(linked) != 3 {
+		t.Fatalf("Expected 3 chunks, got %d", len(linked))
+	}
+	for _, chunk := range linked {
+		if chunk.DocumentID != "doc" || chunk.ID == "" {
+			t.Errorf("Expected chunk to be assigned to doc with an ID, got %+v", chunk)
+		}
+	}
+	if linked[0].LeftChunkID != nil || *linked[0].RightChunkID != linked[1].ID {
+		t.Errorf("Expected the first chunk to link only to the second")
+	}
+	if *linked[1].LeftChunkID != "a" || *linked[1].RightChunkID != "c" {
+		t.Errorf("Expected the second chunk to link to both neighbours")
+	}
+	if *linked[2].LeftChunkID != "parent" || linked[2].RightChunkID != nil {
+		t.Errorf("Expected the last chunk to keep the link its chunker set")
+	}
+
+	// Stopping early hands over no more chunks
+	for chunk := range linkStream(slices.Values(chunks), "doc") {
+		if chunk.ID != "a" {
+			t.Errorf("Expected to stop after the first chunk, got %s", chunk.ID)
+		}
+		break
+	}
+}
+
+func TestChunkStream(t *testing.T) {
+	failure := errors.New("tokenizer failed")
+	chunker := &streamingChunker{chunks: []*models.Chunk{{ID: "a"}, {ID: "b"}}, err: failure}
+
+	var err error
+	var ids []string
+	for chunk := range chunkStream(chunker, "content", 10, &err) {
+		ids = append(ids, chunk.ID)
+	}
+	if len(ids) != 2 || !errors.Is(err, failure) {
+		t.Errorf("Expected both chunks and then the chunker's error, got %v, %v", ids, err)
+	}
+
+	// A consumer that stops early is not an error
+	for range chunkStream(chunker, "content", 10, &err) {
+		break
+	}
+	if err != nil {
+		t.Errorf("Expected no error after stopping early, got %v", err)
+	}
+}
@@ -0,0 +1,60 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
+)
+
+// ErrStageTimeout is the cause of a stage that ran past its own timeout, as opposed to the
+// deadline of the whole run.
+var ErrStageTimeout = errors.New("stage timed out")
+
+type embedTimeoutKey struct{}
+
+// withEmbedTimeout returns a context that bounds the embedding of each chunk processed under it
+// by timeout. The embedding pool is shared by several callers, so the timeout travels with the
+// context rather than through every one of them.
+func withEmbedTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	if timeout <= 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, embedTimeoutKey{}, timeout)
+}
+
+// embedTimeout returns the timeout set with withEmbedTimeout, or zero.
+func embedTimeout(ctx context.Context) time.Duration {
+	timeout, _ := ctx.Value(embedTimeoutKey{}).(time.Duration)
+	return timeout
+}
+
+// withStageTimeout returns ctx bounded by timeout, or ctx itself when timeout is zero or less.
+// When timeout passes first, the cause of the returned context is an ErrStageTimeout naming
+// stage.
+func withStageTimeout(
+	ctx context.Context,
+	stage interfaces.HookStage,
+	timeout time.Duration,
+) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	cause := fmt.Errorf("%w: %s took longer than %s", ErrStageTimeout, stage, timeout)
+	return context.WithTimeoutCause(ctx, timeout, cause)
+}
+
+// stageError adds the ErrStageTimeout cause of stageCtx to err when the stage's own timeout
+// ended it, so the error says which limit was hit. Errors of a stage that was cut short by its
+// parent context, or did not time out at all, are returned unchanged.
+func stageError(stageCtx context.Context, err error) error {
+	if err == nil || stageCtx.Err() == nil {
+		return err
+	}
+	if cause := context.Cause(stageCtx); errors.Is(cause, ErrStageTimeout) && !errors.Is(err, ErrStageTimeout) {
+		return fmt.Errorf("%w: %w", cause, err)
+	}
+	return err
+}
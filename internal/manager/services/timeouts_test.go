@@ -0,0 +1,79 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
+	"github.com/code-sleuth/ike-go/internal/manager/models"
+)
+
+// hangingEmbedder never answers; it returns once ctx is done, like an HTTP client would.
+type hangingEmbedder struct {
+	mockEmbedder
+}
+
+func (h *hangingEmbedder) GenerateEmbedding(ctx context.Context, _ string) ([]float32, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestProcessingEngine_processChunk_EmbedTimeout(t *testing.T) {
+	embedder := &hangingEmbedder{mockEmbedder{modelName: "text-embedding-3-small", dimension: 1536}}
+	chunk := &models.Chunk{ID: "chunk-1", DocumentID: "doc-1", Body: stringPtr("content")}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	result := NewProcessingEngine().processChunk(withEmbedTimeout(ctx, 10*time.Millisecond), chunk, embedder, nil)
+	if !errors.Is(result.Error, ErrStageTimeout) || !errors.Is(result.Error, context.DeadlineExceeded) {
+		t.Errorf("Expected the chunk to fail with ErrStageTimeout, got %v", result.Error)
+	}
+	if ctx.Err() != nil {
+		t.Errorf("Expected the run's context to outlive the chunk's timeout, got %v", ctx.Err())
+	}
+}
+
+func TestStageError(t *testing.T) {
+	t.Run("stage timeout", func(t *testing.T) {
+		ctx, cancel := withStageTimeout(context.Background(), interfaces.HookImport, time.Nanosecond)
+		defer cancel()
+		<-ctx.Done()
+
+		err := stageError(ctx, ctx.Err())
+		if !errors.Is(err, ErrStageTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected ErrStageTimeout wrapping the deadline, got %v", err)
+		}
+		expected := "stage timed out: import took longer than 1ns: context deadline exceeded"
+		if err.Error() != expected {
+			t.Errorf("Expected %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("parent deadline", func(t *testing.T) {
+		parent, cancelParent := context.WithTimeout(context.Background(), time.Nanosecond)
+		defer cancelParent()
+		ctx, cancel := withStageTimeout(parent, interfaces.HookImport, time.Hour)
+		defer cancel()
+		<-ctx.Done()
+
+		if err := stageError(ctx, ctx.Err()); errors.Is(err, ErrStageTimeout) {
+			t.Errorf("Expected the run's deadline not to be blamed on the stage, got %v", err)
+		}
+	})
+
+	t.Run("no timeout", func(t *testing.T) {
+		ctx, cancel := withStageTimeout(context.Background(), interfaces.HookImport, 0)
+		defer cancel()
+
+		if _, ok := ctx.Deadline(); ok {
+			t.Error("Expected a zero timeout to leave the context without a deadline")
+		}
+		failure := errors.New("not found")
+		if err := stageError(ctx, failure); !errors.Is(err, failure) || errors.Is(err, ErrStageTimeout) {
+			t.Errorf("Expected other errors to be returned unchanged, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,22 @@
+package services
+
+import "github.com/code-sleuth/ike-go/pkg/tracing"
+
+// Span names for the stages of the pipeline. Each stage's span is a child of the one that
+// started it, so a trace shows a whole source run from import to the last saved chunk.
+const (
+	spanProcessSource = "ike.process_source"
+	spanImport        = "ike.import"
+	spanProcessDoc    = "ike.process_document"
+	spanTransform     = "ike.transform"
+	spanChunk         = "ike.chunk"
+	spanEmbedChunks   = "ike.embed_chunks"
+	spanEmbed         = "ike.embed"
+	spanSave          = "ike.save"
+)
+
+// endSpan ends span, marking it failed when err is set.
+func endSpan(span *tracing.Span, err error) {
+	span.RecordError(err)
+	span.End()
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+)
+
+// contentHash returns the hash stored on documents to detect unchanged content.
+func contentHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// skipUnchanged records hash on the freshly transformed document and reports whether an earlier
+// document of the same source has identical content that is fully embedded with model. When it
+// has, the new document is removed as a duplicate and the earlier one stays searchable, so the
+// caller can skip chunking and embedding.
+func (e *ProcessingEngine) skipUnchanged(
+	ctx context.Context,
+	db *sql.DB,
+	documentID, sourceID, hash, model string,
+) (bool, error) {
+	_, err := db.ExecContext(ctx, e.dialect.Rebind(`UPDATE documents SET content_hash = ? WHERE id = ?`),
+		hash, documentID)
+	if err != nil {
+		return false, err
+	}
+
+	// Documents with dead-lettered chunks are incomplete and must be processed again
+	query := `
+		SELECT d.id FROM documents d
+		WHERE d.source_id = ? AND d.id != ? AND d.content_hash = ? AND d.deleted_at IS NULL
+		  AND EXISTS (
+		      SELECT 1 FROM chunks c JOIN embeddings em ON em.object_id = c.id AND em.object_type = 'chunk'
+		      WHERE c.document_id = d.id AND em.model = ?)
+		  AND NOT EXISTS (SELECT 1 FROM failed_chunks f WHERE f.document_id = d.id)
+		LIMIT 1
+	`
+	var existingID string
+	err = db.QueryRowContext(ctx, e.dialect.Rebind(query), sourceID, documentID, hash, model).Scan(&existingID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if err := e.removeDuplicateDocument(ctx, db, documentID); err != nil {
+		return false, err
+	}
+	e.logger.Info().
+		Str("document_id", existingID).
+		Str("duplicate_id", documentID).
+		Msg("Content unchanged, skipping chunking and embedding")
+	return true, nil
+}
+
+// removeDuplicateDocument deletes a document that was just transformed and has no chunks yet.
+func (e *ProcessingEngine) removeDuplicateDocument(ctx context.Context, db *sql.DB, duplicateID string) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	statements := []string{
+		`DELETE FROM document_meta WHERE document_id = ?`,
+		`DELETE FROM document_tags WHERE document_id = ?`,
+		`DELETE FROM documents WHERE id = ?`,
+	}
+	for _, statement := range statements {
+		if _, err := tx.ExecContext(ctx, e.dialect.Rebind(statement), duplicateID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
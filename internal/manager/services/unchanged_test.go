@@ -0,0 +1,38 @@
+package services
+
+import "testing"
+
+func TestContentHash(t *testing.T) {
+	tests := []struct {
+		name        string
+		a, b        string
+		expectEqual bool
+		description string
+	}{
+		{
+			name:        "identical content",
+			a:           "# Title\n\nBody",
+			b:           "# Title\n\nBody",
+			expectEqual: true,
+			description: "should hash unchanged content the same so re-imports skip it",
+		},
+		{
+			name:        "changed content",
+			a:           "# Title\n\nBody",
+			b:           "# Title\n\nBody!",
+			expectEqual: false,
+			description: "should hash changed content differently so it is embedded again",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if equal := contentHash(tt.a) == contentHash(tt.b); equal != tt.expectEqual {
+				t.Errorf("Expected equal hashes %v, got %v for test: %s", tt.expectEqual, equal, tt.description)
+			}
+			if len(contentHash(tt.a)) != 64 {
+				t.Errorf("Expected a hex SHA-256, got %q", contentHash(tt.a))
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
+	"github.com/code-sleuth/ike-go/internal/manager/models"
+	"github.com/code-sleuth/ike-go/internal/manager/repository"
+	"github.com/code-sleuth/ike-go/pkg/dialect"
+	"github.com/code-sleuth/ike-go/pkg/util"
+
+	"github.com/rs/zerolog"
+)
+
+// JobEnqueuer adds jobs to the queue; repository.JobRepository implements it.
+type JobEnqueuer interface {
+	Enqueue(job *models.Job) error
+}
+
+// WebhookReceiver turns change notifications from sources into import jobs for the sources
+// already in the database. Notifications about sources that were never imported are ignored.
+type WebhookReceiver struct {
+	db      *sql.DB
+	dialect dialect.Dialect
+	queue   JobEnqueuer
+	options interfaces.ProcessingOptions
+	logger  zerolog.Logger
+}
+
+// NewWebhookReceiver creates a receiver that enqueues imports with options. The collection of
+// each job is taken from the sources being updated.
+func NewWebhookReceiver(
+	db *sql.DB,
+	d dialect.Dialect,
+	queue JobEnqueuer,
+	options interfaces.ProcessingOptions,
+) *WebhookReceiver {
+	return &WebhookReceiver{
+		db:      db,
+		dialect: d,
+		queue:   queue,
+		options: options,
+		logger:  util.NewLogger(zerolog.ErrorLevel),
+	}
+}
+
+// GitHubPush enqueues a re-import of the given paths for every collection holding files from
+// branch of repository ("owner/repo"). It returns the IDs of the enqueued jobs.
+func (r *WebhookReceiver) GitHubPush(ctx context.Context, repository, branch string, paths []string) ([]string, error) {
+	if len(paths) == 0 {
+		return nil, nil
+	}
+
+	// The GitHub importer stores file URLs as https://github.com/owner/repo/blob/<ref>/<path>
+	prefix := fmt.Sprintf("https://github.com/%s/blob/%s/", repository, branch)
+	collections, err := r.collections(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	if len(collections) == 0 {
+		r.logger.Info().Str("repository", repository).Str("branch", branch).Msg("Ignoring push for unknown source")
+		return nil, nil
+	}
+
+	sourceURL := fmt.Sprintf("https://github.com/%s/tree/%s", repository, branch)
+	return r.enqueue(interfaces.WithActor(ctx, "webhook:github"), sourceURL, collections, paths)
+}
+
+// WordPressPost enqueues a re-import of a single post for every collection holding posts from
+// endpoint, a WP-JSON collection URL such as https://example.com/wp-json/wp/v2/posts. It
+// returns the IDs of the enqueued jobs.
+func (r *WebhookReceiver) WordPressPost(ctx context.Context, endpoint string, postID int) ([]string, error) {
+	endpoint = strings.TrimRight(endpoint, "/")
+
+	// The WP-JSON importer stores post URLs as <endpoint>/<id>
+	collections, err := r.collections(ctx, endpoint+"/")
+	if err != nil {
+		return nil, err
+	}
+	if len(collections) == 0 {
+		r.logger.Info().Str("endpoint", endpoint).Int("post_id", postID).Msg("Ignoring update for unknown source")
+		return nil, nil
+	}
+
+	return r.enqueue(interfaces.WithActor(ctx, "webhook:wordpress"), endpoint, collections,
+		[]string{strconv.Itoa(postID)})
+}
+
+// collections returns the collections of live sources whose URL starts with prefix.
+func (r *WebhookReceiver) collections(ctx context.Context, prefix string) ([]string, error) {
+	query := `SELECT DISTINCT collection FROM sources
+			  WHERE LOWER(raw_url) LIKE ? ESCAPE '\' AND deleted_at IS NULL ORDER BY collection`
+	rows, err := r.db.QueryContext(ctx, r.dialect.Rebind(query), escapeLike(strings.ToLower(prefix))+"%")
+	if err != nil {
+		r.logger.Error().Err(err).Str("prefix", prefix).Msg("Failed to look up sources")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var collections []string
+	for rows.Next() {
+		var collection string
+		if err := rows.Scan(&collection); err != nil {
+			return nil, err
+		}
+		collections = append(collections, collection)
+	}
+	return collections, rows.Err()
+}
+
+// enqueue adds one import job for sourceURL per collection, limited to paths, and records the
+// update that caused it in the event log.
+func (r *WebhookReceiver) enqueue(
+	ctx context.Context,
+	sourceURL string,
+	collections, paths []string,
+) ([]string, error) {
+	var ids []string
+	for _, collection := range collections {
+		options := r.options
+		options.Collection = collection
+		options.Paths = paths
+		// Each notification is a fresh import of what changed, not a resume of an earlier one
+		options.Restart = true
+
+		job, err := NewImportJob(sourceURL, &options)
+		if err != nil {
+			return ids, err
+		}
+		if err := r.queue.Enqueue(job); err != nil {
+			return ids, err
+		}
+		r.logger.Info().
+			Str("job_id", job.ID).
+			Str("source_url", sourceURL).
+			Int("path_count", len(paths)).
+			Msg("Enqueued import for webhook")
+		ids = append(ids, job.ID)
+
+		detail := fmt.Sprintf("%s: %d paths changed in collection %s", sourceURL, len(paths), collection)
+		err = repository.RecordEvent(ctx, r.db, r.dialect, &models.Event{
+			Type: models.EventUpdateDetected, JobID: &job.ID, Detail: &detail,
+		})
+		if err != nil {
+			r.logger.Error().Err(err).Str("job_id", job.ID).Msg("Failed to record update")
+		}
+	}
+	return ids, nil
+}
+
+// escapeLike escapes LIKE wildcards so s matches literally with ESCAPE '\'.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
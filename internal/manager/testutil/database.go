@@ -11,7 +11,7 @@ import (
 )
 
 // SetupTestDB creates a test database connection and runs migrations.
-func SetupTestDB(t *testing.T) *sql.DB {
+func SetupTestDB(t testing.TB) *sql.DB {
 	t.Helper()
 	// Load environment variables from .env file
 	err := LoadEnvFromFile("../../../.env")
@@ -23,7 +23,8 @@ func SetupTestDB(t *testing.T) *sql.DB {
 	dbURL := os.Getenv("TURSO_DATABASE_URL")
 	authToken := os.Getenv("TURSO_AUTH_TOKEN")
 
-	if dbURL == "" || authToken == "" {
+	// Local file: databases need no token
+	if dbURL == "" || (authToken == "" && !strings.HasPrefix(dbURL, "file:")) {
 		t.Skip("Database environment variables not set - skipping integration test")
 	}
 
@@ -40,7 +41,7 @@ func SetupTestDB(t *testing.T) *sql.DB {
 }
 
 // CleanupTestDB performs cleanup after tests.
-func CleanupTestDB(t *testing.T, database *sql.DB) {
+func CleanupTestDB(t testing.TB, database *sql.DB) {
 	t.Helper()
 	if database == nil {
 		return
@@ -51,10 +52,12 @@ func CleanupTestDB(t *testing.T, database *sql.DB) {
 }
 
 // cleanupTestData removes all test data from database tables.
-func cleanupTestData(t *testing.T, database *sql.DB) {
+func cleanupTestData(t testing.TB, database *sql.DB) {
 	t.Helper()
 	// Clean up in reverse order of dependencies
 	tables := []string{
+		"events",
+		"jobs",
 		"embeddings",
 		"document_meta",
 		"document_tags",
@@ -0,0 +1,52 @@
+// Package tokenizers splits text into tokens the way embedding models count them, so chunkers
+// can size chunks for the model that embeds them.
+package tokenizers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/tiktoken-go/tokenizer"
+)
+
+// Names of the tiktoken encodings NewTiktoken supports.
+const (
+	Cl100kBase = "cl100k_base"
+	P50kBase   = "p50k_base"
+	R50kBase   = "r50k_base"
+)
+
+var ErrUnknownTokenizer = errors.New("unknown tokenizer")
+
+// Tiktoken is a byte-pair encoding used by OpenAI models.
+type Tiktoken struct {
+	name  string
+	codec tokenizer.Codec
+}
+
+// NewTiktoken returns the named encoding: cl100k_base, p50k_base, or r50k_base.
+func NewTiktoken(name string) (*Tiktoken, error) {
+	name = strings.ToLower(name)
+	switch name {
+	case Cl100kBase, P50kBase, R50kBase:
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownTokenizer, name)
+	}
+	codec, err := tokenizer.Get(tokenizer.Encoding(name))
+	if err != nil {
+		return nil, err
+	}
+	return &Tiktoken{name: name, codec: codec}, nil
+}
+
+// Tokenize splits text into its byte-pair tokens.
+func (t *Tiktoken) Tokenize(text string) ([]string, error) {
+	_, tokens, err := t.codec.Encode(text)
+	return tokens, err
+}
+
+// GetName returns the name of the encoding.
+func (t *Tiktoken) GetName() string {
+	return t.name
+}
@@ -0,0 +1,58 @@
+package tokenizers
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNewTiktoken(t *testing.T) {
+	tokenizer, err := NewTiktoken("CL100K_BASE")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if tokenizer.GetName() != Cl100kBase {
+		t.Errorf("Expected %s, got %s", Cl100kBase, tokenizer.GetName())
+	}
+
+	text := "Hello, world! Tokens concatenate back to the text."
+	tokens, err := tokenizer.Tokenize(text)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(tokens) < 2 || strings.Join(tokens, "") != text {
+		t.Errorf("Expected tokens of %q, got %q", text, tokens)
+	}
+
+	if _, err := NewTiktoken("o200k_harmony"); !errors.Is(err, ErrUnknownTokenizer) {
+		t.Errorf("Expected ErrUnknownTokenizer, got %v", err)
+	}
+}
+
+func TestWordPiece_Tokenize(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected []string
+	}{
+		{name: "words", text: "the quick fox", expected: []string{"the", " quick", " fox"}},
+		{name: "punctuation", text: "Hello, world!", expected: []string{"Hello", ",", " world", "!"}},
+		{name: "long word", text: "internationalization", expected: []string{"intern", "ationa", "lizati", "on"}},
+		{name: "surrounding whitespace", text: "  hi there \n", expected: []string{"  hi", " there \n"}},
+		{name: "chinese", text: "中文 text", expected: []string{"中", "文", " text"}},
+		{name: "unicode punctuation", text: "naïve—café", expected: []string{"naïve", "—", "café"}},
+		{name: "whitespace only", text: " \t\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, err := WordPiece{}.Tokenize(tt.text)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(tokens, tt.expected) {
+				t.Errorf("Expected %q, got %q", tt.expected, tokens)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package tokenizers
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// wordPieceRunes is the longest piece of a word WordPiece counts as one token. BERT's vocabulary
+// holds most common words whole and splits rarer ones into pieces of a few characters.
+const wordPieceRunes = 6
+
+// WordPiece estimates the tokens of BERT-based models, such as Together AI's M2-BERT, without
+// their vocabulary. Like BERT it splits text on whitespace, punctuation, and Chinese characters,
+// then counts each word as one token per wordPieceRunes characters, which errs towards more
+// tokens than the model counts for long words, so chunks stay within its limit.
+type WordPiece struct{}
+
+// Tokenize splits text into estimated WordPiece tokens. Whitespace belongs to the token after
+// it, or to the last token at the end of text; text that is all whitespace has no tokens.
+func (WordPiece) Tokenize(text string) ([]string, error) {
+	var tokens []string
+	start, wordRunes := 0, 0
+	emit := func(end int) {
+		tokens = append(tokens, text[start:end])
+		start, wordRunes = end, 0
+	}
+
+	for i, r := range text {
+		end := i + utf8.RuneLen(r)
+		switch {
+		case unicode.IsSpace(r):
+			if wordRunes > 0 {
+				emit(i)
+			}
+		case isPunctuation(r) || unicode.Is(unicode.Han, r):
+			if wordRunes > 0 {
+				emit(i)
+			}
+			emit(end)
+		default:
+			wordRunes++
+			if wordRunes == wordPieceRunes {
+				emit(end)
+			}
+		}
+	}
+
+	switch {
+	case wordRunes > 0:
+		emit(len(text))
+	case start < len(text) && len(tokens) > 0:
+		tokens[len(tokens)-1] += text[start:]
+	}
+	return tokens, nil
+}
+
+// GetName returns "wordpiece".
+func (WordPiece) GetName() string {
+	return "wordpiece"
+}
+
+// isPunctuation reports whether BERT splits words at r: any ASCII character other than a letter,
+// digit, or space, and any Unicode punctuation.
+func isPunctuation(r rune) bool {
+	if r < utf8.RuneSelf {
+		return r > ' ' && r != 0x7f && !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}
+	return unicode.IsPunct(r)
+}
@@ -0,0 +1,175 @@
+package transformers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
+	"github.com/code-sleuth/ike-go/internal/manager/models"
+	"github.com/code-sleuth/ike-go/pkg/dialect"
+	"github.com/code-sleuth/ike-go/pkg/stmtcache"
+	"github.com/code-sleuth/ike-go/pkg/util"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+var (
+	ErrCannotTransformDumpDownload = errors.New("cannot transform this download, it has no body")
+	ErrEmptyDumpRecord             = errors.New("dump record has no body")
+)
+
+// DumpTransformer turns records imported from JSONL or CSV dumps into documents. The content
+// was extracted by whatever tool produced the dump, so the record's body is used as is.
+type DumpTransformer struct {
+	dialect dialect.Dialect
+	logger  zerolog.Logger
+}
+
+// NewDumpTransformer creates a new dump transformer.
+func NewDumpTransformer() *DumpTransformer {
+	return &DumpTransformer{
+		dialect: dialect.SQLite,
+		logger:  util.NewLogger(zerolog.ErrorLevel),
+	}
+}
+
+// GetSourceType returns the source type this transformer handles.
+func (d *DumpTransformer) GetSourceType() string {
+	return models.DumpSourceFormat
+}
+
+// SetDialect sets the SQL dialect used for the transformer's queries.
+func (d *DumpTransformer) SetDialect(dl dialect.Dialect) {
+	d.dialect = dl
+}
+
+// MatchesSource reports whether source was imported from a dump.
+func (d *DumpTransformer) MatchesSource(source *models.Source) bool {
+	return source.Format != nil && *source.Format == models.DumpSourceFormat
+}
+
+// CanTransform checks if this transformer can handle the given download.
+func (d *DumpTransformer) CanTransform(download *models.Download) bool {
+	return download.Body != nil
+}
+
+// Transform converts a dump record's download into a document.
+func (d *DumpTransformer) Transform(
+	ctx context.Context,
+	download *models.Download,
+	db *sql.DB,
+) (*interfaces.TransformResult, error) {
+	if !d.CanTransform(download) {
+		return nil, ErrCannotTransformDumpDownload
+	}
+
+	var record models.DumpRecord
+	if err := json.Unmarshal([]byte(*download.Body), &record); err != nil {
+		d.logger.Error().Err(err).Str("download_id", download.ID).Msg("failed to parse dump record")
+		return nil, err
+	}
+	content := strings.TrimSpace(record.Body)
+	if content == "" {
+		return nil, ErrEmptyDumpRecord
+	}
+
+	const (
+		minChunkSize = 212
+		maxChunkSize = 8191 // Default for OpenAI embeddings
+	)
+	now := time.Now()
+	document := &models.Document{
+		ID:           uuid.New().String(),
+		SourceID:     download.SourceID,
+		DownloadID:   download.ID,
+		Format:       stringPtr(models.DumpSourceFormat),
+		IndexedAt:    &now,
+		MinChunkSize: minChunkSize,
+		MaxChunkSize: maxChunkSize,
+		PublishedAt:  record.PublishedAt,
+	}
+
+	metadata := make(map[string]interface{})
+	if record.Title != "" {
+		metadata["document_title"] = record.Title
+	}
+
+	if err := d.saveDocument(ctx, document, db); err != nil {
+		d.logger.Error().Err(err).Msg("failed to save document")
+		return nil, err
+	}
+	if err := d.saveMetadata(ctx, document.ID, metadata, db); err != nil {
+		d.logger.Error().Err(err).Msg("failed to save metadata")
+		return nil, err
+	}
+
+	return &interfaces.TransformResult{
+		Document: document,
+		Content:  content,
+		Metadata: metadata,
+	}, nil
+}
+
+// saveDocument saves the document to the database.
+func (d *DumpTransformer) saveDocument(ctx context.Context, document *models.Document, db *sql.DB) error {
+	query := `INSERT INTO documents (id, source_id, download_id, format, indexed_at, min_chunk_size,
+                       max_chunk_size, published_at, modified_at, wp_version)
+			  VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	var indexedAtStr, publishedAtStr *string
+
+	if document.IndexedAt != nil {
+		str := d.dialect.FormatTime(*document.IndexedAt)
+		indexedAtStr = &str
+	}
+	if document.PublishedAt != nil {
+		str := d.dialect.FormatTime(*document.PublishedAt)
+		publishedAtStr = &str
+	}
+
+	_, err := db.ExecContext(ctx, d.dialect.Rebind(query),
+		document.ID, document.SourceID, document.DownloadID, document.Format, indexedAtStr,
+		document.MinChunkSize, document.MaxChunkSize, publishedAtStr, nil, nil)
+
+	return err
+}
+
+// saveMetadata saves the metadata to the database.
+func (d *DumpTransformer) saveMetadata(
+	ctx context.Context,
+	documentID string,
+	metadata map[string]interface{},
+	db *sql.DB,
+) error {
+	for key, value := range metadata {
+		// Store strings as is and anything else as JSON
+		metaValue, ok := value.(string)
+		if !ok {
+			metaJSON, err := json.Marshal(value)
+			if err != nil {
+				d.logger.Error().Err(err).Msgf("failed to marshal metadata for key %s: %v", key, value)
+				continue
+			}
+			metaValue = string(metaJSON)
+		}
+
+		query := d.dialect.Upsert("document_meta",
+			[]string{"id", "document_id", "key", "meta", "created_at"},
+			[]string{"document_id", "key"},
+			[]string{"meta", "created_at"})
+
+		_, err := stmtcache.Exec(ctx, db, d.dialect.Rebind(query), uuid.New().String(), documentID, key,
+			metaValue, d.dialect.FormatTime(time.Now()))
+		if err != nil {
+			d.logger.Error().Err(err).Msgf("failed to save metadata for key %s: %v", key, value)
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,45 @@
+package transformers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/code-sleuth/ike-go/internal/manager/models"
+)
+
+func TestDumpTransformer_MatchesSource(t *testing.T) {
+	transformer := NewDumpTransformer()
+	if transformer.GetSourceType() != "dump" {
+		t.Errorf("Expected source type 'dump', got %s", transformer.GetSourceType())
+	}
+
+	dump, json := models.DumpSourceFormat, "json"
+	if !transformer.MatchesSource(&models.Source{Format: &dump}) {
+		t.Error("Expected a dump source to match")
+	}
+	if transformer.MatchesSource(&models.Source{Format: &json}) || transformer.MatchesSource(&models.Source{}) {
+		t.Error("Expected other sources not to match")
+	}
+}
+
+func TestDumpTransformer_Transform_InvalidRecord(t *testing.T) {
+	transformer := NewDumpTransformer()
+
+	tests := []struct {
+		name        string
+		body        *string
+		expectedErr error
+	}{
+		{name: "no body", expectedErr: ErrCannotTransformDumpDownload},
+		{name: "empty record", body: stringPtr(`{"title": "Empty", "body": "  "}`), expectedErr: ErrEmptyDumpRecord},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := transformer.Transform(context.Background(), &models.Download{ID: "d1", Body: tt.body}, nil)
+			if !errors.Is(err, tt.expectedErr) {
+				t.Errorf("Expected %v, got %v", tt.expectedErr, err)
+			}
+		})
+	}
+}
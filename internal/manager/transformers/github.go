@@ -3,16 +3,16 @@ package transformers
 import (
 	"context"
 	"database/sql"
-	"encoding/base64"
 	"encoding/json"
 	"errors"
-	"fmt"
 	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
 	"github.com/code-sleuth/ike-go/internal/manager/models"
+	"github.com/code-sleuth/ike-go/pkg/dialect"
+	"github.com/code-sleuth/ike-go/pkg/stmtcache"
 	"github.com/code-sleuth/ike-go/pkg/util"
 	"github.com/rs/zerolog"
 
@@ -26,21 +26,26 @@ const (
 	extYML  = ".yml"
 )
 
+// gitHubLicenseHeader holds the SPDX identifier of the repository's license in the headers the
+// GitHub importer records with each download.
+const gitHubLicenseHeader = "X-GitHub-License"
+
 var ErrCannotTransformDownload = errors.New("cannot transform this download, its not a valid GitHub file")
 
 // GitHubTransformer handles transforming GitHub file downloads into documents.
 type GitHubTransformer struct {
 	markdownConverter *md.Converter
+	dialect           dialect.Dialect
 	logger            zerolog.Logger
 }
 
 // NewGitHubTransformer creates a new GitHub transformer.
 func NewGitHubTransformer() *GitHubTransformer {
-	converter := md.NewConverter("", true, nil)
 	logger := util.NewLogger(zerolog.ErrorLevel)
 
 	return &GitHubTransformer{
-		markdownConverter: converter,
+		markdownConverter: sharedConverter(),
+		dialect:           dialect.SQLite,
 		logger:            logger,
 	}
 }
@@ -50,6 +55,11 @@ func (g *GitHubTransformer) GetSourceType() string {
 	return "github"
 }
 
+// SetDialect sets the SQL dialect used for the transformer's queries.
+func (g *GitHubTransformer) SetDialect(d dialect.Dialect) {
+	g.dialect = d
+}
+
 // CanTransform checks if this transformer can handle the given download.
 func (g *GitHubTransformer) CanTransform(download *models.Download) bool {
 	if download.Body == nil {
@@ -103,6 +113,9 @@ func (g *GitHubTransformer) Transform(
 
 	// Extract metadata
 	metadata := g.extractMetadata(source, filePath, content)
+	if license := g.fileLicense(download); license != "" {
+		metadata["license"] = license
+	}
 
 	// Save document to database
 	if err := g.saveDocument(ctx, document, db); err != nil {
@@ -159,17 +172,7 @@ func (g *GitHubTransformer) extractFilePath(rawURL *string) string {
 func (g *GitHubTransformer) processContent(body, filePath string) string {
 	ext := filepath.Ext(filePath)
 
-	// Handle base64 encoded content (GitHub API returns base64 for binary files)
-	if strings.Contains(body, "base64") {
-		decoded, err := base64.StdEncoding.DecodeString(body)
-		if err != nil {
-			g.logger.Error().Err(err).Msgf("failed to decode base64 content for download: %s", body)
-			// If decoding fails, treat as plain text
-			return body
-		}
-		body = string(decoded)
-	}
-
+	// The importer stores file content already decoded from the contents API's base64
 	switch ext {
 	case ".md":
 		// Markdown files are already in the right format
@@ -200,59 +203,56 @@ func (g *GitHubTransformer) processContent(body, filePath string) string {
 		".cfg",
 		".conf":
 		// Code and text files - add code fences for syntax highlighting
-		language := g.getLanguageFromExtension(ext)
-		if language != "" {
-			return fmt.Sprintf("```%s\n%s\n```", language, body)
-		}
-		return fmt.Sprintf("```\n%s\n```", body)
+		return fenceCode(g.getLanguageFromExtension(ext), body)
 	default:
 		// Unknown file type, treat as plain text
 		return body
 	}
 }
 
+// codeLanguages maps file extensions to the language identifiers code fences are tagged with.
+var codeLanguages = map[string]string{
+	".py":    "python",
+	".js":    "javascript",
+	".ts":    "typescript",
+	".go":    "go",
+	".java":  "java",
+	".cpp":   "cpp",
+	".c":     "c",
+	".h":     "c",
+	".hpp":   "cpp",
+	".css":   "css",
+	".html":  "html",
+	".htm":   "html",
+	".xml":   "xml",
+	extJSON:  "json",
+	extYAML:  "yaml",
+	extYML:   "yaml",
+	".toml":  "toml",
+	".ini":   "ini",
+	".cfg":   "ini",
+	".conf":  "ini",
+	".sh":    "bash",
+	".bash":  "bash",
+	".zsh":   "zsh",
+	".fish":  "fish",
+	".ps1":   "powershell",
+	".sql":   "sql",
+	".r":     "r",
+	".rb":    "ruby",
+	".php":   "php",
+	".swift": "swift",
+	".kt":    "kotlin",
+	".scala": "scala",
+	".rs":    "rust",
+	".dart":  "dart",
+	".lua":   "lua",
+	".pl":    "perl",
+}
+
 // getLanguageFromExtension returns the language identifier for syntax highlighting.
 func (g *GitHubTransformer) getLanguageFromExtension(ext string) string {
-	languageMap := map[string]string{
-		".py":    "python",
-		".js":    "javascript",
-		".ts":    "typescript",
-		".go":    "go",
-		".java":  "java",
-		".cpp":   "cpp",
-		".c":     "c",
-		".h":     "c",
-		".hpp":   "cpp",
-		".css":   "css",
-		".html":  "html",
-		".htm":   "html",
-		".xml":   "xml",
-		extJSON:  "json",
-		extYAML:  "yaml",
-		extYML:   "yaml",
-		".toml":  "toml",
-		".ini":   "ini",
-		".cfg":   "ini",
-		".conf":  "ini",
-		".sh":    "bash",
-		".bash":  "bash",
-		".zsh":   "zsh",
-		".fish":  "fish",
-		".ps1":   "powershell",
-		".sql":   "sql",
-		".r":     "r",
-		".rb":    "ruby",
-		".php":   "php",
-		".swift": "swift",
-		".kt":    "kotlin",
-		".scala": "scala",
-		".rs":    "rust",
-		".dart":  "dart",
-		".lua":   "lua",
-		".pl":    "perl",
-	}
-
-	return languageMap[ext]
+	return codeLanguages[ext]
 }
 
 // createDocument creates a document record.
@@ -442,6 +442,22 @@ func (g *GitHubTransformer) extractMetadata(source *models.Source, filePath, con
 	return metadata
 }
 
+// fileLicense returns the license of a downloaded file: the SPDX expression in its header, else
+// the license of the repository it was imported from.
+func (g *GitHubTransformer) fileLicense(download *models.Download) string {
+	if license := spdxLicense(*download.Body); license != "" {
+		return license
+	}
+	var headers map[string][]string
+	if err := json.Unmarshal([]byte(download.Headers), &headers); err != nil {
+		return ""
+	}
+	if license := headers[gitHubLicenseHeader]; len(license) > 0 {
+		return license[0]
+	}
+	return ""
+}
+
 // extractRepoInfo extracts repository information from GitHub URL.
 func (g *GitHubTransformer) extractRepoInfo(rawURL string) map[string]string {
 	parts := strings.Split(rawURL, "/")
@@ -484,7 +500,7 @@ func (g *GitHubTransformer) getSource(ctx context.Context, sourceID string, db *
 			 format, created_at, updated_at 
 			 FROM sources WHERE id = ?`
 
-	row := db.QueryRowContext(ctx, query, sourceID)
+	row := db.QueryRowContext(ctx, g.dialect.Rebind(query), sourceID)
 
 	var source models.Source
 	var authorEmail, rawURL, scheme, host, path, queryParam, format sql.NullString
@@ -521,10 +537,10 @@ func (g *GitHubTransformer) getSource(ctx context.Context, sourceID string, db *
 	}
 
 	// Parse timestamps
-	if createdAt, err := time.Parse(time.RFC3339, createdAtStr); err == nil {
+	if createdAt, err := dialect.ParseTime(createdAtStr); err == nil {
 		source.CreatedAt = createdAt
 	}
-	if updatedAt, err := time.Parse(time.RFC3339, updatedAtStr); err == nil {
+	if updatedAt, err := dialect.ParseTime(updatedAtStr); err == nil {
 		source.UpdatedAt = updatedAt
 	}
 
@@ -540,21 +556,21 @@ func (g *GitHubTransformer) saveDocument(ctx context.Context, document *models.D
 	var indexedAtStr, publishedAtStr, modifiedAtStr *string
 
 	if document.IndexedAt != nil {
-		str := document.IndexedAt.Format(time.RFC3339)
+		str := g.dialect.FormatTime(*document.IndexedAt)
 		indexedAtStr = &str
 	}
 	if document.PublishedAt != nil {
-		str := document.PublishedAt.Format(time.RFC3339)
+		str := g.dialect.FormatTime(*document.PublishedAt)
 		publishedAtStr = &str
 	}
 	if document.ModifiedAt != nil {
-		str := document.ModifiedAt.Format(time.RFC3339)
+		str := g.dialect.FormatTime(*document.ModifiedAt)
 		modifiedAtStr = &str
 	}
 
-	_, err := db.ExecContext(ctx, query, document.ID, document.SourceID, document.DownloadID,
-		document.Format, indexedAtStr, document.MinChunkSize, document.MaxChunkSize,
-		publishedAtStr, modifiedAtStr, document.WPVersion)
+	_, err := db.ExecContext(ctx, g.dialect.Rebind(query),
+		document.ID, document.SourceID, document.DownloadID, document.Format, indexedAtStr,
+		document.MinChunkSize, document.MaxChunkSize, publishedAtStr, modifiedAtStr, document.WPVersion)
 	if err != nil {
 		g.logger.Error().Err(err).Msgf("failed to save document for ID: %s", document.ID)
 	}
@@ -576,14 +592,13 @@ func (g *GitHubTransformer) saveMetadata(
 			continue
 		}
 
-		query := `INSERT INTO document_meta (id, document_id, key, meta, created_at)
-				  VALUES (?, ?, ?, ?, ?)
-				  ON CONFLICT(document_id, key) DO UPDATE SET
-				  	meta = excluded.meta,
-				  	created_at = excluded.created_at`
+		query := g.dialect.Upsert("document_meta",
+			[]string{"id", "document_id", "key", "meta", "created_at"},
+			[]string{"document_id", "key"},
+			[]string{"meta", "created_at"})
 
-		_, err = db.ExecContext(ctx, query, uuid.New().String(), documentID, key,
-			string(metaJSON), time.Now().Format(time.RFC3339))
+		_, err = stmtcache.Exec(ctx, db, g.dialect.Rebind(query), uuid.New().String(), documentID, key,
+			string(metaJSON), g.dialect.FormatTime(time.Now()))
 		if err != nil {
 			g.logger.Error().Err(err).Msgf("failed to save metadata for key %s: %v", key, err)
 			return err
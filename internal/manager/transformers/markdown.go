@@ -0,0 +1,54 @@
+package transformers
+
+import (
+	"bytes"
+	"sync"
+
+	md "github.com/JohannesKaufmann/html-to-markdown"
+)
+
+// maxPooledBuffer is the largest buffer, in bytes, returned to bufferPool. Bigger ones are left to
+// the garbage collector, so one huge file doesn't keep its memory pinned for the rest of an import.
+const maxPooledBuffer = 1 << 20
+
+// sharedConverter returns the HTML-to-markdown converter every transformer uses. Building one
+// compiles and registers its whole rule set, and a Converter is safe for concurrent use, so one is
+// built for the process rather than per transformer.
+var sharedConverter = sync.OnceValue(func() *md.Converter {
+	return md.NewConverter("", true, nil)
+})
+
+// bufferPool holds the buffers documents are assembled in between conversions.
+var bufferPool = sync.Pool{
+	New: func() any { return new(bytes.Buffer) },
+}
+
+// getBuffer returns an empty buffer from bufferPool.
+func getBuffer() *bytes.Buffer {
+	buf, _ := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	return buf
+}
+
+// putBuffer returns buf to bufferPool unless it has grown past maxPooledBuffer.
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBuffer {
+		return
+	}
+	bufferPool.Put(buf)
+}
+
+// fenceCode wraps body in a markdown code fence tagged with language, when it is known. The fence is
+// assembled in a pooled buffer, so the only allocation is the returned string.
+func fenceCode(language, body string) string {
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	buf.Grow(len(language) + len(body) + len("```\n\n```"))
+	buf.WriteString("```")
+	buf.WriteString(language)
+	buf.WriteByte('\n')
+	buf.WriteString(body)
+	buf.WriteString("\n```")
+	return buf.String()
+}
@@ -0,0 +1,62 @@
+package transformers
+
+import (
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestFenceCode(t *testing.T) {
+	if got := fenceCode("go", "package main"); got != "```go\npackage main\n```" {
+		t.Errorf("Expected a go fence, got %q", got)
+	}
+	if got := fenceCode("", "plain"); got != "```\nplain\n```" {
+		t.Errorf("Expected an untagged fence, got %q", got)
+	}
+}
+
+func TestPutBuffer_DropsLargeBuffers(t *testing.T) {
+	buf := getBuffer()
+	buf.Grow(maxPooledBuffer + 1)
+	putBuffer(buf)
+
+	// sync.Pool may drop buffers at any time, so only check that a large one never comes back
+	for range 10 {
+		got := getBuffer()
+		if got.Cap() > maxPooledBuffer {
+			t.Fatalf("Expected buffers over %d bytes not to be pooled, got one of %d", maxPooledBuffer, got.Cap())
+		}
+		if got.Len() != 0 {
+			t.Fatalf("Expected pooled buffers to be empty, got %q", got.String())
+		}
+		putBuffer(got)
+	}
+}
+
+func TestSharedConverter(t *testing.T) {
+	if NewWPJSONTransformer().markdownConverter != NewGitHubTransformer().markdownConverter {
+		t.Error("Expected transformers to share one converter")
+	}
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			markdown, err := sharedConverter().ConvertString("<p>Post <strong>" + strings.Repeat("x", i+1) + "</strong></p>")
+			if err != nil || markdown != "Post **"+strings.Repeat("x", i+1)+"**" {
+				t.Errorf("Unexpected conversion: %q, %v", markdown, err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func BenchmarkFenceCode(b *testing.B) {
+	body := strings.Repeat("fmt.Println(\"Hello, World!\")\n", 1000)
+
+	b.ReportAllocs()
+	for b.Loop() {
+		fenceCode("go", body)
+	}
+}
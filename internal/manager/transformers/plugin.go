@@ -0,0 +1,203 @@
+package transformers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
+	"github.com/code-sleuth/ike-go/internal/manager/models"
+	"github.com/code-sleuth/ike-go/internal/manager/plugins"
+	"github.com/code-sleuth/ike-go/pkg/dialect"
+	"github.com/code-sleuth/ike-go/pkg/stmtcache"
+	"github.com/code-sleuth/ike-go/pkg/util"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+var (
+	ErrCannotTransformPluginDownload = errors.New("cannot transform this download, it has no body")
+	ErrEmptyPluginContent            = errors.New("plugin returned no content")
+)
+
+// PluginTransformer turns downloads of a plugin's source type into documents. When the plugin
+// declares a transformer it produces the content; otherwise the download body is used as is.
+type PluginTransformer struct {
+	plugin  *plugins.Plugin
+	dialect dialect.Dialect
+	logger  zerolog.Logger
+}
+
+// NewPluginTransformer creates a transformer backed by plugin.
+func NewPluginTransformer(plugin *plugins.Plugin) *PluginTransformer {
+	return &PluginTransformer{
+		plugin:  plugin,
+		dialect: dialect.SQLite,
+		logger:  util.NewLogger(zerolog.ErrorLevel),
+	}
+}
+
+// GetSourceType returns the source type declared by the plugin.
+func (p *PluginTransformer) GetSourceType() string {
+	return p.plugin.Manifest.SourceType
+}
+
+// SetDialect sets the SQL dialect used for the transformer's queries.
+func (p *PluginTransformer) SetDialect(d dialect.Dialect) {
+	p.dialect = d
+}
+
+// MatchesSource reports whether source was imported from a URL the plugin handles.
+func (p *PluginTransformer) MatchesSource(source *models.Source) bool {
+	return source.RawURL != nil && p.plugin.Matches(*source.RawURL)
+}
+
+// CanTransform checks if this transformer can handle the given download.
+func (p *PluginTransformer) CanTransform(download *models.Download) bool {
+	return download.Body != nil
+}
+
+// Transform converts a download into a document, asking the plugin for its content.
+func (p *PluginTransformer) Transform(
+	ctx context.Context,
+	download *models.Download,
+	db *sql.DB,
+) (*interfaces.TransformResult, error) {
+	if !p.CanTransform(download) {
+		return nil, ErrCannotTransformPluginDownload
+	}
+
+	var rawURL, format sql.NullString
+	err := db.QueryRowContext(ctx, p.dialect.Rebind(`SELECT raw_url, format FROM sources WHERE id = ?`),
+		download.SourceID).Scan(&rawURL, &format)
+	if err != nil {
+		p.logger.Error().Err(err).Str("source_id", download.SourceID).Msg("failed to get source")
+		return nil, err
+	}
+
+	transformed := &plugins.TransformResult{Content: *download.Body}
+	if p.plugin.Manifest.Transformer {
+		transformed, err = p.plugin.Transform(ctx, plugins.TransformParams{
+			URL:    rawURL.String,
+			Body:   *download.Body,
+			Format: format.String,
+		})
+		if err != nil {
+			p.logger.Error().Err(err).Str("download_id", download.ID).Msg("plugin transform failed")
+			return nil, err
+		}
+	}
+	if transformed.Content == "" {
+		return nil, ErrEmptyPluginContent
+	}
+
+	const (
+		minChunkSize = 212
+		maxChunkSize = 8191 // Default for OpenAI embeddings
+	)
+	now := time.Now()
+	document := &models.Document{
+		ID:           uuid.New().String(),
+		SourceID:     download.SourceID,
+		DownloadID:   download.ID,
+		Format:       stringPtr(format.String),
+		IndexedAt:    &now,
+		MinChunkSize: minChunkSize,
+		MaxChunkSize: maxChunkSize,
+		PublishedAt:  transformed.PublishedAt,
+		ModifiedAt:   transformed.ModifiedAt,
+	}
+
+	metadata := transformed.Metadata
+	if metadata == nil {
+		metadata = make(map[string]interface{})
+	}
+	metadata["plugin"] = p.plugin.Manifest.Name
+	if _, exists := metadata["canonical_url"]; !exists && format.String == "html" {
+		if canonical := canonicalURL(*download.Body, rawURL.String); canonical != "" {
+			metadata["canonical_url"] = canonical
+		}
+	}
+
+	if err := p.saveDocument(ctx, document, db); err != nil {
+		p.logger.Error().Err(err).Msg("failed to save document")
+		return nil, err
+	}
+	if err := p.saveMetadata(ctx, document.ID, metadata, db); err != nil {
+		p.logger.Error().Err(err).Msg("failed to save metadata")
+		return nil, err
+	}
+
+	return &interfaces.TransformResult{
+		Document: document,
+		Content:  transformed.Content,
+		Language: transformed.Language,
+		Metadata: metadata,
+	}, nil
+}
+
+// saveDocument saves the document to the database.
+func (p *PluginTransformer) saveDocument(ctx context.Context, document *models.Document, db *sql.DB) error {
+	query := `INSERT INTO documents (id, source_id, download_id, format, indexed_at, min_chunk_size,
+                       max_chunk_size, published_at, modified_at, wp_version)
+			  VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	var indexedAtStr, publishedAtStr, modifiedAtStr *string
+
+	if document.IndexedAt != nil {
+		str := p.dialect.FormatTime(*document.IndexedAt)
+		indexedAtStr = &str
+	}
+	if document.PublishedAt != nil {
+		str := p.dialect.FormatTime(*document.PublishedAt)
+		publishedAtStr = &str
+	}
+	if document.ModifiedAt != nil {
+		str := p.dialect.FormatTime(*document.ModifiedAt)
+		modifiedAtStr = &str
+	}
+
+	_, err := db.ExecContext(ctx, p.dialect.Rebind(query),
+		document.ID, document.SourceID, document.DownloadID, document.Format, indexedAtStr,
+		document.MinChunkSize, document.MaxChunkSize, publishedAtStr, modifiedAtStr, document.WPVersion)
+
+	return err
+}
+
+// saveMetadata saves the metadata to the database.
+func (p *PluginTransformer) saveMetadata(
+	ctx context.Context,
+	documentID string,
+	metadata map[string]interface{},
+	db *sql.DB,
+) error {
+	for key, value := range metadata {
+		// Store strings as is and anything else as JSON
+		metaValue, ok := value.(string)
+		if !ok {
+			metaJSON, err := json.Marshal(value)
+			if err != nil {
+				p.logger.Error().Err(err).Msgf("failed to marshal metadata for key %s: %v", key, value)
+				continue
+			}
+			metaValue = string(metaJSON)
+		}
+
+		query := p.dialect.Upsert("document_meta",
+			[]string{"id", "document_id", "key", "meta", "created_at"},
+			[]string{"document_id", "key"},
+			[]string{"meta", "created_at"})
+
+		_, err := stmtcache.Exec(ctx, db, p.dialect.Rebind(query), uuid.New().String(), documentID, key,
+			metaValue, p.dialect.FormatTime(time.Now()))
+		if err != nil {
+			p.logger.Error().Err(err).Msgf("failed to save metadata for key %s: %v", key, value)
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,114 @@
+package transformers
+
+import (
+	"bufio"
+	"net/url"
+	"strings"
+
+	"github.com/code-sleuth/ike-go/pkg/util"
+
+	"golang.org/x/net/html"
+)
+
+const (
+	// spdxTag introduces a file's SPDX license expression, such as "Apache-2.0 OR MIT".
+	spdxTag = "SPDX-License-Identifier:"
+	// spdxHeaderLines bounds how far into a file its SPDX tag is looked for, so documentation that
+	// merely mentions the tag isn't taken for a license.
+	spdxHeaderLines = 20
+)
+
+// spdxLicense returns the SPDX license expression declared in content's header, or "" when it has
+// none.
+func spdxLicense(content string) string {
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for line := 0; line < spdxHeaderLines && scanner.Scan(); line++ {
+		_, expression, found := strings.Cut(scanner.Text(), spdxTag)
+		if !found {
+			continue
+		}
+		// Tags sit in comments, whose closing marker may end the line
+		expression = strings.TrimSpace(expression)
+		for _, closer := range []string{"*/", "-->", "#}", "--%>"} {
+			expression = strings.TrimSpace(strings.TrimSuffix(expression, closer))
+		}
+		return expression
+	}
+	return ""
+}
+
+// canonicalURL returns the URL an HTML page declares canonical with <link rel="canonical"> or,
+// failing that, <meta property="og:url">, resolved against pageURL and normalized like source
+// URLs, so syndicated copies of a page share it. It returns "" when the page declares neither.
+func canonicalURL(page, pageURL string) string {
+	var canonical, ogURL string
+	tokenizer := html.NewTokenizer(strings.NewReader(page))
+	for canonical == "" {
+		tokenType := tokenizer.Next()
+		if tokenType == html.ErrorToken {
+			break
+		}
+		if tokenType != html.StartTagToken && tokenType != html.SelfClosingTagToken {
+			continue
+		}
+		token := tokenizer.Token()
+		switch token.Data {
+		case "body":
+			// Only the head declares the page's canonical URL
+			return resolveCanonical(ogURL, pageURL)
+		case "link":
+			if hasToken(attr(token, "rel"), "canonical") {
+				canonical = attr(token, "href")
+			}
+		case "meta":
+			if ogURL == "" && strings.EqualFold(attr(token, "property"), "og:url") {
+				ogURL = attr(token, "content")
+			}
+		}
+	}
+	if canonical != "" {
+		return resolveCanonical(canonical, pageURL)
+	}
+	return resolveCanonical(ogURL, pageURL)
+}
+
+// resolveCanonical resolves ref against pageURL and normalizes it, returning "" for an empty or
+// invalid ref.
+func resolveCanonical(ref, pageURL string) string {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return ""
+	}
+	resolved, err := url.Parse(ref)
+	if err != nil {
+		return ""
+	}
+	if base, err := url.Parse(pageURL); err == nil && pageURL != "" {
+		resolved = base.ResolveReference(resolved)
+	}
+	normalized, err := util.NormalizeURL(resolved.String())
+	if err != nil {
+		return ""
+	}
+	return normalized
+}
+
+// attr returns the value of token's attribute name, or "" when it has none.
+func attr(token html.Token, name string) string {
+	for _, a := range token.Attr {
+		if strings.EqualFold(a.Key, name) {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// hasToken reports whether the space-separated list, such as a rel attribute, contains value.
+func hasToken(list, value string) bool {
+	for _, field := range strings.Fields(list) {
+		if strings.EqualFold(field, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,121 @@
+package transformers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/code-sleuth/ike-go/internal/manager/models"
+)
+
+func TestSPDXLicense(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{name: "line comment", content: "// SPDX-License-Identifier: MIT\npackage main", expected: "MIT"},
+		{name: "block comment", content: "/* SPDX-License-Identifier: Apache-2.0 OR MIT */\nint x;",
+			expected: "Apache-2.0 OR MIT"},
+		{name: "html comment", content: "<!-- SPDX-License-Identifier: CC-BY-4.0 -->\n# Guide",
+			expected: "CC-BY-4.0"},
+		{name: "hash comment", content: "#!/bin/sh\n# SPDX-License-Identifier: GPL-2.0-only\necho hi",
+			expected: "GPL-2.0-only"},
+		{name: "none", content: "package main\n\nfunc main() {}"},
+		{name: "past the header",
+			content: strings.Repeat("line\n", spdxHeaderLines) + "// SPDX-License-Identifier: MIT\n"},
+	}
+	for _, tt := range tests {
+		if got := spdxLicense(tt.content); got != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestCanonicalURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		page     string
+		expected string
+	}{
+		{
+			name: "rel canonical",
+			page: `<html><head><meta property="og:url" content="https://og.example.com/a">` +
+				`<link rel="canonical" href="https://Example.com/original/?utm_source=feed"></head></html>`,
+			expected: "https://example.com/original",
+		},
+		{
+			name:     "og:url fallback",
+			page:     `<head><meta property="og:url" content="https://example.com/post"></head>`,
+			expected: "https://example.com/post",
+		},
+		{
+			name:     "relative href",
+			page:     `<head><link rel="alternate canonical" href="/posts/1"></head>`,
+			expected: "https://mirror.example.org/posts/1",
+		},
+		{
+			name: "link in body ignored",
+			page: `<head><title>Copy</title></head><body>` +
+				`<link rel="canonical" href="https://example.com/elsewhere"></body>`,
+		},
+		{name: "none", page: `<p>No head at all</p>`},
+	}
+	for _, tt := range tests {
+		if got := canonicalURL(tt.page, "https://mirror.example.org/copy/1"); got != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestSEOCanonicalURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		wpData   map[string]interface{}
+		expected string
+	}{
+		{
+			name: "yoast_head_json",
+			wpData: map[string]interface{}{
+				"link":            "https://partner.example.com/reposted",
+				"yoast_head_json": map[string]interface{}{"canonical": "https://example.com/original"},
+			},
+			expected: "https://example.com/original",
+		},
+		{
+			name: "yoast_head markup",
+			wpData: map[string]interface{}{
+				"yoast_head": `<link rel="canonical" href="https://example.com/original/" />`,
+			},
+			expected: "https://example.com/original",
+		},
+		{name: "no SEO plugin", wpData: map[string]interface{}{"link": "https://example.com/post"}},
+	}
+	for _, tt := range tests {
+		if got := seoCanonicalURL(tt.wpData); got != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestGitHubTransformer_FileLicense(t *testing.T) {
+	transformer := NewGitHubTransformer()
+	headers := `{"X-GitHub-SHA": ["abc123"], "X-GitHub-License": ["Apache-2.0"]}`
+
+	tests := []struct {
+		name     string
+		download *models.Download
+		expected string
+	}{
+		{name: "repository license", expected: "Apache-2.0",
+			download: &models.Download{Headers: headers, Body: stringPtr("package main")}},
+		{name: "file header wins", expected: "MIT",
+			download: &models.Download{Headers: headers, Body: stringPtr("// SPDX-License-Identifier: MIT\n")}},
+		{name: "none", download: &models.Download{Headers: `{"X-GitHub-SHA": ["abc123"]}`,
+			Body: stringPtr("package main")}},
+	}
+	for _, tt := range tests {
+		if got := transformer.fileLicense(tt.download); got != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.expected, got)
+		}
+	}
+}
@@ -5,12 +5,15 @@ import (
 	"database/sql"
 	"encoding/json"
 	"errors"
+	"html"
 	"regexp"
 	"strings"
 	"time"
 
 	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
 	"github.com/code-sleuth/ike-go/internal/manager/models"
+	"github.com/code-sleuth/ike-go/pkg/dialect"
+	"github.com/code-sleuth/ike-go/pkg/stmtcache"
 	"github.com/code-sleuth/ike-go/pkg/util"
 	"github.com/rs/zerolog"
 
@@ -29,19 +32,17 @@ var (
 // WPJSONTransformer handles transforming WordPress JSON API downloads into documents.
 type WPJSONTransformer struct {
 	markdownConverter *md.Converter
+	dialect           dialect.Dialect
 	logger            zerolog.Logger
 }
 
 // NewWPJSONTransformer creates a new WordPress JSON transformer.
 func NewWPJSONTransformer() *WPJSONTransformer {
-	converter := md.NewConverter("", true, nil)
 	logger := util.NewLogger(zerolog.ErrorLevel)
 
-	// Configure the converter for better markdown output
-	// Use default configuration for now
-
 	return &WPJSONTransformer{
-		markdownConverter: converter,
+		markdownConverter: sharedConverter(),
+		dialect:           dialect.SQLite,
 		logger:            logger,
 	}
 }
@@ -51,6 +52,11 @@ func (w *WPJSONTransformer) GetSourceType() string {
 	return "wp-json"
 }
 
+// SetDialect sets the SQL dialect used for the transformer's queries.
+func (w *WPJSONTransformer) SetDialect(d dialect.Dialect) {
+	w.dialect = d
+}
+
 // CanTransform checks if this transformer can handle the given download.
 func (w *WPJSONTransformer) CanTransform(download *models.Download) bool {
 	if download.Body == nil {
@@ -196,7 +202,7 @@ func (w *WPJSONTransformer) extractDocument(
 
 	// Extract and parse dates
 	if dateGMT, exists := wpData["date_gmt"].(string); exists {
-		parsed, err := time.Parse("2006-01-02T15:04:05", dateGMT)
+		parsed, err := dialect.ParseTime(dateGMT)
 		if err != nil {
 			w.logger.Error().Err(err).Msgf("failed to parse date: %s", dateGMT)
 			return nil, err
@@ -205,7 +211,7 @@ func (w *WPJSONTransformer) extractDocument(
 	}
 
 	if modifiedGMT, exists := wpData["modified_gmt"].(string); exists {
-		parsed, err := time.Parse("2006-01-02T15:04:05", modifiedGMT)
+		parsed, err := dialect.ParseTime(modifiedGMT)
 		if err != nil {
 			w.logger.Error().Err(err).Msgf("failed to parse modified date: %s", modifiedGMT)
 			return nil, err
@@ -229,7 +235,7 @@ func (w *WPJSONTransformer) extractMetadata(wpData map[string]interface{}, conte
 		if titleMap, ok := titleObj.(map[string]interface{}); ok {
 			if rendered, exists := titleMap["rendered"].(string); exists {
 				// Store title as plain text (no need to convert HTML for simple titles)
-				metadata["document_title"] = strings.TrimSpace(rendered)
+				metadata["document_title"] = plainText(rendered)
 			}
 		}
 	}
@@ -249,8 +255,10 @@ func (w *WPJSONTransformer) extractMetadata(wpData map[string]interface{}, conte
 	// Count links in content
 	metadata["links_count"] = w.countLinks(content)
 
-	// Extract canonical URL
-	if link, exists := wpData["link"].(string); exists {
+	// Extract canonical URL, preferring the one an SEO plugin declares for syndicated posts
+	if canonical := seoCanonicalURL(wpData); canonical != "" {
+		metadata["canonical_url"] = canonical
+	} else if link, exists := wpData["link"].(string); exists {
 		metadata["canonical_url"] = link
 	}
 
@@ -288,9 +296,93 @@ func (w *WPJSONTransformer) extractMetadata(wpData map[string]interface{}, conte
 		metadata["tags"] = tags
 	}
 
+	// Names of linked resources, present when the post was imported with _embed
+	if embedded, exists := wpData["_embedded"].(map[string]interface{}); exists {
+		extractEmbedded(embedded, metadata)
+	}
+
 	return metadata
 }
 
+// seoCanonicalURL returns the canonical URL Yoast SEO declares for a post, from the
+// yoast_head_json it adds to REST responses or else the head markup in yoast_head, or "" when the
+// site doesn't run it.
+func seoCanonicalURL(wpData map[string]interface{}) string {
+	link, _ := wpData["link"].(string)
+	if head, ok := wpData["yoast_head_json"].(map[string]interface{}); ok {
+		for _, key := range []string{"canonical", "og_url"} {
+			if value, ok := head[key].(string); ok {
+				if canonical := resolveCanonical(value, link); canonical != "" {
+					return canonical
+				}
+			}
+		}
+	}
+	if head, ok := wpData["yoast_head"].(string); ok {
+		return canonicalURL(head, link)
+	}
+	return ""
+}
+
+// htmlTag matches the tags of inline markup WordPress allows in titles, such as <em>.
+var htmlTag = regexp.MustCompile(`<[^>]*>`)
+
+// plainText returns text WordPress renders as HTML, such as a title or term name, as plain text:
+// tags are dropped and entities decoded, so "June 2025 &#8211; Sale" becomes "June 2025 – Sale".
+func plainText(rendered string) string {
+	return strings.TrimSpace(html.UnescapeString(htmlTag.ReplaceAllString(rendered, "")))
+}
+
+// extractEmbedded extracts the author's name, the featured image's URL, and the names of the
+// categories and tags from a post's _embedded resources. Resources the site would not embed,
+// such as authors of private sites, come as error objects without a name and are left out.
+func extractEmbedded(embedded map[string]interface{}, metadata map[string]interface{}) {
+	if authors, exists := embedded["author"].([]interface{}); exists && len(authors) > 0 {
+		if author, ok := authors[0].(map[string]interface{}); ok {
+			if name, ok := author["name"].(string); ok && name != "" {
+				metadata["author_name"] = plainText(name)
+			}
+		}
+	}
+
+	if media, exists := embedded["wp:featuredmedia"].([]interface{}); exists && len(media) > 0 {
+		if image, ok := media[0].(map[string]interface{}); ok {
+			if sourceURL, ok := image["source_url"].(string); ok && sourceURL != "" {
+				metadata["featured_media_url"] = sourceURL
+			}
+			if alt, ok := image["alt_text"].(string); ok && alt != "" {
+				metadata["featured_media_alt"] = plainText(alt)
+			}
+		}
+	}
+
+	// wp:term holds one list of terms per taxonomy
+	var categories, tags []string
+	taxonomies, _ := embedded["wp:term"].([]interface{})
+	for _, taxonomy := range taxonomies {
+		terms, _ := taxonomy.([]interface{})
+		for _, term := range terms {
+			termMap, _ := term.(map[string]interface{})
+			name, _ := termMap["name"].(string)
+			if name == "" {
+				continue
+			}
+			switch termMap["taxonomy"] {
+			case "category":
+				categories = append(categories, plainText(name))
+			case "post_tag":
+				tags = append(tags, plainText(name))
+			}
+		}
+	}
+	if len(categories) > 0 {
+		metadata["category_names"] = categories
+	}
+	if len(tags) > 0 {
+		metadata["tag_names"] = tags
+	}
+}
+
 // detectLanguage attempts to detect the language of the content.
 func (w *WPJSONTransformer) detectLanguage(content string) string {
 	// Simple heuristic for now - could be enhanced with actual language detection
@@ -321,12 +413,12 @@ func (w *WPJSONTransformer) detectLanguage(content string) string {
 	return "en"
 }
 
+// markdownLink matches a markdown link: [text](url).
+var markdownLink = regexp.MustCompile(`\[([^\]]*)\]\([^\)]*\)`)
+
 // countLinks counts the number of markdown links in the content.
 func (w *WPJSONTransformer) countLinks(content string) int {
-	// Regex pattern for markdown links: [text](url)
-	linkPattern := regexp.MustCompile(`\[([^\]]*)\]\([^\)]*\)`)
-	matches := linkPattern.FindAllString(content, -1)
-	return len(matches)
+	return len(markdownLink.FindAllStringIndex(content, -1))
 }
 
 // saveDocument saves the document to the database.
@@ -338,21 +430,21 @@ func (w *WPJSONTransformer) saveDocument(ctx context.Context, document *models.D
 	var indexedAtStr, publishedAtStr, modifiedAtStr *string
 
 	if document.IndexedAt != nil {
-		str := document.IndexedAt.Format(time.RFC3339)
+		str := w.dialect.FormatTime(*document.IndexedAt)
 		indexedAtStr = &str
 	}
 	if document.PublishedAt != nil {
-		str := document.PublishedAt.Format(time.RFC3339)
+		str := w.dialect.FormatTime(*document.PublishedAt)
 		publishedAtStr = &str
 	}
 	if document.ModifiedAt != nil {
-		str := document.ModifiedAt.Format(time.RFC3339)
+		str := w.dialect.FormatTime(*document.ModifiedAt)
 		modifiedAtStr = &str
 	}
 
-	_, err := db.ExecContext(ctx, query, document.ID, document.SourceID, document.DownloadID,
-		document.Format, indexedAtStr, document.MinChunkSize, document.MaxChunkSize,
-		publishedAtStr, modifiedAtStr, document.WPVersion)
+	_, err := db.ExecContext(ctx, w.dialect.Rebind(query),
+		document.ID, document.SourceID, document.DownloadID, document.Format, indexedAtStr,
+		document.MinChunkSize, document.MaxChunkSize, publishedAtStr, modifiedAtStr, document.WPVersion)
 
 	return err
 }
@@ -380,14 +472,13 @@ func (w *WPJSONTransformer) saveMetadata(
 			metaValue = string(metaJSON)
 		}
 
-		query := `INSERT INTO document_meta (id, document_id, key, meta, created_at)
-				  VALUES (?, ?, ?, ?, ?)
-				  ON CONFLICT(document_id, key) DO UPDATE SET
-				  	meta = excluded.meta,
-				  	created_at = excluded.created_at`
+		query := w.dialect.Upsert("document_meta",
+			[]string{"id", "document_id", "key", "meta", "created_at"},
+			[]string{"document_id", "key"},
+			[]string{"meta", "created_at"})
 
-		_, err := db.ExecContext(ctx, query, uuid.New().String(), documentID, key,
-			metaValue, time.Now().Format(time.RFC3339))
+		_, err := stmtcache.Exec(ctx, db, w.dialect.Rebind(query), uuid.New().String(), documentID, key,
+			metaValue, w.dialect.FormatTime(time.Now()))
 		if err != nil {
 			w.logger.Error().Err(err).Msgf("failed to save metadata for key %s: %v", key, value)
 			return err
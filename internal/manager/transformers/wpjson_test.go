@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"database/sql/driver"
+	"reflect"
 	"testing"
 	"time"
 
@@ -445,6 +446,36 @@ func TestWPJSONTransformer_ExtractMetadata(t *testing.T) {
 			},
 			description: "should extract all available metadata",
 		},
+		{
+			name: "embedded resources",
+			wpData: map[string]interface{}{
+				"title": map[string]interface{}{"rendered": "Embedded"},
+				"_embedded": map[string]interface{}{
+					"author": []interface{}{map[string]interface{}{"id": float64(5), "name": "Jane Writer"}},
+					"wp:featuredmedia": []interface{}{map[string]interface{}{
+						"source_url": "https://example.com/uploads/hero.png", "alt_text": "A hero image",
+					}},
+					"wp:term": []interface{}{
+						[]interface{}{map[string]interface{}{"taxonomy": "category", "name": "Guides"}},
+						[]interface{}{
+							map[string]interface{}{"taxonomy": "post_tag", "name": "forms"},
+							map[string]interface{}{"taxonomy": "post_tag", "name": "spam"},
+						},
+					},
+				},
+			},
+			content: "Content without links.",
+			expected: map[string]interface{}{
+				"document_title":     "Embedded",
+				"links_count":        0,
+				"author_name":        "Jane Writer",
+				"featured_media_url": "https://example.com/uploads/hero.png",
+				"featured_media_alt": "A hero image",
+				"category_names":     []string{"Guides"},
+				"tag_names":          []string{"forms", "spam"},
+			},
+			description: "should extract names from embedded resources",
+		},
 		{
 			name: "minimal metadata",
 			wpData: map[string]interface{}{
@@ -534,6 +565,34 @@ func TestWPJSONTransformer_ExtractMetadata(t *testing.T) {
 	}
 }
 
+func TestExtractEmbedded(t *testing.T) {
+	metadata := make(map[string]interface{})
+	extractEmbedded(map[string]interface{}{
+		"author":           []interface{}{map[string]interface{}{"code": "rest_user_invalid_id"}},
+		"wp:featuredmedia": []interface{}{map[string]interface{}{"source_url": "https://example.com/a.png"}},
+		"wp:term":          []interface{}{[]interface{}{map[string]interface{}{"taxonomy": "genre", "name": "Jazz"}}},
+	}, metadata)
+
+	expected := map[string]interface{}{"featured_media_url": "https://example.com/a.png"}
+	if !reflect.DeepEqual(metadata, expected) {
+		t.Errorf("Expected %v, got %v", expected, metadata)
+	}
+}
+
+func TestPlainText(t *testing.T) {
+	tests := map[string]string{
+		"June 2025 &#8211; End of Month Sale": "June 2025 – End of Month Sale",
+		" Tips &amp; Tricks ":                 "Tips & Tricks",
+		"<em>Don&#8217;t</em> miss it":        "Don’t miss it",
+		"&lt;script&gt; tags":                 "<script> tags",
+	}
+	for rendered, expected := range tests {
+		if got := plainText(rendered); got != expected {
+			t.Errorf("plainText(%q) = %q, expected %q", rendered, got, expected)
+		}
+	}
+}
+
 func TestWPJSONTransformer_DetectLanguage(t *testing.T) {
 	transformer := NewWPJSONTransformer()
 
@@ -0,0 +1,142 @@
+// Package blobstore keeps large download bodies outside the database, in a directory or an
+// S3-compatible bucket, so multi-megabyte files don't bloat the database or its backups.
+// download_bodies rows of such bodies hold the blob's key instead of the body.
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/code-sleuth/ike-go/pkg/compression"
+)
+
+// DefaultMinSize is the smallest body, in bytes, written to the blob store when
+// IKE_BLOB_MIN_SIZE is not set.
+const DefaultMinSize = 1 << 20
+
+var (
+	ErrInvalidConfig = errors.New("invalid blob store configuration")
+	ErrNotConfigured = errors.New("body is kept in a blob store, but IKE_BLOB_STORE is not set")
+	ErrNotFound      = errors.New("blob not found")
+)
+
+// Store reads and writes blobs by key.
+type Store interface {
+	// Put stores the size bytes read from body under key, replacing any blob already there.
+	Put(ctx context.Context, key string, body io.Reader, size int64) error
+	// Get opens the blob stored under key, or returns ErrNotFound.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+}
+
+// Config is where bodies go. With no Store, every body is kept in the database.
+type Config struct {
+	Store Store
+	// MinSize is the smallest body, in bytes, written to Store; smaller ones stay in the database
+	MinSize int
+}
+
+// The configuration set with SetDefault.
+var (
+	defaultMu     sync.RWMutex
+	defaultConfig Config
+)
+
+// ConfigFromEnv reads IKE_BLOB_STORE and IKE_BLOB_MIN_SIZE (bytes, DefaultMinSize when unset).
+// IKE_BLOB_STORE is a directory, as a path or file:// URL, or an s3://bucket/prefix URL; S3
+// credentials come from AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, and AWS_SESSION_TOKEN, the
+// region from AWS_REGION, and IKE_BLOB_S3_ENDPOINT points at S3-compatible services such as
+// MinIO or R2.
+func ConfigFromEnv() (Config, error) {
+	return ConfigFrom(os.Getenv)
+}
+
+// ConfigFrom builds a Config like ConfigFromEnv, reading each variable with getenv.
+func ConfigFrom(getenv func(string) string) (Config, error) {
+	location := getenv("IKE_BLOB_STORE")
+	if location == "" {
+		return Config{}, nil
+	}
+
+	config := Config{MinSize: DefaultMinSize}
+	if value := getenv("IKE_BLOB_MIN_SIZE"); value != "" {
+		size, err := strconv.Atoi(value)
+		if err != nil || size < 0 {
+			return Config{}, fmt.Errorf("%w: IKE_BLOB_MIN_SIZE %q", ErrInvalidConfig, value)
+		}
+		config.MinSize = size
+	}
+
+	parsed, err := url.Parse(location)
+	switch {
+	case err == nil && parsed.Scheme == "s3":
+		if parsed.Host == "" {
+			return Config{}, fmt.Errorf("%w: IKE_BLOB_STORE %q names no bucket", ErrInvalidConfig, location)
+		}
+		config.Store, err = NewS3(S3Config{
+			Endpoint:        getenv("IKE_BLOB_S3_ENDPOINT"),
+			Region:          getenv("AWS_REGION"),
+			Bucket:          parsed.Host,
+			Prefix:          strings.Trim(parsed.Path, "/"),
+			AccessKeyID:     getenv("AWS_ACCESS_KEY_ID"),
+			SecretAccessKey: getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    getenv("AWS_SESSION_TOKEN"),
+		})
+	case err == nil && parsed.Scheme == "file":
+		config.Store, err = NewDisk(parsed.Path)
+	case err == nil && parsed.Scheme != "":
+		return Config{}, fmt.Errorf("%w: IKE_BLOB_STORE scheme %q", ErrInvalidConfig, parsed.Scheme)
+	default:
+		config.Store, err = NewDisk(location)
+	}
+	if err != nil {
+		return Config{}, fmt.Errorf("%w: IKE_BLOB_STORE: %w", ErrInvalidConfig, err)
+	}
+	return config, nil
+}
+
+// SetDefault sets the configuration Default returns from then on.
+func SetDefault(config Config) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultConfig = config
+}
+
+// Default returns the configuration set with SetDefault.
+func Default() Config {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultConfig
+}
+
+// Stores reports whether a body of size bytes goes to the blob store.
+func (c Config) Stores(size int) bool {
+	return c.Store != nil && size >= c.MinSize
+}
+
+// BodyKey returns the key of the body with the given content hash.
+func BodyKey(contentHash string) string {
+	if len(contentHash) < 2 {
+		return "bodies/" + contentHash
+	}
+	return "bodies/" + contentHash[:2] + "/" + contentHash
+}
+
+// ReadBody returns the plain-text body stored under key with the given encoding.
+func (c Config) ReadBody(ctx context.Context, key, encoding string) (string, error) {
+	if c.Store == nil {
+		return "", ErrNotConfigured
+	}
+	blob, err := c.Store.Get(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	defer blob.Close()
+	return compression.DecodeBodyReader(blob, encoding)
+}
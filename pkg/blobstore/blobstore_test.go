@@ -0,0 +1,111 @@
+package blobstore
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/code-sleuth/ike-go/pkg/compression"
+)
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("IKE_BLOB_STORE", "")
+	config, err := ConfigFromEnv()
+	if err != nil || config.Store != nil {
+		t.Errorf("Expected no store when unset, got %+v (%v)", config, err)
+	}
+
+	dir := t.TempDir()
+	t.Setenv("IKE_BLOB_STORE", "file://"+dir)
+	t.Setenv("IKE_BLOB_MIN_SIZE", "2048")
+	config, err = ConfigFromEnv()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if disk, ok := config.Store.(*Disk); !ok || disk.dir != dir || config.MinSize != 2048 {
+		t.Errorf("Expected a disk store in %s from 2048 bytes, got %+v", dir, config)
+	}
+
+	t.Setenv("IKE_BLOB_STORE", "s3://bodies/ike")
+	t.Setenv("AWS_ACCESS_KEY_ID", "key")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_REGION", "eu-west-1")
+	config, err = ConfigFromEnv()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	s3, ok := config.Store.(*S3)
+	if !ok || s3.objectURL("k") != "https://s3.eu-west-1.amazonaws.com/bodies/ike/k" {
+		t.Errorf("Expected an S3 store for bodies/ike, got %+v", config.Store)
+	}
+
+	for name, value := range map[string]string{"IKE_BLOB_STORE": "ftp://host/dir", "IKE_BLOB_MIN_SIZE": "big"} {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv("IKE_BLOB_STORE", dir)
+			t.Setenv(name, value)
+			if _, err := ConfigFromEnv(); !errors.Is(err, ErrInvalidConfig) {
+				t.Errorf("Expected ErrInvalidConfig, got %v", err)
+			}
+		})
+	}
+}
+
+func TestDisk(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewDisk(dir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	ctx := context.Background()
+
+	if err := store.Put(ctx, BodyKey("abcdef"), strings.NewReader("hello"), 5); err != nil {
+		t.Fatalf("Failed to put blob: %v", err)
+	}
+	if store.path(BodyKey("abcdef")) != filepath.Join(dir, "bodies", "ab", "abcdef") {
+		t.Errorf("Unexpected blob path %s", store.path(BodyKey("abcdef")))
+	}
+	if store.path("../../etc/passwd") != filepath.Join(dir, "etc", "passwd") {
+		t.Errorf("Expected keys to stay inside the store, got %s", store.path("../../etc/passwd"))
+	}
+
+	config := Config{Store: store, MinSize: 4}
+	body, err := config.ReadBody(ctx, BodyKey("abcdef"), compression.EncodingIdentity)
+	if err != nil || body != "hello" {
+		t.Errorf("Expected hello, got %q (%v)", body, err)
+	}
+	if _, err := store.Get(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestConfig_ReadBody_Gzip(t *testing.T) {
+	store, _ := NewDisk(t.TempDir())
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	writer.Write([]byte("a large body"))
+	writer.Close()
+	if err := store.Put(context.Background(), "k", &compressed, int64(compressed.Len())); err != nil {
+		t.Fatalf("Failed to put blob: %v", err)
+	}
+
+	config := Config{Store: store}
+	body, err := config.ReadBody(context.Background(), "k", compression.EncodingGzip)
+	if err != nil || body != "a large body" {
+		t.Errorf("Expected the decompressed body, got %q (%v)", body, err)
+	}
+	if _, err := (Config{}).ReadBody(context.Background(), "k", compression.EncodingGzip); !errors.Is(err, ErrNotConfigured) {
+		t.Errorf("Expected ErrNotConfigured, got %v", err)
+	}
+}
+
+func TestConfig_Stores(t *testing.T) {
+	store, _ := NewDisk(t.TempDir())
+	config := Config{Store: store, MinSize: 10}
+	if config.Stores(9) || !config.Stores(10) || (Config{}).Stores(1<<30) {
+		t.Error("Expected only bodies of at least MinSize to go to a configured store")
+	}
+}
@@ -0,0 +1,64 @@
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// Disk keeps blobs as files under a directory, a key's slashes naming subdirectories.
+type Disk struct {
+	dir string
+}
+
+// NewDisk returns a store in dir, creating it if needed.
+func NewDisk(dir string) (*Disk, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+	return &Disk{dir: dir}, nil
+}
+
+// Put writes body to a temporary file beside the blob and renames it into place, so readers never
+// see a partly written blob.
+func (d *Disk) Put(_ context.Context, key string, body io.Reader, _ int64) error {
+	target := d.path(key)
+	if err := os.MkdirAll(filepath.Dir(target), 0o750); err != nil {
+		return err
+	}
+	file, err := os.CreateTemp(filepath.Dir(target), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := io.Copy(file, body); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), target)
+}
+
+// Get opens the blob stored under key.
+func (d *Disk) Get(_ context.Context, key string) (io.ReadCloser, error) {
+	file, err := os.Open(d.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// path returns the file of key. Keys are cleaned as if rooted, so none can name a file outside dir.
+func (d *Disk) path(key string) string {
+	return filepath.Join(d.dir, filepath.FromSlash(path.Clean("/"+key)))
+}
@@ -0,0 +1,211 @@
+package blobstore
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/code-sleuth/ike-go/pkg/httpclient"
+)
+
+const (
+	defaultS3Region  = "us-east-1"
+	s3Timeout        = 10 * time.Minute
+	unsignedPayload  = "UNSIGNED-PAYLOAD"
+	amzDateFormat    = "20060102T150405Z"
+	signingAlgorithm = "AWS4-HMAC-SHA256"
+)
+
+var (
+	ErrMissingCredentials = errors.New("AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY are required")
+	ErrS3Request          = errors.New("S3 request failed")
+)
+
+// S3Config locates a bucket and the credentials to reach it.
+type S3Config struct {
+	// Endpoint is the service URL; https://s3.<Region>.amazonaws.com when empty
+	Endpoint string
+	// Region is the bucket's region, us-east-1 when empty
+	Region string
+	Bucket string
+	// Prefix, when set, is prepended to every key
+	Prefix          string
+	AccessKeyID     string
+	SecretAccessKey string
+	// SessionToken is sent with temporary credentials
+	SessionToken string
+}
+
+// S3 keeps blobs in an S3 bucket, or a bucket of any service speaking its API, addressed by path
+// so buckets with dots in their names and services without virtual-host routing work too.
+type S3 struct {
+	config   S3Config
+	endpoint *url.URL
+	client   *http.Client
+	now      func() time.Time
+}
+
+// NewS3 returns a store in the configured bucket.
+func NewS3(config S3Config) (*S3, error) {
+	if config.AccessKeyID == "" || config.SecretAccessKey == "" {
+		return nil, ErrMissingCredentials
+	}
+	if config.Region == "" {
+		config.Region = defaultS3Region
+	}
+	if config.Endpoint == "" {
+		config.Endpoint = "https://s3." + config.Region + ".amazonaws.com"
+	}
+	endpoint, err := url.Parse(config.Endpoint)
+	if err != nil || endpoint.Host == "" {
+		return nil, fmt.Errorf("%w: endpoint %q is not a URL", ErrInvalidConfig, config.Endpoint)
+	}
+	return &S3{config: config, endpoint: endpoint, client: httpclient.New(s3Timeout), now: time.Now}, nil
+}
+
+// Put uploads the size bytes of body under key. The payload is not hashed into the signature,
+// so body is streamed rather than read twice.
+func (s *S3) Put(ctx context.Context, key string, body io.Reader, size int64) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.objectURL(key), body)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	if size == 0 {
+		req.Body = http.NoBody
+	}
+
+	resp, err := s.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// Get downloads the blob stored under key.
+func (s *S3) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.objectURL(key), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.do(req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// do signs and sends req, turning error responses into errors.
+func (s *S3) do(req *http.Request) (*http.Response, error) {
+	req.Header.Set("X-Amz-Content-Sha256", unsignedPayload)
+	if s.config.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.config.SessionToken)
+	}
+	s.sign(req, s.now())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close()
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("%w: %s %s: %s: %s", ErrS3Request, req.Method, req.URL.Path, resp.Status,
+			strings.TrimSpace(string(message)))
+	}
+	return resp, nil
+}
+
+// objectURL returns the path-style URL of key.
+func (s *S3) objectURL(key string) string {
+	if s.config.Prefix != "" {
+		key = s.config.Prefix + "/" + key
+	}
+	object := *s.endpoint
+	object.Path = strings.TrimSuffix(object.Path, "/") + "/" + s.config.Bucket + "/" + key
+	return object.String()
+}
+
+// sign adds an AWS Signature Version 4 Authorization header to req, covering its host and every
+// header already set on it.
+func (s *S3) sign(req *http.Request, now time.Time) {
+	amzDate := now.UTC().Format(amzDateFormat)
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	payloadHash := req.Header.Get("X-Amz-Content-Sha256")
+	canonicalRequest := strings.Join([]string{
+		req.Method, req.URL.EscapedPath(), canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(), signedHeaders, payloadHash,
+	}, "\n")
+
+	scope := date + "/" + s.config.Region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{signingAlgorithm, amzDate, scope, sha256Hex(canonicalRequest)}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.config.SecretAccessKey), date)
+	key = hmacSHA256(key, s.config.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		signingAlgorithm, s.config.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// canonicalQuery renders query sorted by key and value, encoded as Signature Version 4 expects.
+func canonicalQuery(query url.Values) string {
+	pairs := make([]string, 0, len(query))
+	for key, values := range query {
+		for _, value := range values {
+			pairs = append(pairs, awsEscape(key)+"="+awsEscape(value))
+		}
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "&")
+}
+
+// awsEscape percent-encodes everything but unreserved characters.
+func awsEscape(value string) string {
+	return strings.ReplaceAll(url.QueryEscape(value), "+", "%20")
+}
+
+func sha256Hex(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, value string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(value))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,77 @@
+package compression
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	// EncodingIdentity marks a body stored as plain text.
+	EncodingIdentity = "identity"
+	// EncodingGzip marks a body stored as gzip-compressed bytes.
+	EncodingGzip = "gzip"
+	// DefaultMinSize is the smallest body, in bytes, worth compressing.
+	DefaultMinSize = 1024
+)
+
+var ErrUnsupportedEncoding = errors.New("unsupported body encoding")
+
+// EncodeBody prepares a download body for storage. Bodies of at least minSize bytes are gzip
+// compressed when that actually makes them smaller; everything else is stored as-is.
+// It returns the value to bind to the body column together with its encoding.
+func EncodeBody(body string, minSize int) (interface{}, string, error) {
+	if len(body) < minSize {
+		return body, EncodingIdentity, nil
+	}
+
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write([]byte(body)); err != nil {
+		return nil, "", err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, "", err
+	}
+
+	if buf.Len() >= len(body) {
+		return body, EncodingIdentity, nil
+	}
+
+	return buf.Bytes(), EncodingGzip, nil
+}
+
+// DecodeBody returns the plain-text body for a stored value and its encoding.
+// An empty encoding is treated as identity for rows written before encodings were tracked.
+func DecodeBody(raw []byte, encoding string) (string, error) {
+	if encoding == "" || encoding == EncodingIdentity {
+		return string(raw), nil
+	}
+	return DecodeBodyReader(bytes.NewReader(raw), encoding)
+}
+
+// DecodeBodyReader is DecodeBody for a body read from r, such as one kept in a blob store, so a
+// compressed body is decompressed as it is read rather than held twice.
+func DecodeBodyReader(r io.Reader, encoding string) (string, error) {
+	switch encoding {
+	case "", EncodingIdentity:
+		decoded, err := io.ReadAll(r)
+		return string(decoded), err
+	case EncodingGzip:
+		reader, err := gzip.NewReader(r)
+		if err != nil {
+			return "", err
+		}
+		defer reader.Close()
+
+		decoded, err := io.ReadAll(reader)
+		if err != nil {
+			return "", err
+		}
+		return string(decoded), nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedEncoding, encoding)
+	}
+}
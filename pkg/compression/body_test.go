@@ -0,0 +1,91 @@
+package compression
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestEncodeDecodeBody(t *testing.T) {
+	tests := []struct {
+		name             string
+		body             string
+		minSize          int
+		expectedEncoding string
+		description      string
+	}{
+		{
+			name:             "small body stays plain",
+			body:             `{"id": 1}`,
+			minSize:          DefaultMinSize,
+			expectedEncoding: EncodingIdentity,
+			description:      "should not compress bodies below the minimum size",
+		},
+		{
+			name:             "large body is compressed",
+			body:             strings.Repeat("<p>WordPress content</p>", 200),
+			minSize:          DefaultMinSize,
+			expectedEncoding: EncodingGzip,
+			description:      "should gzip large repetitive bodies",
+		},
+		{
+			name:             "incompressible body stays plain",
+			body:             "abc",
+			minSize:          0,
+			expectedEncoding: EncodingIdentity,
+			description:      "should keep bodies that gzip would make larger",
+		},
+		{
+			name:             "empty body",
+			body:             "",
+			minSize:          DefaultMinSize,
+			expectedEncoding: EncodingIdentity,
+			description:      "should handle empty bodies",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, encoding, err := EncodeBody(tt.body, tt.minSize)
+			if err != nil {
+				t.Fatalf("Unexpected error for test %s: %v", tt.description, err)
+			}
+			if encoding != tt.expectedEncoding {
+				t.Errorf("Expected encoding %s, got %s for test: %s", tt.expectedEncoding, encoding, tt.description)
+			}
+
+			var raw []byte
+			switch v := value.(type) {
+			case string:
+				raw = []byte(v)
+			case []byte:
+				raw = v
+			default:
+				t.Fatalf("Unexpected value type %T", value)
+			}
+
+			decoded, err := DecodeBody(raw, encoding)
+			if err != nil {
+				t.Fatalf("Unexpected decode error for test %s: %v", tt.description, err)
+			}
+			if decoded != tt.body {
+				t.Errorf("Round trip mismatch for test: %s", tt.description)
+			}
+		})
+	}
+}
+
+func TestDecodeBody_Errors(t *testing.T) {
+	if _, err := DecodeBody([]byte("plain"), "zstd"); !errors.Is(err, ErrUnsupportedEncoding) {
+		t.Errorf("Expected ErrUnsupportedEncoding, got %v", err)
+	}
+
+	if _, err := DecodeBody([]byte("not gzip"), EncodingGzip); err == nil {
+		t.Error("Expected error decoding invalid gzip data")
+	}
+
+	decoded, err := DecodeBody([]byte("legacy"), "")
+	if err != nil || decoded != "legacy" {
+		t.Errorf("Expected legacy rows to decode as identity, got %q, %v", decoded, err)
+	}
+}
@@ -0,0 +1,271 @@
+// Package config gathers ike-go's settings into one Config, built once and handed to the
+// components that need them instead of each reading environment variables itself.
+//
+// Settings are named by their environment variables, such as GITHUB_TOKEN, and layered: a JSON
+// settings file overrides the environment, and overrides such as command-line flags override
+// both.
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/code-sleuth/ike-go/pkg/blobstore"
+	"github.com/code-sleuth/ike-go/pkg/db"
+	"github.com/code-sleuth/ike-go/pkg/httpclient"
+	"github.com/code-sleuth/ike-go/pkg/util"
+	"github.com/rs/zerolog"
+)
+
+var (
+	ErrUnknownKey    = errors.New("unknown setting")
+	ErrInvalidValue  = errors.New("settings must be strings, numbers, or booleans")
+	ErrInvalidConfig = errors.New("invalid configuration")
+)
+
+// Keys names every setting, as the environment, settings files, and overrides give it.
+var Keys = []string{
+	// Database; see db.ConfigFromEnv
+	"TURSO_DATABASE_URL", "TURSO_AUTH_TOKEN", "DB_READ_URL", "DB_READ_AUTH_TOKEN", "DB_SEPARATE_READS",
+	"DB_READ_MAX_OPEN_CONNS", "DB_MAX_OPEN_CONNS", "DB_MAX_IDLE_CONNS", "DB_CONN_MAX_LIFETIME",
+	"DB_CONN_MAX_IDLE_TIME", "SQLITE_JOURNAL_MODE", "SQLITE_BUSY_TIMEOUT", "SQLITE_SYNCHRONOUS",
+	"SQLITE_CACHE_SIZE", "SQLITE_MMAP_SIZE",
+	// Outbound HTTP; see httpclient.ConfigFromEnv
+	"IKE_HTTP_PROXY", "IKE_HTTP_CA_FILE", "IKE_HTTP_INSECURE_SKIP_VERIFY", "IKE_HTTP_USER_AGENT",
+	"IKE_HTTP_TIMEOUT", "IKE_HTTP_MAX_RESPONSE_SIZE",
+	// Blob store; see blobstore.ConfigFromEnv
+	"IKE_BLOB_STORE", "IKE_BLOB_MIN_SIZE", "IKE_BLOB_S3_ENDPOINT", "AWS_REGION", "AWS_ACCESS_KEY_ID",
+	"AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN",
+	// Credentials for the services sources are imported from and embedded with
+	"GITHUB_TOKEN", "OPENAI_API_KEY", "TOGETHER_API_KEY", "COHERE_API_KEY", "JINA_API_KEY",
+	"RERANKER_URL", "RERANKER_API_KEY", "WORDPRESS_USERNAME", "WORDPRESS_APP_PASSWORD",
+	"WORDPRESS_TOKEN", "WORDPRESS_HEADERS", "WORDPRESS_AUTH_HOSTS",
+	// Server
+	"IKE_ADMIN_TOKEN", "IKE_SEARCH_TOKENS", "GITHUB_WEBHOOK_SECRET", "WORDPRESS_WEBHOOK_SECRET",
+	// Processing
+	"CHUNKER_TOKENIZER", "IKE_PLUGIN_DIR",
+	// Logging; see util.ParseLogLevels
+	"IKE_LOG_LEVELS",
+}
+
+// Config is every setting ike-go reads, typed.
+type Config struct {
+	Database *db.Config
+	HTTP     httpclient.Config
+	Blob     blobstore.Config
+
+	// API keys and tokens; empty ones leave the services needing them unusable
+	GitHubToken    string
+	OpenAIAPIKey   string
+	TogetherAPIKey string
+	CohereAPIKey   string
+	JinaAPIKey     string
+
+	Reranker  Reranker
+	WordPress WordPress
+	Server    Server
+
+	// ChunkerTokenizer names the tiktoken encoding chunks are sized with; cl100k_base when empty
+	ChunkerTokenizer string
+	// PluginDir is the directory importer and transformer plugins are loaded from
+	PluginDir string
+	// LogLevels are the levels of component loggers, by component or package name
+	LogLevels map[string]zerolog.Level
+}
+
+// Reranker is the cross-encoder reranking server search can use.
+type Reranker struct {
+	URL    string
+	APIKey string
+}
+
+// WordPress holds the credentials WordPress API requests are sent with.
+type WordPress struct {
+	// Username and AppPassword are sent as Basic auth, for WordPress Application Passwords
+	Username    string
+	AppPassword string
+	// Token is sent as a Bearer token, for JWT authentication plugins
+	Token string
+	// Headers are added to every request
+	Headers map[string]string
+	// Hosts limits the credentials to these hosts; when empty they are sent to every site
+	Hosts []string
+}
+
+// Server holds the credentials the HTTP server checks.
+type Server struct {
+	AdminToken string
+	// SearchTokens are token=label,label entries granting search access limited to the labels
+	SearchTokens           []string
+	GitHubWebhookSecret    string
+	WordPressWebhookSecret string
+}
+
+// FromEnv builds a Config from the environment alone.
+func FromEnv() (*Config, error) {
+	return From(os.Getenv)
+}
+
+// Load builds a Config from the environment, then the JSON settings file at path when path is
+// not empty, then overrides, each layer replacing the settings the ones before it gave. The file
+// is an object of settings, such as {"GITHUB_TOKEN": "ghp_...", "DB_MAX_OPEN_CONNS": 8}.
+func Load(path string, overrides map[string]string) (*Config, error) {
+	values := make(map[string]string)
+	if path != "" {
+		fileValues, err := readFile(path)
+		if err != nil {
+			return nil, err
+		}
+		maps.Copy(values, fileValues)
+	}
+	for _, key := range slices.Sorted(maps.Keys(overrides)) {
+		if !slices.Contains(Keys, key) {
+			return nil, fmt.Errorf("%w %q", ErrUnknownKey, key)
+		}
+		values[key] = overrides[key]
+	}
+
+	return From(func(key string) string {
+		if value, ok := values[key]; ok {
+			return value
+		}
+		return os.Getenv(key)
+	})
+}
+
+// readFile reads the settings in the JSON file at path.
+func readFile(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open settings file: %w", err)
+	}
+	defer file.Close()
+
+	var raw map[string]interface{}
+	decoder := json.NewDecoder(file)
+	decoder.UseNumber()
+	if err := decoder.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("failed to parse settings file %s: %w", path, err)
+	}
+
+	values := make(map[string]string, len(raw))
+	for _, key := range slices.Sorted(maps.Keys(raw)) {
+		if !slices.Contains(Keys, key) {
+			return nil, fmt.Errorf("%w %q in %s", ErrUnknownKey, key, path)
+		}
+		switch v := raw[key].(type) {
+		case string:
+			values[key] = v
+		case json.Number, bool:
+			values[key] = fmt.Sprint(v)
+		default:
+			return nil, fmt.Errorf("%w: %q in %s", ErrInvalidValue, key, path)
+		}
+	}
+	return values, nil
+}
+
+// From builds a Config, reading each setting with getenv, and validates it.
+func From(getenv func(string) string) (*Config, error) {
+	database, err := db.ConfigFrom(getenv)
+	if err != nil {
+		return nil, err
+	}
+	httpConfig, err := httpclient.ConfigFrom(getenv)
+	if err != nil {
+		return nil, err
+	}
+	blobConfig, err := blobstore.ConfigFrom(getenv)
+	if err != nil {
+		return nil, err
+	}
+	logLevels, err := util.ParseLogLevels(getenv("IKE_LOG_LEVELS"))
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &Config{
+		Database:       database,
+		HTTP:           httpConfig,
+		Blob:           blobConfig,
+		GitHubToken:    getenv("GITHUB_TOKEN"),
+		OpenAIAPIKey:   getenv("OPENAI_API_KEY"),
+		TogetherAPIKey: getenv("TOGETHER_API_KEY"),
+		CohereAPIKey:   getenv("COHERE_API_KEY"),
+		JinaAPIKey:     getenv("JINA_API_KEY"),
+		Reranker: Reranker{
+			URL:    getenv("RERANKER_URL"),
+			APIKey: getenv("RERANKER_API_KEY"),
+		},
+		WordPress: WordPress{
+			Username:    getenv("WORDPRESS_USERNAME"),
+			AppPassword: getenv("WORDPRESS_APP_PASSWORD"),
+			Token:       getenv("WORDPRESS_TOKEN"),
+			Headers:     parsePairs(getenv("WORDPRESS_HEADERS")),
+			Hosts:       splitList(strings.ToLower(getenv("WORDPRESS_AUTH_HOSTS"))),
+		},
+		Server: Server{
+			AdminToken:             getenv("IKE_ADMIN_TOKEN"),
+			SearchTokens:           strings.Fields(getenv("IKE_SEARCH_TOKENS")),
+			GitHubWebhookSecret:    getenv("GITHUB_WEBHOOK_SECRET"),
+			WordPressWebhookSecret: getenv("WORDPRESS_WEBHOOK_SECRET"),
+		},
+		ChunkerTokenizer: getenv("CHUNKER_TOKENIZER"),
+		PluginDir:        getenv("IKE_PLUGIN_DIR"),
+		LogLevels:        logLevels,
+	}
+	return cfg, cfg.Validate()
+}
+
+// Validate checks settings that only make sense together or in a particular form.
+func (c *Config) Validate() error {
+	if c.Database != nil {
+		if err := c.Database.Validate(); err != nil {
+			return err
+		}
+	}
+	if (c.WordPress.Username == "") != (c.WordPress.AppPassword == "") {
+		return fmt.Errorf("%w: WORDPRESS_USERNAME and WORDPRESS_APP_PASSWORD must be set together",
+			ErrInvalidConfig)
+	}
+	if c.Reranker.URL != "" {
+		parsed, err := url.Parse(c.Reranker.URL)
+		if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+			return fmt.Errorf("%w: RERANKER_URL %q is not a URL", ErrInvalidConfig, c.Reranker.URL)
+		}
+	}
+	return nil
+}
+
+// parsePairs parses key=value pairs separated by commas, whose values may be URL-encoded.
+func parsePairs(value string) map[string]string {
+	pairs := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		key, val, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			continue
+		}
+		if decoded, err := url.QueryUnescape(strings.TrimSpace(val)); err == nil {
+			val = decoded
+		}
+		pairs[strings.TrimSpace(key)] = strings.TrimSpace(val)
+	}
+	return pairs
+}
+
+// splitList splits a list separated by commas, dropping empty entries.
+func splitList(value string) []string {
+	var list []string
+	for _, entry := range strings.Split(value, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			list = append(list, entry)
+		}
+	}
+	return list
+}
@@ -0,0 +1,131 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/code-sleuth/ike-go/pkg/db"
+	"github.com/code-sleuth/ike-go/pkg/util"
+	"github.com/rs/zerolog"
+)
+
+func writeSettings(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "settings.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write settings file: %v", err)
+	}
+	return path
+}
+
+func TestLoad_Layers(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "env-token")
+	t.Setenv("OPENAI_API_KEY", "env-openai")
+	t.Setenv("TOGETHER_API_KEY", "env-together")
+	t.Setenv("DB_MAX_OPEN_CONNS", "2")
+
+	path := writeSettings(t, `{"GITHUB_TOKEN": "file-token", "OPENAI_API_KEY": "file-openai", "DB_MAX_OPEN_CONNS": 4}`)
+	cfg, err := Load(path, map[string]string{"OPENAI_API_KEY": "flag-openai"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if cfg.TogetherAPIKey != "env-together" {
+		t.Errorf("Expected the environment's Together key, got %q", cfg.TogetherAPIKey)
+	}
+	if cfg.GitHubToken != "file-token" {
+		t.Errorf("Expected the settings file to override the environment, got %q", cfg.GitHubToken)
+	}
+	if cfg.OpenAIAPIKey != "flag-openai" {
+		t.Errorf("Expected the override to win over the file and environment, got %q", cfg.OpenAIAPIKey)
+	}
+	if cfg.Database.MaxOpenConns != 4 {
+		t.Errorf("Expected the file's numeric DB_MAX_OPEN_CONNS 4, got %d", cfg.Database.MaxOpenConns)
+	}
+}
+
+func TestLoad_UnknownKeys(t *testing.T) {
+	path := writeSettings(t, `{"GITHUB_TOKN": "typo"}`)
+	if _, err := Load(path, nil); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Expected ErrUnknownKey for a file key, got %v", err)
+	}
+	if _, err := Load("", map[string]string{"OPENAI_KEY": "x"}); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Expected ErrUnknownKey for an override, got %v", err)
+	}
+
+	path = writeSettings(t, `{"WORDPRESS_AUTH_HOSTS": ["a.example.com"]}`)
+	if _, err := Load(path, nil); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("Expected ErrInvalidValue for a list, got %v", err)
+	}
+}
+
+func TestFrom(t *testing.T) {
+	values := map[string]string{
+		"WORDPRESS_USERNAME":     "editor",
+		"WORDPRESS_APP_PASSWORD": "pass",
+		"WORDPRESS_HEADERS":      "X-Site=blog%2C%20main, X-Env = staging",
+		"WORDPRESS_AUTH_HOSTS":   "Blog.Example.com, ,staging.example.com",
+		"IKE_SEARCH_TOKENS":      "abc=team ops=ops,team",
+		"IKE_LOG_LEVELS":         "importers=debug, engine=warn",
+	}
+	cfg, err := From(func(key string) string { return values[key] })
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if want := map[string]string{"X-Site": "blog, main", "X-Env": "staging"}; !reflect.DeepEqual(
+		cfg.WordPress.Headers, want) {
+		t.Errorf("Expected headers %v, got %v", want, cfg.WordPress.Headers)
+	}
+	if want := []string{"blog.example.com", "staging.example.com"}; !reflect.DeepEqual(cfg.WordPress.Hosts, want) {
+		t.Errorf("Expected hosts %v, got %v", want, cfg.WordPress.Hosts)
+	}
+	if want := []string{"abc=team", "ops=ops,team"}; !reflect.DeepEqual(cfg.Server.SearchTokens, want) {
+		t.Errorf("Expected search tokens %v, got %v", want, cfg.Server.SearchTokens)
+	}
+	want := map[string]zerolog.Level{"importers": zerolog.DebugLevel, "engine": zerolog.WarnLevel}
+	if !reflect.DeepEqual(cfg.LogLevels, want) {
+		t.Errorf("Expected log levels %v, got %v", want, cfg.LogLevels)
+	}
+}
+
+func TestFrom_Invalid(t *testing.T) {
+	tests := []struct {
+		name        string
+		values      map[string]string
+		expectedErr error
+	}{
+		{
+			name:        "username without app password",
+			values:      map[string]string{"WORDPRESS_USERNAME": "editor"},
+			expectedErr: ErrInvalidConfig,
+		},
+		{
+			name:        "reranker URL without a host",
+			values:      map[string]string{"RERANKER_URL": "localhost:8080"},
+			expectedErr: ErrInvalidConfig,
+		},
+		{
+			name:        "unknown log level",
+			values:      map[string]string{"IKE_LOG_LEVELS": "importers=chatty"},
+			expectedErr: util.ErrInvalidLogLevel,
+		},
+		{
+			name:        "invalid database setting",
+			values:      map[string]string{"SQLITE_JOURNAL_MODE": "FAST"},
+			expectedErr: db.ErrInvalidJournalMode,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := From(func(key string) string { return tt.values[key] })
+			if !errors.Is(err, tt.expectedErr) {
+				t.Errorf("Expected %v, got %v", tt.expectedErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package db
+
+import (
+	"context"
+	"database/sql/driver"
+	"strings"
+	"time"
+)
+
+const (
+	// busyRetryDelay is the wait before the first retry of a statement the database was too busy
+	// for; each retry waits twice as long, up to maxBusyRetryDelay.
+	busyRetryDelay    = 10 * time.Millisecond
+	maxBusyRetryDelay = 500 * time.Millisecond
+)
+
+// busyErrors are fragments of the errors a database returns when another connection holds the
+// lock a statement needs, as SQLITE_BUSY and SQLITE_LOCKED.
+var busyErrors = []string{"sqlite_busy", "sqlite_locked", "database is locked", "database table is locked"}
+
+// isBusy reports whether err is the database refusing a statement because it is locked.
+func isBusy(err error) bool {
+	if err == nil {
+		return false
+	}
+	message := strings.ToLower(err.Error())
+	for _, fragment := range busyErrors {
+		if strings.Contains(message, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+// retryBusy runs fn, running it again while it fails because the database is locked, waiting
+// longer between each attempt, until timeout has passed or ctx ends. It returns fn's last error.
+func retryBusy(ctx context.Context, timeout time.Duration, fn func() error) error {
+	deadline := time.Now().Add(timeout)
+	delay := busyRetryDelay
+	for {
+		err := fn()
+		if !isBusy(err) || time.Now().Add(delay).After(deadline) {
+			return err
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		delay = min(delay*2, maxBusyRetryDelay)
+	}
+}
+
+// busyConnector retries the statements of its connections that fail with SQLITE_BUSY or
+// SQLITE_LOCKED for up to timeout. Local SQLite connections wait for the lock themselves through
+// PRAGMA busy_timeout, but remote libSQL servers return the error at once, so concurrent chunk
+// workers writing to one database would otherwise fail.
+type busyConnector struct {
+	driver.Connector
+	timeout time.Duration
+}
+
+func (c *busyConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &busyConn{Conn: conn, timeout: c.timeout}, nil
+}
+
+// busyConn is a connection whose statements and transactions are retried while the database is
+// locked. Everything else is passed to the wrapped connection.
+type busyConn struct {
+	driver.Conn
+	timeout time.Duration
+}
+
+func (c *busyConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	var result driver.Result
+	err := retryBusy(ctx, c.timeout, func() error {
+		var err error
+		result, err = execer.ExecContext(ctx, query, args)
+		return err
+	})
+	return result, err
+}
+
+func (c *busyConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	var rows driver.Rows
+	err := retryBusy(ctx, c.timeout, func() error {
+		var err error
+		rows, err = queryer.QueryContext(ctx, query, args)
+		return err
+	})
+	return rows, err
+}
+
+func (c *busyConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	var tx driver.Tx
+	err := retryBusy(ctx, c.timeout, func() error {
+		var err error
+		if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+			tx, err = beginner.BeginTx(ctx, opts)
+		} else {
+			tx, err = c.Conn.Begin() //nolint:staticcheck // fallback for drivers without BeginTx
+		}
+		return err
+	})
+	return tx, err
+}
+
+func (c *busyConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		return preparer.PrepareContext(ctx, query)
+	}
+	return c.Conn.Prepare(query)
+}
+
+func (c *busyConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *busyConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+)
+
+var (
+	errBusy   = errors.New("SQLITE_BUSY: database is locked")
+	errSyntax = errors.New("SQLITE_ERROR: near \"SELEC\": syntax error")
+)
+
+// lockedConnector opens connections whose statements fail with errs, one per attempt, before
+// succeeding.
+type lockedConnector struct {
+	errs     []error
+	attempts int
+}
+
+func (c *lockedConnector) Connect(context.Context) (driver.Conn, error) { return &lockedConn{c}, nil }
+func (c *lockedConnector) Driver() driver.Driver                        { return nil }
+
+type lockedConn struct {
+	connector *lockedConnector
+}
+
+func (c *lockedConn) ExecContext(context.Context, string, []driver.NamedValue) (driver.Result, error) {
+	c.connector.attempts++
+	if c.connector.attempts <= len(c.connector.errs) {
+		return nil, c.connector.errs[c.connector.attempts-1]
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (c *lockedConn) Prepare(string) (driver.Stmt, error) { return nil, errSyntax }
+func (c *lockedConn) Close() error                        { return nil }
+func (c *lockedConn) Begin() (driver.Tx, error)           { return nil, errSyntax }
+
+func TestBusyConnector(t *testing.T) {
+	tests := []struct {
+		name             string
+		errs             []error
+		timeout          time.Duration
+		expectedErr      error
+		expectedAttempts int
+	}{
+		{name: "retried until the lock is released", errs: []error{errBusy, errBusy}, timeout: time.Second,
+			expectedAttempts: 3},
+		{name: "other errors are not retried", errs: []error{errSyntax}, timeout: time.Second,
+			expectedErr: errSyntax, expectedAttempts: 1},
+		{name: "gives up after the timeout", errs: []error{errBusy, errBusy, errBusy, errBusy, errBusy},
+			timeout: 25 * time.Millisecond, expectedErr: errBusy, expectedAttempts: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			connector := &lockedConnector{errs: tt.errs}
+			database := sql.OpenDB(&busyConnector{Connector: connector, timeout: tt.timeout})
+			defer database.Close()
+
+			_, err := database.Exec("INSERT INTO chunks (id) VALUES (?)", "chunk-1")
+			if !errors.Is(err, tt.expectedErr) {
+				t.Errorf("Expected error %v, got %v", tt.expectedErr, err)
+			}
+			if connector.attempts != tt.expectedAttempts {
+				t.Errorf("Expected %d attempts, got %d", tt.expectedAttempts, connector.attempts)
+			}
+		})
+	}
+}
+
+func TestIsBusy(t *testing.T) {
+	for _, err := range []error{errBusy, errors.New("SQLITE_LOCKED: database table is locked")} {
+		if !isBusy(err) {
+			t.Errorf("Expected %q to be a busy error", err)
+		}
+	}
+	for _, err := range []error{nil, errSyntax} {
+		if isBusy(err) {
+			t.Errorf("Expected %v not to be a busy error", err)
+		}
+	}
+}
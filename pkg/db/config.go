@@ -0,0 +1,241 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// Default SQLite busy timeout so concurrent writers wait for the lock instead of failing with SQLITE_BUSY.
+	defaultBusyTimeout = 5 * time.Second
+	// Default journal mode for local databases; WAL lets readers proceed while a writer holds the lock.
+	defaultJournalMode = "WAL"
+	// Default synchronous level; NORMAL is safe with WAL and much faster than FULL.
+	defaultSynchronous = "NORMAL"
+	// Default page cache, in KiB as a negative cache_size means; SQLite's own 2 MiB makes bulk
+	// inserts re-read index pages they just wrote.
+	defaultCacheSize = -64 * 1024
+	// Default memory-mapped I/O size in bytes; reads of a mapped database skip a copy per page.
+	defaultMmapSize = 256 << 20
+)
+
+var (
+	ErrInvalidJournalMode = errors.New("invalid journal mode")
+	ErrInvalidSynchronous = errors.New("invalid synchronous level")
+	ErrInvalidPoolSetting = errors.New("invalid connection pool setting")
+	ErrInvalidMmapSize    = errors.New("invalid mmap size")
+)
+
+var (
+	validJournalModes = []string{"DELETE", "TRUNCATE", "PERSIST", "MEMORY", "WAL", "OFF"}
+	validSynchronous  = []string{"OFF", "NORMAL", "FULL", "EXTRA"}
+)
+
+// Config holds connection, pool, and pragma settings for the database.
+//
+// Pragmas only apply to local file: databases, opened with the pure-Go modernc.org/sqlite
+// driver; remote Turso/libSQL servers manage journaling themselves. BusyTimeout
+// applies to both: remote statements that fail with SQLITE_BUSY or SQLITE_LOCKED are retried
+// for up to that long.
+type Config struct {
+	URL       string
+	AuthToken string
+
+	// Read handle settings. ReadURL points reads (search, listing) at a separate database such as
+	// a replica; SeparateReads opens a second pool on URL instead. For local databases the write
+	// pool is then limited to a single connection, matching SQLite's single-writer model.
+	ReadURL          string
+	ReadAuthToken    string
+	SeparateReads    bool
+	ReadMaxOpenConns int
+
+	// Pool settings. Zero values keep the database/sql defaults.
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+
+	// SQLite pragmas applied to every new local connection. CacheSize is in pages when positive
+	// and KiB when negative, as PRAGMA cache_size takes it; MmapSize is in bytes. Zero values keep
+	// SQLite's defaults.
+	JournalMode string
+	BusyTimeout time.Duration
+	Synchronous string
+	CacheSize   int
+	MmapSize    int64
+}
+
+// DefaultConfig returns a Config with the recommended pragma settings and no connection details.
+func DefaultConfig() *Config {
+	return &Config{
+		JournalMode: defaultJournalMode,
+		BusyTimeout: defaultBusyTimeout,
+		Synchronous: defaultSynchronous,
+		CacheSize:   defaultCacheSize,
+		MmapSize:    defaultMmapSize,
+	}
+}
+
+// ConfigFromEnv builds a Config from TURSO_DATABASE_URL, TURSO_AUTH_TOKEN and the optional
+// DB_MAX_OPEN_CONNS, DB_MAX_IDLE_CONNS, DB_CONN_MAX_LIFETIME, DB_CONN_MAX_IDLE_TIME,
+// DB_READ_URL, DB_READ_AUTH_TOKEN, DB_SEPARATE_READS, DB_READ_MAX_OPEN_CONNS,
+// SQLITE_JOURNAL_MODE, SQLITE_BUSY_TIMEOUT, SQLITE_SYNCHRONOUS, SQLITE_CACHE_SIZE and
+// SQLITE_MMAP_SIZE variables.
+func ConfigFromEnv() (*Config, error) {
+	return ConfigFrom(os.Getenv)
+}
+
+// ConfigFrom builds a Config like ConfigFromEnv, reading each variable with getenv, such as a
+// lookup in layered settings.
+func ConfigFrom(getenv func(string) string) (*Config, error) {
+	cfg := DefaultConfig()
+	cfg.URL = getenv("TURSO_DATABASE_URL")
+	cfg.AuthToken = getenv("TURSO_AUTH_TOKEN")
+	cfg.ReadURL = getenv("DB_READ_URL")
+	cfg.ReadAuthToken = getenv("DB_READ_AUTH_TOKEN")
+
+	var err error
+	if value := getenv("DB_SEPARATE_READS"); value != "" {
+		if cfg.SeparateReads, err = strconv.ParseBool(value); err != nil {
+			return nil, fmt.Errorf("%w: DB_SEPARATE_READS: %w", ErrInvalidPoolSetting, err)
+		}
+	}
+	if cfg.ReadMaxOpenConns, err = intFrom(getenv, "DB_READ_MAX_OPEN_CONNS", cfg.ReadMaxOpenConns); err != nil {
+		return nil, err
+	}
+	if cfg.MaxOpenConns, err = intFrom(getenv, "DB_MAX_OPEN_CONNS", cfg.MaxOpenConns); err != nil {
+		return nil, err
+	}
+	if cfg.MaxIdleConns, err = intFrom(getenv, "DB_MAX_IDLE_CONNS", cfg.MaxIdleConns); err != nil {
+		return nil, err
+	}
+	if cfg.ConnMaxLifetime, err = durationFrom(getenv, "DB_CONN_MAX_LIFETIME", cfg.ConnMaxLifetime); err != nil {
+		return nil, err
+	}
+	if cfg.ConnMaxIdleTime, err = durationFrom(getenv, "DB_CONN_MAX_IDLE_TIME", cfg.ConnMaxIdleTime); err != nil {
+		return nil, err
+	}
+	if cfg.BusyTimeout, err = durationFrom(getenv, "SQLITE_BUSY_TIMEOUT", cfg.BusyTimeout); err != nil {
+		return nil, err
+	}
+	if cfg.CacheSize, err = intFrom(getenv, "SQLITE_CACHE_SIZE", cfg.CacheSize); err != nil {
+		return nil, err
+	}
+	if value := getenv("SQLITE_MMAP_SIZE"); value != "" {
+		if cfg.MmapSize, err = strconv.ParseInt(value, 10, 64); err != nil {
+			return nil, fmt.Errorf("%w: SQLITE_MMAP_SIZE: %w", ErrInvalidMmapSize, err)
+		}
+	}
+	if value := getenv("SQLITE_JOURNAL_MODE"); value != "" {
+		cfg.JournalMode = value
+	}
+	if value := getenv("SQLITE_SYNCHRONOUS"); value != "" {
+		cfg.Synchronous = value
+	}
+
+	return cfg, cfg.Validate()
+}
+
+// Validate checks the pool and pragma settings.
+func (c *Config) Validate() error {
+	if c.MaxOpenConns < 0 || c.MaxIdleConns < 0 || c.ConnMaxLifetime < 0 || c.ConnMaxIdleTime < 0 ||
+		c.ReadMaxOpenConns < 0 {
+		return ErrInvalidPoolSetting
+	}
+	if c.JournalMode != "" && !contains(validJournalModes, c.JournalMode) {
+		return fmt.Errorf("%w: %s", ErrInvalidJournalMode, c.JournalMode)
+	}
+	if c.Synchronous != "" && !contains(validSynchronous, c.Synchronous) {
+		return fmt.Errorf("%w: %s", ErrInvalidSynchronous, c.Synchronous)
+	}
+	if c.MmapSize < 0 {
+		return fmt.Errorf("%w: %d", ErrInvalidMmapSize, c.MmapSize)
+	}
+	return nil
+}
+
+// IsLocal reports whether the URL points at a local SQLite file.
+func (c *Config) IsLocal() bool {
+	return strings.HasPrefix(c.URL, "file:")
+}
+
+// HasReadHandle reports whether reads go through their own connection pool.
+func (c *Config) HasReadHandle() bool {
+	return c.ReadURL != "" || c.SeparateReads
+}
+
+// ReadConfig returns the configuration for the read pool: the read URL and token when set,
+// otherwise the primary ones, with the read pool size.
+func (c *Config) ReadConfig() *Config {
+	read := *c
+	read.ReadURL, read.ReadAuthToken, read.SeparateReads = "", "", false
+	if c.ReadURL != "" {
+		read.URL = c.ReadURL
+		if c.ReadAuthToken != "" {
+			read.AuthToken = c.ReadAuthToken
+		}
+	}
+	if c.ReadMaxOpenConns > 0 {
+		read.MaxOpenConns = c.ReadMaxOpenConns
+	}
+	return &read
+}
+
+// Pragmas returns the PRAGMA statements to run on each new local connection. Foreign keys are
+// always enforced, so deletes cascade as the schema declares.
+func (c *Config) Pragmas() []string {
+	pragmas := []string{"PRAGMA foreign_keys = ON"}
+	if c.JournalMode != "" {
+		pragmas = append(pragmas, "PRAGMA journal_mode = "+strings.ToUpper(c.JournalMode))
+	}
+	if c.BusyTimeout > 0 {
+		pragmas = append(pragmas, fmt.Sprintf("PRAGMA busy_timeout = %d", c.BusyTimeout.Milliseconds()))
+	}
+	if c.Synchronous != "" {
+		pragmas = append(pragmas, "PRAGMA synchronous = "+strings.ToUpper(c.Synchronous))
+	}
+	if c.CacheSize != 0 {
+		pragmas = append(pragmas, fmt.Sprintf("PRAGMA cache_size = %d", c.CacheSize))
+	}
+	if c.MmapSize > 0 {
+		pragmas = append(pragmas, fmt.Sprintf("PRAGMA mmap_size = %d", c.MmapSize))
+	}
+	return pragmas
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func intFrom(getenv func(string) string, key string, defaultValue int) (int, error) {
+	value := getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s: %w", ErrInvalidPoolSetting, key, err)
+	}
+	return parsed, nil
+}
+
+func durationFrom(getenv func(string) string, key string, defaultValue time.Duration) (time.Duration, error) {
+	value := getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s: %w", ErrInvalidPoolSetting, key, err)
+	}
+	return parsed, nil
+}
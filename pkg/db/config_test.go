@@ -0,0 +1,222 @@
+package db
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("TURSO_DATABASE_URL", "file:/tmp/ike.db")
+	t.Setenv("TURSO_AUTH_TOKEN", "")
+	t.Setenv("DB_MAX_OPEN_CONNS", "4")
+	t.Setenv("DB_CONN_MAX_LIFETIME", "1m")
+	t.Setenv("SQLITE_BUSY_TIMEOUT", "10s")
+	t.Setenv("SQLITE_SYNCHRONOUS", "full")
+	t.Setenv("SQLITE_CACHE_SIZE", "-32768")
+
+	cfg, err := ConfigFromEnv()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if cfg.MaxOpenConns != 4 {
+		t.Errorf("Expected MaxOpenConns 4, got %d", cfg.MaxOpenConns)
+	}
+	if cfg.ConnMaxLifetime != time.Minute {
+		t.Errorf("Expected ConnMaxLifetime 1m, got %v", cfg.ConnMaxLifetime)
+	}
+	if cfg.BusyTimeout != 10*time.Second {
+		t.Errorf("Expected BusyTimeout 10s, got %v", cfg.BusyTimeout)
+	}
+	if cfg.CacheSize != -32768 {
+		t.Errorf("Expected CacheSize -32768, got %d", cfg.CacheSize)
+	}
+	if cfg.MmapSize != defaultMmapSize {
+		t.Errorf("Expected default MmapSize %d, got %d", defaultMmapSize, cfg.MmapSize)
+	}
+	if cfg.JournalMode != defaultJournalMode {
+		t.Errorf("Expected default journal mode %s, got %s", defaultJournalMode, cfg.JournalMode)
+	}
+	if !cfg.IsLocal() {
+		t.Error("Expected file: URL to be local")
+	}
+}
+
+func TestConfigFromEnv_InvalidValues(t *testing.T) {
+	tests := []struct {
+		name        string
+		key         string
+		value       string
+		expectedErr error
+	}{
+		{name: "non numeric pool size", key: "DB_MAX_OPEN_CONNS", value: "many", expectedErr: ErrInvalidPoolSetting},
+		{name: "invalid duration", key: "SQLITE_BUSY_TIMEOUT", value: "soon", expectedErr: ErrInvalidPoolSetting},
+		{name: "invalid journal mode", key: "SQLITE_JOURNAL_MODE", value: "FAST", expectedErr: ErrInvalidJournalMode},
+		{name: "invalid synchronous", key: "SQLITE_SYNCHRONOUS", value: "SOMETIMES", expectedErr: ErrInvalidSynchronous},
+		{name: "non numeric cache size", key: "SQLITE_CACHE_SIZE", value: "big", expectedErr: ErrInvalidPoolSetting},
+		{name: "negative mmap size", key: "SQLITE_MMAP_SIZE", value: "-1", expectedErr: ErrInvalidMmapSize},
+		{name: "non numeric mmap size", key: "SQLITE_MMAP_SIZE", value: "256MB", expectedErr: ErrInvalidMmapSize},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(tt.key, tt.value)
+			if _, err := ConfigFromEnv(); !errors.Is(err, tt.expectedErr) {
+				t.Errorf("Expected %v, got %v", tt.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestConfig_Pragmas(t *testing.T) {
+	cfg := DefaultConfig()
+	pragmas := cfg.Pragmas()
+
+	expected := []string{
+		"PRAGMA foreign_keys = ON",
+		"PRAGMA journal_mode = WAL",
+		"PRAGMA busy_timeout = 5000",
+		"PRAGMA synchronous = NORMAL",
+		"PRAGMA cache_size = -65536",
+		"PRAGMA mmap_size = 268435456",
+	}
+	if len(pragmas) != len(expected) {
+		t.Fatalf("Expected %d pragmas, got %d: %v", len(expected), len(pragmas), pragmas)
+	}
+	for i := range expected {
+		if pragmas[i] != expected[i] {
+			t.Errorf("Expected %q, got %q", expected[i], pragmas[i])
+		}
+	}
+
+	if pragmas := (&Config{}).Pragmas(); len(pragmas) != 1 || pragmas[0] != "PRAGMA foreign_keys = ON" {
+		t.Errorf("Expected only foreign key enforcement for empty config, got %v", pragmas)
+	}
+}
+
+func TestNewConnectionWithConfig_Validation(t *testing.T) {
+	if _, err := NewConnectionWithConfig(&Config{}); !errors.Is(err, ErrDatabaseURLRequired) {
+		t.Errorf("Expected ErrDatabaseURLRequired, got %v", err)
+	}
+
+	cfg := &Config{URL: "libsql://example.turso.io"}
+	if _, err := NewConnectionWithConfig(cfg); !errors.Is(err, ErrAuthTokenRequired) {
+		t.Errorf("Expected ErrAuthTokenRequired, got %v", err)
+	}
+}
+
+func TestNewConnectionWithConfig_Local(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.URL = "file:" + filepath.Join(t.TempDir(), "ike.db")
+	cfg.SeparateReads = true
+
+	database, err := NewConnectionWithConfig(cfg)
+	if err != nil {
+		t.Fatalf("Failed to open local database: %v", err)
+	}
+	defer database.Close()
+
+	pragmas := []struct {
+		pragma   string
+		expected string
+	}{
+		{"foreign_keys", "1"},
+		{"journal_mode", "wal"},
+		{"busy_timeout", "5000"},
+		{"synchronous", "1"},
+		{"cache_size", "-65536"},
+		{"mmap_size", "268435456"},
+	}
+	for _, tt := range pragmas {
+		var value string
+		if err := database.QueryRow("PRAGMA " + tt.pragma).Scan(&value); err != nil {
+			t.Fatalf("Failed to read %s: %v", tt.pragma, err)
+		}
+		if value != tt.expected {
+			t.Errorf("Expected %s %s, got %s", tt.pragma, tt.expected, value)
+		}
+	}
+
+	if _, err := database.Exec("CREATE TABLE notes (body TEXT)"); err != nil {
+		t.Fatalf("Failed to write through the primary handle: %v", err)
+	}
+	if _, err := database.Reader().Exec("INSERT INTO notes (body) VALUES ('hello')"); err == nil {
+		t.Error("Expected the read handle to reject writes")
+	}
+}
+
+func TestConfig_ReadConfig(t *testing.T) {
+	tests := []struct {
+		name          string
+		cfg           Config
+		expectHandle  bool
+		expectedURL   string
+		expectedToken string
+		expectedConns int
+		description   string
+	}{
+		{
+			name:          "no read handle",
+			cfg:           Config{URL: "libsql://primary", AuthToken: "write"},
+			expectHandle:  false,
+			expectedURL:   "libsql://primary",
+			expectedToken: "write",
+			description:   "should read through the primary when nothing is configured",
+		},
+		{
+			name:          "replica",
+			cfg:           Config{URL: "libsql://primary", AuthToken: "write", ReadURL: "libsql://replica", ReadAuthToken: "read"},
+			expectHandle:  true,
+			expectedURL:   "libsql://replica",
+			expectedToken: "read",
+			description:   "should use the replica URL and token for reads",
+		},
+		{
+			name:          "replica sharing token",
+			cfg:           Config{URL: "libsql://primary", AuthToken: "write", ReadURL: "libsql://replica"},
+			expectHandle:  true,
+			expectedURL:   "libsql://replica",
+			expectedToken: "write",
+			description:   "should fall back to the primary token",
+		},
+		{
+			name:          "separate local pool",
+			cfg:           Config{URL: "file:ike.db", SeparateReads: true, MaxOpenConns: 2, ReadMaxOpenConns: 8},
+			expectHandle:  true,
+			expectedURL:   "file:ike.db",
+			expectedConns: 8,
+			description:   "should open a second pool on the same file with the read pool size",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.cfg.HasReadHandle() != tt.expectHandle {
+				t.Errorf("Expected HasReadHandle %v, got %v", tt.expectHandle, tt.cfg.HasReadHandle())
+			}
+
+			read := tt.cfg.ReadConfig()
+			if read.URL != tt.expectedURL {
+				t.Errorf("Expected read URL %s, got %s", tt.expectedURL, read.URL)
+			}
+			if read.AuthToken != tt.expectedToken {
+				t.Errorf("Expected read token %s, got %s", tt.expectedToken, read.AuthToken)
+			}
+			if read.MaxOpenConns != tt.expectedConns && tt.expectedConns != 0 {
+				t.Errorf("Expected read pool size %d, got %d", tt.expectedConns, read.MaxOpenConns)
+			}
+			if read.HasReadHandle() {
+				t.Error("Expected read config not to have a read handle of its own")
+			}
+		})
+	}
+}
+
+func TestDB_Reader(t *testing.T) {
+	database := &DB{}
+	if database.Reader() != database.DB {
+		t.Error("Expected Reader to fall back to the primary handle")
+	}
+}
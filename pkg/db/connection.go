@@ -1,56 +1,168 @@
 package db
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
 	"errors"
-	"os"
 	"strings"
 
+	"github.com/code-sleuth/ike-go/pkg/dialect"
+	"github.com/code-sleuth/ike-go/pkg/stmtcache"
 	"github.com/code-sleuth/ike-go/pkg/util"
 
 	"github.com/rs/zerolog"
 	"github.com/tursodatabase/libsql-client-go/libsql"
+	// libsql opens local file: databases with the driver registered as "sqlite"
+	_ "modernc.org/sqlite"
 )
 
 var (
 	ErrDatabaseURLRequired = errors.New("TURSO_DATABASE_URL environment variable is required")
 	ErrAuthTokenRequired   = errors.New("TURSO_AUTH_TOKEN environment variable is required")
+	ErrPragmasUnsupported  = errors.New("database connection does not support pragmas")
 )
 
 type DB struct {
 	*sql.DB
+	reader  *sql.DB
+	dialect dialect.Dialect
 }
 
+// Dialect returns the SQL dialect of the connected database, defaulting to SQLite.
+func (db *DB) Dialect() dialect.Dialect {
+	if db.dialect == nil {
+		return dialect.SQLite
+	}
+	return db.dialect
+}
+
+// Rebind rewrites a ?-placeholder query for the connected database's dialect.
+func (db *DB) Rebind(query string) string {
+	return db.Dialect().Rebind(query)
+}
+
+// NewConnection opens a database connection configured from the environment.
 func NewConnection() (*DB, error) {
-	dbURL := os.Getenv("TURSO_DATABASE_URL")
 	logger := util.NewLogger(zerolog.ErrorLevel)
-	if strings.EqualFold(dbURL, "") {
+	cfg, err := ConfigFromEnv()
+	if err != nil {
+		logger.Err(err).Msg("invalid database configuration")
+		return nil, err
+	}
+	return NewConnectionWithConfig(cfg)
+}
+
+// NewConnectionWithConfig opens a database connection using the given configuration. When the
+// configuration has a read handle, a second pool is opened for reads; see Reader.
+func NewConnectionWithConfig(cfg *Config) (*DB, error) {
+	logger := util.NewLogger(zerolog.ErrorLevel)
+	if strings.EqualFold(cfg.URL, "") {
 		logger.Error().Msg("TURSO_DATABASE_URL env variable not set")
 		return nil, ErrDatabaseURLRequired
 	}
 
-	authToken := os.Getenv("TURSO_AUTH_TOKEN")
-	if strings.EqualFold(authToken, "") {
+	if err := cfg.Validate(); err != nil {
+		logger.Err(err).Msg("invalid database configuration")
+		return nil, err
+	}
+
+	sqlDialect, err := dialect.ForURL(cfg.URL)
+	if err != nil {
+		logger.Err(err).Msg("unsupported database URL")
+		return nil, err
+	}
+
+	writeCfg := cfg
+	if cfg.HasReadHandle() && cfg.IsLocal() {
+		// SQLite allows one writer at a time; funnel writes through a single connection
+		single := *cfg
+		single.MaxOpenConns = 1
+		writeCfg = &single
+	}
+
+	db, err := open(writeCfg, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var reader *sql.DB
+	if cfg.HasReadHandle() {
+		readCfg := cfg.ReadConfig()
+		var readOnly []string
+		if readCfg.IsLocal() {
+			readOnly = []string{"PRAGMA query_only = ON"}
+		}
+		reader, err = open(readCfg, readOnly)
+		if err != nil {
+			_ = db.Close()
+			return nil, err
+		}
+	}
+
+	return &DB{DB: db, reader: reader, dialect: sqlDialect}, nil
+}
+
+// open connects to cfg.URL and configures the pool, running the configured pragmas plus
+// extraPragmas on every new local connection. Statements on remote connections that find the
+// database locked are retried for up to cfg.BusyTimeout.
+func open(cfg *Config, extraPragmas []string) (*sql.DB, error) {
+	logger := util.NewLogger(zerolog.ErrorLevel)
+
+	// Local SQLite files don't need a token
+	if strings.EqualFold(cfg.AuthToken, "") && !cfg.IsLocal() {
 		logger.Error().Msg("TURSO_AUTH_TOKEN env variable not set")
 		return nil, ErrAuthTokenRequired
 	}
 
-	connector, err := libsql.NewConnector(dbURL, libsql.WithAuthToken(authToken))
+	var options []libsql.Option
+	if !cfg.IsLocal() {
+		options = append(options, libsql.WithAuthToken(cfg.AuthToken))
+	}
+
+	connector, err := libsql.NewConnector(cfg.URL, options...)
 	if err != nil {
 		logger.Err(err).Msg("failed to create connector")
 		return nil, err
 	}
 
+	if cfg.IsLocal() {
+		connector = &pragmaConnector{Connector: connector, pragmas: append(cfg.Pragmas(), extraPragmas...)}
+	} else if cfg.BusyTimeout > 0 {
+		connector = &busyConnector{Connector: connector, timeout: cfg.BusyTimeout}
+	}
+
 	db := sql.OpenDB(connector)
+	configurePool(db, cfg)
+
 	if err := db.Ping(); err != nil {
 		logger.Err(err).Msg("failed to ping database")
+		_ = db.Close()
 		return nil, err
 	}
 
-	return &DB{DB: db}, nil
+	return db, nil
+}
+
+// Reader returns the handle to use for read-only queries such as search and listing. It is the
+// separate read pool when one is configured and the primary handle otherwise. Reads that must see
+// the caller's own recent writes should use the primary handle.
+func (db *DB) Reader() *sql.DB {
+	if db.reader != nil {
+		return db.reader
+	}
+	return db.DB
 }
 
 func (db *DB) Close() error {
+	stmtcache.Forget(db.DB)
+	if db.reader != nil {
+		stmtcache.Forget(db.reader)
+		if err := db.reader.Close(); err != nil {
+			_ = db.DB.Close()
+			return err
+		}
+	}
 	return db.DB.Close()
 }
 
@@ -62,3 +174,49 @@ func Connect() (*sql.DB, error) {
 	}
 	return dbWrapper.DB, nil
 }
+
+func configurePool(db *sql.DB, cfg *Config) {
+	if cfg.MaxOpenConns > 0 {
+		db.SetMaxOpenConns(cfg.MaxOpenConns)
+	}
+	if cfg.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(cfg.MaxIdleConns)
+	}
+	if cfg.ConnMaxLifetime > 0 {
+		db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	}
+	if cfg.ConnMaxIdleTime > 0 {
+		db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+	}
+}
+
+// pragmaConnector runs the configured pragmas on every connection the pool opens,
+// since SQLite pragmas are per-connection state.
+type pragmaConnector struct {
+	driver.Connector
+	pragmas []string
+}
+
+func (c *pragmaConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(c.pragmas) == 0 {
+		return conn, nil
+	}
+
+	execer, ok := conn.(driver.ExecerContext)
+	if !ok {
+		_ = conn.Close()
+		return nil, ErrPragmasUnsupported
+	}
+	for _, pragma := range c.pragmas {
+		if _, err := execer.ExecContext(ctx, pragma, nil); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+	}
+
+	return conn, nil
+}
@@ -0,0 +1,170 @@
+package dialect
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrUnknownDialect = errors.New("unknown SQL dialect")
+
+// Dialect hides the SQL differences between supported databases. Queries are written with
+// ?-style placeholders and passed through Rebind before they are executed.
+type Dialect interface {
+	// Name returns the dialect's identifier, e.g. "sqlite".
+	Name() string
+	// Rebind rewrites ? placeholders into the dialect's bind syntax.
+	Rebind(query string) string
+	// Upsert renders an INSERT into table that updates updateColumns when a row with the same
+	// conflictColumns already exists.
+	Upsert(table string, columns, conflictColumns, updateColumns []string) string
+	// InsertIgnore renders an INSERT into table that skips rows colliding on conflictColumns.
+	InsertIgnore(table string, columns, conflictColumns []string) string
+	// FormatTime renders t for storage in a timestamp column.
+	FormatTime(t time.Time) string
+	// Now returns the SQL expression for the current timestamp.
+	Now() string
+}
+
+var (
+	SQLite   Dialect = sqliteDialect{}
+	Postgres Dialect = postgresDialect{}
+	MySQL    Dialect = mysqlDialect{}
+)
+
+// ForName returns the dialect with the given name.
+func ForName(name string) (Dialect, error) {
+	switch strings.ToLower(name) {
+	case "sqlite", "sqlite3", "libsql", "turso":
+		return SQLite, nil
+	case "postgres", "postgresql", "pgx":
+		return Postgres, nil
+	case "mysql", "mariadb":
+		return MySQL, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownDialect, name)
+	}
+}
+
+// ForURL infers the dialect from a database URL's scheme. libsql, http(s), and file URLs
+// are all served by SQLite.
+func ForURL(url string) (Dialect, error) {
+	scheme, _, found := strings.Cut(url, ":")
+	if !found {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownDialect, url)
+	}
+
+	switch strings.ToLower(scheme) {
+	case "libsql", "http", "https", "ws", "wss", "file":
+		return SQLite, nil
+	default:
+		return ForName(scheme)
+	}
+}
+
+type sqliteDialect struct{}
+
+func (sqliteDialect) Name() string { return "sqlite" }
+
+func (sqliteDialect) Rebind(query string) string { return query }
+
+func (sqliteDialect) Upsert(table string, columns, conflictColumns, updateColumns []string) string {
+	return insert("INSERT INTO", table, columns) + onConflictUpdate(conflictColumns, updateColumns)
+}
+
+func (sqliteDialect) InsertIgnore(table string, columns, conflictColumns []string) string {
+	return insert("INSERT INTO", table, columns) + onConflictNothing(conflictColumns)
+}
+
+func (sqliteDialect) FormatTime(t time.Time) string { return t.UTC().Format(time.RFC3339) }
+
+func (sqliteDialect) Now() string { return "strftime('%Y-%m-%dT%H:%M:%SZ', 'now')" }
+
+type postgresDialect struct{}
+
+func (postgresDialect) Name() string { return "postgres" }
+
+func (postgresDialect) Rebind(query string) string {
+	n := 0
+	return replacePlaceholders(query, func() string {
+		n++
+		return "$" + strconv.Itoa(n)
+	})
+}
+
+func (postgresDialect) Upsert(table string, columns, conflictColumns, updateColumns []string) string {
+	return insert("INSERT INTO", table, columns) + onConflictUpdate(conflictColumns, updateColumns)
+}
+
+func (postgresDialect) InsertIgnore(table string, columns, conflictColumns []string) string {
+	return insert("INSERT INTO", table, columns) + onConflictNothing(conflictColumns)
+}
+
+func (postgresDialect) FormatTime(t time.Time) string { return t.UTC().Format(time.RFC3339Nano) }
+
+func (postgresDialect) Now() string { return "CURRENT_TIMESTAMP" }
+
+type mysqlDialect struct{}
+
+func (mysqlDialect) Name() string { return "mysql" }
+
+func (mysqlDialect) Rebind(query string) string { return query }
+
+func (mysqlDialect) Upsert(table string, columns, _, updateColumns []string) string {
+	assignments := make([]string, len(updateColumns))
+	for i, column := range updateColumns {
+		assignments[i] = column + " = VALUES(" + column + ")"
+	}
+	return insert("INSERT INTO", table, columns) + " ON DUPLICATE KEY UPDATE " + strings.Join(assignments, ", ")
+}
+
+func (mysqlDialect) InsertIgnore(table string, columns, _ []string) string {
+	return insert("INSERT IGNORE INTO", table, columns)
+}
+
+func (mysqlDialect) FormatTime(t time.Time) string { return t.UTC().Format("2006-01-02 15:04:05") }
+
+func (mysqlDialect) Now() string { return "UTC_TIMESTAMP()" }
+
+func insert(verb, table string, columns []string) string {
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ")
+	return fmt.Sprintf("%s %s (%s) VALUES (%s)", verb, table, strings.Join(columns, ", "), placeholders)
+}
+
+func onConflictUpdate(conflictColumns, updateColumns []string) string {
+	assignments := make([]string, len(updateColumns))
+	for i, column := range updateColumns {
+		assignments[i] = column + " = excluded." + column
+	}
+	return fmt.Sprintf(" ON CONFLICT (%s) DO UPDATE SET %s",
+		strings.Join(conflictColumns, ", "), strings.Join(assignments, ", "))
+}
+
+func onConflictNothing(conflictColumns []string) string {
+	return fmt.Sprintf(" ON CONFLICT (%s) DO NOTHING", strings.Join(conflictColumns, ", "))
+}
+
+// replacePlaceholders swaps each ? outside of quoted strings and identifiers for next().
+func replacePlaceholders(query string, next func() string) string {
+	var b strings.Builder
+	b.Grow(len(query) + 16)
+
+	var quote rune
+	for _, r := range query {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == '?':
+			b.WriteString(next())
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
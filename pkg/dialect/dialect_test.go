@@ -0,0 +1,129 @@
+package dialect
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestForURL(t *testing.T) {
+	tests := []struct {
+		name        string
+		url         string
+		expected    Dialect
+		expectedErr error
+		description string
+	}{
+		{
+			name:        "turso",
+			url:         "libsql://ike-go.turso.io",
+			expected:    SQLite,
+			description: "should treat libsql URLs as SQLite",
+		},
+		{
+			name:        "local file",
+			url:         "file:ike.db",
+			expected:    SQLite,
+			description: "should treat file URLs as SQLite",
+		},
+		{
+			name:        "postgres",
+			url:         "postgres://user@localhost/ike",
+			expected:    Postgres,
+			description: "should recognise Postgres URLs",
+		},
+		{
+			name:        "mysql",
+			url:         "mysql://user@localhost/ike",
+			expected:    MySQL,
+			description: "should recognise MySQL URLs",
+		},
+		{
+			name:        "unknown scheme",
+			url:         "oracle://localhost",
+			expectedErr: ErrUnknownDialect,
+			description: "should reject unsupported schemes",
+		},
+		{
+			name:        "no scheme",
+			url:         "ike.db",
+			expectedErr: ErrUnknownDialect,
+			description: "should reject URLs without a scheme",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := ForURL(tt.url)
+			if tt.expectedErr != nil {
+				if !errors.Is(err, tt.expectedErr) {
+					t.Errorf("Expected error %v, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if d != tt.expected {
+				t.Errorf("Expected dialect %s, got %s", tt.expected.Name(), d.Name())
+			}
+		})
+	}
+}
+
+func TestRebind(t *testing.T) {
+	query := `SELECT id FROM sources WHERE host = ? AND path <> '?' AND format = ?`
+
+	if got := SQLite.Rebind(query); got != query {
+		t.Errorf("Expected SQLite query unchanged, got %s", got)
+	}
+
+	expected := `SELECT id FROM sources WHERE host = $1 AND path <> '?' AND format = $2`
+	if got := Postgres.Rebind(query); got != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+}
+
+func TestUpsert(t *testing.T) {
+	columns := []string{"id", "document_id", "key", "meta"}
+	conflict := []string{"document_id", "key"}
+	update := []string{"meta"}
+
+	tests := []struct {
+		name     string
+		dialect  Dialect
+		expected string
+	}{
+		{
+			name:    "sqlite",
+			dialect: SQLite,
+			expected: "INSERT INTO document_meta (id, document_id, key, meta) VALUES (?, ?, ?, ?)" +
+				" ON CONFLICT (document_id, key) DO UPDATE SET meta = excluded.meta",
+		},
+		{
+			name:    "mysql",
+			dialect: MySQL,
+			expected: "INSERT INTO document_meta (id, document_id, key, meta) VALUES (?, ?, ?, ?)" +
+				" ON DUPLICATE KEY UPDATE meta = VALUES(meta)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.dialect.Upsert("document_meta", columns, conflict, update); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestFormatTime(t *testing.T) {
+	ts := time.Date(2024, 5, 6, 7, 8, 9, 0, time.FixedZone("EST", -5*3600))
+
+	if got := SQLite.FormatTime(ts); got != "2024-05-06T12:08:09Z" {
+		t.Errorf("Expected UTC RFC3339 timestamp, got %s", got)
+	}
+	if got := MySQL.FormatTime(ts); got != "2024-05-06 12:08:09" {
+		t.Errorf("Expected MySQL DATETIME timestamp, got %s", got)
+	}
+}
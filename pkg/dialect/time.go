@@ -0,0 +1,32 @@
+package dialect
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrInvalidTime = errors.New("unsupported timestamp format")
+
+// timeLayouts are the timestamp formats found in the database: RFC 3339 from FormatTime and the
+// importers, SQLite's datetime() and MySQL's space-separated form, and PostgreSQL's text output,
+// whose offset may be hours only. Fractional seconds are optional in each.
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999Z07",
+	"2006-01-02 15:04:05.999999999",
+}
+
+// ParseTime parses a stored timestamp in any format a supported database or an older version of
+// ike-go wrote, returning it in UTC. Timestamps without an offset are taken to be UTC, as every
+// writer stores them.
+func ParseTime(value string) (time.Time, error) {
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%w: %q", ErrInvalidTime, value)
+}
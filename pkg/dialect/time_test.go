@@ -0,0 +1,45 @@
+package dialect
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseTime(t *testing.T) {
+	expected := time.Date(2024, 5, 6, 12, 8, 9, 0, time.UTC)
+	values := []string{
+		"2024-05-06T12:08:09Z",
+		"2024-05-06T07:08:09-05:00",
+		"2024-05-06T12:08:09",
+		"2024-05-06 12:08:09",
+		"2024-05-06 14:08:09+02",
+		"2024-05-06 12:08:09+00:00",
+	}
+	for _, value := range values {
+		got, err := ParseTime(value)
+		if err != nil {
+			t.Errorf("Unexpected error parsing %q: %v", value, err)
+			continue
+		}
+		if !got.Equal(expected) || got.Location() != time.UTC {
+			t.Errorf("Expected %v parsing %q, got %v", expected, value, got)
+		}
+	}
+
+	got, err := ParseTime("2024-05-06 12:08:09.123456+00")
+	if err != nil || got.Nanosecond() != 123456000 {
+		t.Errorf("Expected fractional seconds to be kept, got %v (%v)", got, err)
+	}
+
+	for _, d := range []Dialect{SQLite, Postgres, MySQL} {
+		got, err := ParseTime(d.FormatTime(expected))
+		if err != nil || !got.Equal(expected) {
+			t.Errorf("Expected %s timestamps to round-trip, got %v (%v)", d.Name(), got, err)
+		}
+	}
+
+	if _, err := ParseTime("yesterday"); !errors.Is(err, ErrInvalidTime) {
+		t.Errorf("Expected ErrInvalidTime, got %v", err)
+	}
+}
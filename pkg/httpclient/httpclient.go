@@ -0,0 +1,206 @@
+// Package httpclient builds the HTTP clients importers, embedders, and other outbound callers
+// use, so proxy, TLS, User-Agent, timeout, and response-size settings apply to all of them.
+package httpclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultUserAgent is sent with requests that don't set their own User-Agent.
+const DefaultUserAgent = "ike-go"
+
+var (
+	ErrInvalidConfig    = errors.New("invalid HTTP client configuration")
+	ErrResponseTooLarge = errors.New("response body exceeds the maximum size")
+	errNoCertificates   = errors.New("no certificates found")
+)
+
+// The configuration set with SetDefault, and the transport built from it.
+var (
+	defaultMu        sync.RWMutex
+	defaultConfig    Config
+	defaultTransport = newTransport(Config{})
+)
+
+// Config controls the clients New returns. The zero Config uses Go's defaults, including the
+// HTTP_PROXY, HTTPS_PROXY, and NO_PROXY environment variables.
+type Config struct {
+	// ProxyURL, when set, is used for every request instead of the proxy environment variables
+	ProxyURL *url.URL
+	// RootCAs, when set, replaces the system certificate pool, for servers signed by a private CA
+	RootCAs *x509.CertPool
+	// InsecureSkipVerify disables certificate verification; only for testing against servers
+	// with self-signed certificates
+	InsecureSkipVerify bool
+	// UserAgent is sent with requests that don't set their own; DefaultUserAgent when empty
+	UserAgent string
+	// Timeout, when set, overrides the timeout each caller would otherwise use
+	Timeout time.Duration
+	// MaxResponseSize, when set, fails reads of response bodies larger than it with
+	// ErrResponseTooLarge
+	MaxResponseSize int64
+}
+
+// ConfigFromEnv reads IKE_HTTP_PROXY, IKE_HTTP_CA_FILE (a PEM file of CA certificates),
+// IKE_HTTP_INSECURE_SKIP_VERIFY, IKE_HTTP_USER_AGENT, IKE_HTTP_TIMEOUT (a duration such as 45s),
+// and IKE_HTTP_MAX_RESPONSE_SIZE (bytes).
+func ConfigFromEnv() (Config, error) {
+	return ConfigFrom(os.Getenv)
+}
+
+// ConfigFrom builds a Config like ConfigFromEnv, reading each variable with getenv.
+func ConfigFrom(getenv func(string) string) (Config, error) {
+	config := Config{UserAgent: getenv("IKE_HTTP_USER_AGENT")}
+
+	if value := getenv("IKE_HTTP_PROXY"); value != "" {
+		proxyURL, err := url.Parse(value)
+		if err != nil || proxyURL.Host == "" {
+			return Config{}, fmt.Errorf("%w: IKE_HTTP_PROXY %q is not a URL", ErrInvalidConfig, value)
+		}
+		config.ProxyURL = proxyURL
+	}
+	if path := getenv("IKE_HTTP_CA_FILE"); path != "" {
+		pool, err := loadCertPool(path)
+		if err != nil {
+			return Config{}, fmt.Errorf("%w: IKE_HTTP_CA_FILE: %w", ErrInvalidConfig, err)
+		}
+		config.RootCAs = pool
+	}
+	if value := getenv("IKE_HTTP_INSECURE_SKIP_VERIFY"); value != "" {
+		insecure, err := strconv.ParseBool(value)
+		if err != nil {
+			return Config{}, fmt.Errorf("%w: IKE_HTTP_INSECURE_SKIP_VERIFY %q", ErrInvalidConfig, value)
+		}
+		config.InsecureSkipVerify = insecure
+	}
+	if value := getenv("IKE_HTTP_TIMEOUT"); value != "" {
+		timeout, err := time.ParseDuration(value)
+		if err != nil || timeout <= 0 {
+			return Config{}, fmt.Errorf("%w: IKE_HTTP_TIMEOUT %q", ErrInvalidConfig, value)
+		}
+		config.Timeout = timeout
+	}
+	if value := getenv("IKE_HTTP_MAX_RESPONSE_SIZE"); value != "" {
+		size, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || size <= 0 {
+			return Config{}, fmt.Errorf("%w: IKE_HTTP_MAX_RESPONSE_SIZE %q", ErrInvalidConfig, value)
+		}
+		config.MaxResponseSize = size
+	}
+	return config, nil
+}
+
+// loadCertPool reads the PEM-encoded certificates in the file at path.
+func loadCertPool(path string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("%w in %s", errNoCertificates, path)
+	}
+	return pool, nil
+}
+
+// SetDefault sets the configuration of the clients New returns from then on. Clients share one
+// transport, so connections are reused across callers.
+func SetDefault(config Config) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultConfig = config
+	defaultTransport = newTransport(config)
+}
+
+// New returns a client using the default configuration, with timeout unless the configuration
+// sets its own.
+func New(timeout time.Duration) *http.Client {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	if defaultConfig.Timeout > 0 {
+		timeout = defaultConfig.Timeout
+	}
+	return &http.Client{Timeout: timeout, Transport: defaultTransport}
+}
+
+// newTransport returns the transport for config: a clone of http.DefaultTransport with its proxy
+// and TLS settings, wrapped to add the User-Agent and enforce the response size.
+func newTransport(config Config) http.RoundTripper {
+	base, _ := http.DefaultTransport.(*http.Transport)
+	transport := base.Clone()
+	if config.ProxyURL != nil {
+		transport.Proxy = http.ProxyURL(config.ProxyURL)
+	}
+	if config.RootCAs != nil || config.InsecureSkipVerify {
+		transport.TLSClientConfig = &tls.Config{
+			MinVersion:         tls.VersionTLS12,
+			RootCAs:            config.RootCAs,
+			InsecureSkipVerify: config.InsecureSkipVerify, // #nosec G402 -- opted into for testing
+		}
+	}
+
+	userAgent := config.UserAgent
+	if userAgent == "" {
+		userAgent = DefaultUserAgent
+	}
+	return &transportWrapper{next: transport, userAgent: userAgent, maxResponseSize: config.MaxResponseSize}
+}
+
+// transportWrapper adds the User-Agent to requests and limits the size of response bodies.
+type transportWrapper struct {
+	next            http.RoundTripper
+	userAgent       string
+	maxResponseSize int64
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *transportWrapper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("User-Agent") == "" {
+		// RoundTrippers must not modify the caller's request
+		req = req.Clone(req.Context())
+		req.Header.Set("User-Agent", t.userAgent)
+	}
+	resp, err := t.next.RoundTrip(req)
+	if err != nil || t.maxResponseSize <= 0 {
+		return resp, err
+	}
+	resp.Body = &limitedBody{body: resp.Body, remaining: t.maxResponseSize}
+	return resp, nil
+}
+
+// limitedBody fails reads past the maximum response size instead of truncating the body.
+type limitedBody struct {
+	body      io.ReadCloser
+	remaining int64
+}
+
+func (l *limitedBody) Read(p []byte) (int, error) {
+	if l.remaining <= 0 {
+		// Only a body that goes on past the limit is too large
+		var probe [1]byte
+		if n, err := l.body.Read(probe[:]); n == 0 {
+			return 0, err
+		}
+		return 0, ErrResponseTooLarge
+	}
+	if int64(len(p)) > l.remaining {
+		p = p[:l.remaining]
+	}
+	n, err := l.body.Read(p)
+	l.remaining -= int64(n)
+	return n, err
+}
+
+func (l *limitedBody) Close() error {
+	return l.body.Close()
+}
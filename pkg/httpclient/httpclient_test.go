@@ -0,0 +1,113 @@
+package httpclient
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("IKE_HTTP_PROXY", "http://proxy.internal:3128")
+	t.Setenv("IKE_HTTP_USER_AGENT", "docs-indexer/1.0")
+	t.Setenv("IKE_HTTP_TIMEOUT", "45s")
+	t.Setenv("IKE_HTTP_MAX_RESPONSE_SIZE", "1048576")
+
+	config, err := ConfigFromEnv()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if config.ProxyURL.Host != "proxy.internal:3128" || config.UserAgent != "docs-indexer/1.0" ||
+		config.Timeout != 45*time.Second || config.MaxResponseSize != 1048576 {
+		t.Errorf("Unexpected config %+v", config)
+	}
+
+	for name, value := range map[string]string{
+		"IKE_HTTP_PROXY":                "proxy.internal",
+		"IKE_HTTP_CA_FILE":              "/nonexistent/ca.pem",
+		"IKE_HTTP_INSECURE_SKIP_VERIFY": "maybe",
+		"IKE_HTTP_TIMEOUT":              "45",
+		"IKE_HTTP_MAX_RESPONSE_SIZE":    "-1",
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(name, value)
+			if _, err := ConfigFromEnv(); !errors.Is(err, ErrInvalidConfig) {
+				t.Errorf("Expected ErrInvalidConfig for %s=%q, got %v", name, value, err)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	var userAgents []string
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgents = append(userAgents, r.UserAgent())
+		_, _ = w.Write([]byte(strings.Repeat("x", 100)))
+	}))
+	defer testServer.Close()
+	t.Cleanup(func() { SetDefault(Config{}) })
+
+	SetDefault(Config{MaxResponseSize: 100})
+	client := New(time.Minute)
+	if client.Timeout != time.Minute {
+		t.Errorf("Expected the caller's timeout, got %v", client.Timeout)
+	}
+	resp, err := client.Get(testServer.URL)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// A body of exactly the maximum size is read in full
+	if body, err := io.ReadAll(resp.Body); err != nil || len(body) != 100 {
+		t.Errorf("Expected the whole body, got %d bytes, %v", len(body), err)
+	}
+	resp.Body.Close()
+
+	SetDefault(Config{MaxResponseSize: 10, UserAgent: "docs-indexer/1.0", Timeout: time.Second})
+	client = New(time.Minute)
+	if client.Timeout != time.Second {
+		t.Errorf("Expected the configured timeout to win, got %v", client.Timeout)
+	}
+	req, _ := http.NewRequest(http.MethodGet, testServer.URL, nil)
+	req.Header.Set("User-Agent", "custom")
+	for _, send := range []func() (*http.Response, error){
+		func() (*http.Response, error) { return client.Get(testServer.URL) },
+		func() (*http.Response, error) { return client.Do(req) },
+	} {
+		resp, err := send()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, err := io.ReadAll(resp.Body); !errors.Is(err, ErrResponseTooLarge) {
+			t.Errorf("Expected ErrResponseTooLarge, got %v", err)
+		}
+		resp.Body.Close()
+	}
+	if len(userAgents) != 3 || userAgents[0] != DefaultUserAgent || userAgents[1] != "docs-indexer/1.0" ||
+		userAgents[2] != "custom" {
+		t.Errorf("Unexpected User-Agents %v", userAgents)
+	}
+}
+
+func TestNew_Proxy(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+	}))
+	defer proxy.Close()
+	t.Cleanup(func() { SetDefault(Config{}) })
+
+	proxyURL, _ := url.Parse(proxy.URL)
+	SetDefault(Config{ProxyURL: proxyURL})
+	resp, err := New(time.Minute).Get("http://wordpress.internal/wp-json/wp/v2/posts")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if proxied != "http://wordpress.internal/wp-json/wp/v2/posts" {
+		t.Errorf("Expected the request to go through the proxy, got %q", proxied)
+	}
+}
@@ -0,0 +1,123 @@
+package ikego
+
+import (
+	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
+	"github.com/code-sleuth/ike-go/internal/manager/search"
+)
+
+// internal returns the options as the pipeline takes them.
+func (o *ProcessingOptions) internal() *interfaces.ProcessingOptions {
+	options := &interfaces.ProcessingOptions{
+		MaxTokens:         o.MaxTokens,
+		ChunkStrategy:     o.ChunkStrategy,
+		EmbeddingModel:    o.EmbeddingModel,
+		Concurrency:       o.Concurrency,
+		Timeout:           o.Timeout,
+		ImportTimeout:     o.ImportTimeout,
+		TransformTimeout:  o.TransformTimeout,
+		EmbedTimeout:      o.EmbedTimeout,
+		Collection:        o.Collection,
+		Owners:            o.Owners,
+		ACLLabels:         o.ACLLabels,
+		Restart:           o.Restart,
+		Paths:             o.Paths,
+		Force:             o.Force,
+		SourceConcurrency: o.SourceConcurrency,
+	}
+	if progress := o.Progress; progress != nil {
+		options.Progress = func(event interfaces.ProgressEvent) {
+			progress(ProgressEvent{
+				Stage:  string(event.Stage),
+				Source: event.Source,
+				Item:   event.Item,
+				Count:  event.Count,
+				Err:    event.Err,
+			})
+		}
+	}
+	return options
+}
+
+// batchReportFrom returns report as the package exports it; nil stays nil.
+func batchReportFrom(report *interfaces.BatchReport) *BatchReport {
+	if report == nil {
+		return nil
+	}
+	results := make([]SourceResult, 0, len(report.Results))
+	for _, result := range report.Results {
+		results = append(results, SourceResult(result))
+	}
+	return &BatchReport{Results: results, Succeeded: report.Succeeded, Failed: report.Failed}
+}
+
+// internal returns the options as the searcher takes them. A nil Reranker or Expander converts
+// to a nil interface, leaving that stage out.
+func (o SearchOptions) internal() search.Options {
+	return search.Options{
+		Mode:            search.Mode(o.Mode),
+		Limit:           o.Limit,
+		Weight:          o.Weight,
+		Filters:         o.Filters.internal(),
+		RRFK:            o.RRFK,
+		Diversity:       o.Diversity,
+		Reranker:        o.Reranker,
+		RerankTopN:      o.RerankTopN,
+		RecencyHalfLife: o.RecencyHalfLife,
+		RecencyWeight:   o.RecencyWeight,
+		Expander:        o.Expander,
+		Expansions:      o.Expansions,
+		SnippetWidth:    o.SnippetWidth,
+	}
+}
+
+func (f SearchFilters) internal() search.Filters {
+	return search.Filters{
+		Collection:   f.Collection,
+		Host:         f.Host,
+		SourceIDs:    f.SourceIDs,
+		Format:       f.Format,
+		Owner:        f.Owner,
+		IncludeStale: f.IncludeStale,
+		Access:       search.Access(f.Access),
+	}
+}
+
+func searchFiltersFrom(filters search.Filters) SearchFilters {
+	return SearchFilters{
+		Collection:   filters.Collection,
+		Host:         filters.Host,
+		SourceIDs:    filters.SourceIDs,
+		Format:       filters.Format,
+		Owner:        filters.Owner,
+		IncludeStale: filters.IncludeStale,
+		Access:       SearchAccess(filters.Access),
+	}
+}
+
+func searchResultsFrom(results []search.Result) []SearchResult {
+	converted := make([]SearchResult, 0, len(results))
+	for _, result := range results {
+		var snippet *Snippet
+		if result.Snippet != nil {
+			snippet = &Snippet{Text: result.Snippet.Text, Match: result.Snippet.Match}
+			for _, span := range result.Snippet.Highlights {
+				snippet.Highlights = append(snippet.Highlights, Span(span))
+			}
+		}
+		converted = append(converted, SearchResult{
+			ChunkID:      result.ChunkID,
+			DocumentID:   result.DocumentID,
+			SourceID:     result.SourceID,
+			SourceURL:    result.SourceURL,
+			Body:         result.Body,
+			Score:        result.Score,
+			KeywordRank:  result.KeywordRank,
+			VectorRank:   result.VectorRank,
+			UpdatedAt:    result.UpdatedAt,
+			Snippet:      snippet,
+			CanonicalURL: result.CanonicalURL,
+			License:      result.License,
+		})
+	}
+	return converted
+}
@@ -0,0 +1,137 @@
+package ikego
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/code-sleuth/ike-go/internal/manager/chunkers"
+	"github.com/code-sleuth/ike-go/internal/manager/embedders"
+	"github.com/code-sleuth/ike-go/internal/manager/importers"
+	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
+	"github.com/code-sleuth/ike-go/internal/manager/plugins"
+	"github.com/code-sleuth/ike-go/internal/manager/services"
+	"github.com/code-sleuth/ike-go/internal/manager/transformers"
+	"github.com/code-sleuth/ike-go/pkg/config"
+
+	"github.com/rs/zerolog"
+)
+
+var ErrUnsupportedEmbeddingModel = errors.New("unsupported embedding model")
+
+// DefaultEmbeddingModel is the model chunks are embedded with unless options name another.
+const DefaultEmbeddingModel = "text-embedding-3-small"
+
+// DefaultOptions returns the options imports run with when none are given: token chunks of up
+// to 8191 tokens embedded with DefaultEmbeddingModel, five at a time.
+func DefaultOptions() *ProcessingOptions {
+	return &ProcessingOptions{
+		MaxTokens:      8191,
+		ChunkStrategy:  "token",
+		EmbeddingModel: DefaultEmbeddingModel,
+		Concurrency:    5,
+	}
+}
+
+// registerDefaults registers the built-in importers, for WordPress JSON API endpoints, GitHub
+// repositories, and JSONL and CSV dumps, their transformers, the importers and transformers of
+// the plugins in cfg.PluginDir, and the token chunker with engine, configured with cfg.
+func registerDefaults(engine *services.ProcessingEngine, cfg *config.Config, logger *zerolog.Logger) error {
+	var options []importers.Option
+	if logger != nil {
+		options = append(options, importers.WithLogger(*logger))
+	}
+
+	wordpress := cfg.WordPress
+	for _, importer := range []interfaces.Importer{
+		importers.NewWPJSONImporter(append(options, importers.WithAuth(importers.WPAuth{
+			Username:    wordpress.Username,
+			AppPassword: wordpress.AppPassword,
+			Token:       wordpress.Token,
+			Headers:     wordpress.Headers,
+			Hosts:       wordpress.Hosts,
+		}))...),
+		importers.NewGitHubImporter(append(options, importers.WithToken(cfg.GitHubToken))...),
+		importers.NewDumpImporter(),
+	} {
+		if err := engine.RegisterImporter(importer); err != nil {
+			return fmt.Errorf("failed to register importer %s: %w", importer.GetSourceType(), err)
+		}
+	}
+
+	for _, transformer := range []interfaces.Transformer{
+		transformers.NewWPJSONTransformer(),
+		transformers.NewGitHubTransformer(),
+		transformers.NewDumpTransformer(),
+	} {
+		if err := engine.RegisterTransformer(transformer); err != nil {
+			return fmt.Errorf("failed to register transformer %s: %w", transformer.GetSourceType(), err)
+		}
+	}
+
+	if err := registerPlugins(engine, cfg.PluginDir); err != nil {
+		return err
+	}
+
+	tokenChunker, err := chunkers.NewTokenChunker(chunkers.WithTokenizerName(cfg.ChunkerTokenizer))
+	if err != nil {
+		return fmt.Errorf("failed to create token chunker: %w", err)
+	}
+	return engine.RegisterChunker(tokenChunker)
+}
+
+// registerPlugins registers the importers and transformers of the plugins in dir, as the import
+// command does. A plugin's transformer is registered even when it only provides an importer.
+func registerPlugins(engine *services.ProcessingEngine, dir string) error {
+	if dir == "" {
+		return nil
+	}
+
+	found, err := plugins.Discover(context.Background(), dir)
+	if err != nil {
+		return err
+	}
+	for _, plugin := range found {
+		if plugin.Manifest.Importer {
+			if err := engine.RegisterImporter(importers.NewPluginImporter(plugin)); err != nil {
+				return fmt.Errorf("failed to register plugin %s: %w", plugin.Manifest.Name, err)
+			}
+		}
+		if err := engine.RegisterTransformer(transformers.NewPluginTransformer(plugin)); err != nil {
+			return fmt.Errorf("failed to register plugin %s: %w", plugin.Manifest.Name, err)
+		}
+	}
+	return nil
+}
+
+// NewEmbedder creates the embedder for model, an OpenAI or Together AI embedding model,
+// authenticating with the API key in cfg.
+func NewEmbedder(model string, cfg *config.Config) (Embedder, error) {
+	return newEmbedder(model, cfg, nil)
+}
+
+func newEmbedder(model string, cfg *config.Config, logger *zerolog.Logger) (Embedder, error) {
+	var options []embedders.Option
+	if logger != nil {
+		options = append(options, embedders.WithLogger(*logger))
+	}
+
+	switch model {
+	case "text-embedding-3-small", "text-embedding-3-large", "text-embedding-ada-002":
+		openaiEmbedder, err := embedders.NewOpenAIEmbedder(model,
+			append(options, embedders.WithAPIKey(cfg.OpenAIAPIKey))...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create OpenAI embedder: %w", err)
+		}
+		return openaiEmbedder, nil
+	case "togethercomputer/m2-bert-80M-8k-retrieval", "togethercomputer/m2-bert-80M-32k-retrieval":
+		togetherEmbedder, err := embedders.NewTogetherAIEmbedder(model,
+			append(options, embedders.WithAPIKey(cfg.TogetherAPIKey))...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Together AI embedder: %w", err)
+		}
+		return togetherEmbedder, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedEmbeddingModel, model)
+	}
+}
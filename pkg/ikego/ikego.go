@@ -0,0 +1,174 @@
+// Package ikego embeds ike-go's import, transform, chunk, and embed pipeline, and search over
+// what it stores, in other Go programs.
+//
+// It is the supported API of the module: the identifiers it exports, and the fields and methods
+// of the types it exports, follow semantic versioning, so they are only removed or changed
+// incompatibly in a new major version. Its types are its own, converted to and from those the
+// pipeline uses internally, so the internal ones can change without breaking callers. The
+// packages under internal can't be imported from other modules; those under pkg, such as pkg/db
+// and pkg/config, can. Sources and formats of other kinds are added with plugins (IKE_PLUGIN_DIR).
+//
+//	database, err := db.NewConnection()
+//	...
+//	engine, err := ikego.New(database)
+//	...
+//	err = engine.Import(ctx, "https://github.com/owner/repo", nil)
+//	...
+//	results, err := engine.Search(ctx, "how do I configure retries?", ikego.SearchOptions{Limit: 5})
+package ikego
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
+	"github.com/code-sleuth/ike-go/internal/manager/search"
+	"github.com/code-sleuth/ike-go/internal/manager/services"
+	"github.com/code-sleuth/ike-go/pkg/config"
+	"github.com/code-sleuth/ike-go/pkg/db"
+	"github.com/code-sleuth/ike-go/pkg/migrations"
+
+	"github.com/rs/zerolog"
+)
+
+// Engine runs the pipeline against a database, and searches it.
+type Engine struct {
+	engine     *services.ProcessingEngine
+	database   *db.DB
+	config     *config.Config
+	logger     *zerolog.Logger
+	model      string
+	noDefaults bool
+
+	// embedders are those given with WithEmbedder and those created for searches, by model
+	embedders map[string]Embedder
+	mu        sync.Mutex
+}
+
+// New creates an engine storing what it imports in database. Unless WithoutDefaults is given, it
+// runs the built-in importers, transformers, and chunkers, and creates the embedder for each model
+// with NewEmbedder when it is first used, all configured from the environment or WithConfig.
+func New(database *db.DB, opts ...Option) (*Engine, error) {
+	o := newOptions(opts)
+	cfg := o.config
+	if cfg == nil {
+		var err error
+		if cfg, err = config.FromEnv(); err != nil {
+			return nil, err
+		}
+	}
+
+	var engineOptions []services.EngineOption
+	if o.logger != nil {
+		engineOptions = append(engineOptions, services.WithLogger(*o.logger))
+	}
+	e := &Engine{
+		engine:     services.NewProcessingEngine(engineOptions...),
+		database:   database,
+		config:     cfg,
+		logger:     o.logger,
+		model:      o.model,
+		noDefaults: o.noDefaults,
+		embedders:  make(map[string]Embedder),
+	}
+	e.engine.SetDialect(database.Dialect())
+
+	if !o.noDefaults {
+		if err := registerDefaults(e.engine, cfg, o.logger); err != nil {
+			return nil, err
+		}
+		e.engine.SetEmbedderFactory(func(model string) (interfaces.Embedder, error) {
+			return newEmbedder(model, cfg, o.logger)
+		})
+	}
+
+	for _, embedder := range o.embedders {
+		if err := e.engine.RegisterEmbedder(embedder); err != nil {
+			return nil, fmt.Errorf("failed to register embedder %s: %w", embedder.GetModelName(), err)
+		}
+		e.embedders[embedder.GetModelName()] = embedder
+	}
+
+	return e, nil
+}
+
+// Migrate applies the database migrations that have not been applied yet, returning the versions
+// it applied. It must have run before the first import.
+func (e *Engine) Migrate(ctx context.Context) ([]string, error) {
+	return migrations.Apply(ctx, e.database.DB)
+}
+
+// Import runs the complete pipeline for a source: it fetches the content at sourceURL, such as a
+// GitHub repository or WordPress JSON API endpoint, then transforms, chunks, and embeds it. nil
+// options use DefaultOptions.
+func (e *Engine) Import(ctx context.Context, sourceURL string, options *ProcessingOptions) error {
+	if options == nil {
+		options = DefaultOptions()
+	}
+	return e.engine.ProcessSource(ctx, sourceURL, options.internal(), e.database.DB)
+}
+
+// ImportAll runs Import for each of sourceURLs, options.SourceConcurrency at a time, and reports
+// on each. It returns ErrBatchIncomplete when any failed.
+func (e *Engine) ImportAll(ctx context.Context, sourceURLs []string, options *ProcessingOptions) (*BatchReport, error) {
+	if options == nil {
+		options = DefaultOptions()
+	}
+	report, err := e.engine.ProcessSources(ctx, sourceURLs, options.internal(), e.database.DB)
+	return batchReportFrom(report), err
+}
+
+// ProcessDocument transforms, chunks, and embeds a download already imported, such as one whose
+// processing failed. nil options use DefaultOptions.
+func (e *Engine) ProcessDocument(ctx context.Context, downloadID string, options *ProcessingOptions) error {
+	if options == nil {
+		options = DefaultOptions()
+	}
+	return e.engine.ProcessDocument(ctx, downloadID, options.internal(), e.database.DB)
+}
+
+// Search returns the chunks best matching query. Queries are embedded for vector search with
+// the model set with WithEmbeddingModel. Only content every caller may see is searched unless
+// options.Filters.Access grants more.
+func (e *Engine) Search(ctx context.Context, query string, options SearchOptions) ([]SearchResult, error) {
+	var embedder Embedder
+	if options.Mode != SearchKeyword {
+		var err error
+		if embedder, err = e.embedderFor(e.model); err != nil {
+			return nil, err
+		}
+	}
+	results, err := search.NewSearcher(e.database, embedder).Search(ctx, query, options.internal())
+	if err != nil {
+		return nil, err
+	}
+	return searchResultsFrom(results), nil
+}
+
+// embedderFor returns the embedder given for model, creating it with NewEmbedder when there is
+// none and the defaults are in use.
+func (e *Engine) embedderFor(model string) (Embedder, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if embedder, ok := e.embedders[model]; ok {
+		return embedder, nil
+	}
+	if e.noDefaults {
+		return nil, fmt.Errorf("%w: %s", ErrNoEmbedderRegistered, model)
+	}
+	embedder, err := newEmbedder(model, e.config, e.logger)
+	if err != nil {
+		return nil, err
+	}
+	e.embedders[model] = embedder
+	return embedder, nil
+}
+
+// Shutdown stops the engine accepting new imports and waits for those running to stop between
+// documents, leaving the rest of each to resume at its next Import. If ctx ends first, they are
+// cancelled and Shutdown returns ctx's error.
+func (e *Engine) Shutdown(ctx context.Context) error {
+	return e.engine.Shutdown(ctx)
+}
@@ -0,0 +1,144 @@
+package ikego
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/code-sleuth/ike-go/internal/manager/embedders"
+	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
+	"github.com/code-sleuth/ike-go/internal/manager/search"
+	"github.com/code-sleuth/ike-go/pkg/config"
+	"github.com/code-sleuth/ike-go/pkg/db"
+)
+
+// fakeEmbedder is an embedder of a made-up model.
+type fakeEmbedder struct {
+	model string
+}
+
+func (f *fakeEmbedder) GenerateEmbedding(context.Context, string) ([]float32, error) {
+	return []float32{1, 0}, nil
+}
+
+func (f *fakeEmbedder) GetModelName() string { return f.model }
+func (f *fakeEmbedder) GetDimension() int    { return 2 }
+func (f *fakeEmbedder) GetMaxTokens() int    { return 512 }
+
+func TestNewEmbedder(t *testing.T) {
+	cfg := &config.Config{OpenAIAPIKey: "sk-test", TogetherAPIKey: "tg-test"}
+
+	for _, model := range []string{DefaultEmbeddingModel, "togethercomputer/m2-bert-80M-8k-retrieval"} {
+		embedder, err := NewEmbedder(model, cfg)
+		if err != nil {
+			t.Fatalf("Unexpected error for %s: %v", model, err)
+		}
+		if embedder.GetModelName() != model {
+			t.Errorf("Expected model %s, got %s", model, embedder.GetModelName())
+		}
+	}
+
+	if _, err := NewEmbedder("word2vec", cfg); !errors.Is(err, ErrUnsupportedEmbeddingModel) {
+		t.Errorf("Expected ErrUnsupportedEmbeddingModel, got %v", err)
+	}
+}
+
+func TestNewEmbedder_EmptyKey(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "sk-environment")
+	t.Setenv("TOGETHER_API_KEY", "tg-environment")
+
+	for _, model := range []string{DefaultEmbeddingModel, "togethercomputer/m2-bert-80M-8k-retrieval"} {
+		if _, err := NewEmbedder(model, &config.Config{}); !errors.Is(err, embedders.ErrAPIKeyNotSet) {
+			t.Errorf("Expected ErrAPIKeyNotSet for %s with no key configured, got %v", model, err)
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	cfg := &config.Config{OpenAIAPIKey: "sk-test"}
+
+	if _, err := New(&db.DB{}, WithConfig(cfg)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	embedder := &fakeEmbedder{model: "custom"}
+	engine, err := New(&db.DB{}, WithConfig(cfg), WithEmbedder(embedder), WithEmbeddingModel("custom"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if given, err := engine.embedderFor("custom"); err != nil || given != embedder {
+		t.Errorf("Expected the given embedder for its model, got %v, %v", given, err)
+	}
+}
+
+func TestEngine_Search_WithoutDefaults(t *testing.T) {
+	engine, err := New(&db.DB{}, WithConfig(&config.Config{}), WithoutDefaults())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	_, err = engine.Search(context.Background(), "retries", SearchOptions{Mode: SearchVector})
+	if !errors.Is(err, ErrNoEmbedderRegistered) {
+		t.Errorf("Expected ErrNoEmbedderRegistered without an embedder, got %v", err)
+	}
+}
+
+func TestProcessingOptions_Internal(t *testing.T) {
+	var events []ProgressEvent
+	options := &ProcessingOptions{
+		MaxTokens:  512,
+		Collection: "docs",
+		Paths:      []string{"README.md"},
+		Progress:   func(event ProgressEvent) { events = append(events, event) },
+	}
+
+	internal := options.internal()
+	if internal.MaxTokens != 512 || internal.Collection != "docs" || !reflect.DeepEqual(internal.Paths, options.Paths) {
+		t.Errorf("Unexpected converted options %+v", internal)
+	}
+	internal.Progress(interfaces.ProgressEvent{Stage: interfaces.StageEmbedded, Item: "doc-1", Count: 3})
+	want := []ProgressEvent{{Stage: "embedded", Item: "doc-1", Count: 3}}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("Expected progress events %+v, got %+v", want, events)
+	}
+
+	if (&ProcessingOptions{}).internal().Progress != nil {
+		t.Error("Expected no progress function when none is set")
+	}
+}
+
+func TestSearchResultsFrom(t *testing.T) {
+	results := searchResultsFrom([]search.Result{{
+		ChunkID: "chunk-1",
+		Score:   0.5,
+		Snippet: &search.Highlighted{Text: "configure retries", Highlights: []search.Span{{Start: 10, End: 17}}},
+	}})
+
+	want := []SearchResult{{
+		ChunkID: "chunk-1",
+		Score:   0.5,
+		Snippet: &Snippet{Text: "configure retries", Highlights: []Span{{Start: 10, End: 17}}},
+	}}
+	if !reflect.DeepEqual(results, want) {
+		t.Errorf("Expected %+v, got %+v", want, results)
+	}
+}
+
+func TestSearchOptions_Internal(t *testing.T) {
+	options := SearchOptions{Mode: SearchKeyword, Limit: 5, Filters: SearchFilters{Host: "github.com"}}.internal()
+	if options.Mode != search.ModeKeyword || options.Limit != 5 || options.Filters.Host != "github.com" {
+		t.Errorf("Unexpected converted options %+v", options)
+	}
+	if options.Reranker != nil || options.Expander != nil {
+		t.Error("Expected no reranker or expander when none is set")
+	}
+}
+
+func TestDefaultOptions(t *testing.T) {
+	options := DefaultOptions()
+	if options.EmbeddingModel != DefaultEmbeddingModel || options.ChunkStrategy != "token" ||
+		options.MaxTokens <= 0 || options.Concurrency <= 0 {
+		t.Errorf("Unexpected default options %+v", options)
+	}
+}
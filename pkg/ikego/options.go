@@ -0,0 +1,68 @@
+package ikego
+
+import (
+	"github.com/code-sleuth/ike-go/pkg/config"
+
+	"github.com/rs/zerolog"
+)
+
+// Option configures an Engine when it is created, as in
+// New(database, WithConfig(cfg), WithEmbedder(myEmbedder)).
+type Option func(*options)
+
+// options are the settings Options give.
+type options struct {
+	config     *config.Config
+	logger     *zerolog.Logger
+	noDefaults bool
+	embedders  []Embedder
+	model      string
+}
+
+// newOptions returns the settings given by opts.
+func newOptions(opts []Option) *options {
+	o := &options{model: DefaultEmbeddingModel}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithConfig configures the engine's default components with cfg, such as one from config.Load,
+// in place of the environment.
+func WithConfig(cfg *config.Config) Option {
+	return func(o *options) {
+		o.config = cfg
+	}
+}
+
+// WithLogger sends the logs of the engine and its default components to logger, such as one from
+// util.NewSlogLogger.
+func WithLogger(logger zerolog.Logger) Option {
+	return func(o *options) {
+		o.logger = &logger
+	}
+}
+
+// WithoutDefaults leaves out the built-in importers, transformers, chunkers, and embedders, for
+// engines that only search, with the embedders given with WithEmbedder.
+func WithoutDefaults() Option {
+	return func(o *options) {
+		o.noDefaults = true
+	}
+}
+
+// WithEmbedder registers embedder for its model, in place of the one NewEmbedder would create.
+func WithEmbedder(embedder Embedder) Option {
+	return func(o *options) {
+		o.embedders = append(o.embedders, embedder)
+	}
+}
+
+// WithEmbeddingModel sets the model Engine.Search embeds queries with; DefaultEmbeddingModel when
+// not given. It should be the model the searched content was embedded with.
+func WithEmbeddingModel(model string) Option {
+	return func(o *options) {
+		o.model = model
+	}
+}
@@ -0,0 +1,252 @@
+package ikego
+
+import (
+	"context"
+	"time"
+
+	"github.com/code-sleuth/ike-go/internal/manager/interfaces"
+	"github.com/code-sleuth/ike-go/internal/manager/search"
+	"github.com/code-sleuth/ike-go/internal/manager/services"
+)
+
+// Embedder creates vector embeddings with one model. Programs can implement it to embed with
+// models of their own, given to the engine with WithEmbedder.
+type Embedder interface {
+	// GenerateEmbedding creates a vector embedding for the given content
+	GenerateEmbedding(ctx context.Context, content string) ([]float32, error)
+
+	// GetModelName returns the name of the embedding model
+	GetModelName() string
+
+	// GetDimension returns the dimension of the embedding vectors
+	GetDimension() int
+
+	// GetMaxTokens returns the maximum number of tokens this embedder can handle
+	GetMaxTokens() int
+}
+
+// BatchEmbedder is implemented by embedders whose API embeds several inputs in one request, so
+// the engine can embed a batch of chunks with a single call.
+type BatchEmbedder interface {
+	// GenerateEmbeddings creates a vector embedding for each of contents, in the order of contents
+	GenerateEmbeddings(ctx context.Context, contents []string) ([][]float32, error)
+}
+
+// Reranker re-scores search results against the query; see SearchOptions.Reranker.
+type Reranker interface {
+	// Rerank returns a relevance score for each document, in the order given
+	Rerank(ctx context.Context, query string, documents []string) ([]float64, error)
+
+	// GetModelName returns the name of the reranking model
+	GetModelName() string
+}
+
+// QueryExpander generates paraphrases of a search query; see SearchOptions.Expander.
+type QueryExpander interface {
+	// Expand returns up to n paraphrases of the query, excluding the query itself
+	Expand(ctx context.Context, query string, n int) ([]string, error)
+
+	// GetModelName returns the name of the model generating paraphrases
+	GetModelName() string
+}
+
+// ProcessingOptions configures an import; DefaultOptions returns those used when none are given.
+type ProcessingOptions struct {
+	MaxTokens      int
+	ChunkStrategy  string
+	EmbeddingModel string
+	Concurrency    int
+	// Timeout bounds a whole import; zero leaves it unbounded.
+	Timeout time.Duration
+	// ImportTimeout bounds the fetch of each source, TransformTimeout the transform of each
+	// download, and EmbedTimeout the embedding of each chunk. Zero leaves the stage bounded only
+	// by Timeout.
+	ImportTimeout    time.Duration
+	TransformTimeout time.Duration
+	EmbedTimeout     time.Duration
+	// Collection places newly imported sources in the named collection; empty uses the default.
+	Collection string
+	// Owners are recorded as owners of every source imported.
+	Owners []string
+	// ACLLabels are the access-control labels of every source imported. Searches only return
+	// labeled content to callers granted one of its labels.
+	ACLLabels []string
+	// Restart ignores any unfinished import of the source and imports it from the first item.
+	Restart bool
+	// Paths limits the import to these items, such as repository file paths or WordPress post
+	// IDs; empty imports the whole source.
+	Paths []string
+	// Force chunks and embeds documents even when their content is unchanged.
+	Force bool
+	// SourceConcurrency is how many sources ImportAll imports at once; zero or less uses 2.
+	SourceConcurrency int
+	// Progress, when set, receives progress events.
+	Progress ProgressFunc
+}
+
+// ProgressEvent is a single progress update. Count is how many units the event adds to its
+// stage: the number of items discovered, chunks produced, and so on.
+type ProgressEvent struct {
+	// Stage is the step reported: discovered, imported, transformed, chunked, embedded, skipped,
+	// failed, or waiting.
+	Stage string
+	// Source is the URL of the source being imported, if any.
+	Source string
+	// Item identifies what the event is about, such as a file path, post ID, or document ID.
+	Item  string
+	Count int
+	Err   error
+}
+
+// ProgressFunc receives progress events. They are reported from several goroutines, so
+// implementations must be safe for concurrent use.
+type ProgressFunc func(event ProgressEvent)
+
+// BatchReport is the outcome of each source of ImportAll, in the order the URLs were given with
+// duplicates removed.
+type BatchReport struct {
+	Results   []SourceResult
+	Succeeded int
+	Failed    int
+}
+
+// SourceResult is the outcome of one source of ImportAll.
+type SourceResult struct {
+	SourceURL string
+	Err       error
+	Duration  time.Duration
+}
+
+// Credentials are API keys and tokens for one call, used in place of those the engine was
+// configured with. Empty fields leave the configured ones in use.
+type Credentials struct {
+	GitHubToken    string
+	OpenAIAPIKey   string
+	TogetherAPIKey string
+}
+
+// SearchMode selects how Engine.Search matches chunks.
+type SearchMode string
+
+const (
+	SearchHybrid  SearchMode = "hybrid"
+	SearchKeyword SearchMode = "keyword"
+	SearchVector  SearchMode = "vector"
+)
+
+// SearchOptions configures a search; the zero value is a hybrid search with default limits.
+type SearchOptions struct {
+	Mode  SearchMode
+	Limit int
+	// Weight is the share of the fused score given to vector results, from 0 (keyword only) to 1
+	// (vector only). Only used in hybrid mode.
+	Weight float64
+	// Filters restricts results to part of the corpus.
+	Filters SearchFilters
+	// RRFK is the reciprocal rank fusion constant; 0 uses the default.
+	RRFK int
+	// Diversity re-ranks results to differ from each other when greater than 0, up to 1.
+	Diversity float64
+	// Reranker, when set, re-scores the top RerankTopN results before they are returned.
+	Reranker Reranker
+	// RerankTopN is the number of results passed to the reranker; 0 reranks every candidate.
+	RerankTopN int
+	// RecencyHalfLife enables time-decay ranking: a document this old keeps half of the decaying
+	// share of its score.
+	RecencyHalfLife time.Duration
+	// RecencyWeight is the share of the score subject to decay; 0 uses the default.
+	RecencyWeight float64
+	// Expander, when set, generates paraphrases of the query whose results are searched too.
+	Expander QueryExpander
+	// Expansions is the number of paraphrases to generate; 0 uses the default.
+	Expansions int
+	// SnippetWidth is the length of highlighted snippets; 0 uses the default.
+	SnippetWidth int
+}
+
+// SearchFilters restricts a search to part of the corpus.
+type SearchFilters struct {
+	Collection string
+	Host       string
+	SourceIDs  []string
+	Format     string
+	Owner      string
+	// IncludeStale also searches documents that outlived their source's TTL.
+	IncludeStale bool
+	// Access is who the search runs for.
+	Access SearchAccess
+}
+
+// SearchAccess is who a search runs for; its zero value searches public content only.
+type SearchAccess struct {
+	Labels []string
+	// Unrestricted searches every document whatever its labels, for trusted callers.
+	Unrestricted bool
+}
+
+// SearchResult is a chunk matching a search.
+type SearchResult struct {
+	ChunkID     string  `json:"chunk_id"`
+	DocumentID  string  `json:"document_id"`
+	SourceID    string  `json:"source_id"`
+	SourceURL   string  `json:"source_url"`
+	Body        string  `json:"body"`
+	Score       float64 `json:"score"`
+	KeywordRank int     `json:"keyword_rank,omitempty"`
+	VectorRank  int     `json:"vector_rank,omitempty"`
+	// UpdatedAt is when the document was last modified or, failing that, published.
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+	// Snippet is a highlighted excerpt explaining why the chunk matched.
+	Snippet *Snippet `json:"snippet,omitempty"`
+	// CanonicalURL is the URL the document declares canonical.
+	CanonicalURL string `json:"canonical_url,omitempty"`
+	// License is the SPDX license expression of the document.
+	License string `json:"license,omitempty"`
+}
+
+// Snippet is an excerpt of a result with the byte ranges of Text that matched the query.
+type Snippet struct {
+	Text       string `json:"text"`
+	Highlights []Span `json:"highlights,omitempty"`
+	Match      string `json:"match"`
+}
+
+// Span is the byte range [Start, End) of a snippet.
+type Span struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// Errors the engine and search return, for matching with errors.Is.
+var (
+	ErrNoImporterCanHandle  = services.ErrNoImporterCanHandle
+	ErrNoEmbedderRegistered = services.ErrNoEmbedderRegistered
+	ErrBatchIncomplete      = services.ErrBatchIncomplete
+	ErrShuttingDown         = services.ErrShuttingDown
+	ErrStageTimeout         = services.ErrStageTimeout
+	ErrEmptyQuery           = search.ErrEmptyQuery
+	ErrInvalidFilter        = search.ErrInvalidFilter
+)
+
+// ParseSearchFilters parses key=value filter expressions, such as host=github.com or
+// owner=docs-team. Supported keys are collection, host, source (repeatable), format, and owner.
+func ParseSearchFilters(expressions []string) (SearchFilters, error) {
+	filters, err := search.ParseFilters(expressions)
+	if err != nil {
+		return SearchFilters{}, err
+	}
+	return searchFiltersFrom(filters), nil
+}
+
+// WithCredentials returns a context whose imports and embedding requests authenticate with
+// credentials instead of those the engine was configured with. Fields left empty keep the
+// configured ones.
+func WithCredentials(ctx context.Context, credentials Credentials) context.Context {
+	return interfaces.WithCredentials(ctx, interfaces.Credentials(credentials))
+}
+
+// WithActor returns a context whose recorded events name actor, such as a user or service, as
+// their cause.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return interfaces.WithActor(ctx, actor)
+}
@@ -0,0 +1,385 @@
+// Package metrics is a small registry of counters, gauges, and histograms exposed in the
+// Prometheus text exposition format, so a Prometheus server can scrape ike-go without pulling
+// in a client library.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// contentType is the Prometheus text exposition format version written by Handler.
+const contentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// DefBuckets are histogram buckets in seconds suited to network requests.
+var DefBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60}
+
+// Default is the registry the package-level constructors register with and Handler serves.
+var Default = NewRegistry()
+
+// Registry holds metric families and writes them in the text exposition format.
+type Registry struct {
+	mu       sync.Mutex
+	families map[string]*vec
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{families: make(map[string]*vec)}
+}
+
+// register adds v to the registry. Metric names are fixed at compile time, so registering the
+// same name twice is a programming error and panics.
+func (r *Registry) register(v *vec) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.families[v.name]; exists {
+		panic(fmt.Sprintf("metrics: %s registered twice", v.name))
+	}
+	r.families[v.name] = v
+}
+
+// WriteTo writes every metric in the text exposition format, families sorted by name.
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.mu.Lock()
+	families := make([]*vec, 0, len(r.families))
+	for _, family := range r.families {
+		families = append(families, family)
+	}
+	r.mu.Unlock()
+	sort.Slice(families, func(i, j int) bool { return families[i].name < families[j].name })
+
+	counter := &countingWriter{w: w}
+	buffered := bufio.NewWriter(counter)
+	for _, family := range families {
+		family.write(buffered)
+	}
+	err := buffered.Flush()
+	return counter.n, err
+}
+
+// Handler serves the registry's metrics for Prometheus to scrape.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		_, _ = r.WriteTo(w)
+	})
+}
+
+// Handler serves the Default registry's metrics.
+func Handler() http.Handler {
+	return Default.Handler()
+}
+
+// CounterVec is a counter partitioned by label values.
+type CounterVec struct{ vec *vec }
+
+// NewCounterVec registers a counter with the given label names on the Default registry.
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	return Default.NewCounterVec(name, help, labels...)
+}
+
+// NewCounterVec registers a counter with the given label names.
+func (r *Registry) NewCounterVec(name, help string, labels ...string) *CounterVec {
+	v := newVec(name, help, "counter", labels, func() child { return &Counter{} })
+	r.register(v)
+	return &CounterVec{vec: v}
+}
+
+// With returns the counter for the label values, given in the order the labels were declared.
+func (c *CounterVec) With(values ...string) *Counter {
+	counter, _ := c.vec.with(values).(*Counter)
+	return counter
+}
+
+// Counter is a value that only goes up.
+type Counter struct{ value atomicFloat }
+
+// Inc adds one to the counter.
+func (c *Counter) Inc() { c.value.add(1) }
+
+// Add adds delta, which must not be negative, to the counter.
+func (c *Counter) Add(delta float64) {
+	if delta < 0 {
+		return
+	}
+	c.value.add(delta)
+}
+
+// Value returns the counter's current value.
+func (c *Counter) Value() float64 { return c.value.load() }
+
+func (c *Counter) write(w *bufio.Writer, name, labels string) {
+	writeSample(w, name, labels, c.value.load())
+}
+
+// GaugeVec is a gauge partitioned by label values.
+type GaugeVec struct{ vec *vec }
+
+// NewGaugeVec registers a gauge with the given label names on the Default registry.
+func NewGaugeVec(name, help string, labels ...string) *GaugeVec {
+	return Default.NewGaugeVec(name, help, labels...)
+}
+
+// NewGaugeVec registers a gauge with the given label names.
+func (r *Registry) NewGaugeVec(name, help string, labels ...string) *GaugeVec {
+	v := newVec(name, help, "gauge", labels, func() child { return &Gauge{} })
+	r.register(v)
+	return &GaugeVec{vec: v}
+}
+
+// With returns the gauge for the label values, given in the order the labels were declared.
+func (g *GaugeVec) With(values ...string) *Gauge {
+	gauge, _ := g.vec.with(values).(*Gauge)
+	return gauge
+}
+
+// Gauge is a value that can go up and down.
+type Gauge struct{ value atomicFloat }
+
+// Set sets the gauge to value.
+func (g *Gauge) Set(value float64) { g.value.store(value) }
+
+// Add adds delta, which may be negative, to the gauge.
+func (g *Gauge) Add(delta float64) { g.value.add(delta) }
+
+// Inc adds one to the gauge.
+func (g *Gauge) Inc() { g.value.add(1) }
+
+// Dec subtracts one from the gauge.
+func (g *Gauge) Dec() { g.value.add(-1) }
+
+// Value returns the gauge's current value.
+func (g *Gauge) Value() float64 { return g.value.load() }
+
+func (g *Gauge) write(w *bufio.Writer, name, labels string) {
+	writeSample(w, name, labels, g.value.load())
+}
+
+// HistogramVec is a histogram partitioned by label values.
+type HistogramVec struct{ vec *vec }
+
+// NewHistogramVec registers a histogram with the given buckets and label names on the Default
+// registry. Nil buckets use DefBuckets.
+func NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	return Default.NewHistogramVec(name, help, buckets, labels...)
+}
+
+// NewHistogramVec registers a histogram with the given buckets and label names. Nil buckets use
+// DefBuckets.
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	if buckets == nil {
+		buckets = DefBuckets
+	}
+	bounds := append([]float64(nil), buckets...)
+	sort.Float64s(bounds)
+
+	v := newVec(name, help, "histogram", labels, func() child {
+		return &Histogram{bounds: bounds, counts: make([]uint64, len(bounds))}
+	})
+	r.register(v)
+	return &HistogramVec{vec: v}
+}
+
+// With returns the histogram for the label values, given in the order the labels were declared.
+func (h *HistogramVec) With(values ...string) *Histogram {
+	histogram, _ := h.vec.with(values).(*Histogram)
+	return histogram
+}
+
+// Histogram counts observations in buckets of upper bounds.
+type Histogram struct {
+	mu     sync.Mutex
+	bounds []float64
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+// Observe records value, such as a duration in seconds.
+func (h *Histogram) Observe(value float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i, bound := range h.bounds {
+		if value <= bound {
+			h.counts[i]++
+			break
+		}
+	}
+	h.sum += value
+	h.count++
+}
+
+// Count returns the number of observations.
+func (h *Histogram) Count() uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.count
+}
+
+func (h *Histogram) write(w *bufio.Writer, name, labels string) {
+	h.mu.Lock()
+	counts := append([]uint64(nil), h.counts...)
+	sum, count := h.sum, h.count
+	h.mu.Unlock()
+
+	var cumulative uint64
+	for i, bound := range h.bounds {
+		cumulative += counts[i]
+		writeSample(w, name+"_bucket", joinLabels(labels, `le="`+formatFloat(bound)+`"`), float64(cumulative))
+	}
+	writeSample(w, name+"_bucket", joinLabels(labels, `le="+Inf"`), float64(count))
+	writeSample(w, name+"_sum", labels, sum)
+	writeSample(w, name+"_count", labels, float64(count))
+}
+
+// child is one labelled series of a metric family.
+type child interface {
+	write(w *bufio.Writer, name, labels string)
+}
+
+// vec is a metric family: its metadata and a series per combination of label values.
+type vec struct {
+	name     string
+	help     string
+	typ      string
+	labels   []string
+	newChild func() child
+
+	mu       sync.RWMutex
+	children map[string]child
+}
+
+func newVec(name, help, typ string, labels []string, newChild func() child) *vec {
+	return &vec{
+		name:     name,
+		help:     help,
+		typ:      typ,
+		labels:   labels,
+		newChild: newChild,
+		children: make(map[string]child),
+	}
+}
+
+// with returns the series for values, creating it on first use. Passing the wrong number of
+// values is a programming error and panics.
+func (v *vec) with(values []string) child {
+	if len(values) != len(v.labels) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", v.name, len(v.labels), len(values)))
+	}
+
+	pairs := make([]string, len(values))
+	for i, value := range values {
+		pairs[i] = v.labels[i] + `="` + escapeLabel(value) + `"`
+	}
+	key := strings.Join(pairs, ",")
+
+	v.mu.RLock()
+	c, exists := v.children[key]
+	v.mu.RUnlock()
+	if exists {
+		return c
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if c, exists := v.children[key]; exists {
+		return c
+	}
+	c = v.newChild()
+	v.children[key] = c
+	return c
+}
+
+func (v *vec) write(w *bufio.Writer) {
+	v.mu.RLock()
+	keys := make([]string, 0, len(v.children))
+	for key := range v.children {
+		keys = append(keys, key)
+	}
+	children := make(map[string]child, len(v.children))
+	for key, c := range v.children {
+		children[key] = c
+	}
+	v.mu.RUnlock()
+	sort.Strings(keys)
+
+	fmt.Fprintf(w, "# HELP %s %s\n", v.name, escapeHelp(v.help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", v.name, v.typ)
+	for _, key := range keys {
+		children[key].write(w, v.name, key)
+	}
+}
+
+// writeSample writes one sample line; labels is the already formatted label list, if any.
+func writeSample(w *bufio.Writer, name, labels string, value float64) {
+	w.WriteString(name)
+	if labels != "" {
+		w.WriteString("{" + labels + "}")
+	}
+	w.WriteString(" " + formatFloat(value) + "\n")
+}
+
+func joinLabels(labels, extra string) string {
+	if labels == "" {
+		return extra
+	}
+	return labels + "," + extra
+}
+
+func formatFloat(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	default:
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	}
+}
+
+var (
+	labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func escapeLabel(value string) string { return labelEscaper.Replace(value) }
+
+func escapeHelp(value string) string { return helpEscaper.Replace(value) }
+
+// atomicFloat is a float64 updated with compare-and-swap.
+type atomicFloat struct{ bits atomic.Uint64 }
+
+func (f *atomicFloat) load() float64 { return math.Float64frombits(f.bits.Load()) }
+
+func (f *atomicFloat) store(value float64) { f.bits.Store(math.Float64bits(value)) }
+
+func (f *atomicFloat) add(delta float64) {
+	for {
+		old := f.bits.Load()
+		updated := math.Float64bits(math.Float64frombits(old) + delta)
+		if f.bits.CompareAndSwap(old, updated) {
+			return
+		}
+	}
+}
+
+// countingWriter counts the bytes written through it for WriteTo.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,113 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestRegistry_WriteTo(t *testing.T) {
+	registry := NewRegistry()
+	imported := registry.NewCounterVec("ike_items_total", "Items by status.", "source_type", "status")
+	running := registry.NewGaugeVec("ike_runs_in_progress", "Runs in progress.")
+	latency := registry.NewHistogramVec("ike_request_seconds", "Request latency.", []float64{1, 0.1}, "model")
+
+	imported.With("github", "imported").Add(3)
+	imported.With("github", "failed").Inc()
+	imported.With("wp-json", `say "hi"`).Inc()
+	running.With().Inc()
+	running.With().Inc()
+	running.With().Dec()
+	latency.With("ada").Observe(0.05)
+	latency.With("ada").Observe(0.5)
+	latency.With("ada").Observe(2)
+
+	var out strings.Builder
+	if _, err := registry.WriteTo(&out); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := `# HELP ike_items_total Items by status.
+# TYPE ike_items_total counter
+ike_items_total{source_type="github",status="failed"} 1
+ike_items_total{source_type="github",status="imported"} 3
+ike_items_total{source_type="wp-json",status="say \"hi\""} 1
+# HELP ike_request_seconds Request latency.
+# TYPE ike_request_seconds histogram
+ike_request_seconds_bucket{model="ada",le="0.1"} 1
+ike_request_seconds_bucket{model="ada",le="1"} 2
+ike_request_seconds_bucket{model="ada",le="+Inf"} 3
+ike_request_seconds_sum{model="ada"} 2.55
+ike_request_seconds_count{model="ada"} 3
+# HELP ike_runs_in_progress Runs in progress.
+# TYPE ike_runs_in_progress gauge
+ike_runs_in_progress 1
+`
+	if out.String() != expected {
+		t.Errorf("Unexpected exposition:\n%s\nexpected:\n%s", out.String(), expected)
+	}
+}
+
+func TestRegistry_Handler(t *testing.T) {
+	registry := NewRegistry()
+	registry.NewCounterVec("ike_test_total", "A test counter.").With().Inc()
+
+	recorder := httptest.NewRecorder()
+	registry.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if got := recorder.Header().Get("Content-Type"); got != contentType {
+		t.Errorf("Expected content type %q, got %q", contentType, got)
+	}
+	if !strings.Contains(recorder.Body.String(), "ike_test_total 1\n") {
+		t.Errorf("Expected the counter in the response, got %q", recorder.Body.String())
+	}
+}
+
+func TestCounter_ConcurrentAdds(t *testing.T) {
+	counter := NewRegistry().NewCounterVec("ike_concurrent_total", "Concurrent adds.").With()
+
+	var wg sync.WaitGroup
+	for range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				counter.Inc()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if counter.Value() != 5000 {
+		t.Errorf("Expected 5000, got %v", counter.Value())
+	}
+}
+
+func TestRegistry_Misuse(t *testing.T) {
+	tests := []struct {
+		name        string
+		misuse      func(r *Registry)
+		description string
+	}{
+		{name: "duplicate name", misuse: func(r *Registry) {
+			r.NewCounterVec("ike_dup_total", "First.")
+			r.NewGaugeVec("ike_dup_total", "Second.")
+		}, description: "should panic when a name is registered twice"},
+		{name: "wrong label count", misuse: func(r *Registry) {
+			r.NewCounterVec("ike_labels_total", "Labels.", "stage").With("import", "extra")
+		}, description: "should panic when label values do not match the declared labels"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Expected a panic for test: %s", tt.description)
+				}
+			}()
+			tt.misuse(NewRegistry())
+		})
+	}
+}
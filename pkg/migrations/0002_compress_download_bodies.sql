@@ -0,0 +1,7 @@
+-- migrate:up
+
+-- body_encoding records how downloads.body is stored so readers know whether to decompress it.
+-- Existing rows keep their plain-text bodies ('identity'); run `ike-go migrate --compress-bodies`
+-- to gzip them in place.
+ALTER TABLE downloads ADD COLUMN body_encoding TEXT NOT NULL DEFAULT 'identity'
+    CHECK (body_encoding IN ('identity', 'gzip'));
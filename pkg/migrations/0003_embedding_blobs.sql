@@ -0,0 +1,25 @@
+-- migrate:up
+
+-- Store embedding vectors as little-endian float32 BLOBs instead of formatted text.
+-- SQLite cannot change a column type in place, so the table is rebuilt. Existing text
+-- vectors are copied unchanged; run `ike-go migrate --convert-embeddings` to re-encode them.
+CREATE TABLE embeddings_new (
+    id TEXT NOT NULL PRIMARY KEY,
+    embedding_1536 BLOB,
+    embedding_3072 BLOB,
+    embedding_768 BLOB,
+    model TEXT,
+    embedded_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+    object_id TEXT NOT NULL,
+    object_type TEXT NOT NULL DEFAULT 'chunk',
+    FOREIGN KEY (object_id) REFERENCES chunks(id)
+);
+
+INSERT INTO embeddings_new (id, embedding_1536, embedding_3072, embedding_768, model, embedded_at, object_id, object_type)
+SELECT id, embedding_1536, embedding_3072, embedding_768, model, embedded_at, object_id, object_type
+FROM embeddings;
+
+DROP TABLE embeddings;
+ALTER TABLE embeddings_new RENAME TO embeddings;
+
+CREATE INDEX IF NOT EXISTS idx_embeddings_object_id ON embeddings(object_id);
@@ -0,0 +1,8 @@
+-- migrate:up
+
+-- deleted_at marks soft-deleted rows; NULL means the row is live.
+ALTER TABLE sources ADD COLUMN deleted_at TEXT;
+ALTER TABLE documents ADD COLUMN deleted_at TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_sources_deleted_at ON sources(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_documents_deleted_at ON documents(deleted_at);
@@ -0,0 +1,27 @@
+-- migrate:up
+
+-- download_bodies stores each distinct download body once, keyed by the hex SHA-256 of its
+-- decoded content. Downloads reference it through content_hash, so re-importing an unchanged
+-- file only records a lightweight row instead of another copy of the body. Rows written before
+-- this migration keep their inline downloads.body.
+CREATE TABLE IF NOT EXISTS download_bodies (
+    content_hash TEXT PRIMARY KEY,
+    body BLOB NOT NULL,
+    body_encoding TEXT NOT NULL DEFAULT 'identity' CHECK (body_encoding IN ('identity', 'gzip')),
+    size INTEGER NOT NULL,
+    created_at TEXT NOT NULL
+);
+
+ALTER TABLE downloads ADD COLUMN content_hash TEXT REFERENCES download_bodies(content_hash);
+
+CREATE INDEX IF NOT EXISTS idx_downloads_content_hash ON downloads(content_hash);
+
+-- drop a body once the last download referencing it is gone (pruned or purged)
+CREATE TRIGGER IF NOT EXISTS release_download_bodies
+AFTER DELETE ON downloads
+WHEN OLD.content_hash IS NOT NULL
+BEGIN
+    DELETE FROM download_bodies
+    WHERE content_hash = OLD.content_hash
+      AND NOT EXISTS (SELECT 1 FROM downloads WHERE content_hash = OLD.content_hash);
+END;
@@ -0,0 +1,22 @@
+-- migrate:up
+
+-- chunks_fts is a full-text index over chunk bodies for keyword search. Rows are written by the
+-- processing engine in the same transaction as the chunk itself; underscores are token
+-- characters so identifiers such as max_tokens are indexed whole.
+CREATE VIRTUAL TABLE IF NOT EXISTS chunks_fts USING fts5(
+    chunk_id UNINDEXED,
+    body,
+    tokenize = "unicode61 tokenchars '_'"
+);
+
+-- index chunks stored before this migration
+INSERT INTO chunks_fts (chunk_id, body)
+SELECT id, COALESCE(body, '') FROM chunks
+WHERE id NOT IN (SELECT chunk_id FROM chunks_fts);
+
+-- chunks are deleted from several places (purge, cascades), so removal is kept in sync here
+CREATE TRIGGER IF NOT EXISTS chunks_fts_delete
+AFTER DELETE ON chunks
+BEGIN
+    DELETE FROM chunks_fts WHERE chunk_id = OLD.id;
+END;
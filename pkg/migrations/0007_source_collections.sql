@@ -0,0 +1,7 @@
+-- migrate:up
+
+-- Every source belongs to a named collection such as "product-docs" or "internal-wiki", so one
+-- database can hold several corpora. Documents and chunks inherit the collection of their source.
+ALTER TABLE sources ADD COLUMN collection TEXT NOT NULL DEFAULT 'default';
+
+CREATE INDEX IF NOT EXISTS idx_sources_collection ON sources(collection);
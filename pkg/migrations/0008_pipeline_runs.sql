@@ -0,0 +1,30 @@
+-- migrate:up
+
+-- pipeline_runs records each ProcessSource invocation so an import interrupted by a crash or
+-- cancellation can be resumed instead of restarting from the first item. A run stays resumable
+-- until its status is 'completed'.
+CREATE TABLE IF NOT EXISTS pipeline_runs (
+    id TEXT PRIMARY KEY,
+    source_url TEXT NOT NULL,
+    status TEXT NOT NULL,
+    error TEXT,
+    started_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL,
+    finished_at TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_pipeline_runs_source_url ON pipeline_runs(source_url, status);
+
+-- pipeline_run_items tracks every item (a repository file, a post) of a run: 'imported' once its
+-- download is stored, 'done' once it has been chunked and embedded, 'failed' otherwise.
+CREATE TABLE IF NOT EXISTS pipeline_run_items (
+    run_id TEXT NOT NULL REFERENCES pipeline_runs(id) ON DELETE CASCADE,
+    item_key TEXT NOT NULL,
+    position INTEGER NOT NULL,
+    source_id TEXT,
+    download_id TEXT NOT NULL,
+    status TEXT NOT NULL,
+    error TEXT,
+    updated_at TEXT NOT NULL,
+    PRIMARY KEY (run_id, item_key)
+);
@@ -0,0 +1,17 @@
+-- migrate:up
+
+-- failed_chunks is the dead-letter table for chunks that could not be embedded or saved. The
+-- chunk is kept in full so `retry-failed` can re-process just those chunks; rows are removed once
+-- a retry succeeds.
+CREATE TABLE IF NOT EXISTS failed_chunks (
+    chunk_id TEXT PRIMARY KEY,
+    document_id TEXT NOT NULL,
+    chunk TEXT NOT NULL,
+    model TEXT NOT NULL,
+    error TEXT NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 1,
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_failed_chunks_document_id ON failed_chunks(document_id);
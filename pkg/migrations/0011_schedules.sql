@@ -0,0 +1,18 @@
+-- migrate:up
+
+-- schedules re-import sources on a cron cadence. The daemon enqueues an import job for every
+-- enabled schedule whose next_run_at has passed, then advances next_run_at from its expression.
+CREATE TABLE IF NOT EXISTS schedules (
+    id TEXT PRIMARY KEY,
+    source_url TEXT NOT NULL UNIQUE,
+    cron TEXT NOT NULL,
+    options TEXT NOT NULL,
+    enabled INTEGER NOT NULL DEFAULT 1,
+    next_run_at TEXT,
+    last_run_at TEXT,
+    last_error TEXT,
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_schedules_next_run_at ON schedules(enabled, next_run_at);
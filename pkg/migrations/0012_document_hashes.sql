@@ -0,0 +1,7 @@
+-- migrate:up
+
+-- content_hash is the SHA-256 of a document's transformed content. Re-imports compare it with
+-- the source's earlier documents and skip chunking and embedding when the content is unchanged.
+ALTER TABLE documents ADD COLUMN content_hash TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_documents_source_id_content_hash ON documents(source_id, content_hash);
@@ -0,0 +1,9 @@
+-- migrate:up
+
+-- Workers claim the highest-priority queued job first, oldest first within a priority, so an
+-- interactive import jumps ahead of bulk scheduled re-imports. Schedules carry the priority
+-- their jobs are enqueued with.
+ALTER TABLE jobs ADD COLUMN priority INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE schedules ADD COLUMN priority INTEGER NOT NULL DEFAULT -10;
+
+CREATE INDEX IF NOT EXISTS idx_jobs_status_priority ON jobs(status, priority DESC, created_at);
@@ -0,0 +1,15 @@
+-- migrate:up
+
+-- source_settings override the processing options for content imported from a URL. A row applies
+-- to that URL and to every item under it, such as the files of a repository or the posts of a
+-- WordPress endpoint, and the longest matching URL wins. NULL columns keep the options the import
+-- was started with.
+CREATE TABLE IF NOT EXISTS source_settings (
+    source_url TEXT PRIMARY KEY,
+    chunk_strategy TEXT,
+    max_tokens INTEGER,
+    embedding_model TEXT,
+    concurrency INTEGER,
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL
+);
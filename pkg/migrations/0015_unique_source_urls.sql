@@ -0,0 +1,59 @@
+-- migrate:up
+
+-- Importers upsert sources on their normalized raw_url, so a re-import attaches new downloads to
+-- the existing source. Before the unique index can be created, live sources sharing a raw_url are
+-- merged into the oldest one: their downloads, documents, and run items move to it and the
+-- duplicates are soft-deleted.
+UPDATE downloads SET source_id = (
+    SELECT keep.id FROM sources dup
+    JOIN sources keep ON keep.raw_url = dup.raw_url AND keep.deleted_at IS NULL
+    WHERE dup.id = downloads.source_id
+    ORDER BY keep.created_at, keep.id LIMIT 1
+)
+WHERE source_id IN (
+    SELECT dup.id FROM sources dup
+    WHERE dup.deleted_at IS NULL AND EXISTS (
+        SELECT 1 FROM sources keep
+        WHERE keep.raw_url = dup.raw_url AND keep.deleted_at IS NULL
+          AND (keep.created_at < dup.created_at OR (keep.created_at = dup.created_at AND keep.id < dup.id))
+    )
+);
+
+UPDATE documents SET source_id = (
+    SELECT keep.id FROM sources dup
+    JOIN sources keep ON keep.raw_url = dup.raw_url AND keep.deleted_at IS NULL
+    WHERE dup.id = documents.source_id
+    ORDER BY keep.created_at, keep.id LIMIT 1
+)
+WHERE source_id IN (
+    SELECT dup.id FROM sources dup
+    WHERE dup.deleted_at IS NULL AND EXISTS (
+        SELECT 1 FROM sources keep
+        WHERE keep.raw_url = dup.raw_url AND keep.deleted_at IS NULL
+          AND (keep.created_at < dup.created_at OR (keep.created_at = dup.created_at AND keep.id < dup.id))
+    )
+);
+
+UPDATE pipeline_run_items SET source_id = (
+    SELECT keep.id FROM sources dup
+    JOIN sources keep ON keep.raw_url = dup.raw_url AND keep.deleted_at IS NULL
+    WHERE dup.id = pipeline_run_items.source_id
+    ORDER BY keep.created_at, keep.id LIMIT 1
+)
+WHERE source_id IN (
+    SELECT dup.id FROM sources dup
+    WHERE dup.deleted_at IS NULL AND EXISTS (
+        SELECT 1 FROM sources keep
+        WHERE keep.raw_url = dup.raw_url AND keep.deleted_at IS NULL
+          AND (keep.created_at < dup.created_at OR (keep.created_at = dup.created_at AND keep.id < dup.id))
+    )
+);
+
+UPDATE sources SET deleted_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now')
+WHERE deleted_at IS NULL AND EXISTS (
+    SELECT 1 FROM sources keep
+    WHERE keep.raw_url = sources.raw_url AND keep.deleted_at IS NULL
+      AND (keep.created_at < sources.created_at OR (keep.created_at = sources.created_at AND keep.id < sources.id))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_sources_live_raw_url ON sources(raw_url) WHERE deleted_at IS NULL;
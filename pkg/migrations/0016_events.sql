@@ -0,0 +1,19 @@
+-- migrate:up
+
+-- events is an append-only log of what happened to sources and documents, so "when and why did
+-- this document change?" can be answered after the fact. actor names who or what caused the
+-- event, such as the CLI, a worker, or a webhook, and job_id the queued job it ran in.
+CREATE TABLE IF NOT EXISTS events (
+    id TEXT PRIMARY KEY,
+    type TEXT NOT NULL,
+    source_id TEXT,
+    document_id TEXT,
+    job_id TEXT,
+    actor TEXT,
+    detail TEXT,
+    created_at TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_events_source_id ON events(source_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_events_document_id ON events(document_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_events_created_at ON events(created_at);
@@ -0,0 +1,29 @@
+-- migrate:up
+
+-- webhook_subscriptions are outbound webhooks: the daemon POSTs every event recorded after a
+-- subscription was created whose type is in event_types, a comma-separated list that is empty
+-- for every type, to url, signed with secret.
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    id TEXT PRIMARY KEY,
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    event_types TEXT NOT NULL DEFAULT '',
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL
+);
+
+-- webhook_deliveries tracks each event owed to a subscription. Deliveries stay pending, retried
+-- at next_attempt_at, until the endpoint accepts them or they run out of attempts and fail.
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    subscription_id TEXT NOT NULL,
+    event_id TEXT NOT NULL,
+    status TEXT NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TEXT NOT NULL,
+    last_error TEXT,
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL,
+    PRIMARY KEY (subscription_id, event_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_pending ON webhook_deliveries(status, next_attempt_at);
@@ -0,0 +1,213 @@
+-- migrate:up
+
+-- Deleting a source deletes its downloads and documents, and deleting a document its chunks,
+-- embeddings, dead-lettered chunks, tags, and metadata, through ON DELETE CASCADE. Documents keep
+-- no foreign key to their download, since downloads are pruned to the last three per source
+-- while their documents stay searchable, and chunks none to their neighbours, which may be
+-- dead-lettered until retried. SQLite cannot add constraints to existing tables, so each table is
+-- rebuilt as in 0003, keeping only rows whose parent still exists: orphans left by deletes made
+-- before foreign keys were enforced are dropped. Migrations run with enforcement off, so dropping
+-- the old tables does not cascade.
+CREATE TABLE downloads_new (
+    id TEXT NOT NULL PRIMARY KEY,
+    source_id TEXT NOT NULL REFERENCES sources(id) ON DELETE CASCADE,
+    attempted_at TEXT,
+    downloaded_at TEXT,
+    status_code INTEGER,
+    headers TEXT NOT NULL,
+    body TEXT,
+    body_encoding TEXT NOT NULL DEFAULT 'identity' CHECK (body_encoding IN ('identity', 'gzip')),
+    content_hash TEXT REFERENCES download_bodies(content_hash)
+);
+
+INSERT INTO downloads_new (id, source_id, attempted_at, downloaded_at, status_code, headers, body, body_encoding,
+    content_hash)
+SELECT id, source_id, attempted_at, downloaded_at, status_code, headers, body, body_encoding,
+    (SELECT content_hash FROM download_bodies b WHERE b.content_hash = downloads.content_hash)
+FROM downloads
+WHERE source_id IN (SELECT id FROM sources);
+
+CREATE TABLE documents_new (
+    id TEXT NOT NULL PRIMARY KEY,
+    source_id TEXT NOT NULL REFERENCES sources(id) ON DELETE CASCADE,
+    download_id TEXT NOT NULL,
+    format TEXT CHECK (format IN ('json', 'yml', 'yaml')),
+    indexed_at TEXT,
+    min_chunk_size INTEGER NOT NULL,
+    max_chunk_size INTEGER NOT NULL,
+    published_at TEXT,
+    modified_at TEXT,
+    wp_version TEXT,
+    deleted_at TEXT,
+    content_hash TEXT
+);
+
+INSERT INTO documents_new (id, source_id, download_id, format, indexed_at, min_chunk_size, max_chunk_size,
+    published_at, modified_at, wp_version, deleted_at, content_hash)
+SELECT id, source_id, download_id, format, indexed_at, min_chunk_size, max_chunk_size,
+    published_at, modified_at, wp_version, deleted_at, content_hash
+FROM documents
+WHERE source_id IN (SELECT id FROM sources);
+
+CREATE TABLE chunks_new (
+    id TEXT NOT NULL PRIMARY KEY,
+    document_id TEXT NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+    parent_chunk_id TEXT,
+    left_chunk_id TEXT,
+    right_chunk_id TEXT,
+    body TEXT,
+    byte_size INTEGER,
+    tokenizer TEXT,
+    token_count INTEGER,
+    natural_lang TEXT CHECK (natural_lang IN ('en', 'fr')),
+    code_lang TEXT CHECK (code_lang IN ('python', 'sql', 'javascript'))
+);
+
+INSERT INTO chunks_new (id, document_id, parent_chunk_id, left_chunk_id, right_chunk_id, body, byte_size,
+    tokenizer, token_count, natural_lang, code_lang)
+SELECT id, document_id, parent_chunk_id, left_chunk_id, right_chunk_id, body, byte_size,
+    tokenizer, token_count, natural_lang, code_lang
+FROM chunks
+WHERE document_id IN (SELECT id FROM documents_new);
+
+CREATE TABLE embeddings_new (
+    id TEXT NOT NULL PRIMARY KEY,
+    embedding_1536 BLOB,
+    embedding_3072 BLOB,
+    embedding_768 BLOB,
+    model TEXT,
+    embedded_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+    object_id TEXT NOT NULL REFERENCES chunks(id) ON DELETE CASCADE,
+    object_type TEXT NOT NULL DEFAULT 'chunk'
+);
+
+INSERT INTO embeddings_new (id, embedding_1536, embedding_3072, embedding_768, model, embedded_at, object_id,
+    object_type)
+SELECT id, embedding_1536, embedding_3072, embedding_768, model, embedded_at, object_id, object_type
+FROM embeddings
+WHERE object_id IN (SELECT id FROM chunks_new);
+
+CREATE TABLE document_tags_new (
+    id TEXT NOT NULL PRIMARY KEY,
+    document_id TEXT NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+    tag_id TEXT NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+    UNIQUE (document_id, tag_id)
+);
+
+INSERT INTO document_tags_new (id, document_id, tag_id, created_at)
+SELECT id, document_id, tag_id, created_at
+FROM document_tags
+WHERE document_id IN (SELECT id FROM documents_new) AND tag_id IN (SELECT id FROM tags);
+
+CREATE TABLE document_meta_new (
+    id TEXT NOT NULL PRIMARY KEY,
+    document_id TEXT NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+    "key" TEXT NOT NULL,
+    meta TEXT,
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+    UNIQUE (document_id, "key")
+);
+
+INSERT INTO document_meta_new (id, document_id, "key", meta, created_at)
+SELECT id, document_id, "key", meta, created_at
+FROM document_meta
+WHERE document_id IN (SELECT id FROM documents_new);
+
+CREATE TABLE failed_chunks_new (
+    chunk_id TEXT PRIMARY KEY,
+    document_id TEXT NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+    chunk TEXT NOT NULL,
+    model TEXT NOT NULL,
+    error TEXT NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 1,
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL
+);
+
+INSERT INTO failed_chunks_new (chunk_id, document_id, chunk, model, error, attempts, created_at, updated_at)
+SELECT chunk_id, document_id, chunk, model, error, attempts, created_at, updated_at
+FROM failed_chunks
+WHERE document_id IN (SELECT id FROM documents_new);
+
+CREATE TABLE webhook_deliveries_new (
+    subscription_id TEXT NOT NULL REFERENCES webhook_subscriptions(id) ON DELETE CASCADE,
+    event_id TEXT NOT NULL,
+    status TEXT NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TEXT NOT NULL,
+    last_error TEXT,
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL,
+    PRIMARY KEY (subscription_id, event_id)
+);
+
+INSERT INTO webhook_deliveries_new (subscription_id, event_id, status, attempts, next_attempt_at, last_error,
+    created_at, updated_at)
+SELECT subscription_id, event_id, status, attempts, next_attempt_at, last_error, created_at, updated_at
+FROM webhook_deliveries
+WHERE subscription_id IN (SELECT id FROM webhook_subscriptions);
+
+-- chunks_fts rows of the dropped orphans
+DELETE FROM chunks_fts WHERE chunk_id NOT IN (SELECT id FROM chunks_new);
+
+DROP TABLE webhook_deliveries;
+DROP TABLE failed_chunks;
+DROP TABLE document_meta;
+DROP TABLE document_tags;
+DROP TABLE embeddings;
+DROP TABLE chunks;
+DROP TABLE documents;
+DROP TABLE downloads;
+
+ALTER TABLE downloads_new RENAME TO downloads;
+ALTER TABLE documents_new RENAME TO documents;
+ALTER TABLE chunks_new RENAME TO chunks;
+ALTER TABLE embeddings_new RENAME TO embeddings;
+ALTER TABLE document_tags_new RENAME TO document_tags;
+ALTER TABLE document_meta_new RENAME TO document_meta;
+ALTER TABLE failed_chunks_new RENAME TO failed_chunks;
+ALTER TABLE webhook_deliveries_new RENAME TO webhook_deliveries;
+
+-- dropping the tables dropped their indexes and triggers
+CREATE INDEX IF NOT EXISTS idx_downloads_source_id ON downloads(source_id);
+CREATE INDEX IF NOT EXISTS idx_downloads_content_hash ON downloads(content_hash);
+CREATE INDEX IF NOT EXISTS idx_documents_source_id ON documents(source_id);
+CREATE INDEX IF NOT EXISTS idx_documents_deleted_at ON documents(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_documents_source_id_content_hash ON documents(source_id, content_hash);
+CREATE INDEX IF NOT EXISTS idx_chunks_document_id ON chunks(document_id);
+CREATE INDEX IF NOT EXISTS idx_embeddings_object_id ON embeddings(object_id);
+CREATE INDEX IF NOT EXISTS idx_document_tags_document_id ON document_tags(document_id);
+CREATE INDEX IF NOT EXISTS idx_document_tags_tag_id ON document_tags(tag_id);
+CREATE INDEX IF NOT EXISTS idx_document_meta_document_id ON document_meta(document_id);
+CREATE INDEX IF NOT EXISTS idx_failed_chunks_document_id ON failed_chunks(document_id);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_pending ON webhook_deliveries(status, next_attempt_at);
+
+CREATE TRIGGER IF NOT EXISTS maintain_last_3_downloads
+AFTER INSERT ON downloads
+BEGIN
+    DELETE FROM downloads
+    WHERE source_id = NEW.source_id
+      AND id NOT IN (
+        SELECT id
+        FROM downloads
+        WHERE source_id = NEW.source_id
+        ORDER BY downloaded_at DESC NULLS LAST
+        LIMIT 3
+      );
+END;
+
+CREATE TRIGGER IF NOT EXISTS release_download_bodies
+AFTER DELETE ON downloads
+WHEN OLD.content_hash IS NOT NULL
+BEGIN
+    DELETE FROM download_bodies
+    WHERE content_hash = OLD.content_hash
+      AND NOT EXISTS (SELECT 1 FROM downloads WHERE content_hash = OLD.content_hash);
+END;
+
+CREATE TRIGGER IF NOT EXISTS chunks_fts_delete
+AFTER DELETE ON chunks
+BEGIN
+    DELETE FROM chunks_fts WHERE chunk_id = OLD.id;
+END;
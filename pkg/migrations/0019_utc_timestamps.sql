@@ -0,0 +1,20 @@
+-- migrate:up
+
+-- Importers stored timestamps in the server's local time with an offset, e.g.
+-- 2024-05-06T14:08:09+02:00, while everything else stores UTC as 2024-05-06T12:08:09Z, so string
+-- comparisons and ORDER BY downloaded_at mixed up rows written in different zones. Rewrite them in
+-- UTC; values SQLite cannot parse are left as they are.
+UPDATE sources SET created_at = strftime('%Y-%m-%dT%H:%M:%SZ', created_at)
+WHERE created_at NOT LIKE '%Z' AND strftime('%Y-%m-%dT%H:%M:%SZ', created_at) IS NOT NULL;
+
+UPDATE sources SET updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', updated_at)
+WHERE updated_at NOT LIKE '%Z' AND strftime('%Y-%m-%dT%H:%M:%SZ', updated_at) IS NOT NULL;
+
+UPDATE downloads SET attempted_at = strftime('%Y-%m-%dT%H:%M:%SZ', attempted_at)
+WHERE attempted_at NOT LIKE '%Z' AND strftime('%Y-%m-%dT%H:%M:%SZ', attempted_at) IS NOT NULL;
+
+UPDATE downloads SET downloaded_at = strftime('%Y-%m-%dT%H:%M:%SZ', downloaded_at)
+WHERE downloaded_at NOT LIKE '%Z' AND strftime('%Y-%m-%dT%H:%M:%SZ', downloaded_at) IS NOT NULL;
+
+UPDATE download_bodies SET created_at = strftime('%Y-%m-%dT%H:%M:%SZ', created_at)
+WHERE created_at NOT LIKE '%Z' AND strftime('%Y-%m-%dT%H:%M:%SZ', created_at) IS NOT NULL;
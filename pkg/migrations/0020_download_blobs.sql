@@ -0,0 +1,6 @@
+-- migrate:up
+
+-- Bodies of at least IKE_BLOB_MIN_SIZE bytes are kept in the blob store named by IKE_BLOB_STORE
+-- rather than in the database. Their download_bodies row records the blob's key, with an empty
+-- body; body_encoding and size still describe the blob.
+ALTER TABLE download_bodies ADD COLUMN blob_key TEXT;
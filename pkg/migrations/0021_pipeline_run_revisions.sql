@@ -0,0 +1,8 @@
+-- migrate:up
+
+-- revision is the version of the source a run imported, such as the tree a repository branch
+-- pointed at, and embedding_model the model it embedded with. Importers that can tell what changed
+-- since a revision, such as the GitHub importer, import only that when the source's last
+-- completed run embedded with the same model.
+ALTER TABLE pipeline_runs ADD COLUMN revision TEXT;
+ALTER TABLE pipeline_runs ADD COLUMN embedding_model TEXT;
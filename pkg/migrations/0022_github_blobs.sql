@@ -0,0 +1,23 @@
+-- migrate:up
+
+-- github_blobs maps the blob SHA of a GitHub file to its stored body, so a file already imported
+-- from any repository, branch, or path, such as the same file in a fork or mirror, is not fetched
+-- from the contents API again. Entries go with their body once no download references it.
+CREATE TABLE IF NOT EXISTS github_blobs (
+    sha TEXT PRIMARY KEY,
+    content_hash TEXT NOT NULL REFERENCES download_bodies(content_hash) ON DELETE CASCADE,
+    encoding TEXT NOT NULL,
+    created_at TEXT NOT NULL
+);
+
+-- Bodies of GitHub downloads already stored, except those stored before content was decoded at
+-- import, which lack the X-GitHub-Encoding header
+INSERT OR IGNORE INTO github_blobs (sha, content_hash, encoding, created_at)
+SELECT json_extract(headers, '$."X-GitHub-SHA"[0]'), content_hash,
+    json_extract(headers, '$."X-GitHub-Encoding"[0]'), COALESCE(downloaded_at, attempted_at)
+FROM downloads
+WHERE content_hash IN (SELECT content_hash FROM download_bodies)
+  AND json_valid(headers)
+  AND json_extract(headers, '$."X-GitHub-SHA"[0]') != ''
+  AND json_extract(headers, '$."X-GitHub-Encoding"[0]') IS NOT NULL
+ORDER BY downloaded_at;
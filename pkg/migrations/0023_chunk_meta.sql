@@ -0,0 +1,14 @@
+-- migrate:up
+
+-- chunk_meta holds metadata of single chunks, such as the heading path, line range, or page a
+-- chunk was cut from, as document_meta does for documents: one JSON value per key.
+CREATE TABLE IF NOT EXISTS chunk_meta (
+    id TEXT NOT NULL PRIMARY KEY,
+    chunk_id TEXT NOT NULL REFERENCES chunks(id) ON DELETE CASCADE,
+    "key" TEXT NOT NULL,
+    meta TEXT,
+    created_at TEXT NOT NULL,
+    UNIQUE (chunk_id, "key")
+);
+
+CREATE INDEX IF NOT EXISTS idx_chunk_meta_chunk_id ON chunk_meta(chunk_id);
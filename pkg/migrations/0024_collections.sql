@@ -0,0 +1,16 @@
+-- migrate:up
+
+-- collections names the corpora sources are grouped into (see 0007), with a description, so a
+-- collection can be listed, described, and deleted as a whole rather than only filtered on.
+-- Sources keep the collection's name; documents and chunks inherit it from their source.
+CREATE TABLE IF NOT EXISTS collections (
+    name TEXT NOT NULL PRIMARY KEY,
+    description TEXT,
+    created_at TEXT NOT NULL
+);
+
+INSERT OR IGNORE INTO collections (name, created_at)
+VALUES ('default', strftime('%Y-%m-%dT%H:%M:%SZ', 'now'));
+
+INSERT OR IGNORE INTO collections (name, created_at)
+SELECT collection, MIN(created_at) FROM sources GROUP BY collection;
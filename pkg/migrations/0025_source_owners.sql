@@ -0,0 +1,14 @@
+-- migrate:up
+
+-- source_owners records who owns each source: teams and people named in a repository's
+-- CODEOWNERS, the authors of WordPress posts, or owners given at import with --owner. A source
+-- can have several owners, and sources.author_email keeps the email of its last author where
+-- the importer knows it.
+CREATE TABLE IF NOT EXISTS source_owners (
+    source_id TEXT NOT NULL REFERENCES sources(id) ON DELETE CASCADE,
+    owner TEXT NOT NULL,
+    created_at TEXT NOT NULL,
+    PRIMARY KEY (source_id, owner)
+);
+
+CREATE INDEX IF NOT EXISTS idx_source_owners_owner ON source_owners(owner);
@@ -0,0 +1,15 @@
+-- migrate:up
+
+-- Source health: when content was last imported from a source without error, the last error its
+-- imports ended with, and how many imports in a row have failed, so dead feeds and revoked
+-- tokens show up in `sources doctor` instead of silently going stale. Existing sources start
+-- from the last completed run that imported them.
+ALTER TABLE sources ADD COLUMN last_success_at TEXT;
+ALTER TABLE sources ADD COLUMN last_error TEXT;
+ALTER TABLE sources ADD COLUMN consecutive_failure_count INTEGER NOT NULL DEFAULT 0;
+
+UPDATE sources SET last_success_at = (
+    SELECT MAX(r.finished_at) FROM pipeline_runs r
+    JOIN pipeline_run_items i ON i.run_id = r.id
+    WHERE i.source_id = sources.id AND r.status = 'completed'
+);
@@ -0,0 +1,11 @@
+-- migrate:up
+
+-- Document TTLs: source_settings can give the content under a URL a time to live, after which
+-- `documents expire` marks its documents stale, leaving them out of search unless asked for, or
+-- purges them. Useful for promotions and release announcements that should not outlive their
+-- relevance. Ages run from when a document was published, else from when it was indexed.
+ALTER TABLE source_settings ADD COLUMN ttl_seconds INTEGER;
+ALTER TABLE source_settings ADD COLUMN expiry_action TEXT CHECK (expiry_action IN ('stale', 'purge'));
+ALTER TABLE documents ADD COLUMN stale_at TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_documents_stale_at ON documents(stale_at);
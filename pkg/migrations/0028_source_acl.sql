@@ -0,0 +1,18 @@
+-- migrate:up
+
+-- source_acl holds the access-control labels of each source's documents, such as public,
+-- internal, or private from a GitHub repository's visibility, or the groups source_settings
+-- give the content under a URL. Each import replaces the labels of what it imports. Searches
+-- only return documents whose source has no labels, is labeled public, or has a label the
+-- caller was granted.
+CREATE TABLE IF NOT EXISTS source_acl (
+    source_id TEXT NOT NULL REFERENCES sources(id) ON DELETE CASCADE,
+    label TEXT NOT NULL,
+    created_at TEXT NOT NULL,
+    PRIMARY KEY (source_id, label)
+);
+
+CREATE INDEX IF NOT EXISTS idx_source_acl_label ON source_acl(label);
+
+-- acl_labels is a JSON array of labels replacing those importers derive for content under the URL.
+ALTER TABLE source_settings ADD COLUMN acl_labels TEXT;
@@ -0,0 +1,16 @@
+-- migrate:up
+
+-- source_credentials hold the API keys and tokens imports of content under a URL authenticate
+-- with, for deployments importing on behalf of several customers. Like source_settings, a row
+-- applies to that URL and to every item under it, and the longest matching URL wins. NULL columns
+-- keep the credentials the import was started with, and credentials a caller passes with an
+-- import win over the stored ones. Values are stored as given, so whoever can read the database
+-- can read them.
+CREATE TABLE IF NOT EXISTS source_credentials (
+    source_url TEXT PRIMARY KEY,
+    github_token TEXT,
+    openai_api_key TEXT,
+    together_api_key TEXT,
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL
+);
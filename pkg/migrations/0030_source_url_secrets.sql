@@ -0,0 +1,6 @@
+-- migrate:up
+
+-- url_secrets holds the credentials stripped from a source's URL before it is stored, such as an
+-- access_token query parameter, encoded as a query string, so the source can be fetched again
+-- without sources.raw_url carrying them. Like the other columns, they are stored as given.
+ALTER TABLE source_credentials ADD COLUMN url_secrets TEXT;
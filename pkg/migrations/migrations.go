@@ -0,0 +1,265 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	ErrSchemaOutdated      = errors.New("database schema is older than this binary")
+	ErrSchemaTooNew        = errors.New("database schema is newer than this binary")
+	ErrForeignKeyViolation = errors.New("foreign key violation")
+)
+
+//go:embed *.sql
+var files embed.FS
+
+// createSchemaVersion holds the single-row table recording the schema version the database was
+// last migrated to; startup checks compare it against Latest.
+const createSchemaVersion = `CREATE TABLE IF NOT EXISTS schema_version (
+	id INTEGER PRIMARY KEY CHECK (id = 1),
+	version TEXT NOT NULL,
+	updated_at TEXT NOT NULL
+)`
+
+// Migration is a single versioned schema change.
+type Migration struct {
+	Version string
+	SQL     string
+}
+
+// All returns the embedded migrations ordered by version.
+func All() ([]Migration, error) {
+	names, err := fs.Glob(files, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+
+	migrations := make([]Migration, 0, len(names))
+	for _, name := range names {
+		content, err := files.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		migrations = append(migrations, Migration{
+			Version: strings.TrimSuffix(name, ".sql"),
+			SQL:     string(content),
+		})
+	}
+
+	return migrations, nil
+}
+
+// Latest returns the version of the newest embedded migration, i.e. the schema version this
+// binary expects.
+func Latest() (string, error) {
+	all, err := All()
+	if err != nil {
+		return "", err
+	}
+	if len(all) == 0 {
+		return "", nil
+	}
+	return all[len(all)-1].Version, nil
+}
+
+// Current returns the schema version recorded in schema_version, or "" when the database has
+// never been migrated by a binary that records it.
+func Current(ctx context.Context, db *sql.DB) (string, error) {
+	var exists int
+	err := db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_version'`).Scan(&exists)
+	if err != nil || exists == 0 {
+		return "", err
+	}
+
+	var version string
+	err = db.QueryRowContext(ctx, `SELECT version FROM schema_version WHERE id = 1`).Scan(&version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return version, err
+}
+
+// Check verifies that the database schema matches the version this binary was built for, so
+// commands fail up front with a clear error instead of mid-pipeline.
+func Check(ctx context.Context, db *sql.DB) error {
+	latest, err := Latest()
+	if err != nil {
+		return err
+	}
+
+	current, err := Current(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	return compareVersions(current, latest)
+}
+
+func compareVersions(current, latest string) error {
+	switch {
+	case current < latest:
+		if current == "" {
+			current = "unversioned"
+		}
+		return fmt.Errorf("%w: database is at %s, binary expects %s; run `ike-go migrate` to upgrade",
+			ErrSchemaOutdated, current, latest)
+	case current > latest:
+		return fmt.Errorf("%w: database is at %s, binary expects %s; upgrade ike-go to a newer release",
+			ErrSchemaTooNew, current, latest)
+	default:
+		return nil
+	}
+}
+
+// Applied returns the versions already recorded in schema_migrations.
+func Applied(ctx context.Context, db *sql.DB) (map[string]bool, error) {
+	rows, err := db.QueryContext(ctx, `SELECT version FROM schema_migrations WHERE version IS NOT NULL`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[string]bool)
+	for rows.Next() {
+		var version string
+		if err := rows.Scan(&version); err != nil {
+			return nil, err
+		}
+		applied[version] = true
+	}
+
+	return applied, rows.Err()
+}
+
+// Apply runs every embedded migration that has not been recorded in schema_migrations yet,
+// each in its own transaction, and returns the versions it applied.
+func Apply(ctx context.Context, db *sql.DB) ([]string, error) {
+	// The baseline migration creates schema_migrations, but databases created before versioned
+	// migrations existed may predate it, so make sure it is there before reading from it.
+	if _, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (version TEXT)`); err != nil {
+		return nil, err
+	}
+	if _, err := db.ExecContext(ctx, createSchemaVersion); err != nil {
+		return nil, err
+	}
+
+	all, err := All()
+	if err != nil {
+		return nil, err
+	}
+
+	applied, err := Applied(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []string
+	for _, migration := range all {
+		if applied[migration.Version] {
+			continue
+		}
+		if err := apply(ctx, db, migration); err != nil {
+			return versions, err
+		}
+		versions = append(versions, migration.Version)
+	}
+
+	// Databases fully migrated before schema_version existed have nothing left to apply, so record
+	// their version here.
+	if len(versions) == 0 && len(all) > 0 {
+		if err := setVersion(ctx, db, all[len(all)-1].Version); err != nil {
+			return nil, err
+		}
+	}
+
+	return versions, nil
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+func setVersion(ctx context.Context, db execer, version string) error {
+	_, err := db.ExecContext(ctx, `INSERT INTO schema_version (id, version, updated_at) VALUES (1, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET version = excluded.version, updated_at = excluded.updated_at`,
+		version, time.Now().UTC().Format(time.RFC3339))
+	return err
+}
+
+// apply runs migration in a transaction with foreign key enforcement off, following SQLite's
+// procedure for schema changes: dropping a table with enforcement on deletes its rows first,
+// which would cascade into the tables that reference it when a migration rebuilds one. Foreign
+// keys are checked before the migration commits instead.
+func apply(ctx context.Context, db *sql.DB, migration Migration) error {
+	// Pragmas apply per connection, so the migration must run on the one they were set on
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var foreignKeys int
+	if err := conn.QueryRowContext(ctx, `PRAGMA foreign_keys`).Scan(&foreignKeys); err != nil {
+		return err
+	}
+	if _, err := conn.ExecContext(ctx, `PRAGMA foreign_keys = OFF`); err != nil {
+		return err
+	}
+	defer func() {
+		_, _ = conn.ExecContext(context.WithoutCancel(ctx), fmt.Sprintf(`PRAGMA foreign_keys = %d`, foreignKeys))
+	}()
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if _, err := tx.ExecContext(ctx, migration.SQL); err != nil {
+		return err
+	}
+	if err := checkForeignKeys(ctx, tx); err != nil {
+		return fmt.Errorf("%s: %w", migration.Version, err)
+	}
+	_, err = tx.ExecContext(ctx, `INSERT INTO schema_migrations (version) VALUES (?)`, migration.Version)
+	if err != nil {
+		return err
+	}
+	if err := setVersion(ctx, tx, migration.Version); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// checkForeignKeys returns ErrForeignKeyViolation for the first row referencing a missing one.
+func checkForeignKeys(ctx context.Context, tx *sql.Tx) error {
+	rows, err := tx.QueryContext(ctx, `PRAGMA foreign_key_check`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		var table, parent string
+		var rowID sql.NullInt64
+		var index int
+		if err := rows.Scan(&table, &rowID, &parent, &index); err != nil {
+			return err
+		}
+		return fmt.Errorf("%w: a row of %s references a missing row of %s", ErrForeignKeyViolation, table, parent)
+	}
+	return rows.Err()
+}
@@ -0,0 +1,77 @@
+package migrations
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAll(t *testing.T) {
+	all, err := All()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(all) == 0 {
+		t.Fatal("Expected embedded migrations")
+	}
+
+	for i := 1; i < len(all); i++ {
+		if all[i-1].Version >= all[i].Version {
+			t.Errorf("Expected migrations in ascending order, got %s before %s", all[i-1].Version, all[i].Version)
+		}
+	}
+
+	latest, err := Latest()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if latest != all[len(all)-1].Version {
+		t.Errorf("Expected latest version %s, got %s", all[len(all)-1].Version, latest)
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		name        string
+		current     string
+		latest      string
+		expectedErr error
+		description string
+	}{
+		{
+			name:        "up to date",
+			current:     "0005_download_bodies",
+			latest:      "0005_download_bodies",
+			description: "should accept a database at the binary's version",
+		},
+		{
+			name:        "outdated",
+			current:     "0003_embedding_blobs",
+			latest:      "0005_download_bodies",
+			expectedErr: ErrSchemaOutdated,
+			description: "should ask for a migration when the database is behind",
+		},
+		{
+			name:        "unversioned",
+			current:     "",
+			latest:      "0005_download_bodies",
+			expectedErr: ErrSchemaOutdated,
+			description: "should treat a database without schema_version as outdated",
+		},
+		{
+			name:        "too new",
+			current:     "0009_future",
+			latest:      "0005_download_bodies",
+			expectedErr: ErrSchemaTooNew,
+			description: "should refuse a database migrated by a newer binary",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := compareVersions(tt.current, tt.latest)
+			if !errors.Is(err, tt.expectedErr) {
+				t.Errorf("Expected error %v, got %v", tt.expectedErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+// Package stmtcache keeps prepared statements for queries run once per row of an ingest, such as
+// chunk, embedding, and metadata inserts, so the database parses them once rather than on every
+// call. A statement is cached per database handle; database/sql prepares it again on each pool
+// connection it first runs on and keeps it prepared there, so each connection parses a query once.
+package stmtcache
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+)
+
+// Cache holds the prepared statements of queries by database handle.
+type Cache struct {
+	mu    sync.Mutex
+	stmts map[key]*sql.Stmt
+}
+
+type key struct {
+	db    *sql.DB
+	query string
+}
+
+// New returns an empty cache.
+func New() *Cache {
+	return &Cache{stmts: make(map[key]*sql.Stmt)}
+}
+
+// defaultCache is the cache used by the package-level functions.
+var defaultCache = New()
+
+// Prepare returns the statement of query on db, preparing it on first use.
+func (c *Cache) Prepare(ctx context.Context, db *sql.DB, query string) (*sql.Stmt, error) {
+	k := key{db: db, query: query}
+	c.mu.Lock()
+	stmt, ok := c.stmts[k]
+	c.mu.Unlock()
+	if ok {
+		return stmt, nil
+	}
+
+	// Prepare without holding the lock, so a slow database doesn't hold up other queries
+	stmt, err := db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cached, ok := c.stmts[k]; ok {
+		// Another caller prepared it first
+		_ = stmt.Close()
+		return cached, nil
+	}
+	c.stmts[k] = stmt
+	return stmt, nil
+}
+
+// Exec runs query on db through its cached statement.
+func (c *Cache) Exec(ctx context.Context, db *sql.DB, query string, args ...interface{}) (sql.Result, error) {
+	stmt, err := c.Prepare(ctx, db, query)
+	if err != nil {
+		return nil, err
+	}
+	return stmt.ExecContext(ctx, args...)
+}
+
+// Forget closes and drops the statements prepared on db. Call it when db is closed.
+func (c *Cache) Forget(db *sql.DB) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k, stmt := range c.stmts {
+		if k.db == db {
+			_ = stmt.Close()
+			delete(c.stmts, k)
+		}
+	}
+}
+
+// Exec runs query on db through the default cache.
+func Exec(ctx context.Context, db *sql.DB, query string, args ...interface{}) (sql.Result, error) {
+	return defaultCache.Exec(ctx, db, query, args...)
+}
+
+// Prepare returns the statement of query on db from the default cache. To run it in a transaction,
+// prepare it before beginning the transaction and pass it to Tx.StmtContext: preparing takes a
+// connection, which a transaction holding the pool's only connection would wait on forever.
+func Prepare(ctx context.Context, db *sql.DB, query string) (*sql.Stmt, error) {
+	return defaultCache.Prepare(ctx, db, query)
+}
+
+// Forget drops the statements the default cache prepared on db. Call it when db is closed.
+func Forget(db *sql.DB) {
+	defaultCache.Forget(db)
+}
@@ -0,0 +1,127 @@
+package stmtcache
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"sync/atomic"
+	"testing"
+)
+
+// countingDriver is a database/sql driver whose statements do nothing but count how often they are
+// prepared.
+type countingDriver struct {
+	prepares atomic.Int32
+}
+
+func (d *countingDriver) Open(string) (driver.Conn, error) { return &countingConn{driver: d}, nil }
+
+type countingConn struct {
+	driver *countingDriver
+}
+
+func (c *countingConn) Prepare(string) (driver.Stmt, error) {
+	c.driver.prepares.Add(1)
+	return countingStmt{}, nil
+}
+func (c *countingConn) Close() error              { return nil }
+func (c *countingConn) Begin() (driver.Tx, error) { return countingTx{}, nil }
+
+type countingStmt struct{}
+
+func (countingStmt) Close() error                               { return nil }
+func (countingStmt) NumInput() int                              { return -1 }
+func (countingStmt) Exec([]driver.Value) (driver.Result, error) { return driver.RowsAffected(1), nil }
+func (countingStmt) Query([]driver.Value) (driver.Rows, error)  { return nil, driver.ErrSkip }
+
+type countingTx struct{}
+
+func (countingTx) Commit() error   { return nil }
+func (countingTx) Rollback() error { return nil }
+
+func openCounting(t *testing.T) (*sql.DB, *countingDriver) {
+	t.Helper()
+	countingDriver := &countingDriver{}
+	db := sql.OpenDB(dsnConnector{driver: countingDriver})
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = db.Close() })
+	return db, countingDriver
+}
+
+type dsnConnector struct {
+	driver *countingDriver
+}
+
+func (c dsnConnector) Connect(context.Context) (driver.Conn, error) { return c.driver.Open("") }
+func (c dsnConnector) Driver() driver.Driver                        { return c.driver }
+
+func TestCache_Exec(t *testing.T) {
+	db, countingDriver := openCounting(t)
+	cache := New()
+	ctx := context.Background()
+
+	for range 5 {
+		if _, err := cache.Exec(ctx, db, "INSERT INTO chunks (id) VALUES (?)", "id"); err != nil {
+			t.Fatalf("Exec failed: %v", err)
+		}
+	}
+	if _, err := cache.Exec(ctx, db, "INSERT INTO embeddings (id) VALUES (?)", "id"); err != nil {
+		t.Fatalf("Exec failed: %v", err)
+	}
+
+	if got := countingDriver.prepares.Load(); got != 2 {
+		t.Errorf("Expected each query to be prepared once, got %d prepares", got)
+	}
+	if len(cache.stmts) != 2 {
+		t.Errorf("Expected 2 cached statements, got %d", len(cache.stmts))
+	}
+}
+
+func TestCache_Prepare_Transaction(t *testing.T) {
+	db, countingDriver := openCounting(t)
+	cache := New()
+	ctx := context.Background()
+
+	for range 3 {
+		stmt, err := cache.Prepare(ctx, db, "INSERT INTO chunks (id) VALUES (?)")
+		if err != nil {
+			t.Fatalf("Prepare failed: %v", err)
+		}
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			t.Fatalf("BeginTx failed: %v", err)
+		}
+		if _, err := tx.StmtContext(ctx, stmt).ExecContext(ctx, "id"); err != nil {
+			t.Fatalf("Exec in transaction failed: %v", err)
+		}
+		if err := tx.Commit(); err != nil {
+			t.Fatalf("Commit failed: %v", err)
+		}
+	}
+
+	// The transactions ran on the connection the statement was prepared on, so it was reused
+	if got := countingDriver.prepares.Load(); got != 1 {
+		t.Errorf("Expected the query to be prepared once, got %d prepares", got)
+	}
+}
+
+func TestCache_Forget(t *testing.T) {
+	db, _ := openCounting(t)
+	other, _ := openCounting(t)
+	cache := New()
+	ctx := context.Background()
+
+	for _, handle := range []*sql.DB{db, other} {
+		if _, err := cache.Exec(ctx, handle, "INSERT INTO chunks (id) VALUES (?)", "id"); err != nil {
+			t.Fatalf("Exec failed: %v", err)
+		}
+	}
+
+	cache.Forget(db)
+	if len(cache.stmts) != 1 {
+		t.Fatalf("Expected only the other handle's statement to remain, got %d", len(cache.stmts))
+	}
+	if _, ok := cache.stmts[key{db: other, query: "INSERT INTO chunks (id) VALUES (?)"}]; !ok {
+		t.Error("Expected the other handle's statement to be kept")
+	}
+}
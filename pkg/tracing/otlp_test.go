@@ -0,0 +1,119 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestOTLPExporter_Export(t *testing.T) {
+	var body otlpRequest
+	var header string
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get("X-Api-Key")
+		if r.URL.Path != "/v1/traces" || r.Header.Get("Content-Type") != "application/json" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer collector.Close()
+
+	exporter, err := NewOTLPExporter(collector.URL+"/v1/traces", map[string]string{"X-Api-Key": "secret"}, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	start := time.Unix(1700000000, 0)
+	err = exporter.Export(context.Background(), []SpanData{{
+		TraceID: "0af7651916cd43dd8448eb211c80319c", SpanID: "b7ad6b7169203331", Name: "ike.transform",
+		Start: start, End: start.Add(time.Second), Err: errors.New("transform failed"),
+		Attributes: []Attribute{String("document.id", "doc-1"), Int("chunk.count", 4), Bool("forced", true)},
+	}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if header != "secret" {
+		t.Errorf("Expected the configured header, got %q", header)
+	}
+	if len(body.ResourceSpans) != 1 || len(body.ResourceSpans[0].ScopeSpans) != 1 {
+		t.Fatalf("Unexpected request body: %+v", body)
+	}
+	service := body.ResourceSpans[0].Resource.Attributes[0]
+	if service.Key != "service.name" || *service.Value.StringValue != defaultServiceName {
+		t.Errorf("Expected service.name %q, got %+v", defaultServiceName, service)
+	}
+
+	span := body.ResourceSpans[0].ScopeSpans[0].Spans[0]
+	if span.Name != "ike.transform" || span.StartTimeUnixNano != "1700000000000000000" {
+		t.Errorf("Unexpected span: %+v", span)
+	}
+	if span.Status == nil || span.Status.Code != statusCodeError || span.Status.Message != "transform failed" {
+		t.Errorf("Expected an error status, got %+v", span.Status)
+	}
+	if len(span.Attributes) != 3 || *span.Attributes[1].Value.IntValue != "4" || !*span.Attributes[2].Value.BoolValue {
+		t.Errorf("Unexpected attributes: %+v", span.Attributes)
+	}
+}
+
+func TestOTLPExporter_CollectorError(t *testing.T) {
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer collector.Close()
+
+	exporter, err := NewOTLPExporter(collector.URL, nil, "ike-test")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	err = exporter.Export(context.Background(), []SpanData{{Name: "ike.save"}})
+	if !errors.Is(err, ErrExportFailed) {
+		t.Errorf("Expected ErrExportFailed, got %v", err)
+	}
+}
+
+func TestNewOTLPExporter_InvalidEndpoint(t *testing.T) {
+	for _, endpoint := range []string{"", "localhost:4318", "ftp://collector/v1/traces", "http://"} {
+		if _, err := NewOTLPExporter(endpoint, nil, ""); !errors.Is(err, ErrInvalidEndpoint) {
+			t.Errorf("Expected ErrInvalidEndpoint for %q, got %v", endpoint, err)
+		}
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	if _, err := FromEnv(); !errors.Is(err, ErrNotConfigured) {
+		t.Fatalf("Expected ErrNotConfigured without an endpoint, got %v", err)
+	}
+
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://localhost:4318/")
+	t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "x-api-key=a%20b, ,tenant=ike")
+	t.Setenv("OTEL_SERVICE_NAME", "ike-worker")
+	tracer, err := FromEnv()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer tracer.Shutdown(context.Background())
+
+	exporter, ok := tracer.exporter.(*OTLPExporter)
+	if !ok {
+		t.Fatalf("Expected an OTLPExporter, got %T", tracer.exporter)
+	}
+	if exporter.endpoint != "http://localhost:4318/v1/traces" {
+		t.Errorf("Expected /v1/traces appended to the endpoint, got %q", exporter.endpoint)
+	}
+	if exporter.serviceName != "ike-worker" {
+		t.Errorf("Expected service name ike-worker, got %q", exporter.serviceName)
+	}
+	if exporter.headers["x-api-key"] != "a b" || exporter.headers["tenant"] != "ike" || len(exporter.headers) != 2 {
+		t.Errorf("Unexpected headers: %v", exporter.headers)
+	}
+}
@@ -0,0 +1,285 @@
+// Package tracing records spans around the stages of the processing pipeline and exports them
+// to an OpenTelemetry collector over OTLP/HTTP, so the slow stages of a long import can be found
+// in any tracing backend. Spans are only recorded once a Tracer is installed with SetTracer;
+// until then Start returns a nil *Span, whose methods do nothing.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultBatchSize is how many ended spans are exported in one request.
+	defaultBatchSize = 512
+	// defaultQueueSize bounds the spans waiting for export; newer spans are dropped beyond it.
+	defaultQueueSize = 4096
+	// defaultFlushInterval is how often spans are exported when fewer than a batch have ended.
+	defaultFlushInterval = 5 * time.Second
+)
+
+// Exporter sends ended spans to a tracing backend.
+type Exporter interface {
+	Export(ctx context.Context, spans []SpanData) error
+}
+
+// Attribute is a key and value recorded on a span.
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// String returns a string attribute.
+func String(key, value string) Attribute { return Attribute{Key: key, Value: value} }
+
+// Int returns an integer attribute.
+func Int(key string, value int) Attribute { return Attribute{Key: key, Value: int64(value)} }
+
+// Bool returns a boolean attribute.
+func Bool(key string, value bool) Attribute { return Attribute{Key: key, Value: value} }
+
+// SpanData is an ended span as handed to an Exporter.
+type SpanData struct {
+	TraceID    string
+	SpanID     string
+	ParentID   string
+	Name       string
+	Start      time.Time
+	End        time.Time
+	Attributes []Attribute
+	Err        error
+}
+
+// Span is an operation being traced. A nil *Span is valid and records nothing.
+type Span struct {
+	tracer *Tracer
+	mu     sync.Mutex
+	data   SpanData
+	ended  bool
+}
+
+// SetAttributes records attrs on the span, for example IDs only known once the operation ran.
+func (s *Span) SetAttributes(attrs ...Attribute) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Attributes = append(s.data.Attributes, attrs...)
+}
+
+// RecordError marks the span as failed with err; a nil err is ignored.
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Err = err
+}
+
+// End finishes the span and queues it for export. Only the first call has an effect.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.End = time.Now()
+	data := s.data
+	s.mu.Unlock()
+
+	s.tracer.enqueue(data)
+}
+
+type spanKey struct{}
+
+// Tracer batches ended spans and exports them in the background.
+type Tracer struct {
+	exporter  Exporter
+	batchSize int
+	queueSize int
+
+	mu      sync.Mutex
+	queue   []SpanData
+	dropped int
+	flush   chan struct{}
+	stop    chan struct{}
+	done    chan struct{}
+	once    sync.Once
+}
+
+// NewTracer creates a tracer that exports through exporter every flushInterval, or as soon as a
+// batch of spans has ended. A flushInterval <= 0 uses the default of 5 seconds.
+func NewTracer(exporter Exporter, flushInterval time.Duration) *Tracer {
+	if flushInterval <= 0 {
+		flushInterval = defaultFlushInterval
+	}
+	t := &Tracer{
+		exporter:  exporter,
+		batchSize: defaultBatchSize,
+		queueSize: defaultQueueSize,
+		flush:     make(chan struct{}, 1),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	go t.run(flushInterval)
+	return t
+}
+
+var (
+	globalMu sync.RWMutex
+	global   *Tracer
+)
+
+// SetTracer installs t as the tracer Start records spans with; nil disables tracing.
+func SetTracer(t *Tracer) {
+	globalMu.Lock()
+	defer globalMu.Unlock()
+	global = t
+}
+
+// Start begins a span named name as a child of the span in ctx, if any, and returns a context
+// carrying it. It returns ctx and a nil span when no tracer is installed.
+func Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, *Span) {
+	globalMu.RLock()
+	tracer := global
+	globalMu.RUnlock()
+	if tracer == nil {
+		return ctx, nil
+	}
+
+	span := &Span{tracer: tracer, data: SpanData{
+		SpanID:     newID(8),
+		Name:       name,
+		Start:      time.Now(),
+		Attributes: attrs,
+	}}
+	if parent, ok := ctx.Value(spanKey{}).(*Span); ok {
+		span.data.TraceID, span.data.ParentID = parent.data.TraceID, parent.data.SpanID
+	} else {
+		span.data.TraceID = newID(16)
+	}
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// SpanFromContext returns the span carried by ctx, or nil when there is none.
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+// Shutdown exports the spans still queued and stops the tracer. Spans ended afterwards are
+// dropped.
+func (t *Tracer) Shutdown(ctx context.Context) error {
+	if t == nil {
+		return nil
+	}
+	t.once.Do(func() { close(t.stop) })
+	select {
+	case <-t.done:
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	}
+	return t.export(ctx)
+}
+
+// Shutdown shuts down the installed tracer, if any.
+func Shutdown(ctx context.Context) error {
+	globalMu.RLock()
+	tracer := global
+	globalMu.RUnlock()
+	return tracer.Shutdown(ctx)
+}
+
+func (t *Tracer) enqueue(data SpanData) {
+	t.mu.Lock()
+	select {
+	case <-t.stop:
+		t.mu.Unlock()
+		return
+	default:
+	}
+	if len(t.queue) >= t.queueSize {
+		t.dropped++
+		t.mu.Unlock()
+		return
+	}
+	t.queue = append(t.queue, data)
+	full := len(t.queue) >= t.batchSize
+	t.mu.Unlock()
+
+	if full {
+		select {
+		case t.flush <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// run exports queued spans until Shutdown is called. Export errors are dropped so a collector
+// outage never fails the pipeline it traces.
+func (t *Tracer) run(interval time.Duration) {
+	defer close(t.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-t.stop:
+			return
+		case <-ticker.C:
+		case <-t.flush:
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), interval)
+		_ = t.export(ctx)
+		cancel()
+	}
+}
+
+// export sends every queued span in batches.
+func (t *Tracer) export(ctx context.Context) error {
+	var errs []error
+	for {
+		t.mu.Lock()
+		n := min(len(t.queue), t.batchSize)
+		batch := t.queue[:n:n]
+		t.queue = t.queue[n:]
+		t.mu.Unlock()
+
+		if n == 0 {
+			return errors.Join(errs...)
+		}
+		if err := t.exporter.Export(ctx, batch); err != nil {
+			errs = append(errs, err)
+		}
+	}
+}
+
+// Dropped returns how many spans were dropped because the export queue was full.
+func (t *Tracer) Dropped() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.dropped
+}
+
+// newID returns a random hex ID of size bytes, as used for trace and span IDs.
+func newID(size int) string {
+	id := make([]byte, size)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// formatInt formats nanosecond timestamps and integers the way OTLP/JSON expects, as strings.
+func formatInt(value int64) string {
+	return strconv.FormatInt(value, 10)
+}
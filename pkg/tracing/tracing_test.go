@@ -0,0 +1,114 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordingExporter struct {
+	mu    sync.Mutex
+	spans []SpanData
+}
+
+func (r *recordingExporter) Export(_ context.Context, spans []SpanData) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spans = append(r.spans, spans...)
+	return nil
+}
+
+func installTracer(t *testing.T) (*Tracer, *recordingExporter) {
+	t.Helper()
+	exporter := &recordingExporter{}
+	tracer := NewTracer(exporter, time.Hour)
+	SetTracer(tracer)
+	t.Cleanup(func() { SetTracer(nil) })
+	return tracer, exporter
+}
+
+func TestStart_Disabled(t *testing.T) {
+	ctx := context.Background()
+	spanCtx, span := Start(ctx, "ike.disabled")
+
+	if span != nil {
+		t.Fatalf("Expected a nil span without a tracer, got %+v", span)
+	}
+	if spanCtx != ctx {
+		t.Error("Expected the context to be returned unchanged without a tracer")
+	}
+
+	// Methods on the nil span must not panic
+	span.SetAttributes(String("key", "value"))
+	span.RecordError(errors.New("ignored"))
+	span.End()
+}
+
+func TestStart_ParentAndChild(t *testing.T) {
+	tracer, exporter := installTracer(t)
+
+	ctx, parent := Start(context.Background(), "ike.process_source", String("source.url", "https://example.com"))
+	_, child := Start(ctx, "ike.import")
+	child.SetAttributes(Int("import.imported", 3))
+	child.RecordError(errors.New("import failed"))
+	child.End()
+	child.End()
+	parent.End()
+
+	if got := SpanFromContext(ctx); got != parent {
+		t.Errorf("Expected the context to carry the parent span")
+	}
+	if err := tracer.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(exporter.spans) != 2 {
+		t.Fatalf("Expected 2 exported spans, got %d", len(exporter.spans))
+	}
+	childData, parentData := exporter.spans[0], exporter.spans[1]
+	if childData.TraceID != parentData.TraceID {
+		t.Errorf("Expected the child to share the parent's trace ID")
+	}
+	if childData.ParentID != parentData.SpanID || parentData.ParentID != "" {
+		t.Errorf("Expected the child's parent to be %q, got %q", parentData.SpanID, childData.ParentID)
+	}
+	if len(parentData.TraceID) != 32 || len(parentData.SpanID) != 16 {
+		t.Errorf("Expected 16 and 8 byte hex IDs, got %q and %q", parentData.TraceID, parentData.SpanID)
+	}
+	if childData.Err == nil || childData.Err.Error() != "import failed" {
+		t.Errorf("Expected the child's error to be recorded, got %v", childData.Err)
+	}
+	if len(childData.Attributes) != 1 || childData.Attributes[0].Value != int64(3) {
+		t.Errorf("Unexpected child attributes: %+v", childData.Attributes)
+	}
+	if childData.End.Before(childData.Start) {
+		t.Errorf("Expected the span to end after it started")
+	}
+}
+
+func TestTracer_DropsWhenQueueFull(t *testing.T) {
+	tracer, exporter := installTracer(t)
+	tracer.queueSize = 2
+	tracer.batchSize = 10
+
+	for range 3 {
+		_, span := Start(context.Background(), "ike.embed")
+		span.End()
+	}
+	if err := tracer.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(exporter.spans) != 2 || tracer.Dropped() != 1 {
+		t.Errorf("Expected 2 exported and 1 dropped span, got %d and %d", len(exporter.spans), tracer.Dropped())
+	}
+
+	// Spans ended after shutdown are not queued
+	_, span := Start(context.Background(), "ike.late")
+	span.End()
+	if len(tracer.queue) != 0 {
+		t.Errorf("Expected no spans queued after shutdown, got %d", len(tracer.queue))
+	}
+}
@@ -0,0 +1,38 @@
+package util
+
+import (
+	"bytes"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html/charset"
+	"golang.org/x/text/encoding/charmap"
+)
+
+// utf8BOM is dropped from the start of bodies.
+var utf8BOM = []byte("\xef\xbb\xbf")
+
+// ToUTF8 returns body as UTF-8 text, with the name of the charset it was decoded from. Bodies
+// that are already valid UTF-8 are kept as they are, whatever they declare, since older sites
+// often label UTF-8 as ISO-8859-1. Others are decoded from the charset named by a byte order
+// mark, contentType's charset parameter, or an HTML meta tag, and from Windows-1252, the usual
+// charset of undeclared legacy pages, when none names one other than UTF-8. Bytes the charset
+// cannot decode become U+FFFD.
+func ToUTF8(body []byte, contentType string) (string, string) {
+	body = bytes.TrimPrefix(body, utf8BOM)
+	if utf8.Valid(body) {
+		return string(body), "utf-8"
+	}
+
+	encoding, name, _ := charset.DetermineEncoding(body, contentType)
+	if name == "utf-8" {
+		// Declared as UTF-8 but not valid UTF-8, so most likely mislabeled
+		encoding, name = charmap.Windows1252, "windows-1252"
+	}
+	decoded, err := encoding.NewDecoder().Bytes(body)
+	if err != nil {
+		return strings.ToValidUTF8(string(body), "\uFFFD"), "utf-8"
+	}
+	// Decoding from a byte order mark keeps it, as U+FEFF
+	return strings.ToValidUTF8(strings.TrimPrefix(string(decoded), "\ufeff"), "\uFFFD"), name
+}
@@ -0,0 +1,35 @@
+package util
+
+import "testing"
+
+func TestToUTF8(t *testing.T) {
+	tests := []struct {
+		name            string
+		body            string
+		contentType     string
+		expected        string
+		expectedCharset string
+	}{
+		{name: "utf-8", body: "café", expected: "café", expectedCharset: "utf-8"},
+		{name: "utf-8 with BOM", body: "\xef\xbb\xbfcafé", expected: "café", expectedCharset: "utf-8"},
+		{name: "utf-8 mislabeled as latin-1", body: "café", contentType: "text/html; charset=iso-8859-1",
+			expected: "café", expectedCharset: "utf-8"},
+		{name: "undeclared windows-1252", body: "\x93caf\xe9\x94", expected: "“café”",
+			expectedCharset: "windows-1252"},
+		{name: "latin-1 from header", body: "caf\xe9", contentType: "text/html; charset=ISO-8859-1",
+			expected: "café", expectedCharset: "windows-1252"},
+		{name: "meta tag", body: "<html><head><meta charset=\"koi8-r\"></head><body>\xf0\xd2\xc9\xd7\xc5\xd4</body>",
+			expected: `<html><head><meta charset="koi8-r"></head><body>Привет</body>`, expectedCharset: "koi8-r"},
+		{name: "declared utf-8 but not", body: "caf\xe9", contentType: "application/json; charset=UTF-8",
+			expected: "café", expectedCharset: "windows-1252"},
+		{name: "utf-16 BOM", body: "\xff\xfec\x00a\x00f\x00\xe9\x00", expected: "café", expectedCharset: "utf-16le"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, charset := ToUTF8([]byte(tt.body), tt.contentType)
+			if text != tt.expected || charset != tt.expectedCharset {
+				t.Errorf("Expected %q from %s, got %q from %s", tt.expected, tt.expectedCharset, text, charset)
+			}
+		})
+	}
+}
@@ -1,28 +1,122 @@
 package util
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/rs/zerolog"
 )
 
-// NewLogger returns a configured zerolog.Logger with the specified log level.
+var ErrInvalidLogLevel = errors.New("log levels must be given as component=level")
+
+// logOutput is where loggers created by NewLogger write.
+var logOutput io.Writer = os.Stdout
+
+// The logger set with SetLogger, and the component levels set with SetLogLevels.
+var (
+	logMu     sync.RWMutex
+	logBase   *zerolog.Logger
+	logLevels map[string]zerolog.Level
+)
+
+// SetLogOutput sends the output of loggers created from now on to w, for example to keep standard
+// output free for a command's results.
+func SetLogOutput(w io.Writer) {
+	logOutput = w
+}
+
+// SetLogger makes loggers created from now on log through logger, such as an application's own
+// zerolog logger or one from NewSlogLogger, instead of writing to the log output. They keep their
+// own levels. Credentials are only redacted if logger redacts them; NewSlogLogger's does.
+func SetLogger(logger zerolog.Logger) {
+	logMu.Lock()
+	defer logMu.Unlock()
+	logBase = &logger
+}
+
+// SetLogLevels sets the levels of component loggers created from now on, by component name, such
+// as "importers.github", or by the package before the dot, such as "importers". Components not
+// named keep the level they ask for.
+func SetLogLevels(levels map[string]zerolog.Level) {
+	logMu.Lock()
+	defer logMu.Unlock()
+	logLevels = levels
+}
+
+// ParseLogLevels parses component=level pairs separated by commas, such as
+// "importers=debug,engine=warn".
+func ParseLogLevels(value string) (map[string]zerolog.Level, error) {
+	levels := make(map[string]zerolog.Level)
+	for _, pair := range strings.Split(value, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		component, name, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(component) == "" {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidLogLevel, pair)
+		}
+		level, err := zerolog.ParseLevel(strings.ToLower(strings.TrimSpace(name)))
+		if err != nil || level == zerolog.NoLevel {
+			return nil, fmt.Errorf("%w: unknown level %q for %s", ErrInvalidLogLevel, name, component)
+		}
+		levels[strings.TrimSpace(component)] = level
+	}
+	return levels, nil
+}
+
+// NewLogger returns a configured zerolog.Logger with the specified log level. Credentials in log
+// lines, such as tokens in logged URLs and errors, are redacted before they are written.
 func NewLogger(level zerolog.Level) zerolog.Logger {
+	return baseLogger().Level(level)
+}
+
+// ComponentLogger returns the logger of the named component, such as "importers.github", with
+// the name in its component field. It logs at the level SetLogLevels gives the component or,
+// failing that, at level.
+func ComponentLogger(component string, level zerolog.Level) zerolog.Logger {
+	logMu.RLock()
+	if componentLevel, ok := logLevels[component]; ok {
+		level = componentLevel
+	} else if packageName, _, found := strings.Cut(component, "."); found {
+		if packageLevel, ok := logLevels[packageName]; ok {
+			level = packageLevel
+		}
+	}
+	logMu.RUnlock()
+
+	return baseLogger().With().Str("component", component).Logger().Level(level)
+}
+
+// baseLogger returns the logger set with SetLogger or, without one, a logger writing to the log
+// output.
+func baseLogger() zerolog.Logger {
+	logMu.RLock()
+	base := logBase
+	logMu.RUnlock()
+	if base != nil {
+		return *base
+	}
+
+	output := redactingWriter{w: logOutput}
+
 	// Initialize base logger with console output for development or JSON for production
 	var logger zerolog.Logger
 	stage := os.Getenv("STAGE")
 	if strings.EqualFold(stage, "local") {
 		// Pretty printing for development
-		logger = zerolog.New(zerolog.ConsoleWriter{Out: os.Stdout, TimeFormat: time.RFC3339}).
+		logger = zerolog.New(zerolog.ConsoleWriter{Out: output, TimeFormat: time.RFC3339}).
 			With().
 			Str("app", "ike-"+stage).
 			Timestamp().
 			Logger()
 	} else {
 		// JSON output for production
-		logger = zerolog.New(os.Stdout).
+		logger = zerolog.New(output).
 			With().
 			Timestamp().
 			Str("app", "ike-"+stage).
@@ -32,8 +126,5 @@ func NewLogger(level zerolog.Level) zerolog.Logger {
 	// Set UNIX timestamp format for production
 	zerolog.TimeFieldFormat = zerolog.TimeFormatUnix
 
-	// Set log level
-	zerolog.SetGlobalLevel(level)
-
 	return logger
 }
@@ -0,0 +1,98 @@
+package util
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestComponentLogger(t *testing.T) {
+	var output bytes.Buffer
+	SetLogger(zerolog.New(&output))
+	SetLogLevels(map[string]zerolog.Level{"importers": zerolog.DebugLevel, "importers.github": zerolog.WarnLevel})
+	t.Cleanup(func() {
+		logBase = nil
+		SetLogLevels(nil)
+	})
+
+	wpjson := ComponentLogger("importers.wpjson", zerolog.ErrorLevel)
+	wpjson.Debug().Msg("package level")
+	github := ComponentLogger("importers.github", zerolog.DebugLevel)
+	github.Info().Msg("component level")
+	engine := ComponentLogger("engine", zerolog.ErrorLevel)
+	engine.Info().Msg("requested level")
+
+	logged := output.String()
+	if !strings.Contains(logged, `"component":"importers.wpjson"`) || !strings.Contains(logged, "package level") {
+		t.Errorf("Expected the package's level to let the debug line through, got %q", logged)
+	}
+	if strings.Contains(logged, "component level") {
+		t.Errorf("Expected the component's level to win over the package's, got %q", logged)
+	}
+	if strings.Contains(logged, "requested level") {
+		t.Errorf("Expected unnamed components to keep the level they ask for, got %q", logged)
+	}
+}
+
+func TestParseLogLevels(t *testing.T) {
+	levels, err := ParseLogLevels(" importers = Debug,engine=warn,")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if levels["importers"] != zerolog.DebugLevel || levels["engine"] != zerolog.WarnLevel || len(levels) != 2 {
+		t.Errorf("Expected importers at debug and engine at warn, got %v", levels)
+	}
+
+	for _, value := range []string{"importers", "=debug", "engine=loud"} {
+		if _, err := ParseLogLevels(value); !errors.Is(err, ErrInvalidLogLevel) {
+			t.Errorf("Expected ErrInvalidLogLevel for %q, got %v", value, err)
+		}
+	}
+}
+
+// recordingHandler keeps the records it handles.
+type recordingHandler struct {
+	records []slog.Record
+}
+
+func (h *recordingHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h *recordingHandler) Handle(_ context.Context, record slog.Record) error {
+	h.records = append(h.records, record)
+	return nil
+}
+
+func (h *recordingHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+
+func (h *recordingHandler) WithGroup(string) slog.Handler { return h }
+
+func TestNewSlogLogger(t *testing.T) {
+	handler := &recordingHandler{}
+	logger := NewSlogLogger(handler)
+
+	logger.Warn().Str("url", "https://example.com/?token=secret").Int("post_id", 7).Msg("Fetch failed")
+
+	if len(handler.records) != 1 {
+		t.Fatalf("Expected one record, got %d", len(handler.records))
+	}
+	record := handler.records[0]
+	if record.Level != slog.LevelWarn || record.Message != "Fetch failed" {
+		t.Errorf("Expected a warning saying Fetch failed, got %v %q", record.Level, record.Message)
+	}
+	attrs := map[string]slog.Value{}
+	record.Attrs(func(attr slog.Attr) bool {
+		attrs[attr.Key] = attr.Value
+		return true
+	})
+	if attrs["post_id"].Kind() != slog.KindInt64 || attrs["post_id"].Int64() != 7 {
+		t.Errorf("Expected post_id 7 as an integer, got %v", attrs["post_id"])
+	}
+	if url := attrs["url"].String(); strings.Contains(url, "secret") {
+		t.Errorf("Expected the token to be redacted, got %s", url)
+	}
+}
@@ -0,0 +1,110 @@
+package util
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"maps"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// NewSlogLogger returns a logger that hands its records to handler, for applications logging
+// with log/slog. Pass it to SetLogger, or to a component's WithLogger option. Credentials are
+// redacted before records reach handler.
+func NewSlogLogger(handler slog.Handler) zerolog.Logger {
+	return zerolog.New(redactingWriter{w: slogWriter{handler: handler}}).With().Timestamp().Logger()
+}
+
+// slogWriter decodes the JSON lines zerolog writes into slog records.
+type slogWriter struct {
+	handler slog.Handler
+}
+
+func (s slogWriter) Write(p []byte) (int, error) {
+	var fields map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(p))
+	decoder.UseNumber()
+	if err := decoder.Decode(&fields); err != nil {
+		return 0, err
+	}
+
+	ctx := context.Background()
+	level := slogLevel(fields[zerolog.LevelFieldName])
+	if !s.handler.Enabled(ctx, level) {
+		return len(p), nil
+	}
+
+	message, _ := fields[zerolog.MessageFieldName].(string)
+	record := slog.NewRecord(recordTime(fields[zerolog.TimestampFieldName]), level, message, 0)
+	for _, key := range slices.Sorted(maps.Keys(fields)) {
+		switch key {
+		case zerolog.LevelFieldName, zerolog.MessageFieldName, zerolog.TimestampFieldName:
+			continue
+		}
+		record.AddAttrs(slogAttr(key, fields[key]))
+	}
+	if err := s.handler.Handle(ctx, record); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// slogLevel maps a zerolog level name to the nearest slog level; trace sits below debug, and
+// fatal and panic above error.
+func slogLevel(value interface{}) slog.Level {
+	name, _ := value.(string)
+	level, err := zerolog.ParseLevel(name)
+	if err != nil {
+		return slog.LevelInfo
+	}
+	switch level {
+	case zerolog.TraceLevel:
+		return slog.LevelDebug - 4
+	case zerolog.DebugLevel:
+		return slog.LevelDebug
+	case zerolog.WarnLevel:
+		return slog.LevelWarn
+	case zerolog.ErrorLevel:
+		return slog.LevelError
+	case zerolog.FatalLevel, zerolog.PanicLevel:
+		return slog.LevelError + 4
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// recordTime parses a zerolog timestamp, written as UNIX seconds or RFC 3339, falling back to now.
+func recordTime(value interface{}) time.Time {
+	switch v := value.(type) {
+	case json.Number:
+		if seconds, err := v.Int64(); err == nil {
+			return time.Unix(seconds, 0)
+		}
+	case string:
+		if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+			return t
+		}
+		if seconds, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return time.Unix(seconds, 0)
+		}
+	}
+	return time.Now()
+}
+
+// slogAttr converts a decoded JSON field to an attribute, keeping whole numbers as integers.
+func slogAttr(key string, value interface{}) slog.Attr {
+	if number, ok := value.(json.Number); ok {
+		if i, err := number.Int64(); err == nil {
+			return slog.Int64(key, i)
+		}
+		if f, err := number.Float64(); err == nil {
+			return slog.Float64(key, f)
+		}
+	}
+	return slog.Any(key, value)
+}
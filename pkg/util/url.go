@@ -0,0 +1,76 @@
+package util
+
+import (
+	"errors"
+	"net"
+	"net/url"
+	"strings"
+)
+
+var ErrInvalidURL = errors.New("URL must be absolute")
+
+// defaultPorts are dropped from normalized URLs.
+var defaultPorts = map[string]string{"http": "80", "https": "443"}
+
+// trackingParams are query parameters added by analytics and ad platforms to links, dropped from
+// normalized URLs along with any utm_ parameter. They never change the page served.
+var trackingParams = map[string]bool{
+	"fbclid": true, "gclid": true, "dclid": true, "gbraid": true, "wbraid": true, "msclkid": true,
+	"yclid": true, "twclid": true, "igshid": true, "mc_cid": true, "mc_eid": true, "_ga": true, "_gl": true,
+	"_hsenc": true, "_hsmi": true, "mkt_tok": true,
+}
+
+// NormalizeURL returns the canonical form of an absolute URL, so the same page imported twice
+// maps to one source. The scheme and host are lower-cased, default ports, fragments, and trailing
+// slashes are dropped, tracking parameters such as utm_source and gclid are removed, and the
+// remaining query parameters are sorted. Path case is kept, since most servers
+// treat it as significant.
+func NormalizeURL(rawURL string) (string, error) {
+	parsed, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return "", err
+	}
+	if parsed.Scheme == "" || parsed.Host == "" {
+		return "", ErrInvalidURL
+	}
+
+	parsed.Scheme = strings.ToLower(parsed.Scheme)
+	parsed.Host = strings.ToLower(parsed.Host)
+	if host, port, err := net.SplitHostPort(parsed.Host); err == nil && defaultPorts[parsed.Scheme] == port {
+		parsed.Host = host
+	}
+
+	parsed.Fragment, parsed.RawFragment = "", ""
+	query := parsed.Query()
+	for key := range query {
+		if isTrackingParam(key) {
+			query.Del(key)
+		}
+	}
+	parsed.RawQuery = query.Encode()
+	parsed.ForceQuery = false
+	if trimmed := strings.TrimRight(parsed.Path, "/"); trimmed != parsed.Path {
+		parsed.Path = trimmed
+		parsed.RawPath = strings.TrimRight(parsed.RawPath, "/")
+	}
+
+	return parsed.String(), nil
+}
+
+// isTrackingParam reports whether the query parameter key only tracks where a visitor came from.
+func isTrackingParam(key string) bool {
+	key = strings.ToLower(key)
+	return strings.HasPrefix(key, "utm_") || trackingParams[key]
+}
+
+// UnderURL reports whether rawURL is prefix or a path, query, or fragment beneath it, so settings
+// for github.com/owner/repo do not apply to github.com/owner/repository.
+func UnderURL(rawURL, prefix string) bool {
+	if !strings.HasPrefix(rawURL, prefix) {
+		return false
+	}
+	if len(rawURL) == len(prefix) || strings.HasSuffix(prefix, "/") {
+		return true
+	}
+	return strings.ContainsRune("/?#", rune(rawURL[len(prefix)]))
+}
@@ -0,0 +1,107 @@
+package util
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalizeURL(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expected    string
+		expectedErr error
+	}{
+		{name: "already normal", input: "https://github.com/owner/repo/blob/main/README.md",
+			expected: "https://github.com/owner/repo/blob/main/README.md"},
+		{name: "case and default port", input: "HTTPS://Example.COM:443/wp-json/wp/v2/posts/42",
+			expected: "https://example.com/wp-json/wp/v2/posts/42"},
+		{name: "other port kept", input: "http://localhost:8080/posts", expected: "http://localhost:8080/posts"},
+		{name: "trailing slash and fragment", input: "https://example.com/docs/#intro",
+			expected: "https://example.com/docs"},
+		{name: "root", input: "https://example.com/", expected: "https://example.com"},
+		{name: "sorted query", input: "https://example.com/search?b=2&a=1&a=0",
+			expected: "https://example.com/search?a=1&a=0&b=2"},
+		{name: "tracking parameters", input: "https://example.com/post?utm_source=x&UTM_Medium=y&id=7&gclid=abc&fbclid=d",
+			expected: "https://example.com/post?id=7"},
+		{name: "only tracking parameters", input: "https://example.com/post/?utm_campaign=spring#top",
+			expected: "https://example.com/post"},
+		{name: "empty query", input: "https://example.com/page?", expected: "https://example.com/page"},
+		{name: "escaped path kept", input: "https://github.com/o/r/blob/main/My%20Doc.md",
+			expected: "https://github.com/o/r/blob/main/My%20Doc.md"},
+		{name: "relative", input: "/posts/42", expectedErr: ErrInvalidURL},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeURL(tt.input)
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("Expected error %v, got %v", tt.expectedErr, err)
+			}
+			if got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestUnderURL(t *testing.T) {
+	tests := []struct {
+		name        string
+		rawURL      string
+		prefix      string
+		expected    bool
+		description string
+	}{
+		{
+			name:        "same URL",
+			rawURL:      "https://github.com/owner/repo",
+			prefix:      "https://github.com/owner/repo",
+			expected:    true,
+			description: "should apply settings to the URL they were stored for",
+		},
+		{
+			name:        "repository file",
+			rawURL:      "https://github.com/owner/repo/blob/main/README.md",
+			prefix:      "https://github.com/owner/repo",
+			expected:    true,
+			description: "should apply repository settings to its files",
+		},
+		{
+			name:        "WordPress post",
+			rawURL:      "https://example.com/wp-json/wp/v2/posts?slug=hello",
+			prefix:      "https://example.com/wp-json/wp/v2/posts",
+			expected:    true,
+			description: "should apply endpoint settings to posts fetched with a query",
+		},
+		{
+			name:        "trailing slash prefix",
+			rawURL:      "https://example.com/docs/page",
+			prefix:      "https://example.com/docs/",
+			expected:    true,
+			description: "should match a prefix that ends with a slash",
+		},
+		{
+			name:        "sibling repository",
+			rawURL:      "https://github.com/owner/repository/blob/main/README.md",
+			prefix:      "https://github.com/owner/repo",
+			expected:    false,
+			description: "should not apply settings to a repository whose name shares a prefix",
+		},
+		{
+			name:        "unrelated URL",
+			rawURL:      "https://example.com",
+			prefix:      "https://github.com/owner/repo",
+			expected:    false,
+			description: "should not match a different host",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := UnderURL(tt.rawURL, tt.prefix); got != tt.expected {
+				t.Errorf("Expected %v, got %v for test: %s", tt.expected, got, tt.description)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package vector
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// bytesPerFloat is the size of an encoded float32 component.
+const bytesPerFloat = 4
+
+var (
+	ErrInvalidLength = errors.New("encoded vector length is not a multiple of 4")
+	ErrInvalidLegacy = errors.New("invalid legacy vector format")
+)
+
+// Encode serializes a vector as little-endian float32 values.
+func Encode(values []float32) []byte {
+	buf := make([]byte, len(values)*bytesPerFloat)
+	for i, v := range values {
+		binary.LittleEndian.PutUint32(buf[i*bytesPerFloat:], math.Float32bits(v))
+	}
+	return buf
+}
+
+// Decode parses a little-endian float32 BLOB produced by Encode.
+func Decode(data []byte) ([]float32, error) {
+	if len(data)%bytesPerFloat != 0 {
+		return nil, fmt.Errorf("%w: %d bytes", ErrInvalidLength, len(data))
+	}
+
+	values := make([]float32, len(data)/bytesPerFloat)
+	for i := range values {
+		values[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[i*bytesPerFloat:]))
+	}
+	return values, nil
+}
+
+// ParseLegacy parses the text format embeddings were stored in before BLOB storage,
+// e.g. "[[0.1 0.2 0.3]]" (the output of fmt.Sprintf("[%v]", vector)).
+func ParseLegacy(text string) ([]float32, error) {
+	trimmed := strings.TrimSpace(text)
+	if !strings.HasPrefix(trimmed, "[") || !strings.HasSuffix(trimmed, "]") {
+		return nil, ErrInvalidLegacy
+	}
+	trimmed = strings.Trim(trimmed, "[]")
+
+	fields := strings.Fields(strings.ReplaceAll(trimmed, ",", " "))
+	values := make([]float32, 0, len(fields))
+	for _, field := range fields {
+		value, err := strconv.ParseFloat(field, 32)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidLegacy, err)
+		}
+		values = append(values, float32(value))
+	}
+	return values, nil
+}
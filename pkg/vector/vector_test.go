@@ -0,0 +1,79 @@
+package vector
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float32
+	}{
+		{name: "empty vector", values: []float32{}},
+		{name: "single value", values: []float32{0.5}},
+		{name: "mixed values", values: []float32{-1.25, 0, 3.5, math.MaxFloat32, math.SmallestNonzeroFloat32}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded := Encode(tt.values)
+			if len(encoded) != len(tt.values)*4 {
+				t.Errorf("Expected %d bytes, got %d", len(tt.values)*4, len(encoded))
+			}
+
+			decoded, err := Decode(encoded)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(decoded) != len(tt.values) {
+				t.Fatalf("Expected %d values, got %d", len(tt.values), len(decoded))
+			}
+			for i := range tt.values {
+				if decoded[i] != tt.values[i] {
+					t.Errorf("Value %d: expected %v, got %v", i, tt.values[i], decoded[i])
+				}
+			}
+		})
+	}
+}
+
+func TestEncode_LittleEndian(t *testing.T) {
+	encoded := Encode([]float32{1})
+	expected := []byte{0x00, 0x00, 0x80, 0x3f}
+	for i := range expected {
+		if encoded[i] != expected[i] {
+			t.Fatalf("Expected %v, got %v", expected, encoded)
+		}
+	}
+}
+
+func TestDecode_InvalidLength(t *testing.T) {
+	if _, err := Decode([]byte{1, 2, 3}); !errors.Is(err, ErrInvalidLength) {
+		t.Errorf("Expected ErrInvalidLength, got %v", err)
+	}
+}
+
+func TestParseLegacy(t *testing.T) {
+	values := []float32{0.1, -0.25, 3}
+	parsed, err := ParseLegacy(fmt.Sprintf("[%v]", values))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(parsed) != len(values) {
+		t.Fatalf("Expected %d values, got %d", len(values), len(parsed))
+	}
+	for i := range values {
+		if parsed[i] != values[i] {
+			t.Errorf("Value %d: expected %v, got %v", i, values[i], parsed[i])
+		}
+	}
+
+	for _, invalid := range []string{"", "0.1 0.2", "[[0.1 abc]]"} {
+		if _, err := ParseLegacy(invalid); !errors.Is(err, ErrInvalidLegacy) {
+			t.Errorf("Expected ErrInvalidLegacy for %q, got %v", invalid, err)
+		}
+	}
+}